@@ -7,6 +7,7 @@ import (
 	"github.com/bcc-code/bcc-media-flows/paths"
 	"github.com/bcc-code/bcc-media-flows/services/ffmpeg"
 	"github.com/bcc-code/bcc-media-flows/services/transcode"
+	"github.com/bcc-code/bcc-media-flows/utils"
 	"github.com/bcc-code/bcc-media-flows/utils/testutils"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/suite"
@@ -49,7 +50,7 @@ func (s *AnalyzeTestSuite) TestGetMimeType_AudioFile() {
 	t := s.T()
 
 	testFile := paths.MustParse("./testdata/generated/mime_test.wav")
-	err := transcode.GenerateToneFile(440, 1, 48000, "01:00:00:00", testFile)
+	err := transcode.GenerateToneFile(440, 1, 48000, utils.MustParseTimecode("01:00:00:00", utils.Rate25), testFile)
 	assert.NoError(t, err)
 
 	ua := UtilActivities{}
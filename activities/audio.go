@@ -93,10 +93,11 @@ func (aa AudioActivities) AdjustAudioToVideoStart(ctx context.Context, input Adj
 		log.Warn(err.Error())
 		telegram.SendText(telegram.ChatOther, fmt.Sprintf("🟧 Unable to get timecode for `%s`. File imported unadjusted and *WILL* be out of sync with video.", input.AudioFile))
 	} else {
-		videoSamples, err = utils.TCToSamples(videoTC, 25, 48000)
+		tc, err := utils.ParseTimecode(videoTC, videoTimecodeRate(input.VideoFile))
 		if err != nil {
 			return nil, err
 		}
+		videoSamples = tc.Samples(48000)
 	}
 
 	audioSamples, err := ffmpeg.GetTimeReference(input.AudioFile.Local())
@@ -126,6 +127,16 @@ func (aa AudioActivities) AdjustAudioToVideoStart(ctx context.Context, input Adj
 	return &common.AudioResult{OutputPath: input.OutputFile}, err
 }
 
+// videoTimecodeRate is the rate the timecode of a video is in. It is 25, as YouPlay
+// records, when the rate cannot be read or is not one timecode is counted in.
+func videoTimecodeRate(video paths.Path) utils.TimecodeRate {
+	info, err := ffmpeg.GetStreamInfo(video.Local())
+	if err != nil || info.TimecodeRate.IsZero() {
+		return utils.Rate25
+	}
+	return info.TimecodeRate
+}
+
 func (aa AudioActivities) DetectSilence(ctx context.Context, input common.DetectSilenceInput) (bool, error) {
 	log := activity.GetLogger(ctx)
 	activity.RecordHeartbeat(ctx, "DetectSilence")
@@ -211,10 +222,12 @@ func (aa AudioActivities) Convert51to4Mono(ctx context.Context, input common.Aud
 }
 
 type ToneInput struct {
-	Frequency       int
-	Duration        float64
-	SampleRate      int
-	TimeCode        string
+	Frequency  int
+	Duration   float64
+	SampleRate int
+	TimeCode   string
+	// FrameRate is the rate TimeCode is in, 25 when it is not set.
+	FrameRate       utils.TimecodeRate
 	DestinationFile paths.Path
 }
 
@@ -226,7 +239,16 @@ func (aa AudioActivities) GenerateToneFile(ctx context.Context, input ToneInput)
 	activity.RecordHeartbeat(ctx, "GenerateToneFile")
 	log.Info("Starting GenerateToneFileActivity")
 
-	err := transcode.GenerateToneFile(input.Frequency, input.Duration, input.SampleRate, input.TimeCode, input.DestinationFile)
+	rate := input.FrameRate
+	if rate.IsZero() {
+		rate = utils.Rate25
+	}
+	tc, err := utils.ParseTimecode(input.TimeCode, rate)
+	if err != nil {
+		return nil, err
+	}
+
+	err = transcode.GenerateToneFile(input.Frequency, input.Duration, input.SampleRate, tc, input.DestinationFile)
 	return &common.AudioResult{
 		OutputPath: input.DestinationFile,
 	}, err
@@ -2,11 +2,9 @@ package activities
 
 import (
 	"context"
-	"encoding/json"
-	"fmt"
-	"github.com/bcc-code/bcc-media-flows/environment"
 
-	"github.com/bcc-code/bcc-media-flows/internal/httpx"
+	"github.com/bcc-code/bcc-media-flows/services/audiosync"
+	"go.temporal.io/sdk/activity"
 )
 
 type GetAudioDiffParams struct {
@@ -16,41 +14,28 @@ type GetAudioDiffParams struct {
 
 type GetAudioDiffResult struct {
 	Difference int // in milliseconds
+	// Confidence is how well the audio matched, from 0 to 1.
+	Confidence float64
+	// Drift is how much the difference changes through the file, in milliseconds.
+	Drift int
 }
 
-func (ua UtilActivities) GetAudioDiff(ctx context.Context, params GetAudioDiffParams) (*GetAudioDiffResult, error) {
-	syncServiceURL := environment.Get().Services.Sync()
-
-	client := httpx.New(httpx.Config{
-		Service: "audio sync",
-		Headers: map[string]string{"Content-Type": "application/json"},
-	})
-
-	resp, err := client.R().
-		SetContext(ctx).
-		SetBody(map[string]string{
-			"reference_file": params.ReferenceFile,
-			"target_file":    params.TargetFile,
-		}).
-		Post(syncServiceURL)
+// GetAudioDiff measures how much earlier the target audio is than the reference, by
+// cross-correlating the two at several points in the files. It decodes them with
+// ffmpeg, so it runs on the audio queue.
+func (aa AudioActivities) GetAudioDiff(ctx context.Context, params GetAudioDiffParams) (*GetAudioDiffResult, error) {
+	log := activity.GetLogger(ctx)
+	activity.RecordHeartbeat(ctx, "GetAudioDiff")
+	log.Info("Starting GetAudioDiffActivity")
 
+	result, err := audiosync.Offset(params.ReferenceFile, params.TargetFile, audiosync.Options{})
 	if err != nil {
 		return nil, err
 	}
 
-	// Parse the JSON response
-	var response struct {
-		Offset float64 `json:"offset"`
-	}
-
-	if err := json.Unmarshal(resp.Body(), &response); err != nil {
-		return nil, fmt.Errorf("failed to parse response: %w", err)
-	}
-
-	// Convert seconds to milliseconds
-	differenceMs := int(response.Offset * 1000)
-
 	return &GetAudioDiffResult{
-		Difference: differenceMs,
+		Difference: int(result.Offset.Milliseconds()),
+		Confidence: result.Confidence,
+		Drift:      int(result.Drift.Milliseconds()),
 	}, nil
 }
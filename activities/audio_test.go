@@ -7,6 +7,7 @@ import (
 	"github.com/bcc-code/bcc-media-flows/common"
 	"github.com/bcc-code/bcc-media-flows/paths"
 	"github.com/bcc-code/bcc-media-flows/services/transcode"
+	"github.com/bcc-code/bcc-media-flows/utils"
 	"github.com/bcc-code/bcc-media-flows/utils/testutils"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/suite"
@@ -31,7 +32,7 @@ func (s *AudioTestSuite) TestTranscodeToAudioAac() {
 	inputFile := paths.MustParse("./testdata/generated/aac_input.wav")
 	outputDir := paths.MustParse("./testdata/generated/aac_output/")
 	os.MkdirAll(outputDir.Local(), 0755)
-	err := transcode.GenerateToneFile(440, 2, 48000, "01:00:00:00", inputFile)
+	err := transcode.GenerateToneFile(440, 2, 48000, utils.MustParseTimecode("01:00:00:00", utils.Rate25), inputFile)
 	assert.NoError(t, err)
 
 	aa := AudioActivities{}
@@ -56,7 +57,7 @@ func (s *AudioTestSuite) TestTranscodeToAudioWav() {
 	inputFile := paths.MustParse("./testdata/generated/wav_input.wav")
 	outputDir := paths.MustParse("./testdata/generated/wav_output/")
 	os.MkdirAll(outputDir.Local(), 0755)
-	err := transcode.GenerateToneFile(440, 2, 48000, "01:00:00:00", inputFile)
+	err := transcode.GenerateToneFile(440, 2, 48000, utils.MustParseTimecode("01:00:00:00", utils.Rate25), inputFile)
 	assert.NoError(t, err)
 
 	aa := AudioActivities{}
@@ -80,7 +81,7 @@ func (s *AudioTestSuite) TestTranscodeToAudioWav_WithTimecode() {
 	inputFile := paths.MustParse("./testdata/generated/wav_tc_input.wav")
 	outputDir := paths.MustParse("./testdata/generated/wav_tc_output/")
 	os.MkdirAll(outputDir.Local(), 0755)
-	err := transcode.GenerateToneFile(440, 2, 48000, "01:00:00:00", inputFile)
+	err := transcode.GenerateToneFile(440, 2, 48000, utils.MustParseTimecode("01:00:00:00", utils.Rate25), inputFile)
 	assert.NoError(t, err)
 
 	aa := AudioActivities{}
@@ -160,7 +161,7 @@ func (s *AudioTestSuite) TestDetectSilence_NotSilent() {
 	t := s.T()
 
 	testFile := paths.MustParse("./testdata/generated/silence_tone.wav")
-	err := transcode.GenerateToneFile(440, 2, 48000, "01:00:00:00", testFile)
+	err := transcode.GenerateToneFile(440, 2, 48000, utils.MustParseTimecode("01:00:00:00", utils.Rate25), testFile)
 	assert.NoError(t, err)
 
 	aa := AudioActivities{}
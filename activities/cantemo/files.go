@@ -4,7 +4,9 @@ import (
 	"context"
 	"fmt"
 	vsactivitiy "github.com/bcc-code/bcc-media-flows/activities/vidispine"
+	"github.com/bcc-code/bcc-media-flows/paths"
 	"github.com/bcc-code/bcc-media-flows/services/cantemo"
+	"github.com/bcc-code/bcc-media-flows/services/fixity"
 	"go.temporal.io/sdk/activity"
 	"strings"
 	"time"
@@ -34,6 +36,8 @@ type RenameFileParams struct {
 	SourceStorage     string
 	DestinatinStorage string
 	NewPath           string
+	// Checksums, when set, are what MoveFileWait checks the moved file against.
+	Checksums *fixity.Checksums `json:",omitempty"`
 }
 
 func (a Activities) RenameFile(_ context.Context, params *RenameFileParams) (string, error) {
@@ -83,11 +87,49 @@ func (a Activities) MoveFileWait(ctx context.Context, params *RenameFileParams)
 
 	if job == nil {
 		// No job found, don't error out. Likely nothing too do
-		return nil, nil
+		return nil, verifyMovedShape(ctx, params)
 	}
 
-	return vsactivitiy.Vidispine.WaitForJobCompletion(ctx, vsactivitiy.WaitForJobCompletionParams{
+	result, err := vsactivitiy.Vidispine.WaitForJobCompletion(ctx, vsactivitiy.WaitForJobCompletionParams{
 		JobID:     job.JobID,
 		SleepTime: 20,
 	})
+	if err != nil {
+		return nil, err
+	}
+
+	return result, verifyMovedShape(ctx, params)
+}
+
+// verifyMovedShape checks the file of the shape, where it is after the move, against
+// the checksums in the params, if any.
+func verifyMovedShape(ctx context.Context, params *RenameFileParams) error {
+	if params.Checksums == nil {
+		return nil
+	}
+
+	shapes, err := vsactivitiy.Vidispine.GetShapes(ctx, vsactivitiy.VXOnlyParam{VXID: params.ItemID})
+	if err != nil {
+		return err
+	}
+
+	var shapePath string
+	for _, shape := range shapes.Shape {
+		if shape.ID == params.ShapeID {
+			shapePath = shape.GetPath()
+		}
+	}
+	if shapePath == "" {
+		return fmt.Errorf("shape %s of %s has no file after the move", params.ShapeID, params.ItemID)
+	}
+
+	file, err := paths.Parse(shapePath)
+	if err != nil {
+		return err
+	}
+
+	_, err = fixity.Verify(ctx, file.Local(), *params.Checksums, func(read int64) {
+		activity.RecordHeartbeat(ctx, read)
+	})
+	return fixity.ApplicationError(err)
 }
@@ -0,0 +1,362 @@
+package activities
+
+import (
+	"context"
+	"encoding/csv"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/bcc-code/bcc-media-flows/paths"
+	"github.com/bcc-code/bcc-media-flows/utils"
+	"go.temporal.io/api/workflowservice/v1"
+	"go.temporal.io/sdk/activity"
+	"go.temporal.io/sdk/temporal"
+)
+
+type CleanupInput struct {
+	Root      paths.Path
+	OlderThan time.Time
+	// Exclude are folders nothing is deleted in, such as the temp folders of the
+	// workflows that are running.
+	Exclude []paths.Path
+	// DryRun deletes nothing, and only reports what would be deleted.
+	DryRun bool
+	// Report is a CSV file the files deleted, or that would be, are written to. Nil
+	// writes none.
+	Report *paths.Path
+}
+
+// Reasons a cleanup deletes a file for.
+const (
+	CleanupReasonAge   = "age"
+	CleanupReasonSpace = "space"
+)
+
+// CleanupFile is a file a cleanup deletes, or would delete in a dry run.
+type CleanupFile struct {
+	Path     string
+	Size     int64
+	Modified time.Time
+	Reason   string
+}
+
+// CleanupFilesResult is what a cleanup deleted, or would delete in a dry run. The files
+// are in the report, so a full disk does not fill the workflow history.
+type CleanupFilesResult struct {
+	Count int
+	Size  int64
+}
+
+func (ua UtilActivities) DeleteEmptyDirectories(ctx context.Context, input CleanupInput) ([]string, error) {
+	log := activity.GetLogger(ctx)
+	activity.RecordHeartbeat(ctx, "DeleteEmptyDirectories")
+	log.Info("Starting DeleteEmptyDirectoriesActivity")
+
+	empty, err := utils.GetEmptyDirs(input.Root.Local())
+	if err != nil {
+		return nil, err
+	}
+
+	exclude := excludedFolders(input.Exclude)
+
+	deleted := []string{}
+	for _, dir := range empty {
+		if isExcluded(dir, exclude) {
+			continue
+		}
+		if input.DryRun {
+			deleted = append(deleted, dir)
+			continue
+		}
+		err := os.Remove(dir)
+		if err != nil {
+			return deleted, err
+		}
+		deleted = append(deleted, dir)
+	}
+
+	return deleted, nil
+}
+
+// DeleteOldFiles deletes the files under the root modified before OlderThan.
+func (ua UtilActivities) DeleteOldFiles(ctx context.Context, input CleanupInput) (*CleanupFilesResult, error) {
+	log := activity.GetLogger(ctx)
+	activity.RecordHeartbeat(ctx, "DeleteOldFiles")
+	log.Info("Starting DeleteOldFilesActivity")
+
+	stop := simpleHeartBeater(ctx)
+	defer close(stop)
+
+	files, err := cleanupCandidates(input.Root.Local(), excludedFolders(input.Exclude))
+	if err != nil {
+		return nil, err
+	}
+
+	var old []CleanupFile
+	for _, file := range files {
+		if file.Modified.Before(input.OlderThan) {
+			file.Reason = CleanupReasonAge
+			old = append(old, file)
+		}
+	}
+
+	return deleteCleanupFiles(ctx, old, input.DryRun, input.Report)
+}
+
+type FreeSpaceInput struct {
+	// Folders are swept together, oldest file first whichever folder it is in. Files
+	// modified before the OlderThan of their folder are left to DeleteOldFiles.
+	Folders []CleanupInput
+	// Size is how many bytes to free.
+	Size   int64
+	DryRun bool
+	Report *paths.Path
+}
+
+// FreeSpace deletes the oldest files in the folders until Size bytes are freed, or none
+// are left. It is how a drive short of space is cleaned up before its files are old.
+func (ua UtilActivities) FreeSpace(ctx context.Context, input FreeSpaceInput) (*CleanupFilesResult, error) {
+	log := activity.GetLogger(ctx)
+	activity.RecordHeartbeat(ctx, "FreeSpace")
+	log.Info("Starting FreeSpaceActivity", "size", input.Size)
+
+	stop := simpleHeartBeater(ctx)
+	defer close(stop)
+
+	var candidates []CleanupFile
+	for _, folder := range input.Folders {
+		files, err := cleanupCandidates(folder.Root.Local(), excludedFolders(folder.Exclude))
+		if err != nil {
+			return nil, err
+		}
+		for _, file := range files {
+			if !file.Modified.Before(folder.OlderThan) {
+				file.Reason = CleanupReasonSpace
+				candidates = append(candidates, file)
+			}
+		}
+	}
+
+	sort.Slice(candidates, func(i, j int) bool {
+		if !candidates[i].Modified.Equal(candidates[j].Modified) {
+			return candidates[i].Modified.Before(candidates[j].Modified)
+		}
+		return candidates[i].Path < candidates[j].Path
+	})
+
+	var selected []CleanupFile
+	var size int64
+	for _, file := range candidates {
+		if size >= input.Size {
+			break
+		}
+		selected = append(selected, file)
+		size += file.Size
+	}
+
+	return deleteCleanupFiles(ctx, selected, input.DryRun, input.Report)
+}
+
+// deleteCleanupFiles deletes the files, or only counts them in a dry run, and writes
+// them to the report.
+func deleteCleanupFiles(ctx context.Context, files []CleanupFile, dryRun bool, report *paths.Path) (*CleanupFilesResult, error) {
+	result := &CleanupFilesResult{}
+	var deleted []CleanupFile
+	var err error
+
+	for _, file := range files {
+		if !dryRun {
+			if err = os.Remove(file.Path); err != nil {
+				break
+			}
+		}
+		deleted = append(deleted, file)
+		result.Count++
+		result.Size += file.Size
+	}
+
+	activity.GetLogger(ctx).Info("Cleaned up files", "count", result.Count, "size", result.Size, "dryRun", dryRun)
+
+	if report != nil {
+		if reportErr := writeCleanupReport(report.Local(), deleted); reportErr != nil && err == nil {
+			err = reportErr
+		}
+	}
+	return result, err
+}
+
+func writeCleanupReport(file string, files []CleanupFile) error {
+	err := os.MkdirAll(filepath.Dir(file), os.ModePerm)
+	if err != nil {
+		return err
+	}
+
+	f, err := os.Create(file)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	w := csv.NewWriter(f)
+	_ = w.Write([]string{"path", "size", "modified", "reason"})
+	for _, file := range files {
+		_ = w.Write([]string{file.Path, fmt.Sprint(file.Size), file.Modified.Format(time.RFC3339), file.Reason})
+	}
+	w.Flush()
+	return w.Error()
+}
+
+// cleanupCandidates are the files under the root a cleanup can delete: not hidden, not
+// a readme, which says what a folder is for and keeps it, and not in an excluded folder.
+func cleanupCandidates(root string, exclude []string) ([]CleanupFile, error) {
+	var files []CleanupFile
+	err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if isExcluded(path, exclude) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		name := filepath.Base(path)
+		if name[0] == '.' || strings.HasPrefix(name, "README") || strings.HasSuffix(name, "readme.txt") {
+			return nil
+		}
+
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		files = append(files, CleanupFile{Path: path, Size: info.Size(), Modified: info.ModTime()})
+		return nil
+	})
+	return files, err
+}
+
+func excludedFolders(exclude []paths.Path) []string {
+	var out []string
+	for _, p := range exclude {
+		out = append(out, filepath.Clean(p.Local()))
+	}
+	return out
+}
+
+func isExcluded(path string, exclude []string) bool {
+	path = filepath.Clean(path)
+	for _, ex := range exclude {
+		if path == ex || strings.HasPrefix(path, ex+string(filepath.Separator)) {
+			return true
+		}
+	}
+	return false
+}
+
+// DiskUsage is the size of the file system a path is on, and how much of it is free.
+type DiskUsage struct {
+	Size int64
+	Free int64
+}
+
+func (d DiskUsage) FreePercent() float64 {
+	if d.Size == 0 {
+		return 0
+	}
+	return float64(d.Free) / float64(d.Size) * 100
+}
+
+func (ua UtilActivities) GetDiskUsage(ctx context.Context, input FileInput) (*DiskUsage, error) {
+	log := activity.GetLogger(ctx)
+	activity.RecordHeartbeat(ctx, "GetDiskUsage")
+	log.Info("Starting GetDiskUsageActivity")
+
+	var stat syscall.Statfs_t
+	err := syscall.Statfs(input.Path.Local(), &stat)
+	if err != nil {
+		return nil, err
+	}
+	return &DiskUsage{
+		Size: int64(stat.Blocks) * int64(stat.Bsize),
+		Free: int64(stat.Bavail) * int64(stat.Bsize),
+	}, nil
+}
+
+// FolderUsage is what is in a cleanup folder, and how much of it is old enough to be
+// deleted.
+type FolderUsage struct {
+	Count    int
+	Size     int64
+	OldCount int
+	OldSize  int64
+}
+
+func (ua UtilActivities) GetFolderUsage(ctx context.Context, input CleanupInput) (*FolderUsage, error) {
+	log := activity.GetLogger(ctx)
+	activity.RecordHeartbeat(ctx, "GetFolderUsage")
+	log.Info("Starting GetFolderUsageActivity")
+
+	stop := simpleHeartBeater(ctx)
+	defer close(stop)
+
+	files, err := cleanupCandidates(input.Root.Local(), excludedFolders(input.Exclude))
+	if err != nil {
+		return nil, err
+	}
+
+	usage := &FolderUsage{}
+	for _, file := range files {
+		usage.Count++
+		usage.Size += file.Size
+		if file.Modified.Before(input.OlderThan) {
+			usage.OldCount++
+			usage.OldSize += file.Size
+		}
+	}
+	return usage, nil
+}
+
+// GetRunningWorkflowRuns returns the first run IDs of the workflows that are running,
+// which their temp folders are named by.
+func (ua UtilActivities) GetRunningWorkflowRuns(ctx context.Context, _ any) ([]string, error) {
+	log := activity.GetLogger(ctx)
+	activity.RecordHeartbeat(ctx, "GetRunningWorkflowRuns")
+	log.Info("Starting GetRunningWorkflowRunsActivity")
+
+	if ua.Temporal == nil {
+		return nil, temporal.NewNonRetryableApplicationError("no Temporal client", "TEMPORAL_NOT_CONFIGURED", nil)
+	}
+
+	var ids []string
+	var token []byte
+	for {
+		resp, err := ua.Temporal.ListWorkflow(ctx, &workflowservice.ListWorkflowExecutionsRequest{
+			Query:         `ExecutionStatus = "Running"`,
+			NextPageToken: token,
+		})
+		if err != nil {
+			return nil, err
+		}
+		for _, exec := range resp.GetExecutions() {
+			id := exec.GetFirstRunId()
+			if id == "" {
+				id = exec.GetExecution().GetRunId()
+			}
+			ids = append(ids, id)
+		}
+		token = resp.GetNextPageToken()
+		if len(token) == 0 {
+			break
+		}
+	}
+
+	sort.Strings(ids)
+	return ids, nil
+}
@@ -0,0 +1,147 @@
+package activities
+
+import (
+	"encoding/csv"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/bcc-code/bcc-media-flows/paths"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.temporal.io/sdk/testsuite"
+)
+
+// cleanupFolder makes a folder of files of a size, modified a number of days ago.
+func cleanupFolder(t *testing.T, files map[string]int) paths.Path {
+	root := paths.New(paths.TestDrive, filepath.Join("generated", "cleanup", t.Name()))
+	require.NoError(t, os.RemoveAll(root.Local()))
+	t.Cleanup(func() { _ = os.RemoveAll(root.Local()) })
+
+	now := time.Now()
+	for name, days := range files {
+		file := filepath.Join(root.Local(), name)
+		require.NoError(t, os.MkdirAll(filepath.Dir(file), os.ModePerm))
+		require.NoError(t, os.WriteFile(file, make([]byte, 100), os.ModePerm))
+		modified := now.AddDate(0, 0, -days)
+		require.NoError(t, os.Chtimes(file, modified, modified))
+	}
+	return root
+}
+
+func Test_DeleteOldFiles(t *testing.T) {
+	root := cleanupFolder(t, map[string]int{
+		"old.mxf":                    20,
+		"young.mxf":                  2,
+		"readme.txt":                 30,
+		"workflows/running/old.mxf":  20,
+		"workflows/finished/old.mxf": 20,
+	})
+
+	env := (&testsuite.WorkflowTestSuite{}).NewTestActivityEnvironment()
+	env.RegisterActivity(Util)
+
+	report := root.Append("report.csv")
+	input := CleanupInput{
+		Root:      root,
+		OlderThan: time.Now().AddDate(0, 0, -14),
+		Exclude:   []paths.Path{root.Append("workflows/running")},
+		DryRun:    true,
+		Report:    &report,
+	}
+
+	value, err := env.ExecuteActivity(Util.DeleteOldFiles, input)
+	require.NoError(t, err)
+	var result CleanupFilesResult
+	require.NoError(t, value.Get(&result))
+	assert.Equal(t, CleanupFilesResult{Count: 2, Size: 200}, result)
+	assert.FileExists(t, filepath.Join(root.Local(), "old.mxf"), "a dry run deletes nothing")
+
+	f, err := os.Open(report.Local())
+	require.NoError(t, err)
+	defer f.Close()
+	rows, err := csv.NewReader(f).ReadAll()
+	require.NoError(t, err)
+	require.Len(t, rows, 3)
+	assert.Equal(t, []string{"path", "size", "modified", "reason"}, rows[0])
+	assert.Equal(t, CleanupReasonAge, rows[1][3])
+
+	input.DryRun = false
+	input.Report = nil
+	_, err = env.ExecuteActivity(Util.DeleteOldFiles, input)
+	require.NoError(t, err)
+	assert.NoFileExists(t, filepath.Join(root.Local(), "old.mxf"))
+	assert.NoFileExists(t, filepath.Join(root.Local(), "workflows/finished/old.mxf"))
+	assert.FileExists(t, filepath.Join(root.Local(), "workflows/running/old.mxf"))
+	assert.FileExists(t, filepath.Join(root.Local(), "young.mxf"))
+	assert.FileExists(t, filepath.Join(root.Local(), "readme.txt"))
+}
+
+func Test_FreeSpace(t *testing.T) {
+	root := cleanupFolder(t, map[string]int{
+		"a.mxf":                   10,
+		"b.mxf":                   5,
+		"c.mxf":                   1,
+		"ancient.mxf":             30,
+		"workflows/running/x.mxf": 12,
+	})
+
+	env := (&testsuite.WorkflowTestSuite{}).NewTestActivityEnvironment()
+	env.RegisterActivity(Util)
+
+	report := root.Append("report.csv")
+	value, err := env.ExecuteActivity(Util.FreeSpace, FreeSpaceInput{
+		Folders: []CleanupInput{{
+			Root:      root,
+			OlderThan: time.Now().AddDate(0, 0, -14),
+			Exclude:   []paths.Path{root.Append("workflows/running")},
+		}},
+		Size:   150,
+		Report: &report,
+	})
+	require.NoError(t, err)
+	var result CleanupFilesResult
+	require.NoError(t, value.Get(&result))
+
+	// Oldest first until enough is freed, leaving what is old enough to the age pass.
+	assert.Equal(t, CleanupFilesResult{Count: 2, Size: 200}, result)
+	assert.NoFileExists(t, filepath.Join(root.Local(), "a.mxf"))
+	assert.NoFileExists(t, filepath.Join(root.Local(), "b.mxf"))
+	assert.FileExists(t, filepath.Join(root.Local(), "c.mxf"))
+	assert.FileExists(t, filepath.Join(root.Local(), "ancient.mxf"))
+	assert.FileExists(t, filepath.Join(root.Local(), "workflows/running/x.mxf"))
+
+	// The files are younger than their retention, so what went is listed.
+	f, err := os.Open(report.Local())
+	require.NoError(t, err)
+	defer f.Close()
+	rows, err := csv.NewReader(f).ReadAll()
+	require.NoError(t, err)
+	require.Len(t, rows, 3)
+	assert.Equal(t, filepath.Join(root.Local(), "a.mxf"), rows[1][0])
+	assert.Equal(t, filepath.Join(root.Local(), "b.mxf"), rows[2][0])
+	assert.Equal(t, CleanupReasonSpace, rows[1][3])
+}
+
+func Test_DeleteEmptyDirectories_Exclude(t *testing.T) {
+	root := cleanupFolder(t, map[string]int{"keep.mxf": 1})
+	require.NoError(t, os.MkdirAll(filepath.Join(root.Local(), "workflows", "running"), os.ModePerm))
+	require.NoError(t, os.MkdirAll(filepath.Join(root.Local(), "workflows", "finished"), os.ModePerm))
+
+	env := (&testsuite.WorkflowTestSuite{}).NewTestActivityEnvironment()
+	env.RegisterActivity(Util)
+
+	_, err := env.ExecuteActivity(Util.DeleteEmptyDirectories, CleanupInput{
+		Root:    root,
+		Exclude: []paths.Path{root.Append("workflows/running")},
+	})
+	require.NoError(t, err)
+	assert.DirExists(t, filepath.Join(root.Local(), "workflows", "running"))
+	assert.NoDirExists(t, filepath.Join(root.Local(), "workflows", "finished"))
+}
+
+func Test_DiskUsage(t *testing.T) {
+	assert.Equal(t, 25.0, DiskUsage{Size: 400, Free: 100}.FreePercent())
+	assert.Zero(t, DiskUsage{}.FreePercent())
+}
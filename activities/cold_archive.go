@@ -0,0 +1,76 @@
+package activities
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/bcc-code/bcc-media-flows/paths"
+	"github.com/bcc-code/bcc-media-flows/services/s3"
+	"go.temporal.io/sdk/activity"
+	"go.temporal.io/sdk/temporal"
+)
+
+type ColdObjectInput struct {
+	// URI is the file as Vidispine names it on the cold archive storage, such as
+	// "s3://bucket/2024/01/02/file.mxf".
+	URI string
+}
+
+func (ua UtilActivities) coldObject(uri string) (*s3.Client, string, string, error) {
+	bucket, key, err := s3.ParseURI(uri)
+	if err != nil {
+		return nil, "", "", temporal.NewNonRetryableApplicationError(err.Error(), "S3_DESTINATION", err)
+	}
+	storage := paths.Current().ColdArchive()
+	if storage == nil {
+		err = errors.New("the storage registry has no cold archive")
+		return nil, "", "", temporal.NewNonRetryableApplicationError(err.Error(), "S3_NOT_CONFIGURED", err)
+	}
+	client, ok := ua.S3[storage.RcloneName()]
+	if !ok {
+		err = fmt.Errorf("no S3 credentials for %s, the rclone remote of the cold archive", storage.RcloneName())
+		return nil, "", "", temporal.NewNonRetryableApplicationError(err.Error(), "S3_NOT_CONFIGURED", err)
+	}
+	return client, bucket, key, nil
+}
+
+// GetColdObject returns the size and storage class of a file in the cold archive.
+func (ua UtilActivities) GetColdObject(ctx context.Context, input ColdObjectInput) (*s3.ObjectStatus, error) {
+	activity.GetLogger(ctx).Info("Starting GetColdObject", "uri", input.URI)
+
+	client, bucket, key, err := ua.coldObject(input.URI)
+	if err != nil {
+		return nil, err
+	}
+	return client.HeadObject(ctx, bucket, key)
+}
+
+// StartColdRestore asks S3 to restore a file in the cold archive, unless it can be read
+// already or is being restored, and returns how far the restore has come. S3 restores
+// in hours, so the workflow asks again until the file is readable.
+func (ua UtilActivities) StartColdRestore(ctx context.Context, input ColdObjectInput) (*s3.ObjectStatus, error) {
+	log := activity.GetLogger(ctx)
+	log.Info("Starting StartColdRestore", "uri", input.URI)
+
+	client, bucket, key, err := ua.coldObject(input.URI)
+	if err != nil {
+		return nil, err
+	}
+
+	status, err := client.HeadObject(ctx, bucket, key)
+	if err != nil {
+		return nil, err
+	}
+	if status.Readable() || status.Restoring {
+		return status, nil
+	}
+
+	log.Info("Restoring", "uri", input.URI, "tier", ua.ColdArchive.RestoreTier(), "days", ua.ColdArchive.RestoreDays())
+	err = client.RestoreObject(ctx, bucket, key, ua.ColdArchive.RestoreDays(), ua.ColdArchive.RestoreTier())
+	if err != nil && !errors.Is(err, s3.ErrRestoreInProgress) {
+		return nil, err
+	}
+	status.Restoring = true
+	return status, nil
+}
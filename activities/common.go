@@ -2,14 +2,42 @@ package activities
 
 import (
 	"context"
+	"sync"
 	"time"
 
+	"github.com/bcc-code/bcc-media-flows/analytics"
 	"github.com/bcc-code/bcc-media-flows/services/ffmpeg"
 	"go.temporal.io/sdk/activity"
 )
 
+// registerProgressCallback heartbeats ffmpeg progress while the activity runs and, once
+// the returned channel is closed, reports the speed the run averaged to analytics.
 func registerProgressCallback(ctx context.Context) (chan struct{}, func(ffmpeg.Progress)) {
-	return newHeartBeater[ffmpeg.Progress](ctx)
+	heartbeatStop, heartbeat := newHeartBeater[ffmpeg.Progress](ctx)
+
+	var lock sync.Mutex
+	var last ffmpeg.Progress
+	cb := func(p ffmpeg.Progress) {
+		lock.Lock()
+		last = p
+		lock.Unlock()
+		heartbeat(p)
+	}
+
+	stopChan := make(chan struct{})
+	go func() {
+		<-stopChan
+		close(heartbeatStop)
+
+		lock.Lock()
+		speed, ok := last.SpeedRatio()
+		lock.Unlock()
+		if ok && activity.IsActivity(ctx) {
+			analytics.GetService().FFmpegFinished(activity.GetInfo(ctx).ActivityType.Name, speed)
+		}
+	}()
+
+	return stopChan, cb
 }
 
 func newHeartBeater[T any](ctx context.Context) (chan struct{}, func(T)) {
@@ -2,31 +2,24 @@ package activities
 
 import (
 	"context"
-	"errors"
-	"strings"
 
 	"github.com/bcc-code/bcc-media-flows/services/emails"
+	"github.com/bcc-code/bcc-media-flows/services/notifications/routing"
+	"go.temporal.io/sdk/activity"
 )
 
-func (ua UtilActivities) SendEmail(_ context.Context, msg emails.Message) (any, error) {
-	var errs []error
-	for _, email := range msg.To {
-		// Skip blank recipients. Recipient lists are built by splitting a
-		// comma-separated field, so trailing commas or an empty sender field
-		// yield empty entries that SendGrid rejects outright.
-		email = strings.TrimSpace(email)
-		if email == "" {
-			continue
-		}
+// SendEmail sends the message where the notification routes say, which is its
+// recipients unless a route says otherwise.
+func (ua UtilActivities) SendEmail(ctx context.Context, msg emails.Message) (any, error) {
+	_, err := routing.Default().Send(ctx, routing.Notification{
+		Event:      eventOrMessage(msg.Event),
+		Workflow:   activity.GetInfo(ctx).WorkflowType.Name,
+		WorkflowID: activity.GetInfo(ctx).WorkflowExecution.ID,
+		Via:        routing.TypeEmail,
+		Subject:    msg.Subject,
+		Text:       msg.PlainText,
+		HTML:       msg.HTML,
+	}, routing.Channel{Type: routing.TypeEmail, To: msg.To})
 
-		if err := emails.Send(email, msg.Subject, msg.PlainText, msg.HTML); err != nil {
-			errs = append(errs, err)
-		}
-	}
-
-	if len(errs) > 0 {
-		return nil, errors.Join(errs...)
-	}
-
-	return nil, nil
+	return nil, err
 }
@@ -6,11 +6,9 @@ import (
 	"io"
 	"os"
 	"path/filepath"
-	"strings"
 	"time"
 
 	"github.com/bcc-code/bcc-media-flows/paths"
-	"github.com/bcc-code/bcc-media-flows/utils"
 	"github.com/samber/lo"
 	"go.temporal.io/sdk/activity"
 )
@@ -248,59 +246,3 @@ func (ua UtilActivities) WaitForFile(ctx context.Context, input FileInput) (bool
 		time.Sleep(time.Second * 5)
 	}
 }
-
-type CleanupInput struct {
-	Root      paths.Path
-	OlderThan time.Time
-}
-
-func (ua UtilActivities) DeleteEmptyDirectories(ctx context.Context, input CleanupInput) ([]string, error) {
-	log := activity.GetLogger(ctx)
-	activity.RecordHeartbeat(ctx, "DeleteEmptyDirectories")
-	log.Info("Starting DeleteEmptyDirectoriesActivity")
-
-	empty, err := utils.GetEmptyDirs(input.Root.Local())
-	if err != nil {
-		return nil, err
-	}
-
-	deleted := []string{}
-	for _, dir := range empty {
-		err := os.Remove(dir)
-		if err != nil {
-			return deleted, err
-		}
-		deleted = append(deleted, dir)
-	}
-
-	return deleted, nil
-}
-
-func (ua UtilActivities) DeleteOldFiles(ctx context.Context, input CleanupInput) ([]string, error) {
-	log := activity.GetLogger(ctx)
-	activity.RecordHeartbeat(ctx, "DeleteOldFiles")
-	log.Info("Starting DeleteOldFilesActivity")
-
-	deleted := []string{}
-	files, err := utils.GetOldFiles(input.Root.Local(), input.OlderThan)
-	if err != nil {
-		return deleted, err
-	}
-
-	for _, file := range files {
-
-		if strings.HasSuffix(file, "readme.txt") {
-			// We do not delete readme files as they provide info about what the folders do and also keep the
-			// the folders from being deleted
-			continue
-		}
-
-		err := os.Remove(file)
-		if err != nil {
-			return deleted, err
-		}
-		deleted = append(deleted, file)
-	}
-
-	return deleted, err
-}
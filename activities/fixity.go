@@ -0,0 +1,89 @@
+package activities
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/bcc-code/bcc-media-flows/paths"
+	"github.com/bcc-code/bcc-media-flows/services/fixity"
+	"github.com/bcc-code/bcc-media-flows/services/rclone"
+	"go.temporal.io/sdk/activity"
+)
+
+func (ua UtilActivities) ComputeChecksums(ctx context.Context, input FileInput) (*fixity.Checksums, error) {
+	log := activity.GetLogger(ctx)
+	log.Info("Starting ComputeChecksums", "path", input.Path.Local())
+
+	stop, progress := newHeartBeater[int64](ctx)
+	defer close(stop)
+
+	sums, err := fixity.Compute(ctx, input.Path.Local(), progress)
+	if err != nil {
+		return nil, err
+	}
+	return &sums, nil
+}
+
+type VerifyChecksumsInput struct {
+	Path      paths.Path
+	Checksums fixity.Checksums
+}
+
+// VerifyChecksums checks a file the worker can read against the checksums it was
+// recorded with.
+func (ua UtilActivities) VerifyChecksums(ctx context.Context, input VerifyChecksumsInput) (*fixity.Checksums, error) {
+	log := activity.GetLogger(ctx)
+	log.Info("Starting VerifyChecksums", "path", input.Path.Local())
+
+	stop, progress := newHeartBeater[int64](ctx)
+	defer close(stop)
+
+	sums, err := fixity.Verify(ctx, input.Path.Local(), input.Checksums, progress)
+	if err != nil {
+		return nil, fixity.ApplicationError(err)
+	}
+	return &sums, nil
+}
+
+// RcloneVerifyChecksums checks a file on a drive only rclone reaches, such as S3,
+// against the MD5 it was recorded with. rclone downloads the file to hash it.
+func (ua UtilActivities) RcloneVerifyChecksums(ctx context.Context, input VerifyChecksumsInput) (*fixity.Checksums, error) {
+	log := activity.GetLogger(ctx)
+	log.Info("Starting RcloneVerifyChecksums", "path", input.Path.Rclone())
+
+	if input.Checksums.MD5 == "" {
+		return nil, fixity.ErrNotSet
+	}
+
+	job, err := rclone.StartHashsum(ctx, input.Path.Rclone(), "md5")
+	if err != nil {
+		return nil, err
+	}
+
+	for {
+		md5, err := rclone.HashsumResult(ctx, job.JobID)
+		if errors.Is(err, rclone.ErrHashsumRunning) {
+			activity.RecordHeartbeat(ctx, job.JobID)
+			select {
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			case <-time.After(10 * time.Second):
+			}
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		actual := fixity.Checksums{MD5: md5}
+		if !input.Checksums.Match(actual) {
+			return nil, fixity.ApplicationError(&fixity.MismatchError{
+				File:     input.Path.Rclone(),
+				Expected: input.Checksums,
+				Actual:   actual,
+			})
+		}
+		return &actual, nil
+	}
+}
@@ -129,76 +129,6 @@ func TestCheckJobStatusActivity_NotFoundIsAnError(t *testing.T) {
 	assert.Contains(t, err.Error(), "404")
 }
 
-func TestGetAudioDiff_ConvertsSecondsToMilliseconds(t *testing.T) {
-	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
-		w.Header().Set("Content-Type", "application/json")
-		_, _ = w.Write([]byte(`{"offset":1.234}`))
-	}))
-	t.Cleanup(server.Close)
-	setEnv(t, "SYNC_SERVICE_URL", server.URL)
-
-	env := activityEnv(t)
-	ua := UtilActivities{}
-	env.RegisterActivity(ua.GetAudioDiff)
-
-	val, err := env.ExecuteActivity(ua.GetAudioDiff, GetAudioDiffParams{
-		ReferenceFile: "/mnt/ref.wav",
-		TargetFile:    "/mnt/target.wav",
-	})
-	require.NoError(t, err)
-
-	var result GetAudioDiffResult
-	require.NoError(t, val.Get(&result))
-	assert.Equal(t, 1234, result.Difference)
-}
-
-func TestGetAudioDiff_ServerErrorIsNotAZeroOffset(t *testing.T) {
-	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
-		w.WriteHeader(http.StatusInternalServerError)
-		_, _ = w.Write([]byte(`{"detail":"boom"}`))
-	}))
-	t.Cleanup(server.Close)
-	setEnv(t, "SYNC_SERVICE_URL", server.URL)
-
-	env := activityEnv(t)
-	ua := UtilActivities{}
-	env.RegisterActivity(ua.GetAudioDiff)
-
-	_, err := env.ExecuteActivity(ua.GetAudioDiff, GetAudioDiffParams{
-		ReferenceFile: "/mnt/ref.wav",
-		TargetFile:    "/mnt/target.wav",
-	})
-
-	require.Error(t, err)
-	assert.Contains(t, err.Error(), "500")
-}
-
-func TestGetAudioDiff_SendsBothFiles(t *testing.T) {
-	var body string
-	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		raw := make([]byte, r.ContentLength)
-		_, _ = r.Body.Read(raw)
-		body = string(raw)
-		w.Header().Set("Content-Type", "application/json")
-		_, _ = w.Write([]byte(`{"offset":0}`))
-	}))
-	t.Cleanup(server.Close)
-	setEnv(t, "SYNC_SERVICE_URL", server.URL)
-
-	env := activityEnv(t)
-	ua := UtilActivities{}
-	env.RegisterActivity(ua.GetAudioDiff)
-
-	_, err := env.ExecuteActivity(ua.GetAudioDiff, GetAudioDiffParams{
-		ReferenceFile: "/mnt/ref.wav",
-		TargetFile:    "/mnt/target.wav",
-	})
-
-	require.NoError(t, err)
-	assert.Contains(t, body, `"reference_file":"/mnt/ref.wav"`)
-	assert.Contains(t, body, `"target_file":"/mnt/target.wav"`)
-}
-
 func TestServiceClientsHaveTimeouts(t *testing.T) {
 	assert.Positive(t, shortServiceClient().GetClient().Timeout)
 }
@@ -0,0 +1,27 @@
+package activities
+
+import (
+	"context"
+
+	"github.com/bcc-code/bcc-media-flows/paths"
+	"github.com/bcc-code/bcc-media-flows/services/ffmpeg"
+	"go.temporal.io/sdk/activity"
+)
+
+type MediaQCParams struct {
+	FilePath paths.Path
+}
+
+// MediaQC looks for black and frozen video, interlacing in video flagged progressive,
+// and silence, dropouts, loudness and clipping in each audio channel of a file, and
+// reports when they are.
+func (va VideoActivities) MediaQC(ctx context.Context, input MediaQCParams) (*ffmpeg.QCReport, error) {
+	log := activity.GetLogger(ctx)
+	activity.RecordHeartbeat(ctx, "MediaQC")
+	log.Info("Starting MediaQCActivity")
+
+	stop, progressCallback := registerProgressCallback(ctx)
+	defer close(stop)
+
+	return ffmpeg.AnalyzeQC(input.FilePath.Local(), progressCallback)
+}
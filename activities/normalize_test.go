@@ -7,6 +7,7 @@ import (
 	"github.com/bcc-code/bcc-media-flows/common"
 	"github.com/bcc-code/bcc-media-flows/paths"
 	"github.com/bcc-code/bcc-media-flows/services/transcode"
+	"github.com/bcc-code/bcc-media-flows/utils"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/suite"
 	"go.temporal.io/sdk/testsuite"
@@ -31,7 +32,7 @@ func (s *NormalizeTestSuite) TestAnalyzeEBUR128Activity() {
 
 	// Use the existing test WAV file from the ffmpeg package
 	testFile := paths.MustParse("./testdata/generated/ebur128_test.wav")
-	err := transcode.GenerateToneFile(440, 3, 48000, "01:00:00:00", testFile)
+	err := transcode.GenerateToneFile(440, 3, 48000, utils.MustParseTimecode("01:00:00:00", utils.Rate25), testFile)
 	assert.NoError(t, err)
 
 	res, err := s.env.ExecuteActivity(aa.AnalyzeEBUR128Activity, AnalyzeEBUR128Params{
@@ -57,7 +58,7 @@ func (s *NormalizeTestSuite) TestAnalyzeEBUR128Activity_SuggestsAdjustment() {
 
 	// Generate a quiet tone (low amplitude sine generates quiet audio)
 	testFile := paths.MustParse("./testdata/generated/ebur128_quiet.wav")
-	err := transcode.GenerateToneFile(440, 3, 48000, "01:00:00:00", testFile)
+	err := transcode.GenerateToneFile(440, 3, 48000, utils.MustParseTimecode("01:00:00:00", utils.Rate25), testFile)
 	assert.NoError(t, err)
 
 	res, err := s.env.ExecuteActivity(aa.AnalyzeEBUR128Activity, AnalyzeEBUR128Params{
@@ -84,7 +85,7 @@ func (s *NormalizeTestSuite) TestAdjustAudioLevelActivity() {
 	testFile := paths.MustParse("./testdata/generated/adjust_level_input.wav")
 	outputDir := paths.MustParse("./testdata/generated/adjust_level_output/")
 	os.MkdirAll(outputDir.Local(), 0755)
-	err := transcode.GenerateToneFile(440, 3, 48000, "01:00:00:00", testFile)
+	err := transcode.GenerateToneFile(440, 3, 48000, utils.MustParseTimecode("01:00:00:00", utils.Rate25), testFile)
 	assert.NoError(t, err)
 
 	res, err := s.env.ExecuteActivity(aa.AdjustAudioLevelActivity, AdjustAudioLevelParams{
@@ -112,7 +113,7 @@ func (s *NormalizeTestSuite) TestNormalizeAudioActivity_WithAdjustment() {
 	testFile := paths.MustParse("./testdata/generated/normalize_input.wav")
 	outputDir := paths.MustParse("./testdata/generated/normalize_output/")
 	os.MkdirAll(outputDir.Local(), 0755)
-	err := transcode.GenerateToneFile(440, 3, 48000, "01:00:00:00", testFile)
+	err := transcode.GenerateToneFile(440, 3, 48000, utils.MustParseTimecode("01:00:00:00", utils.Rate25), testFile)
 	assert.NoError(t, err)
 
 	res, err := s.env.ExecuteActivity(aa.NormalizeAudioActivity, NormalizeAudioParams{
@@ -2,6 +2,7 @@ package activities
 
 import (
 	cantemoactivities "github.com/bcc-code/bcc-media-flows/activities/cantemo"
+	"github.com/bcc-code/bcc-media-flows/services/s3"
 	"github.com/bcc-code/bcc-media-flows/services/subtrans"
 	"github.com/bcc-code/bcc-media-flows/services/vidispine"
 	"reflect"
@@ -11,6 +12,7 @@ import (
 	platform_activities "github.com/bcc-code/bcc-media-flows/activities/platform"
 	vsactivity "github.com/bcc-code/bcc-media-flows/activities/vidispine"
 	"github.com/bcc-code/bcc-media-flows/environment"
+	"go.temporal.io/sdk/client"
 )
 
 func GetMethodNames(of any) []string {
@@ -33,12 +35,27 @@ var Video = VideoActivities{}
 type UtilActivities struct {
 	Vidispine vidispine.Client
 	Subtrans  *subtrans.Client
+	// S3 are the clients for direct uploads, by the name of the rclone remote that
+	// reaches the same buckets.
+	S3 map[string]*s3.Client
+	// ColdArchive is how archived originals are restored, through the client in S3 of
+	// the rclone remote of the cold archive storage.
+	ColdArchive environment.ColdArchive
+	// Temporal is the client the worker runs with, for the activities that look at
+	// other workflows.
+	Temporal client.Client
+	// CallbackSecrets sign callbacks, by the SecretID the callback names.
+	CallbackSecrets map[string]string
 }
 
 // Util is replaced at boot with clients built from the configuration.
 var Util = &UtilActivities{}
 
-type LiveActivities struct{}
+type LiveActivities struct {
+	// Reapers are the Reaper recorders by live ingest source. A source without one
+	// records on the default Reaper.
+	Reapers map[string]string
+}
 
 var Live = LiveActivities{}
 
@@ -77,6 +77,7 @@ func TestEveryFFmpegActivityIsOnAnFFmpegQueue(t *testing.T) {
 
 	require.Contains(t, found, "CropShortActivity",
 		"the scan found no known ffmpeg user, so it is not looking at anything")
+	require.Contains(t, found, "GetAudioDiff", "audiosync runs ffmpeg, so its activity is an ffmpeg user")
 
 	for method, receiver := range found {
 		if _, allowed := ffmpegOnWorkerQueue[method]; allowed {
@@ -89,8 +90,7 @@ func TestEveryFFmpegActivityIsOnAnFFmpegQueue(t *testing.T) {
 }
 
 // ffmpegUsingActivityMethods returns activity methods whose body references the
-// ffmpeg or transcode service packages, keyed by method name and mapped to the
-// receiver type they hang off.
+// media packages, keyed by method name and mapped to the receiver type they hang off.
 func ffmpegUsingActivityMethods(t *testing.T) map[string]string {
 	t.Helper()
 
@@ -130,15 +130,23 @@ func ffmpegUsingActivityMethods(t *testing.T) map[string]string {
 	return found
 }
 
-// mediaPackageNames returns the local names the file imports the ffmpeg and
-// transcode service packages under.
+// mediaPackagePaths are the service packages that run ffmpeg or ffprobe. A package
+// that calls one of these on its own behalf, such as audiosync, is listed too, as the
+// scan does not follow calls into other packages.
+var mediaPackagePaths = []string{
+	"github.com/bcc-code/bcc-media-flows/services/ffmpeg",
+	"github.com/bcc-code/bcc-media-flows/services/transcode",
+	"github.com/bcc-code/bcc-media-flows/services/audiosync",
+}
+
+// mediaPackageNames returns the local names the file imports the media packages
+// under.
 func mediaPackageNames(file *ast.File) map[string]bool {
 	names := map[string]bool{}
 
 	for _, imp := range file.Imports {
 		path := strings.Trim(imp.Path.Value, `"`)
-		if path != "github.com/bcc-code/bcc-media-flows/services/ffmpeg" &&
-			path != "github.com/bcc-code/bcc-media-flows/services/transcode" {
+		if !slices.Contains(mediaPackagePaths, path) {
 			continue
 		}
 
@@ -108,7 +108,8 @@ func (ua UtilActivities) RcloneCopyDir(ctx context.Context, input RcloneCopyDirI
 	activity.RecordHeartbeat(ctx, "Rclone CopyDir")
 	activity.GetLogger(ctx).Debug(fmt.Sprintf("Rclone CopyDir: %s -> %s", input.Source, input.Destination))
 
-	res, err := rclone.CopyDir(ctx, input.Source, input.Destination)
+	ctx = rclone.WithWorkflow(ctx, activity.GetInfo(ctx).WorkflowExecution.ID)
+	res, err := rclone.CopyDir(ctx, input.Source, input.Destination, input.Priority)
 	if err != nil {
 		return 0, err
 	}
@@ -140,6 +141,7 @@ func (ua UtilActivities) RcloneMoveFile(ctx context.Context, input RcloneFileInp
 	srcFs, srcRemote := input.Source.RcloneFsRemote()
 	dstFs, dstRemote := input.Destination.RcloneFsRemote()
 
+	ctx = rclone.WithWorkflow(ctx, activity.GetInfo(ctx).WorkflowExecution.ID)
 	res, err := rclone.MoveFile(
 		ctx,
 		srcFs, srcRemote,
@@ -160,6 +162,7 @@ func (ua UtilActivities) RcloneCopyFile(ctx context.Context, input RcloneFileInp
 	srcFs, srcRemote := input.Source.RcloneFsRemote()
 	dstFs, dstRemote := input.Destination.RcloneFsRemote()
 
+	ctx = rclone.WithWorkflow(ctx, activity.GetInfo(ctx).WorkflowExecution.ID)
 	res, err := rclone.CopyFile(
 		ctx,
 		srcFs, srcRemote,
@@ -16,10 +16,16 @@ import (
 // point these activities at a stub.
 var reaperBaseUrl = "http://100.123.200.12:8081"
 
-func reaperClient() *resty.Client {
+// reaperClient talks to the Reaper that records a live ingest source.
+func (l LiveActivities) reaperClient(source string) *resty.Client {
+	baseURL := reaperBaseUrl
+	if url, ok := l.Reapers[source]; ok {
+		baseURL = url
+	}
+
 	return httpx.New(httpx.Config{
 		Service: "reaper",
-		BaseURL: reaperBaseUrl,
+		BaseURL: baseURL,
 	})
 }
 
@@ -34,10 +40,20 @@ func decodeReaperBody(resp *resty.Response, target any) error {
 	return nil
 }
 
-func (l LiveActivities) StartReaper(ctx context.Context, _ any) (string, error) {
+type StartReaperParams struct {
+	// Source is the live ingest source to record. Empty records on the default Reaper.
+	Source string
+}
+
+func (l LiveActivities) StartReaper(ctx context.Context, params *StartReaperParams) (string, error) {
+	source := ""
+	if params != nil {
+		source = params.Source
+	}
+
 	// 409 means a session is already recording, and its id is the answer to the
 	// question this activity asks.
-	resp, err := httpx.Tolerating(reaperClient().R().SetContext(ctx), http.StatusConflict).
+	resp, err := httpx.Tolerating(l.reaperClient(source).R().SetContext(ctx), http.StatusConflict).
 		Get("/start")
 	if err != nil {
 		return "", err
@@ -61,8 +77,18 @@ type ReaperResult struct {
 	Files []string
 }
 
-func (l LiveActivities) StopReaper(ctx context.Context, _ any) (*ReaperResult, error) {
-	resp, err := reaperClient().R().SetContext(ctx).Get("/stop")
+type StopReaperParams struct {
+	// Source is the live ingest source to stop recording. Empty stops the default Reaper.
+	Source string
+}
+
+func (l LiveActivities) StopReaper(ctx context.Context, params *StopReaperParams) (*ReaperResult, error) {
+	source := ""
+	if params != nil {
+		source = params.Source
+	}
+
+	resp, err := l.reaperClient(source).R().SetContext(ctx).Get("/stop")
 	if err != nil {
 		return nil, err
 	}
@@ -77,10 +103,12 @@ func (l LiveActivities) StopReaper(ctx context.Context, _ any) (*ReaperResult, e
 
 type ListReaperFilesParams struct {
 	SessionID string
+	// Source is the live ingest source the session recorded.
+	Source string
 }
 
 func (l LiveActivities) ListReaperFiles(ctx context.Context, params *ListReaperFilesParams) (*ReaperResult, error) {
-	resp, err := reaperClient().R().
+	resp, err := l.reaperClient(params.Source).R().
 		SetContext(ctx).
 		SetQueryParam("session_id", params.SessionID).
 		Get("/files")
@@ -171,5 +171,44 @@ func TestListReaperFiles_NonJSONBodyIsAnError(t *testing.T) {
 }
 
 func TestReaperClient_HasATimeout(t *testing.T) {
-	assert.Positive(t, reaperClient().GetClient().Timeout)
+	assert.Positive(t, LiveActivities{}.reaperClient("").GetClient().Timeout)
+}
+
+// Two rooms recorded at once each have their own Reaper.
+func TestStartReaper_RecordsOnTheReaperOfTheSource(t *testing.T) {
+	reaperServer(t, func(w http.ResponseWriter, _ *http.Request) {
+		t.Error("the default reaper was asked to record a source that has its own")
+	})
+
+	roomB := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"session_id":"room-b-1"}`))
+	}))
+	t.Cleanup(roomB.Close)
+
+	live := LiveActivities{Reapers: map[string]string{"room-b": roomB.URL}}
+	sessionID, err := live.StartReaper(context.Background(), &StartReaperParams{Source: "room-b"})
+
+	require.NoError(t, err)
+	assert.Equal(t, "room-b-1", sessionID)
+}
+
+func TestStopReaper_StopsTheReaperOfTheSource(t *testing.T) {
+	reaperServer(t, func(w http.ResponseWriter, _ *http.Request) {
+		t.Error("the default reaper was asked to stop a source that has its own")
+	})
+
+	roomB := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/stop", r.URL.Path)
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`["/mnt/room-b.wav"]`))
+	}))
+	t.Cleanup(roomB.Close)
+
+	live := LiveActivities{Reapers: map[string]string{"room-b": roomB.URL}}
+	result, err := live.StopReaper(context.Background(), &StopReaperParams{Source: "room-b"})
+
+	require.NoError(t, err)
+	require.NotNil(t, result)
+	assert.Equal(t, []string{"/mnt/room-b.wav"}, result.Files)
 }
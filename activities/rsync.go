@@ -4,6 +4,7 @@ import (
 	"context"
 
 	"github.com/bcc-code/bcc-media-flows/paths"
+	"github.com/bcc-code/bcc-media-flows/services/fixity"
 	"github.com/bcc-code/bcc-media-flows/services/rsync"
 	"go.temporal.io/sdk/activity"
 )
@@ -11,10 +12,15 @@ import (
 type RsyncIncrementalCopyInput struct {
 	In  paths.Path
 	Out paths.Path
+	// Verify checks the copy against the source when it is done. Only the last copy of
+	// a growing file can be checked, as the source is still written to before that.
+	Verify bool
 }
 
 type RsyncIncrementalCopyResult struct {
 	Size int64
+	// Checksums of the copy, when it was verified.
+	Checksums *fixity.Checksums `json:",omitempty"`
 }
 
 func (l LiveActivities) RsyncIncrementalCopy(ctx context.Context, input RsyncIncrementalCopyInput) (*RsyncIncrementalCopyResult, error) {
@@ -29,6 +35,18 @@ func (l LiveActivities) RsyncIncrementalCopy(ctx context.Context, input RsyncInc
 		return nil, err
 	}
 
+	if input.Verify {
+		sums, err := fixity.Compute(ctx, input.In.Local(), nil)
+		if err != nil {
+			return nil, err
+		}
+		sums, err = fixity.Verify(ctx, input.Out.Local(), sums, nil)
+		if err != nil {
+			return nil, fixity.ApplicationError(err)
+		}
+		return &RsyncIncrementalCopyResult{Size: sums.Size, Checksums: &sums}, nil
+	}
+
 	// Stat the destination file to get its size
 	fileInfo, statErr := input.Out.Stat()
 	if statErr != nil {
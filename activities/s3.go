@@ -0,0 +1,57 @@
+package activities
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/bcc-code/bcc-media-flows/paths"
+	"github.com/bcc-code/bcc-media-flows/services/s3"
+
+	"go.temporal.io/sdk/activity"
+	"go.temporal.io/sdk/temporal"
+)
+
+type S3UploadFileInput struct {
+	Source paths.Path
+	// Destination is where rclone would copy the file to, such as
+	// "s3prod:vod-asset-ingest-prod/path/file.mxf".
+	Destination string
+}
+
+// S3UploadFile uploads a file to S3 directly rather than through rclone. It heartbeats
+// how far it has come, and a retried attempt resumes from there.
+func (ua UtilActivities) S3UploadFile(ctx context.Context, input S3UploadFileInput) (*s3.Result, error) {
+	logger := activity.GetLogger(ctx)
+	logger.Info("Starting S3UploadFile")
+
+	remote, bucket, key, err := s3.ParseDestination(input.Destination)
+	if err != nil {
+		return nil, temporal.NewNonRetryableApplicationError(err.Error(), "S3_DESTINATION", err)
+	}
+	client, ok := ua.S3[remote]
+	if !ok {
+		err = fmt.Errorf("no S3 credentials for %s", remote)
+		return nil, temporal.NewNonRetryableApplicationError(err.Error(), "S3_NOT_CONFIGURED", err)
+	}
+
+	var resume *s3.State
+	if activity.HasHeartbeatDetails(ctx) {
+		var state s3.State
+		if err := activity.GetHeartbeatDetails(ctx, &state); err == nil {
+			resume = &state
+			logger.Info(fmt.Sprintf("Resuming upload %s at %d of %d parts", state.UploadID, state.PartsDone, state.PartsAll))
+		}
+	}
+
+	result, err := client.Upload(ctx, input.Source.Local(), bucket, key, resume, func(state s3.State) {
+		activity.RecordHeartbeat(ctx, state)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if result.Resumed > 0 {
+		logger.Info(fmt.Sprintf("Uploaded %s, %d of %d parts by an earlier attempt", input.Destination, result.Resumed, result.Parts))
+	}
+	return result, nil
+}
@@ -0,0 +1,122 @@
+package activities
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/bcc-code/bcc-media-flows/paths"
+	"github.com/bcc-code/bcc-media-flows/services/subtitles"
+	"github.com/bcc-code/bcc-media-flows/services/vidispine/vscommon"
+
+	"go.temporal.io/sdk/activity"
+	"go.temporal.io/sdk/temporal"
+)
+
+type ConvertSubtitlesInput struct {
+	// Source is read in the format its extension names.
+	Source      paths.Path
+	Destination paths.Path
+	// Format is what Destination is written as. Left out, it is the format the
+	// extension of Destination names.
+	Format string
+	// Offset is how many seconds the cues are moved by, earlier when negative, such as
+	// when the subtitles are for a clip of a longer video.
+	Offset float64
+	// Language replaces the language of the source, for the formats that carry one.
+	Language string
+}
+
+type ConvertSubtitlesResult struct {
+	Path paths.Path
+	Cues int
+}
+
+// ConvertSubtitles converts a subtitle file to another format, keeping the timing of
+// the cues, or shifting it by the offset. Files that can not be read, or formats that
+// are not supported, fail without retries.
+func (ua UtilActivities) ConvertSubtitles(ctx context.Context, input ConvertSubtitlesInput) (*ConvertSubtitlesResult, error) {
+	log := activity.GetLogger(ctx)
+	log.Info("Starting ConvertSubtitlesActivity")
+
+	formatName := input.Format
+	if formatName == "" {
+		formatName = input.Destination.Ext()
+	}
+	format, err := subtitles.ParseFormat(formatName)
+	if err != nil {
+		return nil, temporal.NewNonRetryableApplicationError(err.Error(), "SUBTITLES_FORMAT", err)
+	}
+
+	subs, err := subtitles.ReadFile(input.Source.Local())
+	if err != nil {
+		err = fmt.Errorf("%s: %w", input.Source.Local(), err)
+		return nil, temporal.NewNonRetryableApplicationError(err.Error(), "SUBTITLES_PARSE", err)
+	}
+
+	if input.Offset != 0 {
+		subs = subs.Shift(time.Duration(input.Offset * float64(time.Second)))
+	}
+	if input.Language != "" {
+		subs.Language = input.Language
+	}
+
+	err = subs.WriteFile(input.Destination.Local(), format, 0644)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ConvertSubtitlesResult{
+		Path: input.Destination,
+		Cues: len(subs.Cues),
+	}, nil
+}
+
+type ValidateSubtitlesInput struct {
+	File paths.Path
+	// Language picks the QC rules the file is checked against.
+	Language string
+	// VXID is the asset the subtitles are for, which no cue should run past the end
+	// of. Duration, in seconds, is used instead when there is no VXID.
+	VXID     string
+	Duration float64
+}
+
+// ValidateSubtitles checks a subtitle file against the QC rules of its language:
+// reading speed, line length and count, the timing of the cues and the encoding of
+// the file. Whether the report blocks the import is up to the workflow.
+func (ua UtilActivities) ValidateSubtitles(ctx context.Context, input ValidateSubtitlesInput) (*subtitles.Report, error) {
+	log := activity.GetLogger(ctx)
+	log.Info("Starting ValidateSubtitlesActivity")
+
+	format, err := subtitles.ParseFormat(input.File.Ext())
+	if err != nil {
+		return nil, temporal.NewNonRetryableApplicationError(err.Error(), "SUBTITLES_FORMAT", err)
+	}
+
+	data, err := os.ReadFile(input.File.Local())
+	if err != nil {
+		return nil, err
+	}
+
+	duration := input.Duration
+	if input.VXID != "" {
+		meta, err := ua.Vidispine.GetMetadata(input.VXID)
+		if err != nil {
+			return nil, err
+		}
+		duration, err = strconv.ParseFloat(meta.Get(vscommon.FieldDurationSeconds, "0"), 64)
+		if err != nil {
+			return nil, fmt.Errorf("duration of %s: %w", input.VXID, err)
+		}
+	}
+
+	report := subtitles.Validate(data, format, subtitles.QCRulesFor(input.Language), time.Duration(duration*float64(time.Second)))
+	report.File = input.File.Base()
+	if input.Language != "" {
+		report.Language = input.Language
+	}
+	return &report, nil
+}
@@ -0,0 +1,75 @@
+package activities
+
+import (
+	"os"
+	"path/filepath"
+
+	"github.com/bcc-code/bcc-media-flows/paths"
+	"github.com/bcc-code/bcc-media-flows/services/subtitles"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func (s *UnitTestSuite) TestConvertSubtitles() {
+	t := s.T()
+
+	dir := "./testdata/generated/subtitles"
+	require.NoError(t, os.MkdirAll(dir, os.ModePerm))
+	source := filepath.Join(dir, "clip.srt")
+	require.NoError(t, os.WriteFile(source, []byte("1\n00:00:05,000 --> 00:00:07,000\nFørste\n\n2\n00:00:12,000 --> 00:00:14,500\n<i>Andre</i>\n"), os.ModePerm))
+
+	ua := UtilActivities{}
+	s.env.RegisterActivity(ua.ConvertSubtitles)
+	res, err := s.env.ExecuteActivity(ua.ConvertSubtitles, ConvertSubtitlesInput{
+		Source:      paths.MustParse(source),
+		Destination: paths.MustParse(filepath.Join(dir, "clip.vtt")),
+		Offset:      -10,
+	})
+	require.NoError(t, err)
+
+	result := &ConvertSubtitlesResult{}
+	require.NoError(t, res.Get(result))
+	assert.Equal(t, 1, result.Cues, "the cue that ended before the clip is dropped")
+
+	data, err := os.ReadFile("./" + result.Path.Local())
+	require.NoError(t, err)
+	assert.Equal(t, "WEBVTT\n\n00:00:02.000 --> 00:00:04.500\n<i>Andre</i>\n\n", string(data))
+}
+
+func (s *UnitTestSuite) TestConvertSubtitles_UnsupportedFormatIsNotRetried() {
+	ua := UtilActivities{}
+	s.env.RegisterActivity(ua.ConvertSubtitles)
+	_, err := s.env.ExecuteActivity(ua.ConvertSubtitles, ConvertSubtitlesInput{
+		Source:      paths.MustParse("./testdata/generated/subtitles/clip.srt"),
+		Destination: paths.MustParse("./testdata/generated/subtitles/clip.sub"),
+	})
+
+	assert.ErrorContains(s.T(), err, "SUBTITLES_FORMAT")
+}
+
+func (s *UnitTestSuite) TestValidateSubtitles() {
+	t := s.T()
+
+	dir := "./testdata/generated/subtitles"
+	require.NoError(t, os.MkdirAll(dir, os.ModePerm))
+	file := filepath.Join(dir, "qc.srt")
+	require.NoError(t, os.WriteFile(file, []byte("1\n00:00:01,000 --> 00:00:03,000\nGod morgen\n\n2\n00:00:08,000 --> 00:00:12,000\nFor sent\n"), os.ModePerm))
+
+	ua := UtilActivities{}
+	s.env.RegisterActivity(ua.ValidateSubtitles)
+	res, err := s.env.ExecuteActivity(ua.ValidateSubtitles, ValidateSubtitlesInput{
+		File:     paths.MustParse(file),
+		Language: "no",
+		Duration: 10,
+	})
+	require.NoError(t, err)
+
+	report := &subtitles.Report{}
+	require.NoError(t, res.Get(report))
+	assert.Equal(t, "no", report.Language)
+	assert.Equal(t, "qc.srt", report.File)
+	assert.Equal(t, 2, report.Cues)
+	require.Len(t, report.Issues, 1)
+	assert.Equal(t, subtitles.RuleBeyondAsset, report.Issues[0].Rule)
+	assert.False(t, report.Blocked, "the default policy only warns")
+}
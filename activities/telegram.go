@@ -2,9 +2,40 @@ package activities
 
 import (
 	"context"
+
+	"github.com/bcc-code/bcc-media-flows/services/notifications"
+	"github.com/bcc-code/bcc-media-flows/services/notifications/routing"
 	"github.com/bcc-code/bcc-media-flows/services/telegram"
+	"go.temporal.io/sdk/activity"
 )
 
-func (ua UtilActivities) SendTelegramMessage(_ context.Context, msg *telegram.Message) (*telegram.Message, error) {
-	return telegram.Send(msg)
+// SendTelegramMessage sends the message where the notification routes say, which is
+// its chat unless a route says otherwise. An edit goes to the message it edits.
+func (ua UtilActivities) SendTelegramMessage(ctx context.Context, msg *telegram.Message) (*telegram.Message, error) {
+	if msg.TelegramMessage != nil {
+		return telegram.Send(msg)
+	}
+
+	sent, err := routing.Default().Send(ctx, routing.Notification{
+		Event:      eventOrMessage(msg.Event),
+		Workflow:   activity.GetInfo(ctx).WorkflowType.Name,
+		WorkflowID: activity.GetInfo(ctx).WorkflowExecution.ID,
+		Via:        routing.TypeTelegram,
+		Chat:       telegram.ChatName(msg.Chat),
+		Text:       msg.Markdown,
+	}, routing.Channel{Type: routing.TypeTelegram, ChatID: msg.Chat.Value})
+
+	// Routed away from Telegram, there is no message to edit later; an edit then
+	// sends it anew.
+	if sent == nil {
+		return msg, err
+	}
+	return sent, err
+}
+
+func eventOrMessage(event string) string {
+	if event == "" {
+		return notifications.EventMessage
+	}
+	return event
 }
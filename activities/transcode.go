@@ -505,3 +505,25 @@ func (va VideoActivities) FixDurationActivity(ctx context.Context, input FixDura
 		OutputPath: input.OutputPath,
 	}, nil
 }
+
+func (va VideoActivities) PackageCMAF(ctx context.Context, input common.CMAFInput) (*common.CMAFResult, error) {
+	log := activity.GetLogger(ctx)
+	activity.RecordHeartbeat(ctx, "PackageCMAF")
+	log.Info("Starting PackageCMAF")
+
+	stopChan, progressCallback := registerProgressCallback(ctx)
+	defer close(stopChan)
+
+	return transcode.PackageCMAF(input, progressCallback)
+}
+
+func (va VideoActivities) CreateThumbnailSprites(ctx context.Context, input common.ThumbnailSpritesInput) (*common.ThumbnailSpritesResult, error) {
+	log := activity.GetLogger(ctx)
+	activity.RecordHeartbeat(ctx, "CreateThumbnailSprites")
+	log.Info("Starting CreateThumbnailSprites")
+
+	stopChan, progressCallback := registerProgressCallback(ctx)
+	defer close(stopChan)
+
+	return transcode.ThumbnailSprites(input, progressCallback)
+}
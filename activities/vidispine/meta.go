@@ -3,12 +3,16 @@ package vsactivity
 import (
 	"context"
 	"fmt"
+	"math/rand"
+	"sort"
 	"strings"
+	"time"
 
 	"github.com/bcc-code/bcc-media-flows/languages"
 	"github.com/bcc-code/bcc-media-flows/paths"
 	"github.com/bcc-code/bcc-media-flows/services/vidispine/vsapi"
 	"github.com/bcc-code/bcc-media-flows/services/vidispine/vscommon"
+	"github.com/samber/lo"
 	"go.temporal.io/sdk/activity"
 )
 
@@ -127,6 +131,119 @@ func (a Activities) SearchItemsByMetadataField(ctx context.Context, params Searc
 	return a.Client.SearchByMetadataField(params.Name, params.Value)
 }
 
+type SampleItemsWithFieldParams struct {
+	Field vscommon.FieldType
+	Count int
+	// Without leaves out the items with a value in any of these fields.
+	Without []vscommon.FieldType
+}
+
+// search is the search for the items to sample from, from a 1-based position.
+func (p SampleItemsWithFieldParams) search(first int) vsapi.ItemSearchParams {
+	search := vsapi.ItemSearchParams{HasField: p.Field.Value, First: first, Number: 1}
+	for _, field := range p.Without {
+		search.Operators = append(search.Operators, vsapi.SearchOperator{
+			Operation: "NOT",
+			Fields:    []vsapi.SearchField{{Name: field.Value, Values: []string{"*"}}},
+		})
+	}
+	return search
+}
+
+type ItemFieldValue struct {
+	VXID  string
+	Value string
+}
+
+// SampleItemsWithField picks items with a value in the field at random, up to Count of
+// them, and returns the value each has.
+func (a Activities) SampleItemsWithField(ctx context.Context, params SampleItemsWithFieldParams) ([]ItemFieldValue, error) {
+	log := activity.GetLogger(ctx)
+	log.Info("Starting SampleItemsWithField", "field", params.Field.Value, "count", params.Count)
+
+	found, err := a.Client.SearchItems(params.search(0))
+	if err != nil {
+		return nil, err
+	}
+
+	// Positions in the search results, which are 1-based.
+	picked := map[int]bool{}
+	for len(picked) < min(params.Count, found.Hits) {
+		picked[rand.Intn(found.Hits)+1] = true
+	}
+	positions := lo.Keys(picked)
+	sort.Ints(positions)
+
+	var sample []ItemFieldValue
+	for _, position := range positions {
+		result, err := a.Client.SearchItems(params.search(position))
+		if err != nil {
+			return nil, err
+		}
+		for _, item := range result.Items {
+			sample = append(sample, ItemFieldValue{VXID: item.ID, Value: item.Get(params.Field, "")})
+		}
+	}
+	return sample, nil
+}
+
+// SearchTime is how Vidispine dates are written in searches, such as "created", and
+// how the dates the workflows set are written.
+const SearchTime = "2006-01-02T15:04:05"
+
+type SearchArchiveCandidatesParams struct {
+	// UnusedSince is the cutoff: an item was last used before it, or, never used since
+	// it was recorded, created before it.
+	UnusedSince time.Time
+	// First is the 1-based position of the first item returned.
+	First  int
+	Number int
+}
+
+type ArchiveCandidates struct {
+	Hits  int
+	VXIDs []string
+}
+
+// SearchArchiveCandidates returns a page of the items unused since a time whose
+// originals are not archived. The archived ones are left out by the search, so a page
+// only has to be read again for the items left where they are.
+func (a Activities) SearchArchiveCandidates(ctx context.Context, params SearchArchiveCandidatesParams) (*ArchiveCandidates, error) {
+	log := activity.GetLogger(ctx)
+	log.Info("Starting SearchArchiveCandidates", "unusedSince", params.UnusedSince, "first", params.First)
+
+	cutoff := params.UnusedSince.UTC().Format(SearchTime)
+	used := vsapi.SearchField{Name: vscommon.FieldLastUsed.Value, Values: []string{"*"}}
+	result, err := a.Client.SearchItems(vsapi.ItemSearchParams{
+		Operators: []vsapi.SearchOperator{
+			{
+				Operation: "OR",
+				Ranges:    []vsapi.SearchRange{{Field: vscommon.FieldLastUsed.Value, Start: "1000-01-01", End: cutoff}},
+				Operators: []vsapi.SearchOperator{{
+					Operation: "AND",
+					Ranges:    []vsapi.SearchRange{{Field: "created", Start: "1000-01-01", End: cutoff}},
+					Operators: []vsapi.SearchOperator{{Operation: "NOT", Fields: []vsapi.SearchField{used}}},
+				}},
+			},
+			{
+				Operation: "NOT",
+				Fields:    []vsapi.SearchField{{Name: vscommon.FieldArchived.Value, Values: []string{"*"}}},
+			},
+		},
+		First:  params.First,
+		Number: params.Number,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	page := &ArchiveCandidates{Hits: result.Hits}
+	for _, item := range result.Items {
+		page.VXIDs = append(page.VXIDs, item.ID)
+	}
+	return page, nil
+}
+
 // UpdateAssetRelations attempts to find languages of related audio files and update the metadata
 // of this asset with the link
 func (a Activities) UpdateAssetRelations(ctx context.Context, params VXOnlyParam) ([]string, error) {
@@ -0,0 +1,32 @@
+package activities
+
+import (
+	"context"
+	"errors"
+
+	"github.com/bcc-code/bcc-media-flows/services/webhooks"
+	"go.temporal.io/sdk/activity"
+	"go.temporal.io/sdk/temporal"
+)
+
+type SendWebhookInput struct {
+	Callback webhooks.Callback
+	Event    webhooks.Event
+}
+
+// SendWebhook posts a workflow's outcome to its callback URL. An answer that a retry
+// would not change, such as 404, fails it without retrying.
+func (ua UtilActivities) SendWebhook(ctx context.Context, input SendWebhookInput) (any, error) {
+	activity.GetLogger(ctx).Info("Starting SendWebhook", "url", input.Callback.URL, "status", input.Event.Status)
+
+	err := webhooks.Send(ctx, input.Callback, ua.CallbackSecrets, input.Event)
+	if errors.Is(err, webhooks.ErrUnknownSecret) {
+		return nil, temporal.NewNonRetryableApplicationError(err.Error(), "WEBHOOK_SECRET_UNKNOWN", err)
+	}
+
+	var statusErr *webhooks.StatusError
+	if errors.As(err, &statusErr) && !statusErr.Retryable() {
+		return nil, temporal.NewNonRetryableApplicationError(err.Error(), "WEBHOOK_REFUSED", err)
+	}
+	return nil, err
+}
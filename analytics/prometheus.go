@@ -0,0 +1,165 @@
+package analytics
+
+import (
+	"net/http"
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const metricsNamespace = "bccflows"
+
+// PrometheusSink keeps what it is sent as Prometheus metrics, served by Handler.
+type PrometheusSink struct {
+	registry *prometheus.Registry
+
+	activitiesRunning *prometheus.GaugeVec
+	activityDuration  *prometheus.HistogramVec
+	activityFailures  *prometheus.CounterVec
+	workflowDuration  *prometheus.HistogramVec
+	workflowFailures  *prometheus.CounterVec
+	ffmpegSpeed       *prometheus.HistogramVec
+
+	rcloneBytes        prometheus.Counter
+	rcloneSpeed        prometheus.Gauge
+	rcloneTransferring prometheus.Gauge
+	rcloneErrors       prometheus.Gauge
+
+	lock            sync.Mutex
+	seenRclone      bool
+	lastRcloneBytes int64
+}
+
+// Activities and workflows here run from seconds to hours.
+var durationBuckets = prometheus.ExponentialBuckets(1, 2, 16)
+
+func NewPrometheusSink() *PrometheusSink {
+	p := &PrometheusSink{
+		registry: prometheus.NewRegistry(),
+
+		activitiesRunning: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: metricsNamespace,
+			Name:      "activities_running",
+			Help:      "Activities currently executing on this worker.",
+		}, []string{"activity", "queue"}),
+		activityDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: metricsNamespace,
+			Name:      "activity_duration_seconds",
+			Help:      "How long activities took to execute, successful or not.",
+			Buckets:   durationBuckets,
+		}, []string{"activity", "queue", "status"}),
+		activityFailures: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: metricsNamespace,
+			Name:      "activity_failures_total",
+			Help:      "Activity executions that returned an error.",
+		}, []string{"activity", "queue"}),
+		workflowDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: metricsNamespace,
+			Name:      "workflow_duration_seconds",
+			Help:      "How long workflows took, in workflow time, successful or not.",
+			Buckets:   durationBuckets,
+		}, []string{"workflow", "status"}),
+		workflowFailures: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: metricsNamespace,
+			Name:      "workflow_failures_total",
+			Help:      "Workflows that returned an error.",
+		}, []string{"workflow"}),
+		ffmpegSpeed: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: metricsNamespace,
+			Name:      "ffmpeg_speed_ratio",
+			Help:      "Average speed of ffmpeg runs, as a multiple of real time.",
+			Buckets:   []float64{0.25, 0.5, 1, 2, 4, 8, 16, 32, 64},
+		}, []string{"activity"}),
+
+		rcloneBytes: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: metricsNamespace,
+			Name:      "rclone_transferred_bytes_total",
+			Help:      "Bytes rclone has transferred.",
+		}),
+		rcloneSpeed: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: metricsNamespace,
+			Name:      "rclone_speed_bytes_per_second",
+			Help:      "Average speed of the transfers rclone is running.",
+		}),
+		rcloneTransferring: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: metricsNamespace,
+			Name:      "rclone_transfers_running",
+			Help:      "Transfers rclone is running.",
+		}),
+		rcloneErrors: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: metricsNamespace,
+			Name:      "rclone_errors",
+			Help:      "Errors rclone has reported since it started.",
+		}),
+	}
+
+	p.registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		p.activitiesRunning,
+		p.activityDuration,
+		p.activityFailures,
+		p.workflowDuration,
+		p.workflowFailures,
+		p.ffmpegSpeed,
+		p.rcloneBytes,
+		p.rcloneSpeed,
+		p.rcloneTransferring,
+		p.rcloneErrors,
+	)
+
+	return p
+}
+
+// Handler serves the metrics in the Prometheus exposition format.
+func (p *PrometheusSink) Handler() http.Handler {
+	return promhttp.HandlerFor(p.registry, promhttp.HandlerOpts{Registry: p.registry})
+}
+
+func (p *PrometheusSink) ActivityStarted(event ActivityEvent) {
+	p.activitiesRunning.WithLabelValues(event.ActivityName, event.Queue).Inc()
+}
+
+func (p *PrometheusSink) ActivityFinished(event ActivityEvent) {
+	p.activitiesRunning.WithLabelValues(event.ActivityName, event.Queue).Dec()
+	p.activityDuration.WithLabelValues(event.ActivityName, event.Queue, event.Status).Observe(event.Duration.Seconds())
+	if event.Status == StatusFailure {
+		p.activityFailures.WithLabelValues(event.ActivityName, event.Queue).Inc()
+	}
+}
+
+func (p *PrometheusSink) WorkflowStarted(WorkflowEvent) {}
+
+func (p *PrometheusSink) WorkflowFinished(event WorkflowEvent) {
+	p.workflowDuration.WithLabelValues(event.WorkflowName, event.Status).Observe(event.Duration.Seconds())
+	if event.Status == StatusFailure {
+		p.workflowFailures.WithLabelValues(event.WorkflowName).Inc()
+	}
+}
+
+func (p *PrometheusSink) FFmpegFinished(activityName string, speed float64) {
+	p.ffmpegSpeed.WithLabelValues(activityName).Observe(speed)
+}
+
+func (p *PrometheusSink) RcloneStats(stats RcloneStats) {
+	p.rcloneSpeed.Set(stats.Speed)
+	p.rcloneTransferring.Set(float64(stats.Transferring))
+	p.rcloneErrors.Set(float64(stats.Errors))
+
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	// rclone counts from its own start, so a smaller total means it restarted. What it
+	// had done before this worker first asked is not counted.
+	delta := stats.Bytes - p.lastRcloneBytes
+	if delta < 0 {
+		delta = stats.Bytes
+	}
+	if p.seenRclone {
+		p.rcloneBytes.Add(float64(delta))
+	}
+	p.seenRclone = true
+	p.lastRcloneBytes = stats.Bytes
+}
@@ -0,0 +1,69 @@
+package analytics
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func scrape(t *testing.T, sink *PrometheusSink) string {
+	t.Helper()
+
+	rec := httptest.NewRecorder()
+	sink.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+	return rec.Body.String()
+}
+
+func TestPrometheusSink_ActivitiesAndWorkflows(t *testing.T) {
+	sink := NewPrometheusSink()
+	s := &Service{}
+	s.AddSink(sink)
+
+	s.ActivityStarted("TranscodeToH264", "transcode", "wf-1")
+	s.ActivityFinished("TranscodeToH264", "worker", "transcode", "wf-1", "Success", 3000)
+	s.ActivityStarted("TranscodeToH264", "transcode", "wf-2")
+	s.ActivityFinished("TranscodeToH264", "worker", "transcode", "wf-2", StatusFailure, 500)
+	s.WorkflowFinished("VXExport", "wf-1", "", StatusFailure, 60000)
+
+	metrics := scrape(t, sink)
+
+	assert.Contains(t, metrics, `bccflows_activities_running{activity="TranscodeToH264",queue="transcode"} 0`)
+	assert.Contains(t, metrics, `bccflows_activity_duration_seconds_count{activity="TranscodeToH264",queue="transcode",status="Success"} 1`)
+	assert.Contains(t, metrics, `bccflows_activity_duration_seconds_sum{activity="TranscodeToH264",queue="transcode",status="Success"} 3`)
+	assert.Contains(t, metrics, `bccflows_activity_failures_total{activity="TranscodeToH264",queue="transcode"} 1`)
+	assert.Contains(t, metrics, `bccflows_workflow_duration_seconds_sum{status="Failure",workflow="VXExport"} 60`)
+	assert.Contains(t, metrics, `bccflows_workflow_failures_total{workflow="VXExport"} 1`)
+}
+
+func TestPrometheusSink_FFmpegAndRclone(t *testing.T) {
+	sink := NewPrometheusSink()
+	s := &Service{}
+	s.AddSink(sink)
+
+	s.FFmpegFinished("TranscodeToH264", 2.5)
+
+	// The first report is the baseline; only what rclone moves after it counts.
+	s.RcloneStats(RcloneStats{Bytes: 1000, Speed: 10, Transferring: 2})
+	s.RcloneStats(RcloneStats{Bytes: 1500, Speed: 20, Transferring: 1})
+	// rclone restarted.
+	s.RcloneStats(RcloneStats{Bytes: 200, Speed: 5, Transferring: 1, Errors: 1})
+
+	metrics := scrape(t, sink)
+
+	assert.Contains(t, metrics, `bccflows_ffmpeg_speed_ratio_sum{activity="TranscodeToH264"} 2.5`)
+	assert.Contains(t, metrics, "bccflows_rclone_transferred_bytes_total 700")
+	assert.Contains(t, metrics, "bccflows_rclone_speed_bytes_per_second 5")
+	assert.Contains(t, metrics, "bccflows_rclone_transfers_running 1")
+	assert.Contains(t, metrics, "bccflows_rclone_errors 1")
+}
+
+func TestService_WithoutSinksDropsEvents(t *testing.T) {
+	assert.NotPanics(t, func() {
+		GetService().ActivityStarted("a", "q", "wf")
+		GetService().RcloneStats(RcloneStats{})
+	})
+}
@@ -0,0 +1,95 @@
+package analytics
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/bcc-code/bcc-media-flows/internal/bootstrap"
+	r "github.com/rudderlabs/analytics-go/v4"
+)
+
+// rudderstackSink enqueues activity and workflow events as Rudderstack tracks. It has
+// no use for the ffmpeg and rclone measurements.
+type rudderstackSink struct {
+	rudderClient r.Client
+}
+
+// newRudderstackSink returns nil when Rudderstack is not configured.
+func newRudderstackSink(config Config) *rudderstackSink {
+	if config.WriteKey == "" || config.DataPlane == "" {
+		fmt.Printf("WARN: Rudderstack is not configured, data will not be sent to Rudderstack\n")
+		return nil
+	}
+
+	c, err := r.NewWithConfig(config.WriteKey,
+		r.Config{
+			DataPlaneUrl: config.DataPlane,
+			Interval:     1 * time.Second,
+			BatchSize:    100,
+			Verbose:      config.Verbose,
+			DisableGzip:  false,
+		})
+
+	if err != nil {
+		fmt.Printf("FATAL: Failed to create rudderstack client: %v", err)
+		return nil
+	}
+
+	return &rudderstackSink{
+		rudderClient: c,
+	}
+}
+
+func (s *rudderstackSink) enqueue(event string, properties map[string]interface{}) {
+	err := s.rudderClient.Enqueue(r.Track{
+		Event:      event,
+		UserId:     "analytics",
+		Properties: properties,
+	})
+
+	if err != nil {
+		fmt.Printf("WARN: Failed to enqueue %s event: %v\n", event, err)
+	}
+}
+
+func (s *rudderstackSink) ActivityStarted(event ActivityEvent) {
+	s.enqueue("ActivityStarted", map[string]interface{}{
+		"activityName":   event.ActivityName,
+		"workerId":       bootstrap.Identity(),
+		"queue":          event.Queue,
+		"parentWorkflow": event.ParentWorkflow,
+	})
+}
+
+func (s *rudderstackSink) ActivityFinished(event ActivityEvent) {
+	s.enqueue("ActivityFinished", map[string]interface{}{
+		"activityName":   event.ActivityName,
+		"workerId":       event.WorkerID,
+		"queue":          event.Queue,
+		"parentWorkflow": event.ParentWorkflow,
+		"status":         event.Status,
+		"executionTime":  event.Duration.Milliseconds(),
+	})
+}
+
+func (s *rudderstackSink) WorkflowStarted(event WorkflowEvent) {
+	s.enqueue("WorkflowStarted", map[string]interface{}{
+		"workflowName":   event.WorkflowName,
+		"workflowId":     event.WorkflowID,
+		"parentWorkflow": event.ParentWorkflow,
+	})
+}
+
+func (s *rudderstackSink) WorkflowFinished(event WorkflowEvent) {
+	s.enqueue("WorkflowFinished", map[string]interface{}{
+		"workflowName":   event.WorkflowName,
+		"workflowId":     event.WorkflowID,
+		"parentWorkflow": event.ParentWorkflow,
+		"status":         event.Status,
+		"executionTime":  event.Duration.Milliseconds(),
+	})
+}
+
+func (s *rudderstackSink) FFmpegFinished(string, float64) {}
+
+func (s *rudderstackSink) RcloneStats(RcloneStats) {}
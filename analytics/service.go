@@ -1,12 +1,8 @@
 package analytics
 
 import (
-	"fmt"
-	"github.com/bcc-code/bcc-media-flows/internal/bootstrap"
 	"sync"
 	"time"
-
-	r "github.com/rudderlabs/analytics-go/v4"
 )
 
 var (
@@ -14,15 +10,16 @@ var (
 	once     sync.Once
 )
 
+// Init creates the service, sending to Rudderstack when config has a write key and a
+// data plane. Other sinks are added with AddSink.
 func Init(config Config) {
 	once.Do(func() {
 		Instance = newService(config)
 	})
 }
 
-// disabled stands in for an uninitialised service. Every method checks
-// rudderClient and returns early, so this drops events instead of panicking on
-// a nil receiver in a process that never called Init.
+// disabled stands in for an uninitialised service. It has no sinks, so it drops
+// events instead of panicking on a nil receiver in a process that never called Init.
 var disabled = &Service{}
 
 func GetService() *Service {
@@ -32,8 +29,10 @@ func GetService() *Service {
 	return Instance
 }
 
+// Service reports what the worker does to every sink it has.
 type Service struct {
-	rudderClient r.Client
+	lock  sync.RWMutex
+	sinks []Sink
 }
 
 type Config struct {
@@ -43,125 +42,79 @@ type Config struct {
 }
 
 func newService(config Config) *Service {
-	if config.WriteKey == "" || config.DataPlane == "" {
-		fmt.Printf("WARN: Rudderstack is not configured, data will not be sent to Rudderstack")
-		return &Service{}
-	}
+	s := &Service{}
 
-	c, err := r.NewWithConfig(config.WriteKey,
-		r.Config{
-			DataPlaneUrl: config.DataPlane,
-			Interval:     1 * time.Second,
-			BatchSize:    100,
-			Verbose:      config.Verbose,
-			DisableGzip:  false,
-		})
-
-	if err != nil {
-		fmt.Printf("FATAL: Failed to create rudderstack client: %v", err)
+	rudderstack := newRudderstackSink(config)
+	if rudderstack != nil {
+		s.AddSink(rudderstack)
 	}
 
-	return &Service{
-		rudderClient: c,
-	}
+	return s
 }
 
-func (s *Service) ActivityStarted(activityName string, queue string, parentWorkflow string) {
-	if s.rudderClient == nil {
-		fmt.Printf("DEBUG: rudderstack client is nil")
-		return
-	}
-
-	identity := bootstrap.Identity()
+func (s *Service) AddSink(sink Sink) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
 
-	properties := map[string]interface{}{
-		"activityName":   activityName,
-		"workerId":       identity,
-		"queue":          queue,
-		"parentWorkflow": parentWorkflow,
-	}
+	s.sinks = append(s.sinks, sink)
+}
 
-	err := s.rudderClient.Enqueue(r.Track{
-		Event:      "ActivityStarted",
-		UserId:     "analytics",
-		Properties: properties,
-	})
+func (s *Service) each(f func(Sink)) {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
 
-	if err != nil {
-		fmt.Printf("WARN: Failed to enqueue ActivityStarted event: %v\n", err)
+	for _, sink := range s.sinks {
+		f(sink)
 	}
 }
 
-func (s *Service) ActivityFinished(activityName string, workerId string, queue string, parentWorkflow string, status string, executionTime int64) {
-	if s.rudderClient == nil {
-		fmt.Printf("DEBUG: rudderstack client is nil")
-		return
-	}
-
-	properties := map[string]interface{}{
-		"activityName":   activityName,
-		"workerId":       workerId,
-		"queue":          queue,
-		"parentWorkflow": parentWorkflow,
-		"status":         status,
-		"executionTime":  executionTime,
+func (s *Service) ActivityStarted(activityName string, queue string, parentWorkflow string) {
+	event := ActivityEvent{
+		ActivityName:   activityName,
+		Queue:          queue,
+		ParentWorkflow: parentWorkflow,
 	}
+	s.each(func(sink Sink) { sink.ActivityStarted(event) })
+}
 
-	err := s.rudderClient.Enqueue(r.Track{
-		Event:      "ActivityFinished",
-		UserId:     "analytics",
-		Properties: properties,
-	})
-
-	if err != nil {
-		fmt.Printf("WARN: Failed to enqueue ActivityFinished event: %v\n", err)
-	}
+func (s *Service) ActivityFinished(activityName string, workerId string, queue string, parentWorkflow string, status string, executionTime int64) {
+	event := ActivityEvent{
+		ActivityName:   activityName,
+		WorkerID:       workerId,
+		Queue:          queue,
+		ParentWorkflow: parentWorkflow,
+		Status:         status,
+		Duration:       time.Duration(executionTime) * time.Millisecond,
+	}
+	s.each(func(sink Sink) { sink.ActivityFinished(event) })
 }
 
 func (s *Service) WorkflowStarted(workflowName string, workflowId string, parentWorkflow string) {
-	if s.rudderClient == nil {
-		fmt.Printf("DEBUG: rudderstack client is nil")
-		return
-	}
-
-	properties := map[string]interface{}{
-		"workflowName":   workflowName,
-		"workflowId":     workflowId,
-		"parentWorkflow": parentWorkflow,
-	}
-
-	err := s.rudderClient.Enqueue(r.Track{
-		Event:      "WorkflowStarted",
-		UserId:     "analytics",
-		Properties: properties,
-	})
-
-	if err != nil {
-		fmt.Printf("WARN: Failed to enqueue WorkflowStarted event: %v\n", err)
+	event := WorkflowEvent{
+		WorkflowName:   workflowName,
+		WorkflowID:     workflowId,
+		ParentWorkflow: parentWorkflow,
 	}
+	s.each(func(sink Sink) { sink.WorkflowStarted(event) })
 }
 
 func (s *Service) WorkflowFinished(workflowName string, workflowId string, parentWorkflow string, status string, executionTime int64) {
-	if s.rudderClient == nil {
-		fmt.Printf("DEBUG: rudderstack client is nil")
-		return
-	}
-
-	properties := map[string]interface{}{
-		"workflowName":   workflowName,
-		"workflowId":     workflowId,
-		"parentWorkflow": parentWorkflow,
-		"status":         status,
-		"executionTime":  executionTime,
-	}
+	event := WorkflowEvent{
+		WorkflowName:   workflowName,
+		WorkflowID:     workflowId,
+		ParentWorkflow: parentWorkflow,
+		Status:         status,
+		Duration:       time.Duration(executionTime) * time.Millisecond,
+	}
+	s.each(func(sink Sink) { sink.WorkflowFinished(event) })
+}
 
-	err := s.rudderClient.Enqueue(r.Track{
-		Event:      "WorkflowFinished",
-		UserId:     "analytics",
-		Properties: properties,
-	})
+// FFmpegFinished reports the speed an ffmpeg run in the activity averaged, as a multiple
+// of real time.
+func (s *Service) FFmpegFinished(activityName string, speed float64) {
+	s.each(func(sink Sink) { sink.FFmpegFinished(activityName, speed) })
+}
 
-	if err != nil {
-		fmt.Printf("WARN: Failed to enqueue WorkflowFinished event: %v\n", err)
-	}
+func (s *Service) RcloneStats(stats RcloneStats) {
+	s.each(func(sink Sink) { sink.RcloneStats(stats) })
 }
@@ -0,0 +1,46 @@
+package analytics
+
+import "time"
+
+// Sink is somewhere the Service sends what it is told. A sink must not block: it is
+// called inline from activities and the workflow interceptor.
+type Sink interface {
+	ActivityStarted(event ActivityEvent)
+	ActivityFinished(event ActivityEvent)
+	WorkflowStarted(event WorkflowEvent)
+	WorkflowFinished(event WorkflowEvent)
+	FFmpegFinished(activityName string, speed float64)
+	RcloneStats(stats RcloneStats)
+}
+
+// ActivityEvent has Status and Duration set only when the activity has finished.
+type ActivityEvent struct {
+	ActivityName   string
+	WorkerID       string
+	Queue          string
+	ParentWorkflow string
+	Status         string
+	Duration       time.Duration
+}
+
+// WorkflowEvent has Status and Duration set only when the workflow has finished.
+type WorkflowEvent struct {
+	WorkflowName   string
+	WorkflowID     string
+	ParentWorkflow string
+	Status         string
+	Duration       time.Duration
+}
+
+// RcloneStats is the rclone server's totals at one point in time.
+type RcloneStats struct {
+	// Bytes transferred since the rclone server started.
+	Bytes int64
+	// Speed is the average over the current transfers, in bytes per second.
+	Speed        float64
+	Transferring int
+	Errors       int
+}
+
+// StatusFailure is the Status of an activity or workflow that returned an error.
+const StatusFailure = "Failure"
@@ -0,0 +1,106 @@
+package cache
+
+import (
+	"container/list"
+	"sync"
+	"time"
+)
+
+type memoryEntry struct {
+	key    string
+	value  []byte
+	expiry time.Time
+}
+
+// Memory is a Store local to the process. Expired entries are dropped when they are
+// next read, or evicted like any other once the store is full.
+type Memory struct {
+	lock       sync.Mutex
+	maxEntries int
+	// order has the most recently used entry at the front.
+	order   *list.List
+	entries map[string]*list.Element
+	stats   Stats
+}
+
+// NewMemory returns an empty store holding at most maxEntries values, evicting the
+// least recently used first. Zero means no limit.
+func NewMemory(maxEntries int) *Memory {
+	return &Memory{
+		maxEntries: maxEntries,
+		order:      list.New(),
+		entries:    map[string]*list.Element{},
+	}
+}
+
+func (m *Memory) Get(key string) ([]byte, bool) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	element, ok := m.entries[key]
+	if !ok {
+		m.stats.Misses++
+		return nil, false
+	}
+
+	entry := element.Value.(*memoryEntry)
+	if !entry.expiry.After(time.Now()) {
+		m.remove(element)
+		m.stats.Expired++
+		m.stats.Misses++
+		return nil, false
+	}
+
+	m.order.MoveToFront(element)
+	m.stats.Hits++
+	return entry.value, true
+}
+
+func (m *Memory) Set(key string, value []byte, ttl time.Duration) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	expiry := time.Now().Add(ttl)
+	if element, ok := m.entries[key]; ok {
+		entry := element.Value.(*memoryEntry)
+		entry.value = value
+		entry.expiry = expiry
+		m.order.MoveToFront(element)
+		return
+	}
+
+	m.entries[key] = m.order.PushFront(&memoryEntry{key: key, value: value, expiry: expiry})
+
+	for m.maxEntries > 0 && m.order.Len() > m.maxEntries {
+		oldest := m.order.Back()
+		if oldest.Value.(*memoryEntry).expiry.After(time.Now()) {
+			m.stats.Evictions++
+		} else {
+			m.stats.Expired++
+		}
+		m.remove(oldest)
+	}
+}
+
+func (m *Memory) Delete(key string) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	if element, ok := m.entries[key]; ok {
+		m.remove(element)
+	}
+}
+
+func (m *Memory) Stats() Stats {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	stats := m.stats
+	stats.Entries = m.order.Len()
+	return stats
+}
+
+func (m *Memory) remove(element *list.Element) {
+	m.order.Remove(element)
+	delete(m.entries, element.Value.(*memoryEntry).key)
+}
@@ -0,0 +1,94 @@
+package cache
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Memory_GetSet(t *testing.T) {
+	m := NewMemory(0)
+
+	_, ok := m.Get("a")
+	assert.False(t, ok)
+
+	m.Set("a", []byte("1"), time.Minute)
+	value, ok := m.Get("a")
+	assert.True(t, ok)
+	assert.Equal(t, []byte("1"), value)
+
+	m.Delete("a")
+	_, ok = m.Get("a")
+	assert.False(t, ok)
+
+	assert.Equal(t, Stats{Hits: 1, Misses: 2}, m.Stats())
+}
+
+func Test_Memory_PerKeyTTL(t *testing.T) {
+	m := NewMemory(0)
+
+	m.Set("short", []byte("1"), -time.Second)
+	m.Set("long", []byte("2"), time.Hour)
+
+	_, ok := m.Get("short")
+	assert.False(t, ok)
+	_, ok = m.Get("long")
+	assert.True(t, ok)
+
+	stats := m.Stats()
+	assert.Equal(t, uint64(1), stats.Expired)
+	assert.Equal(t, 1, stats.Entries)
+}
+
+func Test_Memory_EvictsLeastRecentlyUsed(t *testing.T) {
+	m := NewMemory(2)
+
+	m.Set("a", []byte("1"), time.Minute)
+	m.Set("b", []byte("2"), time.Minute)
+	// Reading a makes b the least recently used.
+	m.Get("a")
+	m.Set("c", []byte("3"), time.Minute)
+
+	_, ok := m.Get("b")
+	assert.False(t, ok)
+	_, ok = m.Get("a")
+	assert.True(t, ok)
+	_, ok = m.Get("c")
+	assert.True(t, ok)
+
+	stats := m.Stats()
+	assert.Equal(t, uint64(1), stats.Evictions)
+	assert.Equal(t, 2, stats.Entries)
+}
+
+type testValue struct {
+	Name string
+}
+
+func Test_GetOrSet_UsesDefaultStore(t *testing.T) {
+	previous := Default()
+	SetDefault(NewMemory(0))
+	t.Cleanup(func() { SetDefault(previous) })
+
+	calls := 0
+	factory := func() (*testValue, error) {
+		calls++
+		return &testValue{Name: "x"}, nil
+	}
+
+	v, err := GetOrSet("key", factory)
+	assert.NoError(t, err)
+	assert.Equal(t, "x", v.Name)
+
+	v, err = GetOrSet("key", factory)
+	assert.NoError(t, err)
+	assert.Equal(t, "x", v.Name)
+	assert.Equal(t, 1, calls)
+
+	// A value that no longer decodes as T is dropped rather than returned.
+	Default().Set("bad", []byte("not json"), time.Minute)
+	assert.Nil(t, Get[testValue]("bad"))
+	_, ok := Default().Get("bad")
+	assert.False(t, ok)
+}
@@ -0,0 +1,165 @@
+package cache
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+	"sync/atomic"
+	"time"
+
+	_ "github.com/glebarez/go-sqlite"
+)
+
+// SQLite is a Store in a database file, so values survive a restart and are shared by
+// every process on the host that opens the same file.
+//
+// Hits, misses and the other counters in Stats are this process's own; Entries is the
+// whole table.
+type SQLite struct {
+	db         *sql.DB
+	maxEntries int
+
+	hits      atomic.Uint64
+	misses    atomic.Uint64
+	evictions atomic.Uint64
+	expired   atomic.Uint64
+	errors    atomic.Uint64
+}
+
+const sqliteSchema = `
+CREATE TABLE IF NOT EXISTS cache_entries (
+	key         TEXT PRIMARY KEY,
+	value       BLOB NOT NULL,
+	expires_at  INTEGER NOT NULL,
+	accessed_at INTEGER NOT NULL
+);
+CREATE INDEX IF NOT EXISTS cache_entries_expires_at ON cache_entries (expires_at);
+CREATE INDEX IF NOT EXISTS cache_entries_accessed_at ON cache_entries (accessed_at);
+`
+
+// OpenSQLite opens, or creates, the store at path. It holds at most maxEntries values,
+// evicting the least recently used first. Zero means no limit.
+func OpenSQLite(path string, maxEntries int) (*SQLite, error) {
+	// WAL and a busy timeout let several workers on the host use the file at once.
+	db, err := sql.Open("sqlite", "file:"+path+"?_pragma=busy_timeout(5000)&_pragma=journal_mode(WAL)")
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = db.Exec(sqliteSchema)
+	if err != nil {
+		db.Close()
+		return nil, fmt.Errorf("creating cache schema in %s: %w", path, err)
+	}
+
+	return &SQLite{db: db, maxEntries: maxEntries}, nil
+}
+
+func (s *SQLite) Close() error {
+	return s.db.Close()
+}
+
+func (s *SQLite) Get(key string) ([]byte, bool) {
+	now := time.Now().UnixNano()
+
+	var value []byte
+	var expiresAt int64
+	err := s.db.QueryRow("SELECT value, expires_at FROM cache_entries WHERE key = ?", key).Scan(&value, &expiresAt)
+	if err == sql.ErrNoRows {
+		s.misses.Add(1)
+		return nil, false
+	}
+	if err != nil {
+		s.failed("reading", key, err)
+		s.misses.Add(1)
+		return nil, false
+	}
+
+	if expiresAt <= now {
+		s.Delete(key)
+		s.expired.Add(1)
+		s.misses.Add(1)
+		return nil, false
+	}
+
+	_, err = s.db.Exec("UPDATE cache_entries SET accessed_at = ? WHERE key = ?", now, key)
+	if err != nil {
+		s.failed("touching", key, err)
+	}
+
+	s.hits.Add(1)
+	return value, true
+}
+
+func (s *SQLite) Set(key string, value []byte, ttl time.Duration) {
+	now := time.Now()
+
+	_, err := s.db.Exec(`
+		INSERT INTO cache_entries (key, value, expires_at, accessed_at) VALUES (?, ?, ?, ?)
+		ON CONFLICT (key) DO UPDATE SET value = excluded.value, expires_at = excluded.expires_at, accessed_at = excluded.accessed_at`,
+		key, value, now.Add(ttl).UnixNano(), now.UnixNano())
+	if err != nil {
+		s.failed("writing", key, err)
+		return
+	}
+
+	s.prune(now)
+}
+
+// prune drops what has expired and then, if the table is still too large, what was
+// used least recently.
+func (s *SQLite) prune(now time.Time) {
+	result, err := s.db.Exec("DELETE FROM cache_entries WHERE expires_at <= ?", now.UnixNano())
+	if err != nil {
+		s.failed("pruning", "expired entries", err)
+		return
+	}
+	if n, err := result.RowsAffected(); err == nil {
+		s.expired.Add(uint64(n))
+	}
+
+	if s.maxEntries <= 0 {
+		return
+	}
+
+	result, err = s.db.Exec(`
+		DELETE FROM cache_entries WHERE key IN (
+			SELECT key FROM cache_entries ORDER BY accessed_at DESC LIMIT -1 OFFSET ?
+		)`, s.maxEntries)
+	if err != nil {
+		s.failed("evicting", "entries", err)
+		return
+	}
+	if n, err := result.RowsAffected(); err == nil {
+		s.evictions.Add(uint64(n))
+	}
+}
+
+func (s *SQLite) Delete(key string) {
+	_, err := s.db.Exec("DELETE FROM cache_entries WHERE key = ?", key)
+	if err != nil {
+		s.failed("deleting", key, err)
+	}
+}
+
+func (s *SQLite) Stats() Stats {
+	stats := Stats{
+		Hits:      s.hits.Load(),
+		Misses:    s.misses.Load(),
+		Evictions: s.evictions.Load(),
+		Expired:   s.expired.Load(),
+		Errors:    s.errors.Load(),
+	}
+
+	err := s.db.QueryRow("SELECT COUNT(*) FROM cache_entries").Scan(&stats.Entries)
+	if err != nil {
+		s.failed("counting", "entries", err)
+	}
+
+	return stats
+}
+
+func (s *SQLite) failed(action, key string, err error) {
+	s.errors.Add(1)
+	log.Printf("cache: %s %s: %v", action, key, err)
+}
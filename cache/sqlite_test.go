@@ -0,0 +1,77 @@
+package cache
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_SQLite_SurvivesReopen(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cache.sqlite3")
+
+	s, err := OpenSQLite(path, 0)
+	require.NoError(t, err)
+	s.Set("a", []byte("1"), time.Hour)
+	s.Set("a", []byte("2"), time.Hour)
+	require.NoError(t, s.Close())
+
+	s, err = OpenSQLite(path, 0)
+	require.NoError(t, err)
+	defer s.Close()
+
+	value, ok := s.Get("a")
+	assert.True(t, ok)
+	assert.Equal(t, []byte("2"), value)
+
+	s.Delete("a")
+	_, ok = s.Get("a")
+	assert.False(t, ok)
+
+	assert.Equal(t, Stats{Hits: 1, Misses: 1}, s.Stats())
+}
+
+func Test_SQLite_SharedBetweenStores(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cache.sqlite3")
+
+	a, err := OpenSQLite(path, 0)
+	require.NoError(t, err)
+	defer a.Close()
+	b, err := OpenSQLite(path, 0)
+	require.NoError(t, err)
+	defer b.Close()
+
+	a.Set("key", []byte("value"), time.Hour)
+	value, ok := b.Get("key")
+	assert.True(t, ok)
+	assert.Equal(t, []byte("value"), value)
+}
+
+func Test_SQLite_TTLAndEviction(t *testing.T) {
+	s, err := OpenSQLite(filepath.Join(t.TempDir(), "cache.sqlite3"), 2)
+	require.NoError(t, err)
+	defer s.Close()
+
+	s.Set("expired", []byte("0"), -time.Second)
+	_, ok := s.Get("expired")
+	assert.False(t, ok)
+
+	s.Set("a", []byte("1"), time.Hour)
+	time.Sleep(time.Millisecond)
+	s.Set("b", []byte("2"), time.Hour)
+	time.Sleep(time.Millisecond)
+	s.Get("a")
+	time.Sleep(time.Millisecond)
+	s.Set("c", []byte("3"), time.Hour)
+
+	_, ok = s.Get("b")
+	assert.False(t, ok)
+	_, ok = s.Get("a")
+	assert.True(t, ok)
+
+	stats := s.Stats()
+	assert.Equal(t, uint64(1), stats.Evictions)
+	assert.Equal(t, 2, stats.Entries)
+}
@@ -1,73 +1,99 @@
 package cache
 
 import (
+	"encoding/json"
+	"log"
 	"sync"
 	"time"
 )
 
-type entry struct {
-	Expiry time.Time
-	Value  any
-}
+// DefaultTTL is how long Set and GetOrSet keep a value.
+const DefaultTTL = time.Minute * 5
 
-var lock = sync.Mutex{}
-var store = map[string]entry{}
+// DefaultMaxEntries bounds the in-memory store used until SetDefault is called.
+const DefaultMaxEntries = 10000
 
-type janitor struct {
-	interval time.Duration
+// Store holds encoded values under a key until their TTL runs out, or until the store
+// needs the room. A store that fails to read or write treats it as a miss and counts
+// it in Stats.Errors: a cache is never a reason for the caller to fail.
+type Store interface {
+	Get(key string) ([]byte, bool)
+	Set(key string, value []byte, ttl time.Duration)
+	Delete(key string)
+	Stats() Stats
 }
 
-func (j *janitor) start() {
-	ticker := time.NewTicker(j.interval)
-	for {
-		select {
-		case <-ticker.C:
-			j.clear()
-		}
-	}
+// Stats are counted since the store was created, by this process.
+type Stats struct {
+	Hits      uint64 `json:"hits"`
+	Misses    uint64 `json:"misses"`
+	Evictions uint64 `json:"evictions"`
+	Expired   uint64 `json:"expired"`
+	Errors    uint64 `json:"errors"`
+	Entries   int    `json:"entries"`
 }
 
-func (j *janitor) clear() {
-	lock.Lock()
-	defer lock.Unlock()
-	var deleteKeys []string
-	for k, v := range store {
-		if v.Expiry.Before(time.Now()) {
-			deleteKeys = append(deleteKeys, k)
-		}
-	}
-	for _, k := range deleteKeys {
-		delete(store, k)
-	}
+var (
+	defaultLock  sync.RWMutex
+	defaultStore Store = NewMemory(DefaultMaxEntries)
+)
+
+// Default is the store Get, Set and GetOrSet use.
+func Default() Store {
+	defaultLock.RLock()
+	defer defaultLock.RUnlock()
+
+	return defaultStore
 }
 
-func init() {
-	j := &janitor{
-		interval: time.Minute * 1,
-	}
-	go j.start()
+// SetDefault replaces the store Get, Set and GetOrSet use. Values in the previous store
+// are not carried over.
+func SetDefault(store Store) {
+	defaultLock.Lock()
+	defer defaultLock.Unlock()
+
+	defaultStore = store
 }
 
 func Get[T any](key string) *T {
-	lock.Lock()
-	i, ok := store[key]
-	lock.Unlock()
-	if ok && i.Expiry.After(time.Now()) {
-		return i.Value.(*T)
+	data, ok := Default().Get(key)
+	if !ok {
+		return nil
 	}
-	return nil
+
+	var value T
+	err := json.Unmarshal(data, &value)
+	if err != nil {
+		// Written by an older version of T, most likely. Drop it so it is fetched again.
+		log.Printf("cache: dropping %s: %v", key, err)
+		Default().Delete(key)
+		return nil
+	}
+	return &value
 }
 
 func Set[T any](key string, value *T) {
-	lock.Lock()
-	defer lock.Unlock()
-	store[key] = entry{
-		Expiry: time.Now().Add(time.Minute * 5),
-		Value:  value,
+	SetWithTTL(key, value, DefaultTTL)
+}
+
+func SetWithTTL[T any](key string, value *T, ttl time.Duration) {
+	data, err := json.Marshal(value)
+	if err != nil {
+		log.Printf("cache: not storing %s: %v", key, err)
+		return
 	}
+	Default().Set(key, data, ttl)
+}
+
+func Delete(key string) {
+	Default().Delete(key)
 }
 
 func GetOrSet[T any](key string, factory func() (*T, error)) (*T, error) {
+	return GetOrSetWithTTL(key, DefaultTTL, factory)
+}
+
+func GetOrSetWithTTL[T any](key string, ttl time.Duration, factory func() (*T, error)) (*T, error) {
 	v := Get[T](key)
 	if v != nil {
 		return v, nil
@@ -76,6 +102,6 @@ func GetOrSet[T any](key string, factory func() (*T, error)) (*T, error) {
 	if err != nil {
 		return nil, err
 	}
-	Set(key, v)
+	SetWithTTL(key, v, ttl)
 	return v, nil
 }
@@ -0,0 +1,28 @@
+package main
+
+import (
+	"os"
+
+	"github.com/bcc-code/bcc-media-flows/internal/bootstrap"
+	"github.com/bcc-code/bcc-media-flows/services/vidispine/vsfake"
+	"github.com/gin-gonic/gin"
+)
+
+// Serves an in-memory Vidispine, seeded from the recorded responses in the folder
+// given as the first argument, if any (e.g. services/vidispine/testdata).
+func main() {
+	fake := vsfake.New()
+	if len(os.Args) > 1 {
+		if err := fake.LoadDir(os.Args[1]); err != nil {
+			panic(err)
+		}
+	}
+
+	router := gin.Default()
+	router.Any("/*path", gin.WrapH(fake))
+
+	err := bootstrap.Serve(router, "8085")
+	if err != nil {
+		panic(err)
+	}
+}
@@ -0,0 +1,290 @@
+package main
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	wfutils "github.com/bcc-code/bcc-media-flows/utils/workflows"
+	"github.com/gin-gonic/gin"
+	"go.temporal.io/api/common/v1"
+	"go.temporal.io/api/enums/v1"
+	"go.temporal.io/api/serviceerror"
+	"go.temporal.io/api/workflow/v1"
+	"go.temporal.io/api/workflowservice/v1"
+	"go.temporal.io/sdk/converter"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// jobSummary is one workflow as the jobs endpoints report it. Status is Temporal's
+// name for it: Running, Completed, Failed, Canceled, Terminated, ContinuedAsNew or
+// TimedOut.
+type jobSummary struct {
+	WorkflowID  string     `json:"workflowId"`
+	RunID       string     `json:"runId"`
+	Type        string     `json:"type"`
+	Status      string     `json:"status"`
+	VXID        string     `json:"vxid,omitempty"`
+	TriggeredBy string     `json:"triggeredBy,omitempty"`
+	StartTime   time.Time  `json:"startTime"`
+	CloseTime   *time.Time `json:"closeTime,omitempty"`
+}
+
+// jobActivity is an activity the workflow is waiting on. Progress is what the activity
+// last heartbeated, such as the ffmpeg progress of a transcode.
+type jobActivity struct {
+	ActivityID    string     `json:"activityId"`
+	Type          string     `json:"type"`
+	State         string     `json:"state"`
+	Attempt       int32      `json:"attempt"`
+	LastStarted   *time.Time `json:"lastStarted,omitempty"`
+	LastHeartbeat *time.Time `json:"lastHeartbeat,omitempty"`
+	Progress      any        `json:"progress,omitempty"`
+	LastFailure   string     `json:"lastFailure,omitempty"`
+}
+
+type jobStatus struct {
+	jobSummary
+	Activities []jobActivity `json:"activities"`
+	Result     any           `json:"result,omitempty"`
+	Failure    string        `json:"failure,omitempty"`
+}
+
+type jobList struct {
+	Jobs          []jobSummary `json:"jobs"`
+	NextPageToken string       `json:"nextPageToken,omitempty"`
+}
+
+// optionalTime is nil for a timestamp Temporal has not set.
+func optionalTime(t *timestamppb.Timestamp) *time.Time {
+	if t == nil {
+		return nil
+	}
+	value := t.AsTime()
+	return &value
+}
+
+// keywordAttribute reads a search attribute as the string it was set to, or "" when the
+// workflow does not have it.
+func keywordAttribute(attrs *common.SearchAttributes, names ...string) string {
+	for _, name := range names {
+		payload, ok := attrs.GetIndexedFields()[name]
+		if !ok {
+			continue
+		}
+		var value string
+		if err := json.Unmarshal(payload.GetData(), &value); err == nil && value != "" {
+			return value
+		}
+	}
+	return ""
+}
+
+func summarizeJob(info *workflow.WorkflowExecutionInfo) jobSummary {
+	return jobSummary{
+		WorkflowID:  info.GetExecution().GetWorkflowId(),
+		RunID:       info.GetExecution().GetRunId(),
+		Type:        info.GetType().GetName(),
+		Status:      info.GetStatus().String(),
+		VXID:        keywordAttribute(info.GetSearchAttributes(), wfutils.VXIDKey.GetName(), wfutils.LegacyVXIDKey.GetName()),
+		TriggeredBy: keywordAttribute(info.GetSearchAttributes(), wfutils.TriggeredByKey.GetName()),
+		StartTime:   info.GetStartTime().AsTime(),
+		CloseTime:   optionalTime(info.GetCloseTime()),
+	}
+}
+
+// decodePayloads returns the payloads as JSON values: the only value when there is one,
+// all of them when there are several.
+func decodePayloads(payloads *common.Payloads) any {
+	var values []any
+	for _, payload := range payloads.GetPayloads() {
+		var value any
+		if err := converter.GetDefaultDataConverter().FromPayload(payload, &value); err != nil {
+			value = fmt.Sprintf("undecodable %s payload", payload.GetMetadata()["encoding"])
+		}
+		values = append(values, value)
+	}
+
+	switch len(values) {
+	case 0:
+		return nil
+	case 1:
+		return values[0]
+	}
+	return values
+}
+
+func respondWithJobError(ctx *gin.Context, workflowID string, err error) {
+	var notFound *serviceerror.NotFound
+	if errors.As(err, &notFound) {
+		ctx.JSON(http.StatusNotFound, gin.H{
+			"error": fmt.Sprintf("job %s not found", workflowID),
+		})
+		return
+	}
+
+	ctx.JSON(http.StatusInternalServerError, gin.H{
+		"error": err.Error(),
+	})
+}
+
+// jobStatusHandler reports where a workflow is: what it is waiting on while it runs,
+// and its result or why it failed once it has stopped.
+func jobStatusHandler(ctx *gin.Context) {
+	workflowID := ctx.Param("workflowId")
+
+	wfClient, err := getClient()
+	if err != nil {
+		respondWithJobError(ctx, workflowID, err)
+		return
+	}
+
+	description, err := wfClient.DescribeWorkflowExecution(ctx, workflowID, "")
+	if err != nil {
+		respondWithJobError(ctx, workflowID, err)
+		return
+	}
+
+	info := description.GetWorkflowExecutionInfo()
+	status := jobStatus{
+		jobSummary: summarizeJob(info),
+		Activities: []jobActivity{},
+	}
+
+	for _, pending := range description.GetPendingActivities() {
+		activity := jobActivity{
+			ActivityID:    pending.GetActivityId(),
+			Type:          pending.GetActivityType().GetName(),
+			State:         pending.GetState().String(),
+			Attempt:       pending.GetAttempt(),
+			LastStarted:   optionalTime(pending.GetLastStartedTime()),
+			LastHeartbeat: optionalTime(pending.GetLastHeartbeatTime()),
+			Progress:      decodePayloads(pending.GetHeartbeatDetails()),
+			LastFailure:   pending.GetLastFailure().GetMessage(),
+		}
+		status.Activities = append(status.Activities, activity)
+	}
+
+	if info.GetStatus() != enums.WORKFLOW_EXECUTION_STATUS_RUNNING {
+		// The run has stopped, so Get returns at once: the result if it completed, and
+		// why it did not otherwise.
+		var result any
+		err = wfClient.GetWorkflow(ctx, workflowID, status.RunID).Get(ctx, &result)
+		if err != nil {
+			status.Failure = err.Error()
+		} else {
+			status.Result = result
+		}
+	}
+
+	ctx.JSON(http.StatusOK, status)
+}
+
+// jobCancelHandler asks a workflow to cancel. It returns once Temporal has recorded the
+// request; the workflow winds down after that, and GET /jobs/:workflowId shows it
+// Canceled when it has.
+func jobCancelHandler(ctx *gin.Context) {
+	workflowID := ctx.Param("workflowId")
+
+	wfClient, err := getClient()
+	if err != nil {
+		respondWithJobError(ctx, workflowID, err)
+		return
+	}
+
+	err = wfClient.CancelWorkflow(ctx, workflowID, "")
+	if err != nil {
+		respondWithJobError(ctx, workflowID, err)
+		return
+	}
+
+	ctx.JSON(http.StatusAccepted, gin.H{
+		"workflowId": workflowID,
+		"message":    "cancellation requested",
+	})
+}
+
+// visibilityValue refuses what would need escaping in a visibility query rather than
+// trying to escape it. VXIDs and user names never do.
+func visibilityValue(name, value string) (string, error) {
+	if strings.ContainsAny(value, `"'\`) {
+		return "", fmt.Errorf("%s must not contain quotes or backslashes", name)
+	}
+	return `"` + value + `"`, nil
+}
+
+// jobListHandler lists the workflows started for an asset, by someone, or both, newest
+// first. Listing everything is not offered: ask Temporal directly for that.
+func jobListHandler(ctx *gin.Context) {
+	var conditions []string
+	for _, filter := range []struct {
+		param string
+		key   string
+	}{
+		{"vxid", wfutils.VXIDKey.GetName()},
+		{"triggeredBy", wfutils.TriggeredByKey.GetName()},
+	} {
+		value := ctx.Query(filter.param)
+		if value == "" {
+			continue
+		}
+		quoted, err := visibilityValue(filter.param, value)
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		conditions = append(conditions, fmt.Sprintf("%s = %s", filter.key, quoted))
+	}
+	if len(conditions) == 0 {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "vxid or triggeredBy is required"})
+		return
+	}
+
+	request := &workflowservice.ListWorkflowExecutionsRequest{
+		Query:    strings.Join(conditions, " AND ") + " ORDER BY StartTime DESC",
+		PageSize: 50,
+	}
+	if pageSize := ctx.Query("pageSize"); pageSize != "" {
+		size, err := strconv.Atoi(pageSize)
+		if err != nil || size < 1 || size > 1000 {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "pageSize must be between 1 and 1000"})
+			return
+		}
+		request.PageSize = int32(size)
+	}
+	if token := ctx.Query("nextPageToken"); token != "" {
+		decoded, err := base64.URLEncoding.DecodeString(token)
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid nextPageToken"})
+			return
+		}
+		request.NextPageToken = decoded
+	}
+
+	wfClient, err := getClient()
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	resp, err := wfClient.ListWorkflow(ctx, request)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	list := jobList{Jobs: []jobSummary{}}
+	for _, execution := range resp.GetExecutions() {
+		list.Jobs = append(list.Jobs, summarizeJob(execution))
+	}
+	if len(resp.GetNextPageToken()) > 0 {
+		list.NextPageToken = base64.URLEncoding.EncodeToString(resp.GetNextPageToken())
+	}
+
+	ctx.JSON(http.StatusOK, list)
+}
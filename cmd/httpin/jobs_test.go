@@ -0,0 +1,249 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.temporal.io/api/common/v1"
+	"go.temporal.io/api/enums/v1"
+	"go.temporal.io/api/failure/v1"
+	"go.temporal.io/api/serviceerror"
+	"go.temporal.io/api/workflow/v1"
+	"go.temporal.io/api/workflowservice/v1"
+	"go.temporal.io/sdk/client"
+	"go.temporal.io/sdk/converter"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// jobsClient answers the calls the jobs handlers make from canned responses.
+type jobsClient struct {
+	client.Client
+
+	description *workflowservice.DescribeWorkflowExecutionResponse
+	describeErr error
+	result      any
+	resultErr   error
+	cancelErr   error
+	list        *workflowservice.ListWorkflowExecutionsResponse
+
+	canceled     string
+	listRequest  *workflowservice.ListWorkflowExecutionsRequest
+	fetchedRunID string
+}
+
+func (c *jobsClient) DescribeWorkflowExecution(context.Context, string, string) (*workflowservice.DescribeWorkflowExecutionResponse, error) {
+	return c.description, c.describeErr
+}
+
+func (c *jobsClient) GetWorkflow(_ context.Context, _ string, runID string) client.WorkflowRun {
+	c.fetchedRunID = runID
+	return resultRun{result: c.result, err: c.resultErr}
+}
+
+func (c *jobsClient) CancelWorkflow(_ context.Context, workflowID string, _ string) error {
+	c.canceled = workflowID
+	return c.cancelErr
+}
+
+func (c *jobsClient) ListWorkflow(_ context.Context, request *workflowservice.ListWorkflowExecutionsRequest) (*workflowservice.ListWorkflowExecutionsResponse, error) {
+	c.listRequest = request
+	return c.list, nil
+}
+
+type resultRun struct {
+	client.WorkflowRun
+
+	result any
+	err    error
+}
+
+func (r resultRun) Get(_ context.Context, valuePtr any) error {
+	if r.err != nil {
+		return r.err
+	}
+	data, _ := json.Marshal(r.result)
+	return json.Unmarshal(data, valuePtr)
+}
+
+func keyword(t *testing.T, value string) *common.Payload {
+	t.Helper()
+
+	payload, err := converter.GetDefaultDataConverter().ToPayload(value)
+	require.NoError(t, err)
+	return payload
+}
+
+func executionInfo(t *testing.T, status enums.WorkflowExecutionStatus) *workflow.WorkflowExecutionInfo {
+	t.Helper()
+
+	info := &workflow.WorkflowExecutionInfo{
+		Execution: &common.WorkflowExecution{WorkflowId: "wf-1", RunId: "run-1"},
+		Type:      &common.WorkflowType{Name: "VXExport"},
+		Status:    status,
+		StartTime: timestamppb.New(time.Date(2026, 5, 1, 10, 0, 0, 0, time.UTC)),
+		SearchAttributes: &common.SearchAttributes{IndexedFields: map[string]*common.Payload{
+			"VXID":        keyword(t, "VX-123"),
+			"TriggeredBy": keyword(t, "portal"),
+		}},
+	}
+	if status != enums.WORKFLOW_EXECUTION_STATUS_RUNNING {
+		info.CloseTime = timestamppb.New(time.Date(2026, 5, 1, 10, 5, 0, 0, time.UTC))
+	}
+	return info
+}
+
+func jobsRequest(t *testing.T, method, url string) *httptest.ResponseRecorder {
+	t.Helper()
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.GET("/jobs", jobListHandler)
+	router.GET("/jobs/:workflowId", jobStatusHandler)
+	router.POST("/jobs/:workflowId/cancel", jobCancelHandler)
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(method, url, nil))
+	return rec
+}
+
+func Test_JobStatus_Running_ListsPendingActivities(t *testing.T) {
+	heartbeat, err := converter.GetDefaultDataConverter().ToPayloads(map[string]any{"percent": 42.5})
+	require.NoError(t, err)
+
+	c := &jobsClient{description: &workflowservice.DescribeWorkflowExecutionResponse{
+		WorkflowExecutionInfo: executionInfo(t, enums.WORKFLOW_EXECUTION_STATUS_RUNNING),
+		PendingActivities: []*workflow.PendingActivityInfo{{
+			ActivityId:       "5",
+			ActivityType:     &common.ActivityType{Name: "TranscodeToH264"},
+			State:            enums.PENDING_ACTIVITY_STATE_STARTED,
+			Attempt:          2,
+			HeartbeatDetails: heartbeat,
+			LastFailure:      &failure.Failure{Message: "worker went away"},
+		}},
+	}}
+	withClient(t, c)
+
+	rec := jobsRequest(t, http.MethodGet, "/jobs/wf-1")
+
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.JSONEq(t, `{
+		"workflowId": "wf-1",
+		"runId": "run-1",
+		"type": "VXExport",
+		"status": "Running",
+		"vxid": "VX-123",
+		"triggeredBy": "portal",
+		"startTime": "2026-05-01T10:00:00Z",
+		"activities": [{
+			"activityId": "5",
+			"type": "TranscodeToH264",
+			"state": "Started",
+			"attempt": 2,
+			"progress": {"percent": 42.5},
+			"lastFailure": "worker went away"
+		}]
+	}`, rec.Body.String())
+	// A running workflow's result would block the request until it finished.
+	assert.Empty(t, c.fetchedRunID)
+}
+
+func Test_JobStatus_Completed_IncludesResult(t *testing.T) {
+	c := &jobsClient{
+		description: &workflowservice.DescribeWorkflowExecutionResponse{
+			WorkflowExecutionInfo: executionInfo(t, enums.WORKFLOW_EXECUTION_STATUS_COMPLETED),
+		},
+		result: map[string]any{"ID": "VX-123"},
+	}
+	withClient(t, c)
+
+	rec := jobsRequest(t, http.MethodGet, "/jobs/wf-1")
+
+	require.Equal(t, http.StatusOK, rec.Code)
+	var status jobStatus
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &status))
+	assert.Equal(t, "Completed", status.Status)
+	assert.Equal(t, map[string]any{"ID": "VX-123"}, status.Result)
+	assert.Empty(t, status.Failure)
+	require.NotNil(t, status.CloseTime)
+	assert.Equal(t, "run-1", c.fetchedRunID)
+}
+
+func Test_JobStatus_Failed_IncludesFailure(t *testing.T) {
+	withClient(t, &jobsClient{
+		description: &workflowservice.DescribeWorkflowExecutionResponse{
+			WorkflowExecutionInfo: executionInfo(t, enums.WORKFLOW_EXECUTION_STATUS_FAILED),
+		},
+		resultErr: errors.New("workflow execution error: transcode failed"),
+	})
+
+	rec := jobsRequest(t, http.MethodGet, "/jobs/wf-1")
+
+	require.Equal(t, http.StatusOK, rec.Code)
+	var status jobStatus
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &status))
+	assert.Equal(t, "Failed", status.Status)
+	assert.Contains(t, status.Failure, "transcode failed")
+	assert.Nil(t, status.Result)
+}
+
+func Test_JobStatus_NotFound_Returns404(t *testing.T) {
+	withClient(t, &jobsClient{describeErr: serviceerror.NewNotFound("workflow not found")})
+
+	rec := jobsRequest(t, http.MethodGet, "/jobs/nope")
+
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+	assert.Contains(t, rec.Body.String(), "job nope not found")
+}
+
+func Test_JobCancel(t *testing.T) {
+	c := &jobsClient{}
+	withClient(t, c)
+
+	rec := jobsRequest(t, http.MethodPost, "/jobs/wf-1/cancel")
+
+	assert.Equal(t, http.StatusAccepted, rec.Code)
+	assert.Equal(t, "wf-1", c.canceled)
+
+	withClient(t, &jobsClient{cancelErr: serviceerror.NewNotFound("workflow not found")})
+	assert.Equal(t, http.StatusNotFound, jobsRequest(t, http.MethodPost, "/jobs/nope/cancel").Code)
+}
+
+func Test_JobList_ByVXID(t *testing.T) {
+	c := &jobsClient{list: &workflowservice.ListWorkflowExecutionsResponse{
+		Executions:    []*workflow.WorkflowExecutionInfo{executionInfo(t, enums.WORKFLOW_EXECUTION_STATUS_RUNNING)},
+		NextPageToken: []byte("next"),
+	}}
+	withClient(t, c)
+
+	rec := jobsRequest(t, http.MethodGet, "/jobs?vxid=VX-123&triggeredBy=portal&pageSize=10")
+
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, `VXID = "VX-123" AND TriggeredBy = "portal" ORDER BY StartTime DESC`, c.listRequest.Query)
+	assert.Equal(t, int32(10), c.listRequest.PageSize)
+
+	var list jobList
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &list))
+	require.Len(t, list.Jobs, 1)
+	assert.Equal(t, "wf-1", list.Jobs[0].WorkflowID)
+	assert.Equal(t, "VX-123", list.Jobs[0].VXID)
+
+	rec = jobsRequest(t, http.MethodGet, "/jobs?vxid=VX-123&nextPageToken="+list.NextPageToken)
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, []byte("next"), c.listRequest.NextPageToken)
+}
+
+func Test_JobList_RejectsUnfilteredAndUnsafeQueries(t *testing.T) {
+	withClient(t, &jobsClient{})
+
+	assert.Equal(t, http.StatusBadRequest, jobsRequest(t, http.MethodGet, "/jobs").Code)
+	assert.Equal(t, http.StatusBadRequest, jobsRequest(t, http.MethodGet, `/jobs?vxid=VX-1%22%20OR%20true`).Code)
+	assert.Equal(t, http.StatusBadRequest, jobsRequest(t, http.MethodGet, "/jobs?vxid=VX-1&pageSize=0").Code)
+}
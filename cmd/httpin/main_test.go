@@ -6,6 +6,7 @@ import (
 	"errors"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 
 	"github.com/gin-gonic/gin"
@@ -152,3 +153,55 @@ func Test_TriggerHandler_NeverReturns200WithNullBody(t *testing.T) {
 		})
 	}
 }
+
+func Test_TriggerHandler_JSONBody(t *testing.T) {
+	withClient(t, stubClient{run: stubRun{}})
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.POST("/trigger/:job", triggerHandler)
+
+	req := httptest.NewRequest(http.MethodPost, "/trigger/NormalizeAudio", strings.NewReader(`{"file": "/mnt/isilon/x.wav", "targetLUFS": -23}`))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.JSONEq(t, `{"workflowId": "wf-1", "runId": "run-1"}`, rec.Body.String())
+}
+
+func Test_TriggerHandler_InvalidParams_ListsFields(t *testing.T) {
+	withClient(t, stubClient{run: stubRun{}})
+
+	rec := triggerRequest(t, "CreateThumbnailsVX", "width=abc")
+
+	require.Equal(t, http.StatusBadRequest, rec.Code)
+	var body struct {
+		Fields []map[string]string `json:"fields"`
+	}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+	assert.ElementsMatch(t, []map[string]string{
+		{"field": "vxID", "message": "is required"},
+		{"field": "width", "message": "must be an integer"},
+	}, body.Fields)
+}
+
+func Test_TriggerHandler_InvalidCallbackURL_Returns400(t *testing.T) {
+	withClient(t, stubClient{run: stubRun{}})
+
+	rec := triggerRequest(t, "UpdateAssetRelations", "vxID=VX-1&callbackUrl=portal.example/done")
+
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	assert.Contains(t, rec.Body.String(), "callbackUrl")
+}
+
+// The worker signs with the secret the ID names, so one it would not know is refused
+// before the workflow starts.
+func Test_TriggerHandler_UnknownCallbackSecret_Returns400(t *testing.T) {
+	withClient(t, stubClient{run: stubRun{}})
+
+	rec := triggerRequest(t, "UpdateAssetRelations", "vxID=VX-1&callbackUrl=https://portal.example/done&callbackSecretId=portal")
+
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	assert.Contains(t, rec.Body.String(), "callbackSecretId")
+}
@@ -0,0 +1,519 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"runtime"
+	"strings"
+	"sync"
+	"text/template"
+	"time"
+
+	"github.com/bcc-code/bcc-media-flows/environment"
+	"github.com/bcc-code/bcc-media-flows/paths"
+	"github.com/bcc-code/bcc-media-flows/workflows"
+	ingestworkflows "github.com/bcc-code/bcc-media-flows/workflows/ingest"
+	"github.com/google/uuid"
+)
+
+// watcherRoute sends watcher events for the files it matches to a workflow. Routes are
+// tried in order and the first match wins.
+type watcherRoute struct {
+	Name string `json:"name"`
+
+	// Prefixes and Globs match the event path; a route with either matches a path that
+	// any of them matches. Globs use filepath.Match against the whole path.
+	Prefixes []string `json:"prefixes,omitempty"`
+	Globs    []string `json:"globs,omitempty"`
+
+	// Pattern is a regular expression the path must also match. Its named groups are
+	// available to the templates as .Match.
+	Pattern string `json:"pattern,omitempty"`
+
+	// Extensions limits the route to these file extensions, case-insensitively.
+	Extensions []string `json:"extensions,omitempty"`
+
+	// MinSize skips the route for smaller files.
+	MinSize int64 `json:"minSize,omitempty"`
+
+	// SettleTime is how long a file must have been left alone before it is dispatched.
+	// An event for a file changed more recently is answered with 425 so the watcher
+	// sends it again.
+	SettleTime routeDuration `json:"settleTime,omitempty"`
+
+	Workflow string `json:"workflow"`
+
+	// TaskQueue defaults to the worker queue.
+	TaskQueue string `json:"taskQueue,omitempty"`
+
+	// WorkflowID is a template. Empty means a random ID.
+	WorkflowID string `json:"workflowId,omitempty"`
+
+	// Params is the workflow input. Every string in it is a template, rendered with
+	// watcherTemplateData.
+	Params any `json:"params"`
+}
+
+// routeDuration is a time.Duration written as a string, such as "30s", in the file.
+type routeDuration time.Duration
+
+func (d routeDuration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(time.Duration(d).String())
+}
+
+func (d *routeDuration) UnmarshalJSON(value []byte) error {
+	var s string
+	err := json.Unmarshal(value, &s)
+	if err != nil {
+		return err
+	}
+	parsed, err := time.ParseDuration(s)
+	if err != nil {
+		return err
+	}
+	*d = routeDuration(parsed)
+	return nil
+}
+
+// watcherTemplateData is what route templates are rendered with.
+type watcherTemplateData struct {
+	Path      string
+	Dir       string
+	Base      string
+	Ext       string
+	Size      int64
+	UpdatedAt time.Time
+	Now       time.Time
+	Match     map[string]string
+}
+
+// Drive and RelPath are the event path as a paths.Path. They are methods so a template
+// that uses them fails when the path is not on a known drive, while one that does not
+// is unaffected.
+func (d watcherTemplateData) Drive() (string, error) {
+	p, err := paths.Parse(d.Path)
+	if err != nil {
+		return "", err
+	}
+	return p.Drive.Value, nil
+}
+
+func (d watcherTemplateData) RelPath() (string, error) {
+	p, err := paths.Parse(d.Path)
+	if err != nil {
+		return "", err
+	}
+	return p.Path, nil
+}
+
+var watcherTemplateFuncs = template.FuncMap{
+	"uuid": uuid.NewString,
+	"join": filepath.Join,
+	// liveIngestID is the workflow ID of the live ingest of a source, the one the
+	// workflow itself insists on.
+	"liveIngestID": ingestworkflows.LiveIngestWorkflowIDFor,
+}
+
+type compiledRoute struct {
+	watcherRoute
+	pattern    *regexp.Regexp
+	workflowID *template.Template
+	params     any
+}
+
+// watcherRoutes is a loaded routing table.
+type watcherRoutes struct {
+	Source   string          `json:"source"`
+	LoadedAt time.Time       `json:"loadedAt"`
+	Routes   []compiledRoute `json:"-"`
+}
+
+func (t *watcherRoutes) MarshalJSON() ([]byte, error) {
+	type table watcherRoutes
+	routes := make([]watcherRoute, len(t.Routes))
+	for i, r := range t.Routes {
+		routes[i] = r.watcherRoute
+	}
+	return json.Marshal(struct {
+		*table
+		Routes []watcherRoute `json:"routes"`
+	}{(*table)(t), routes})
+}
+
+// knownWorkflowNames are the names the worker registers its workflows under.
+func knownWorkflowNames() map[string]bool {
+	names := map[string]bool{}
+	for _, wf := range workflows.WorkerWorkflows {
+		fullName := runtime.FuncForPC(reflect.ValueOf(wf).Pointer()).Name()
+		names[fullName[strings.LastIndex(fullName, ".")+1:]] = true
+	}
+	return names
+}
+
+// compileWatcherRoutes validates a table and parses its templates, so a bad table is
+// refused as a whole when loaded rather than failing one event at a time.
+func compileWatcherRoutes(routes []watcherRoute, source string) (*watcherRoutes, error) {
+	known := knownWorkflowNames()
+	table := &watcherRoutes{Source: source, LoadedAt: time.Now()}
+	seen := map[string]bool{}
+
+	var errs []error
+	for i, route := range routes {
+		name := route.Name
+		if name == "" {
+			name = fmt.Sprintf("#%d", i)
+			errs = append(errs, fmt.Errorf("route %s has no name", name))
+		}
+		if seen[name] {
+			errs = append(errs, fmt.Errorf("route %s is defined twice", name))
+		}
+		seen[name] = true
+
+		if !known[route.Workflow] {
+			errs = append(errs, fmt.Errorf("route %s: unknown workflow %q", name, route.Workflow))
+		}
+
+		compiled := compiledRoute{watcherRoute: route}
+		var err error
+		if route.Pattern != "" {
+			compiled.pattern, err = regexp.Compile(route.Pattern)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("route %s: pattern: %w", name, err))
+			}
+		}
+		for _, glob := range route.Globs {
+			if _, err := filepath.Match(glob, ""); err != nil {
+				errs = append(errs, fmt.Errorf("route %s: glob %q: %w", name, glob, err))
+			}
+		}
+		if route.WorkflowID != "" {
+			compiled.workflowID, err = template.New(name).Funcs(watcherTemplateFuncs).Parse(route.WorkflowID)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("route %s: workflowId: %w", name, err))
+			}
+		}
+		compiled.params, err = compileParams(name, route.Params)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("route %s: params: %w", name, err))
+		}
+
+		table.Routes = append(table.Routes, compiled)
+	}
+
+	if len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+	return table, nil
+}
+
+// compileParams replaces every string in a decoded JSON value with its parsed template.
+func compileParams(name string, value any) (any, error) {
+	switch v := value.(type) {
+	case string:
+		return template.New(name).Funcs(watcherTemplateFuncs).Option("missingkey=error").Parse(v)
+	case map[string]any:
+		out := make(map[string]any, len(v))
+		for key, item := range v {
+			compiled, err := compileParams(name, item)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", key, err)
+			}
+			out[key] = compiled
+		}
+		return out, nil
+	case []any:
+		out := make([]any, len(v))
+		for i, item := range v {
+			compiled, err := compileParams(name, item)
+			if err != nil {
+				return nil, fmt.Errorf("[%d]: %w", i, err)
+			}
+			out[i] = compiled
+		}
+		return out, nil
+	}
+	return value, nil
+}
+
+func renderParams(value any, data watcherTemplateData) (any, error) {
+	switch v := value.(type) {
+	case *template.Template:
+		return renderTemplate(v, data)
+	case map[string]any:
+		out := make(map[string]any, len(v))
+		for key, item := range v {
+			rendered, err := renderParams(item, data)
+			if err != nil {
+				return nil, err
+			}
+			out[key] = rendered
+		}
+		return out, nil
+	case []any:
+		out := make([]any, len(v))
+		for i, item := range v {
+			rendered, err := renderParams(item, data)
+			if err != nil {
+				return nil, err
+			}
+			out[i] = rendered
+		}
+		return out, nil
+	}
+	return value, nil
+}
+
+func renderTemplate(t *template.Template, data watcherTemplateData) (string, error) {
+	var b bytes.Buffer
+	err := t.Execute(&b, data)
+	return b.String(), err
+}
+
+// matches reports whether the route applies to the event, and the named groups of its
+// pattern if it does.
+func (r compiledRoute) matches(event watcherResult) (map[string]string, bool) {
+	if len(r.Prefixes) > 0 || len(r.Globs) > 0 {
+		matched := false
+		for _, prefix := range r.Prefixes {
+			if strings.HasPrefix(event.Path, prefix) {
+				matched = true
+				break
+			}
+		}
+		for _, glob := range r.Globs {
+			if ok, _ := filepath.Match(glob, event.Path); ok {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return nil, false
+		}
+	}
+
+	if len(r.Extensions) > 0 {
+		ext := strings.ToLower(filepath.Ext(event.Path))
+		found := false
+		for _, e := range r.Extensions {
+			if strings.ToLower("."+strings.TrimPrefix(e, ".")) == ext {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil, false
+		}
+	}
+
+	if event.Size < r.MinSize {
+		return nil, false
+	}
+
+	groups := map[string]string{}
+	if r.pattern != nil {
+		match := r.pattern.FindStringSubmatch(event.Path)
+		if match == nil {
+			return nil, false
+		}
+		for i, name := range r.pattern.SubexpNames() {
+			if name != "" {
+				groups[name] = match[i]
+			}
+		}
+	}
+
+	return groups, true
+}
+
+// watcherDispatch is what a routing table decides to do with one event.
+type watcherDispatch struct {
+	Route      string `json:"route"`
+	Workflow   string `json:"workflow"`
+	TaskQueue  string `json:"taskQueue"`
+	WorkflowID string `json:"workflowId,omitempty"`
+	Params     any    `json:"params"`
+	// SettlesAt is set when the file has not been left alone for the route's settle
+	// time yet, and nothing should be started before then.
+	SettlesAt *time.Time `json:"settlesAt,omitempty"`
+}
+
+var errNoWatcherRoute = errors.New("no watcher route matches")
+
+// resolve finds the route for an event and renders what to start.
+func (t *watcherRoutes) resolve(event watcherResult, now time.Time) (*watcherDispatch, error) {
+	for _, route := range t.Routes {
+		groups, ok := route.matches(event)
+		if !ok {
+			continue
+		}
+
+		dispatch := &watcherDispatch{
+			Route:     route.Name,
+			Workflow:  route.Workflow,
+			TaskQueue: route.TaskQueue,
+		}
+		if dispatch.TaskQueue == "" {
+			dispatch.TaskQueue = environment.GetWorkerQueue()
+		}
+
+		if settle := time.Duration(route.SettleTime); settle > 0 && !event.UpdatedAt.IsZero() {
+			settlesAt := event.UpdatedAt.Add(settle)
+			if now.Before(settlesAt) {
+				dispatch.SettlesAt = &settlesAt
+			}
+		}
+
+		data := watcherTemplateData{
+			Path:      event.Path,
+			Dir:       filepath.Dir(event.Path),
+			Base:      filepath.Base(event.Path),
+			Ext:       filepath.Ext(event.Path),
+			Size:      event.Size,
+			UpdatedAt: event.UpdatedAt,
+			Now:       now,
+			Match:     groups,
+		}
+
+		var err error
+		if route.workflowID != nil {
+			dispatch.WorkflowID, err = renderTemplate(route.workflowID, data)
+			if err != nil {
+				return nil, fmt.Errorf("route %s: workflowId: %w", route.Name, err)
+			}
+		}
+		dispatch.Params, err = renderParams(route.params, data)
+		if err != nil {
+			return nil, fmt.Errorf("route %s: params: %w", route.Name, err)
+		}
+
+		return dispatch, nil
+	}
+
+	return nil, fmt.Errorf("%w %s", errNoWatcherRoute, event.Path)
+}
+
+// defaultWatcherRoutes is the table used when no file is configured, and what a file
+// would have to say to keep today's behaviour.
+func defaultWatcherRoutes() []watcherRoute {
+	return []watcherRoute{
+		{
+			// This needs to match any subfolder
+			Name:     "multitrack",
+			Prefixes: []string{"/mnt/filecatalyst/multitrack/Ingest/tempFraBrunstad/"},
+			Workflow: "HandleMultitrackFile",
+			Params:   map[string]any{"Path": "{{.Path}}"},
+		},
+		{
+			// A subfolder is a source, such as a room, with its own live ingest. A file
+			// directly in the folder is from the default source.
+			Name:       "growing",
+			Prefixes:   []string{"/mnt/filecatalyst/ingestgrow/"},
+			Pattern:    "^/mnt/filecatalyst/ingestgrow/(?:(?P<source>[^/]+)/)?[^/]+$",
+			Workflow:   "Incremental",
+			WorkflowID: "{{liveIngestID .Match.source}}",
+			Params:     map[string]any{"Path": "{{.Path}}", "Source": "{{.Match.source}}"},
+		},
+		{
+			Name: "raw-import",
+			Prefixes: []string{
+				"/mnt/isilon/Input/Rawmaterial/",
+				"/mnt/filecatalyst/Rawmaterial/",
+				"/mnt/filecatalyst/delivery2/RawMaterial/",
+			},
+			Workflow:   "RawMaterial",
+			WorkflowID: "RAWIMPORT-{{uuid}}",
+			Params: map[string]any{
+				"FilesToIngest": []any{map[string]any{"Drive": "{{.Drive}}", "Path": "{{.RelPath}}"}},
+			},
+		},
+		{
+			Name:     "simple-copy",
+			Prefixes: []string{"/mnt/filecatalyst/delivery2/simple"},
+			Workflow: "CopyFile",
+			Params: map[string]any{
+				"Source":      "{{.Path}}",
+				"Destination": `{{join "/mnt/isilon/Input/FromDelivery" (.Now.Format "2006/01/02") .Base}}`,
+			},
+		},
+		{
+			Name:     "transcode",
+			Pattern:  fmt.Sprintf("(?:%s/)(?P<encoding>[\\w-]*)(?:/in/)", environment.Get().Paths.TranscodeRoot()),
+			Workflow: "WatchFolderTranscode",
+			Params:   map[string]any{"Path": "{{.Path}}", "FolderName": "{{.Match.encoding}}"},
+		},
+	}
+}
+
+// loadWatcherRoutes reads the routing table from file, or returns the built-in one when
+// file is empty.
+func loadWatcherRoutes(file string) (*watcherRoutes, error) {
+	if file == "" {
+		return compileWatcherRoutes(defaultWatcherRoutes(), "built-in")
+	}
+
+	contents, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+
+	var routes []watcherRoute
+	err = json.Unmarshal(contents, &routes)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", file, err)
+	}
+
+	return compileWatcherRoutes(routes, file)
+}
+
+var (
+	watcherRoutesMu      sync.RWMutex
+	currentWatcherRoutes *watcherRoutes
+)
+
+func getWatcherRoutes() *watcherRoutes {
+	watcherRoutesMu.RLock()
+	defer watcherRoutesMu.RUnlock()
+
+	return currentWatcherRoutes
+}
+
+func setWatcherRoutes(table *watcherRoutes) {
+	watcherRoutesMu.Lock()
+	currentWatcherRoutes = table
+	watcherRoutesMu.Unlock()
+}
+
+// watchWatcherRoutesFile reloads the table whenever the file changes. A table that does
+// not load is logged and the previous one stays in effect.
+func watchWatcherRoutesFile(file string, interval time.Duration) {
+	var lastModified time.Time
+	if stat, err := os.Stat(file); err == nil {
+		lastModified = stat.ModTime()
+	}
+
+	for range time.Tick(interval) {
+		stat, err := os.Stat(file)
+		if err != nil {
+			fmt.Printf("watcher routes: %v\n", err)
+			continue
+		}
+		if !stat.ModTime().After(lastModified) {
+			continue
+		}
+		lastModified = stat.ModTime()
+
+		table, err := loadWatcherRoutes(file)
+		if err != nil {
+			fmt.Printf("watcher routes: keeping the previous table: %v\n", err)
+			continue
+		}
+		setWatcherRoutes(table)
+		fmt.Printf("watcher routes: reloaded %d routes from %s\n", len(table.Routes), file)
+	}
+}
@@ -0,0 +1,301 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/bcc-code/bcc-media-flows/environment"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.temporal.io/sdk/client"
+)
+
+func defaultRoutes(t *testing.T) *watcherRoutes {
+	t.Helper()
+
+	t.Setenv("TRANSCODE_ROOT_PATH", "/mnt/isilon/system/multitrack/transcode")
+	t.Setenv("QUEUE", "worker")
+	environment.Load()
+
+	table, err := loadWatcherRoutes("")
+	require.NoError(t, err)
+	return table
+}
+
+func toJSON(t *testing.T, value any) string {
+	t.Helper()
+
+	data, err := json.Marshal(value)
+	require.NoError(t, err)
+	return string(data)
+}
+
+// The built-in table has to start exactly what the hard-coded branches it replaced did.
+func Test_DefaultWatcherRoutes_MatchPreviousBranches(t *testing.T) {
+	table := defaultRoutes(t)
+	now := time.Date(2026, 3, 4, 12, 0, 0, 0, time.UTC)
+
+	cases := []struct {
+		path       string
+		route      string
+		workflow   string
+		workflowID string
+		params     string
+	}{
+		{
+			"/mnt/filecatalyst/multitrack/Ingest/tempFraBrunstad/2026/track.wav",
+			"multitrack", "HandleMultitrackFile", "",
+			`{"Path":"/mnt/filecatalyst/multitrack/Ingest/tempFraBrunstad/2026/track.wav"}`,
+		},
+		{
+			"/mnt/filecatalyst/ingestgrow/live.mxf",
+			"growing", "Incremental", "LIVE-INGEST",
+			`{"Path":"/mnt/filecatalyst/ingestgrow/live.mxf","Source":""}`,
+		},
+		{
+			"/mnt/filecatalyst/ingestgrow/room-b/live.mxf",
+			"growing", "Incremental", "LIVE-INGEST-room-b",
+			`{"Path":"/mnt/filecatalyst/ingestgrow/room-b/live.mxf","Source":"room-b"}`,
+		},
+		{
+			"/mnt/isilon/Input/Rawmaterial/clip.mov",
+			"raw-import", "RawMaterial", "",
+			`{"FilesToIngest":[{"Drive":"isilon","Path":"Input/Rawmaterial/clip.mov"}]}`,
+		},
+		{
+			"/mnt/filecatalyst/delivery2/simple/file.zip",
+			"simple-copy", "CopyFile", "",
+			`{"Destination":"/mnt/isilon/Input/FromDelivery/2026/03/04/file.zip","Source":"/mnt/filecatalyst/delivery2/simple/file.zip"}`,
+		},
+		{
+			"/mnt/isilon/system/multitrack/transcode/prores-hq/in/clip.mov",
+			"transcode", "WatchFolderTranscode", "",
+			`{"FolderName":"prores-hq","Path":"/mnt/isilon/system/multitrack/transcode/prores-hq/in/clip.mov"}`,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.route, func(t *testing.T) {
+			dispatch, err := table.resolve(watcherResult{Path: c.path}, now)
+			require.NoError(t, err)
+
+			assert.Equal(t, c.route, dispatch.Route)
+			assert.Equal(t, c.workflow, dispatch.Workflow)
+			assert.Equal(t, "worker", dispatch.TaskQueue)
+			if c.route == "raw-import" {
+				assert.Regexp(t, "^RAWIMPORT-[0-9a-f-]{36}$", dispatch.WorkflowID)
+			} else {
+				assert.Equal(t, c.workflowID, dispatch.WorkflowID)
+			}
+			assert.JSONEq(t, c.params, toJSON(t, dispatch.Params))
+			assert.Nil(t, dispatch.SettlesAt)
+		})
+	}
+}
+
+func Test_DefaultWatcherRoutes_Unmatched(t *testing.T) {
+	table := defaultRoutes(t)
+
+	_, err := table.resolve(watcherResult{Path: "/mnt/elsewhere/file.mov"}, time.Now())
+	assert.ErrorIs(t, err, errNoWatcherRoute)
+	assert.ErrorContains(t, err, "/mnt/elsewhere/file.mov")
+}
+
+func Test_CompileWatcherRoutes_RejectsBadTable(t *testing.T) {
+	_, err := compileWatcherRoutes([]watcherRoute{
+		{Name: "a", Workflow: "NoSuchWorkflow"},
+		{Name: "b", Workflow: "CopyFile", Pattern: "("},
+		{Name: "b", Workflow: "CopyFile", Params: map[string]any{"Path": "{{.Path"}},
+		{Workflow: "CopyFile"},
+	}, "test")
+
+	require.Error(t, err)
+	assert.ErrorContains(t, err, `route a: unknown workflow "NoSuchWorkflow"`)
+	assert.ErrorContains(t, err, "route b: pattern")
+	assert.ErrorContains(t, err, "route b is defined twice")
+	assert.ErrorContains(t, err, "route b: params: Path")
+	assert.ErrorContains(t, err, "route #3 has no name")
+}
+
+func Test_WatcherRoutes_ExtensionsSizeAndSettle(t *testing.T) {
+	var routes []watcherRoute
+	require.NoError(t, json.Unmarshal([]byte(`[
+		{
+			"name": "big-mxf",
+			"globs": ["/mnt/dropbox/*/*"],
+			"extensions": ["mxf"],
+			"minSize": 1000,
+			"settleTime": "30s",
+			"workflow": "CopyFile",
+			"taskQueue": "other",
+			"workflowId": "COPY-{{.Base}}",
+			"params": {"Source": "{{.Path}}", "Overwrite": true}
+		}
+	]`), &routes))
+	table, err := compileWatcherRoutes(routes, "test")
+	require.NoError(t, err)
+
+	now := time.Now()
+	settled := watcherResult{Path: "/mnt/dropbox/a/clip.MXF", Size: 2000, UpdatedAt: now.Add(-time.Minute)}
+
+	dispatch, err := table.resolve(settled, now)
+	require.NoError(t, err)
+	assert.Equal(t, "other", dispatch.TaskQueue)
+	assert.Equal(t, "COPY-clip.MXF", dispatch.WorkflowID)
+	assert.JSONEq(t, `{"Source":"/mnt/dropbox/a/clip.MXF","Overwrite":true}`, toJSON(t, dispatch.Params))
+	assert.Nil(t, dispatch.SettlesAt)
+
+	fresh := settled
+	fresh.UpdatedAt = now.Add(-10 * time.Second)
+	dispatch, err = table.resolve(fresh, now)
+	require.NoError(t, err)
+	require.NotNil(t, dispatch.SettlesAt)
+	assert.Equal(t, fresh.UpdatedAt.Add(30*time.Second), *dispatch.SettlesAt)
+
+	for _, skipped := range []watcherResult{
+		{Path: "/mnt/dropbox/a/clip.mov", Size: 2000},
+		{Path: "/mnt/dropbox/a/clip.mxf", Size: 10},
+		{Path: "/mnt/dropbox/a/b/clip.mxf", Size: 2000},
+	} {
+		_, err = table.resolve(skipped, now)
+		assert.ErrorIs(t, err, errNoWatcherRoute, skipped.Path)
+	}
+}
+
+func Test_LoadWatcherRoutes_File(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "routes.json")
+	require.NoError(t, os.WriteFile(file, []byte(`[{"name":"x","prefixes":["/mnt/x/"],"workflow":"CopyFile","params":{"Source":"{{.Path}}"}}]`), 0644))
+
+	table, err := loadWatcherRoutes(file)
+	require.NoError(t, err)
+	assert.Equal(t, file, table.Source)
+	require.Len(t, table.Routes, 1)
+
+	require.NoError(t, os.WriteFile(file, []byte(`{`), 0644))
+	_, err = loadWatcherRoutes(file)
+	assert.Error(t, err)
+}
+
+type recordingClient struct {
+	client.Client
+
+	options  client.StartWorkflowOptions
+	workflow any
+	args     []any
+}
+
+func (c *recordingClient) ExecuteWorkflow(_ context.Context, options client.StartWorkflowOptions, workflow any, args ...any) (client.WorkflowRun, error) {
+	c.options = options
+	c.workflow = workflow
+	c.args = args
+	return stubRun{}, nil
+}
+
+func watcherRequest(t *testing.T, event watcherResult) *httptest.ResponseRecorder {
+	t.Helper()
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.POST("/watchers", watchersHandler)
+
+	body, err := json.Marshal(event)
+	require.NoError(t, err)
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/watchers", bytes.NewReader(body)))
+	return rec
+}
+
+func withWatcherRoutes(t *testing.T, table *watcherRoutes) {
+	t.Helper()
+
+	previous := getWatcherRoutes()
+	setWatcherRoutes(table)
+	t.Cleanup(func() { setWatcherRoutes(previous) })
+}
+
+func Test_WatchersHandler_StartsRoutedWorkflow(t *testing.T) {
+	withWatcherRoutes(t, defaultRoutes(t))
+	c := &recordingClient{}
+	withClient(t, c)
+
+	rec := watcherRequest(t, watcherResult{Path: "/mnt/filecatalyst/ingestgrow/live.mxf"})
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "Incremental", c.workflow)
+	assert.Equal(t, "LIVE-INGEST", c.options.ID)
+	assert.Equal(t, "worker", c.options.TaskQueue)
+	require.Len(t, c.args, 1)
+	assert.JSONEq(t, `{"Path":"/mnt/filecatalyst/ingestgrow/live.mxf","Source":""}`, toJSON(t, c.args[0]))
+}
+
+func Test_WatchersHandler_Unmatched_Returns500(t *testing.T) {
+	withWatcherRoutes(t, defaultRoutes(t))
+	withClient(t, &recordingClient{})
+
+	rec := watcherRequest(t, watcherResult{Path: "/mnt/elsewhere/file.mov"})
+
+	assert.Equal(t, http.StatusInternalServerError, rec.Code)
+	assert.Contains(t, rec.Body.String(), "/mnt/elsewhere/file.mov")
+}
+
+func Test_WatchersHandler_Unsettled_Returns425(t *testing.T) {
+	table, err := compileWatcherRoutes([]watcherRoute{{
+		Name:       "slow",
+		Prefixes:   []string{"/mnt/slow/"},
+		SettleTime: routeDuration(time.Hour),
+		Workflow:   "CopyFile",
+		Params:     map[string]any{"Source": "{{.Path}}"},
+	}}, "test")
+	require.NoError(t, err)
+	withWatcherRoutes(t, table)
+	c := &recordingClient{}
+	withClient(t, c)
+
+	rec := watcherRequest(t, watcherResult{Path: "/mnt/slow/file.mov", UpdatedAt: time.Now()})
+
+	assert.Equal(t, http.StatusTooEarly, rec.Code)
+	assert.Nil(t, c.workflow)
+}
+
+func Test_WatcherRoutesHandler(t *testing.T) {
+	withWatcherRoutes(t, defaultRoutes(t))
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.GET("/watchers/routes", watcherRoutesHandler)
+
+	get := func(url string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, url, nil))
+		return rec
+	}
+
+	rec := get("/watchers/routes")
+	require.Equal(t, http.StatusOK, rec.Code)
+	var table struct {
+		Source string         `json:"source"`
+		Routes []watcherRoute `json:"routes"`
+	}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &table))
+	assert.Equal(t, "built-in", table.Source)
+	assert.Len(t, table.Routes, 5)
+
+	rec = get("/watchers/routes?path=/mnt/filecatalyst/ingestgrow/live.mxf")
+	require.Equal(t, http.StatusOK, rec.Code)
+	var dispatch watcherDispatch
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &dispatch))
+	assert.Equal(t, "growing", dispatch.Route)
+	assert.Equal(t, "LIVE-INGEST", dispatch.WorkflowID)
+
+	assert.Equal(t, http.StatusNotFound, get("/watchers/routes?path=/mnt/elsewhere/x").Code)
+	assert.Equal(t, http.StatusBadRequest, get("/watchers/routes?path=/mnt/x&size=big").Code)
+}
@@ -2,19 +2,17 @@ package main
 
 import (
 	"context"
+	"errors"
 	"fmt"
-	"path/filepath"
-	"regexp"
-	"strings"
+	"net/http"
+	"strconv"
 	"time"
 
 	"github.com/bcc-code/bcc-media-flows/environment"
-	"github.com/bcc-code/bcc-media-flows/paths"
+	"github.com/bcc-code/bcc-media-flows/services/webhooks"
 	wfutils "github.com/bcc-code/bcc-media-flows/utils/workflows"
 	ingestworkflows "github.com/bcc-code/bcc-media-flows/workflows/ingest"
-	miscworkflows "github.com/bcc-code/bcc-media-flows/workflows/misc"
 	"github.com/gin-gonic/gin"
-	"github.com/google/uuid"
 )
 
 type watcherResult struct {
@@ -35,145 +33,87 @@ func watchersHandler(ctx *gin.Context) {
 
 	fmt.Printf("watcher event: path=%q size=%d updatedAt=%s\n", result.Path, result.Size, result.UpdatedAt.Format(time.RFC3339))
 
-	// This needs to match any subfolder
-	multitrackPath := strings.HasPrefix(result.Path, "/mnt/filecatalyst/multitrack/Ingest/tempFraBrunstad/")
-	growingPath := strings.HasPrefix(result.Path, "/mnt/filecatalyst/ingestgrow/")
-	rawImportIsilon := strings.HasPrefix(result.Path, "/mnt/isilon/Input/Rawmaterial/")
-	rawImportFileCatalyst := strings.HasPrefix(result.Path, "/mnt/filecatalyst/Rawmaterial/")
-	rawImportFileCatalystDelivery2 := strings.HasPrefix(result.Path, "/mnt/filecatalyst/delivery2/RawMaterial/")
-	fileboxSimpleUpload := strings.HasPrefix(result.Path, "/mnt/filecatalyst/delivery2/simple")
-
-	var branch string
-	if multitrackPath {
-		branch = "multitrack"
-		err = doMultitrackCopy(ctx, result.Path)
-	} else if growingPath {
-		branch = "growing"
-		err = doGrowingFile(ctx, result.Path)
-	} else if rawImportIsilon || rawImportFileCatalyst || rawImportFileCatalystDelivery2 {
-		branch = "raw-import"
-		err = doRawImport(ctx, result.Path)
-	} else if fileboxSimpleUpload {
-		branch = "simple-copy"
-		err = doSimpleCopy(ctx, result.Path)
-	} else {
-		branch = "transcode"
-		err = doTranscode(ctx, result.Path)
-	}
-	fmt.Printf("watcher dispatched: path=%q branch=%s\n", result.Path, branch)
-
+	dispatch, err := getWatcherRoutes().resolve(result, time.Now())
 	if err != nil {
 		fmt.Println(err.Error())
 		ctx.String(500, err.Error())
 		return
 	}
 
-	ctx.Status(200)
-}
-
-func doSimpleCopy(ctx context.Context, path string) error {
-	c, err := getClient()
-	if err != nil {
-		return err
+	if dispatch.SettlesAt != nil {
+		fmt.Printf("watcher deferred: path=%q branch=%s settlesAt=%s\n", result.Path, dispatch.Route, dispatch.SettlesAt.Format(time.RFC3339))
+		ctx.String(http.StatusTooEarly, "file has not settled yet, retry after %s", dispatch.SettlesAt.Format(time.RFC3339))
+		return
 	}
 
-	now := time.Now()
-	destination := filepath.Join(
-		"/mnt/isilon/Input/FromDelivery",
-		now.Format("2006/01/02"),
-		filepath.Base(path),
-	)
-
-	workflowOptions := wfutils.NewWorkflowOptions(environment.GetWorkerQueue(), "", "watcher")
+	err = doWatcherDispatch(ctx, dispatch)
+	fmt.Printf("watcher dispatched: path=%q branch=%s\n", result.Path, dispatch.Route)
 
-	_, err = c.ExecuteWorkflow(ctx, workflowOptions, miscworkflows.CopyFile, miscworkflows.CopyFileInput{
-		Source:      path,
-		Destination: destination,
-	})
-
-	return err
-}
-
-func doMultitrackCopy(ctx context.Context, path string) error {
-	c, err := getClient()
 	if err != nil {
-		return err
+		fmt.Println(err.Error())
+		ctx.String(500, err.Error())
+		return
 	}
 
-	workflowOptions := wfutils.NewWorkflowOptions(environment.GetWorkerQueue(), "", "watcher")
-
-	_, err = c.ExecuteWorkflow(ctx, workflowOptions, miscworkflows.HandleMultitrackFile, miscworkflows.HandleMultitrackFileInput{
-		Path: path,
-	})
-
-	return err
+	ctx.Status(200)
 }
 
-func doGrowingFile(ctx context.Context, path string) error {
+func doWatcherDispatch(ctx context.Context, dispatch *watcherDispatch) error {
 	c, err := getClient()
 	if err != nil {
 		return err
 	}
 
-	workflowOptions := wfutils.NewWorkflowOptions(environment.GetWorkerQueue(), "", "watcher")
-	workflowOptions.ID = "LIVE-INGEST" // Fixed ID for the incremental workflow
-
-	_, err = c.ExecuteWorkflow(ctx, workflowOptions, ingestworkflows.Incremental, ingestworkflows.IncrementalParams{
-		Path: path,
-	})
+	workflowOptions := wfutils.NewWorkflowOptions(dispatch.TaskQueue, "", "watcher")
+	if dispatch.WorkflowID != "" {
+		workflowOptions.ID = dispatch.WorkflowID
+	}
 
+	_, err = c.ExecuteWorkflow(ctx, workflowOptions, dispatch.Workflow, dispatch.Params)
 	return err
 }
 
-var exp = regexp.MustCompile(fmt.Sprintf("(?:%s/)(?P<encoding>[\\w-]*)(?:/in/)", environment.Get().Paths.TranscodeRoot()))
+// watcherRoutesHandler shows the routing table in effect. With a path it instead shows
+// what an event for that path would start, without starting anything.
+func watcherRoutesHandler(ctx *gin.Context) {
+	table := getWatcherRoutes()
 
-func doTranscode(ctx context.Context, path string) error {
-	match := exp.MatchString(path)
-	if !match {
-		return fmt.Errorf("%s not matched", path)
+	path := ctx.Query("path")
+	if path == "" {
+		ctx.JSON(200, table)
+		return
 	}
 
-	c, err := getClient()
-	if err != nil {
-		return err
+	event := watcherResult{Path: path}
+	if size := ctx.Query("size"); size != "" {
+		var err error
+		event.Size, err = strconv.ParseInt(size, 10, 64)
+		if err != nil {
+			ctx.String(400, "invalid size: %s", err.Error())
+			return
+		}
 	}
 
-	matches := exp.FindStringSubmatch(path)
-	t := matches[1]
-
-	workflowOptions := wfutils.NewWorkflowOptions(environment.GetWorkerQueue(), "", "watcher")
-
-	_, err = c.ExecuteWorkflow(ctx, workflowOptions, miscworkflows.WatchFolderTranscode, miscworkflows.WatchFolderTranscodeInput{
-		Path:       path,
-		FolderName: t,
-	})
-	return err
-}
-
-func doRawImport(ctx context.Context, path string) error {
-	c, err := getClient()
-	if err != nil {
-		return err
+	dispatch, err := table.resolve(event, time.Now())
+	if errors.Is(err, errNoWatcherRoute) {
+		ctx.String(404, err.Error())
+		return
 	}
-
-	workflowOptions := wfutils.NewWorkflowOptions(environment.GetWorkerQueue(), "", "watcher")
-	workflowOptions.ID = "RAWIMPORT-" + uuid.NewString()
-
-	parsedPath, err := paths.Parse(path)
 	if err != nil {
-		return err
+		ctx.String(500, err.Error())
+		return
 	}
 
-	_, err = c.ExecuteWorkflow(ctx, workflowOptions, ingestworkflows.RawMaterial, ingestworkflows.RawMaterialParams{
-		FilesToIngest: paths.Files{parsedPath},
-	})
-
-	return err
+	ctx.JSON(200, dispatch)
 }
 
 type sidecarIngestRequest struct {
 	Sidecar string `json:"sidecar"`
 	Path    string `json:"path"`
+	// CallbackURL is posted the outcome of the ingest, signed with the secret
+	// CallbackSecretID names when there is one.
+	CallbackURL      string `json:"callbackUrl" binding:"omitempty,http_url"`
+	CallbackSecretID string `json:"callbackSecretId"`
 }
 
 // jsonIngestHandler handles the dedicated JSON ingest endpoint. The body is a
@@ -191,10 +131,17 @@ func jsonIngestHandler(ctx *gin.Context) {
 		ctx.String(400, "missing path")
 		return
 	}
+	if !knownCallbackSecret(req.CallbackSecretID) {
+		ctx.String(400, "unknown callbackSecretId")
+		return
+	}
 
 	fmt.Printf("json ingest: sidecar=%q path=%q\n", req.Sidecar, req.Path)
 
-	err := doIngestJSON(ctx, req.Path)
+	err := doIngestJSON(ctx, req.Path, webhooks.Callback{
+		URL:      req.CallbackURL,
+		SecretID: req.CallbackSecretID,
+	})
 	if err != nil {
 		fmt.Println(err.Error())
 		ctx.String(500, err.Error())
@@ -204,13 +151,14 @@ func jsonIngestHandler(ctx *gin.Context) {
 	ctx.Status(200)
 }
 
-func doIngestJSON(ctx context.Context, jsonPath string) error {
+func doIngestJSON(ctx context.Context, jsonPath string, callback webhooks.Callback) error {
 	c, err := getClient()
 	if err != nil {
 		return err
 	}
 
 	workflowOptions := wfutils.NewWorkflowOptions(environment.GetWorkerQueue(), "", "watcher")
+	workflowOptions = wfutils.WithCallback(workflowOptions, callback)
 
 	_, err = c.ExecuteWorkflow(ctx, workflowOptions, ingestworkflows.AssetJSON, ingestworkflows.AssetJSONParams{
 		JSONPath: jsonPath,
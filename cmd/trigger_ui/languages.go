@@ -0,0 +1,30 @@
+package main
+
+import (
+	"log"
+	"net/http"
+
+	"github.com/bcc-code/bcc-media-flows/environment"
+	"github.com/bcc-code/bcc-media-flows/languages"
+	"github.com/gin-gonic/gin"
+)
+
+// configureLanguages loads the language table the worker is given, so the forms offer
+// the same languages and the table can be looked at. A file that cannot be read or is
+// not valid leaves the table built in.
+func configureLanguages(cfg environment.Languages) {
+	if cfg.File() == "" {
+		return
+	}
+
+	table, err := languages.LoadFile(cfg.File())
+	if err != nil {
+		log.Printf("Error loading the language table, using the one built in: %v", err)
+		return
+	}
+	languages.Configure(table)
+}
+
+func (s *TriggerServer) languagesGET(ctx *gin.Context) {
+	ctx.HTML(http.StatusOK, "languages.gohtml", languages.Current())
+}
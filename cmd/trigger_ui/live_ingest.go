@@ -0,0 +1,96 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"path"
+
+	ingestworkflows "github.com/bcc-code/bcc-media-flows/workflows/ingest"
+	"github.com/gin-gonic/gin"
+	workflowpb "go.temporal.io/api/workflow/v1"
+	workflowservice "go.temporal.io/api/workflowservice/v1"
+)
+
+// liveIngestQuery finds the live ingests that are running, from every source.
+const liveIngestQuery = "WorkflowType = 'Incremental' AND ExecutionStatus = 'Running'"
+
+type LiveIngestDetails struct {
+	WorkflowID string
+	Start      string
+	Status     ingestworkflows.LiveIngestStatus
+	// Error is why the status could not be asked for.
+	Error string
+}
+
+func (s *TriggerServer) runningLiveIngests(ctx context.Context) ([]*workflowpb.WorkflowExecutionInfo, error) {
+	resp, err := s.wfClient.ListWorkflow(ctx, &workflowservice.ListWorkflowExecutionsRequest{
+		Query: liveIngestQuery,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return resp.Executions, nil
+}
+
+// liveIngestSource is the source a transferred file is from: the folder it is in. A
+// file of the default source is in none, and the folder is then not a source's.
+func liveIngestSource(linuxPath string) string {
+	return path.Base(path.Dir(linuxPath))
+}
+
+// signalLiveIngest tells the live ingest of the source a file is from that the file has
+// been transferred, with its path, and returns the ID of the ingest. When no ingest of
+// that source is running, the one of the default source is told, if it is.
+func (s *TriggerServer) signalLiveIngest(ctx context.Context, linuxPath string) (string, error) {
+	executions, err := s.runningLiveIngests(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	running := map[string]string{}
+	for _, exec := range executions {
+		running[exec.Execution.GetWorkflowId()] = exec.Execution.GetRunId()
+	}
+
+	for _, workflowID := range []string{
+		ingestworkflows.LiveIngestWorkflowIDFor(liveIngestSource(linuxPath)),
+		ingestworkflows.LiveIngestWorkflowIDFor(""),
+	} {
+		runID, ok := running[workflowID]
+		if !ok {
+			continue
+		}
+		return workflowID, s.wfClient.SignalWorkflow(ctx, workflowID, runID, ingestworkflows.FileTransferredSignal, linuxPath)
+	}
+	return "", nil
+}
+
+func (s *TriggerServer) liveIngestGET(ctx *gin.Context) {
+	executions, err := s.runningLiveIngests(ctx)
+	if err != nil {
+		renderErrorPage(ctx, http.StatusInternalServerError, err)
+		return
+	}
+
+	var ingests []LiveIngestDetails
+	for _, exec := range executions {
+		details := LiveIngestDetails{
+			WorkflowID: exec.Execution.GetWorkflowId(),
+			Start:      exec.GetStartTime().AsTime().Format("2006-01-02 15:04:05"),
+		}
+
+		value, err := s.wfClient.QueryWorkflow(ctx, details.WorkflowID, exec.Execution.GetRunId(), ingestworkflows.LiveIngestStatusQuery)
+		if err == nil {
+			err = value.Get(&details.Status)
+		}
+		if err != nil {
+			details.Error = err.Error()
+		}
+
+		ingests = append(ingests, details)
+	}
+
+	ctx.HTML(http.StatusOK, "live-ingest.gohtml", gin.H{
+		"Ingests": ingests,
+	})
+}
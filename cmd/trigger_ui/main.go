@@ -22,6 +22,7 @@ import (
 	"os"
 
 	"github.com/bcc-code/bcc-media-flows/languages"
+	"github.com/bcc-code/bcc-media-flows/paths"
 	"github.com/bcc-code/bcc-media-flows/services/vidispine"
 	"github.com/bcc-code/bcc-media-flows/services/vidispine/vsapi"
 	"github.com/bcc-code/bcc-media-flows/services/vidispine/vscommon"
@@ -235,10 +236,16 @@ func (s *TriggerServer) vxExportPOST(ctx *gin.Context) {
 	vxID := ctx.Query("id")
 	languages := ctx.PostFormArray("languages[]")
 	audioSource := ctx.PostForm("audioSource")
+	dryRun := ctx.PostForm("dryRun") == "on"
 
 	workflowOptions := wfutils.NewWorkflowOptions(environment.GetQueue(), vxID, getTriggeredBy(ctx))
 
 	go func() {
+		// A plan should leave the item as it was.
+		if dryRun {
+			return
+		}
+
 		err := s.vidispine.SetItemMetadataField(vsapi.ItemMetadataFieldParams{
 			ItemID: vxID,
 			Key:    vscommon.FieldExportAudioSource.Value,
@@ -313,11 +320,17 @@ func (s *TriggerServer) vxExportPOST(ctx *gin.Context) {
 		Destinations:  ctx.PostFormArray("destinations[]"),
 		Languages:     languages,
 		Resolutions:   selectedResolutions,
+		DryRun:        dryRun,
+	}
+
+	subclips := ctx.PostFormArray("subclips[]")
+	if dryRun {
+		s.vxExportPlan(ctx, workflowOptions, params, subclips)
+		return
 	}
 
 	var wfID string
 
-	subclips := ctx.PostFormArray("subclips[]")
 	if len(subclips) > 0 {
 		for _, subclip := range subclips {
 			params.Subclip = subclip
@@ -354,6 +367,43 @@ func (s *TriggerServer) vxExportPOST(ctx *gin.Context) {
 	})
 }
 
+// vxExportPlan runs the export as a dry run, once for each subclip if any are chosen,
+// and shows the plans. A dry run only reads, so it is waited for.
+func (s *TriggerServer) vxExportPlan(ctx *gin.Context, workflowOptions client.StartWorkflowOptions, params export.VXExportParams, subclips []string) {
+	if len(subclips) == 0 {
+		subclips = []string{""}
+	}
+
+	var plans []*export.ExportPlan
+	for _, subclip := range subclips {
+		params.Subclip = subclip
+		workflowOptions.ID = uuid.NewString()
+		res, err := s.wfClient.ExecuteWorkflow(ctx, workflowOptions, export.VXExport, params)
+		if err != nil {
+			renderErrorPage(ctx, http.StatusInternalServerError, err)
+			return
+		}
+
+		var results []wfutils.ResultOrError[export.VXExportResult]
+		err = res.Get(ctx, &results)
+		if err != nil {
+			renderErrorPage(ctx, http.StatusInternalServerError, err)
+			return
+		}
+
+		for _, result := range results {
+			if result.Result != nil && result.Result.Plan != nil {
+				plans = append(plans, result.Result.Plan)
+			}
+		}
+	}
+
+	ctx.HTML(http.StatusOK, "vx-export-plan.gohtml", gin.H{
+		"ID":    params.VXID,
+		"Plans": plans,
+	})
+}
+
 func (s *TriggerServer) vxExportTimedMetadataPOST(ctx *gin.Context) {
 	vxID := ctx.PostForm("id")
 	workflowOptions := wfutils.NewWorkflowOptions(environment.GetQueue(), vxID, getTriggeredBy(ctx))
@@ -372,14 +422,30 @@ func (s *TriggerServer) vxExportTimedMetadataPOST(ctx *gin.Context) {
 }
 
 type MoveFilesGETParams struct {
-	Storages []miscworkflows.MBStorage
+	Storages []paths.Storage
 	Error    string
 	Success  string
 }
 
+// configureStorages loads the storage registry the worker is given, so the move form
+// offers the same storages. A file that cannot be read or is not valid leaves the
+// registry built in.
+func configureStorages(cfg environment.Storages) {
+	if cfg.File() == "" {
+		return
+	}
+
+	registry, err := paths.LoadFile(cfg.File())
+	if err != nil {
+		log.Printf("Error loading the storage registry, using the one built in: %v", err)
+		return
+	}
+	paths.Configure(registry)
+}
+
 func (s *TriggerServer) moveFilesGET(ctx *gin.Context) {
 	ctx.HTML(http.StatusOK, "move-files.gohtml", MoveFilesGETParams{
-		Storages: miscworkflows.Storages,
+		Storages: paths.Current().VidispineStorages(),
 	})
 }
 
@@ -389,7 +455,7 @@ func (s *TriggerServer) moveFilesPOST(ctx *gin.Context) {
 
 	if vxIDs == "" {
 		ctx.HTML(http.StatusOK, "move-files.gohtml", MoveFilesGETParams{
-			Storages: miscworkflows.Storages,
+			Storages: paths.Current().VidispineStorages(),
 			Error:    "VX IDs are required",
 		})
 		return
@@ -397,7 +463,7 @@ func (s *TriggerServer) moveFilesPOST(ctx *gin.Context) {
 
 	if destinationStorage == "" {
 		ctx.HTML(http.StatusOK, "move-files.gohtml", MoveFilesGETParams{
-			Storages: miscworkflows.Storages,
+			Storages: paths.Current().VidispineStorages(),
 			Error:    "Destination storage is required",
 		})
 		return
@@ -423,7 +489,7 @@ func (s *TriggerServer) moveFilesPOST(ctx *gin.Context) {
 
 	if len(vxIDList) == 0 {
 		ctx.HTML(http.StatusOK, "move-files.gohtml", MoveFilesGETParams{
-			Storages: miscworkflows.Storages,
+			Storages: paths.Current().VidispineStorages(),
 			Error:    "No valid VX IDs found",
 		})
 		return
@@ -449,14 +515,14 @@ func (s *TriggerServer) moveFilesPOST(ctx *gin.Context) {
 
 	if successCount == 0 {
 		ctx.HTML(http.StatusOK, "move-files.gohtml", MoveFilesGETParams{
-			Storages: miscworkflows.Storages,
+			Storages: paths.Current().VidispineStorages(),
 			Error:    "Failed to start workflows for any VX IDs",
 		})
 		return
 	}
 
 	ctx.HTML(http.StatusOK, "move-files.gohtml", MoveFilesGETParams{
-		Storages: miscworkflows.Storages,
+		Storages: paths.Current().VidispineStorages(),
 		Success:  fmt.Sprintf("Successfully started move workflows for %d out of %d VX IDs", successCount, len(vxIDList)),
 	})
 }
@@ -465,6 +531,9 @@ func main() {
 	bootstrap.LoadEnv()
 	environment.Load()
 	environment.WarnMissing(environment.RequiredByTriggerUI)
+	configureLanguages(environment.Get().Languages)
+	configureStorages(environment.Get().Storages)
+	configureVBExport(environment.Get().VBExport)
 
 	router := gin.Default()
 
@@ -555,6 +624,10 @@ func main() {
 
 	router.GET("/workflow/:id", server.workflowDetailsGET)
 
+	router.GET("/live-ingest", server.liveIngestGET)
+
+	router.GET("/languages", server.languagesGET)
+
 	router.GET("/", func(ctx *gin.Context) {
 		ctx.HTML(http.StatusOK, "index.gohtml", nil)
 	})
@@ -15,7 +15,7 @@ import (
 
 type VBTriggerGETParams struct {
 	Title          string
-	Destinations   []string
+	Destinations   []vb_export.DestinationOption
 	SubtitleShapes []string
 	SubtitleStyles []string
 }
@@ -54,12 +54,26 @@ func (s *TriggerServer) vbExportGET(ctx *gin.Context) {
 
 	ctx.HTML(http.StatusOK, "vb-export.gohtml", VBTriggerGETParams{
 		Title:          title,
-		Destinations:   vb_export.Destinations.Values(),
+		Destinations:   vb_export.DestinationOptions(),
 		SubtitleShapes: subtitleShapes,
 		SubtitleStyles: subStyles,
 	})
 }
 
+// configureVBExport loads the VB export profiles, so the form offers them.
+func configureVBExport(cfg environment.VBExport) {
+	if cfg.ProfilesFile() == "" {
+		return
+	}
+
+	profiles, err := vb_export.LoadProfiles(cfg.ProfilesFile())
+	if err != nil {
+		log.Printf("Error loading VB export profiles: %v", err)
+		return
+	}
+	vb_export.ConfigureProfiles(profiles)
+}
+
 func (s *TriggerServer) vbExportPOST(ctx *gin.Context) {
 	vxID := ctx.Query("id")
 
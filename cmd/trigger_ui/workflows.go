@@ -190,22 +190,27 @@ func (s *TriggerServer) fileCatalystWebhookHandler(ctx *gin.Context) {
 	// Extract just the filename
 	filename := filepath.Base(linuxPath)
 
-	// Send signal to the LIVE-INGEST workflow
-	workflowID := "LIVE-INGEST"
-	signalName := "file_transferred"
-
-	// Send the signal with just the filename
-	err := s.wfClient.SignalWorkflow(ctx, workflowID, "", signalName, filename)
+	// The live ingest of the source the file is from is told, with the whole path, as
+	// rooms can record files of the same name.
+	signalled, err := s.signalLiveIngest(ctx, linuxPath)
 	if err != nil {
 		ctx.JSON(http.StatusInternalServerError, gin.H{
 			"error": fmt.Sprintf("Failed to send signal: %s", err.Error()),
 		})
 		return
 	}
+	if signalled == "" {
+		ctx.JSON(http.StatusNotFound, gin.H{
+			"error":    "No live ingest is running for the file",
+			"filename": filename,
+		})
+		return
+	}
 
 	ctx.JSON(http.StatusOK, gin.H{
 		"message":  "Signal sent successfully",
 		"filename": filename,
+		"workflow": signalled,
 	})
 }
 
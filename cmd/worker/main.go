@@ -6,9 +6,13 @@ import (
 	"fmt"
 	"github.com/bcc-code/bcc-media-flows/internal/bootstrap"
 	cantemo "github.com/bcc-code/bcc-media-flows/services/cantemo"
+	"github.com/bcc-code/bcc-media-flows/services/s3"
+	"github.com/bcc-code/bcc-media-flows/services/subtitles"
 	"github.com/bcc-code/bcc-media-flows/services/subtrans"
+	"github.com/bcc-code/bcc-media-flows/services/vidispine"
 	"github.com/bcc-code/bcc-media-flows/services/vidispine/vsapi"
 	"log"
+	"net/http"
 	"os"
 	"os/exec"
 	"reflect"
@@ -16,17 +20,22 @@ import (
 	"time"
 
 	"github.com/bcc-code/bcc-media-flows/analytics"
+	"github.com/bcc-code/bcc-media-flows/cache"
 	"github.com/bcc-code/bcc-media-flows/services/clickup"
 	"github.com/bcc-code/bcc-media-flows/services/directus"
+	"github.com/bcc-code/bcc-media-flows/services/notifications/routing"
 	"github.com/bcc-code/bcc-media-flows/services/vizualizer"
 	wfutils "github.com/bcc-code/bcc-media-flows/utils/workflows"
 	miscworkflows "github.com/bcc-code/bcc-media-flows/workflows/misc"
 
 	"github.com/bcc-code/bcc-media-flows/services/rclone"
 	"github.com/bcc-code/bcc-media-flows/workflows"
+	"github.com/bcc-code/bcc-media-flows/workflows/vb_export"
 
 	"github.com/bcc-code/bcc-media-flows/activities"
 	"github.com/bcc-code/bcc-media-flows/environment"
+	"github.com/bcc-code/bcc-media-flows/languages"
+	"github.com/bcc-code/bcc-media-flows/paths"
 	"github.com/teamwork/reload"
 	"go.temporal.io/sdk/activity"
 	"go.temporal.io/sdk/interceptor"
@@ -107,6 +116,7 @@ func main() {
 		DataPlane: environment.Get().Rudderstack.DataPlaneURL(),
 		Verbose:   false,
 	})
+	serveMetrics(environment.Get().Metrics, environment.Get().Rclone)
 
 	identity := bootstrap.Identity()
 
@@ -142,22 +152,177 @@ func main() {
 		BackgroundActivityContext:          context.WithValue(ctx, miscworkflows.ClientContextKey, c),
 		Interceptors: []interceptor.WorkerInterceptor{
 			&wfutils.AnalyticsWorkerInterceptor{},
+			&wfutils.CallbackWorkerInterceptor{},
 		},
 	}
 
 	if environment.Get().Rclone.Password() != "" {
+		configureRclone(environment.Get().Rclone)
 		go rclone.StartFileTransferQueue()
 	}
 
+	configureCache(environment.Get().Cache)
+	configureNotifications(environment.Get().Notifications)
+	configureSubtitles(environment.Get().Subtitles)
+	configureLanguages(environment.Get().Languages)
+	configureStorages(environment.Get().Storages)
+	configureVBExport(environment.Get().VBExport)
+
 	buildClients(environment.Get())
+	activities.Util.Temporal = c
 
 	registerWorker(c, environment.GetQueue(), workerOptions)
 }
 
+// serveMetrics adds a Prometheus sink to analytics and serves it, when an address is
+// configured. The rclone transfer queue is served next to it.
+func serveMetrics(cfg environment.Metrics, rcloneCfg environment.Rclone) {
+	if cfg.Addr() == "" {
+		return
+	}
+
+	sink := analytics.NewPrometheusSink()
+	analytics.GetService().AddSink(sink)
+
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", sink.Handler())
+	mux.Handle("/rclone/queue", rclone.DefaultScheduler().QueueHandler(rcloneCfg.QueueKey()))
+
+	go func() {
+		log.Printf("Serving metrics on %s", cfg.Addr())
+		err := http.ListenAndServe(cfg.Addr(), mux)
+		log.Printf("Metrics server stopped: %v", err)
+	}()
+}
+
+// configureCache moves the cache to a file shared by the workers on the host, when one
+// is configured, and logs its stats now and then.
+func configureCache(cfg environment.Cache) {
+	if cfg.Path() != "" {
+		store, err := cache.OpenSQLite(cfg.Path(), cfg.MaxEntries())
+		if err != nil {
+			log.Printf("Error opening cache %s, caching in memory: %v", cfg.Path(), err)
+			cache.SetDefault(cache.NewMemory(cfg.MaxEntries()))
+		} else {
+			cache.SetDefault(store)
+		}
+	} else {
+		cache.SetDefault(cache.NewMemory(cfg.MaxEntries()))
+	}
+
+	go func() {
+		for range time.Tick(15 * time.Minute) {
+			log.Printf("Cache stats: %+v", cache.Default().Stats())
+		}
+	}()
+}
+
+// configureNotifications routes notifications as the routes file says. A file that
+// cannot be read leaves them going where the workflows send them.
+func configureNotifications(cfg environment.Notifications) {
+	if cfg.RoutesFile() == "" {
+		return
+	}
+
+	config, err := routing.LoadConfig(cfg.RoutesFile())
+	if err != nil {
+		log.Printf("Error loading notification routes, sending notifications where the workflows do: %v", err)
+		return
+	}
+	routing.SetDefault(routing.New(config))
+	log.Printf("Loaded %d notification routes from %s", len(config.Routes), cfg.RoutesFile())
+}
+
+// configureRclone loads the transfer budgets per remote. A file that cannot be read
+// leaves the default limits.
+func configureRclone(cfg environment.Rclone) {
+	if cfg.SchedulerFile() == "" {
+		return
+	}
+
+	config, err := rclone.LoadSchedulerConfig(cfg.SchedulerFile())
+	if err != nil {
+		log.Printf("Error loading rclone scheduler budgets, using the defaults: %v", err)
+		return
+	}
+	rclone.ConfigureScheduler(config)
+	log.Printf("Loaded rclone budgets for %d remotes from %s", len(config.Remotes), cfg.SchedulerFile())
+}
+
+// configureSubtitles loads the subtitle QC rules per language. A file that cannot be
+// read leaves the default rules, which only warn.
+func configureSubtitles(cfg environment.Subtitles) {
+	if cfg.QCFile() == "" {
+		return
+	}
+
+	config, err := subtitles.LoadQCConfig(cfg.QCFile())
+	if err != nil {
+		log.Printf("Error loading subtitle QC rules, using the defaults: %v", err)
+		return
+	}
+	subtitles.ConfigureQC(config)
+	log.Printf("Loaded subtitle QC rules for %d languages from %s", len(config.Languages), cfg.QCFile())
+}
+
+// configureLanguages loads the language table. A file that cannot be read or is not
+// valid leaves the table built in.
+func configureLanguages(cfg environment.Languages) {
+	if cfg.File() == "" {
+		return
+	}
+
+	table, err := languages.LoadFile(cfg.File())
+	if err != nil {
+		log.Printf("Error loading the language table, using the one built in: %v", err)
+		return
+	}
+	languages.Configure(table)
+	log.Printf("Loaded version %d of the language table, %d languages, from %s", table.Version, len(table.Languages), cfg.File())
+}
+
+// configureStorages loads the storage registry. A file that cannot be read or is not
+// valid leaves the registry built in, configured again so the mounts the environment
+// has for its drives, which were not loaded when it was first configured, apply.
+func configureStorages(cfg environment.Storages) {
+	if cfg.File() == "" {
+		paths.Configure(paths.Current())
+		return
+	}
+
+	registry, err := paths.LoadFile(cfg.File())
+	if err != nil {
+		log.Printf("Error loading the storage registry, using the one built in: %v", err)
+		paths.Configure(paths.Current())
+		return
+	}
+	paths.Configure(registry)
+	log.Printf("Loaded version %d of the storage registry, %d storages, from %s", registry.Version, len(registry.Storages), cfg.File())
+}
+
+// configureVBExport loads the VB export profiles. A file that cannot be read leaves
+// only the built-in destinations.
+func configureVBExport(cfg environment.VBExport) {
+	if cfg.ProfilesFile() == "" {
+		return
+	}
+
+	profiles, err := vb_export.LoadProfiles(cfg.ProfilesFile())
+	if err != nil {
+		log.Printf("Error loading VB export profiles: %v", err)
+		return
+	}
+	vb_export.ConfigureProfiles(profiles)
+	log.Printf("Loaded %d VB export profiles from %s", len(profiles), cfg.ProfilesFile())
+}
+
 // buildClients constructs every service client once, from the configuration, and hands
 // them to the activities that use them. Nothing reaches for a client later.
 func buildClients(cfg *environment.Config) {
-	vsClient := vsapi.NewClient(cfg.Vidispine)
+	var vsClient vidispine.Client = vsapi.NewClient(cfg.Vidispine)
+	if ttl := cfg.Cache.VidispineTTL(); ttl > 0 {
+		vsClient = vidispine.NewCachedClient(vsClient, ttl)
+	}
 	cantemoClient := cantemo.NewClient(cfg.Cantemo)
 
 	activities.Vidispine.Client = vsClient
@@ -169,6 +334,17 @@ func buildClients(cfg *environment.Config) {
 	activities.Util.Vidispine = vsClient
 	activities.Util.Subtrans = subtrans.NewClient(cfg.Subtrans)
 
+	activities.Util.S3 = map[string]*s3.Client{}
+	for remote, s3Cfg := range cfg.S3.Remotes() {
+		if s3Cfg.Configured() {
+			activities.Util.S3[remote] = s3.NewClient(s3Cfg)
+		}
+	}
+	activities.Util.ColdArchive = cfg.ColdArchive
+	activities.Util.CallbackSecrets = cfg.Callbacks.Secrets()
+
+	activities.Live.Reapers = cfg.LiveIngest.Reapers()
+
 	activities.Directus = &activities.DirectusActivities{
 		Client:         directus.NewClient(cfg.Directus),
 		ShortsFolderID: cfg.Directus.ShortsFolderID(),
@@ -0,0 +1,79 @@
+package dash
+
+import "encoding/xml"
+
+type MPD struct {
+	XMLName                   xml.Name `xml:"MPD"`
+	Xmlns                     string   `xml:"xmlns,attr"`
+	Profiles                  string   `xml:"profiles,attr"`
+	Type                      string   `xml:"type,attr"`
+	MediaPresentationDuration string   `xml:"mediaPresentationDuration,attr"`
+	MinBufferTime             string   `xml:"minBufferTime,attr"`
+	Period                    Period   `xml:"Period"`
+}
+
+type Period struct {
+	ID             string          `xml:"id,attr"`
+	Start          string          `xml:"start,attr"`
+	AdaptationSets []AdaptationSet `xml:"AdaptationSet"`
+}
+
+type AdaptationSet struct {
+	ID               int              `xml:"id,attr"`
+	ContentType      string           `xml:"contentType,attr"`
+	MimeType         string           `xml:"mimeType,attr"`
+	Lang             string           `xml:"lang,attr,omitempty"`
+	Label            string           `xml:"Label,omitempty"`
+	SegmentAlignment bool             `xml:"segmentAlignment,attr,omitempty"`
+	Roles            []Role           `xml:"Role"`
+	Representations  []Representation `xml:"Representation"`
+}
+
+type Role struct {
+	SchemeIDURI string `xml:"schemeIdUri,attr"`
+	Value       string `xml:"value,attr"`
+}
+
+type Representation struct {
+	ID                string                     `xml:"id,attr"`
+	Bandwidth         int                        `xml:"bandwidth,attr"`
+	Codecs            string                     `xml:"codecs,attr,omitempty"`
+	Width             int                        `xml:"width,attr,omitempty"`
+	Height            int                        `xml:"height,attr,omitempty"`
+	AudioSamplingRate int                        `xml:"audioSamplingRate,attr,omitempty"`
+	AudioChannels     *AudioChannelConfiguration `xml:"AudioChannelConfiguration"`
+	BaseURL           string                     `xml:"BaseURL,omitempty"`
+	SegmentList       *SegmentList               `xml:"SegmentList"`
+}
+
+type AudioChannelConfiguration struct {
+	SchemeIDURI string `xml:"schemeIdUri,attr"`
+	Value       int    `xml:"value,attr"`
+}
+
+type SegmentList struct {
+	Timescale       int             `xml:"timescale,attr"`
+	Initialization  Initialization  `xml:"Initialization"`
+	SegmentTimeline SegmentTimeline `xml:"SegmentTimeline"`
+	SegmentURLs     []SegmentURL    `xml:"SegmentURL"`
+}
+
+type Initialization struct {
+	SourceURL string `xml:"sourceURL,attr"`
+}
+
+type SegmentTimeline struct {
+	S []S `xml:"S"`
+}
+
+// S is one run of equally long segments: D is the duration in the timescale of the
+// segment list, R the number of repeats after the first.
+type S struct {
+	T *int64 `xml:"t,attr"`
+	D int64  `xml:"d,attr"`
+	R int    `xml:"r,attr,omitempty"`
+}
+
+type SegmentURL struct {
+	Media string `xml:"media,attr"`
+}
@@ -41,6 +41,8 @@ type WavAudioInput struct {
 	Path            paths.Path
 	DestinationPath paths.Path
 	Timecode        string
+	// FrameRate is the rate Timecode is in, 25 when it is not set.
+	FrameRate utils.TimecodeRate
 }
 
 type AudioResult struct {
@@ -82,8 +84,50 @@ type PlayoutMuxInput struct {
 	SubtitleFilePaths map[string]paths.Path
 	OutputDir         paths.Path
 	FallbackLanguage  string
+	// Timecode is the start timecode of the muxed file, in the rate of the video. Empty
+	// takes the timecode of the video.
+	Timecode string
 }
 
 type PlayoutMuxResult struct {
 	Path paths.Path
 }
+
+type CMAFInput struct {
+	// VideoFiles are the video-only renditions, in the order the master playlist
+	// should list them.
+	VideoFiles        []paths.Path
+	AudioFilePaths    map[string]paths.Path
+	SubtitleFilePaths map[string]paths.Path
+	DestinationPath   paths.Path
+
+	// SegmentDuration is the target segment length in seconds. Zero means 6.
+	SegmentDuration int
+}
+
+type CMAFResult struct {
+	HLSPlaylist  paths.Path
+	DASHManifest paths.Path
+}
+
+type ThumbnailSpritesInput struct {
+	FilePath        paths.Path
+	DestinationPath paths.Path
+
+	// Interval is the seconds between thumbnails. Zero means 10.
+	Interval int
+	// Width is the width of a thumbnail, and the height follows the video. Zero means 160.
+	Width int
+	// Columns and Rows are the thumbnails per sprite sheet. Zero means 10 of each.
+	Columns int
+	Rows    int
+	// Format is "jpg" or "webp". Empty means jpg.
+	Format string
+}
+
+type ThumbnailSpritesResult struct {
+	Sprites []paths.Path
+	// VTTFile is the WebVTT thumbnails track, with a cue per thumbnail pointing into a
+	// sprite sheet with #xywh=.
+	VTTFile paths.Path
+}
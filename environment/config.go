@@ -6,6 +6,7 @@ import (
 	"strconv"
 	"strings"
 	"sync"
+	"time"
 )
 
 type Temporal struct {
@@ -60,6 +61,38 @@ type Subtrans struct {
 func (s Subtrans) BaseURL() string { return s.baseURL }
 func (s Subtrans) APIKey() string  { return s.apiKey }
 
+type Subtitles struct {
+	qcFile string
+}
+
+// QCFile is the JSON file with the subtitle QC rules and policy per language. Empty
+// checks every language against the default rules, and only warns.
+func (s Subtitles) QCFile() string { return s.qcFile }
+
+type Languages struct {
+	file string
+}
+
+// File is the JSON file with the language table, the channel mappings and codes of
+// every language. Empty uses the table built in.
+func (l Languages) File() string { return l.file }
+
+type Storages struct {
+	file string
+}
+
+// File is the JSON file with the storage registry: the drives paths are on and the
+// Vidispine storages. Empty uses the registry built in.
+func (s Storages) File() string { return s.file }
+
+type VBExport struct {
+	profilesFile string
+}
+
+// ProfilesFile is the JSON file with the VB export profiles, the destinations that are
+// configured rather than built in. Empty offers only the built-in ones.
+func (v VBExport) ProfilesFile() string { return v.profilesFile }
+
 type Directus struct {
 	baseURL        string
 	apiKey         string
@@ -83,13 +116,81 @@ func (c ClickUp) ShortsViewID() string     { return c.shortsViewID }
 func (c ClickUp) ShortsViewToken() string  { return c.shortsViewToken }
 
 type Rclone struct {
-	username string
-	password string
+	username      string
+	password      string
+	schedulerFile string
+	queueKey      string
 }
 
 func (r Rclone) Username() string { return r.username }
 func (r Rclone) Password() string { return r.password }
 
+// SchedulerFile is the JSON file with the transfer budgets per rclone remote. Empty
+// keeps the default limits.
+func (r Rclone) SchedulerFile() string { return r.schedulerFile }
+
+// QueueKey is the api-key that changing the priority of queued transfers takes. Empty
+// refuses every change.
+func (r Rclone) QueueKey() string { return r.queueKey }
+
+// S3Remote is an S3 account the worker uploads to directly, named like the rclone
+// remote that reaches the same buckets.
+type S3Remote struct {
+	endpoint        string
+	region          string
+	accessKeyID     string
+	secretAccessKey string
+}
+
+// Endpoint is an S3-compatible endpoint such as MinIO's. Empty means AWS in Region.
+func (s S3Remote) Endpoint() string        { return s.endpoint }
+func (s S3Remote) Region() string          { return s.region }
+func (s S3Remote) AccessKeyID() string     { return s.accessKeyID }
+func (s S3Remote) SecretAccessKey() string { return s.secretAccessKey }
+
+// Configured reports whether there are credentials for the remote.
+func (s S3Remote) Configured() bool { return s.accessKeyID != "" }
+
+type S3 struct {
+	s3prod      S3Remote
+	bmms3       S3Remote
+	coldarchive S3Remote
+}
+
+// Remotes are the S3 accounts by rclone remote name: s3prod for the asset ingest and
+// massive.io buckets, bmms3 for the BMM ones, and coldarchive for the bucket of the
+// cold archive.
+func (s S3) Remotes() map[string]S3Remote {
+	return map[string]S3Remote{
+		"s3prod":      s.s3prod,
+		"bmms3":       s.bmms3,
+		"coldarchive": s.coldarchive,
+	}
+}
+
+func s3Remote(prefix string) S3Remote {
+	return S3Remote{
+		endpoint:        os.Getenv(prefix + "_ENDPOINT"),
+		region:          os.Getenv(prefix + "_REGION"),
+		accessKeyID:     os.Getenv(prefix + "_ACCESS_KEY_ID"),
+		secretAccessKey: os.Getenv(prefix + "_SECRET_ACCESS_KEY"),
+	}
+}
+
+// ColdArchive is how originals are restored from the cold archive. The storage itself
+// is in the storage registry.
+type ColdArchive struct {
+	restoreTier string
+	restoreDays int
+}
+
+// RestoreTier is how fast S3 restores an archived original: Bulk, Standard or
+// Expedited, the cheapest and slowest first.
+func (c ColdArchive) RestoreTier() string { return c.restoreTier }
+
+// RestoreDays is how long S3 keeps the restored copy of an archived original.
+func (c ColdArchive) RestoreDays() int { return c.restoreDays }
+
 type FileCatalyst struct {
 	url      string
 	taskID   string
@@ -140,12 +241,10 @@ func (t Telegram) ChatBMM() int64       { return t.chatBMM }
 
 type Services struct {
 	shorts     string
-	sync       string
 	vizualizer string
 }
 
 func (s Services) Shorts() string { return s.shorts }
-func (s Services) Sync() string   { return s.sync }
 func (s Services) Vizualizer() string {
 	if s.vizualizer != "" {
 		return s.vizualizer
@@ -161,6 +260,75 @@ type TriggerUI struct {
 func (t TriggerUI) MassiveWebhookAPIKey() string { return t.massiveWebhookAPIKey }
 func (t TriggerUI) TriggeredByHeader() string    { return t.triggeredByHeader }
 
+type HTTPIn struct {
+	watcherRoutesFile string
+}
+
+// WatcherRoutesFile is the JSON routing table for watcher events. Empty means the
+// built-in routes.
+func (h HTTPIn) WatcherRoutesFile() string { return h.watcherRoutesFile }
+
+type Cache struct {
+	path         string
+	maxEntries   int
+	vidispineTTL time.Duration
+}
+
+// Path is the SQLite file the worker caches in, shared by every worker on the host.
+// Empty means an in-memory cache per process.
+func (c Cache) Path() string    { return c.path }
+func (c Cache) MaxEntries() int { return c.maxEntries }
+
+// VidispineTTL is how long Vidispine metadata is cached. Zero turns caching it off.
+func (c Cache) VidispineTTL() time.Duration { return c.vidispineTTL }
+
+type Metrics struct {
+	addr string
+}
+
+// Addr is where the worker serves /metrics, such as ":9090". Empty means it does not.
+func (m Metrics) Addr() string { return m.addr }
+
+type LiveIngest struct {
+	reapers map[string]string
+}
+
+// Reapers are the Reaper recorders by live ingest source, from "source=url" pairs
+// separated by commas. A source without one records on the default Reaper.
+func (l LiveIngest) Reapers() map[string]string { return l.reapers }
+
+// parsePairs reads "key=value" pairs separated by commas, skipping pairs without a key
+// or a value.
+func parsePairs(value string) map[string]string {
+	pairs := map[string]string{}
+	for _, pair := range strings.Split(value, ",") {
+		key, v, ok := strings.Cut(strings.TrimSpace(pair), "=")
+		key, v = strings.TrimSpace(key), strings.TrimSpace(v)
+		if !ok || key == "" || v == "" {
+			continue
+		}
+		pairs[key] = v
+	}
+	return pairs
+}
+
+type Callbacks struct {
+	secrets map[string]string
+}
+
+// Secrets are the secrets callbacks are signed with, by the ID a request names them
+// with, from "id=secret" pairs separated by commas. Requests only carry the ID, so the
+// secrets are not kept with the workflows.
+func (c Callbacks) Secrets() map[string]string { return c.secrets }
+
+type Notifications struct {
+	routesFile string
+}
+
+// RoutesFile is the JSON file the notification routes are read from. Empty means
+// every notification goes where the workflow sends it.
+func (n Notifications) RoutesFile() string { return n.routesFile }
+
 type Rudderstack struct {
 	writeKey     string
 	dataPlaneURL string
@@ -181,21 +349,33 @@ type Config struct {
 	SendgridAPIKey string
 	BMMFileBaseURL string
 
-	Temporal     Temporal
-	Paths        Paths
-	Vidispine    Vidispine
-	Cantemo      Cantemo
-	Subtrans     Subtrans
-	Directus     Directus
-	ClickUp      ClickUp
-	Rclone       Rclone
-	FileCatalyst FileCatalyst
-	PlayoutFTP   PlayoutFTP
-	RavenDB      RavenDB
-	Telegram     Telegram
-	Services     Services
-	TriggerUI    TriggerUI
-	Rudderstack  Rudderstack
+	Temporal      Temporal
+	Paths         Paths
+	Vidispine     Vidispine
+	Cantemo       Cantemo
+	Subtrans      Subtrans
+	Subtitles     Subtitles
+	Languages     Languages
+	Storages      Storages
+	VBExport      VBExport
+	Directus      Directus
+	ClickUp       ClickUp
+	Rclone        Rclone
+	S3            S3
+	ColdArchive   ColdArchive
+	FileCatalyst  FileCatalyst
+	PlayoutFTP    PlayoutFTP
+	RavenDB       RavenDB
+	Telegram      Telegram
+	Services      Services
+	TriggerUI     TriggerUI
+	HTTPIn        HTTPIn
+	Cache         Cache
+	Metrics       Metrics
+	LiveIngest    LiveIngest
+	Notifications Notifications
+	Callbacks     Callbacks
+	Rudderstack   Rudderstack
 }
 
 var (
@@ -245,6 +425,22 @@ func Load() *Config {
 			apiKey:  os.Getenv("SUBTRANS_API_KEY"),
 		},
 
+		Subtitles: Subtitles{
+			qcFile: os.Getenv("SUBTITLE_QC_FILE"),
+		},
+
+		Languages: Languages{
+			file: os.Getenv("LANGUAGES_FILE"),
+		},
+
+		Storages: Storages{
+			file: os.Getenv("STORAGES_FILE"),
+		},
+
+		VBExport: VBExport{
+			profilesFile: os.Getenv("VB_EXPORT_PROFILES_FILE"),
+		},
+
 		Directus: Directus{
 			baseURL:        os.Getenv("DIRECTUS_BASE_URL"),
 			apiKey:         os.Getenv("DIRECTUS_API_KEY"),
@@ -259,8 +455,21 @@ func Load() *Config {
 		},
 
 		Rclone: Rclone{
-			username: os.Getenv("RCLONE_USERNAME"),
-			password: os.Getenv("RCLONE_PASSWORD"),
+			username:      os.Getenv("RCLONE_USERNAME"),
+			password:      os.Getenv("RCLONE_PASSWORD"),
+			schedulerFile: os.Getenv("RCLONE_SCHEDULER_FILE"),
+			queueKey:      os.Getenv("RCLONE_QUEUE_API_KEY"),
+		},
+
+		S3: S3{
+			s3prod:      s3Remote("S3PROD"),
+			bmms3:       s3Remote("BMMS3"),
+			coldarchive: s3Remote("COLD_ARCHIVE"),
+		},
+
+		ColdArchive: ColdArchive{
+			restoreTier: stringOr("COLD_ARCHIVE_RESTORE_TIER", "Bulk"),
+			restoreDays: intOr("COLD_ARCHIVE_RESTORE_DAYS", 7),
 		},
 
 		FileCatalyst: FileCatalyst{
@@ -293,7 +502,6 @@ func Load() *Config {
 
 		Services: Services{
 			shorts:     os.Getenv("SHORTS_SERVICE_URL"),
-			sync:       os.Getenv("SYNC_SERVICE_URL"),
 			vizualizer: os.Getenv("VIZUALIZER_BASE_URL"),
 		},
 
@@ -302,6 +510,32 @@ func Load() *Config {
 			triggeredByHeader:    os.Getenv("TRIGGERED_BY_HEADER"),
 		},
 
+		HTTPIn: HTTPIn{
+			watcherRoutesFile: os.Getenv("WATCHER_ROUTES_FILE"),
+		},
+
+		Cache: Cache{
+			path:         os.Getenv("CACHE_PATH"),
+			maxEntries:   intOr("CACHE_MAX_ENTRIES", 10000),
+			vidispineTTL: durationOr("CACHE_VIDISPINE_TTL", 0),
+		},
+
+		Metrics: Metrics{
+			addr: os.Getenv("METRICS_ADDR"),
+		},
+
+		LiveIngest: LiveIngest{
+			reapers: parsePairs(os.Getenv("LIVE_INGEST_REAPERS")),
+		},
+
+		Notifications: Notifications{
+			routesFile: os.Getenv("NOTIFICATION_ROUTES_FILE"),
+		},
+
+		Callbacks: Callbacks{
+			secrets: parsePairs(os.Getenv("CALLBACK_SECRETS")),
+		},
+
 		Rudderstack: Rudderstack{
 			writeKey:     os.Getenv("RUDDERSTACK_WRITE_KEY"),
 			dataPlaneURL: os.Getenv("RUDDERSTACK_DATA_PLANE_URL"),
@@ -345,6 +579,13 @@ func chatID(name string) int64 {
 	return id
 }
 
+func stringOr(name string, fallback string) string {
+	if value := os.Getenv(name); value != "" {
+		return value
+	}
+	return fallback
+}
+
 func intOr(name string, fallback int) int {
 	raw := os.Getenv(name)
 	if raw == "" {
@@ -360,6 +601,21 @@ func intOr(name string, fallback int) int {
 	return value
 }
 
+func durationOr(name string, fallback time.Duration) time.Duration {
+	raw := os.Getenv(name)
+	if raw == "" {
+		return fallback
+	}
+
+	value, err := time.ParseDuration(raw)
+	if err != nil {
+		log.Printf("WARNING: %s is not a duration, using %s: %v", name, fallback, err)
+		return fallback
+	}
+
+	return value
+}
+
 // Getenv is for the handful of places that look up a name chosen at runtime.
 func Getenv(name string) string {
 	return os.Getenv(name)
@@ -0,0 +1,145 @@
+package languages
+
+import (
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+)
+
+// defaultTable is the language table the binaries are built with, used unless another
+// is configured.
+//
+//go:embed languages.json
+var defaultTable []byte
+
+// Table is a language table as it is kept in a file.
+type Table struct {
+	// Version is raised with every change to the file, so it can be seen which is loaded.
+	Version   int          `json:"version"`
+	Languages LanguageList `json:"languages"`
+	// Source is where the table was loaded from.
+	Source string `json:"-"`
+}
+
+var current Table
+
+// Load parses and validates a language table.
+func Load(data []byte) (Table, error) {
+	var table Table
+	if err := json.Unmarshal(data, &table); err != nil {
+		return Table{}, err
+	}
+	if len(table.Languages) == 0 {
+		return Table{}, errors.New("no languages")
+	}
+	if err := table.Languages.Validate(); err != nil {
+		return Table{}, err
+	}
+	sort.Sort(table.Languages)
+	return table, nil
+}
+
+// LoadFile loads the language table in a JSON file, laid out as languages.json.
+func LoadFile(file string) (Table, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return Table{}, err
+	}
+
+	table, err := Load(data)
+	if err != nil {
+		return Table{}, fmt.Errorf("%s: %w", file, err)
+	}
+	table.Source = file
+	return table, nil
+}
+
+// Configure makes a table the one the lookups use. It is not safe to call while they
+// are in use, so it is called at boot. Workflows do not use the lookups but read the
+// table through wfutils.Languages, which records it in the history.
+func Configure(table Table) {
+	current = table
+
+	LanguagesByNumber = table.Languages.ByNumber()
+	LanguagesByISO = table.Languages.ByISO6391()
+	LanguagesByMU1 = table.Languages.ByMU1()
+	LanguagesByMU2 = table.Languages.ByMU2()
+	LanguagesByReaper = table.Languages.ByReaperChan()
+	LanguagesByISOTwoLetter = table.Languages.ByISO6392TwoLetter()
+	LanguageBySoftron = table.Languages.BySoftron()
+	LanguageByBMM = table.Languages.ByBMMCode()
+}
+
+// Current is the table the lookups use.
+func Current() Table {
+	return current
+}
+
+// Validate checks that no code is used by two languages and that no channel is mapped
+// to two. Every problem is reported, not only the first.
+func (l LanguageList) Validate() error {
+	var errs []error
+
+	numbers := map[int]string{}
+	codes := map[string]map[string]string{}
+	channels := map[string]map[int]string{}
+
+	code := func(lang Language, kind, value string) {
+		if value == "" {
+			return
+		}
+		if codes[kind] == nil {
+			codes[kind] = map[string]string{}
+		}
+		if other, ok := codes[kind][value]; ok {
+			errs = append(errs, fmt.Errorf("%s %q is used by both %s and %s", kind, value, other, lang.ISO6391))
+			return
+		}
+		codes[kind][value] = lang.ISO6391
+	}
+
+	channel := func(lang Language, kind string, start, count int) {
+		if start < 0 {
+			return
+		}
+		if count < 0 {
+			errs = append(errs, fmt.Errorf("%s: %s has a negative channel count", lang.ISO6391, kind))
+			return
+		}
+		if channels[kind] == nil {
+			channels[kind] = map[int]string{}
+		}
+		for ch := start; ch < start+count; ch++ {
+			if other, ok := channels[kind][ch]; ok {
+				errs = append(errs, fmt.Errorf("%s channel %d is mapped to both %s and %s", kind, ch, other, lang.ISO6391))
+				continue
+			}
+			channels[kind][ch] = lang.ISO6391
+		}
+	}
+
+	for _, lang := range l {
+		if lang.ISO6391 == "" {
+			errs = append(errs, fmt.Errorf("language %d has no ISO 639 code", lang.LanguageNumber))
+		}
+		if other, ok := numbers[lang.LanguageNumber]; ok {
+			errs = append(errs, fmt.Errorf("language number %d is used by both %s and %s", lang.LanguageNumber, other, lang.ISO6391))
+		}
+		numbers[lang.LanguageNumber] = lang.ISO6391
+
+		code(lang, "ISO 639 code", lang.ISO6391)
+		code(lang, "two letter code", lang.ISO6392TwoLetter)
+		code(lang, "BMM code", lang.BMMLanguageCode)
+
+		channel(lang, "Reaper", lang.ReaperChannel, 1)
+		channel(lang, "MU1", lang.MU1ChannelStart, lang.MU1ChannelCount)
+		channel(lang, "MU2", lang.MU2ChannelStart, lang.MU2ChannelCount)
+		// Softron records each language as a stereo pair.
+		channel(lang, "Softron", lang.SoftronStartCh, 2)
+	}
+
+	return errors.Join(errs...)
+}
@@ -0,0 +1,76 @@
+package languages
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_DefaultTable(t *testing.T) {
+	table, err := Load(defaultTable)
+	require.NoError(t, err)
+
+	assert.Equal(t, "built in", Current().Source)
+	assert.Len(t, Current().Languages, len(table.Languages))
+	assert.Equal(t, "deu", LanguagesByMU1[4].ISO6391)
+	assert.Equal(t, "nob", LanguageByBMM["no"].ISO6391)
+	assert.Equal(t, "eng", LanguageBySoftron[6].ISO6391)
+}
+
+func Test_Validate(t *testing.T) {
+	valid := LanguageList{
+		{LanguageNumber: 0, ISO6391: "nor", BMMLanguageCode: "nb", ReaperChannel: 1, MU1ChannelStart: 1, MU1ChannelCount: 2, MU2ChannelStart: -1, SoftronStartCh: 0},
+		{LanguageNumber: 1, ISO6391: "deu", BMMLanguageCode: "de", ReaperChannel: 2, MU1ChannelStart: 3, MU1ChannelCount: 2, MU2ChannelStart: -1, SoftronStartCh: 2},
+		{LanguageNumber: 99, ISO6391: "zxx", ReaperChannel: -1, MU1ChannelStart: -1, MU2ChannelStart: -1, SoftronStartCh: -1},
+	}
+	require.NoError(t, valid.Validate())
+
+	cases := []struct {
+		name   string
+		change func(l *Language)
+		err    string
+	}{
+		{"duplicate number", func(l *Language) { l.LanguageNumber = 0 }, "language number 0 is used by both nor and deu"},
+		{"duplicate code", func(l *Language) { l.ISO6391 = "nor" }, `ISO 639 code "nor" is used by both nor and nor`},
+		{"duplicate BMM code", func(l *Language) { l.BMMLanguageCode = "nb" }, `BMM code "nb" is used by both nor and deu`},
+		{"Reaper collision", func(l *Language) { l.ReaperChannel = 1 }, "Reaper channel 1 is mapped to both nor and deu"},
+		{"MU1 overlap", func(l *Language) { l.MU1ChannelStart = 2 }, "MU1 channel 2 is mapped to both nor and deu"},
+		{"Softron pair overlap", func(l *Language) { l.SoftronStartCh = 1 }, "Softron channel 1 is mapped to both nor and deu"},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			list := append(LanguageList{}, valid...)
+			tc.change(&list[1])
+			assert.ErrorContains(t, list.Validate(), tc.err)
+		})
+	}
+}
+
+func Test_LoadFile(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "languages.json")
+	require.NoError(t, os.WriteFile(file, []byte(`{
+		"version": 7,
+		"languages": [
+			{"LanguageNumber": 1, "ISO6391": "deu", "ReaperChannel": 2, "MU1ChannelStart": -1, "MU2ChannelStart": -1, "SoftronStartCh": -1},
+			{"LanguageNumber": 0, "ISO6391": "nor", "ReaperChannel": 1, "MU1ChannelStart": -1, "MU2ChannelStart": -1, "SoftronStartCh": -1}
+		]
+	}`), 0644))
+
+	table, err := LoadFile(file)
+	require.NoError(t, err)
+	assert.Equal(t, 7, table.Version)
+	assert.Equal(t, file, table.Source)
+	assert.Equal(t, "nor", table.Languages[0].ISO6391, "sorted by number")
+
+	require.NoError(t, os.WriteFile(file, []byte(`{"languages": [
+		{"LanguageNumber": 0, "ISO6391": "nor", "ReaperChannel": 1},
+		{"LanguageNumber": 1, "ISO6391": "deu", "ReaperChannel": 1}
+	]}`), 0644))
+	_, err = LoadFile(file)
+	assert.ErrorContains(t, err, file+": ")
+	assert.ErrorContains(t, err, "Reaper channel 1")
+}
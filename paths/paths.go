@@ -8,7 +8,6 @@ import (
 	"path/filepath"
 	"strings"
 
-	"github.com/bcc-code/bcc-media-flows/environment"
 	"github.com/orsinium-labs/enum"
 )
 
@@ -60,45 +59,34 @@ var (
 	FileCatalystDrive  = Drive{Value: "filecatalyst"}
 	TestDrive          = Drive{Value: "test"}
 	MassiveIngestDrive = Drive{Value: "massive_ingest"}
-	Drives             = enum.New(IsilonDrive, FileCatalystDrive, TempDrive, AssetIngestDrive, BrunstadDrive, LucidLinkDrive, TestDrive, MassiveIngestDrive)
 	ErrDriveNotFound   = errors.New("drive not found")
 	ErrPathNotValid    = errors.New("path not valid")
 )
 
+// Drives are the drives in the storage registry, which can have more than those named
+// here.
+var Drives enum.Enum[Drive, string]
+
+// RcloneName is the rclone remote the drive is on, without its colon.
+//
 //goland:noinspection GoMixedReceiverTypes
 func (d Drive) RcloneName() string {
-	switch d {
-	case IsilonDrive:
-		return "isilon"
-	case FileCatalystDrive:
-		return "isilon"
-	case BrunstadDrive:
-		return "brunstad"
-	case LucidLinkDrive:
-		return "lucid"
-	case MassiveIngestDrive:
-		return "massive_ingest"
+	s := current.Drive(d)
+	if s == nil {
+		return ""
 	}
-	return ""
+	return s.RcloneName()
 }
 
+// RclonePath is where the drive starts in rclone.
+//
 //goland:noinspection GoMixedReceiverTypes
 func (d Drive) RclonePath() string {
-	switch d {
-	case IsilonDrive:
-		return "isilon:isilon"
-	case FileCatalystDrive:
-		return "isilon:filecatalyst"
-	case AssetIngestDrive:
-		return "s3prod:vod-asset-ingest-prod"
-	case BrunstadDrive:
-		return "brunstad:"
-	case LucidLinkDrive:
-		return "lucid:lucidlink"
-	case MassiveIngestDrive:
-		return "s3prod:/massiveio-bccm/"
+	s := current.Drive(d)
+	if s == nil || s.Rclone == nil {
+		return ""
 	}
-	return ""
+	return s.Rclone.FS + s.Rclone.Root
 }
 
 type Path struct {
@@ -114,11 +102,8 @@ func (p Path) Dir() Path {
 }
 
 func (p Path) OnExternalDrive() bool {
-	switch p.Drive {
-	case BrunstadDrive, AssetIngestDrive, LucidLinkDrive, MassiveIngestDrive:
-		return true
-	}
-	return false
+	s := current.Drive(p.Drive)
+	return s != nil && s.External
 }
 
 // Local returns the path in a local unix style path.
@@ -138,23 +123,11 @@ func (p Path) Ext() string {
 
 // RcloneFsRemote returns (fs, remote) for rclone usage
 func (p Path) RcloneFsRemote() (string, string) {
-	switch p.Drive {
-	case MassiveIngestDrive:
-		return "s3prod:", filepath.Join("massiveio-bccm", p.Path)
-	case TempDrive:
-		return "isilon:", filepath.Join("temp", p.Path)
-	case IsilonDrive:
-		return "isilon:", filepath.Join("isilon", p.Path)
-	case FileCatalystDrive:
-		return "isilon:", filepath.Join("filecatalyst", p.Path)
-	case AssetIngestDrive:
-		return "s3prod:", filepath.Join("vod-asset-ingest-prod", p.Path)
-	case BrunstadDrive:
-		return "brunstad:/", p.Path
-	case LucidLinkDrive:
-		return "lucid:", filepath.Join("lucidlink", p.Path)
+	s := current.Drive(p.Drive)
+	if s == nil {
+		return "", ""
 	}
-	return "", ""
+	return s.fsRemote(p.Path)
 }
 
 func (p Path) Rclone() string {
@@ -207,41 +180,37 @@ type prefix struct {
 	Rclone string
 }
 
-var drivePrefixes = map[Drive]prefix{
-	IsilonDrive:        {"/mnt/isilon/", environment.GetIsilonPrefix(), "isilon:isilon/"},
-	FileCatalystDrive:  {"/mnt/filecatalyst/", environment.GetFileCatalystMountPrefix(), "isilon:filecatalyst/"},
-	TempDrive:          {"/mnt/temp/", environment.GetTempMountPrefix(), "isilon:temp/"},
-	AssetIngestDrive:   {"/dev/null/", "/dev/null/", "s3prod:vod-asset-ingest-prod/"},
-	MassiveIngestDrive: {"/dev/null/", "/dev/null/", "s3prod:/massiveio-bccm/"},
-	BrunstadDrive:      {"/dev/null/", "/dev/null/", "brunstad:/"},
-	LucidLinkDrive:     {"/dev/null/", "/dev/null/", "lucid:lucidlink/"},
-	TestDrive:          {"./testdata/", "testdata/", "/dev/null/"},
-}
+// drivePrefixes are where the paths on each drive start, from the storage registry.
+var drivePrefixes map[Drive]prefix
 
-// Parse parses a path string into a Path struct
-//
-// Note that even though this function iterates over a map,
-// the output is deterministic and the order of the map is irrelevant.
-//
-//workflowcheck:ignore
+// Parse parses a path string into a Path struct. When the prefixes of two drives match,
+// the longer one does, so a drive mounted in a folder of another is found.
 func Parse(path string) (Path, error) {
-	for drive, ps := range drivePrefixes {
-		prefixes := []string{ps.Linux, ps.Client, ps.Rclone}
-		for _, p := range prefixes {
-			if strings.HasPrefix(path, p) {
-				return Path{
-					Drive: drive,
-					Path:  strings.TrimPrefix(path, p),
-				}, nil
+	var found Path
+	var length int
+	for _, drive := range Drives.Members() {
+		ps := drivePrefixes[drive]
+		for _, p := range []string{ps.Linux, ps.Client, ps.Rclone} {
+			if p == unmounted || len(p) <= length || !strings.HasPrefix(path, p) {
+				continue
 			}
+			found = Path{Drive: drive, Path: strings.TrimPrefix(path, p)}
+			length = len(p)
 		}
 	}
-	return Path{},
-		temporal.NewNonRetryableApplicationError(
-			"path is invalid", "invalid_path",
-			ErrPathNotValid,
-			path,
-		)
+	if length > 0 {
+		return found, nil
+	}
+	return Path{}, InvalidPathError(path)
+}
+
+// InvalidPathError is the error Parse returns for a path no drive has.
+func InvalidPathError(path string) error {
+	return temporal.NewNonRetryableApplicationError(
+		"path is invalid", "invalid_path",
+		ErrPathNotValid,
+		path,
+	)
 }
 
 func MustParse(path string) Path {
@@ -0,0 +1,358 @@
+package paths
+
+import (
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/bcc-code/bcc-media-flows/environment"
+	"github.com/orsinium-labs/enum"
+)
+
+// defaultRegistry is the storage registry the binaries are built with, used unless
+// another is configured.
+//
+//go:embed storages.json
+var defaultRegistry []byte
+
+// unmounted is where a path on a drive without a local mount or an rclone remote
+// resolves to, so a mistake reads nothing rather than something else.
+const unmounted = "/dev/null/"
+
+// Duration reads "336h" and the like from JSON.
+type Duration time.Duration
+
+func (d *Duration) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return fmt.Errorf("duration must be a string like \"336h\": %w", err)
+	}
+	parsed, err := time.ParseDuration(s)
+	if err != nil {
+		return err
+	}
+	*d = Duration(parsed)
+	return nil
+}
+
+func (d Duration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(time.Duration(d).String())
+}
+
+// Rclone is where rclone reaches a storage: the remote, with its colon, and the folder
+// on it the storage starts at.
+type Rclone struct {
+	FS   string `json:"fs"`
+	Root string `json:"root,omitempty"`
+}
+
+// Storage is somewhere media is kept. A drive is a storage paths are kept relative to,
+// as Path.Drive; a Vidispine storage is one files are moved between by VXID. A storage
+// can be both.
+type Storage struct {
+	Name string `json:"name"`
+	// Drive makes the storage a drive, named by Name.
+	Drive bool `json:"drive,omitempty"`
+	// Linux is where the storage is on the media servers, and the base path Vidispine
+	// has for it.
+	Linux string `json:"linux,omitempty"`
+	// Mount is where the storage is on the worker, when it is not at Linux.
+	Mount  string  `json:"mount,omitempty"`
+	Rclone *Rclone `json:"rclone,omitempty"`
+	// External storages are not on Isilon, so files are copied to and from them with
+	// rclone rather than moved on the file system.
+	External    bool   `json:"external,omitempty"`
+	VidispineID string `json:"vidispineId,omitempty"`
+	// Archive storages are where files are moved to be kept, and are not moved from. An
+	// archive storage that is also external is the cold archive.
+	Archive bool `json:"archive,omitempty"`
+	// Retention is how long files are kept in the temporary folders of the storage before
+	// they are cleaned up. Zero leaves it to the cleanup.
+	Retention Duration `json:"retention,omitempty"`
+	// MinFreePercent is how much of a drive is kept free. When it has less, the cleanup
+	// deletes the oldest files in its temporary folders, old enough or not, until it has
+	// TargetFreePercent. Zero cleans up by age only.
+	MinFreePercent    float64 `json:"minFreePercent,omitempty"`
+	TargetFreePercent float64 `json:"targetFreePercent,omitempty"`
+}
+
+// Quota is when a drive is short of space, and how much space cleaning it up frees.
+type Quota struct {
+	MinFreePercent    float64
+	TargetFreePercent float64
+}
+
+// Registry is the storages as they are kept in a file.
+type Registry struct {
+	// Version is raised with every change to the file, so it can be seen which is loaded.
+	Version  int       `json:"version"`
+	Storages []Storage `json:"storages"`
+	// Source is where the registry was loaded from.
+	Source string `json:"-"`
+}
+
+var current Registry
+
+func init() {
+	registry, err := Load(defaultRegistry)
+	if err != nil {
+		panic(fmt.Sprintf("built-in storage registry: %v", err))
+	}
+	registry.Source = "built in"
+	Configure(registry)
+}
+
+// Load parses and validates a storage registry.
+func Load(data []byte) (Registry, error) {
+	var registry Registry
+	if err := json.Unmarshal(data, &registry); err != nil {
+		return Registry{}, err
+	}
+	if len(registry.Storages) == 0 {
+		return Registry{}, errors.New("no storages")
+	}
+	if err := registry.Validate(); err != nil {
+		return Registry{}, err
+	}
+	return registry, nil
+}
+
+// LoadFile loads the storage registry in a JSON file, laid out as storages.json.
+func LoadFile(file string) (Registry, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return Registry{}, err
+	}
+
+	registry, err := Load(data)
+	if err != nil {
+		return Registry{}, fmt.Errorf("%s: %w", file, err)
+	}
+	registry.Source = file
+	return registry, nil
+}
+
+// Configure makes a registry the one paths are resolved with. It is not safe to call
+// while they are in use, so it is called at boot.
+func Configure(registry Registry) {
+	current = registry
+
+	prefixes := map[Drive]prefix{}
+	var drives []Drive
+	for _, s := range registry.Storages {
+		if !s.Drive {
+			continue
+		}
+		drive := Drive{Value: s.Name}
+		drives = append(drives, drive)
+		if s.Mount == "" {
+			s.Mount = environmentMount(s.Name)
+		}
+		prefixes[drive] = s.prefix()
+	}
+	drivePrefixes = prefixes
+	Drives = enum.New(drives...)
+}
+
+// Current is the registry paths are resolved with.
+func Current() Registry {
+	return current
+}
+
+// Validate checks that no name or Vidispine storage is used twice, that every storage
+// can be reached and that there is at most one cold archive. Every problem is
+// reported, not only the first.
+func (r Registry) Validate() error {
+	var errs []error
+
+	names := map[string]bool{}
+	ids := map[string]string{}
+	var coldArchive string
+	for i, s := range r.Storages {
+		if s.Name == "" {
+			errs = append(errs, fmt.Errorf("storage %d has no name", i))
+			continue
+		}
+		if names[s.Name] {
+			errs = append(errs, fmt.Errorf("storage %q is there twice", s.Name))
+		}
+		names[s.Name] = true
+
+		if !s.Drive && s.VidispineID == "" {
+			errs = append(errs, fmt.Errorf("%s is neither a drive nor a Vidispine storage", s.Name))
+		}
+		if s.Drive && s.Linux == "" && s.Rclone == nil {
+			errs = append(errs, fmt.Errorf("%s has neither a path nor an rclone remote", s.Name))
+		}
+		if s.Rclone != nil && !strings.HasSuffix(s.Rclone.FS, ":") && !strings.HasSuffix(s.Rclone.FS, ":/") {
+			errs = append(errs, fmt.Errorf("%s: rclone fs %q is not a remote, such as \"isilon:\"", s.Name, s.Rclone.FS))
+		}
+		if s.Retention < 0 {
+			errs = append(errs, fmt.Errorf("%s has a negative retention", s.Name))
+		}
+		if s.MinFreePercent < 0 || s.TargetFreePercent > 100 || s.TargetFreePercent < s.MinFreePercent {
+			errs = append(errs, fmt.Errorf("%s: free space target %g%% is not between the minimum %g%% and 100%%", s.Name, s.TargetFreePercent, s.MinFreePercent))
+		}
+
+		if s.VidispineID == "" {
+			continue
+		}
+		if s.Linux == "" {
+			errs = append(errs, fmt.Errorf("%s: Vidispine storage %s has no path", s.Name, s.VidispineID))
+		}
+		if other, ok := ids[s.VidispineID]; ok {
+			errs = append(errs, fmt.Errorf("Vidispine storage %s is both %s and %s", s.VidispineID, other, s.Name))
+		}
+		ids[s.VidispineID] = s.Name
+
+		if s.Archive && s.External {
+			if s.Rclone == nil {
+				errs = append(errs, fmt.Errorf("%s: the cold archive has no rclone remote", s.Name))
+			}
+			if coldArchive != "" {
+				errs = append(errs, fmt.Errorf("both %s and %s are the cold archive", coldArchive, s.Name))
+			}
+			coldArchive = s.Name
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// Drive is the storage of a drive, or nil for a drive the registry does not have.
+func (r Registry) Drive(drive Drive) *Storage {
+	for _, s := range r.Storages {
+		if s.Drive && s.Name == drive.Value {
+			return &s
+		}
+	}
+	return nil
+}
+
+// Retention is how long the temporary files on a drive are kept, or zero when the
+// registry does not say.
+func (r Registry) Retention(drive Drive) time.Duration {
+	if s := r.Drive(drive); s != nil {
+		return time.Duration(s.Retention)
+	}
+	return 0
+}
+
+// Quota is when a drive is short of space, or nil when only age cleans it up.
+func (r Registry) Quota(drive Drive) *Quota {
+	s := r.Drive(drive)
+	if s == nil || s.MinFreePercent == 0 {
+		return nil
+	}
+	return &Quota{MinFreePercent: s.MinFreePercent, TargetFreePercent: s.TargetFreePercent}
+}
+
+// VidispineStorages are the storages Vidispine has, in the order of the registry.
+func (r Registry) VidispineStorages() []Storage {
+	var out []Storage
+	for _, s := range r.Storages {
+		if s.VidispineID != "" {
+			out = append(out, s)
+		}
+	}
+	return out
+}
+
+// VidispineStorage is the Vidispine storage with a VXID, or nil.
+func (r Registry) VidispineStorage(vxid string) *Storage {
+	for _, s := range r.VidispineStorages() {
+		if s.VidispineID == vxid {
+			return &s
+		}
+	}
+	return nil
+}
+
+// VidispineStorageForPath is the Vidispine storage a file, as Vidispine has its path,
+// is on, or nil. A storage in a folder of another is preferred to the one it is in.
+func (r Registry) VidispineStorageForPath(path string) *Storage {
+	var found *Storage
+	for _, s := range r.VidispineStorages() {
+		if strings.HasPrefix(path, s.Linux) && (found == nil || len(s.Linux) > len(found.Linux)) {
+			found = &s
+		}
+	}
+	return found
+}
+
+// ColdArchive is the Vidispine storage originals are archived to, the one that is both
+// archive and external, or nil when there is none and nothing is archived.
+func (r Registry) ColdArchive() *Storage {
+	for _, s := range r.VidispineStorages() {
+		if s.Archive && s.External {
+			return &s
+		}
+	}
+	return nil
+}
+
+// RcloneName is the rclone remote the storage is on, without its colon, or empty.
+func (s Storage) RcloneName() string {
+	if s.Rclone == nil {
+		return ""
+	}
+	name, _, _ := strings.Cut(s.Rclone.FS, ":")
+	return name
+}
+
+// prefix is where paths on a drive start, on the servers, on the worker and in rclone.
+func (s Storage) prefix() prefix {
+	p := prefix{Linux: unmounted, Client: unmounted, Rclone: unmounted}
+	if s.Linux != "" {
+		p.Linux = dirPrefix(s.Linux)
+		p.Client = p.Linux
+	}
+	if s.Mount != "" {
+		p.Client = dirPrefix(s.Mount)
+	}
+	if s.Rclone != nil {
+		p.Rclone = dirPrefix(s.Rclone.FS + s.Rclone.Root)
+	}
+	return p
+}
+
+// environmentMount is where ISILON_PREFIX, TEMP_MOUNT_PREFIX or
+// FILECATALYST_MOUNT_PREFIX has a drive mounted, for a worker run off the servers. It
+// is the mount of the drive when the registry gives none, so the paths the rest of the
+// worker builds from those variables resolve to it.
+func environmentMount(drive string) string {
+	cfg := environment.Get().Paths
+	switch drive {
+	case IsilonDrive.Value:
+		return cfg.IsilonPrefix()
+	case TempDrive.Value:
+		return cfg.TempMount()
+	case FileCatalystDrive.Value:
+		return cfg.FileCatalystMount()
+	}
+	return ""
+}
+
+func dirPrefix(path string) string {
+	if strings.HasSuffix(path, "/") {
+		return path
+	}
+	return path + "/"
+}
+
+// fsRemote is a path on the storage as rclone's fs and remote.
+func (s Storage) fsRemote(path string) (string, string) {
+	if s.Rclone == nil {
+		return "", ""
+	}
+	root := strings.TrimPrefix(s.Rclone.Root, "/")
+	if root == "" {
+		return s.Rclone.FS, path
+	}
+	return s.Rclone.FS, filepath.Join(root, path)
+}
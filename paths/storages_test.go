@@ -0,0 +1,187 @@
+package paths
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/bcc-code/bcc-media-flows/environment"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_DefaultRegistry(t *testing.T) {
+	assert.Equal(t, "built in", Current().Source)
+
+	assert.Equal(t, "isilon", IsilonDrive.RcloneName())
+	assert.Equal(t, "isilon:filecatalyst", FileCatalystDrive.RclonePath())
+	assert.Equal(t, "s3prod", AssetIngestDrive.RcloneName())
+	assert.Equal(t, "", TestDrive.RcloneName())
+
+	assert.True(t, New(LucidLinkDrive, "a.wav").OnExternalDrive())
+	assert.False(t, New(IsilonDrive, "a.wav").OnExternalDrive())
+
+	fs, remote := New(BrunstadDrive, "/Opptak/a.wav").RcloneFsRemote()
+	assert.Equal(t, "brunstad:/", fs)
+	assert.Equal(t, "/Opptak/a.wav", remote)
+
+	fs, remote = New(TempDrive, "workflows/a").RcloneFsRemote()
+	assert.Equal(t, "isilon:", fs)
+	assert.Equal(t, "temp/workflows/a", remote)
+
+	assert.Equal(t, 14*24*time.Hour, Current().Retention(TempDrive))
+	assert.Zero(t, Current().Retention(LucidLinkDrive))
+
+	assert.Equal(t, &Quota{MinFreePercent: 15, TargetFreePercent: 25}, Current().Quota(TempDrive))
+	assert.Nil(t, Current().Quota(IsilonDrive))
+}
+
+func Test_VidispineStorages(t *testing.T) {
+	registry := Current()
+
+	assert.Equal(t, "Isilon Master", registry.VidispineStorage("VX-56").Name)
+	assert.Nil(t, registry.VidispineStorage("VX-999"))
+
+	storage := registry.VidispineStorageForPath("/mnt/isilon/system/ingestgrow/a.mxf")
+	require.NotNil(t, storage)
+	assert.Equal(t, "VX-41", storage.VidispineID)
+
+	storage = registry.VidispineStorageForPath("/mnt/archive/2020/a.mxf")
+	require.NotNil(t, storage)
+	assert.True(t, storage.Archive)
+
+	assert.Nil(t, registry.VidispineStorageForPath("/mnt/isilon/Transcoding/a.mxf"))
+}
+
+func Test_ColdArchive(t *testing.T) {
+	assert.Nil(t, Current().ColdArchive(), "the built-in registry archives nothing")
+
+	registry := Registry{Storages: []Storage{
+		{Name: "Archive", VidispineID: "VX-82", Linux: "/mnt/archive/", Archive: true},
+		{Name: "Cold Archive", VidispineID: "VX-90", Linux: "s3://cold-archive/", Rclone: &Rclone{FS: "coldarchive:"}, Archive: true, External: true},
+	}}
+	require.NoError(t, registry.Validate())
+
+	storage := registry.ColdArchive()
+	require.NotNil(t, storage)
+	assert.Equal(t, "VX-90", storage.VidispineID)
+	assert.Equal(t, "coldarchive", storage.RcloneName())
+}
+
+func Test_VidispineStorageForPath_Longest(t *testing.T) {
+	registry := Registry{Storages: []Storage{
+		{Name: "Isilon", VidispineID: "VX-1", Linux: "/mnt/isilon/"},
+		{Name: "Isilon Master", VidispineID: "VX-2", Linux: "/mnt/isilon/Master/"},
+	}}
+
+	assert.Equal(t, "VX-2", registry.VidispineStorageForPath("/mnt/isilon/Master/a.mxf").VidispineID)
+	assert.Equal(t, "VX-1", registry.VidispineStorageForPath("/mnt/isilon/Production/a.mxf").VidispineID)
+}
+
+func Test_RegistryValidate(t *testing.T) {
+	cases := []struct {
+		name     string
+		storages []Storage
+		err      string
+	}{
+		{"no name", []Storage{{Drive: true, Linux: "/mnt/a/"}}, "storage 0 has no name"},
+		{"duplicate name", []Storage{{Name: "a", Drive: true, Linux: "/mnt/a/"}, {Name: "a", Drive: true, Linux: "/mnt/b/"}}, `storage "a" is there twice`},
+		{"neither", []Storage{{Name: "a", Linux: "/mnt/a/"}}, "a is neither a drive nor a Vidispine storage"},
+		{"unreachable drive", []Storage{{Name: "a", Drive: true}}, "a has neither a path nor an rclone remote"},
+		{"rclone fs", []Storage{{Name: "a", Drive: true, Rclone: &Rclone{FS: "s3prod"}}}, `rclone fs "s3prod" is not a remote`},
+		{"duplicate Vidispine storage", []Storage{{Name: "a", VidispineID: "VX-1", Linux: "/a/"}, {Name: "b", VidispineID: "VX-1", Linux: "/b/"}}, "Vidispine storage VX-1 is both a and b"},
+		{"free space target", []Storage{{Name: "a", Drive: true, Linux: "/mnt/a/", MinFreePercent: 20, TargetFreePercent: 10}}, "a: free space target 10% is not between the minimum 20% and 100%"},
+		{"Vidispine storage without a path", []Storage{{Name: "a", VidispineID: "VX-1"}}, "a: Vidispine storage VX-1 has no path"},
+		{"cold archive without rclone", []Storage{{Name: "a", VidispineID: "VX-1", Linux: "s3://a/", Archive: true, External: true}}, "a: the cold archive has no rclone remote"},
+		{"two cold archives", []Storage{
+			{Name: "a", VidispineID: "VX-1", Linux: "s3://a/", Rclone: &Rclone{FS: "a:"}, Archive: true, External: true},
+			{Name: "b", VidispineID: "VX-2", Linux: "s3://b/", Rclone: &Rclone{FS: "b:"}, Archive: true, External: true},
+		}, "both a and b are the cold archive"},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.ErrorContains(t, Registry{Storages: tc.storages}.Validate(), tc.err)
+		})
+	}
+}
+
+// A new bucket is a drive in the file, and nothing else.
+func Test_LoadFile_NewDrive(t *testing.T) {
+	t.Cleanup(func() {
+		registry, err := Load(defaultRegistry)
+		require.NoError(t, err)
+		Configure(registry)
+	})
+
+	file := filepath.Join(t.TempDir(), "storages.json")
+	require.NoError(t, os.WriteFile(file, []byte(`{
+		"version": 3,
+		"storages": [
+			{"name": "isilon", "drive": true, "linux": "/mnt/isilon/", "rclone": {"fs": "isilon:", "root": "isilon"}},
+			{"name": "delivery", "drive": true, "rclone": {"fs": "s3prod:", "root": "delivery-prod"}, "external": true, "retention": "72h"}
+		]
+	}`), 0644))
+
+	registry, err := LoadFile(file)
+	require.NoError(t, err)
+	assert.Equal(t, 3, registry.Version)
+	assert.Equal(t, file, registry.Source)
+	Configure(registry)
+
+	path, err := Parse("s3prod:delivery-prod/2025/a.mxf")
+	require.NoError(t, err)
+	assert.Equal(t, "delivery", path.Drive.Value)
+	assert.Equal(t, "2025/a.mxf", path.Path)
+	assert.True(t, path.OnExternalDrive())
+	assert.Equal(t, 72*time.Hour, Current().Retention(path.Drive))
+
+	fs, remote := path.RcloneFsRemote()
+	assert.Equal(t, "s3prod:", fs)
+	assert.Equal(t, "delivery-prod/2025/a.mxf", remote)
+
+	var decoded Path
+	require.NoError(t, json.Unmarshal([]byte(`{"Drive":"delivery","Path":"a.mxf"}`), &decoded))
+	assert.Equal(t, path.Drive, decoded.Drive)
+
+	// Drives the file leaves out are not known.
+	_, err = Parse("/mnt/temp/a.mxf")
+	assert.Error(t, err)
+}
+
+func Test_LoadFile_Invalid(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "storages.json")
+	require.NoError(t, os.WriteFile(file, []byte(`{"version": 1, "storages": []}`), 0644))
+
+	_, err := LoadFile(file)
+	assert.ErrorContains(t, err, "no storages")
+}
+
+// A worker run off the servers has Isilon where ISILON_PREFIX says, unless the registry
+// mounts it elsewhere.
+func Test_Configure_EnvironmentMount(t *testing.T) {
+	t.Setenv("ISILON_PREFIX", "/Volumes/isilon")
+	environment.Load()
+	t.Cleanup(func() {
+		environment.Load()
+		registry, err := Load(defaultRegistry)
+		require.NoError(t, err)
+		Configure(registry)
+	})
+
+	registry, err := Load(defaultRegistry)
+	require.NoError(t, err)
+	Configure(registry)
+
+	path, err := Parse("/Volumes/isilon/system/assets/empty.srt")
+	require.NoError(t, err)
+	assert.Equal(t, New(IsilonDrive, "system/assets/empty.srt"), path)
+	assert.Equal(t, "/Volumes/isilon/system/assets/empty.srt", path.Local())
+	assert.Equal(t, "/mnt/isilon/system/assets/empty.srt", path.Linux())
+
+	registry.Storages[0].Mount = "/srv/isilon/"
+	Configure(registry)
+	assert.Equal(t, "/srv/isilon/a.mxf", New(IsilonDrive, "a.mxf").Local())
+}
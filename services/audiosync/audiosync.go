@@ -0,0 +1,217 @@
+// Package audiosync measures how far two recordings of the same audio are apart, by
+// cross-correlating windows of them in process.
+package audiosync
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"os/exec"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/bcc-code/bcc-media-flows/services/ffmpeg"
+)
+
+type Options struct {
+	// SampleRate is what the audio is decoded at. Zero means 8000, which is plenty to
+	// line up speech and music to the millisecond.
+	SampleRate int
+	// Window is how much of the reference is matched at each point. Zero means 30s.
+	Window time.Duration
+	// MaxOffset is how far from the reference the target is searched. Zero means 5s.
+	MaxOffset time.Duration
+	// Points is how many places in the file are measured, spread from start to end, to
+	// see whether the offset drifts. Zero means 5.
+	Points int
+	// MinConfidence is the correlation a point needs to count. Zero means 0.3.
+	MinConfidence float64
+}
+
+func (o Options) withDefaults() Options {
+	if o.SampleRate <= 0 {
+		o.SampleRate = 8000
+	}
+	if o.Window <= 0 {
+		o.Window = 30 * time.Second
+	}
+	if o.MaxOffset <= 0 {
+		o.MaxOffset = 5 * time.Second
+	}
+	if o.Points <= 0 {
+		o.Points = 5
+	}
+	if o.MinConfidence <= 0 {
+		o.MinConfidence = 0.3
+	}
+	return o
+}
+
+// Measurement is the offset at one point of the reference.
+type Measurement struct {
+	At         time.Duration
+	Offset     time.Duration
+	Confidence float64
+}
+
+type Result struct {
+	// Offset is how much earlier the target is than the reference, so how much it has
+	// to be delayed to line up. Negative means it is late.
+	Offset time.Duration
+	// Confidence is the typical correlation of the points that counted, from 0 to 1.
+	Confidence float64
+	// Drift is how much the offset changed from the first point that counted to the
+	// last. A constant shift cannot fix a file that drifts.
+	Drift        time.Duration
+	Measurements []Measurement
+}
+
+// decoder returns mono samples of a file from start, for at most duration.
+type decoder func(path string, start, duration time.Duration) ([]float64, error)
+
+// Offset measures how far the target is from the reference.
+func Offset(reference, target string, opts Options) (*Result, error) {
+	opts = opts.withDefaults()
+
+	refInfo, err := ffmpeg.GetStreamInfo(reference)
+	if err != nil {
+		return nil, err
+	}
+	targetInfo, err := ffmpeg.GetStreamInfo(target)
+	if err != nil {
+		return nil, err
+	}
+
+	duration := time.Duration(min(refInfo.TotalSeconds, targetInfo.TotalSeconds) * float64(time.Second))
+	decode := func(path string, start, length time.Duration) ([]float64, error) {
+		return decodePCM(path, start, length, opts.SampleRate)
+	}
+	return measure(reference, target, duration, opts, decode)
+}
+
+func measure(reference, target string, duration time.Duration, opts Options, decode decoder) (*Result, error) {
+	if duration <= 0 {
+		return nil, fmt.Errorf("%s and %s have no audio in common to compare", reference, target)
+	}
+
+	window := min(opts.Window, duration)
+	span := duration - window
+
+	var measurements []Measurement
+	for i := 0; i < opts.Points; i++ {
+		at := time.Duration(0)
+		if opts.Points > 1 {
+			at = span * time.Duration(i) / time.Duration(opts.Points-1)
+		}
+		if i > 0 && at == measurements[len(measurements)-1].At {
+			continue
+		}
+
+		m, ok, err := measureAt(reference, target, at, window, opts, decode)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			measurements = append(measurements, m)
+		}
+	}
+
+	return summarize(measurements, opts.MinConfidence)
+}
+
+// measureAt matches the reference window starting at at against the target around the
+// same place. It is not ok when the target ends before there is a whole window to match.
+func measureAt(reference, target string, at, window time.Duration, opts Options, decode decoder) (Measurement, bool, error) {
+	ref, err := decode(reference, at, window)
+	if err != nil {
+		return Measurement{}, false, err
+	}
+
+	targetStart := max(at-opts.MaxOffset, 0)
+	search, err := decode(target, targetStart, window+(at-targetStart)+opts.MaxOffset)
+	if err != nil {
+		return Measurement{}, false, err
+	}
+	if len(ref) == 0 || len(search) < len(ref) {
+		return Measurement{}, false, nil
+	}
+
+	shift, confidence := align(ref, search)
+
+	// The reference at `at` is found in the target at targetStart+shift.
+	found := targetStart + samplesToDuration(shift, opts.SampleRate)
+	return Measurement{
+		At:         at,
+		Offset:     at - found,
+		Confidence: confidence,
+	}, true, nil
+}
+
+// summarize takes the median of the points that are confident enough, so one point in
+// a quiet or ambiguous part of the file does not throw the result.
+func summarize(measurements []Measurement, minConfidence float64) (*Result, error) {
+	var confident []Measurement
+	best := 0.0
+	for _, m := range measurements {
+		best = max(best, m.Confidence)
+		if m.Confidence >= minConfidence {
+			confident = append(confident, m)
+		}
+	}
+	if len(confident) == 0 {
+		return nil, fmt.Errorf("no confident match between the files, best correlation was %.2f", best)
+	}
+
+	offsets := make([]time.Duration, 0, len(confident))
+	confidences := make([]float64, 0, len(confident))
+	for _, m := range confident {
+		offsets = append(offsets, m.Offset)
+		confidences = append(confidences, m.Confidence)
+	}
+	slices.Sort(offsets)
+	slices.Sort(confidences)
+
+	return &Result{
+		Offset:       offsets[len(offsets)/2],
+		Confidence:   confidences[len(confidences)/2],
+		Drift:        confident[len(confident)-1].Offset - confident[0].Offset,
+		Measurements: measurements,
+	}, nil
+}
+
+func samplesToDuration(samples, sampleRate int) time.Duration {
+	return time.Duration(samples) * time.Second / time.Duration(sampleRate)
+}
+
+// decodePCM has ffmpeg mix the audio of a file down to mono 16 bit PCM and returns it
+// as samples from -1 to 1.
+func decodePCM(path string, start, duration time.Duration, sampleRate int) ([]float64, error) {
+	cmd := exec.Command(
+		"ffmpeg",
+		"-hide_banner",
+		"-nostats",
+		"-loglevel", "error",
+		"-ss", strconv.FormatFloat(start.Seconds(), 'f', 3, 64),
+		"-t", strconv.FormatFloat(duration.Seconds(), 'f', 3, 64),
+		"-i", path,
+		"-vn",
+		"-ac", "1",
+		"-ar", strconv.Itoa(sampleRate),
+		"-f", "s16le",
+		"-",
+	)
+
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("couldn't decode audio of %s: %w, %s", path, err, stderr.String())
+	}
+
+	samples := make([]float64, len(out)/2)
+	for i := range samples {
+		samples[i] = float64(int16(binary.LittleEndian.Uint16(out[2*i:]))) / 32768
+	}
+	return samples, nil
+}
@@ -0,0 +1,162 @@
+package audiosync
+
+import (
+	"fmt"
+	"math/cmplx"
+	"math/rand"
+	"os/exec"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testRate = 1000
+
+func noise(seconds float64, seed int64) []float64 {
+	r := rand.New(rand.NewSource(seed))
+	samples := make([]float64, int(seconds*testRate))
+	for i := range samples {
+		samples[i] = r.Float64()*2 - 1
+	}
+	return samples
+}
+
+func at(seconds float64) int {
+	return int(seconds * testRate)
+}
+
+// memoryDecoder decodes from signals held in memory, the way ffmpeg would from files.
+func memoryDecoder(files map[string][]float64) decoder {
+	return func(path string, start, duration time.Duration) ([]float64, error) {
+		samples, ok := files[path]
+		if !ok {
+			return nil, fmt.Errorf("no such file %s", path)
+		}
+		from := min(int(start.Seconds()*testRate), len(samples))
+		to := min(from+int(duration.Seconds()*testRate), len(samples))
+		return samples[from:to], nil
+	}
+}
+
+func testOptions() Options {
+	return Options{
+		SampleRate: testRate,
+		Window:     5 * time.Second,
+		MaxOffset:  2 * time.Second,
+		Points:     4,
+	}.withDefaults()
+}
+
+func measureSignals(t *testing.T, ref, target []float64) (*Result, error) {
+	t.Helper()
+	duration := samplesToDuration(min(len(ref), len(target)), testRate)
+	return measure("ref", "target", duration, testOptions(), memoryDecoder(map[string][]float64{
+		"ref":    ref,
+		"target": target,
+	}))
+}
+
+func Test_fft_RoundTrip(t *testing.T) {
+	x := []complex128{1, 2, 3, 4, 0, -1, 0.5, 2}
+	y := append([]complex128(nil), x...)
+
+	fft(y, false)
+	fft(y, true)
+
+	for i := range x {
+		assert.InDelta(t, 0, cmplx.Abs(x[i]-y[i]), 1e-9)
+	}
+}
+
+func Test_align_MatchesNaiveCorrelation(t *testing.T) {
+	target := noise(3, 1)
+	ref := append([]float64(nil), target[1234:1234+500]...)
+
+	shift, confidence := align(ref, target)
+
+	assert.Equal(t, 1234, shift)
+	assert.InDelta(t, 1, confidence, 1e-9)
+}
+
+func Test_measure_TargetEarly(t *testing.T) {
+	base := noise(60, 2)
+
+	// The target starts 1.5s into the audio, so everything is heard 1.5s earlier in it.
+	result, err := measureSignals(t, base, base[at(1.5):])
+	require.NoError(t, err)
+
+	assert.Equal(t, 1500*time.Millisecond, result.Offset)
+	assert.Equal(t, time.Duration(0), result.Drift)
+	assert.InDelta(t, 1, result.Confidence, 1e-9)
+	assert.Len(t, result.Measurements, 4)
+}
+
+func Test_measure_TargetLate(t *testing.T) {
+	base := noise(60, 3)
+	target := append(make([]float64, at(0.8)), base...)
+
+	result, err := measureSignals(t, base, target)
+	require.NoError(t, err)
+
+	assert.Equal(t, -800*time.Millisecond, result.Offset)
+}
+
+func Test_measure_Drift(t *testing.T) {
+	base := noise(60, 4)
+
+	// 40ms go missing from the target halfway, so the second half is that much earlier.
+	target := append(append([]float64(nil), base[:at(30)]...), base[at(30.04):]...)
+
+	result, err := measureSignals(t, base, target)
+	require.NoError(t, err)
+
+	first := result.Measurements[0]
+	last := result.Measurements[len(result.Measurements)-1]
+	assert.Equal(t, time.Duration(0), first.Offset)
+	assert.Equal(t, 40*time.Millisecond, last.Offset)
+	assert.Equal(t, 40*time.Millisecond, result.Drift)
+}
+
+func Test_measure_NoisyTargetIsLessConfident(t *testing.T) {
+	base := noise(60, 5)
+	hiss := noise(60, 6)
+	target := make([]float64, len(base))
+	for i := range target {
+		target[i] = base[i] + hiss[i]
+	}
+
+	result, err := measureSignals(t, base, target)
+	require.NoError(t, err)
+
+	assert.Equal(t, time.Duration(0), result.Offset)
+	assert.Less(t, result.Confidence, 0.9)
+	assert.Greater(t, result.Confidence, 0.5)
+}
+
+func Test_measure_UnrelatedAudioIsAnError(t *testing.T) {
+	_, err := measureSignals(t, noise(60, 7), noise(60, 8))
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "no confident match")
+}
+
+func Test_measure_SilenceIsAnError(t *testing.T) {
+	_, err := measureSignals(t, noise(60, 9), make([]float64, at(60)))
+
+	require.Error(t, err)
+}
+
+func Test_Offset_FixtureAgainstItself(t *testing.T) {
+	if _, err := exec.LookPath("ffmpeg"); err != nil {
+		t.Skip("ffmpeg is not installed")
+	}
+
+	fixture := "../ffmpeg/test_files/file_example_WAV_1MG.wav"
+	result, err := Offset(fixture, fixture, Options{Window: 2 * time.Second, Points: 2})
+	require.NoError(t, err)
+
+	assert.Equal(t, time.Duration(0), result.Offset)
+	assert.Equal(t, time.Duration(0), result.Drift)
+}
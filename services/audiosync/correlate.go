@@ -0,0 +1,59 @@
+package audiosync
+
+import "math"
+
+// align finds where ref sits in target: the shift that maximises
+// sum(ref[n] * target[n+shift]) for shift from 0 to len(target)-len(ref).
+//
+// The correlation is done with an FFT, so a window of minutes searched over seconds is
+// cheap. The confidence is the normalised correlation at the shift, from -1 to 1, so
+// a match of quiet audio counts as much as a loud one.
+func align(ref, target []float64) (shift int, confidence float64) {
+	if len(ref) == 0 || len(target) < len(ref) {
+		return 0, 0
+	}
+
+	n := nextPowerOfTwo(len(target) + len(ref))
+	a := make([]complex128, n)
+	b := make([]complex128, n)
+	for i, v := range target {
+		a[i] = complex(v, 0)
+	}
+	for i, v := range ref {
+		b[i] = complex(v, 0)
+	}
+
+	fft(a, false)
+	fft(b, false)
+	for i := range a {
+		re, im := real(b[i]), imag(b[i])
+		a[i] *= complex(re, -im)
+	}
+	fft(a, true)
+
+	// a[k] is now sum(target[n+k] * ref[n]); only the shifts with full overlap count.
+	best := math.Inf(-1)
+	for k := 0; k <= len(target)-len(ref); k++ {
+		if v := real(a[k]); v > best {
+			best, shift = v, k
+		}
+	}
+
+	return shift, normalisedCorrelation(ref, target[shift:shift+len(ref)])
+}
+
+// normalisedCorrelation is the Pearson-style correlation of two equally long signals,
+// without removing the mean, which audio does not have to speak of. Silence correlates
+// with nothing.
+func normalisedCorrelation(a, b []float64) float64 {
+	var dot, energyA, energyB float64
+	for i := range a {
+		dot += a[i] * b[i]
+		energyA += a[i] * a[i]
+		energyB += b[i] * b[i]
+	}
+	if energyA == 0 || energyB == 0 {
+		return 0
+	}
+	return dot / math.Sqrt(energyA*energyB)
+}
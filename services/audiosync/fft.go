@@ -0,0 +1,60 @@
+package audiosync
+
+import (
+	"math"
+	"math/bits"
+)
+
+// nextPowerOfTwo is the smallest power of two that is at least n.
+func nextPowerOfTwo(n int) int {
+	if n <= 1 {
+		return 1
+	}
+	return 1 << bits.Len(uint(n-1))
+}
+
+// fft transforms x in place. Its length must be a power of two. The inverse is scaled by
+// 1/n, so fft(fft(x, false), true) is x again.
+func fft(x []complex128, inverse bool) {
+	n := len(x)
+	if n <= 1 {
+		return
+	}
+
+	// Bit-reversal permutation, so the butterflies below can work in place.
+	shift := 64 - bits.Len(uint(n-1))
+	for i := 0; i < n; i++ {
+		j := int(bits.Reverse64(uint64(i)) >> shift)
+		if i < j {
+			x[i], x[j] = x[j], x[i]
+		}
+	}
+
+	sign := -1.0
+	if inverse {
+		sign = 1.0
+	}
+
+	for size := 2; size <= n; size <<= 1 {
+		angle := sign * 2 * math.Pi / float64(size)
+		step := complex(math.Cos(angle), math.Sin(angle))
+		half := size / 2
+		for start := 0; start < n; start += size {
+			w := complex(1, 0)
+			for k := 0; k < half; k++ {
+				even := x[start+k]
+				odd := w * x[start+k+half]
+				x[start+k] = even + odd
+				x[start+k+half] = even - odd
+				w *= step
+			}
+		}
+	}
+
+	if inverse {
+		scale := complex(1/float64(n), 0)
+		for i := range x {
+			x[i] *= scale
+		}
+	}
+}
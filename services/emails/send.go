@@ -62,6 +62,7 @@ func NewMessage(template notifications.Template, to []string, cc []string, bcc [
 		HTML:      html,
 		PlainText: plainText,
 		Subject:   template.Subject(),
+		Event:     notifications.EventOf(template),
 		To:        to,
 		CC:        cc,
 		BCC:       bcc,
@@ -72,7 +73,9 @@ type Message struct {
 	Subject   string
 	HTML      string
 	PlainText string
-	To        []string
-	CC        []string
-	BCC       []string
+	// Event is what the message reports, one of the notifications.Event values.
+	Event string
+	To    []string
+	CC    []string
+	BCC   []string
 }
@@ -22,6 +22,16 @@ type Progress struct {
 	Speed          string  `json:"speed"`
 }
 
+// SpeedRatio is Speed as a number, such as 2.5 for "2.5x". ffmpeg reports it averaged
+// over the run so far. It is false before ffmpeg has reported one, or when it said N/A.
+func (p Progress) SpeedRatio() (float64, bool) {
+	speed, err := strconv.ParseFloat(strings.TrimSuffix(strings.TrimSpace(p.Speed), "x"), 64)
+	if err != nil || speed <= 0 {
+		return 0, false
+	}
+	return speed, true
+}
+
 type StreamInfo struct {
 	HasAudio     bool
 	HasVideo     bool
@@ -34,6 +44,9 @@ type StreamInfo struct {
 	TotalFrames  int
 	TotalSeconds float64
 	FrameRate    int
+	// TimecodeRate is the frame rate as it is, with 29.97 not rounded down, when it is one
+	// timecode is counted in. It is never drop-frame, which only the timecode can tell.
+	TimecodeRate utils.TimecodeRate
 	Height       int
 	Width        int
 }
@@ -104,6 +117,7 @@ func ProbeResultToInfo(info *FFProbeResult) StreamInfo {
 			seconds, _ := strconv.ParseFloat(parts[1], 64)
 			if seconds != 0 {
 				streamInfo.FrameRate = int(frames / seconds)
+				streamInfo.TimecodeRate, _ = utils.RateFromFPS(frames / seconds)
 			}
 		}
 	}
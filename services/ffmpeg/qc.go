@@ -0,0 +1,422 @@
+package ffmpeg
+
+import (
+	"fmt"
+	"math"
+	"os/exec"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/bcc-code/bcc-media-flows/utils"
+)
+
+// What the QC detectors report.
+const (
+	qcBlackDuration   = 2.0
+	qcBlackPixel      = 0.10
+	qcFreezeDuration  = 5.0
+	qcFreezeNoise     = "-60dB"
+	qcSilenceDuration = 10.0
+	qcSilenceNoise    = "-60dB"
+
+	// Dropouts are digital silence in the middle of sound, too short to be a pause.
+	qcDropoutNoise       = "-90dB"
+	qcDropoutMinDuration = 0.02
+	qcDropoutMaxDuration = 1.0
+
+	// qcTruePeakLimit is the EBU R128 limit, in dBTP.
+	qcTruePeakLimit = -1.0
+	// qcClipLevel is the sample peak, in dBFS, a channel counts as clipping at.
+	qcClipLevel = -0.1
+	// qcSilentLevel is what ffmpeg's -inf is stored as, as JSON has no infinity.
+	qcSilentLevel = -99.0
+)
+
+type QCPeriod struct {
+	Start float64 `json:"start"`
+	End   float64 `json:"end"`
+}
+
+type QCChannel struct {
+	// Channel counts from 0.
+	Channel  int        `json:"channel"`
+	Silence  []QCPeriod `json:"silence,omitempty"`
+	Dropouts []QCPeriod `json:"dropouts,omitempty"`
+	// PeakLevel is the sample peak, in dBFS.
+	PeakLevel float64 `json:"peakLevel"`
+	// Clipped is how many times the channel reached full scale.
+	Clipped int `json:"clipped"`
+}
+
+type QCAudioStream struct {
+	// Stream counts the audio streams from 0, as -map 0:a:N does.
+	Stream             int         `json:"stream"`
+	IntegratedLoudness float64     `json:"integratedLoudness"`
+	LoudnessRange      float64     `json:"loudnessRange"`
+	TruePeak           float64     `json:"truePeak"`
+	Channels           []QCChannel `json:"channels"`
+}
+
+type QCVideo struct {
+	Black  []QCPeriod `json:"black,omitempty"`
+	Freeze []QCPeriod `json:"freeze,omitempty"`
+	// InterlacedFrames and ProgressiveFrames are what the interlace detector found,
+	// and FlaggedProgressive what the stream says it is.
+	InterlacedFrames   int  `json:"interlacedFrames"`
+	ProgressiveFrames  int  `json:"progressiveFrames"`
+	FlaggedProgressive bool `json:"flaggedProgressive"`
+}
+
+// QCReport is what the detectors of AnalyzeQC found in a file, timed in seconds from
+// its start.
+type QCReport struct {
+	Duration float64         `json:"duration"`
+	Video    *QCVideo        `json:"video,omitempty"`
+	Audio    []QCAudioStream `json:"audio,omitempty"`
+}
+
+// QCIssue is something in a QC report that someone should look at.
+type QCIssue struct {
+	Check string `json:"check"`
+	// Track is the stream and channel the issue is in, such as "a0.1", or "v" for
+	// the video.
+	Track   string  `json:"track"`
+	Start   float64 `json:"start"`
+	End     float64 `json:"end"`
+	Message string  `json:"message"`
+}
+
+// AnalyzeQC runs the file through ffmpeg's detectors of black, frozen and interlaced
+// video, and of silence, dropouts, loudness and clipping in each audio channel, in
+// one pass.
+func AnalyzeQC(path string, progressCallback ProgressCallback) (*QCReport, error) {
+	info, err := GetStreamInfo(path)
+	if err != nil {
+		return nil, err
+	}
+	if !info.HasVideo && !info.HasAudio {
+		return nil, fmt.Errorf("%s has no video or audio", path)
+	}
+
+	cmd := exec.Command("ffmpeg", qcArgs(path, info)...)
+	log, err := utils.ExecuteLogCmd(cmd, parseProgressCallback(cmd.Args, info, progressCallback))
+	if err != nil {
+		return nil, fmt.Errorf("couldn't execute ffmpeg %s, %w, CMD: '%s'", path, err, cmd.String())
+	}
+
+	return parseQCLog(log, info), nil
+}
+
+// qcArgs runs the detectors as filters named by what they look at: v for the video,
+// and s, d, c and l with the audio stream number for silence, dropouts, clipping and
+// loudness. The names are what their log lines are told apart by.
+func qcArgs(path string, info StreamInfo) []string {
+	var graph, maps []string
+	if info.HasVideo {
+		graph = append(graph, fmt.Sprintf(
+			"[0:v:0]idet@v,blackdetect@v=d=%g:pix_th=%.2f,freezedetect@v=n=%s:d=%g[v]",
+			qcBlackDuration, qcBlackPixel, qcFreezeNoise, qcFreezeDuration,
+		))
+		maps = append(maps, "-map", "[v]")
+	}
+	for i := range info.AudioStreams {
+		graph = append(graph, fmt.Sprintf(
+			"[0:a:%d]silencedetect@s%d=n=%s:d=%g:mono=1,silencedetect@d%d=n=%s:d=%g:mono=1,astats@c%d,ebur128@l%d=peak=true:framelog=verbose[a%d]",
+			i, i, qcSilenceNoise, qcSilenceDuration, i, qcDropoutNoise, qcDropoutMinDuration, i, i, i,
+		))
+		maps = append(maps, "-map", fmt.Sprintf("[a%d]", i))
+	}
+
+	args := []string{
+		"-hide_banner",
+		"-nostats",
+		"-loglevel", "info",
+		"-i", path,
+		"-filter_complex", strings.Join(graph, ";"),
+	}
+	args = append(args, maps...)
+	return append(args, "-f", "null", "-progress", "pipe:1", "-")
+}
+
+var (
+	qcPrefixPattern      = regexp.MustCompile(`^\[([^\]@ ]+)(?:@(\w+))? @ [^\]]+\] ?(.*)$`)
+	qcBlackPattern       = regexp.MustCompile(`black_start:\s*(-?[\d.]+)\s+black_end:\s*(-?[\d.]+)`)
+	qcFreezePattern      = regexp.MustCompile(`lavfi\.freezedetect\.freeze_(start|end):\s*(-?[\d.]+)`)
+	qcIdetPattern        = regexp.MustCompile(`Multi frame detection:\s*TFF:\s*(\d+)\s*BFF:\s*(\d+)\s*Progressive:\s*(\d+)`)
+	qcSilencePattern     = regexp.MustCompile(`(?:channel: (\d+) \| )?silence_(start|end): (-?[\d.]+)`)
+	qcAstatsChannel      = regexp.MustCompile(`^Channel: (\d+)`)
+	qcAstatsPeakLevel    = regexp.MustCompile(`^Peak level dB: (\S+)`)
+	qcAstatsPeakCount    = regexp.MustCompile(`^Peak count: (\d+)`)
+	qcLoudnessPattern    = regexp.MustCompile(`^\s*I:\s+(\S+) LUFS`)
+	qcLoudnessRange      = regexp.MustCompile(`^\s*LRA:\s+(\S+) LU$`)
+	qcTruePeakPattern    = regexp.MustCompile(`^\s*Peak:\s+(\S+) dBFS`)
+	qcFilterStreamNumber = regexp.MustCompile(`^([scdl])(\d+)$`)
+)
+
+// qcLevel parses a level in dB, with silence as qcSilentLevel.
+func qcLevel(s string) float64 {
+	f, err := strconv.ParseFloat(s, 64)
+	if err != nil || math.IsInf(f, -1) || f < qcSilentLevel {
+		return qcSilentLevel
+	}
+	return f
+}
+
+// parseQCLog reads what the detectors of qcArgs logged. Lines without a prefix go
+// with the filter of the line before, as ffmpeg prefixes only the first line of a
+// message.
+func parseQCLog(log string, info StreamInfo) *QCReport {
+	report := &QCReport{Duration: info.TotalSeconds}
+	if info.HasVideo {
+		report.Video = &QCVideo{FlaggedProgressive: info.Progressive}
+	}
+	for i, stream := range info.AudioStreams {
+		audio := QCAudioStream{Stream: i, IntegratedLoudness: qcSilentLevel, TruePeak: qcSilentLevel}
+		for c := 0; c < max(stream.Channels, 1); c++ {
+			audio.Channels = append(audio.Channels, QCChannel{Channel: c, PeakLevel: qcSilentLevel})
+		}
+		report.Audio = append(report.Audio, audio)
+	}
+
+	// channel finds a channel of an audio stream; ffmpeg may report a layout with more
+	// channels than the probe did.
+	channel := func(stream, c int) *QCChannel {
+		if stream >= len(report.Audio) || c < 0 {
+			return nil
+		}
+		audio := &report.Audio[stream]
+		for len(audio.Channels) <= c {
+			audio.Channels = append(audio.Channels, QCChannel{Channel: len(audio.Channels), PeakLevel: qcSilentLevel})
+		}
+		return &audio.Channels[c]
+	}
+
+	type silenceKey struct {
+		dropout         bool
+		stream, channel int
+	}
+
+	var filter, name string
+	freezeStart := -1.0
+	silenceStart := map[silenceKey]float64{}
+	astatsChannel := -1
+
+	for _, line := range strings.Split(log, "\n") {
+		line = strings.TrimRight(line, "\r")
+		text := line
+		if match := qcPrefixPattern.FindStringSubmatch(line); match != nil {
+			filter, name, text = match[1], match[2], match[3]
+		}
+
+		if name == "v" && report.Video != nil {
+			switch filter {
+			case "blackdetect":
+				if m := qcBlackPattern.FindStringSubmatch(text); m != nil {
+					report.Video.Black = append(report.Video.Black, qcPeriod(m[1], m[2]))
+				}
+			case "freezedetect":
+				if m := qcFreezePattern.FindStringSubmatch(text); m != nil {
+					at, _ := strconv.ParseFloat(m[2], 64)
+					if m[1] == "start" {
+						freezeStart = at
+					} else if freezeStart >= 0 {
+						report.Video.Freeze = append(report.Video.Freeze, QCPeriod{Start: freezeStart, End: at})
+						freezeStart = -1
+					}
+				}
+			case "idet":
+				if m := qcIdetPattern.FindStringSubmatch(text); m != nil {
+					tff, _ := strconv.Atoi(m[1])
+					bff, _ := strconv.Atoi(m[2])
+					progressive, _ := strconv.Atoi(m[3])
+					report.Video.InterlacedFrames = tff + bff
+					report.Video.ProgressiveFrames = progressive
+				}
+			}
+			continue
+		}
+
+		m := qcFilterStreamNumber.FindStringSubmatch(name)
+		if m == nil {
+			continue
+		}
+		stream, _ := strconv.Atoi(m[2])
+		if stream >= len(report.Audio) {
+			continue
+		}
+		audio := &report.Audio[stream]
+
+		switch {
+		case filter == "silencedetect" && (m[1] == "s" || m[1] == "d"):
+			sm := qcSilencePattern.FindStringSubmatch(text)
+			if sm == nil {
+				continue
+			}
+			c, _ := strconv.Atoi(sm[1])
+			key := silenceKey{dropout: m[1] == "d", stream: stream, channel: c}
+			at, _ := strconv.ParseFloat(sm[3], 64)
+			at = max(at, 0)
+			if sm[2] == "start" {
+				silenceStart[key] = at
+				continue
+			}
+			start, ok := silenceStart[key]
+			ch := channel(stream, c)
+			if !ok || ch == nil {
+				continue
+			}
+			delete(silenceStart, key)
+			if !key.dropout {
+				ch.Silence = append(ch.Silence, QCPeriod{Start: start, End: at})
+			} else {
+				ch.Dropouts = append(ch.Dropouts, QCPeriod{Start: start, End: at})
+			}
+
+		case filter == "astats" && m[1] == "c":
+			if cm := qcAstatsChannel.FindStringSubmatch(text); cm != nil {
+				n, _ := strconv.Atoi(cm[1])
+				astatsChannel = n - 1
+			} else if strings.HasPrefix(text, "Overall") {
+				astatsChannel = -1
+			} else if ch := channel(stream, astatsChannel); ch == nil {
+				continue
+			} else if pm := qcAstatsPeakLevel.FindStringSubmatch(text); pm != nil {
+				ch.PeakLevel = qcLevel(pm[1])
+			} else if pm := qcAstatsPeakCount.FindStringSubmatch(text); pm != nil {
+				ch.Clipped, _ = strconv.Atoi(pm[1])
+			}
+
+		case filter == "ebur128" && m[1] == "l":
+			if lm := qcLoudnessPattern.FindStringSubmatch(text); lm != nil {
+				audio.IntegratedLoudness = qcLevel(lm[1])
+			} else if lm := qcLoudnessRange.FindStringSubmatch(text); lm != nil {
+				audio.LoudnessRange, _ = strconv.ParseFloat(lm[1], 64)
+			} else if lm := qcTruePeakPattern.FindStringSubmatch(text); lm != nil {
+				audio.TruePeak = qcLevel(lm[1])
+			}
+		}
+	}
+
+	// What is still black, frozen or silent when the file ends, is until the end.
+	if report.Video != nil && freezeStart >= 0 {
+		report.Video.Freeze = append(report.Video.Freeze, QCPeriod{Start: freezeStart, End: report.Duration})
+	}
+	for key, start := range silenceStart {
+		if key.dropout || report.Duration-start < qcSilenceDuration {
+			continue
+		}
+		if ch := channel(key.stream, key.channel); ch != nil {
+			ch.Silence = append(ch.Silence, QCPeriod{Start: start, End: report.Duration})
+		}
+	}
+
+	for i := range report.Audio {
+		for j := range report.Audio[i].Channels {
+			ch := &report.Audio[i].Channels[j]
+			ch.Dropouts = qcDropouts(ch.Dropouts, report.Duration)
+			if ch.PeakLevel < qcClipLevel {
+				ch.Clipped = 0
+			}
+		}
+	}
+
+	return report
+}
+
+func qcPeriod(start, end string) QCPeriod {
+	s, _ := strconv.ParseFloat(start, 64)
+	e, _ := strconv.ParseFloat(end, 64)
+	return QCPeriod{Start: max(s, 0), End: e}
+}
+
+// qcDropouts keeps the digital silence that is short, and has sound before and after
+// it; the rest is the start or end of the file, or a pause.
+func qcDropouts(periods []QCPeriod, duration float64) []QCPeriod {
+	var out []QCPeriod
+	for _, p := range periods {
+		if p.End-p.Start >= qcDropoutMaxDuration || p.Start <= 0 || (duration > 0 && p.End >= duration) {
+			continue
+		}
+		out = append(out, p)
+	}
+	return out
+}
+
+// qcTime formats seconds as HH:MM:SS.mmm.
+func qcTime(seconds float64) string {
+	ms := int64(math.Round(seconds * 1000))
+	return fmt.Sprintf("%02d:%02d:%02d.%03d", ms/3600000, ms/60000%60, ms/1000%60, ms%1000)
+}
+
+// Issues are what in the report is outside what is expected of a master, in the order
+// of the tracks, and by time within each.
+func (r QCReport) Issues() []QCIssue {
+	var issues []QCIssue
+	add := func(check, track string, p QCPeriod, format string, args ...any) {
+		issues = append(issues, QCIssue{Check: check, Track: track, Start: p.Start, End: p.End, Message: fmt.Sprintf(format, args...)})
+	}
+
+	if v := r.Video; v != nil {
+		for _, p := range v.Black {
+			add("black", "v", p, "black for %.1fs", p.End-p.Start)
+		}
+		for _, p := range v.Freeze {
+			add("freeze", "v", p, "frozen for %.1fs", p.End-p.Start)
+		}
+		if v.FlaggedProgressive && v.InterlacedFrames > v.ProgressiveFrames {
+			add("interlace", "v", QCPeriod{End: r.Duration}, "flagged progressive, but %d of %d frames look interlaced",
+				v.InterlacedFrames, v.InterlacedFrames+v.ProgressiveFrames)
+		}
+	}
+
+	for _, a := range r.Audio {
+		stream := fmt.Sprintf("a%d", a.Stream)
+		if a.TruePeak > qcTruePeakLimit {
+			add("true-peak", stream, QCPeriod{End: r.Duration}, "true peak of %.1f dBTP, above %.1f", a.TruePeak, qcTruePeakLimit)
+		}
+		for _, ch := range a.Channels {
+			track := fmt.Sprintf("%s.%d", stream, ch.Channel)
+			for _, p := range ch.Silence {
+				if p.Start <= 0 && r.Duration > 0 && p.End >= r.Duration-1 {
+					add("silence", track, p, "silent throughout")
+					continue
+				}
+				add("silence", track, p, "silent for %.1fs", p.End-p.Start)
+			}
+			for _, p := range ch.Dropouts {
+				add("dropout", track, p, "drops out for %.0fms", (p.End-p.Start)*1000)
+			}
+			if ch.Clipped > 0 {
+				add("clipping", track, QCPeriod{End: r.Duration}, "reaches full scale %d times", ch.Clipped)
+			}
+		}
+	}
+	return issues
+}
+
+// summaryIssues is how many issues Summary lists; the rest are counted.
+const summaryIssues = 5
+
+// Summary describes the issues of the report in a line or a few, for notifications.
+func (r QCReport) Summary() string {
+	issues := r.Issues()
+	if len(issues) == 0 {
+		return "QC passed"
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "QC found %d issues", len(issues))
+	for i, issue := range issues {
+		if i == summaryIssues {
+			fmt.Fprintf(&b, "; and %d more", len(issues)-summaryIssues)
+			break
+		}
+		sep := ": "
+		if i > 0 {
+			sep = "; "
+		}
+		fmt.Fprintf(&b, "%s%s %s at %s", sep, issue.Track, issue.Message, qcTime(issue.Start))
+	}
+	return b.String()
+}
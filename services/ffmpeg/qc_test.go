@@ -0,0 +1,122 @@
+package ffmpeg
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// qcTestLog is what ffmpeg logs for a minute of video with a black slate, a freeze
+// that lasts to the end, and a stereo stream whose right channel is silent.
+const qcTestLog = `Input #0, mxf, from 'master.mxf':
+  Duration: 00:01:00.00, start: 0.000000, bitrate: 100000 kb/s
+[blackdetect@v @ 0x55d1c0a3c2c0] black_start:0 black_end:2.48 black_duration:2.48
+[silencedetect@s0 @ 0x55d1c0a3d100] channel: 1 | silence_start: -0.0213
+[silencedetect@d0 @ 0x55d1c0a3d400] channel: 0 | silence_start: 12.5
+[silencedetect@d0 @ 0x55d1c0a3d400] channel: 0 | silence_end: 12.54 | silence_duration: 0.04
+[silencedetect@d0 @ 0x55d1c0a3d400] channel: 0 | silence_start: 30
+[silencedetect@d0 @ 0x55d1c0a3d400] channel: 0 | silence_end: 33 | silence_duration: 3
+[freezedetect@v @ 0x55d1c0a3c5c0] lavfi.freezedetect.freeze_start: 50.04
+[freezedetect@v @ 0x55d1c0a3c5c0] lavfi.freezedetect.freeze_duration: 5.2
+[idet@v @ 0x55d1c0a3c000] Repeated Fields: Neither:  1500 Top:     0 Bottom:     0
+[idet@v @ 0x55d1c0a3c000] Single frame detection: TFF:  1200 BFF:     0 Progressive:   250 Undetermined:    50
+[idet@v @ 0x55d1c0a3c000] Multi frame detection: TFF:  1400 BFF:     0 Progressive:   100 Undetermined:     0
+[astats@c0 @ 0x55d1c0a3d700] Channel: 1
+[astats@c0 @ 0x55d1c0a3d700] DC offset: 0.000001
+[astats@c0 @ 0x55d1c0a3d700] Peak level dB: -0.000000
+[astats@c0 @ 0x55d1c0a3d700] Peak count: 14
+[astats@c0 @ 0x55d1c0a3d700] Channel: 2
+[astats@c0 @ 0x55d1c0a3d700] Peak level dB: -inf
+[astats@c0 @ 0x55d1c0a3d700] Peak count: 2880000
+[astats@c0 @ 0x55d1c0a3d700] Overall
+[astats@c0 @ 0x55d1c0a3d700] Peak level dB: -0.000000
+[ebur128@l0 @ 0x55d1c0a3da00] Summary:
+
+  Integrated loudness:
+    I:         -21.3 LUFS
+    Threshold: -31.5 LUFS
+
+  Loudness range:
+    LRA:         6.2 LU
+    Threshold:  -41.4 LUFS
+    LRA low:    -26.0 LUFS
+    LRA high:   -19.8 LUFS
+
+  True peak:
+    Peak:        0.4 dBFS
+[out#0/null @ 0x55d1c0a3b000] video:700kB audio:11250kB subtitle:0kB
+`
+
+var qcTestInfo = StreamInfo{
+	HasVideo:     true,
+	HasAudio:     true,
+	Progressive:  true,
+	TotalSeconds: 60,
+	AudioStreams: []FFProbeStream{{Channels: 2}},
+}
+
+func TestParseQCLog(t *testing.T) {
+	report := parseQCLog(qcTestLog, qcTestInfo)
+
+	require.NotNil(t, report.Video)
+	assert.Equal(t, &QCVideo{
+		Black:              []QCPeriod{{Start: 0, End: 2.48}},
+		Freeze:             []QCPeriod{{Start: 50.04, End: 60}},
+		InterlacedFrames:   1400,
+		ProgressiveFrames:  100,
+		FlaggedProgressive: true,
+	}, report.Video)
+
+	require.Len(t, report.Audio, 1)
+	audio := report.Audio[0]
+	assert.Equal(t, -21.3, audio.IntegratedLoudness)
+	assert.Equal(t, 6.2, audio.LoudnessRange)
+	assert.Equal(t, 0.4, audio.TruePeak)
+	assert.Equal(t, []QCChannel{
+		{Channel: 0, Dropouts: []QCPeriod{{Start: 12.5, End: 12.54}}, PeakLevel: 0, Clipped: 14},
+		{Channel: 1, Silence: []QCPeriod{{Start: 0, End: 60}}, PeakLevel: qcSilentLevel},
+	}, audio.Channels)
+}
+
+func TestQCReport_Issues(t *testing.T) {
+	report := parseQCLog(qcTestLog, qcTestInfo)
+
+	var checks []string
+	for _, issue := range report.Issues() {
+		checks = append(checks, issue.Track+" "+issue.Check)
+	}
+	assert.Equal(t, []string{
+		"v black",
+		"v freeze",
+		"v interlace",
+		"a0 true-peak",
+		"a0.0 dropout",
+		"a0.0 clipping",
+		"a0.1 silence",
+	}, checks)
+
+	summary := report.Summary()
+	assert.Contains(t, summary, "QC found 7 issues: v black for 2.5s at 00:00:00.000; v frozen for 10.0s at 00:00:50.040")
+	assert.Contains(t, summary, "; and 2 more")
+}
+
+func TestQCReport_Clean(t *testing.T) {
+	report := parseQCLog("", StreamInfo{HasAudio: true, TotalSeconds: 10, AudioStreams: []FFProbeStream{{Channels: 1}}})
+
+	assert.Empty(t, report.Issues())
+	assert.Equal(t, "QC passed", report.Summary())
+}
+
+func TestQCArgs(t *testing.T) {
+	args := qcArgs("in.mov", StreamInfo{HasVideo: true, HasAudio: true, AudioStreams: []FFProbeStream{{}, {}}})
+
+	assert.Equal(t, []string{
+		"-hide_banner", "-nostats", "-loglevel", "info", "-i", "in.mov",
+		"-filter_complex", "[0:v:0]idet@v,blackdetect@v=d=2:pix_th=0.10,freezedetect@v=n=-60dB:d=5[v];" +
+			"[0:a:0]silencedetect@s0=n=-60dB:d=10:mono=1,silencedetect@d0=n=-90dB:d=0.02:mono=1,astats@c0,ebur128@l0=peak=true:framelog=verbose[a0];" +
+			"[0:a:1]silencedetect@s1=n=-60dB:d=10:mono=1,silencedetect@d1=n=-90dB:d=0.02:mono=1,astats@c1,ebur128@l1=peak=true:framelog=verbose[a1]",
+		"-map", "[v]", "-map", "[a0]", "-map", "[a1]",
+		"-f", "null", "-progress", "pipe:1", "-",
+	}, args)
+}
@@ -129,3 +129,14 @@ func TestJobArgumentsWithPerInputOptions(t *testing.T) {
 		"-y", "/out/muxed.mxf",
 	}, args)
 }
+
+func TestProgressSpeedRatio(t *testing.T) {
+	speed, ok := Progress{Speed: "2.31x"}.SpeedRatio()
+	assert.True(t, ok)
+	assert.Equal(t, 2.31, speed)
+
+	for _, s := range []string{"", "N/A", "0x"} {
+		_, ok = Progress{Speed: s}.SpeedRatio()
+		assert.False(t, ok, s)
+	}
+}
@@ -0,0 +1,181 @@
+// Package fixity computes the checksums files are recorded with at ingest, and checks
+// copies of them against what was recorded.
+package fixity
+
+import (
+	"context"
+	"crypto/md5"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/cespare/xxhash/v2"
+	"go.temporal.io/sdk/temporal"
+)
+
+// Checksums are what a file is recorded with. MD5 is what S3 and rclone can check a
+// copy against; xxHash is much cheaper to recompute on the worker.
+type Checksums struct {
+	MD5    string `json:"md5"`
+	XXHash string `json:"xxhash"`
+	Size   int64  `json:"size"`
+}
+
+var (
+	ErrMismatch = errors.New("checksum mismatch")
+	ErrNotSet   = errors.New("no checksums recorded")
+)
+
+// MismatchError is a copy that is not the file that was recorded.
+type MismatchError struct {
+	File     string
+	Expected Checksums
+	Actual   Checksums
+}
+
+func (e *MismatchError) Error() string {
+	switch {
+	case e.Expected.Size != 0 && e.Actual.Size != e.Expected.Size:
+		return fmt.Sprintf("%s: size is %d, expected %d", e.File, e.Actual.Size, e.Expected.Size)
+	case e.Expected.MD5 != "" && e.Actual.MD5 != e.Expected.MD5:
+		return fmt.Sprintf("%s: MD5 is %s, expected %s", e.File, e.Actual.MD5, e.Expected.MD5)
+	}
+	return fmt.Sprintf("%s: xxHash is %s, expected %s", e.File, e.Actual.XXHash, e.Expected.XXHash)
+}
+
+func (e *MismatchError) Unwrap() error {
+	return ErrMismatch
+}
+
+// ErrorType is the type of the application error a copy that does not match its
+// checksums fails with.
+const ErrorType = "CHECKSUM_MISMATCH"
+
+// ApplicationError makes a mismatch a non-retryable Temporal error: copying again does
+// not fix the source. Other errors are returned as they are.
+func ApplicationError(err error) error {
+	var mismatch *MismatchError
+	if errors.As(err, &mismatch) {
+		return temporal.NewNonRetryableApplicationError(err.Error(), ErrorType, err, *mismatch)
+	}
+	return err
+}
+
+// IsZero is whether nothing is recorded.
+func (c Checksums) IsZero() bool {
+	return c.MD5 == "" && c.XXHash == ""
+}
+
+// Match compares the checksums that are in both, and the sizes when both have one. It
+// is false when the two have no checksum in common, as nothing is then known.
+func (c Checksums) Match(other Checksums) bool {
+	if c.Size != 0 && other.Size != 0 && c.Size != other.Size {
+		return false
+	}
+
+	compared := false
+	if c.MD5 != "" && other.MD5 != "" {
+		if c.MD5 != other.MD5 {
+			return false
+		}
+		compared = true
+	}
+	if c.XXHash != "" && other.XXHash != "" {
+		if c.XXHash != other.XXHash {
+			return false
+		}
+		compared = true
+	}
+	return compared
+}
+
+// String is the checksums as they are stored in Vidispine.
+func (c Checksums) String() string {
+	data, _ := json.Marshal(c)
+	return string(data)
+}
+
+// Parse reads checksums as String writes them.
+func Parse(value string) (Checksums, error) {
+	if value == "" {
+		return Checksums{}, ErrNotSet
+	}
+	var c Checksums
+	if err := json.Unmarshal([]byte(value), &c); err != nil {
+		return Checksums{}, fmt.Errorf("invalid checksums %q: %w", value, err)
+	}
+	if c.IsZero() {
+		return Checksums{}, ErrNotSet
+	}
+	return c, nil
+}
+
+// progressInterval is how much is read between calls to the progress callback.
+const progressInterval = 256 << 20
+
+type progressReader struct {
+	ctx      context.Context
+	r        io.Reader
+	read     int64
+	reported int64
+	progress func(read int64)
+}
+
+func (p *progressReader) Read(b []byte) (int, error) {
+	if err := p.ctx.Err(); err != nil {
+		return 0, err
+	}
+	n, err := p.r.Read(b)
+	p.read += int64(n)
+	if p.progress != nil && p.read-p.reported >= progressInterval {
+		p.reported = p.read
+		p.progress(p.read)
+	}
+	return n, err
+}
+
+// Compute reads the file once for both checksums. progress, when not nil, is called
+// with what has been read every few hundred megabytes, which is what an activity
+// heartbeats on.
+func Compute(ctx context.Context, file string, progress func(read int64)) (Checksums, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return Checksums{}, err
+	}
+	defer f.Close()
+
+	md5Hash := md5.New()
+	xxHash := xxhash.New()
+
+	reader := &progressReader{ctx: ctx, r: f, progress: progress}
+	size, err := io.Copy(io.MultiWriter(md5Hash, xxHash), reader)
+	if err != nil {
+		return Checksums{}, err
+	}
+
+	return Checksums{
+		MD5:    hex.EncodeToString(md5Hash.Sum(nil)),
+		XXHash: hex.EncodeToString(xxHash.Sum(nil)),
+		Size:   size,
+	}, nil
+}
+
+// Verify computes the checksums of the file and compares them to the expected ones.
+// A file that does not match is a *MismatchError.
+func Verify(ctx context.Context, file string, expected Checksums, progress func(read int64)) (Checksums, error) {
+	if expected.IsZero() {
+		return Checksums{}, ErrNotSet
+	}
+
+	actual, err := Compute(ctx, file, progress)
+	if err != nil {
+		return Checksums{}, err
+	}
+	if !expected.Match(actual) {
+		return actual, &MismatchError{File: file, Expected: expected, Actual: actual}
+	}
+	return actual, nil
+}
@@ -0,0 +1,71 @@
+package fixity
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeFile(t *testing.T, data string) string {
+	file := filepath.Join(t.TempDir(), "original.mxf")
+	require.NoError(t, os.WriteFile(file, []byte(data), 0644))
+	return file
+}
+
+func Test_Compute(t *testing.T) {
+	sums, err := Compute(context.Background(), writeFile(t, "hello"), nil)
+	require.NoError(t, err)
+
+	assert.Equal(t, "5d41402abc4b2a76b9719d911017c592", sums.MD5)
+	assert.Equal(t, "26c7827d889f6da3", sums.XXHash)
+	assert.Equal(t, int64(5), sums.Size)
+}
+
+func Test_Verify(t *testing.T) {
+	file := writeFile(t, "hello")
+	recorded, err := Compute(context.Background(), file, nil)
+	require.NoError(t, err)
+
+	_, err = Verify(context.Background(), file, recorded, nil)
+	assert.NoError(t, err)
+
+	require.NoError(t, os.WriteFile(file, []byte("hellO"), 0644))
+	_, err = Verify(context.Background(), file, recorded, nil)
+	assert.ErrorIs(t, err, ErrMismatch)
+
+	var mismatch *MismatchError
+	require.True(t, errors.As(err, &mismatch))
+	assert.Contains(t, mismatch.Error(), "MD5 is ")
+
+	_, err = Verify(context.Background(), file, Checksums{}, nil)
+	assert.ErrorIs(t, err, ErrNotSet)
+}
+
+func Test_Match(t *testing.T) {
+	full := Checksums{MD5: "a", XXHash: "b", Size: 5}
+
+	assert.True(t, full.Match(Checksums{MD5: "a"}), "rclone only has the MD5")
+	assert.False(t, full.Match(Checksums{MD5: "a", Size: 6}))
+	assert.False(t, full.Match(Checksums{XXHash: "c"}))
+	assert.False(t, full.Match(Checksums{Size: 5}), "nothing to compare")
+}
+
+func Test_ParseString(t *testing.T) {
+	sums := Checksums{MD5: "5d41402abc4b2a76b9719d911017c592", XXHash: "26c7827d889f6da3", Size: 5}
+
+	parsed, err := Parse(sums.String())
+	require.NoError(t, err)
+	assert.Equal(t, sums, parsed)
+
+	_, err = Parse("")
+	assert.ErrorIs(t, err, ErrNotSet)
+	_, err = Parse("{}")
+	assert.ErrorIs(t, err, ErrNotSet)
+	_, err = Parse("md5")
+	assert.Error(t, err)
+}
@@ -0,0 +1,23 @@
+package notifications
+
+// What a notification reports, for routing it. Most are plain messages: the
+// workflows word their own progress.
+const (
+	EventStarted   = "started"
+	EventCompleted = "completed"
+	EventFailed    = "failed"
+	EventMessage   = "message"
+)
+
+// EventOf is what a template reports.
+func EventOf(t Template) string {
+	switch t.(type) {
+	case ImportTriggered, *ImportTriggered:
+		return EventStarted
+	case ImportCompleted, *ImportCompleted:
+		return EventCompleted
+	case ImportFailed, *ImportFailed:
+		return EventFailed
+	}
+	return EventMessage
+}
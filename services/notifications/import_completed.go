@@ -29,6 +29,9 @@ func (t ImportCompleted) RenderMarkdown() (string, error) {
 	files := ""
 	for _, f := range t.Files {
 		files += fmt.Sprintf("- `%s`\n", f.Name)
+		if f.QC != "" {
+			files += fmt.Sprintf("  %s\n", f.QC)
+		}
 	}
 
 	return fmt.Sprintf(md, t.JobID, files), nil
@@ -0,0 +1,186 @@
+package routing
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path"
+	"time"
+)
+
+// Channel types.
+const (
+	TypeTelegram = "telegram"
+	TypeEmail    = "email"
+	// TypeSlack is a Slack-compatible incoming webhook: it is posted {"text": ...}.
+	TypeSlack = "slack"
+	// TypeWebhook is posted the whole notification as JSON, signed when it has a secret.
+	TypeWebhook = "webhook"
+)
+
+// Duration reads "30m" and the like from JSON.
+type Duration time.Duration
+
+func (d *Duration) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return fmt.Errorf("duration must be a string like \"30m\": %w", err)
+	}
+	parsed, err := time.ParseDuration(s)
+	if err != nil {
+		return err
+	}
+	*d = Duration(parsed)
+	return nil
+}
+
+func (d Duration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(time.Duration(d).String())
+}
+
+// Channel is somewhere notifications can be sent.
+type Channel struct {
+	Type string `json:"type"`
+	// ChatID is the Telegram chat of a telegram channel.
+	ChatID int64 `json:"chatId,omitempty"`
+	// To are the recipients of an email channel.
+	To []string `json:"to,omitempty"`
+	// URL and Secret are where a slack or webhook channel posts to, and what a webhook
+	// signs with.
+	URL    string `json:"url,omitempty"`
+	Secret string `json:"secret,omitempty"`
+	// PerMinute is how many notifications the channel is sent in a minute at most; the
+	// rest are dropped. 0 is no limit.
+	PerMinute int `json:"perMinute,omitempty"`
+}
+
+func (c Channel) validate() error {
+	switch c.Type {
+	case TypeTelegram:
+		if c.ChatID == 0 {
+			return fmt.Errorf("telegram channel needs a chatId")
+		}
+	case TypeEmail:
+		if len(c.To) == 0 {
+			return fmt.Errorf("email channel needs recipients in to")
+		}
+	case TypeSlack, TypeWebhook:
+		if c.URL == "" {
+			return fmt.Errorf("%s channel needs a url", c.Type)
+		}
+	default:
+		return fmt.Errorf("unknown channel type %q", c.Type)
+	}
+	return nil
+}
+
+// Route sends the notifications it matches to its channels instead of where the
+// workflow sent them. Every condition given must match; one not given matches
+// anything.
+type Route struct {
+	Name string `json:"name"`
+	// Events are notifications.Event values.
+	Events []string `json:"events,omitempty"`
+	// Workflows are workflow type names, or path.Match patterns of them.
+	Workflows []string `json:"workflows,omitempty"`
+	// Via is telegram or email: how the workflow sent the notification.
+	Via []string `json:"via,omitempty"`
+	// Chats are the names of the Telegram chats the workflow sent to, as
+	// telegram.ChatName gives them: vod, oslofjord, other or bmm.
+	Chats []string `json:"chats,omitempty"`
+
+	// Channels are names from Config.Channels. "default" is where the workflow sent
+	// the notification. A route without channels drops what it matches.
+	Channels []string `json:"channels"`
+	// DedupWindow overrides the configuration's for what the route matches.
+	DedupWindow *Duration `json:"dedupWindow,omitempty"`
+}
+
+// DefaultChannel in a route's channels is where the workflow sent the notification.
+const DefaultChannel = "default"
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+func (r Route) matches(n Notification) bool {
+	if len(r.Events) > 0 && !contains(r.Events, n.Event) {
+		return false
+	}
+	if len(r.Via) > 0 && !contains(r.Via, n.Via) {
+		return false
+	}
+	if len(r.Chats) > 0 && !contains(r.Chats, n.Chat) {
+		return false
+	}
+	if len(r.Workflows) > 0 {
+		matched := false
+		for _, pattern := range r.Workflows {
+			if ok, _ := path.Match(pattern, n.Workflow); ok {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return false
+		}
+	}
+	return true
+}
+
+// Config is the notification routes file. Routes are tried in order and the first
+// one matching a notification decides where it goes; a notification no route
+// matches goes where the workflow sent it.
+type Config struct {
+	Channels map[string]Channel `json:"channels"`
+	Routes   []Route            `json:"routes"`
+	// DedupWindow is how long a failure is not sent again to a channel that was just
+	// sent the same one. 0 sends every repeat.
+	DedupWindow Duration `json:"dedupWindow,omitempty"`
+}
+
+func (c Config) validate() error {
+	for name, channel := range c.Channels {
+		if name == DefaultChannel {
+			return fmt.Errorf("channel %q: the name is reserved for where the workflow sent the notification", name)
+		}
+		if err := channel.validate(); err != nil {
+			return fmt.Errorf("channel %q: %w", name, err)
+		}
+	}
+	for i, route := range c.Routes {
+		for _, pattern := range route.Workflows {
+			if _, err := path.Match(pattern, ""); err != nil {
+				return fmt.Errorf("route %d (%s): workflow pattern %q: %w", i, route.Name, pattern, err)
+			}
+		}
+		for _, name := range route.Channels {
+			if _, ok := c.Channels[name]; !ok && name != DefaultChannel {
+				return fmt.Errorf("route %d (%s): unknown channel %q", i, route.Name, name)
+			}
+		}
+	}
+	return nil
+}
+
+// LoadConfig reads and validates a routes file.
+func LoadConfig(file string) (Config, error) {
+	var config Config
+
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return config, err
+	}
+	if err := json.Unmarshal(data, &config); err != nil {
+		return config, fmt.Errorf("%s: %w", file, err)
+	}
+	if err := config.validate(); err != nil {
+		return config, fmt.Errorf("%s: %w", file, err)
+	}
+	return config, nil
+}
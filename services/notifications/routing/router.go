@@ -0,0 +1,243 @@
+// Package routing decides where the notifications workflows send end up. Workflows
+// keep naming a Telegram chat or email recipients; a routes file can send what they
+// send elsewhere, to several places, or nowhere, by event and workflow. Repeated
+// failures are sent once per dedup window, and channels can be rate limited.
+package routing
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"html"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/bcc-code/bcc-media-flows/cache"
+	"github.com/bcc-code/bcc-media-flows/services/emails"
+	"github.com/bcc-code/bcc-media-flows/services/notifications"
+	"github.com/bcc-code/bcc-media-flows/services/telegram"
+	"github.com/bcc-code/bcc-media-flows/services/webhooks"
+)
+
+// Notification is one message a workflow sends.
+type Notification struct {
+	Event      string `json:"event"`
+	Workflow   string `json:"workflow"`
+	WorkflowID string `json:"workflowId"`
+	// Via is telegram or email: how the workflow sent it.
+	Via string `json:"via"`
+	// Chat is the name of the Telegram chat the workflow sent it to.
+	Chat    string `json:"chat,omitempty"`
+	Subject string `json:"subject,omitempty"`
+	// Text is Markdown, which is what the Telegram messages are written in and the
+	// plain text part of the emails.
+	Text string `json:"text"`
+	HTML string `json:"-"`
+}
+
+// senders do the sending, so tests can see what would be sent.
+type senders struct {
+	telegram func(*telegram.Message) (*telegram.Message, error)
+	email    func(to, subject, plainText, html string) error
+	post     func(ctx context.Context, url, secret string, payload any) error
+}
+
+type window struct {
+	start time.Time
+	count int
+}
+
+// Router sends notifications where the configuration says.
+type Router struct {
+	config  Config
+	senders senders
+	now     func() time.Time
+
+	lock    sync.Mutex
+	windows map[string]*window
+}
+
+func New(config Config) *Router {
+	return &Router{
+		config: config,
+		senders: senders{
+			telegram: telegram.Send,
+			email:    emails.Send,
+			post:     webhooks.Post,
+		},
+		now:     time.Now,
+		windows: map[string]*window{},
+	}
+}
+
+var (
+	defaultLock   sync.RWMutex
+	defaultRouter = New(Config{})
+)
+
+// Default is the router the notification activities use. Until SetDefault is called
+// it sends everything where the workflow sent it.
+func Default() *Router {
+	defaultLock.RLock()
+	defer defaultLock.RUnlock()
+	return defaultRouter
+}
+
+func SetDefault(r *Router) {
+	defaultLock.Lock()
+	defer defaultLock.Unlock()
+	defaultRouter = r
+}
+
+// target is one place a notification is sent, and the name it is rate limited and
+// de-duplicated under.
+type target struct {
+	name    string
+	channel Channel
+}
+
+// targets are where the notification goes, and how long a repeat of it is not
+// sent again.
+func (r *Router) targets(n Notification, origin Channel) ([]target, time.Duration) {
+	originTarget := target{
+		name:    DefaultChannel + ":" + n.Via + ":" + n.Chat,
+		channel: origin,
+	}
+
+	for _, route := range r.config.Routes {
+		if !route.matches(n) {
+			continue
+		}
+
+		dedup := time.Duration(r.config.DedupWindow)
+		if route.DedupWindow != nil {
+			dedup = time.Duration(*route.DedupWindow)
+		}
+
+		var targets []target
+		for _, name := range route.Channels {
+			if name == DefaultChannel {
+				targets = append(targets, originTarget)
+			} else {
+				targets = append(targets, target{name, r.config.Channels[name]})
+			}
+		}
+		return targets, dedup
+	}
+
+	return []target{originTarget}, time.Duration(r.config.DedupWindow)
+}
+
+// allow counts the notification against the channel's limit for the current minute.
+func (r *Router) allow(t target) bool {
+	if t.channel.PerMinute <= 0 {
+		return true
+	}
+
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	now := r.now()
+	w, ok := r.windows[t.name]
+	if !ok || now.Sub(w.start) >= time.Minute {
+		w = &window{start: now}
+		r.windows[t.name] = w
+	}
+	if w.count >= t.channel.PerMinute {
+		return false
+	}
+	w.count++
+	return true
+}
+
+func dedupKey(t target, n Notification) string {
+	sum := sha256.Sum256([]byte(strings.Join([]string{t.name, n.Workflow, n.Event, n.Subject, n.Text}, "\x00")))
+	return "notification:" + hex.EncodeToString(sum[:])
+}
+
+// Send sends the notification to where it is routed. origin is where the workflow
+// sent it. For a notification that went to Telegram, the message it became is
+// returned, so the workflow can edit it later.
+func (r *Router) Send(ctx context.Context, n Notification, origin Channel) (*telegram.Message, error) {
+	targets, dedup := r.targets(n, origin)
+	if n.Event != notifications.EventFailed {
+		dedup = 0
+	}
+
+	var sent *telegram.Message
+	var errs []error
+	for _, t := range targets {
+		key := dedupKey(t, n)
+		if dedup > 0 {
+			if _, seen := cache.Default().Get(key); seen {
+				continue
+			}
+		}
+		if !r.allow(t) {
+			log.Printf("Notification to %s dropped: more than %d in a minute", t.name, t.channel.PerMinute)
+			continue
+		}
+
+		msg, err := r.send(ctx, t.channel, n)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", t.name, err))
+			continue
+		}
+		if sent == nil {
+			sent = msg
+		}
+		if dedup > 0 {
+			cache.Default().Set(key, []byte{1}, dedup)
+		}
+	}
+
+	return sent, errors.Join(errs...)
+}
+
+func (r *Router) send(ctx context.Context, channel Channel, n Notification) (*telegram.Message, error) {
+	switch channel.Type {
+	case TypeTelegram:
+		return r.senders.telegram(&telegram.Message{
+			Chat:     telegram.Chat{Value: channel.ChatID},
+			Markdown: n.Text,
+			Event:    n.Event,
+		})
+
+	case TypeEmail:
+		subject := n.Subject
+		if subject == "" {
+			subject = fmt.Sprintf("%s %s", n.Workflow, n.Event)
+		}
+		body := n.HTML
+		if body == "" {
+			body = "<pre>" + html.EscapeString(n.Text) + "</pre>"
+		}
+
+		var errs []error
+		for _, to := range channel.To {
+			// Skip blank recipients. Recipient lists are built by splitting a
+			// comma-separated field, so trailing commas or an empty sender field
+			// yield empty entries that SendGrid rejects outright.
+			to = strings.TrimSpace(to)
+			if to == "" {
+				continue
+			}
+			if err := r.senders.email(to, subject, n.Text, body); err != nil {
+				errs = append(errs, err)
+			}
+		}
+		return nil, errors.Join(errs...)
+
+	case TypeSlack:
+		return nil, r.senders.post(ctx, channel.URL, "", map[string]string{"text": n.Text})
+
+	case TypeWebhook:
+		return nil, r.senders.post(ctx, channel.URL, channel.Secret, n)
+	}
+
+	return nil, fmt.Errorf("unknown channel type %q", channel.Type)
+}
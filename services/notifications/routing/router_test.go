@@ -0,0 +1,203 @@
+package routing
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/bcc-code/bcc-media-flows/cache"
+	"github.com/bcc-code/bcc-media-flows/services/notifications"
+	"github.com/bcc-code/bcc-media-flows/services/telegram"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// recording is what a router with recordingSenders sent, by channel.
+type recording struct {
+	telegram []int64
+	email    []string
+	posts    map[string]any
+}
+
+func newRecordingRouter(t *testing.T, config Config) (*Router, *recording) {
+	t.Helper()
+
+	previous := cache.Default()
+	cache.SetDefault(cache.NewMemory(100))
+	t.Cleanup(func() { cache.SetDefault(previous) })
+
+	sent := &recording{posts: map[string]any{}}
+	r := New(config)
+	r.senders = senders{
+		telegram: func(msg *telegram.Message) (*telegram.Message, error) {
+			sent.telegram = append(sent.telegram, msg.Chat.Value)
+			return msg, nil
+		},
+		email: func(to, _, _, _ string) error {
+			sent.email = append(sent.email, to)
+			return nil
+		},
+		post: func(_ context.Context, url, _ string, payload any) error {
+			sent.posts[url] = payload
+			return nil
+		},
+	}
+	return r, sent
+}
+
+var testConfig = Config{
+	DedupWindow: Duration(30 * time.Minute),
+	Channels: map[string]Channel{
+		"slack": {Type: TypeSlack, URL: "https://slack.example/hook"},
+		"quiet": {Type: TypeTelegram, ChatID: 42},
+		"mail":  {Type: TypeEmail, To: []string{"ops@example.com", " "}},
+	},
+	Routes: []Route{
+		{Name: "export failures", Events: []string{notifications.EventFailed}, Workflows: []string{"VXExport*"}, Channels: []string{DefaultChannel, "slack"}},
+		{Name: "subtitles", Chats: []string{"other"}, Workflows: []string{"ImportSubtitlesFromSubtrans"}, Channels: []string{"quiet", "mail"}},
+		{Name: "muted", Workflows: []string{"FixDurationVX"}},
+	},
+}
+
+var vodChat = Channel{Type: TypeTelegram, ChatID: 7}
+
+func TestSend_UnroutedGoesWhereTheWorkflowSentIt(t *testing.T) {
+	r, sent := newRecordingRouter(t, testConfig)
+
+	msg, err := r.Send(context.Background(), Notification{Event: notifications.EventMessage, Workflow: "VXExport", Via: TypeTelegram, Chat: "vod", Text: "started"}, vodChat)
+
+	require.NoError(t, err)
+	assert.Equal(t, []int64{7}, sent.telegram)
+	assert.Empty(t, sent.posts)
+	require.NotNil(t, msg)
+	assert.Equal(t, int64(7), msg.Chat.Value)
+}
+
+func TestSend_RoutesByEventAndWorkflow(t *testing.T) {
+	r, sent := newRecordingRouter(t, testConfig)
+
+	_, err := r.Send(context.Background(), Notification{Event: notifications.EventFailed, Workflow: "VXExportToVOD", Via: TypeTelegram, Chat: "vod", Text: "🟥 failed"}, vodChat)
+
+	require.NoError(t, err)
+	assert.Equal(t, []int64{7}, sent.telegram)
+	assert.Equal(t, map[string]any{"https://slack.example/hook": map[string]string{"text": "🟥 failed"}}, sent.posts)
+}
+
+func TestSend_RoutesByChat(t *testing.T) {
+	r, sent := newRecordingRouter(t, testConfig)
+	other := Channel{Type: TypeTelegram, ChatID: 9}
+
+	_, err := r.Send(context.Background(), Notification{Event: notifications.EventMessage, Workflow: "ImportSubtitlesFromSubtrans", Via: TypeTelegram, Chat: "other", Text: "done"}, other)
+	require.NoError(t, err)
+	assert.Equal(t, []int64{42}, sent.telegram)
+	assert.Equal(t, []string{"ops@example.com"}, sent.email, "blank recipients are skipped")
+
+	_, err = r.Send(context.Background(), Notification{Event: notifications.EventMessage, Workflow: "ImportSubtitlesFromSubtrans", Via: TypeTelegram, Chat: "vod", Text: "done"}, vodChat)
+	require.NoError(t, err)
+	assert.Equal(t, []int64{42, 7}, sent.telegram, "another chat is not matched")
+}
+
+func TestSend_RouteWithoutChannelsDrops(t *testing.T) {
+	r, sent := newRecordingRouter(t, testConfig)
+
+	msg, err := r.Send(context.Background(), Notification{Event: notifications.EventMessage, Workflow: "FixDurationVX", Via: TypeTelegram, Chat: "other", Text: "fixed"}, vodChat)
+
+	require.NoError(t, err)
+	assert.Nil(t, msg)
+	assert.Empty(t, sent.telegram)
+}
+
+func TestSend_DeduplicatesRepeatedFailures(t *testing.T) {
+	r, sent := newRecordingRouter(t, testConfig)
+	failure := Notification{Event: notifications.EventFailed, Workflow: "VXExport", Via: TypeTelegram, Chat: "vod", Text: "🟥 boom"}
+
+	for i := 0; i < 3; i++ {
+		_, err := r.Send(context.Background(), failure, vodChat)
+		require.NoError(t, err)
+	}
+	assert.Equal(t, []int64{7}, sent.telegram)
+
+	// Another failure, and repeated messages that are not failures, are all sent.
+	other := failure
+	other.Text = "🟥 bang"
+	_, _ = r.Send(context.Background(), other, vodChat)
+	message := Notification{Event: notifications.EventMessage, Workflow: "VXExport", Via: TypeTelegram, Chat: "vod", Text: "progress"}
+	_, _ = r.Send(context.Background(), message, vodChat)
+	_, _ = r.Send(context.Background(), message, vodChat)
+	assert.Equal(t, []int64{7, 7, 7, 7}, sent.telegram)
+}
+
+func TestSend_FailedSendIsNotDeduplicated(t *testing.T) {
+	r, _ := newRecordingRouter(t, testConfig)
+	attempts := 0
+	r.senders.telegram = func(msg *telegram.Message) (*telegram.Message, error) {
+		attempts++
+		if attempts == 1 {
+			return nil, errors.New("telegram is down")
+		}
+		return msg, nil
+	}
+	failure := Notification{Event: notifications.EventFailed, Workflow: "Masters", Via: TypeTelegram, Chat: "vod", Text: "🟥 boom"}
+
+	_, err := r.Send(context.Background(), failure, vodChat)
+	require.ErrorContains(t, err, "telegram is down")
+
+	_, err = r.Send(context.Background(), failure, vodChat)
+	require.NoError(t, err)
+	assert.Equal(t, 2, attempts, "the retry must not be taken for a repeat")
+}
+
+func TestSend_RateLimit(t *testing.T) {
+	config := Config{
+		Channels: map[string]Channel{"slack": {Type: TypeSlack, URL: "https://slack.example/hook", PerMinute: 2}},
+		Routes:   []Route{{Channels: []string{"slack"}}},
+	}
+	r, sent := newRecordingRouter(t, config)
+	now := time.Date(2026, 5, 1, 10, 0, 0, 0, time.UTC)
+	r.now = func() time.Time { return now }
+
+	posted := 0
+	r.senders.post = func(context.Context, string, string, any) error {
+		posted++
+		return nil
+	}
+	send := func() {
+		_, err := r.Send(context.Background(), Notification{Event: notifications.EventMessage, Workflow: "VXExport", Via: TypeTelegram, Text: "x"}, vodChat)
+		require.NoError(t, err)
+	}
+
+	for i := 0; i < 5; i++ {
+		send()
+	}
+	assert.Equal(t, 2, posted)
+
+	now = now.Add(time.Minute)
+	send()
+	assert.Equal(t, 3, posted)
+	assert.Empty(t, sent.telegram)
+}
+
+func TestLoadConfig(t *testing.T) {
+	write := func(config any) string {
+		data, err := json.Marshal(config)
+		require.NoError(t, err)
+		file := filepath.Join(t.TempDir(), "routes.json")
+		require.NoError(t, os.WriteFile(file, data, 0o644))
+		return file
+	}
+
+	config, err := LoadConfig(write(testConfig))
+	require.NoError(t, err)
+	assert.Equal(t, testConfig.DedupWindow, config.DedupWindow)
+	assert.Len(t, config.Routes, 3)
+
+	_, err = LoadConfig(write(Config{Routes: []Route{{Name: "typo", Channels: []string{"slak"}}}}))
+	assert.ErrorContains(t, err, `unknown channel "slak"`)
+
+	_, err = LoadConfig(write(Config{Channels: map[string]Channel{"tg": {Type: TypeTelegram}}}))
+	assert.ErrorContains(t, err, "chatId")
+}
@@ -9,6 +9,8 @@ import (
 type File struct {
 	VXID string
 	Name string
+	// QC is a summary of what media QC found in the file, if it was checked.
+	QC string
 }
 
 func renderHtmlTemplate(t *template.Template, data any) (string, error) {
@@ -0,0 +1,126 @@
+package rclone
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Duration reads "10m" and the like from JSON.
+type Duration time.Duration
+
+func (d *Duration) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return fmt.Errorf("duration must be a string like \"10m\": %w", err)
+	}
+	parsed, err := time.ParseDuration(s)
+	if err != nil {
+		return err
+	}
+	*d = Duration(parsed)
+	return nil
+}
+
+func (d Duration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(time.Duration(d).String())
+}
+
+// Bandwidth is bytes per second. It reads from JSON the way rclone's --bwlimit does:
+// "200M" is 200 MiB/s, and a number without a suffix is KiB/s.
+type Bandwidth float64
+
+var bandwidthSuffixes = map[byte]float64{
+	'B': 1,
+	'K': 1 << 10,
+	'M': 1 << 20,
+	'G': 1 << 30,
+	'T': 1 << 40,
+}
+
+func ParseBandwidth(s string) (Bandwidth, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return 0, fmt.Errorf("empty bandwidth")
+	}
+
+	multiplier := float64(1 << 10)
+	if suffix, ok := bandwidthSuffixes[strings.ToUpper(s[len(s)-1:])[0]]; ok {
+		multiplier = suffix
+		s = s[:len(s)-1]
+	}
+
+	value, err := strconv.ParseFloat(s, 64)
+	if err != nil || value < 0 {
+		return 0, fmt.Errorf("bandwidth %q must be a number with an optional B, K, M, G or T suffix", s)
+	}
+	return Bandwidth(value * multiplier), nil
+}
+
+func (b *Bandwidth) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return fmt.Errorf("bandwidth must be a string like \"200M\": %w", err)
+	}
+	parsed, err := ParseBandwidth(s)
+	if err != nil {
+		return err
+	}
+	*b = parsed
+	return nil
+}
+
+func (b Bandwidth) MarshalJSON() ([]byte, error) {
+	return json.Marshal(strconv.FormatFloat(float64(b), 'f', -1, 64) + "B")
+}
+
+// Budget is what the transfers to and from one remote may use together.
+type Budget struct {
+	// Transfers is how many may run at once. 0 is no limit but the scheduler's.
+	Transfers int `json:"transfers,omitempty"`
+	// Bandwidth is how fast they may go together. Transfers running faster than that
+	// are not slowed down, but no new one is started until they are below it. 0 is
+	// no limit.
+	Bandwidth Bandwidth `json:"bandwidth,omitempty"`
+}
+
+// SchedulerConfig is the rclone scheduler file.
+type SchedulerConfig struct {
+	// MaxTransfers is how many transfers rclone runs at once, whatever the remotes.
+	MaxTransfers int `json:"maxTransfers,omitempty"`
+	// Aging is how long a transfer waits before it is moved up a priority. 0 never
+	// moves it.
+	Aging Duration `json:"aging,omitempty"`
+	// Remotes are budgets by rclone remote name, as in paths.Drive.RcloneName:
+	// isilon, s3prod, bmms3, lucid, brunstad.
+	Remotes map[string]Budget `json:"remotes,omitempty"`
+}
+
+// LoadSchedulerConfig reads a scheduler file. Limits it leaves out are the defaults.
+func LoadSchedulerConfig(file string) (SchedulerConfig, error) {
+	config := SchedulerConfig{
+		MaxTransfers: maxConcurrentTransfers,
+		Aging:        Duration(defaultAging),
+	}
+
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return config, err
+	}
+	if err := json.Unmarshal(data, &config); err != nil {
+		return config, fmt.Errorf("%s: %w", file, err)
+	}
+
+	if config.MaxTransfers <= 0 {
+		return config, fmt.Errorf("%s: maxTransfers must be positive", file)
+	}
+	for remote, budget := range config.Remotes {
+		if budget.Transfers < 0 {
+			return config, fmt.Errorf("%s: remote %q: transfers must not be negative", file, remote)
+		}
+	}
+	return config, nil
+}
@@ -0,0 +1,84 @@
+package rclone
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+type hashsumRequest struct {
+	Async    bool   `json:"_async"`
+	Fs       string `json:"fs"`
+	HashType string `json:"hashType"`
+	// Download makes rclone read the file to hash it, rather than trusting what the
+	// remote says, which for S3 is an ETag that is no MD5 for multipart uploads.
+	Download bool `json:"download"`
+}
+
+// StartHashsum starts a job hashing a single file, given as rclone names it, such as
+// "s3prod:vod-asset-ingest-prod/path/file.mxf". rclone downloads the file to hash it.
+func StartHashsum(ctx context.Context, file, hashType string) (*JobResponse, error) {
+	body, err := json.Marshal(hashsumRequest{
+		Async:    true,
+		Fs:       file,
+		HashType: hashType,
+		Download: true,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, baseUrl+"/operations/hashsum", bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+
+	return doRequest[JobResponse](req)
+}
+
+type hashsumStatus struct {
+	Finished bool   `json:"finished"`
+	Success  bool   `json:"success"`
+	Error    string `json:"error"`
+	Output   struct {
+		Hashsum []string `json:"hashsum"`
+	} `json:"output"`
+}
+
+// ErrHashsumRunning is returned by HashsumResult while the job is still hashing.
+var ErrHashsumRunning = errors.New("hashsum still running")
+
+// HashsumResult is the hash of the file a StartHashsum job hashed.
+func HashsumResult(ctx context.Context, jobID int) (string, error) {
+	body, err := json.Marshal(JobStatusRequest{JobID: jobID})
+	if err != nil {
+		return "", err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, baseUrl+"/job/status", bytes.NewReader(body))
+	if err != nil {
+		return "", err
+	}
+
+	status, err := doRequest[hashsumStatus](req)
+	if err != nil {
+		return "", err
+	}
+	if !status.Finished {
+		return "", ErrHashsumRunning
+	}
+	if !status.Success {
+		return "", fmt.Errorf("hashsum job %d failed: %s", jobID, status.Error)
+	}
+	if len(status.Output.Hashsum) != 1 {
+		return "", fmt.Errorf("hashsum job %d hashed %d files, expected 1", jobID, len(status.Output.Hashsum))
+	}
+
+	// Each line is as md5sum writes it: the hash, two spaces and the file.
+	hash, _, _ := strings.Cut(status.Output.Hashsum[0], " ")
+	return hash, nil
+}
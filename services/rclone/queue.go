@@ -2,89 +2,389 @@ package rclone
 
 import (
 	"context"
+	"sort"
+	"strconv"
+	"strings"
 	"sync"
 	"time"
+
+	"github.com/bcc-code/bcc-media-flows/analytics"
 )
 
 const maxConcurrentTransfers = 5
 
-var queueLock = sync.Mutex{}
-var transferQueue = map[Priority][]chan bool{}
+// defaultAging is how long a transfer waits before it is moved up a priority.
+const defaultAging = 10 * time.Minute
+
+// grantedJobGrace is how long a job the scheduler let start counts for its workflow
+// before rclone reports it transferring. After that, a job rclone does not report is
+// taken to be done.
+const grantedJobGrace = time.Minute
+
+// ticket is one transfer waiting for a slot.
+type ticket struct {
+	id       uint64
+	workflow string
+	remotes  []string
+	priority Priority
+	queued   time.Time
+	grant    chan struct{}
+}
+
+type grantedJob struct {
+	workflow string
+	started  time.Time
+}
+
+// Scheduler hands out transfer slots. A transfer waits until rclone is running fewer
+// than the configured number of transfers, and fewer than the budgets of the remotes
+// it reads and writes allow. Of the transfers that may start, the one with the highest
+// priority goes first, then the one whose workflow has the fewest transfers running,
+// then the one that has waited the longest. A transfer is moved up a priority for
+// every Aging it waits, so low priority transfers are not starved by a steady stream
+// of high priority ones.
+type Scheduler struct {
+	now func() time.Time
 
-func init() {
-	for _, priority := range Priorities.Members() {
-		transferQueue[priority] = []chan bool{}
+	lock    sync.Mutex
+	config  SchedulerConfig
+	waiting []*ticket
+	nextID  uint64
+	jobs    map[int]grantedJob
+}
+
+func NewScheduler(config SchedulerConfig) *Scheduler {
+	return &Scheduler{
+		now:    time.Now,
+		config: config,
+		jobs:   map[int]grantedJob{},
 	}
 }
 
-func waitForTransferSlot(ctx context.Context, priority Priority, timeout time.Duration) error {
-	// Create an unbuffered channel
-	ch := make(chan bool)
+var scheduler = NewScheduler(SchedulerConfig{
+	MaxTransfers: maxConcurrentTransfers,
+	Aging:        Duration(defaultAging),
+})
+
+// ConfigureScheduler replaces the limits of the scheduler the transfers in this
+// package wait for. Transfers already waiting keep their place.
+func ConfigureScheduler(config SchedulerConfig) {
+	if config.MaxTransfers <= 0 {
+		config.MaxTransfers = maxConcurrentTransfers
+	}
+	scheduler.lock.Lock()
+	defer scheduler.lock.Unlock()
+	scheduler.config = config
+}
+
+// DefaultScheduler is the scheduler the transfers in this package wait for.
+func DefaultScheduler() *Scheduler {
+	return scheduler
+}
 
-	queueLock.Lock()
-	transferQueue[priority] = append(transferQueue[priority], ch)
-	queueLock.Unlock()
+type workflowKey struct{}
 
+// WithWorkflow marks the transfers started with ctx as the workflow's, which is what
+// the scheduler shares slots between and what Reprioritize finds them by.
+func WithWorkflow(ctx context.Context, workflowID string) context.Context {
+	return context.WithValue(ctx, workflowKey{}, workflowID)
+}
+
+func workflowOf(ctx context.Context) string {
+	id, _ := ctx.Value(workflowKey{}).(string)
+	return id
+}
+
+// remoteOf is the rclone remote of an fs like "isilon:isilon", or "" for a local path.
+func remoteOf(fs string) string {
+	remote, _, found := strings.Cut(fs, ":")
+	if !found {
+		return ""
+	}
+	return remote
+}
+
+func remotesOf(fss ...string) []string {
+	var remotes []string
+	for _, fs := range fss {
+		remote := remoteOf(fs)
+		if remote == "" {
+			continue
+		}
+		if len(remotes) == 0 || remotes[0] != remote {
+			remotes = append(remotes, remote)
+		}
+	}
+	return remotes
+}
+
+func waitForTransferSlot(ctx context.Context, priority Priority, remotes []string, timeout time.Duration) error {
+	return scheduler.wait(ctx, priority, remotes, timeout)
+}
+
+func (s *Scheduler) wait(ctx context.Context, priority Priority, remotes []string, timeout time.Duration) error {
+	s.lock.Lock()
+	s.nextID++
+	t := &ticket{
+		id:       s.nextID,
+		workflow: workflowOf(ctx),
+		remotes:  remotes,
+		priority: priority,
+		queued:   s.now(),
+		grant:    make(chan struct{}, 1),
+	}
+	s.waiting = append(s.waiting, t)
+	s.lock.Unlock()
+
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+
+	var err error
 	select {
-	case <-ch:
-		break
+	case <-t.grant:
+		return nil
 	case <-ctx.Done():
-		return ctx.Err()
-	case <-time.After(timeout):
-		return errTimeout
+		err = ctx.Err()
+	case <-timer.C:
+		err = errTimeout
 	}
 
-	return nil
+	// A transfer that was granted its slot while giving up just leaves it unused; the
+	// next round counts what rclone is actually running.
+	s.remove(t)
+	return err
 }
 
-func StartFileTransferQueue() {
-	for {
-		checkFileTransferQueue()
-		time.Sleep(time.Second * 5)
+func (s *Scheduler) remove(t *ticket) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	for i, waiting := range s.waiting {
+		if waiting == t {
+			s.waiting = append(s.waiting[:i], s.waiting[i+1:]...)
+			return
+		}
 	}
 }
 
-func checkFileTransferQueue() {
-	stats, err := GetRcloneStatus(context.Background())
-	if err != nil {
+// started records the rclone job a granted transfer became, so the transfers running
+// can be counted per workflow.
+func (s *Scheduler) started(jobID int, workflow string) {
+	if workflow == "" {
 		return
 	}
 
-	count := len(stats.Transferring)
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.jobs[jobID] = grantedJob{workflow: workflow, started: s.now()}
+}
 
-	if count >= maxConcurrentTransfers {
-		return
+// Reprioritize changes the priority of the transfers the workflow has waiting, and
+// returns how many it changed.
+func (s *Scheduler) Reprioritize(workflowID string, priority Priority) int {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	changed := 0
+	for _, t := range s.waiting {
+		if t.workflow == workflowID {
+			t.priority = priority
+			changed++
+		}
 	}
+	return changed
+}
 
-	queueLock.Lock()
-	defer queueLock.Unlock()
+// rank is the index of the priority in Priorities; one it does not know is the lowest.
+func rank(priority Priority) int {
+	members := Priorities.Members()
+	for i, p := range members {
+		if p == priority {
+			return i
+		}
+	}
+	return len(members) - 1
+}
 
-	for _, priority := range Priorities.Members() {
-		started := 0
-		for _, ch := range transferQueue[priority] {
+// effectiveRank is the rank of the priority the transfer has after aging; 0 is the
+// highest.
+func (s *Scheduler) effectiveRank(t *ticket, now time.Time) int {
+	r := rank(t.priority)
+	if s.config.Aging > 0 {
+		r -= int(now.Sub(t.queued) / time.Duration(s.config.Aging))
+	}
+	return max(r, 0)
+}
 
-			// This is a non-blocking send, so if the channel is full, we can skip it.
-			// It basically means that the other side is not listening and we can move on.
-			// this approach works because we're using an unbuffered channel
-			select {
-			case ch <- true:
-				count++
-				started++
-			default:
-			}
+// usage is what rclone is running, counted the ways the budgets are.
+type usage struct {
+	total     int
+	remotes   map[string]int
+	speeds    map[string]float64
+	workflows map[string]int
+}
 
-			if count >= maxConcurrentTransfers {
-				// If we've reached the maximum number of concurrent transfers, then we can stop processing the queue
-				// and remove the items that we've already started
-				transferQueue[priority] = transferQueue[priority][started:]
-				return
+func (s *Scheduler) usage(stats *CoreStats, now time.Time) usage {
+	u := usage{
+		total:     len(stats.Transferring),
+		remotes:   map[string]int{},
+		speeds:    map[string]float64{},
+		workflows: map[string]int{},
+	}
+
+	seen := map[int]bool{}
+	for _, transfer := range stats.Transferring {
+		for _, remote := range remotesOf(transfer.SrcFs, transfer.DstFs) {
+			u.remotes[remote]++
+			u.speeds[remote] += transfer.Speed
+		}
+
+		id, err := strconv.Atoi(strings.TrimPrefix(transfer.Group, "job/"))
+		if err != nil {
+			continue
+		}
+		seen[id] = true
+		if job, ok := s.jobs[id]; ok {
+			u.workflows[job.workflow]++
+		}
+	}
+
+	for id, job := range s.jobs {
+		if seen[id] {
+			continue
+		}
+		if now.Sub(job.started) > grantedJobGrace {
+			delete(s.jobs, id)
+		} else {
+			u.workflows[job.workflow]++
+		}
+	}
+
+	return u
+}
+
+// fits reports whether the transfer can start without going over a budget of the
+// remotes it uses.
+func (s *Scheduler) fits(t *ticket, u usage) bool {
+	for _, remote := range t.remotes {
+		budget, ok := s.config.Remotes[remote]
+		if !ok {
+			continue
+		}
+		if budget.Transfers > 0 && u.remotes[remote] >= budget.Transfers {
+			return false
+		}
+		if budget.Bandwidth > 0 && u.speeds[remote] >= float64(budget.Bandwidth) {
+			return false
+		}
+	}
+	return true
+}
+
+// schedule starts as many of the waiting transfers as the budgets allow.
+func (s *Scheduler) schedule(stats *CoreStats) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	now := s.now()
+	u := s.usage(stats, now)
+
+	for u.total < s.config.MaxTransfers {
+		var next *ticket
+		index := -1
+		for i, t := range s.waiting {
+			if !s.fits(t, u) {
+				continue
+			}
+			if next == nil || s.before(t, next, u, now) {
+				next, index = t, i
 			}
 		}
+		if next == nil {
+			return
+		}
+
+		s.waiting = append(s.waiting[:index], s.waiting[index+1:]...)
+		next.grant <- struct{}{}
+
+		u.total++
+		for _, remote := range next.remotes {
+			u.remotes[remote]++
+		}
+		if next.workflow != "" {
+			u.workflows[next.workflow]++
+		}
+	}
+}
+
+// before reports whether a goes before b.
+func (s *Scheduler) before(a, b *ticket, u usage, now time.Time) bool {
+	if ra, rb := s.effectiveRank(a, now), s.effectiveRank(b, now); ra != rb {
+		return ra < rb
+	}
+	if wa, wb := u.workflows[a.workflow], u.workflows[b.workflow]; wa != wb {
+		return wa < wb
+	}
+	return a.id < b.id
+}
 
-		if started > 0 {
-			// If we get to here, then we've exhausted the queue for this priority and can replace it with an empty slice
-			transferQueue[priority] = []chan bool{}
+// QueuedTransfer is a transfer waiting for a slot.
+type QueuedTransfer struct {
+	WorkflowID string   `json:"workflowId,omitempty"`
+	Remotes    []string `json:"remotes"`
+	Priority   string   `json:"priority"`
+	// EffectivePriority is the priority after aging, which is what it is scheduled by.
+	EffectivePriority string    `json:"effectivePriority"`
+	QueuedAt          time.Time `json:"queuedAt"`
+}
+
+// Queue lists the transfers waiting, highest effective priority first.
+func (s *Scheduler) Queue() []QueuedTransfer {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	now := s.now()
+	waiting := append([]*ticket(nil), s.waiting...)
+	sort.SliceStable(waiting, func(i, j int) bool {
+		return s.effectiveRank(waiting[i], now) < s.effectiveRank(waiting[j], now)
+	})
+
+	queue := make([]QueuedTransfer, 0, len(waiting))
+	for _, t := range waiting {
+		remotes := t.remotes
+		if remotes == nil {
+			remotes = []string{}
 		}
+		queue = append(queue, QueuedTransfer{
+			WorkflowID:        t.workflow,
+			Remotes:           remotes,
+			Priority:          t.priority.Value,
+			EffectivePriority: Priorities.Members()[s.effectiveRank(t, now)].Value,
+			QueuedAt:          t.queued,
+		})
+	}
+	return queue
+}
+
+func StartFileTransferQueue() {
+	for {
+		checkFileTransferQueue()
+		time.Sleep(time.Second * 5)
 	}
+}
+
+func checkFileTransferQueue() {
+	stats, err := GetRcloneStatus(context.Background())
+	if err != nil {
+		return
+	}
+
+	analytics.GetService().RcloneStats(analytics.RcloneStats{
+		Bytes:        stats.Bytes,
+		Speed:        stats.Speed,
+		Transferring: len(stats.Transferring),
+		Errors:       stats.Errors,
+	})
 
+	scheduler.schedule(stats)
 }
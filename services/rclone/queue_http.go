@@ -0,0 +1,66 @@
+package rclone
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"net/http"
+)
+
+type reprioritizeRequest struct {
+	WorkflowID string `json:"workflowId"`
+	Priority   string `json:"priority"`
+}
+
+func writeJSON(w http.ResponseWriter, status int, body any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(body)
+}
+
+// QueueHandler serves the scheduler's queue. GET lists the transfers waiting; POST
+// {"workflowId": ..., "priority": "high"} changes the priority of the ones a workflow
+// has waiting, with the key in an "api-key" header.
+//
+// The queue is the one of the worker serving it: a POST does not reach transfers
+// waiting on other workers. An empty key refuses every POST rather than allowing it.
+func (s *Scheduler) QueueHandler(key string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			writeJSON(w, http.StatusOK, s.Queue())
+
+		case http.MethodPost:
+			if key == "" {
+				writeJSON(w, http.StatusServiceUnavailable, map[string]string{"error": "reprioritizing is disabled: no key is configured"})
+				return
+			}
+			// Constant time, so the response time does not report how much of the key was right.
+			if subtle.ConstantTimeCompare([]byte(r.Header.Get("api-key")), []byte(key)) != 1 {
+				writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "invalid api-key"})
+				return
+			}
+
+			var req reprioritizeRequest
+			if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+				writeJSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+				return
+			}
+			if req.WorkflowID == "" {
+				writeJSON(w, http.StatusBadRequest, map[string]string{"error": "workflowId is required"})
+				return
+			}
+			priority := Priorities.Parse(req.Priority)
+			if priority == nil {
+				writeJSON(w, http.StatusBadRequest, map[string]string{"error": "priority must be one of high, normal, low"})
+				return
+			}
+
+			changed := s.Reprioritize(req.WorkflowID, *priority)
+			writeJSON(w, http.StatusOK, map[string]int{"reprioritized": changed})
+
+		default:
+			w.Header().Set("Allow", "GET, POST")
+			writeJSON(w, http.StatusMethodNotAllowed, map[string]string{"error": "method not allowed"})
+		}
+	})
+}
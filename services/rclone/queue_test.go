@@ -0,0 +1,269 @@
+package rclone
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var schedulerStart = time.Date(2026, 5, 1, 10, 0, 0, 0, time.UTC)
+
+func newTestScheduler(t *testing.T, config SchedulerConfig) (*Scheduler, *time.Time) {
+	t.Helper()
+
+	now := schedulerStart
+	s := NewScheduler(config)
+	s.now = func() time.Time { return now }
+	return s, &now
+}
+
+// enqueue adds a waiting transfer and returns the channel its wait ends on. Transfers
+// are enqueued one at a time, so they are queued in the order of the calls.
+func enqueue(t *testing.T, s *Scheduler, workflow string, priority Priority, remotes ...string) chan error {
+	t.Helper()
+
+	s.lock.Lock()
+	before := len(s.waiting)
+	s.lock.Unlock()
+
+	ctx, cancel := context.WithCancel(WithWorkflow(context.Background(), workflow))
+	t.Cleanup(cancel)
+
+	done := make(chan error, 1)
+	go func() { done <- s.wait(ctx, priority, remotes, time.Hour) }()
+
+	require.Eventually(t, func() bool {
+		s.lock.Lock()
+		defer s.lock.Unlock()
+		return len(s.waiting) > before
+	}, time.Second, time.Millisecond)
+	return done
+}
+
+func granted(done chan error) bool {
+	select {
+	case err := <-done:
+		return err == nil
+	case <-time.After(50 * time.Millisecond):
+		return false
+	}
+}
+
+func transferring(job int, src, dst string, speed float64) Transferring {
+	return Transferring{Group: "job/" + strconv.Itoa(job), SrcFs: src, DstFs: dst, Speed: speed}
+}
+
+func TestSchedule_HighestPriorityFirst(t *testing.T) {
+	s, _ := newTestScheduler(t, SchedulerConfig{MaxTransfers: 1})
+	low := enqueue(t, s, "a", PriorityLow)
+	normal := enqueue(t, s, "b", PriorityNormal)
+	high := enqueue(t, s, "c", PriorityHigh)
+
+	s.schedule(&CoreStats{})
+	assert.True(t, granted(high))
+	assert.False(t, granted(normal))
+
+	s.schedule(&CoreStats{})
+	assert.True(t, granted(normal))
+	assert.False(t, granted(low))
+}
+
+func TestSchedule_GlobalLimitCountsWhatRcloneRuns(t *testing.T) {
+	s, _ := newTestScheduler(t, SchedulerConfig{MaxTransfers: 2})
+	first := enqueue(t, s, "a", PriorityHigh)
+	second := enqueue(t, s, "a", PriorityHigh)
+
+	s.schedule(&CoreStats{Transferring: []Transferring{transferring(1, "isilon:isilon", "s3prod:bucket", 0)}})
+
+	assert.True(t, granted(first))
+	assert.False(t, granted(second))
+}
+
+// Large BMM exports must not hold up copies to Isilon: a remote at its budget only
+// holds up the transfers that use it.
+func TestSchedule_RemoteTransferBudget(t *testing.T) {
+	s, _ := newTestScheduler(t, SchedulerConfig{
+		MaxTransfers: 5,
+		Remotes:      map[string]Budget{"bmms3": {Transfers: 1}},
+	})
+	export := enqueue(t, s, "export", PriorityHigh, "isilon", "bmms3")
+	ingest := enqueue(t, s, "ingest", PriorityLow, "lucid", "isilon")
+
+	s.schedule(&CoreStats{Transferring: []Transferring{transferring(1, "isilon:isilon", "bmms3:bucket", 0)}})
+
+	assert.False(t, granted(export))
+	assert.True(t, granted(ingest))
+
+	s.schedule(&CoreStats{})
+	assert.True(t, granted(export))
+}
+
+func TestSchedule_RemoteBandwidthBudget(t *testing.T) {
+	s, _ := newTestScheduler(t, SchedulerConfig{
+		MaxTransfers: 5,
+		Remotes:      map[string]Budget{"s3prod": {Bandwidth: 100 << 20}},
+	})
+	upload := enqueue(t, s, "a", PriorityHigh, "isilon", "s3prod")
+
+	s.schedule(&CoreStats{Transferring: []Transferring{transferring(1, "isilon:isilon", "s3prod:bucket", 150<<20)}})
+	assert.False(t, granted(upload))
+
+	s.schedule(&CoreStats{Transferring: []Transferring{transferring(1, "isilon:isilon", "s3prod:bucket", 50<<20)}})
+	assert.True(t, granted(upload))
+}
+
+func TestSchedule_SharesSlotsBetweenWorkflows(t *testing.T) {
+	s, _ := newTestScheduler(t, SchedulerConfig{MaxTransfers: 3})
+	s.started(1, "busy")
+	s.started(2, "busy")
+	busy := enqueue(t, s, "busy", PriorityNormal)
+	idle := enqueue(t, s, "idle", PriorityNormal)
+
+	s.schedule(&CoreStats{Transferring: []Transferring{
+		transferring(1, "isilon:isilon", "s3prod:bucket", 0),
+		transferring(2, "isilon:isilon", "s3prod:bucket", 0),
+	}})
+
+	assert.True(t, granted(idle))
+	assert.False(t, granted(busy))
+}
+
+// A job the scheduler let start counts for its workflow until rclone reports it, and
+// not after rclone has stopped reporting it for a while.
+func TestSchedule_GrantedJobsCountUntilTheyAreDone(t *testing.T) {
+	s, now := newTestScheduler(t, SchedulerConfig{MaxTransfers: 5})
+	s.started(1, "busy")
+
+	u := s.usage(&CoreStats{}, *now)
+	assert.Equal(t, 1, u.workflows["busy"])
+
+	*now = now.Add(2 * grantedJobGrace)
+	u = s.usage(&CoreStats{}, *now)
+	assert.Zero(t, u.workflows["busy"])
+	assert.Empty(t, s.jobs)
+}
+
+func TestSchedule_AgingKeepsLowPriorityFromStarving(t *testing.T) {
+	s, now := newTestScheduler(t, SchedulerConfig{MaxTransfers: 1, Aging: Duration(10 * time.Minute)})
+	low := enqueue(t, s, "a", PriorityLow)
+
+	*now = now.Add(20 * time.Minute)
+	high := enqueue(t, s, "b", PriorityHigh)
+	assert.Equal(t, "high", s.Queue()[0].EffectivePriority)
+
+	s.schedule(&CoreStats{})
+	assert.True(t, granted(low), "a low priority transfer that has waited two agings is high, and first")
+	assert.False(t, granted(high))
+}
+
+func TestWait_GivingUpLeavesTheQueue(t *testing.T) {
+	s, _ := newTestScheduler(t, SchedulerConfig{MaxTransfers: 1})
+	ctx, cancel := context.WithCancel(context.Background())
+
+	done := make(chan error, 1)
+	go func() { done <- s.wait(ctx, PriorityNormal, nil, time.Hour) }()
+	require.Eventually(t, func() bool { return len(s.Queue()) == 1 }, time.Second, time.Millisecond)
+
+	cancel()
+	assert.ErrorIs(t, <-done, context.Canceled)
+	assert.Empty(t, s.Queue())
+}
+
+func TestQueueHandler_Reprioritizes(t *testing.T) {
+	s, _ := newTestScheduler(t, SchedulerConfig{MaxTransfers: 1})
+	export := enqueue(t, s, "export", PriorityNormal, "bmms3")
+	live := enqueue(t, s, "live", PriorityLow, "isilon")
+	handler := s.QueueHandler("s3cret")
+	post := func(body, key string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/rclone/queue", strings.NewReader(body))
+		req.Header.Set("api-key", key)
+		res := httptest.NewRecorder()
+		handler.ServeHTTP(res, req)
+		return res
+	}
+
+	res := post(`{"workflowId":"live","priority":"high"}`, "wrong")
+	assert.Equal(t, http.StatusUnauthorized, res.Code)
+
+	res = post(`{"workflowId":"live","priority":"high"}`, "s3cret")
+	assert.Equal(t, http.StatusOK, res.Code)
+	assert.JSONEq(t, `{"reprioritized":1}`, res.Body.String())
+
+	res = httptest.NewRecorder()
+	handler.ServeHTTP(res, httptest.NewRequest(http.MethodGet, "/rclone/queue", nil))
+	assert.Equal(t, http.StatusOK, res.Code)
+	assert.Contains(t, res.Body.String(), `"workflowId":"live","remotes":["isilon"],"priority":"high"`)
+
+	s.schedule(&CoreStats{})
+	assert.True(t, granted(live))
+	assert.False(t, granted(export))
+
+	res = post(`{"workflowId":"export","priority":"urgent"}`, "s3cret")
+	assert.Equal(t, http.StatusBadRequest, res.Code)
+}
+
+// Without a key the queue can be read, and not changed.
+func TestQueueHandler_NoKey(t *testing.T) {
+	s, _ := newTestScheduler(t, SchedulerConfig{MaxTransfers: 1})
+	handler := s.QueueHandler("")
+
+	res := httptest.NewRecorder()
+	handler.ServeHTTP(res, httptest.NewRequest(http.MethodPost, "/rclone/queue", strings.NewReader(`{"workflowId":"live","priority":"high"}`)))
+	assert.Equal(t, http.StatusServiceUnavailable, res.Code)
+
+	res = httptest.NewRecorder()
+	handler.ServeHTTP(res, httptest.NewRequest(http.MethodGet, "/rclone/queue", nil))
+	assert.Equal(t, http.StatusOK, res.Code)
+}
+
+func TestRemotesOf(t *testing.T) {
+	assert.Equal(t, []string{"isilon", "s3prod"}, remotesOf("isilon:isilon", "s3prod:/massiveio-bccm/"))
+	assert.Equal(t, []string{"isilon"}, remotesOf("isilon:isilon", "isilon:filecatalyst"))
+	assert.Equal(t, []string{"lucid"}, remotesOf("/mnt/temp", "lucid:lucidlink"))
+}
+
+func TestLoadSchedulerConfig(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "scheduler.json")
+	require.NoError(t, os.WriteFile(file, []byte(`{
+		"remotes": {
+			"isilon": {"transfers": 4},
+			"bmms3": {"transfers": 2, "bandwidth": "100M"}
+		}
+	}`), 0o644))
+
+	config, err := LoadSchedulerConfig(file)
+	require.NoError(t, err)
+	assert.Equal(t, maxConcurrentTransfers, config.MaxTransfers, "left out is the default")
+	assert.Equal(t, Duration(defaultAging), config.Aging)
+	assert.Equal(t, Budget{Transfers: 2, Bandwidth: 100 << 20}, config.Remotes["bmms3"])
+
+	require.NoError(t, os.WriteFile(file, []byte(`{"remotes": {"isilon": {"bandwidth": "fast"}}}`), 0o644))
+	_, err = LoadSchedulerConfig(file)
+	assert.ErrorContains(t, err, "bandwidth")
+}
+
+func TestParseBandwidth(t *testing.T) {
+	for in, want := range map[string]Bandwidth{
+		"100M": 100 << 20,
+		"1.5G": 1.5 * (1 << 30),
+		"512k": 512 << 10,
+		"10":   10 << 10,
+		"300B": 300,
+	} {
+		got, err := ParseBandwidth(in)
+		require.NoError(t, err, in)
+		assert.Equal(t, want, got, in)
+	}
+
+	_, err := ParseBandwidth("-1M")
+	assert.Error(t, err)
+}
@@ -50,16 +50,34 @@ func TestClient_HasATimeout(t *testing.T) {
 		"every file copy in the tree goes through this client")
 }
 
-// CopyDir rather than CopyFile: the per-file calls wait for a slot from the transfer
-// queue, which only the worker's StartFileTransferQueue goroutine grants.
+// grantSlots stands in for the worker's StartFileTransferQueue goroutine, which is what
+// grants the transfer slots CopyDir, CopyFile and MoveFile wait for, with rclone idle.
+func grantSlots(t *testing.T) {
+	t.Helper()
+
+	done := make(chan struct{})
+	t.Cleanup(func() { close(done) })
+	go func() {
+		for {
+			select {
+			case <-done:
+				return
+			case <-time.After(time.Millisecond):
+				scheduler.schedule(&CoreStats{})
+			}
+		}
+	}()
+}
+
 func TestCopyDir_SubmitsAnAsyncJobAndReturnsItsID(t *testing.T) {
+	grantSlots(t)
 	bodies := rcloneServer(t, func(w http.ResponseWriter, r *http.Request) {
 		assert.Equal(t, "/sync/copy", r.URL.Path)
 		w.Header().Set("Content-Type", "application/json")
 		_, _ = w.Write([]byte(`{"jobid":42}`))
 	})
 
-	res, err := CopyDir(context.Background(), "src:/dir", "dst:/dir")
+	res, err := CopyDir(context.Background(), "src:/dir", "dst:/dir", PriorityNormal)
 
 	require.NoError(t, err)
 	require.NotNil(t, res)
@@ -98,12 +116,13 @@ func TestCopyFile_CancellationEndsTheQueueWait(t *testing.T) {
 // A failed submit must not read as a job that started: the caller polls the id it gets
 // back, and a zero id is a job rclone knows nothing about.
 func TestCopyDir_ServerErrorIsAnError(t *testing.T) {
+	grantSlots(t)
 	rcloneServer(t, func(w http.ResponseWriter, _ *http.Request) {
 		w.WriteHeader(http.StatusInternalServerError)
 		_, _ = w.Write([]byte(`{"error":"directory not found"}`))
 	})
 
-	res, err := CopyDir(context.Background(), "src:/dir", "dst:/dir")
+	res, err := CopyDir(context.Background(), "src:/dir", "dst:/dir", PriorityNormal)
 
 	require.Error(t, err)
 	assert.Nil(t, res)
@@ -224,13 +243,43 @@ func TestListFiles_ReturnsTheListing(t *testing.T) {
 	assert.Len(t, files, 2)
 }
 
+func TestHashsum_DownloadsTheFileAndReadsTheHash(t *testing.T) {
+	finished := false
+	bodies := rcloneServer(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/operations/hashsum":
+			_, _ = w.Write([]byte(`{"jobid":7}`))
+		case "/job/status":
+			if !finished {
+				_, _ = w.Write([]byte(`{"id":7,"finished":false}`))
+				return
+			}
+			_, _ = w.Write([]byte(`{"id":7,"finished":true,"success":true,"output":{"hashType":"md5","hashsum":["5d41402abc4b2a76b9719d911017c592  a.mxf"]}}`))
+		}
+	})
+
+	job, err := StartHashsum(context.Background(), "s3prod:bucket/a.mxf", "md5")
+	require.NoError(t, err)
+	assert.Equal(t, 7, job.JobID)
+	assert.Contains(t, (*bodies)[0], `"download":true`)
+
+	_, err = HashsumResult(context.Background(), job.JobID)
+	assert.ErrorIs(t, err, ErrHashsumRunning)
+
+	finished = true
+	hash, err := HashsumResult(context.Background(), job.JobID)
+	require.NoError(t, err)
+	assert.Equal(t, "5d41402abc4b2a76b9719d911017c592", hash)
+}
+
 // The transfer queue is not an HTTP wait, but it is the longest one in this package —
 // an hour — and a cancelled activity should not sit in it.
 func TestWaitForTransferSlot_RespectsCancellation(t *testing.T) {
 	ctx, cancel := context.WithCancel(context.Background())
 	cancel()
 
-	err := waitForTransferSlot(ctx, PriorityLow, time.Hour)
+	err := waitForTransferSlot(ctx, PriorityLow, nil, time.Hour)
 
 	require.Error(t, err)
 	assert.ErrorIs(t, err, context.Canceled)
@@ -36,7 +36,7 @@ type copyRequest struct {
 	Destination string `json:"dstFs"`
 }
 
-func CopyDir(ctx context.Context, source, destination string) (*JobResponse, error) {
+func CopyDir(ctx context.Context, source, destination string, priority Priority) (*JobResponse, error) {
 	body, err := json.Marshal(copyRequest{
 		Async:       true,
 		Source:      source,
@@ -46,12 +46,27 @@ func CopyDir(ctx context.Context, source, destination string) (*JobResponse, err
 		return nil, err
 	}
 
+	err = waitForTransferSlot(ctx, priority, remotesOf(source, destination), time.Hour)
+	if err != nil {
+		return nil, err
+	}
+
 	req, err := http.NewRequestWithContext(ctx, http.MethodPost, baseUrl+"/sync/copy", bytes.NewReader(body))
 	if err != nil {
 		return nil, err
 	}
 
-	return doRequest[JobResponse](req)
+	return startJob(ctx, req)
+}
+
+// startJob submits a transfer that was granted a slot, and tells the scheduler which
+// job it became.
+func startJob(ctx context.Context, req *http.Request) (*JobResponse, error) {
+	res, err := doRequest[JobResponse](req)
+	if err == nil && res != nil {
+		scheduler.started(res.JobID, workflowOf(ctx))
+	}
+	return res, err
 }
 
 type fileRequest struct {
@@ -74,7 +89,7 @@ func MoveFile(ctx context.Context, sourceRemote, sourcePath, destinationRemote,
 		return nil, err
 	}
 
-	err = waitForTransferSlot(ctx, priority, time.Hour)
+	err = waitForTransferSlot(ctx, priority, remotesOf(sourceRemote, destinationRemote), time.Hour)
 	if err != nil {
 		return nil, err
 	}
@@ -84,7 +99,7 @@ func MoveFile(ctx context.Context, sourceRemote, sourcePath, destinationRemote,
 		return nil, err
 	}
 
-	return doRequest[JobResponse](req)
+	return startJob(ctx, req)
 }
 
 func CopyFile(ctx context.Context, sourceRemote, sourcePath, destinationRemote, destinationPath string, priority Priority) (*JobResponse, error) {
@@ -100,7 +115,7 @@ func CopyFile(ctx context.Context, sourceRemote, sourcePath, destinationRemote,
 		return nil, err
 	}
 
-	err = waitForTransferSlot(ctx, priority, time.Hour)
+	err = waitForTransferSlot(ctx, priority, remotesOf(sourceRemote, destinationRemote), time.Hour)
 	if err != nil {
 		return nil, err
 	}
@@ -110,5 +125,5 @@ func CopyFile(ctx context.Context, sourceRemote, sourcePath, destinationRemote,
 		return nil, err
 	}
 
-	return doRequest[JobResponse](req)
+	return startJob(ctx, req)
 }
@@ -0,0 +1,172 @@
+// Package s3 uploads files to S3 and S3-compatible stores directly, without the rclone
+// daemon. Uploads are multipart, and an upload that was interrupted can be resumed
+// from the parts S3 already has.
+package s3
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	v4 "github.com/aws/aws-sdk-go-v2/aws/signer/v4"
+	"github.com/go-resty/resty/v2"
+
+	"github.com/bcc-code/bcc-media-flows/internal/httpx"
+)
+
+const serviceName = "s3"
+
+// requestTimeout bounds one request, the longest of which is a part upload.
+const requestTimeout = 10 * time.Minute
+
+// emptyPayloadHash is the SHA-256 of nothing, which signs requests without a body.
+const emptyPayloadHash = "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"
+
+var (
+	// ErrNoSuchUpload is an upload S3 no longer has: completed, aborted or expired.
+	ErrNoSuchUpload = errors.New("no such upload")
+	// ErrBadDigest is a part S3 received other than it was sent.
+	ErrBadDigest = errors.New("part does not match its checksum")
+)
+
+type Config interface {
+	Endpoint() string
+	Region() string
+	AccessKeyID() string
+	SecretAccessKey() string
+}
+
+type Client struct {
+	endpoint    string
+	region      string
+	credentials aws.Credentials
+	signer      *v4.Signer
+	now         func() time.Time
+	restyClient *resty.Client
+	// partSize replaces PartSize, for tests that need several parts of a small file.
+	partSize int64
+}
+
+type s3Error struct {
+	Code    string `xml:"Code"`
+	Message string `xml:"Message"`
+}
+
+// s3ErrorFromResponse names the S3 error code, and carries the ones callers act on.
+func s3ErrorFromResponse(resp *resty.Response) error {
+	var body s3Error
+	if err := xml.Unmarshal(resp.Body(), &body); err != nil || body.Code == "" {
+		return httpx.Describe(serviceName, resp)
+	}
+
+	described := httpx.DescribeWithDetail(serviceName, resp, body.Code+": "+body.Message).(*httpx.StatusError)
+	switch body.Code {
+	case "NoSuchUpload":
+		described.Err = ErrNoSuchUpload
+	case "BadDigest", "InvalidDigest":
+		described.Err = ErrBadDigest
+	case "RestoreAlreadyInProgress":
+		described.Err = ErrRestoreInProgress
+	}
+	return described
+}
+
+func NewClient(cfg Config) *Client {
+	c := &Client{
+		endpoint: strings.TrimSuffix(cfg.Endpoint(), "/"),
+		region:   cfg.Region(),
+		credentials: aws.Credentials{
+			AccessKeyID:     cfg.AccessKeyID(),
+			SecretAccessKey: cfg.SecretAccessKey(),
+		},
+		signer: v4.NewSigner(func(o *v4.SignerOptions) {
+			o.DisableURIPathEscaping = true
+		}),
+		now: time.Now,
+	}
+
+	// MinIO and most S3-compatible stores do not care about the region, but it is
+	// part of the signature.
+	if c.region == "" {
+		c.region = "us-east-1"
+	}
+
+	// No retries: a retried CreateMultipartUpload leaves an upload behind, and the
+	// activity around an upload resumes it when it is retried.
+	c.restyClient = httpx.New(httpx.Config{
+		Service:       serviceName,
+		Timeout:       requestTimeout,
+		DescribeError: s3ErrorFromResponse,
+	})
+	c.restyClient.SetPreRequestHook(func(_ *resty.Client, req *http.Request) error {
+		return c.sign(req)
+	})
+
+	return c
+}
+
+// sign signs the request as it is about to be sent. The payload hash is the
+// X-Amz-Content-Sha256 header the request was built with.
+func (c *Client) sign(req *http.Request) error {
+	payloadHash := req.Header.Get("X-Amz-Content-Sha256")
+	if payloadHash == "" {
+		payloadHash = emptyPayloadHash
+		req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+	}
+	return c.signer.SignHTTP(req.Context(), c.credentials, req, payloadHash, serviceName, c.region, c.now())
+}
+
+// escapePath escapes an object key the way S3 signs it: everything but the unreserved
+// characters and the slashes between segments.
+func escapePath(key string) string {
+	var b strings.Builder
+	for i := 0; i < len(key); i++ {
+		ch := key[i]
+		if ch >= 'A' && ch <= 'Z' || ch >= 'a' && ch <= 'z' || ch >= '0' && ch <= '9' ||
+			ch == '-' || ch == '_' || ch == '.' || ch == '~' || ch == '/' {
+			b.WriteByte(ch)
+		} else {
+			fmt.Fprintf(&b, "%%%02X", ch)
+		}
+	}
+	return b.String()
+}
+
+// objectURL addresses the object path-style on a configured endpoint, and
+// virtual-hosted on AWS.
+func (c *Client) objectURL(bucket, key string, query url.Values) string {
+	key = strings.TrimPrefix(key, "/")
+
+	var base, path string
+	if c.endpoint != "" {
+		base = c.endpoint
+		path = "/" + bucket + "/" + key
+	} else {
+		base = fmt.Sprintf("https://%s.s3.%s.amazonaws.com", bucket, c.region)
+		path = "/" + key
+	}
+
+	u := base + escapePath(path)
+	if len(query) > 0 {
+		u += "?" + query.Encode()
+	}
+	return u
+}
+
+func (c *Client) request(ctx context.Context, body []byte) *resty.Request {
+	req := c.restyClient.R().SetContext(ctx)
+	if body != nil {
+		sum := sha256.Sum256(body)
+		req.SetHeader("X-Amz-Content-Sha256", hex.EncodeToString(sum[:]))
+		req.SetBody(body)
+	}
+	return req
+}
@@ -0,0 +1,152 @@
+package s3
+
+import (
+	"context"
+	"crypto/md5"
+	"encoding/base64"
+	"encoding/xml"
+	"fmt"
+	"net/url"
+	"sort"
+	"strconv"
+
+	"github.com/bcc-code/bcc-media-flows/internal/httpx"
+)
+
+// Part is one uploaded part of a multipart upload.
+type Part struct {
+	Number int    `xml:"PartNumber"`
+	ETag   string `xml:"ETag"`
+	Size   int64  `xml:"Size"`
+}
+
+type initiateResult struct {
+	UploadID string `xml:"UploadId"`
+}
+
+// CreateMultipartUpload starts an upload to the key and returns its id.
+func (c *Client) CreateMultipartUpload(ctx context.Context, bucket, key string) (string, error) {
+	resp, err := c.request(ctx, nil).Post(c.objectURL(bucket, key, url.Values{"uploads": {""}}))
+	if err != nil {
+		return "", httpx.SanitizeError(err)
+	}
+
+	var result initiateResult
+	if err := xml.Unmarshal(resp.Body(), &result); err != nil {
+		return "", fmt.Errorf("reading CreateMultipartUpload response: %w", err)
+	}
+	if result.UploadID == "" {
+		return "", fmt.Errorf("CreateMultipartUpload returned no upload id")
+	}
+	return result.UploadID, nil
+}
+
+// UploadPart uploads one part and returns its ETag. S3 checks the part against its
+// MD5, and answers ErrBadDigest when they differ.
+func (c *Client) UploadPart(ctx context.Context, bucket, key, uploadID string, number int, data []byte) (string, error) {
+	sum := md5.Sum(data)
+
+	resp, err := c.request(ctx, data).
+		SetHeader("Content-MD5", base64.StdEncoding.EncodeToString(sum[:])).
+		Put(c.objectURL(bucket, key, url.Values{
+			"partNumber": {strconv.Itoa(number)},
+			"uploadId":   {uploadID},
+		}))
+	if err != nil {
+		return "", httpx.SanitizeError(err)
+	}
+
+	etag := resp.Header().Get("ETag")
+	if etag == "" {
+		return "", fmt.Errorf("UploadPart %d returned no ETag", number)
+	}
+	return etag, nil
+}
+
+type listPartsResult struct {
+	Parts                []Part `xml:"Part"`
+	IsTruncated          bool   `xml:"IsTruncated"`
+	NextPartNumberMarker int    `xml:"NextPartNumberMarker"`
+}
+
+// ListParts lists the parts S3 has of the upload, in order.
+func (c *Client) ListParts(ctx context.Context, bucket, key, uploadID string) ([]Part, error) {
+	var parts []Part
+	marker := 0
+	for {
+		query := url.Values{"uploadId": {uploadID}}
+		if marker > 0 {
+			query.Set("part-number-marker", strconv.Itoa(marker))
+		}
+
+		resp, err := c.request(ctx, nil).Get(c.objectURL(bucket, key, query))
+		if err != nil {
+			return nil, httpx.SanitizeError(err)
+		}
+
+		var result listPartsResult
+		if err := xml.Unmarshal(resp.Body(), &result); err != nil {
+			return nil, fmt.Errorf("reading ListParts response: %w", err)
+		}
+		parts = append(parts, result.Parts...)
+
+		if !result.IsTruncated || result.NextPartNumberMarker <= marker {
+			break
+		}
+		marker = result.NextPartNumberMarker
+	}
+
+	sort.Slice(parts, func(i, j int) bool { return parts[i].Number < parts[j].Number })
+	return parts, nil
+}
+
+type completedPart struct {
+	Number int    `xml:"PartNumber"`
+	ETag   string `xml:"ETag"`
+}
+
+type completeRequest struct {
+	XMLName xml.Name        `xml:"CompleteMultipartUpload"`
+	Parts   []completedPart `xml:"Part"`
+}
+
+type completeResult struct {
+	ETag string `xml:"ETag"`
+	// S3 can answer 200 and still have failed to complete the upload.
+	Code    string `xml:"Code"`
+	Message string `xml:"Message"`
+}
+
+// CompleteMultipartUpload assembles the parts into the object and returns its ETag.
+func (c *Client) CompleteMultipartUpload(ctx context.Context, bucket, key, uploadID string, parts []Part) (string, error) {
+	request := completeRequest{}
+	for _, part := range parts {
+		request.Parts = append(request.Parts, completedPart{Number: part.Number, ETag: part.ETag})
+	}
+	body, err := xml.Marshal(request)
+	if err != nil {
+		return "", err
+	}
+
+	resp, err := c.request(ctx, body).
+		SetHeader("Content-Type", "application/xml").
+		Post(c.objectURL(bucket, key, url.Values{"uploadId": {uploadID}}))
+	if err != nil {
+		return "", httpx.SanitizeError(err)
+	}
+
+	var result completeResult
+	if err := xml.Unmarshal(resp.Body(), &result); err != nil {
+		return "", fmt.Errorf("reading CompleteMultipartUpload response: %w", err)
+	}
+	if result.Code != "" {
+		return "", fmt.Errorf("s3 CompleteMultipartUpload failed: %s: %s", result.Code, result.Message)
+	}
+	return result.ETag, nil
+}
+
+// AbortMultipartUpload drops the upload and the parts S3 has of it.
+func (c *Client) AbortMultipartUpload(ctx context.Context, bucket, key, uploadID string) error {
+	_, err := c.request(ctx, nil).Delete(c.objectURL(bucket, key, url.Values{"uploadId": {uploadID}}))
+	return httpx.SanitizeError(err)
+}
@@ -0,0 +1,99 @@
+package s3
+
+import (
+	"context"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/bcc-code/bcc-media-flows/internal/httpx"
+)
+
+// ErrRestoreInProgress is a restore requested of an object S3 is already restoring.
+var ErrRestoreInProgress = errors.New("restore already in progress")
+
+// ObjectStatus is what HeadObject tells of an object.
+type ObjectStatus struct {
+	Size int64
+	// StorageClass is empty for STANDARD, as S3 leaves the header out for it.
+	StorageClass string
+	// Restoring is a restore of an archived object that has not finished.
+	Restoring bool
+	// Restored is a restored copy of an archived object that can be read until it
+	// expires.
+	Restored bool
+}
+
+// Archived is whether the object is in a storage class that has to be restored before
+// it can be read.
+func (o ObjectStatus) Archived() bool {
+	return o.StorageClass == "GLACIER" || o.StorageClass == "DEEP_ARCHIVE"
+}
+
+// Readable is whether the object can be read now.
+func (o ObjectStatus) Readable() bool {
+	return !o.Archived() || o.Restored
+}
+
+// HeadObject returns the size and storage class of the object, and how far a restore of
+// it has come.
+func (c *Client) HeadObject(ctx context.Context, bucket, key string) (*ObjectStatus, error) {
+	resp, err := c.request(ctx, nil).Head(c.objectURL(bucket, key, nil))
+	if err != nil {
+		return nil, httpx.SanitizeError(err)
+	}
+
+	status := &ObjectStatus{StorageClass: resp.Header().Get("X-Amz-Storage-Class")}
+	status.Size, _ = strconv.ParseInt(resp.Header().Get("Content-Length"), 10, 64)
+
+	// ongoing-request="true" while restoring, and "false" with an expiry-date once the
+	// copy is there.
+	switch restore := resp.Header().Get("X-Amz-Restore"); {
+	case strings.Contains(restore, `ongoing-request="true"`):
+		status.Restoring = true
+	case strings.Contains(restore, `ongoing-request="false"`):
+		status.Restored = true
+	}
+	return status, nil
+}
+
+type restoreRequest struct {
+	XMLName              xml.Name `xml:"RestoreRequest"`
+	Days                 int      `xml:"Days"`
+	GlacierJobParameters struct {
+		Tier string `xml:"Tier"`
+	} `xml:"GlacierJobParameters"`
+}
+
+// RestoreObject asks S3 for a copy of an archived object that can be read for the
+// days. The tier is Bulk, Standard or Expedited, the cheapest and slowest first. A
+// restore S3 is already doing is ErrRestoreInProgress.
+func (c *Client) RestoreObject(ctx context.Context, bucket, key string, days int, tier string) error {
+	request := restoreRequest{Days: days}
+	request.GlacierJobParameters.Tier = tier
+
+	body, err := xml.Marshal(request)
+	if err != nil {
+		return err
+	}
+
+	_, err = c.request(ctx, body).Post(c.objectURL(bucket, key, url.Values{"restore": {""}}))
+	return httpx.SanitizeError(err)
+}
+
+// ParseURI splits an S3 URI such as "s3://bucket/path/file.mxf", the way Vidispine
+// names the files on its S3 storages, into the bucket and key.
+func ParseURI(uri string) (bucket, key string, err error) {
+	rest, found := strings.CutPrefix(uri, "s3://")
+	if !found {
+		return "", "", fmt.Errorf("%q is not s3://bucket/key", uri)
+	}
+	bucket, key, _ = strings.Cut(rest, "/")
+	if bucket == "" || key == "" || strings.HasSuffix(key, "/") {
+		return "", "", fmt.Errorf("%q is not s3://bucket/key", uri)
+	}
+	return bucket, key, nil
+}
@@ -0,0 +1,94 @@
+package s3
+
+import (
+	"context"
+	"encoding/xml"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeGlacier is one archived object, restored by the first restore request and
+// readable once restoreDone is set.
+type fakeGlacier struct {
+	t           *testing.T
+	restoring   bool
+	restoreDone bool
+	request     restoreRequest
+}
+
+func (f *fakeGlacier) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	assert.Equal(f.t, "/cold/2024/01/02/file.mxf", r.URL.Path)
+
+	switch r.Method {
+	case http.MethodHead:
+		w.Header().Set("Content-Length", "1234")
+		w.Header().Set("X-Amz-Storage-Class", "DEEP_ARCHIVE")
+		switch {
+		case f.restoreDone:
+			w.Header().Set("X-Amz-Restore", `ongoing-request="false", expiry-date="Fri, 23 Dec 2026 00:00:00 GMT"`)
+		case f.restoring:
+			w.Header().Set("X-Amz-Restore", `ongoing-request="true"`)
+		}
+	case http.MethodPost:
+		assert.True(f.t, r.URL.Query().Has("restore"))
+		if f.restoring {
+			writeError(w, http.StatusConflict, "RestoreAlreadyInProgress")
+			return
+		}
+		body, _ := io.ReadAll(r.Body)
+		assert.NoError(f.t, xml.Unmarshal(body, &f.request))
+		f.restoring = true
+		w.WriteHeader(http.StatusAccepted)
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+func TestRestoreObject(t *testing.T) {
+	f := &fakeGlacier{t: t}
+	server := httptest.NewServer(f)
+	defer server.Close()
+	c := NewClient(testConfig{endpoint: server.URL})
+	ctx := context.Background()
+
+	status, err := c.HeadObject(ctx, "cold", "2024/01/02/file.mxf")
+	require.NoError(t, err)
+	assert.Equal(t, &ObjectStatus{Size: 1234, StorageClass: "DEEP_ARCHIVE"}, status)
+	assert.True(t, status.Archived())
+	assert.False(t, status.Readable())
+
+	require.NoError(t, c.RestoreObject(ctx, "cold", "2024/01/02/file.mxf", 7, "Bulk"))
+	assert.Equal(t, 7, f.request.Days)
+	assert.Equal(t, "Bulk", f.request.GlacierJobParameters.Tier)
+
+	err = c.RestoreObject(ctx, "cold", "2024/01/02/file.mxf", 7, "Bulk")
+	assert.ErrorIs(t, err, ErrRestoreInProgress)
+
+	status, err = c.HeadObject(ctx, "cold", "2024/01/02/file.mxf")
+	require.NoError(t, err)
+	assert.True(t, status.Restoring)
+	assert.False(t, status.Readable())
+
+	f.restoreDone = true
+	status, err = c.HeadObject(ctx, "cold", "2024/01/02/file.mxf")
+	require.NoError(t, err)
+	assert.True(t, status.Restored)
+	assert.True(t, status.Readable())
+}
+
+func TestParseURI(t *testing.T) {
+	bucket, key, err := ParseURI("s3://cold/2024/01/02/file.mxf")
+	require.NoError(t, err)
+	assert.Equal(t, "cold", bucket)
+	assert.Equal(t, "2024/01/02/file.mxf", key)
+
+	for _, uri := range []string{"file:///mnt/isilon/file.mxf", "s3://cold/", "s3:///file.mxf"} {
+		_, _, err := ParseURI(uri)
+		assert.Error(t, err, uri)
+	}
+}
@@ -0,0 +1,255 @@
+package s3
+
+import (
+	"bytes"
+	"context"
+	"crypto/md5"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+)
+
+const (
+	// DefaultPartSize is what a file is split into, unless it would take more parts
+	// than S3 allows.
+	DefaultPartSize = 64 << 20
+	maxParts        = 10000
+)
+
+// State is how far an upload has come. It is all a retried attempt needs to resume:
+// which parts are done is asked of S3, which is what decides it anyway.
+type State struct {
+	UploadID string
+	Bucket   string
+	Key      string
+	// Size, ModTime and PartSize are the file the upload was started from, and how it
+	// was split. A file that has changed since is uploaded again from the start.
+	Size     int64
+	ModTime  time.Time
+	PartSize int64
+
+	PartsDone int
+	PartsAll  int
+	BytesDone int64
+}
+
+func (s State) matches(bucket, key string, size int64, modTime time.Time, partSize int64) bool {
+	return s.UploadID != "" &&
+		s.Bucket == bucket && s.Key == key &&
+		s.Size == size && s.ModTime.Equal(modTime) && s.PartSize == partSize
+}
+
+// Result is an uploaded object.
+type Result struct {
+	Bucket string
+	Key    string
+	Size   int64
+	ETag   string
+	Parts  int
+	// Resumed is how many of the parts were already uploaded by an earlier attempt.
+	Resumed int
+}
+
+// PartSize is the part size for a file: DefaultPartSize, or as much larger as keeps
+// the file within S3's part count.
+func PartSize(size int64) int64 {
+	partSize := int64(DefaultPartSize)
+	for size > partSize*maxParts {
+		partSize *= 2
+	}
+	return partSize
+}
+
+func partCount(size, partSize int64) int {
+	if size == 0 {
+		return 1
+	}
+	return int((size + partSize - 1) / partSize)
+}
+
+// md5ETag is the MD5 a part's ETag is, when it is one. Objects encrypted with KMS keys
+// have ETags that are not.
+func md5ETag(etag string) ([]byte, bool) {
+	sum, err := hex.DecodeString(strings.Trim(etag, `"`))
+	if err != nil || len(sum) != md5.Size {
+		return nil, false
+	}
+	return sum, true
+}
+
+// multipartETag is the ETag S3 gives an object assembled from parts with these MD5s.
+func multipartETag(sums [][]byte) string {
+	all := md5.New()
+	for _, sum := range sums {
+		all.Write(sum)
+	}
+	return fmt.Sprintf("%s-%d", hex.EncodeToString(all.Sum(nil)), len(sums))
+}
+
+func readPart(file *os.File, number int, partSize, size int64) ([]byte, error) {
+	offset := int64(number-1) * partSize
+	length := min(partSize, size-offset)
+
+	data := make([]byte, length)
+	if _, err := file.ReadAt(data, offset); err != nil && !errors.Is(err, io.EOF) {
+		return nil, err
+	}
+	return data, nil
+}
+
+// Upload uploads the file to the key in parts. With the state of an earlier attempt
+// at the same upload, it keeps the parts S3 has that match the file, and uploads the
+// rest. progress is called with the state after the upload is started and after every
+// part; an attempt that fails is resumed by passing the last state it reported.
+//
+// Every part is checked by S3 against its MD5, parts kept from an earlier attempt are
+// checked against the file, and the object is checked against the parts it was
+// assembled from.
+func (c *Client) Upload(ctx context.Context, path, bucket, key string, resume *State, progress func(State)) (*Result, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return nil, err
+	}
+
+	size := info.Size()
+	partSize := PartSize(size)
+	if c.partSize > 0 {
+		partSize = c.partSize
+	}
+	state := State{
+		Bucket:   bucket,
+		Key:      key,
+		Size:     size,
+		ModTime:  info.ModTime(),
+		PartSize: partSize,
+		PartsAll: partCount(size, partSize),
+	}
+
+	done := map[int]Part{}
+	sums := make([][]byte, state.PartsAll)
+	if resume != nil && resume.matches(bucket, key, size, info.ModTime(), partSize) {
+		parts, err := c.ListParts(ctx, bucket, key, resume.UploadID)
+		switch {
+		case errors.Is(err, ErrNoSuchUpload):
+			// Expired or aborted; start over.
+		case err != nil:
+			return nil, err
+		default:
+			state.UploadID = resume.UploadID
+			for _, part := range parts {
+				if part.Number > state.PartsAll {
+					continue
+				}
+				data, err := readPart(file, part.Number, partSize, size)
+				if err != nil {
+					return nil, err
+				}
+				if part.Size != int64(len(data)) {
+					continue
+				}
+				local := md5.Sum(data)
+				if sum, ok := md5ETag(part.ETag); ok && !bytes.Equal(sum, local[:]) {
+					continue
+				}
+				done[part.Number] = part
+				sums[part.Number-1] = local[:]
+				state.BytesDone += part.Size
+			}
+		}
+	} else if resume != nil && resume.UploadID != "" {
+		// Something else was being uploaded; its parts would only cost storage.
+		_ = c.AbortMultipartUpload(ctx, resume.Bucket, resume.Key, resume.UploadID)
+	}
+	resumed := len(done)
+
+	if state.UploadID == "" {
+		state.UploadID, err = c.CreateMultipartUpload(ctx, bucket, key)
+		if err != nil {
+			return nil, err
+		}
+	}
+	state.PartsDone = len(done)
+	if progress != nil {
+		progress(state)
+	}
+
+	parts := make([]Part, state.PartsAll)
+	for number := 1; number <= state.PartsAll; number++ {
+		if part, ok := done[number]; ok {
+			parts[number-1] = part
+			continue
+		}
+
+		data, err := readPart(file, number, partSize, size)
+		if err != nil {
+			return nil, err
+		}
+		local := md5.Sum(data)
+		sums[number-1] = local[:]
+
+		etag, err := c.UploadPart(ctx, bucket, key, state.UploadID, number, data)
+		if err != nil {
+			return nil, fmt.Errorf("uploading part %d of %d: %w", number, state.PartsAll, err)
+		}
+		if sum, ok := md5ETag(etag); ok && !bytes.Equal(sum, local[:]) {
+			return nil, fmt.Errorf("part %d: ETag %s is not the MD5 of the part: %w", number, etag, ErrBadDigest)
+		}
+		parts[number-1] = Part{Number: number, ETag: etag, Size: int64(len(data))}
+
+		state.PartsDone++
+		state.BytesDone += int64(len(data))
+		if progress != nil {
+			progress(state)
+		}
+	}
+
+	etag, err := c.CompleteMultipartUpload(ctx, bucket, key, state.UploadID, parts)
+	if err != nil {
+		return nil, err
+	}
+
+	// Only when every part's ETag was its MD5 is the object's the MD5 of those.
+	allMD5 := true
+	for _, part := range parts {
+		if _, ok := md5ETag(part.ETag); !ok {
+			allMD5 = false
+		}
+	}
+	if want := multipartETag(sums); allMD5 && strings.Trim(etag, `"`) != want {
+		return nil, fmt.Errorf("uploaded object has ETag %s, the file gives %s", etag, want)
+	}
+
+	return &Result{
+		Bucket:  bucket,
+		Key:     key,
+		Size:    size,
+		ETag:    strings.Trim(etag, `"`),
+		Parts:   state.PartsAll,
+		Resumed: resumed,
+	}, nil
+}
+
+// ParseDestination splits an rclone-style destination such as
+// "s3prod:vod-asset-ingest-prod/path/file.mxf" or "bmms3:/prod-bmm-mediabanken/file"
+// into the remote, bucket and key.
+func ParseDestination(destination string) (remote, bucket, key string, err error) {
+	remote, rest, found := strings.Cut(destination, ":")
+	if !found || remote == "" {
+		return "", "", "", fmt.Errorf("%q is not remote:bucket/key", destination)
+	}
+	bucket, key, _ = strings.Cut(strings.TrimPrefix(rest, "/"), "/")
+	if bucket == "" || key == "" || strings.HasSuffix(key, "/") {
+		return "", "", "", fmt.Errorf("%q is not remote:bucket/key", destination)
+	}
+	return remote, bucket, key, nil
+}
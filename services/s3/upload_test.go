@@ -0,0 +1,306 @@
+package s3
+
+import (
+	"bytes"
+	"context"
+	"crypto/md5"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testConfig struct {
+	endpoint string
+}
+
+func (c testConfig) Endpoint() string        { return c.endpoint }
+func (c testConfig) Region() string          { return "" }
+func (c testConfig) AccessKeyID() string     { return "key" }
+func (c testConfig) SecretAccessKey() string { return "secret" }
+
+// fakeS3 keeps multipart uploads in memory, the way S3 answers them.
+type fakeS3 struct {
+	t    *testing.T
+	lock sync.Mutex
+
+	nextID  int
+	uploads map[string]map[int][]byte
+	objects map[string][]byte
+	aborted []string
+
+	// failPart makes the upload of that part number fail once.
+	failPart int
+	// partRequests counts the part uploads received.
+	partRequests int
+}
+
+func newFakeS3(t *testing.T) (*fakeS3, *Client) {
+	f := &fakeS3{t: t, uploads: map[string]map[int][]byte{}, objects: map[string][]byte{}}
+	server := httptest.NewServer(f)
+	t.Cleanup(server.Close)
+
+	c := NewClient(testConfig{endpoint: server.URL})
+	c.partSize = 1 << 10
+	return f, c
+}
+
+func writeError(w http.ResponseWriter, status int, code string) {
+	w.WriteHeader(status)
+	_, _ = fmt.Fprintf(w, "<Error><Code>%s</Code><Message>%s</Message></Error>", code, code)
+}
+
+func partETag(data []byte) string {
+	sum := md5.Sum(data)
+	return `"` + hex.EncodeToString(sum[:]) + `"`
+}
+
+func (f *fakeS3) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	body, _ := io.ReadAll(r.Body)
+	sum := sha256.Sum256(body)
+	assert.Equal(f.t, hex.EncodeToString(sum[:]), r.Header.Get("X-Amz-Content-Sha256"))
+	assert.True(f.t, strings.HasPrefix(r.Header.Get("Authorization"), "AWS4-HMAC-SHA256 Credential=key/"))
+
+	object := strings.TrimPrefix(r.URL.Path, "/")
+	query := r.URL.Query()
+	uploadID := query.Get("uploadId")
+
+	switch {
+	case r.Method == http.MethodPost && query.Has("uploads"):
+		f.nextID++
+		id := fmt.Sprintf("upload-%d", f.nextID)
+		f.uploads[id] = map[int][]byte{}
+		_, _ = fmt.Fprintf(w, "<InitiateMultipartUploadResult><UploadId>%s</UploadId></InitiateMultipartUploadResult>", id)
+
+	case r.Method == http.MethodPut:
+		parts, ok := f.uploads[uploadID]
+		if !ok {
+			writeError(w, http.StatusNotFound, "NoSuchUpload")
+			return
+		}
+		f.partRequests++
+		number, _ := strconv.Atoi(query.Get("partNumber"))
+		if number == f.failPart {
+			f.failPart = 0
+			writeError(w, http.StatusInternalServerError, "InternalError")
+			return
+		}
+		md5sum := md5.Sum(body)
+		if r.Header.Get("Content-MD5") != base64.StdEncoding.EncodeToString(md5sum[:]) {
+			writeError(w, http.StatusBadRequest, "BadDigest")
+			return
+		}
+		parts[number] = body
+		w.Header().Set("ETag", partETag(body))
+
+	case r.Method == http.MethodGet:
+		parts, ok := f.uploads[uploadID]
+		if !ok {
+			writeError(w, http.StatusNotFound, "NoSuchUpload")
+			return
+		}
+		var numbers []int
+		for number := range parts {
+			numbers = append(numbers, number)
+		}
+		sort.Ints(numbers)
+		_, _ = fmt.Fprint(w, "<ListPartsResult>")
+		for _, number := range numbers {
+			_, _ = fmt.Fprintf(w, "<Part><PartNumber>%d</PartNumber><ETag>%s</ETag><Size>%d</Size></Part>",
+				number, partETag(parts[number]), len(parts[number]))
+		}
+		_, _ = fmt.Fprint(w, "<IsTruncated>false</IsTruncated></ListPartsResult>")
+
+	case r.Method == http.MethodPost:
+		parts, ok := f.uploads[uploadID]
+		if !ok {
+			writeError(w, http.StatusNotFound, "NoSuchUpload")
+			return
+		}
+		var request completeRequest
+		require.NoError(f.t, xml.Unmarshal(body, &request))
+
+		var data []byte
+		var sums [][]byte
+		for _, part := range request.Parts {
+			require.Equal(f.t, partETag(parts[part.Number]), part.ETag)
+			data = append(data, parts[part.Number]...)
+			sum := md5.Sum(parts[part.Number])
+			sums = append(sums, sum[:])
+		}
+		f.objects[object] = data
+		delete(f.uploads, uploadID)
+		_, _ = fmt.Fprintf(w, `<CompleteMultipartUploadResult><ETag>"%s"</ETag></CompleteMultipartUploadResult>`, multipartETag(sums))
+
+	case r.Method == http.MethodDelete:
+		delete(f.uploads, uploadID)
+		f.aborted = append(f.aborted, uploadID)
+	}
+}
+
+func writeFile(t *testing.T, size int) (string, []byte) {
+	t.Helper()
+
+	data := make([]byte, size)
+	for i := range data {
+		data[i] = byte(i * 7)
+	}
+	path := filepath.Join(t.TempDir(), "file.mxf")
+	require.NoError(t, os.WriteFile(path, data, 0o644))
+	return path, data
+}
+
+func TestUpload_InParts(t *testing.T) {
+	f, c := newFakeS3(t)
+	path, data := writeFile(t, 3500)
+
+	var states []State
+	result, err := c.Upload(context.Background(), path, "bucket", "dir/file one.mxf", nil, func(s State) {
+		states = append(states, s)
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, data, f.objects["bucket/dir/file one.mxf"])
+	assert.Equal(t, 4, result.Parts)
+	assert.Zero(t, result.Resumed)
+	assert.Equal(t, int64(3500), result.Size)
+	require.Len(t, states, 5, "one when started, one per part")
+	assert.Equal(t, 4, states[4].PartsDone)
+	assert.Equal(t, int64(3500), states[4].BytesDone)
+}
+
+func TestUpload_EmptyFile(t *testing.T) {
+	f, c := newFakeS3(t)
+	path, _ := writeFile(t, 0)
+
+	result, err := c.Upload(context.Background(), path, "bucket", "empty", nil, nil)
+
+	require.NoError(t, err)
+	assert.Equal(t, 1, result.Parts)
+	assert.Empty(t, f.objects["bucket/empty"])
+}
+
+// An attempt that fails part way is resumed from the parts S3 has, which is what a
+// retried activity does with the state it heartbeated.
+func TestUpload_ResumesFromTheLastState(t *testing.T) {
+	f, c := newFakeS3(t)
+	path, data := writeFile(t, 3500)
+	f.failPart = 3
+
+	var last State
+	_, err := c.Upload(context.Background(), path, "bucket", "file", nil, func(s State) { last = s })
+	require.Error(t, err)
+	assert.Equal(t, 2, last.PartsDone)
+	assert.Equal(t, 3, f.partRequests)
+
+	result, err := c.Upload(context.Background(), path, "bucket", "file", &last, nil)
+
+	require.NoError(t, err)
+	assert.Equal(t, 2, result.Resumed)
+	assert.Equal(t, 5, f.partRequests, "only parts 3 and 4 are uploaded again")
+	assert.Equal(t, data, f.objects["bucket/file"])
+}
+
+func TestUpload_ReuploadsAPartThatDoesNotMatchTheFile(t *testing.T) {
+	f, c := newFakeS3(t)
+	path, data := writeFile(t, 2048)
+	f.failPart = 2
+
+	var last State
+	_, err := c.Upload(context.Background(), path, "bucket", "file", nil, func(s State) { last = s })
+	require.Error(t, err)
+	f.uploads[last.UploadID][1] = bytes.Repeat([]byte{1}, 1024)
+
+	result, err := c.Upload(context.Background(), path, "bucket", "file", &last, nil)
+
+	require.NoError(t, err)
+	assert.Zero(t, result.Resumed)
+	assert.Equal(t, data, f.objects["bucket/file"])
+}
+
+func TestUpload_ChangedFileStartsOver(t *testing.T) {
+	f, c := newFakeS3(t)
+	path, _ := writeFile(t, 3500)
+	f.failPart = 2
+
+	var last State
+	_, err := c.Upload(context.Background(), path, "bucket", "file", nil, func(s State) { last = s })
+	require.Error(t, err)
+
+	changed := bytes.Repeat([]byte{9}, 4000)
+	require.NoError(t, os.WriteFile(path, changed, 0o644))
+
+	result, err := c.Upload(context.Background(), path, "bucket", "file", &last, nil)
+
+	require.NoError(t, err)
+	assert.Zero(t, result.Resumed)
+	assert.Equal(t, []string{last.UploadID}, f.aborted)
+	assert.Equal(t, changed, f.objects["bucket/file"])
+}
+
+func TestUpload_ExpiredUploadStartsOver(t *testing.T) {
+	f, c := newFakeS3(t)
+	path, data := writeFile(t, 2048)
+
+	resume := &State{UploadID: "gone", Bucket: "bucket", Key: "file", Size: 2048, PartSize: 1024}
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	resume.ModTime = info.ModTime()
+
+	result, err := c.Upload(context.Background(), path, "bucket", "file", resume, nil)
+
+	require.NoError(t, err)
+	assert.Zero(t, result.Resumed)
+	assert.Equal(t, data, f.objects["bucket/file"])
+}
+
+func TestUpload_NoSuchUploadIsRecognised(t *testing.T) {
+	_, c := newFakeS3(t)
+
+	_, err := c.ListParts(context.Background(), "bucket", "file", "gone")
+
+	assert.ErrorIs(t, err, ErrNoSuchUpload)
+}
+
+func TestPartSize_StaysWithinThePartCount(t *testing.T) {
+	assert.Equal(t, int64(DefaultPartSize), PartSize(10<<30))
+	size := int64(2 << 40)
+	assert.LessOrEqual(t, partCount(size, PartSize(size)), maxParts)
+}
+
+func TestEscapePath(t *testing.T) {
+	assert.Equal(t, "/bucket/dir/file%20one%2B%28a%29.mxf", escapePath("/bucket/dir/file one+(a).mxf"))
+}
+
+func TestParseDestination(t *testing.T) {
+	for destination, want := range map[string][3]string{
+		"s3prod:vod-asset-ingest-prod/a/b.mxf":   {"s3prod", "vod-asset-ingest-prod", "a/b.mxf"},
+		"bmms3:/prod-bmm-mediabanken/x/file.mp3": {"bmms3", "prod-bmm-mediabanken", "x/file.mp3"},
+	} {
+		remote, bucket, key, err := ParseDestination(destination)
+		require.NoError(t, err, destination)
+		assert.Equal(t, want, [3]string{remote, bucket, key})
+	}
+
+	for _, destination := range []string{"/mnt/isilon/file", "s3prod:bucket", "s3prod:bucket/dir/", ":bucket/key"} {
+		_, _, _, err := ParseDestination(destination)
+		assert.Error(t, err, destination)
+	}
+}
@@ -0,0 +1,185 @@
+package subtitles
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// assHeader is the script info and style of ASS files written without one of their
+// own, the same as ffmpeg writes.
+const assHeader = `[Script Info]
+ScriptType: v4.00+
+PlayResX: 384
+PlayResY: 288
+ScaledBorderAndShadow: yes
+YCbCr Matrix: None
+
+[V4+ Styles]
+Format: Name, Fontname, Fontsize, PrimaryColour, SecondaryColour, OutlineColour, BackColour, Bold, Italic, Underline, StrikeOut, ScaleX, ScaleY, Spacing, Angle, BorderStyle, Outline, Shadow, Alignment, MarginL, MarginR, MarginV, Encoding
+Style: Default,Arial,16,&Hffffff,&Hffffff,&H0,&H0,0,0,0,0,100,100,0,0,1,1,0,2,10,10,10,1
+
+[Events]
+`
+
+const assEventFormat = "Format: Layer, Start, End, Style, Name, MarginL, MarginR, MarginV, Effect, Text"
+
+var (
+	assOverridePattern = regexp.MustCompile(`\{[^}]*\}`)
+	assStylePattern    = regexp.MustCompile(`\\([ibu])(\d+)|\\r`)
+)
+
+// parseASSTime parses H:MM:SS.cc.
+func parseASSTime(s string) (time.Duration, error) {
+	var h, m int
+	var sec float64
+	if _, err := fmt.Sscanf(strings.TrimSpace(s), "%d:%d:%f", &h, &m, &sec); err != nil {
+		return 0, fmt.Errorf("invalid time %q", s)
+	}
+	return time.Duration(h)*time.Hour + time.Duration(m)*time.Minute + seconds2duration(sec), nil
+}
+
+func assTime(d time.Duration) string {
+	d = max(d, 0).Round(10 * time.Millisecond)
+	h, m, s, ms := splitTime(d)
+	return fmt.Sprintf("%d:%02d:%02d.%02d", h, m, s, ms/10)
+}
+
+// ParseASS parses the dialogue events of Advanced SubStation Alpha, and SubStation
+// Alpha, scripts. Override tags other than italic, bold and underline are dropped.
+func ParseASS(data []byte) (*Subtitles, error) {
+	subs := &Subtitles{}
+	inEvents := false
+	seenEvents := false
+	format := strings.Split(strings.TrimPrefix(assEventFormat, "Format: "), ", ")
+
+	for i, line := range strings.Split(normalize(data), "\n") {
+		line = strings.TrimSpace(line)
+		if strings.HasPrefix(line, "[") {
+			inEvents = strings.EqualFold(line, "[Events]")
+			seenEvents = seenEvents || inEvents
+			continue
+		}
+		if !inEvents {
+			continue
+		}
+
+		key, value, found := strings.Cut(line, ":")
+		if !found {
+			continue
+		}
+		switch key {
+		case "Format":
+			format = strings.Split(value, ",")
+			for j := range format {
+				format[j] = strings.TrimSpace(format[j])
+			}
+		case "Dialogue":
+			fields := strings.SplitN(strings.TrimSpace(value), ",", len(format))
+			if len(fields) != len(format) {
+				return nil, fmt.Errorf("line %d: %d fields, the format has %d", i+1, len(fields), len(format))
+			}
+			cue := Cue{}
+			var err error
+			for j, name := range format {
+				switch name {
+				case "Start":
+					cue.Start, err = parseASSTime(fields[j])
+				case "End":
+					cue.End, err = parseASSTime(fields[j])
+				case "Text":
+					cue.Lines = lines(markup(assRuns(fields[j])))
+				}
+				if err != nil {
+					return nil, fmt.Errorf("line %d: %w", i+1, err)
+				}
+			}
+			subs.Cues = append(subs.Cues, cue)
+		}
+	}
+
+	if !seenEvents {
+		return nil, errors.New("no [Events] section")
+	}
+	return subs, nil
+}
+
+// assRuns reads the text of a dialogue event.
+func assRuns(text string) []run {
+	text = strings.NewReplacer(`\N`, "\n", `\n`, "\n", `\h`, " ").Replace(text)
+
+	var out []run
+	var current style
+	add := func(text string) {
+		if text != "" {
+			out = append(out, run{text: text, style: current})
+		}
+	}
+
+	last := 0
+	for _, loc := range assOverridePattern.FindAllStringIndex(text, -1) {
+		add(text[last:loc[0]])
+		last = loc[1]
+		for _, tag := range assStylePattern.FindAllStringSubmatch(text[loc[0]:loc[1]], -1) {
+			on := tag[2] != "0"
+			switch tag[1] {
+			case "i":
+				current.italic = on
+			case "b":
+				current.bold = on
+			case "u":
+				current.underline = on
+			default:
+				current = style{}
+			}
+		}
+	}
+	add(text[last:])
+	return out
+}
+
+// assText writes cue text as the text of a dialogue event.
+func assText(text string) string {
+	var b strings.Builder
+	var current style
+	for _, r := range runs(text) {
+		for _, tag := range []struct {
+			name     string
+			from, to bool
+		}{
+			{"i", current.italic, r.style.italic},
+			{"b", current.bold, r.style.bold},
+			{"u", current.underline, r.style.underline},
+		} {
+			if tag.from != tag.to {
+				on := 0
+				if tag.to {
+					on = 1
+				}
+				fmt.Fprintf(&b, `{\%s%d}`, tag.name, on)
+			}
+		}
+		current = r.style
+		b.WriteString(strings.ReplaceAll(r.text, "\n", `\N`))
+	}
+	return b.String()
+}
+
+// MarshalASSEvents writes the cues as the dialogue events of an ASS script, the part
+// after [Events], for use with a header of script info and styles made elsewhere.
+func (s *Subtitles) MarshalASSEvents() []byte {
+	var b strings.Builder
+	b.WriteString(assEventFormat + "\n")
+	for _, cue := range s.Cues {
+		fmt.Fprintf(&b, "Dialogue: 0,%s,%s,Default,,0,0,0,,%s\n", assTime(cue.Start), assTime(cue.End), assText(cue.Text()))
+	}
+	return []byte(b.String())
+}
+
+// MarshalASS writes the subtitles as an Advanced SubStation Alpha script, in the
+// default style.
+func (s *Subtitles) MarshalASS() []byte {
+	return append([]byte(assHeader), s.MarshalASSEvents()...)
+}
@@ -0,0 +1,44 @@
+package subtitles
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseASS(t *testing.T) {
+	data := `[Script Info]
+Title: test
+
+[V4+ Styles]
+Format: Name, Fontname
+Style: Default,Arial
+
+[Events]
+Format: Layer, Start, End, Style, Name, MarginL, MarginR, MarginV, Effect, Text
+Comment: 0,0:00:00.00,0:00:01.00,Default,,0,0,0,,not shown
+Dialogue: 0,0:00:01.50,0:00:03.00,Default,,0,0,0,,{\an8\i1}Italic{\i0}, plain\Nsecond, line
+Dialogue: 0,1:00:00.00,1:00:01.25,Default,,0,0,0,,{\org(-2000000,0)\fr0.000110}First{\r}\Nsecond
+`
+
+	subs, err := ParseASS([]byte(data))
+
+	require.NoError(t, err)
+	assert.Equal(t, []Cue{
+		{Start: ms(1500), End: ms(3000), Lines: []string{"<i>Italic</i>, plain", "second, line"}},
+		{Start: ms(3600000), End: ms(3601250), Lines: []string{"First", "second"}},
+	}, subs.Cues)
+}
+
+func TestParseASS_NeedsEvents(t *testing.T) {
+	_, err := ParseASS([]byte("1\n00:00:01,000 --> 00:00:02,000\nSRT\n"))
+	assert.Error(t, err)
+}
+
+func TestMarshalASSEvents(t *testing.T) {
+	subs := &Subtitles{Cues: []Cue{{Start: ms(1505), End: ms(3723000), Lines: []string{"<i>one</i>", "<b>two</b>"}}}}
+
+	assert.Equal(t, assEventFormat+"\n"+
+		`Dialogue: 0,0:00:01.51,1:02:03.00,Default,,0,0,0,,{\i1}one{\i0}\N{\b1}two`+"\n", string(subs.MarshalASSEvents()))
+}
@@ -0,0 +1,102 @@
+package subtitles
+
+import (
+	"golang.org/x/text/unicode/norm"
+)
+
+// The text of EBU-STL files with character code table 00 is ISO/IEC 6937: ASCII, a
+// table of symbols and letters in 0xA0-0xFF, and accented letters written as the
+// accent, 0xC1-0xCF, followed by the letter.
+
+var iso6937Symbols = map[byte]rune{
+	0xA0: '\u00a0', 0xA1: '¡', 0xA2: '¢', 0xA3: '£', 0xA4: '$', 0xA5: '¥', 0xA6: '#', 0xA7: '§',
+	0xA8: '¤', 0xA9: '‘', 0xAA: '“', 0xAB: '«', 0xAC: '←', 0xAD: '↑', 0xAE: '→', 0xAF: '↓',
+	0xB0: '°', 0xB1: '±', 0xB2: '²', 0xB3: '³', 0xB4: '×', 0xB5: 'µ', 0xB6: '¶', 0xB7: '·',
+	0xB8: '÷', 0xB9: '’', 0xBA: '”', 0xBB: '»', 0xBC: '¼', 0xBD: '½', 0xBE: '¾', 0xBF: '¿',
+	0xD0: '―', 0xD1: '¹', 0xD2: '®', 0xD3: '©', 0xD4: '™', 0xD5: '♪', 0xD6: '¬', 0xD7: '¦',
+	0xDC: '⅛', 0xDD: '⅜', 0xDE: '⅝', 0xDF: '⅞',
+	0xE0: 'Ω', 0xE1: 'Æ', 0xE2: 'Đ', 0xE3: 'ª', 0xE4: 'Ħ', 0xE6: 'Ĳ', 0xE7: 'Ŀ', 0xE8: 'Ł',
+	0xE9: 'Ø', 0xEA: 'Œ', 0xEB: 'º', 0xEC: 'Þ', 0xED: 'Ŧ', 0xEE: 'Ŋ', 0xEF: 'ŉ',
+	0xF0: 'ĸ', 0xF1: 'æ', 0xF2: 'đ', 0xF3: 'ð', 0xF4: 'ħ', 0xF5: 'ı', 0xF6: 'ĳ', 0xF7: 'ŀ',
+	0xF8: 'ł', 0xF9: 'ø', 0xFA: 'œ', 0xFB: 'ß', 0xFC: 'þ', 0xFD: 'ŧ', 0xFE: 'ŋ', 0xFF: '\u00ad',
+}
+
+// iso6937Accents are the combining marks of the accent bytes.
+var iso6937Accents = map[byte]rune{
+	0xC1: '\u0300', // grave
+	0xC2: '\u0301', // acute
+	0xC3: '\u0302', // circumflex
+	0xC4: '\u0303', // tilde
+	0xC5: '\u0304', // macron
+	0xC6: '\u0306', // breve
+	0xC7: '\u0307', // dot
+	0xC8: '\u0308', // diaeresis
+	0xCA: '\u030a', // ring
+	0xCB: '\u0327', // cedilla
+	0xCD: '\u030b', // double acute
+	0xCE: '\u0328', // ogonek
+	0xCF: '\u030c', // caron
+}
+
+var (
+	iso6937SymbolBytes = map[rune]byte{}
+	iso6937AccentBytes = map[rune]byte{}
+)
+
+func init() {
+	for b, r := range iso6937Symbols {
+		// $ and # are written as in ASCII.
+		if r != '$' && r != '#' {
+			iso6937SymbolBytes[r] = b
+		}
+	}
+	for b, r := range iso6937Accents {
+		iso6937AccentBytes[r] = b
+	}
+}
+
+// decodeISO6937 reads the character at the start of data, and how many bytes it
+// takes. ok is false for bytes that are not characters.
+func decodeISO6937(data []byte) (s string, n int, ok bool) {
+	b := data[0]
+	if mark, accent := iso6937Accents[b]; accent {
+		if len(data) < 2 || data[1] < 0x20 || data[1] > 0x7E {
+			return "", 1, false
+		}
+		return norm.NFC.String(string(rune(data[1])) + string(mark)), 2, true
+	}
+	if r, symbol := iso6937Symbols[b]; symbol {
+		return string(r), 1, true
+	}
+	if b >= 0x20 && b <= 0x7E {
+		return string(rune(b)), 1, true
+	}
+	return "", 1, false
+}
+
+// encodeISO6937 writes text as ISO 6937. Characters it has no way of writing are
+// written as '?'.
+func encodeISO6937(text string) []byte {
+	var out []byte
+	for _, r := range norm.NFC.String(text) {
+		if r >= 0x20 && r <= 0x7E {
+			out = append(out, byte(r))
+			continue
+		}
+		if b, ok := iso6937SymbolBytes[r]; ok {
+			out = append(out, b)
+			continue
+		}
+
+		// An accented letter is its accent and the letter.
+		decomposed := []rune(norm.NFD.String(string(r)))
+		if len(decomposed) == 2 && decomposed[0] < 0x80 {
+			if accent, ok := iso6937AccentBytes[decomposed[1]]; ok {
+				out = append(out, accent, byte(decomposed[0]))
+				continue
+			}
+		}
+		out = append(out, '?')
+	}
+	return out
+}
@@ -0,0 +1,114 @@
+package subtitles
+
+import (
+	"regexp"
+	"strings"
+)
+
+// style is the inline styling a run of cue text has.
+type style struct {
+	italic    bool
+	bold      bool
+	underline bool
+}
+
+// run is text in one style. The text may span lines.
+type run struct {
+	text  string
+	style style
+}
+
+var tagPattern = regexp.MustCompile(`<(/?)([a-zA-Z][^\s>./]*)[^>]*>`)
+
+// runs splits cue text, lines joined by \n, into runs by the <i>, <b> and <u> tags it
+// has. Other tags are dropped.
+func runs(text string) []run {
+	var out []run
+	var current style
+	add := func(text string) {
+		if text == "" {
+			return
+		}
+		if n := len(out); n > 0 && out[n-1].style == current {
+			out[n-1].text += text
+			return
+		}
+		out = append(out, run{text: text, style: current})
+	}
+
+	last := 0
+	for _, match := range tagPattern.FindAllStringSubmatchIndex(text, -1) {
+		add(text[last:match[0]])
+		last = match[1]
+
+		on := match[2] == match[3]
+		switch strings.ToLower(text[match[4]:match[5]]) {
+		case "i":
+			current.italic = on
+		case "b":
+			current.bold = on
+		case "u":
+			current.underline = on
+		}
+	}
+	add(text[last:])
+	return out
+}
+
+// markup writes runs back as cue text, with the tags nested properly.
+func markup(runs []run) string {
+	var b strings.Builder
+	var open []string
+	has := func(tag string) bool {
+		for _, t := range open {
+			if t == tag {
+				return true
+			}
+		}
+		return false
+	}
+
+	for _, r := range runs {
+		want := map[string]bool{"i": r.style.italic, "b": r.style.bold, "u": r.style.underline}
+
+		// Close down to the first tag that is no longer wanted, and open again what
+		// was closed on the way that still is.
+		for i, tag := range open {
+			if want[tag] {
+				continue
+			}
+			for j := len(open) - 1; j >= i; j-- {
+				b.WriteString("</" + open[j] + ">")
+			}
+			open = open[:i]
+			break
+		}
+		for _, tag := range []string{"i", "b", "u"} {
+			if want[tag] && !has(tag) {
+				b.WriteString("<" + tag + ">")
+				open = append(open, tag)
+			}
+		}
+		b.WriteString(r.text)
+	}
+	for j := len(open) - 1; j >= 0; j-- {
+		b.WriteString("</" + open[j] + ">")
+	}
+	return b.String()
+}
+
+// lines is cue text split into its lines, each trimmed, without the empty ones.
+func lines(text string) []string {
+	var out []string
+	for _, line := range strings.Split(text, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			out = append(out, line)
+		}
+	}
+	return out
+}
+
+// plain is cue text without its tags.
+func plain(text string) string {
+	return tagPattern.ReplaceAllString(text, "")
+}
@@ -0,0 +1,308 @@
+package subtitles
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+// Policy is what subtitles that fail QC are: imported with a warning, or not imported.
+type Policy string
+
+const (
+	PolicyWarn  Policy = "warn"
+	PolicyBlock Policy = "block"
+)
+
+type Severity string
+
+const (
+	// SeverityError is a cue that breaks a rule; it blocks the import under
+	// PolicyBlock.
+	SeverityError Severity = "error"
+	// SeverityWarning is something to know of that is fixed on the way in.
+	SeverityWarning Severity = "warning"
+)
+
+// The rules an Issue can be for.
+const (
+	RuleEncoding     = "encoding"
+	RuleParse        = "parse"
+	RuleEmpty        = "empty"
+	RuleZeroLength   = "zero-length"
+	RuleOverlap      = "overlap"
+	RuleBeyondAsset  = "beyond-asset"
+	RuleReadingSpeed = "reading-speed"
+	RuleLineLength   = "line-length"
+	RuleLineCount    = "line-count"
+)
+
+// Rules are the limits subtitles of a language are checked against. Zero leaves a
+// limit unchecked.
+type Rules struct {
+	MaxCharsPerSecond float64 `json:"maxCharsPerSecond"`
+	MaxLineLength     int     `json:"maxLineLength"`
+	MaxLines          int     `json:"maxLines"`
+	Policy            Policy  `json:"policy"`
+}
+
+// DefaultRules are the limits for languages the QC configuration has nothing for.
+var DefaultRules = Rules{
+	MaxCharsPerSecond: 20,
+	MaxLineLength:     42,
+	MaxLines:          2,
+	Policy:            PolicyWarn,
+}
+
+// over is r, with the limits o sets replacing r's.
+func (r Rules) over(o Rules) Rules {
+	if o.MaxCharsPerSecond != 0 {
+		r.MaxCharsPerSecond = o.MaxCharsPerSecond
+	}
+	if o.MaxLineLength != 0 {
+		r.MaxLineLength = o.MaxLineLength
+	}
+	if o.MaxLines != 0 {
+		r.MaxLines = o.MaxLines
+	}
+	if o.Policy != "" {
+		r.Policy = o.Policy
+	}
+	return r
+}
+
+// QCConfig is the rules by language. A language's rules replace the default ones they
+// set, and leave the rest.
+type QCConfig struct {
+	Default   Rules            `json:"default"`
+	Languages map[string]Rules `json:"languages"`
+}
+
+// LoadQCConfig reads the rules from a JSON file.
+func LoadQCConfig(file string) (QCConfig, error) {
+	config := QCConfig{}
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return config, err
+	}
+	if err := json.Unmarshal(data, &config); err != nil {
+		return config, fmt.Errorf("%s: %w", file, err)
+	}
+
+	config.Default = DefaultRules.over(config.Default)
+	languages := map[string]Rules{}
+	for language, rules := range config.Languages {
+		languages[strings.ToLower(language)] = rules
+	}
+	config.Languages = languages
+
+	for language, rules := range languages {
+		if err := rules.validate(); err != nil {
+			return config, fmt.Errorf("%s: language %q: %w", file, language, err)
+		}
+	}
+	if err := config.Default.validate(); err != nil {
+		return config, fmt.Errorf("%s: default: %w", file, err)
+	}
+	return config, nil
+}
+
+func (r Rules) validate() error {
+	switch r.Policy {
+	case "", PolicyWarn, PolicyBlock:
+	default:
+		return fmt.Errorf("policy %q is not %q or %q", r.Policy, PolicyWarn, PolicyBlock)
+	}
+	if r.MaxCharsPerSecond < 0 || r.MaxLineLength < 0 || r.MaxLines < 0 {
+		return fmt.Errorf("limits must not be negative")
+	}
+	return nil
+}
+
+// RulesFor is the rules for a language, such as "nor" or "en".
+func (c QCConfig) RulesFor(language string) Rules {
+	rules := DefaultRules.over(c.Default)
+	if languageRules, ok := c.Languages[strings.ToLower(language)]; ok {
+		rules = rules.over(languageRules)
+	}
+	return rules
+}
+
+var (
+	qcLock   sync.RWMutex
+	qcConfig = QCConfig{Default: DefaultRules}
+)
+
+// ConfigureQC replaces the rules QCRulesFor has.
+func ConfigureQC(config QCConfig) {
+	qcLock.Lock()
+	defer qcLock.Unlock()
+	qcConfig = config
+}
+
+// QCRulesFor is the rules for a language, as configured.
+func QCRulesFor(language string) Rules {
+	qcLock.RLock()
+	defer qcLock.RUnlock()
+	return qcConfig.RulesFor(language)
+}
+
+type Issue struct {
+	Rule     string   `json:"rule"`
+	Severity Severity `json:"severity"`
+	// Cue is the number of the cue, from 1, or 0 for the file as a whole.
+	Cue     int    `json:"cue,omitempty"`
+	Time    string `json:"time,omitempty"`
+	Message string `json:"message"`
+}
+
+// Report is the outcome of checking one subtitle file.
+type Report struct {
+	Language string  `json:"language"`
+	File     string  `json:"file"`
+	Cues     int     `json:"cues"`
+	Policy   Policy  `json:"policy"`
+	Issues   []Issue `json:"issues"`
+	// Blocked is when the policy is to block, and there are errors.
+	Blocked bool `json:"blocked"`
+}
+
+// Errors is the number of issues that are errors.
+func (r Report) Errors() int {
+	n := 0
+	for _, issue := range r.Issues {
+		if issue.Severity == SeverityError {
+			n++
+		}
+	}
+	return n
+}
+
+// reportedIssues is how many issues Summary lists; the rest are counted.
+const reportedIssues = 10
+
+// Summary describes the report in a few lines, for a chat message.
+func (r Report) Summary() string {
+	var b strings.Builder
+	status := "passed"
+	switch {
+	case r.Blocked:
+		status = "blocked"
+	case len(r.Issues) > 0:
+		status = "imported with issues"
+	}
+	fmt.Fprintf(&b, "%s (%s): %s, %d cues, %d errors, %d warnings", r.Language, r.File, status, r.Cues, r.Errors(), len(r.Issues)-r.Errors())
+	for i, issue := range r.Issues {
+		if i == reportedIssues {
+			fmt.Fprintf(&b, "\n  … and %d more", len(r.Issues)-reportedIssues)
+			break
+		}
+		b.WriteString("\n  ")
+		if issue.Cue > 0 {
+			fmt.Fprintf(&b, "#%d %s ", issue.Cue, issue.Time)
+		}
+		fmt.Fprintf(&b, "%s: %s", issue.Rule, issue.Message)
+	}
+	return b.String()
+}
+
+// mojibakePattern is UTF-8 that was read as Latin-1 and written as UTF-8 again, such
+// as "Ã¸" for "ø".
+var mojibakePattern = regexp.MustCompile(`[ÂÃ][\x{80}-\x{BF}]`)
+
+// checkEncoding checks the bytes of a file before it is parsed.
+func checkEncoding(data []byte) []Issue {
+	var issues []Issue
+	if bytes.HasPrefix(data, []byte("\xef\xbb\xbf")) {
+		issues = append(issues, Issue{Rule: RuleEncoding, Severity: SeverityWarning, Message: "starts with a byte order mark"})
+	}
+	if !utf8.Valid(data) {
+		issues = append(issues, Issue{Rule: RuleEncoding, Severity: SeverityError, Message: "is not valid UTF-8"})
+		return issues
+	}
+	if bytes.ContainsRune(data, utf8.RuneError) {
+		issues = append(issues, Issue{Rule: RuleEncoding, Severity: SeverityError, Message: "has replacement characters (�) where text was lost"})
+	}
+	if match := mojibakePattern.Find(data); match != nil {
+		issues = append(issues, Issue{Rule: RuleEncoding, Severity: SeverityError, Message: fmt.Sprintf("has %q, UTF-8 that was encoded twice", match)})
+	}
+	return issues
+}
+
+// Validate checks a subtitle file in the format against the rules. duration is how
+// long the asset the subtitles are for is; zero leaves it unchecked.
+func Validate(data []byte, format Format, rules Rules, duration time.Duration) Report {
+	report := Report{Policy: rules.Policy}
+
+	// STL is not UTF-8.
+	if format != FormatSTL {
+		report.Issues = checkEncoding(data)
+	}
+
+	subs, err := Parse(data, format)
+	if err != nil {
+		report.Issues = append(report.Issues, Issue{Rule: RuleParse, Severity: SeverityError, Message: err.Error()})
+	} else {
+		report.Language = subs.Language
+		report.Cues = len(subs.Cues)
+		report.Issues = append(report.Issues, subs.check(rules, duration)...)
+	}
+
+	report.Blocked = rules.Policy == PolicyBlock && report.Errors() > 0
+	return report
+}
+
+// check checks the cues against the rules.
+func (s *Subtitles) check(rules Rules, duration time.Duration) []Issue {
+	if len(s.Cues) == 0 {
+		return []Issue{{Rule: RuleEmpty, Severity: SeverityError, Message: "has no cues"}}
+	}
+
+	var issues []Issue
+	add := func(i int, rule, format string, args ...any) {
+		issues = append(issues, Issue{
+			Rule:     rule,
+			Severity: SeverityError,
+			Cue:      i + 1,
+			Time:     clockTime(s.Cues[i].Start, "."),
+			Message:  fmt.Sprintf(format, args...),
+		})
+	}
+
+	for i, cue := range s.Cues {
+		length := cue.End - cue.Start
+		if length <= 0 {
+			add(i, RuleZeroLength, "ends at %s, not after it starts", clockTime(cue.End, "."))
+		}
+		if i > 0 && cue.Start < s.Cues[i-1].End {
+			add(i, RuleOverlap, "starts before cue %d ends at %s", i, clockTime(s.Cues[i-1].End, "."))
+		}
+		if duration > 0 && cue.End > duration {
+			add(i, RuleBeyondAsset, "ends at %s, after the asset ends at %s", clockTime(cue.End, "."), clockTime(duration, "."))
+		}
+
+		if rules.MaxLines > 0 && len(cue.Lines) > rules.MaxLines {
+			add(i, RuleLineCount, "has %d lines, more than %d", len(cue.Lines), rules.MaxLines)
+		}
+		characters := 0
+		for _, line := range cue.Lines {
+			n := utf8.RuneCountInString(plain(line))
+			characters += n
+			if rules.MaxLineLength > 0 && n > rules.MaxLineLength {
+				add(i, RuleLineLength, "has a line of %d characters, more than %d", n, rules.MaxLineLength)
+			}
+		}
+		if rules.MaxCharsPerSecond > 0 && length > 0 {
+			if cps := float64(characters) / length.Seconds(); cps > rules.MaxCharsPerSecond {
+				add(i, RuleReadingSpeed, "is %.1f characters per second, more than %g", cps, rules.MaxCharsPerSecond)
+			}
+		}
+	}
+	return issues
+}
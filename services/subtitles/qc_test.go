@@ -0,0 +1,124 @@
+package subtitles
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func rules(report Report) []string {
+	var out []string
+	for _, issue := range report.Issues {
+		out = append(out, issue.Rule)
+	}
+	return out
+}
+
+func TestValidate_Clean(t *testing.T) {
+	data := "1\n00:00:01,000 --> 00:00:03,000\nGod morgen\n\n2\n00:00:04,000 --> 00:00:06,000\n<i>Velkommen</i>\n"
+
+	report := Validate([]byte(data), FormatSRT, DefaultRules, ms(10000))
+
+	assert.Equal(t, 2, report.Cues)
+	assert.Empty(t, report.Issues)
+	assert.False(t, report.Blocked)
+}
+
+func TestValidate_Timing(t *testing.T) {
+	data := "1\n00:00:01,000 --> 00:00:03,000\nOne\n\n" +
+		"2\n00:00:02,500 --> 00:00:04,000\nOverlaps\n\n" +
+		"3\n00:00:05,000 --> 00:00:05,000\nZero\n\n" +
+		"4\n00:00:09,000 --> 00:00:11,000\nPast the end\n"
+
+	report := Validate([]byte(data), FormatSRT, DefaultRules, ms(10000))
+
+	assert.Equal(t, []string{RuleOverlap, RuleZeroLength, RuleBeyondAsset}, rules(report))
+	assert.Equal(t, 2, report.Issues[0].Cue)
+	assert.Equal(t, "00:00:02.500", report.Issues[0].Time)
+}
+
+func TestValidate_Text(t *testing.T) {
+	data := "1\n00:00:01,000 --> 00:00:02,000\n<i>Dette er en altfor lang linje for en undertekst</i>\n\n" +
+		"2\n00:00:03,000 --> 00:00:06,000\nEn\nto\ntre\n"
+
+	report := Validate([]byte(data), FormatSRT, DefaultRules, 0)
+
+	assert.Equal(t, []string{RuleLineLength, RuleReadingSpeed, RuleLineCount}, rules(report))
+	assert.Contains(t, report.Issues[0].Message, "47 characters", "the tags are not counted")
+}
+
+func TestValidate_Encoding(t *testing.T) {
+	bom := Validate([]byte("\ufeff1\n00:00:01,000 --> 00:00:02,000\nHei\n"), FormatSRT, DefaultRules, 0)
+	assert.Equal(t, []string{RuleEncoding}, rules(bom))
+	assert.Equal(t, SeverityWarning, bom.Issues[0].Severity)
+	assert.Equal(t, 0, bom.Errors())
+
+	latin1 := Validate([]byte("1\n00:00:01,000 --> 00:00:02,000\nP\xe5 tide\n"), FormatSRT, DefaultRules, 0)
+	assert.Equal(t, RuleEncoding, latin1.Issues[0].Rule)
+	assert.Contains(t, latin1.Issues[0].Message, "UTF-8")
+
+	twice := Validate([]byte("1\n00:00:01,000 --> 00:00:02,000\nFÃ¸rste\n"), FormatSRT, DefaultRules, 0)
+	assert.Equal(t, []string{RuleEncoding}, rules(twice))
+	assert.Contains(t, twice.Issues[0].Message, "encoded twice")
+}
+
+func TestValidate_Unreadable(t *testing.T) {
+	assert.Equal(t, []string{RuleParse}, rules(Validate([]byte("not a subtitle\n"), FormatSRT, DefaultRules, 0)))
+	assert.Equal(t, []string{RuleEmpty}, rules(Validate([]byte("WEBVTT\n"), FormatVTT, DefaultRules, 0)))
+}
+
+func TestValidate_Policy(t *testing.T) {
+	data := []byte("1\n00:00:01,000 --> 00:00:01,000\nZero\n")
+
+	warn := Validate(data, FormatSRT, DefaultRules, 0)
+	assert.False(t, warn.Blocked)
+
+	block := DefaultRules
+	block.Policy = PolicyBlock
+	assert.True(t, Validate(data, FormatSRT, block, 0).Blocked)
+
+	bom := Validate([]byte("\ufeff1\n00:00:01,000 --> 00:00:02,000\nHei\n"), FormatSRT, block, 0)
+	assert.False(t, bom.Blocked, "warnings do not block")
+}
+
+func TestLoadQCConfig(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "qc.json")
+	require.NoError(t, os.WriteFile(file, []byte(`{
+		"default": {"maxCharsPerSecond": 17},
+		"languages": {
+			"NOR": {"policy": "block"},
+			"de": {"maxLineLength": 50, "policy": "warn"}
+		}
+	}`), 0644))
+
+	config, err := LoadQCConfig(file)
+	require.NoError(t, err)
+
+	assert.Equal(t, Rules{MaxCharsPerSecond: 17, MaxLineLength: 42, MaxLines: 2, Policy: PolicyBlock}, config.RulesFor("nor"))
+	assert.Equal(t, Rules{MaxCharsPerSecond: 17, MaxLineLength: 50, MaxLines: 2, Policy: PolicyWarn}, config.RulesFor("de"))
+	assert.Equal(t, Rules{MaxCharsPerSecond: 17, MaxLineLength: 42, MaxLines: 2, Policy: PolicyWarn}, config.RulesFor("en"))
+}
+
+func TestLoadQCConfig_UnknownPolicy(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "qc.json")
+	require.NoError(t, os.WriteFile(file, []byte(`{"languages": {"nor": {"policy": "reject"}}}`), 0644))
+
+	_, err := LoadQCConfig(file)
+	assert.ErrorContains(t, err, `"reject"`)
+}
+
+func TestReport_Summary(t *testing.T) {
+	report := Report{Language: "nor", File: "sub_nor.srt", Cues: 12, Policy: PolicyBlock, Blocked: true}
+	for i := 0; i < 12; i++ {
+		report.Issues = append(report.Issues, Issue{Rule: RuleOverlap, Severity: SeverityError, Cue: i + 1, Time: "00:00:01.000", Message: "starts early"})
+	}
+
+	summary := report.Summary()
+
+	assert.Contains(t, summary, "nor (sub_nor.srt): blocked, 12 cues, 12 errors, 0 warnings")
+	assert.Contains(t, summary, "#1 00:00:01.000 overlap: starts early")
+	assert.Contains(t, summary, "… and 2 more")
+}
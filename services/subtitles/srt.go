@@ -0,0 +1,108 @@
+package subtitles
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// timingPattern is a cue timing line of SRT or WebVTT. Hours are optional in WebVTT,
+// and SRT files in the wild use either separator before the milliseconds.
+var timingPattern = regexp.MustCompile(`^\s*((?:\d+:)?\d{1,2}:\d{1,2}[,.]\d+)\s*-->\s*((?:\d+:)?\d{1,2}:\d{1,2}[,.]\d+)(.*)$`)
+
+// parseClock parses [hh:]mm:ss,fff or [hh:]mm:ss.fff.
+func parseClock(s string) (time.Duration, error) {
+	s = strings.Replace(s, ",", ".", 1)
+	parts := strings.Split(s, ":")
+	if len(parts) < 2 || len(parts) > 3 {
+		return 0, fmt.Errorf("invalid timestamp %q", s)
+	}
+
+	seconds, err := strconv.ParseFloat(parts[len(parts)-1], 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid timestamp %q", s)
+	}
+	total := time.Duration(seconds * float64(time.Second))
+
+	units := []time.Duration{time.Minute, time.Hour}
+	for i, part := range parts[:len(parts)-1] {
+		n, err := strconv.Atoi(part)
+		if err != nil {
+			return 0, fmt.Errorf("invalid timestamp %q", s)
+		}
+		total += time.Duration(n) * units[len(parts)-2-i]
+	}
+	return total.Round(time.Millisecond), nil
+}
+
+// ParseSRT parses SubRip subtitles. The cue numbers are not needed, and not checked.
+func ParseSRT(data []byte) (*Subtitles, error) {
+	subs := &Subtitles{}
+	var cue *Cue
+	var text []string
+	flush := func() {
+		if cue != nil {
+			cue.Lines = lines(markup(runs(strings.Join(text, "\n"))))
+			subs.Cues = append(subs.Cues, *cue)
+		}
+		cue, text = nil, nil
+	}
+
+	all := strings.Split(normalize(data), "\n")
+	for i, line := range all {
+		if match := timingPattern.FindStringSubmatch(line); match != nil {
+			// The line before the timing is the cue number, and not text of the cue
+			// before, even when the blank line between them is missing.
+			if len(text) > 0 && isCueNumber(text[len(text)-1]) {
+				text = text[:len(text)-1]
+			}
+			flush()
+
+			start, err := parseClock(match[1])
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %w", i+1, err)
+			}
+			end, err := parseClock(match[2])
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %w", i+1, err)
+			}
+			cue = &Cue{Start: start, End: end}
+			continue
+		}
+
+		if cue == nil {
+			if strings.TrimSpace(line) != "" && !isCueNumber(line) {
+				return nil, fmt.Errorf("line %d: text before the first cue timing", i+1)
+			}
+			continue
+		}
+		if strings.TrimSpace(line) == "" {
+			// A blank line ends the cue text, but the cue is kept until the next timing,
+			// as some files have blank lines within cues.
+			if len(text) > 0 && text[len(text)-1] != "" {
+				text = append(text, "")
+			}
+			continue
+		}
+		text = append(text, line)
+	}
+	flush()
+
+	return subs, nil
+}
+
+func isCueNumber(line string) bool {
+	_, err := strconv.Atoi(strings.TrimSpace(line))
+	return err == nil
+}
+
+// MarshalSRT writes the subtitles as SubRip, numbering the cues from 1.
+func (s *Subtitles) MarshalSRT() []byte {
+	var b strings.Builder
+	for i, cue := range s.Cues {
+		fmt.Fprintf(&b, "%d\n%s --> %s\n%s\n\n", i+1, clockTime(cue.Start, ","), clockTime(cue.End, ","), cue.Text())
+	}
+	return []byte(b.String())
+}
@@ -0,0 +1,40 @@
+package subtitles
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Subtrans and editors write SRT with byte order marks, CRLF line endings, dots for
+// commas and the odd missing blank line.
+func TestParseSRT_AsFoundInTheWild(t *testing.T) {
+	data := "\ufeff1\r\n00:00:01,000 --> 00:00:02,500\r\nFirst line\r\n<font color=\"#ffff00\">second</font> line\r\n" +
+		"2\r\n00:00:03.000 --> 00:00:04.250 X1:10 X2:20\r\n<i>Italic</i>\r\n\r\n\r\n" +
+		"3\r\n1:02:03,4 --> 1:02:05,000\r\nLast\r\n"
+
+	subs, err := ParseSRT([]byte(data))
+
+	require.NoError(t, err)
+	assert.Equal(t, []Cue{
+		{Start: ms(1000), End: ms(2500), Lines: []string{"First line", "second line"}},
+		{Start: ms(3000), End: ms(4250), Lines: []string{"<i>Italic</i>"}},
+		{Start: ms(3723400), End: ms(3725000), Lines: []string{"Last"}},
+	}, subs.Cues)
+}
+
+func TestParseSRT_TextBeforeTheFirstCue(t *testing.T) {
+	_, err := ParseSRT([]byte("not a subtitle\n"))
+	assert.ErrorContains(t, err, "line 1")
+}
+
+func TestMarshalSRT(t *testing.T) {
+	subs := &Subtitles{Cues: []Cue{
+		{Start: ms(1000), End: ms(2500), Lines: []string{"One", "<i>two</i>"}},
+		{Start: ms(3723400), End: ms(3725000), Lines: []string{"Three"}},
+	}}
+
+	assert.Equal(t, "1\n00:00:01,000 --> 00:00:02,500\nOne\n<i>two</i>\n\n"+
+		"2\n01:02:03,400 --> 01:02:05,000\nThree\n\n", string(subs.MarshalSRT()))
+}
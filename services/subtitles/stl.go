@@ -0,0 +1,331 @@
+package subtitles
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// EBU-STL (EBU Tech 3264) is a 1024 byte General Subtitle Information block followed
+// by 128 byte Text and Timing Information blocks, one or more per cue.
+const (
+	stlGSISize  = 1024
+	stlTTISize  = 128
+	stlTextSize = 112
+
+	// The control codes of the text field.
+	stlItalicOn     = 0x80
+	stlItalicOff    = 0x81
+	stlUnderlineOn  = 0x82
+	stlUnderlineOff = 0x83
+	stlNewline      = 0x8A
+	stlUnused       = 0x8F
+
+	// stlLastBlock is the extension block number of the last block of a cue, and
+	// stlUserData that of blocks that are not text.
+	stlLastBlock = 0xFF
+	stlUserData  = 0xFE
+
+	// stlFrameRate is what STL files are written with.
+	stlFrameRate = 25
+)
+
+// stlLanguages are the EBU language codes, and the ISO 639 codes they are for. The
+// first is what the language of a file with the code is taken to be.
+var stlLanguages = map[byte][]string{
+	0x04: {"hr", "hrv"},
+	0x06: {"cs", "ces", "cze"},
+	0x07: {"da", "dan"},
+	0x08: {"de", "deu", "ger"},
+	0x09: {"en", "eng"},
+	0x0A: {"es", "spa"},
+	0x0C: {"et", "est"},
+	0x0F: {"fr", "fra", "fre"},
+	0x14: {"is", "isl", "ice"},
+	0x15: {"it", "ita"},
+	0x18: {"lv", "lav"},
+	0x1A: {"lt", "lit"},
+	0x1B: {"hu", "hun"},
+	0x1D: {"nl", "nld", "dut"},
+	0x1E: {"no", "nb", "nn", "nor", "nob", "nno"},
+	0x20: {"pl", "pol"},
+	0x21: {"pt", "por"},
+	0x22: {"ro", "ron", "rum"},
+	0x24: {"sr", "srp"},
+	0x25: {"sk", "slk", "slo"},
+	0x26: {"sl", "slv"},
+	0x27: {"fi", "fin"},
+	0x28: {"sv", "swe"},
+	0x29: {"tr", "tur"},
+	0x49: {"uk", "ukr"},
+	0x56: {"ru", "rus"},
+	0x69: {"ja", "jpn"},
+	0x70: {"el", "ell", "gre"},
+	0x75: {"zh", "zho", "chi"},
+	0x77: {"bg", "bul"},
+}
+
+func stlLanguageCode(language string) string {
+	language = strings.ToLower(language)
+	if primary, _, found := strings.Cut(language, "-"); found {
+		language = primary
+	}
+	for code, languages := range stlLanguages {
+		for _, l := range languages {
+			if l == language {
+				return fmt.Sprintf("%02X", code)
+			}
+		}
+	}
+	return "00"
+}
+
+// stlTimecode is a timecode of four bytes: hours, minutes, seconds and frames.
+func stlTimecode(data []byte, fps float64) time.Duration {
+	seconds := float64(data[0])*3600 + float64(data[1])*60 + float64(data[2]) + float64(data[3])/fps
+	return seconds2duration(seconds)
+}
+
+// stlFrames splits a duration into hours, minutes, seconds and frames.
+func stlFrames(d time.Duration) [4]byte {
+	frames := int64(math.Round(max(d, 0).Seconds() * stlFrameRate))
+	seconds := frames / stlFrameRate
+	return [4]byte{
+		byte(seconds / 3600),
+		byte(seconds / 60 % 60),
+		byte(seconds % 60),
+		byte(frames % stlFrameRate),
+	}
+}
+
+// ParseSTL parses EBU-STL with the Latin character code table. Times are taken
+// relative to the start of programme of the file, unless cues start before it.
+func ParseSTL(data []byte) (*Subtitles, error) {
+	if len(data) < stlGSISize || (len(data)-stlGSISize)%stlTTISize != 0 {
+		return nil, fmt.Errorf("%d bytes is not a GSI block and whole TTI blocks", len(data))
+	}
+	gsi := data[:stlGSISize]
+
+	var fps float64
+	switch string(gsi[3:11]) {
+	case "STL25.01":
+		fps = 25
+	case "STL30.01":
+		fps = 30
+	default:
+		return nil, fmt.Errorf("unsupported disk format code %q", gsi[3:11])
+	}
+	if cct := string(gsi[12:14]); cct != "00" {
+		return nil, fmt.Errorf("unsupported character code table %q", cct)
+	}
+
+	subs := &Subtitles{}
+	if code, err := strconv.ParseUint(string(gsi[14:16]), 16, 8); err == nil {
+		if languages, ok := stlLanguages[byte(code)]; ok {
+			subs.Language = languages[0]
+		}
+	}
+
+	var text []byte
+	for offset := stlGSISize; offset < len(data); offset += stlTTISize {
+		tti := data[offset : offset+stlTTISize]
+		ebn, comment := tti[3], tti[15] == 1
+		if ebn == stlUserData || comment {
+			continue
+		}
+
+		text = append(text, bytes.TrimRight(tti[16:], string([]byte{stlUnused}))...)
+		if ebn != stlLastBlock {
+			continue
+		}
+
+		subs.Cues = append(subs.Cues, Cue{
+			Start: stlTimecode(tti[5:9], fps),
+			End:   stlTimecode(tti[9:13], fps),
+			Lines: lines(markup(stlRuns(text))),
+		})
+		text = nil
+	}
+
+	// Cues are timed from the start of programme, which often is 10:00:00:00.
+	if programme, err := parseSTLTimecode(string(gsi[256:264]), fps); err == nil && programme > 0 {
+		for _, cue := range subs.Cues {
+			if cue.Start < programme {
+				return subs, nil
+			}
+		}
+		subs = subs.Shift(-programme)
+	}
+
+	return subs, nil
+}
+
+// parseSTLTimecode parses a GSI timecode, HHMMSSFF.
+func parseSTLTimecode(tc string, fps float64) (time.Duration, error) {
+	if len(tc) != 8 {
+		return 0, errors.New("invalid timecode")
+	}
+	var parts [4]byte
+	for i := range parts {
+		n, err := strconv.Atoi(tc[i*2 : i*2+2])
+		if err != nil {
+			return 0, err
+		}
+		parts[i] = byte(n)
+	}
+	return stlTimecode(parts[:], fps), nil
+}
+
+// stlRuns reads the text field of a cue. Teletext control codes take a position on
+// screen, and are read as spaces.
+func stlRuns(text []byte) []run {
+	var out []run
+	var current style
+	var b strings.Builder
+	flush := func() {
+		if b.Len() > 0 {
+			out = append(out, run{text: b.String(), style: current})
+			b.Reset()
+		}
+	}
+
+	for i := 0; i < len(text); {
+		c := text[i]
+		switch {
+		case c == stlItalicOn || c == stlItalicOff:
+			flush()
+			current.italic = c == stlItalicOn
+		case c == stlUnderlineOn || c == stlUnderlineOff:
+			flush()
+			current.underline = c == stlUnderlineOn
+		case c == stlNewline:
+			b.WriteString("\n")
+		case c < 0x20:
+			if s := b.String(); s != "" && !strings.HasSuffix(s, " ") && !strings.HasSuffix(s, "\n") {
+				b.WriteString(" ")
+			}
+		default:
+			s, n, ok := decodeISO6937(text[i:])
+			if ok {
+				b.WriteString(s)
+			}
+			i += n
+			continue
+		}
+		i++
+	}
+	flush()
+	return out
+}
+
+// stlText writes cue text as a text field: ISO 6937, with the codes for italics and
+// underlining, and the lines separated by newlines.
+func stlText(text string) []byte {
+	var out []byte
+	var current style
+	for _, r := range runs(text) {
+		if r.style.italic && !current.italic {
+			out = append(out, stlItalicOn)
+		} else if !r.style.italic && current.italic {
+			out = append(out, stlItalicOff)
+		}
+		if r.style.underline && !current.underline {
+			out = append(out, stlUnderlineOn)
+		} else if !r.style.underline && current.underline {
+			out = append(out, stlUnderlineOff)
+		}
+		current = r.style
+
+		for i, line := range strings.Split(r.text, "\n") {
+			if i > 0 {
+				out = append(out, stlNewline)
+			}
+			out = append(out, encodeISO6937(line)...)
+		}
+	}
+	return out
+}
+
+// stlNow is when STL files are created, in their GSI block.
+var stlNow = time.Now
+
+// MarshalSTL writes the subtitles as EBU-STL for teletext at 25 frames per second,
+// timed from 00:00:00:00. Bold is not kept, as STL has no code for it.
+func (s *Subtitles) MarshalSTL() ([]byte, error) {
+	var ttis []byte
+	blocks := 0
+
+	for i, cue := range s.Cues {
+		if i > math.MaxUint16 {
+			return nil, fmt.Errorf("STL has room for %d cues, not %d", math.MaxUint16+1, len(s.Cues))
+		}
+		text := stlText(cue.Text())
+
+		// Text that does not fit one block continues in extension blocks.
+		for ebn := 0; ebn == 0 || len(text) > 0; ebn++ {
+			if ebn >= stlUserData {
+				return nil, fmt.Errorf("cue %d has more text than STL has room for", i+1)
+			}
+			chunk := text[:min(len(text), stlTextSize)]
+			text = text[len(chunk):]
+
+			tti := make([]byte, stlTTISize)
+			binary.LittleEndian.PutUint16(tti[1:3], uint16(i))
+			tti[3] = byte(ebn)
+			if len(text) == 0 {
+				tti[3] = stlLastBlock
+			}
+			start, end := stlFrames(cue.Start), stlFrames(cue.End)
+			copy(tti[5:9], start[:])
+			copy(tti[9:13], end[:])
+			// Teletext rows count from the top, and the cue ends on row 22, two rows
+			// apart for double height.
+			tti[13] = byte(max(1, 22-2*(len(cue.Lines)-1)))
+			tti[14] = 2 // centred
+			copy(tti[16:], chunk)
+			for j := 16 + len(chunk); j < stlTTISize; j++ {
+				tti[j] = stlUnused
+			}
+
+			ttis = append(ttis, tti...)
+			blocks++
+		}
+	}
+
+	gsi := bytes.Repeat([]byte{' '}, stlGSISize)
+	field := func(offset int, value string) {
+		copy(gsi[offset:], value)
+	}
+	now := stlNow().Format("060102")
+	firstCue := "00000000"
+	if len(s.Cues) > 0 {
+		tc := stlFrames(s.Cues[0].Start)
+		firstCue = fmt.Sprintf("%02d%02d%02d%02d", tc[0], tc[1], tc[2], tc[3])
+	}
+
+	field(0, "850")                              // code page
+	field(3, "STL25.01")                         // disk format
+	field(11, "1")                               // teletext level 1
+	field(12, "00")                              // Latin, ISO 6937
+	field(14, stlLanguageCode(s.Language))       // language
+	field(224, now)                              // created
+	field(230, now)                              // revised
+	field(236, "00")                             // revision
+	field(238, fmt.Sprintf("%05d", blocks))      // TTI blocks
+	field(243, fmt.Sprintf("%05d", len(s.Cues))) // subtitles
+	field(248, "001")                            // subtitle groups
+	field(251, "40")                             // characters per row
+	field(253, "23")                             // rows
+	field(255, "1")                              // timecode status: for use
+	field(256, "00000000")                       // start of programme
+	field(264, firstCue)
+	field(272, "1") // disks
+	field(273, "1") // disk sequence
+
+	return append(gsi, ttis...), nil
+}
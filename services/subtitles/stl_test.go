@@ -0,0 +1,97 @@
+package subtitles
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMarshalSTL(t *testing.T) {
+	stlNow = func() time.Time { return time.Date(2026, 3, 14, 0, 0, 0, 0, time.UTC) }
+	t.Cleanup(func() { stlNow = time.Now })
+	subs := &Subtitles{Language: "nor", Cues: []Cue{
+		{Start: ms(10040), End: ms(12000), Lines: []string{"Blåbær og <i>øl</i>", "Ære være Gud"}},
+	}}
+
+	data, err := subs.MarshalSTL()
+
+	require.NoError(t, err)
+	require.Len(t, data, stlGSISize+stlTTISize)
+	gsi, tti := data[:stlGSISize], data[stlGSISize:]
+	assert.Equal(t, "850STL25.01100", string(gsi[:14]))
+	assert.Equal(t, "1E", string(gsi[14:16]), "Norwegian")
+	assert.Equal(t, "260314", string(gsi[224:230]))
+	assert.Equal(t, "00001", string(gsi[238:243]))
+	assert.Equal(t, "00001001", string(gsi[264:272]), "the first cue")
+
+	assert.Equal(t, byte(stlLastBlock), tti[3])
+	assert.Equal(t, []byte{0, 0, 10, 1}, tti[5:9])
+	assert.Equal(t, []byte{0, 0, 12, 0}, tti[9:13])
+	text := []byte("Bl\xcaab\xf1r og \x80\xf9l\x81\x8a\xe1re v\xf1re Gud")
+	assert.Equal(t, text, tti[16:16+len(text)])
+	assert.Equal(t, byte(stlUnused), tti[127])
+}
+
+// Text longer than a block continues in extension blocks, and is read back whole.
+func TestSTL_ExtensionBlocks(t *testing.T) {
+	long := strings.Repeat("lang tekst ", 15)
+	subs := &Subtitles{Cues: []Cue{
+		{Start: ms(1000), End: ms(2000), Lines: []string{strings.TrimSpace(long)}},
+		{Start: ms(3000), End: ms(4000), Lines: []string{"kort"}},
+	}}
+
+	data, err := subs.MarshalSTL()
+	require.NoError(t, err)
+	require.Len(t, data, stlGSISize+3*stlTTISize)
+	assert.Equal(t, byte(0), data[stlGSISize+3])
+	assert.Equal(t, byte(stlLastBlock), data[stlGSISize+stlTTISize+3])
+
+	parsed, err := ParseSTL(data)
+	require.NoError(t, err)
+	assert.Equal(t, subs.Cues, parsed.Cues)
+}
+
+// Files from broadcast are usually timed from 10:00:00:00, the start of programme.
+func TestParseSTL_TimedFromTheStartOfProgramme(t *testing.T) {
+	subs := &Subtitles{Cues: []Cue{{Start: 10*time.Hour + ms(1000), End: 10*time.Hour + ms(2000), Lines: []string{"tekst"}}}}
+	data, err := subs.MarshalSTL()
+	require.NoError(t, err)
+	copy(data[256:264], "10000000")
+
+	parsed, err := ParseSTL(data)
+
+	require.NoError(t, err)
+	assert.Equal(t, ms(1000), parsed.Cues[0].Start)
+}
+
+func TestParseSTL_TeletextControlCodes(t *testing.T) {
+	data, err := (&Subtitles{Language: "de", Cues: []Cue{{Start: ms(1000), End: ms(2000), Lines: []string{"x"}}}}).MarshalSTL()
+	require.NoError(t, err)
+	tti := data[stlGSISize:]
+	field := append([]byte("\x0d\x0b\x0bStart\x07gelb\x8a\x8a\x0dzweite"), []byte(strings.Repeat("\x8f", 100))...)
+	copy(tti[16:], field)
+	// A comment block is not a cue.
+	comment := make([]byte, stlTTISize)
+	copy(comment, tti)
+	comment[15] = 1
+	data = append(data, comment...)
+
+	parsed, err := ParseSTL(data)
+
+	require.NoError(t, err)
+	assert.Equal(t, "de", parsed.Language)
+	assert.Equal(t, []Cue{{Start: ms(1000), End: ms(2000), Lines: []string{"Start gelb", "zweite"}}}, parsed.Cues)
+}
+
+func TestParseSTL_Errors(t *testing.T) {
+	_, err := ParseSTL(make([]byte, 1000))
+	assert.Error(t, err)
+
+	data, _ := (&Subtitles{}).MarshalSTL()
+	copy(data[12:14], "01")
+	_, err = ParseSTL(data)
+	assert.ErrorContains(t, err, "character code table")
+}
@@ -0,0 +1,193 @@
+// Package subtitles reads and writes subtitle files. Every format is parsed into the
+// same cues, so converting is parsing one format and writing another; the timing of
+// the cues is kept as it is, unless it is shifted on purpose.
+//
+// Cue text is plain text with the inline styling all the formats have in common:
+// <i>, <b> and <u>, the way SRT writes them.
+package subtitles
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+type Format string
+
+const (
+	FormatSRT  Format = "srt"
+	FormatVTT  Format = "vtt"
+	FormatTTML Format = "ttml"
+	FormatSTL  Format = "stl"
+	FormatASS  Format = "ass"
+)
+
+// Formats are the formats that can be both read and written.
+var Formats = []Format{FormatSRT, FormatVTT, FormatTTML, FormatSTL, FormatASS}
+
+// Cue is one subtitle: the lines shown from Start until End.
+type Cue struct {
+	Start time.Duration
+	End   time.Duration
+	Lines []string
+}
+
+// Text is the lines of the cue, one per line.
+func (c Cue) Text() string {
+	return strings.Join(c.Lines, "\n")
+}
+
+type Subtitles struct {
+	// Language is an ISO 639 code, when the format carries one.
+	Language string
+	Cues     []Cue
+}
+
+// ParseFormat is the format for a name or a file extension, such as "vtt", ".dfxp"
+// or "IMSC".
+func ParseFormat(name string) (Format, error) {
+	switch strings.ToLower(strings.TrimPrefix(name, ".")) {
+	case "srt":
+		return FormatSRT, nil
+	case "vtt", "webvtt":
+		return FormatVTT, nil
+	case "ttml", "dfxp", "xml", "imsc", "imsc1":
+		return FormatTTML, nil
+	case "stl", "ebu-stl":
+		return FormatSTL, nil
+	case "ass", "ssa":
+		return FormatASS, nil
+	}
+	return "", fmt.Errorf("unsupported subtitle format %q", name)
+}
+
+// Ext is the file extension files of the format are written with.
+func (f Format) Ext() string {
+	return "." + string(f)
+}
+
+// Parse parses subtitles in the format.
+func Parse(data []byte, format Format) (*Subtitles, error) {
+	var subs *Subtitles
+	var err error
+	switch format {
+	case FormatSRT:
+		subs, err = ParseSRT(data)
+	case FormatVTT:
+		subs, err = ParseVTT(data)
+	case FormatTTML:
+		subs, err = ParseTTML(data)
+	case FormatSTL:
+		subs, err = ParseSTL(data)
+	case FormatASS:
+		subs, err = ParseASS(data)
+	default:
+		return nil, fmt.Errorf("unsupported subtitle format %q", format)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", format, err)
+	}
+	subs.sort()
+	return subs, nil
+}
+
+// Marshal writes the subtitles in the format.
+func (s *Subtitles) Marshal(format Format) ([]byte, error) {
+	switch format {
+	case FormatSRT:
+		return s.MarshalSRT(), nil
+	case FormatVTT:
+		return s.MarshalVTT(), nil
+	case FormatTTML:
+		return s.MarshalTTML(), nil
+	case FormatSTL:
+		return s.MarshalSTL()
+	case FormatASS:
+		return s.MarshalASS(), nil
+	}
+	return nil, fmt.Errorf("unsupported subtitle format %q", format)
+}
+
+// ReadFile parses a subtitle file in the format its extension names.
+func ReadFile(path string) (*Subtitles, error) {
+	format, err := ParseFormat(filepath.Ext(path))
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return Parse(data, format)
+}
+
+// WriteFile writes the subtitles to path in the format.
+func (s *Subtitles) WriteFile(path string, format Format, perm os.FileMode) error {
+	data, err := s.Marshal(format)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, perm)
+}
+
+// Shift moves every cue by offset, which may be negative. Cues that would end before
+// the start are dropped, and ones that would start before it start at it.
+func (s *Subtitles) Shift(offset time.Duration) *Subtitles {
+	out := &Subtitles{Language: s.Language}
+	for _, cue := range s.Cues {
+		cue.Start += offset
+		cue.End += offset
+		if cue.End <= 0 {
+			continue
+		}
+		cue.Start = max(cue.Start, 0)
+		out.Cues = append(out.Cues, cue)
+	}
+	return out
+}
+
+// sort orders the cues by when they start, and drops the ones with nothing to show.
+func (s *Subtitles) sort() {
+	cues := s.Cues[:0]
+	for _, cue := range s.Cues {
+		var lines []string
+		for _, line := range cue.Lines {
+			if strings.TrimSpace(line) != "" {
+				lines = append(lines, line)
+			}
+		}
+		if len(lines) == 0 {
+			continue
+		}
+		cue.Lines = lines
+		cues = append(cues, cue)
+	}
+	sort.SliceStable(cues, func(i, j int) bool { return cues[i].Start < cues[j].Start })
+	s.Cues = cues
+}
+
+// splitTime splits a duration into its hours, minutes, seconds and milliseconds.
+func splitTime(d time.Duration) (h, m, s, ms int64) {
+	d = max(d, 0).Round(time.Millisecond)
+	ms = d.Milliseconds()
+	h, ms = ms/3600000, ms%3600000
+	m, ms = ms/60000, ms%60000
+	s, ms = ms/1000, ms%1000
+	return h, m, s, ms
+}
+
+// clockTime is hh:mm:ss followed by the milliseconds after sep.
+func clockTime(d time.Duration, sep string) string {
+	h, m, s, ms := splitTime(d)
+	return fmt.Sprintf("%02d:%02d:%02d%s%03d", h, m, s, sep, ms)
+}
+
+// normalize drops a byte order mark and makes every line end in \n.
+func normalize(data []byte) string {
+	text := strings.TrimPrefix(string(data), "\ufeff")
+	text = strings.ReplaceAll(text, "\r\n", "\n")
+	return strings.ReplaceAll(text, "\r", "\n")
+}
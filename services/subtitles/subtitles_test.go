@@ -0,0 +1,112 @@
+package subtitles
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func ms(n int) time.Duration {
+	return time.Duration(n) * time.Millisecond
+}
+
+var sample = &Subtitles{
+	Language: "no",
+	Cues: []Cue{
+		{Start: ms(1000), End: ms(3480), Lines: []string{"Velkommen til Brunstad.", "<i>Vi begynner nå</i>"}},
+		{Start: ms(3520), End: ms(6000), Lines: []string{"Blåbær & øl: 2 < 3 > 1"}},
+		{Start: ms(3723000), End: ms(3725040), Lines: []string{"<b>Over</b> en time inn"}},
+	},
+}
+
+// Every format writes what every format reads, and the cues come through with their
+// timing, at the precision of the format.
+func TestConvert_EveryFormatToEveryFormat(t *testing.T) {
+	precision := map[Format]time.Duration{
+		FormatSTL: 40 * time.Millisecond,
+		FormatASS: 10 * time.Millisecond,
+	}
+	// STL has no bold.
+	withoutBold := map[Format]bool{FormatSTL: true}
+
+	for _, from := range Formats {
+		data, err := sample.Marshal(from)
+		require.NoError(t, err, from)
+		parsed, err := Parse(data, from)
+		require.NoError(t, err, from)
+
+		for _, to := range Formats {
+			data, err := parsed.Marshal(to)
+			require.NoError(t, err, "%s to %s", from, to)
+			converted, err := Parse(data, to)
+			require.NoError(t, err, "%s to %s", from, to)
+
+			require.Len(t, converted.Cues, len(sample.Cues), "%s to %s", from, to)
+			for i, want := range sample.Cues {
+				got := converted.Cues[i]
+				tolerance := max(precision[from], precision[to])
+				assert.InDelta(t, want.Start, got.Start, float64(tolerance), "%s to %s: cue %d start", from, to, i)
+				assert.InDelta(t, want.End, got.End, float64(tolerance), "%s to %s: cue %d end", from, to, i)
+
+				wantLines := want.Lines
+				if withoutBold[from] || withoutBold[to] {
+					wantLines = lines(markup(withoutBoldRuns(runs(want.Text()))))
+				}
+				assert.Equal(t, wantLines, got.Lines, "%s to %s: cue %d", from, to, i)
+			}
+		}
+	}
+}
+
+func withoutBoldRuns(in []run) []run {
+	var out []run
+	for _, r := range in {
+		r.style.bold = false
+		out = append(out, r)
+	}
+	return out
+}
+
+func TestShift(t *testing.T) {
+	shifted := sample.Shift(-2 * time.Second)
+
+	require.Len(t, shifted.Cues, 3)
+	assert.Equal(t, time.Duration(0), shifted.Cues[0].Start, "a cue that started before the start starts at it")
+	assert.Equal(t, ms(1480), shifted.Cues[0].End)
+	assert.Equal(t, ms(1520), shifted.Cues[1].Start)
+	assert.Equal(t, ms(1000), sample.Cues[0].Start, "the subtitles shifted are left as they are")
+
+	assert.Len(t, sample.Shift(-4*time.Second).Cues, 2, "a cue that ended before the start is dropped")
+	assert.Equal(t, ms(3724000), sample.Shift(time.Second).Cues[2].Start)
+}
+
+func TestParse_SortsAndDropsEmptyCues(t *testing.T) {
+	subs, err := Parse([]byte("2\n00:00:05,000 --> 00:00:06,000\nsecond\n\n1\n00:00:01,000 --> 00:00:02,000\nfirst\n\n3\n00:00:07,000 --> 00:00:08,000\n<i></i>\n"), FormatSRT)
+
+	require.NoError(t, err)
+	require.Len(t, subs.Cues, 2)
+	assert.Equal(t, []string{"first"}, subs.Cues[0].Lines)
+	assert.Equal(t, []string{"second"}, subs.Cues[1].Lines)
+}
+
+func TestParseFormat(t *testing.T) {
+	for name, want := range map[string]Format{
+		".srt": FormatSRT, "VTT": FormatVTT, ".dfxp": FormatTTML, "imsc1": FormatTTML,
+		".stl": FormatSTL, "ssa": FormatASS,
+	} {
+		got, err := ParseFormat(name)
+		require.NoError(t, err, name)
+		assert.Equal(t, want, got, name)
+	}
+
+	_, err := ParseFormat(".sub")
+	assert.Error(t, err)
+}
+
+func TestMarkup_NestsTags(t *testing.T) {
+	assert.Equal(t, "<i>a <b>b</b></i><b> c</b>", markup(runs("<i>a <b>b</i> c</b>")))
+	assert.Equal(t, "plain red", markup(runs(`plain <font color="red">red</font>`)))
+	assert.Equal(t, "<i>one\ntwo</i>", markup(runs("<I>one\ntwo")))
+}
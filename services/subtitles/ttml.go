@@ -0,0 +1,352 @@
+package subtitles
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ttmlTiming is what the time expressions of a TTML document are counted in.
+type ttmlTiming struct {
+	frameRate    float64
+	subFrameRate float64
+	tickRate     float64
+}
+
+var (
+	ttmlClockPattern  = regexp.MustCompile(`^(\d+):(\d{2}):(\d{2})(?:(\.\d+)|:(\d+)(?:\.(\d+))?)?$`)
+	ttmlOffsetPattern = regexp.MustCompile(`^(\d+(?:\.\d+)?)(h|ms|m|s|f|t)$`)
+)
+
+// parse parses a TTML time expression: a clock time, with a fraction or frames, or an
+// offset time such as "12.5s" or "300f".
+func (t ttmlTiming) parse(expr string) (time.Duration, error) {
+	expr = strings.TrimSpace(expr)
+
+	if m := ttmlClockPattern.FindStringSubmatch(expr); m != nil {
+		h, _ := strconv.Atoi(m[1])
+		minutes, _ := strconv.Atoi(m[2])
+		sec, _ := strconv.Atoi(m[3])
+		seconds := float64(h*3600 + minutes*60 + sec)
+		switch {
+		case m[4] != "":
+			fraction, _ := strconv.ParseFloat(m[4], 64)
+			seconds += fraction
+		case m[5] != "":
+			frames, _ := strconv.ParseFloat(m[5], 64)
+			if m[6] != "" {
+				subFrames, _ := strconv.ParseFloat(m[6], 64)
+				frames += subFrames / t.subFrameRate
+			}
+			seconds += frames / t.frameRate
+		}
+		return seconds2duration(seconds), nil
+	}
+
+	if m := ttmlOffsetPattern.FindStringSubmatch(expr); m != nil {
+		value, _ := strconv.ParseFloat(m[1], 64)
+		switch m[2] {
+		case "h":
+			value *= 3600
+		case "m":
+			value *= 60
+		case "ms":
+			value /= 1000
+		case "f":
+			value /= t.frameRate
+		case "t":
+			value /= t.tickRate
+		}
+		return seconds2duration(value), nil
+	}
+
+	return 0, fmt.Errorf("invalid time expression %q", expr)
+}
+
+func seconds2duration(seconds float64) time.Duration {
+	return time.Duration(seconds * float64(time.Second)).Round(time.Millisecond)
+}
+
+// ttmlTimingOf reads the ttp: parameters of the root element.
+func ttmlTimingOf(root xml.StartElement) (ttmlTiming, error) {
+	t := ttmlTiming{frameRate: 30, subFrameRate: 1}
+	tickRate := 0.0
+	multiplier := 1.0
+
+	for _, attr := range root.Attr {
+		var err error
+		switch attr.Name.Local {
+		case "frameRate":
+			t.frameRate, err = strconv.ParseFloat(attr.Value, 64)
+		case "subFrameRate":
+			t.subFrameRate, err = strconv.ParseFloat(attr.Value, 64)
+		case "tickRate":
+			tickRate, err = strconv.ParseFloat(attr.Value, 64)
+		case "frameRateMultiplier":
+			var numerator, denominator float64
+			if _, err = fmt.Sscanf(attr.Value, "%g %g", &numerator, &denominator); err == nil && denominator != 0 {
+				multiplier = numerator / denominator
+			}
+		}
+		if err != nil {
+			return t, fmt.Errorf("invalid %s %q", attr.Name.Local, attr.Value)
+		}
+	}
+
+	t.frameRate *= multiplier
+	t.tickRate = tickRate
+	if t.tickRate == 0 {
+		// Without a tick rate, ticks are frames if there is a frame rate, and seconds
+		// otherwise.
+		t.tickRate = 1
+		if hasAttr(root, "frameRate") {
+			t.tickRate = t.frameRate
+		}
+	}
+	if t.frameRate <= 0 || t.subFrameRate <= 0 || t.tickRate <= 0 {
+		return t, errors.New("frame, sub-frame and tick rates must be positive")
+	}
+	return t, nil
+}
+
+func hasAttr(element xml.StartElement, local string) bool {
+	_, ok := attr(element, local)
+	return ok
+}
+
+func attr(element xml.StartElement, local string) (string, bool) {
+	for _, a := range element.Attr {
+		if a.Name.Local == local {
+			return a.Value, true
+		}
+	}
+	return "", false
+}
+
+// applyTTMLStyle applies the tts: attributes of an element or a style to s.
+func applyTTMLStyle(s style, element xml.StartElement) style {
+	for _, a := range element.Attr {
+		switch a.Name.Local {
+		case "fontStyle":
+			s.italic = a.Value == "italic" || a.Value == "oblique"
+		case "fontWeight":
+			s.bold = a.Value == "bold"
+		case "textDecoration":
+			s.underline = strings.Contains(a.Value, "underline") && !strings.Contains(a.Value, "noUnderline")
+		}
+	}
+	return s
+}
+
+// ttmlFrame is what an element inherits from the ones it is in.
+type ttmlFrame struct {
+	begin  time.Duration
+	end    time.Duration
+	hasEnd bool
+	style  style
+}
+
+var whitespacePattern = regexp.MustCompile(`\s+`)
+
+// ParseTTML parses TTML, including the IMSC1 text profile and DFXP. Times are taken
+// as media time, relative to the elements they are in. Regions, and styles other than
+// italic, bold and underline, are not kept.
+func ParseTTML(data []byte) (*Subtitles, error) {
+	decoder := xml.NewDecoder(bytes.NewReader(data))
+	decoder.Strict = false
+
+	subs := &Subtitles{}
+	styles := map[string]style{}
+	var timing ttmlTiming
+	var stack []ttmlFrame
+	var cue *Cue
+	var cueRuns []run
+	seenRoot := false
+
+	for {
+		token, err := decoder.Token()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		switch t := token.(type) {
+		case xml.StartElement:
+			if !seenRoot {
+				if t.Name.Local != "tt" {
+					return nil, fmt.Errorf("root element is %s, not tt", t.Name.Local)
+				}
+				seenRoot = true
+				if timing, err = ttmlTimingOf(t); err != nil {
+					return nil, err
+				}
+				subs.Language, _ = attr(t, "lang")
+				stack = append(stack, ttmlFrame{})
+				continue
+			}
+
+			parent := stack[len(stack)-1]
+			if cue != nil && t.Name.Local != "span" && t.Name.Local != "br" {
+				// Metadata and the like within the cue text.
+				if err := decoder.Skip(); err != nil {
+					return nil, err
+				}
+				continue
+			}
+
+			switch t.Name.Local {
+			case "style":
+				if id, ok := attr(t, "id"); ok {
+					styles[id] = applyTTMLStyle(style{}, t)
+				}
+			case "br":
+				if cue != nil {
+					cueRuns = append(cueRuns, run{text: "\n", style: parent.style})
+				}
+			}
+
+			frame, err := ttmlFrameOf(t, parent, timing, styles)
+			if err != nil {
+				return nil, err
+			}
+			stack = append(stack, frame)
+
+			if t.Name.Local == "p" {
+				if !frame.hasEnd {
+					return nil, fmt.Errorf("p at %s has no end", clockTime(frame.begin, "."))
+				}
+				cue = &Cue{Start: frame.begin, End: frame.end}
+				cueRuns = nil
+			}
+
+		case xml.EndElement:
+			if len(stack) > 0 {
+				stack = stack[:len(stack)-1]
+			}
+			if t.Name.Local == "p" && cue != nil {
+				cue.Lines = lines(markup(cueRuns))
+				subs.Cues = append(subs.Cues, *cue)
+				cue = nil
+			}
+
+		case xml.CharData:
+			if cue != nil {
+				text := whitespacePattern.ReplaceAllString(string(t), " ")
+				cueRuns = append(cueRuns, run{text: text, style: stack[len(stack)-1].style})
+			}
+		}
+	}
+
+	if !seenRoot {
+		return nil, errors.New("no tt element")
+	}
+	return subs, nil
+}
+
+// ttmlFrameOf is the timing and style of an element in parent.
+func ttmlFrameOf(element xml.StartElement, parent ttmlFrame, timing ttmlTiming, styles map[string]style) (ttmlFrame, error) {
+	frame := parent
+
+	if ids, ok := attr(element, "style"); ok {
+		for _, id := range strings.Fields(ids) {
+			s, found := styles[id]
+			if !found {
+				continue
+			}
+			frame.style.italic = frame.style.italic || s.italic
+			frame.style.bold = frame.style.bold || s.bold
+			frame.style.underline = frame.style.underline || s.underline
+		}
+	}
+	frame.style = applyTTMLStyle(frame.style, element)
+
+	if begin, ok := attr(element, "begin"); ok {
+		offset, err := timing.parse(begin)
+		if err != nil {
+			return frame, err
+		}
+		frame.begin = parent.begin + offset
+	}
+	if end, ok := attr(element, "end"); ok {
+		offset, err := timing.parse(end)
+		if err != nil {
+			return frame, err
+		}
+		frame.end = parent.begin + offset
+		frame.hasEnd = true
+	} else if dur, ok := attr(element, "dur"); ok {
+		duration, err := timing.parse(dur)
+		if err != nil {
+			return frame, err
+		}
+		frame.end = frame.begin + duration
+		frame.hasEnd = true
+	}
+	return frame, nil
+}
+
+func ttmlEscape(text string) string {
+	var b strings.Builder
+	_ = xml.EscapeText(&b, []byte(text))
+	return b.String()
+}
+
+// MarshalTTML writes the subtitles as TTML in the IMSC1 text profile, shown at the
+// bottom of the picture.
+func (s *Subtitles) MarshalTTML() []byte {
+	var b strings.Builder
+	lang := s.Language
+	if lang == "" {
+		lang = "und"
+	}
+
+	b.WriteString(`<?xml version="1.0" encoding="UTF-8"?>` + "\n")
+	fmt.Fprintf(&b, `<tt xmlns="http://www.w3.org/ns/ttml" xmlns:ttp="http://www.w3.org/ns/ttml#parameter" `+
+		`xmlns:tts="http://www.w3.org/ns/ttml#styling" ttp:profile="http://www.w3.org/ns/ttml/profile/imsc1/text" `+
+		`ttp:timeBase="media" xml:lang="%s">`+"\n", ttmlEscape(lang))
+	b.WriteString(`  <head>
+    <styling>
+      <style xml:id="default" tts:color="white" tts:fontFamily="proportionalSansSerif" tts:fontSize="100%" tts:textAlign="center" tts:textOutline="black 5%"/>
+    </styling>
+    <layout>
+      <region xml:id="bottom" tts:origin="10% 10%" tts:extent="80% 80%" tts:displayAlign="after"/>
+    </layout>
+  </head>
+  <body style="default" region="bottom">
+    <div>
+`)
+
+	for _, cue := range s.Cues {
+		fmt.Fprintf(&b, `      <p begin="%s" end="%s">`, clockTime(cue.Start, "."), clockTime(cue.End, "."))
+		for _, r := range runs(cue.Text()) {
+			var attrs []string
+			if r.style.italic {
+				attrs = append(attrs, `tts:fontStyle="italic"`)
+			}
+			if r.style.bold {
+				attrs = append(attrs, `tts:fontWeight="bold"`)
+			}
+			if r.style.underline {
+				attrs = append(attrs, `tts:textDecoration="underline"`)
+			}
+
+			text := strings.ReplaceAll(ttmlEscape(r.text), "&#xA;", "<br/>")
+			if len(attrs) > 0 {
+				text = "<span " + strings.Join(attrs, " ") + ">" + text + "</span>"
+			}
+			b.WriteString(text)
+		}
+		b.WriteString("</p>\n")
+	}
+
+	b.WriteString("    </div>\n  </body>\n</tt>\n")
+	return []byte(b.String())
+}
@@ -0,0 +1,96 @@
+package subtitles
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseTTML_IMSC(t *testing.T) {
+	data := `<?xml version="1.0" encoding="UTF-8"?>
+<tt xmlns="http://www.w3.org/ns/ttml" xmlns:ttp="http://www.w3.org/ns/ttml#parameter"
+    xmlns:tts="http://www.w3.org/ns/ttml#styling" xmlns:ttm="http://www.w3.org/ns/ttml#metadata"
+    ttp:frameRate="25" ttp:tickRate="10000000" xml:lang="nb">
+  <head>
+    <styling>
+      <style xml:id="italic" tts:fontStyle="italic"/>
+    </styling>
+  </head>
+  <body>
+    <div begin="00:01:00.000">
+      <p begin="00:00:01:12" end="00:00:02:00">
+        First
+        <br/>
+        <span style="italic">second</span>   line
+      </p>
+      <p begin="30000000t" dur="1.5s"><metadata><ttm:desc>ignored</ttm:desc></metadata><span tts:fontWeight="bold">Bold</span></p>
+    </div>
+    <div>
+      <p begin="500ms" end="1s" style="italic">All italic</p>
+    </div>
+  </body>
+</tt>`
+
+	subs, err := ParseTTML([]byte(data))
+
+	require.NoError(t, err)
+	assert.Equal(t, "nb", subs.Language)
+	assert.Equal(t, []Cue{
+		{Start: ms(61480), End: ms(62000), Lines: []string{"First", "<i>second</i> line"}},
+		{Start: ms(63000), End: ms(64500), Lines: []string{"<b>Bold</b>"}},
+		{Start: ms(500), End: ms(1000), Lines: []string{"<i>All italic</i>"}},
+	}, subs.Cues)
+}
+
+func TestParseTTML_Errors(t *testing.T) {
+	for name, data := range map[string]string{
+		"not ttml":   `<html></html>`,
+		"no end":     `<tt><body><div><p begin="1s">text</p></div></body></tt>`,
+		"bad time":   `<tt><body><div><p begin="soon" end="2s">text</p></div></body></tt>`,
+		"bad xml":    `<tt><body>`,
+		"frame rate": `<tt ttp:frameRate="0"><body/></tt>`,
+	} {
+		_, err := ParseTTML([]byte(data))
+		assert.Error(t, err, name)
+	}
+}
+
+func TestTTMLTiming_Parse(t *testing.T) {
+	timing := ttmlTiming{frameRate: 25, subFrameRate: 2, tickRate: 10}
+	for expr, want := range map[string]int{
+		"00:00:01.5":    1500,
+		"01:00:00":      3600000,
+		"00:00:01:15":   1600,
+		"00:00:00:24.1": 980,
+		"2h":            7200000,
+		"1.5m":          90000,
+		"250ms":         250,
+		"30f":           1200,
+		"12t":           1200,
+	} {
+		got, err := timing.parse(expr)
+		require.NoError(t, err, expr)
+		assert.Equal(t, ms(want), got, expr)
+	}
+}
+
+func TestTTMLTimingOf_FrameRateMultiplier(t *testing.T) {
+	subs, err := ParseTTML([]byte(`<tt xmlns:ttp="http://www.w3.org/ns/ttml#parameter" ttp:frameRate="30" ttp:frameRateMultiplier="1000 1001">` +
+		`<body><div><p begin="30f" end="60f">NTSC</p></div></body></tt>`))
+
+	require.NoError(t, err)
+	assert.Equal(t, []Cue{{Start: ms(1001), End: ms(2002), Lines: []string{"NTSC"}}}, subs.Cues)
+}
+
+func TestMarshalTTML_IsIMSCText(t *testing.T) {
+	subs := &Subtitles{Language: "en", Cues: []Cue{
+		{Start: ms(1000), End: ms(2000), Lines: []string{"<i>Tom & Jerry</i>", "second"}},
+	}}
+
+	data := string(subs.MarshalTTML())
+
+	assert.Contains(t, data, `ttp:profile="http://www.w3.org/ns/ttml/profile/imsc1/text"`)
+	assert.Contains(t, data, `xml:lang="en"`)
+	assert.Contains(t, data, `<p begin="00:00:01.000" end="00:00:02.000"><span tts:fontStyle="italic">Tom &amp; Jerry</span><br/>second</p>`)
+}
@@ -0,0 +1,88 @@
+package subtitles
+
+import (
+	"errors"
+	"fmt"
+	"html"
+	"regexp"
+	"strings"
+)
+
+// vttTimestampTagPattern is the karaoke-style timestamps within cue text.
+var vttTimestampTagPattern = regexp.MustCompile(`<\d[\d:.]*>`)
+
+// ParseVTT parses WebVTT. Cue settings, and the NOTE, STYLE and REGION blocks, are
+// not kept.
+func ParseVTT(data []byte) (*Subtitles, error) {
+	text := normalize(data)
+	if !strings.HasPrefix(text, "WEBVTT") {
+		return nil, errors.New("missing WEBVTT header")
+	}
+
+	subs := &Subtitles{}
+	blocks := strings.Split(text, "\n\n")
+	// The first block is the header.
+	for _, block := range blocks[1:] {
+		blockLines := strings.Split(strings.Trim(block, "\n"), "\n")
+		if len(blockLines) == 0 || blockLines[0] == "" {
+			continue
+		}
+
+		// A cue may have an identifier on the line before its timing.
+		timing := 0
+		if !strings.Contains(blockLines[0], "-->") {
+			if len(blockLines) < 2 || !strings.Contains(blockLines[1], "-->") {
+				continue
+			}
+			timing = 1
+		}
+
+		match := timingPattern.FindStringSubmatch(blockLines[timing])
+		if match == nil {
+			return nil, fmt.Errorf("invalid cue timing %q", blockLines[timing])
+		}
+		start, err := parseClock(match[1])
+		if err != nil {
+			return nil, err
+		}
+		end, err := parseClock(match[2])
+		if err != nil {
+			return nil, err
+		}
+
+		cueText := vttTimestampTagPattern.ReplaceAllString(strings.Join(blockLines[timing+1:], "\n"), "")
+		cueRuns := runs(cueText)
+		for i := range cueRuns {
+			cueRuns[i].text = html.UnescapeString(cueRuns[i].text)
+		}
+		subs.Cues = append(subs.Cues, Cue{
+			Start: start,
+			End:   end,
+			Lines: lines(markup(cueRuns)),
+		})
+	}
+
+	return subs, nil
+}
+
+// vttEscaper escapes the characters WebVTT cue text can not have as they are.
+var vttEscaper = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;")
+
+// vttText escapes cue text for WebVTT, leaving its styling tags.
+func vttText(text string) string {
+	textRuns := runs(text)
+	for i := range textRuns {
+		textRuns[i].text = vttEscaper.Replace(textRuns[i].text)
+	}
+	return markup(textRuns)
+}
+
+// MarshalVTT writes the subtitles as WebVTT.
+func (s *Subtitles) MarshalVTT() []byte {
+	var b strings.Builder
+	b.WriteString("WEBVTT\n\n")
+	for _, cue := range s.Cues {
+		fmt.Fprintf(&b, "%s --> %s\n%s\n\n", clockTime(cue.Start, "."), clockTime(cue.End, "."), vttText(cue.Text()))
+	}
+	return []byte(b.String())
+}
@@ -0,0 +1,47 @@
+package subtitles
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseVTT(t *testing.T) {
+	data := `WEBVTT - with a title
+Kind: captions
+
+STYLE
+::cue { color: yellow }
+
+NOTE a comment
+that goes on
+
+intro
+00:01.000 --> 00:02.500 line:90% align:center
+<v Speaker>Hello</v> &amp; <c.yellow>welcome</c>
+<i>to &lt;Brunstad&gt;</i>
+
+01:00:00.000 --> 01:00:01.000
+<00:00:00.500>timed
+`
+
+	subs, err := ParseVTT([]byte(data))
+
+	require.NoError(t, err)
+	assert.Equal(t, []Cue{
+		{Start: ms(1000), End: ms(2500), Lines: []string{"Hello & welcome", "<i>to <Brunstad></i>"}},
+		{Start: ms(3600000), End: ms(3601000), Lines: []string{"timed"}},
+	}, subs.Cues)
+}
+
+func TestParseVTT_NeedsTheHeader(t *testing.T) {
+	_, err := ParseVTT([]byte("1\n00:00:01,000 --> 00:00:02,000\nSRT\n"))
+	assert.Error(t, err)
+}
+
+func TestMarshalVTT_EscapesTheText(t *testing.T) {
+	subs := &Subtitles{Cues: []Cue{{Start: ms(1000), End: ms(2000), Lines: []string{"<i>Tom & Jerry</i>", "2 < 3"}}}}
+
+	assert.Equal(t, "WEBVTT\n\n00:00:01.000 --> 00:00:02.000\n<i>Tom &amp; Jerry</i>\n2 &lt; 3\n\n", string(subs.MarshalVTT()))
+}
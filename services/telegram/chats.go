@@ -1,6 +1,8 @@
 package telegram
 
 import (
+	"strconv"
+
 	"github.com/bcc-code/bcc-media-flows/environment"
 
 	"github.com/orsinium-labs/enum"
@@ -30,3 +32,19 @@ func init() {
 	ChatOther.Value = cfg.Telegram.ChatOther()
 	ChatBMM.Value = cfg.Telegram.ChatBMM()
 }
+
+// ChatName is what the notification routes call a chat: vod, oslofjord, other or bmm.
+// Chats configured with the same ID are named after the first of them.
+func ChatName(chat Chat) string {
+	switch chat.Value {
+	case ChatVOD.Value:
+		return "vod"
+	case ChatOslofjord.Value:
+		return "oslofjord"
+	case ChatOther.Value:
+		return "other"
+	case ChatBMM.Value:
+		return "bmm"
+	}
+	return strconv.FormatInt(chat.Value, 10)
+}
@@ -18,12 +18,16 @@ func NewMessage(chat Chat, template notifications.Template) (*Message, error) {
 	return &Message{
 		Chat:     chat,
 		Markdown: markdown,
+		Event:    notifications.EventOf(template),
 	}, err
 }
 
 type Message struct {
-	Chat            Chat
-	Markdown        string
+	Chat     Chat
+	Markdown string
+	// Event is what the message reports, one of the notifications.Event values. The
+	// notification routes can send it elsewhere by it; empty is a plain message.
+	Event           string
 	TelegramMessage *telebot.Message
 }
 
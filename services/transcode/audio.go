@@ -147,12 +147,16 @@ func AudioWav(input common.WavAudioInput, cb ffmpeg.ProgressCallback) (*common.A
 	args := []string{"-codec:a", "pcm_s24le"}
 
 	if input.Timecode != "" {
-		tcSamples, err := utils.TCToSamples(input.Timecode, 25, 48000)
+		rate := input.FrameRate
+		if rate.IsZero() {
+			rate = utils.Rate25
+		}
+		tc, err := utils.ParseTimecode(input.Timecode, rate)
 		if err != nil {
 			return nil, err
 		}
 		args = append(args,
-			"-metadata", fmt.Sprintf("time_reference=%d", tcSamples),
+			"-metadata", fmt.Sprintf("time_reference=%d", tc.Samples(48000)),
 			"-write_bext", "1",
 		)
 	}
@@ -289,22 +293,17 @@ func ExtractAudioChannels(filePath paths.Path, output map[int]paths.Path, cb ffm
 	return out, nil
 }
 
-func GenerateToneFile(frequency int, duration float64, sampleRate int, timecode string, filePath paths.Path) error {
-	samples, err := utils.TCToSamples(timecode, 25, sampleRate)
-	if err != nil {
-		return err
-	}
-
+func GenerateToneFile(frequency int, duration float64, sampleRate int, timecode utils.Timecode, filePath paths.Path) error {
 	params := []string{
 		"-f", "lavfi",
 		"-i", fmt.Sprintf("sine=frequency=%d:sample_rate=%d:duration=%f", frequency, sampleRate, duration),
 		"-codec:a", "pcm_s24le",
-		"-metadata", fmt.Sprintf("time_reference=%d", samples),
+		"-metadata", fmt.Sprintf("time_reference=%d", timecode.Samples(sampleRate)),
 		"-write_bext", "1",
 		filePath.Local(),
 	}
 
-	_, err = ffmpeg.Do(params, ffmpeg.StreamInfo{}, nil)
+	_, err := ffmpeg.Do(params, ffmpeg.StreamInfo{}, nil)
 	return err
 }
 
@@ -5,6 +5,7 @@ import (
 	"testing"
 
 	"github.com/bcc-code/bcc-media-flows/services/ffmpeg"
+	"github.com/bcc-code/bcc-media-flows/utils"
 	"github.com/bcc-code/bcc-media-flows/utils/testutils"
 
 	"github.com/bcc-code/bcc-media-flows/common"
@@ -15,7 +16,7 @@ import (
 func Test_AACEncode(t *testing.T) {
 	testutils.SkipWithoutEncoder(t, "libfdk_aac")
 	tempDstPath := paths.MustParse("./testdata/test" + t.Name() + ".wav")
-	err := GenerateToneFile(1000, 5, 48000, "01:00:00:00", tempDstPath)
+	err := GenerateToneFile(1000, 5, 48000, utils.MustParseTimecode("01:00:00:00", utils.Rate25), tempDstPath)
 	assert.NoError(t, err)
 
 	p, stop := printProgress()
@@ -39,7 +40,7 @@ func Test_AACEncode(t *testing.T) {
 
 func Test_MP3Encode_VBR(t *testing.T) {
 	tempDstPath := paths.MustParse("./testdata/test" + t.Name() + ".wav")
-	err := GenerateToneFile(1000, 5, 48000, "01:00:00:00", tempDstPath)
+	err := GenerateToneFile(1000, 5, 48000, utils.MustParseTimecode("01:00:00:00", utils.Rate25), tempDstPath)
 	assert.NoError(t, err)
 
 	p, stop := printProgress()
@@ -65,7 +66,7 @@ func Test_MP3Encode_VBR(t *testing.T) {
 
 func Test_MP3Encode_CBR(t *testing.T) {
 	tempDstPath := paths.MustParse("./testdata/test" + t.Name() + ".wav")
-	err := GenerateToneFile(1000, 5, 48000, "01:00:00:00", tempDstPath)
+	err := GenerateToneFile(1000, 5, 48000, utils.MustParseTimecode("01:00:00:00", utils.Rate25), tempDstPath)
 	assert.NoError(t, err)
 
 	p, stop := printProgress()
@@ -122,7 +123,7 @@ func Test_AudioChannelSilence(t *testing.T) {
 
 func Test_ToneGenerator(t *testing.T) {
 	tempDstPath := paths.MustParse("./testdata/test.wav")
-	err := GenerateToneFile(1000, 5, 48000, "01:00:00:00", tempDstPath)
+	err := GenerateToneFile(1000, 5, 48000, utils.MustParseTimecode("01:00:00:00", utils.Rate25), tempDstPath)
 	assert.NoError(t, err)
 	assert.FileExistsf(t, tempDstPath.Local(), "File should exist")
 	fileCanBeDeleted := true
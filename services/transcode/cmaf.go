@@ -0,0 +1,484 @@
+package transcode
+
+import (
+	"bufio"
+	"encoding/xml"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/bcc-code/bcc-media-flows/common"
+	"github.com/bcc-code/bcc-media-flows/common/dash"
+	"github.com/bcc-code/bcc-media-flows/languages"
+	"github.com/bcc-code/bcc-media-flows/services/ffmpeg"
+)
+
+const (
+	cmafMasterPlaylist = "master.m3u8"
+	cmafDASHManifest   = "manifest.mpd"
+	cmafAudioGroup     = "audio"
+	cmafSubtitlesGroup = "subs"
+	cmafAudioCodec     = "mp4a.40.2"
+)
+
+// cmafSegment is one media segment as listed in an HLS media playlist.
+type cmafSegment struct {
+	URI      string
+	Duration float64
+}
+
+// cmafRendition is one segmented stream: its media playlist, the init segment and the
+// media segments the playlist lists.
+type cmafRendition struct {
+	ID        string
+	Playlist  string
+	Init      string
+	Segments  []cmafSegment
+	Codecs    string
+	Width     int
+	Height    int
+	Bandwidth int
+	// AverageBandwidth is over the whole rendition, Bandwidth the peak of any segment.
+	AverageBandwidth int
+	Language         languages.Language
+}
+
+func (r cmafRendition) duration() float64 {
+	var total float64
+	for _, s := range r.Segments {
+		total += s.Duration
+	}
+	return total
+}
+
+type cmafSubtitle struct {
+	Playlist string
+	File     string
+	Language languages.Language
+}
+
+// PackageCMAF segments already encoded video and audio renditions into fragmented MP4
+// without re-encoding, and writes an HLS master playlist and a DASH manifest that share
+// the same segments. Subtitles are converted to WebVTT and offered as one file per
+// language in both.
+func PackageCMAF(input common.CMAFInput, cb ffmpeg.ProgressCallback) (*common.CMAFResult, error) {
+	if len(input.VideoFiles) == 0 {
+		return nil, fmt.Errorf("no video files to package")
+	}
+
+	outputDir := input.DestinationPath.Local()
+	err := os.MkdirAll(outputDir, ffmpeg.OutputDirMode)
+	if err != nil {
+		return nil, err
+	}
+
+	segmentDuration := input.SegmentDuration
+	if segmentDuration == 0 {
+		segmentDuration = 6
+	}
+
+	var videos []cmafRendition
+	for _, videoFile := range input.VideoFiles {
+		info, err := ffmpeg.GetStreamInfo(videoFile.Local())
+		if err != nil {
+			return nil, err
+		}
+		if len(info.VideoStreams) == 0 {
+			return nil, fmt.Errorf("%s has no video stream", videoFile.Local())
+		}
+
+		stream := info.VideoStreams[0]
+		rendition := cmafRendition{
+			ID:     fmt.Sprintf("video_%dx%d", stream.Width, stream.Height),
+			Codecs: avcCodecString(stream.Profile, stream.Level),
+			Width:  stream.Width,
+			Height: stream.Height,
+		}
+
+		err = segmentToFMP4(ffmpeg.Input{Path: videoFile.Local()}, "0:v:0", outputDir, &rendition, segmentDuration, info, cb)
+		if err != nil {
+			return nil, err
+		}
+		videos = append(videos, rendition)
+	}
+
+	var audios []cmafRendition
+	for _, f := range languageFilesForPaths(input.AudioFilePaths) {
+		info, err := ffmpeg.GetStreamInfo(f.Path.Local())
+		if err != nil {
+			return nil, err
+		}
+
+		rendition := cmafRendition{
+			ID:       "audio_" + f.Language,
+			Codecs:   cmafAudioCodec,
+			Language: languages.LanguagesByISO[f.Language],
+		}
+
+		// Same offset as Mux: AAC inserts a delay at the start of the file, which would
+		// put the audio out of sync with the video.
+		audioInput := ffmpeg.Input{Args: []string{"-itsoffset", "-0.022"}, Path: f.Path.Local()}
+		err = segmentToFMP4(audioInput, "0:a:0", outputDir, &rendition, segmentDuration, info, cb)
+		if err != nil {
+			return nil, err
+		}
+		audios = append(audios, rendition)
+	}
+
+	totalDuration := videos[0].duration()
+
+	var subtitles []cmafSubtitle
+	for _, f := range languageFilesForPaths(input.SubtitleFilePaths) {
+		subtitle := cmafSubtitle{
+			Playlist: "subs_" + f.Language + ".m3u8",
+			File:     "subs_" + f.Language + ".vtt",
+			Language: languages.LanguagesByISO[f.Language],
+		}
+
+		_, err = ffmpeg.Run(ffmpeg.Job{
+			Input:  f.Path.Local(),
+			Output: filepath.Join(outputDir, subtitle.File),
+			Args:   []string{"-c:s", "webvtt"},
+			Info:   &ffmpeg.StreamInfo{},
+		}, cb)
+		if err != nil {
+			return nil, fmt.Errorf("convert %s to webvtt: %w", f.Path.Local(), err)
+		}
+
+		err = writeCMAFFile(outputDir, subtitle.Playlist, subtitlePlaylist(subtitle.File, totalDuration))
+		if err != nil {
+			return nil, err
+		}
+		subtitles = append(subtitles, subtitle)
+	}
+
+	err = writeCMAFFile(outputDir, cmafMasterPlaylist, hlsMasterPlaylist(videos, audios, subtitles))
+	if err != nil {
+		return nil, err
+	}
+
+	mpd, err := xml.MarshalIndent(dashManifest(videos, audios, subtitles, totalDuration), "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	err = writeCMAFFile(outputDir, cmafDASHManifest, append([]byte(xml.Header), mpd...))
+	if err != nil {
+		return nil, err
+	}
+
+	return &common.CMAFResult{
+		HLSPlaylist:  input.DestinationPath.Append(cmafMasterPlaylist),
+		DASHManifest: input.DestinationPath.Append(cmafDASHManifest),
+	}, nil
+}
+
+// segmentToFMP4 copies one stream into fMP4 segments next to an HLS media playlist, then
+// fills in the rendition from the playlist and the segment sizes ffmpeg left on disk.
+func segmentToFMP4(input ffmpeg.Input, stream, outputDir string, rendition *cmafRendition, segmentDuration int, info ffmpeg.StreamInfo, cb ffmpeg.ProgressCallback) error {
+	rendition.Playlist = rendition.ID + ".m3u8"
+	rendition.Init = rendition.ID + "_init.mp4"
+
+	_, err := ffmpeg.Run(ffmpeg.Job{
+		Input:     input.Path,
+		InputArgs: input.Args,
+		Output:    filepath.Join(outputDir, rendition.Playlist),
+		Args: []string{
+			"-map", stream,
+			"-c", "copy",
+			"-f", "hls",
+			"-hls_time", strconv.Itoa(segmentDuration),
+			"-hls_playlist_type", "vod",
+			"-hls_segment_type", "fmp4",
+			"-hls_flags", "independent_segments",
+			"-hls_fmp4_init_filename", rendition.Init,
+			"-hls_segment_filename", filepath.Join(outputDir, rendition.ID+"_%05d.m4s"),
+		},
+		Info: &info,
+	}, cb)
+	if err != nil {
+		return fmt.Errorf("segment %s: %w", input.Path, err)
+	}
+
+	playlist, err := os.Open(filepath.Join(outputDir, rendition.Playlist))
+	if err != nil {
+		return err
+	}
+	defer playlist.Close()
+
+	init, segments, err := parseMediaPlaylist(bufio.NewScanner(playlist))
+	if err != nil {
+		return fmt.Errorf("read %s: %w", rendition.Playlist, err)
+	}
+	if init != "" {
+		rendition.Init = init
+	}
+	rendition.Segments = segments
+
+	sizes := make([]int64, len(segments))
+	for i, s := range segments {
+		stat, err := os.Stat(filepath.Join(outputDir, s.URI))
+		if err != nil {
+			return err
+		}
+		sizes[i] = stat.Size()
+	}
+	rendition.Bandwidth, rendition.AverageBandwidth = segmentBandwidth(segments, sizes)
+
+	return nil
+}
+
+// parseMediaPlaylist reads the init segment and the media segments from an HLS media
+// playlist. Only what ffmpeg writes for a VOD fMP4 playlist is understood.
+func parseMediaPlaylist(scanner *bufio.Scanner) (string, []cmafSegment, error) {
+	var init string
+	var segments []cmafSegment
+	var duration float64
+	var pending bool
+
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		switch {
+		case line == "":
+			continue
+		case strings.HasPrefix(line, "#EXT-X-MAP:"):
+			_, uri, found := strings.Cut(line, `URI="`)
+			if !found {
+				return "", nil, fmt.Errorf("malformed %s", line)
+			}
+			init, _, _ = strings.Cut(uri, `"`)
+		case strings.HasPrefix(line, "#EXTINF:"):
+			value, _, _ := strings.Cut(strings.TrimPrefix(line, "#EXTINF:"), ",")
+			d, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				return "", nil, fmt.Errorf("malformed %s: %w", line, err)
+			}
+			duration = d
+			pending = true
+		case strings.HasPrefix(line, "#"):
+			continue
+		default:
+			if !pending {
+				return "", nil, fmt.Errorf("segment %s has no duration", line)
+			}
+			segments = append(segments, cmafSegment{URI: line, Duration: duration})
+			pending = false
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return "", nil, err
+	}
+	if len(segments) == 0 {
+		return "", nil, fmt.Errorf("playlist has no segments")
+	}
+	return init, segments, nil
+}
+
+// segmentBandwidth returns the peak and the average bitrate of the segments, in bits per
+// second, which is what HLS calls BANDWIDTH and AVERAGE-BANDWIDTH.
+func segmentBandwidth(segments []cmafSegment, sizes []int64) (int, int) {
+	var peak, totalBits, totalDuration float64
+	for i, s := range segments {
+		bits := float64(sizes[i] * 8)
+		totalBits += bits
+		totalDuration += s.Duration
+		if s.Duration > 0 {
+			peak = math.Max(peak, bits/s.Duration)
+		}
+	}
+
+	if totalDuration == 0 {
+		return 0, 0
+	}
+	return int(math.Ceil(peak)), int(math.Ceil(totalBits / totalDuration))
+}
+
+// avcCodecString is the RFC 6381 codecs value for an H.264 stream as ffprobe describes it.
+func avcCodecString(profile string, level int) string {
+	var profileIDC, constraints int
+	switch strings.ToLower(profile) {
+	case "constrained baseline":
+		profileIDC, constraints = 0x42, 0xe0
+	case "baseline":
+		profileIDC = 0x42
+	case "main":
+		profileIDC = 0x4d
+	case "high 10":
+		profileIDC = 0x6e
+	case "high 4:2:2":
+		profileIDC = 0x7a
+	default:
+		profileIDC = 0x64
+	}
+	return fmt.Sprintf("avc1.%02x%02x%02x", profileIDC, constraints, level)
+}
+
+func hlsLanguageAttributes(lang languages.Language) string {
+	name := lang.LanguageNameSystem
+	if name == "" {
+		name = lang.ISO6391
+	}
+	return fmt.Sprintf(`LANGUAGE="%s",NAME="%s"`, lang.ISO6391, name)
+}
+
+func hlsMasterPlaylist(videos, audios []cmafRendition, subtitles []cmafSubtitle) []byte {
+	var b strings.Builder
+	b.WriteString("#EXTM3U\n#EXT-X-VERSION:7\n#EXT-X-INDEPENDENT-SEGMENTS\n\n")
+
+	audioBandwidth, audioAverage := 0, 0
+	for i, a := range audios {
+		isDefault := "NO"
+		if i == 0 {
+			isDefault = "YES"
+		}
+		fmt.Fprintf(&b, "#EXT-X-MEDIA:TYPE=AUDIO,GROUP-ID=\"%s\",%s,DEFAULT=%s,AUTOSELECT=YES,CHANNELS=\"2\",URI=\"%s\"\n",
+			cmafAudioGroup, hlsLanguageAttributes(a.Language), isDefault, a.Playlist)
+		audioBandwidth = max(audioBandwidth, a.Bandwidth)
+		audioAverage = max(audioAverage, a.AverageBandwidth)
+	}
+
+	for _, s := range subtitles {
+		fmt.Fprintf(&b, "#EXT-X-MEDIA:TYPE=SUBTITLES,GROUP-ID=\"%s\",%s,DEFAULT=NO,AUTOSELECT=YES,URI=\"%s\"\n",
+			cmafSubtitlesGroup, hlsLanguageAttributes(s.Language), s.Playlist)
+	}
+
+	if len(audios) > 0 || len(subtitles) > 0 {
+		b.WriteString("\n")
+	}
+
+	for _, v := range videos {
+		codecs := v.Codecs
+		groups := ""
+		if len(audios) > 0 {
+			codecs += "," + cmafAudioCodec
+			groups += fmt.Sprintf(",AUDIO=\"%s\"", cmafAudioGroup)
+		}
+		if len(subtitles) > 0 {
+			groups += fmt.Sprintf(",SUBTITLES=\"%s\"", cmafSubtitlesGroup)
+		}
+
+		fmt.Fprintf(&b, "#EXT-X-STREAM-INF:BANDWIDTH=%d,AVERAGE-BANDWIDTH=%d,RESOLUTION=%dx%d,CODECS=\"%s\"%s\n%s\n",
+			v.Bandwidth+audioBandwidth, v.AverageBandwidth+audioAverage, v.Width, v.Height, codecs, groups, v.Playlist)
+	}
+
+	return []byte(b.String())
+}
+
+// subtitlePlaylist offers a whole WebVTT file as the single segment of an HLS media
+// playlist, which is how players expect subtitles to come.
+func subtitlePlaylist(file string, duration float64) []byte {
+	return []byte(fmt.Sprintf("#EXTM3U\n#EXT-X-VERSION:3\n#EXT-X-TARGETDURATION:%d\n#EXT-X-PLAYLIST-TYPE:VOD\n#EXTINF:%.3f,\n%s\n#EXT-X-ENDLIST\n",
+		int(math.Ceil(duration)), duration, file))
+}
+
+// dashTimescale is milliseconds, precise enough for segment boundaries.
+const dashTimescale = 1000
+
+func dashSegmentList(r cmafRendition) *dash.SegmentList {
+	list := &dash.SegmentList{
+		Timescale:      dashTimescale,
+		Initialization: dash.Initialization{SourceURL: r.Init},
+	}
+
+	var t int64
+	for i, s := range r.Segments {
+		d := int64(math.Round(s.Duration * dashTimescale))
+		timeline := list.SegmentTimeline.S
+		if len(timeline) > 0 && timeline[len(timeline)-1].D == d {
+			timeline[len(timeline)-1].R++
+		} else {
+			entry := dash.S{D: d}
+			if i == 0 {
+				start := t
+				entry.T = &start
+			}
+			list.SegmentTimeline.S = append(timeline, entry)
+		}
+		list.SegmentURLs = append(list.SegmentURLs, dash.SegmentURL{Media: s.URI})
+		t += d
+	}
+
+	return list
+}
+
+func dashManifest(videos, audios []cmafRendition, subtitles []cmafSubtitle, duration float64) dash.MPD {
+	mpd := dash.MPD{
+		Xmlns:                     "urn:mpeg:dash:schema:mpd:2011",
+		Profiles:                  "urn:mpeg:dash:profile:isoff-live:2011,http://dashif.org/guidelines/dash264",
+		Type:                      "static",
+		MediaPresentationDuration: fmt.Sprintf("PT%.3fS", duration),
+		MinBufferTime:             "PT2S",
+		Period: dash.Period{
+			ID:    "0",
+			Start: "PT0S",
+		},
+	}
+
+	videoSet := dash.AdaptationSet{
+		ContentType:      "video",
+		MimeType:         "video/mp4",
+		SegmentAlignment: true,
+	}
+	for _, v := range videos {
+		videoSet.Representations = append(videoSet.Representations, dash.Representation{
+			ID:          v.ID,
+			Bandwidth:   v.Bandwidth,
+			Codecs:      v.Codecs,
+			Width:       v.Width,
+			Height:      v.Height,
+			SegmentList: dashSegmentList(v),
+		})
+	}
+	mpd.Period.AdaptationSets = append(mpd.Period.AdaptationSets, videoSet)
+
+	for i, a := range audios {
+		set := dash.AdaptationSet{
+			ID:               len(mpd.Period.AdaptationSets),
+			ContentType:      "audio",
+			MimeType:         "audio/mp4",
+			Lang:             a.Language.ISO6391,
+			Label:            a.Language.LanguageNameSystem,
+			SegmentAlignment: true,
+			Representations: []dash.Representation{{
+				ID:                a.ID,
+				Bandwidth:         a.Bandwidth,
+				Codecs:            a.Codecs,
+				AudioSamplingRate: 48000,
+				AudioChannels: &dash.AudioChannelConfiguration{
+					SchemeIDURI: "urn:mpeg:dash:23003:3:audio_channel_configuration:2011",
+					Value:       2,
+				},
+				SegmentList: dashSegmentList(a),
+			}},
+		}
+		if i == 0 {
+			set.Roles = []dash.Role{{SchemeIDURI: "urn:mpeg:dash:role:2011", Value: "main"}}
+		}
+		mpd.Period.AdaptationSets = append(mpd.Period.AdaptationSets, set)
+	}
+
+	for _, s := range subtitles {
+		mpd.Period.AdaptationSets = append(mpd.Period.AdaptationSets, dash.AdaptationSet{
+			ID:          len(mpd.Period.AdaptationSets),
+			ContentType: "text",
+			MimeType:    "text/vtt",
+			Lang:        s.Language.ISO6391,
+			Label:       s.Language.LanguageNameSystem,
+			Roles:       []dash.Role{{SchemeIDURI: "urn:mpeg:dash:role:2011", Value: "subtitle"}},
+			Representations: []dash.Representation{{
+				ID:        strings.TrimSuffix(s.File, filepath.Ext(s.File)),
+				Bandwidth: 256,
+				BaseURL:   s.File,
+			}},
+		})
+	}
+
+	return mpd
+}
+
+func writeCMAFFile(dir, name string, data []byte) error {
+	return os.WriteFile(filepath.Join(dir, name), data, ffmpeg.OutputFileMode)
+}
@@ -0,0 +1,137 @@
+package transcode
+
+import (
+	"bufio"
+	"encoding/xml"
+	"strings"
+	"testing"
+
+	"github.com/bcc-code/bcc-media-flows/languages"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const ffmpegMediaPlaylist = `#EXTM3U
+#EXT-X-VERSION:7
+#EXT-X-TARGETDURATION:6
+#EXT-X-MEDIA-SEQUENCE:0
+#EXT-X-PLAYLIST-TYPE:VOD
+#EXT-X-INDEPENDENT-SEGMENTS
+#EXT-X-MAP:URI="video_1920x1080_init.mp4"
+#EXTINF:6.000000,
+video_1920x1080_00000.m4s
+#EXTINF:6.000000,
+video_1920x1080_00001.m4s
+#EXTINF:2.480000,
+video_1920x1080_00002.m4s
+#EXT-X-ENDLIST
+`
+
+func Test_parseMediaPlaylist(t *testing.T) {
+	init, segments, err := parseMediaPlaylist(bufio.NewScanner(strings.NewReader(ffmpegMediaPlaylist)))
+	require.NoError(t, err)
+
+	assert.Equal(t, "video_1920x1080_init.mp4", init)
+	assert.Equal(t, []cmafSegment{
+		{URI: "video_1920x1080_00000.m4s", Duration: 6},
+		{URI: "video_1920x1080_00001.m4s", Duration: 6},
+		{URI: "video_1920x1080_00002.m4s", Duration: 2.48},
+	}, segments)
+}
+
+func Test_parseMediaPlaylist_Empty(t *testing.T) {
+	_, _, err := parseMediaPlaylist(bufio.NewScanner(strings.NewReader("#EXTM3U\n#EXT-X-ENDLIST\n")))
+	assert.Error(t, err)
+}
+
+func Test_segmentBandwidth(t *testing.T) {
+	peak, average := segmentBandwidth(
+		[]cmafSegment{{Duration: 2}, {Duration: 2}},
+		[]int64{1000, 3000},
+	)
+
+	assert.Equal(t, 12000, peak)
+	assert.Equal(t, 8000, average)
+}
+
+func Test_avcCodecString(t *testing.T) {
+	assert.Equal(t, "avc1.640028", avcCodecString("High", 40))
+	assert.Equal(t, "avc1.4d001f", avcCodecString("Main", 31))
+	assert.Equal(t, "avc1.42e01e", avcCodecString("Constrained Baseline", 30))
+}
+
+func testRenditions() ([]cmafRendition, []cmafRendition, []cmafSubtitle) {
+	videos := []cmafRendition{{
+		ID:               "video_1920x1080",
+		Playlist:         "video_1920x1080.m3u8",
+		Init:             "video_1920x1080_init.mp4",
+		Segments:         []cmafSegment{{"video_1920x1080_00000.m4s", 6}, {"video_1920x1080_00001.m4s", 6}, {"video_1920x1080_00002.m4s", 2.48}},
+		Codecs:           "avc1.640028",
+		Width:            1920,
+		Height:           1080,
+		Bandwidth:        6000000,
+		AverageBandwidth: 5000000,
+	}}
+	audios := []cmafRendition{
+		{ID: "audio_nor", Playlist: "audio_nor.m3u8", Codecs: cmafAudioCodec, Bandwidth: 200000, AverageBandwidth: 190000, Language: languages.LanguagesByISO["nor"]},
+		{ID: "audio_eng", Playlist: "audio_eng.m3u8", Codecs: cmafAudioCodec, Bandwidth: 210000, AverageBandwidth: 190000, Language: languages.LanguagesByISO["eng"]},
+	}
+	subtitles := []cmafSubtitle{
+		{Playlist: "subs_nor.m3u8", File: "subs_nor.vtt", Language: languages.LanguagesByISO["nor"]},
+	}
+	return videos, audios, subtitles
+}
+
+func Test_hlsMasterPlaylist(t *testing.T) {
+	videos, audios, subtitles := testRenditions()
+
+	playlist := string(hlsMasterPlaylist(videos, audios, subtitles))
+
+	assert.True(t, strings.HasPrefix(playlist, "#EXTM3U\n"))
+	assert.Contains(t, playlist, `#EXT-X-MEDIA:TYPE=AUDIO,GROUP-ID="audio",LANGUAGE="nor",NAME="Norwegian",DEFAULT=YES,AUTOSELECT=YES,CHANNELS="2",URI="audio_nor.m3u8"`)
+	assert.Contains(t, playlist, `LANGUAGE="eng",NAME="English",DEFAULT=NO`)
+	assert.Contains(t, playlist, `#EXT-X-MEDIA:TYPE=SUBTITLES,GROUP-ID="subs",LANGUAGE="nor",NAME="Norwegian",DEFAULT=NO,AUTOSELECT=YES,URI="subs_nor.m3u8"`)
+	// The variant has to fit the largest audio rendition on top of its video.
+	assert.Contains(t, playlist, "#EXT-X-STREAM-INF:BANDWIDTH=6210000,AVERAGE-BANDWIDTH=5190000,RESOLUTION=1920x1080,"+
+		`CODECS="avc1.640028,mp4a.40.2",AUDIO="audio",SUBTITLES="subs"`+"\nvideo_1920x1080.m3u8\n")
+}
+
+func Test_hlsMasterPlaylist_VideoOnly(t *testing.T) {
+	videos, _, _ := testRenditions()
+
+	playlist := string(hlsMasterPlaylist(videos, nil, nil))
+
+	assert.NotContains(t, playlist, "#EXT-X-MEDIA")
+	assert.Contains(t, playlist, `CODECS="avc1.640028"`+"\n")
+}
+
+func Test_dashSegmentList_CollapsesRepeats(t *testing.T) {
+	videos, _, _ := testRenditions()
+
+	list := dashSegmentList(videos[0])
+
+	assert.Equal(t, "video_1920x1080_init.mp4", list.Initialization.SourceURL)
+	require.Len(t, list.SegmentTimeline.S, 2)
+	assert.Equal(t, int64(6000), list.SegmentTimeline.S[0].D)
+	assert.Equal(t, 1, list.SegmentTimeline.S[0].R)
+	assert.Equal(t, int64(0), *list.SegmentTimeline.S[0].T)
+	assert.Equal(t, int64(2480), list.SegmentTimeline.S[1].D)
+	assert.Len(t, list.SegmentURLs, 3)
+}
+
+func Test_dashManifest(t *testing.T) {
+	videos, audios, subtitles := testRenditions()
+
+	mpd := dashManifest(videos, audios, subtitles, 14.48)
+	data, err := xml.Marshal(mpd)
+	require.NoError(t, err)
+
+	assert.Equal(t, "PT14.480S", mpd.MediaPresentationDuration)
+	require.Len(t, mpd.Period.AdaptationSets, 4)
+	assert.Equal(t, "video", mpd.Period.AdaptationSets[0].ContentType)
+	assert.Equal(t, "nor", mpd.Period.AdaptationSets[1].Lang)
+	assert.Equal(t, "main", mpd.Period.AdaptationSets[1].Roles[0].Value)
+	assert.Empty(t, mpd.Period.AdaptationSets[2].Roles)
+	assert.Equal(t, "text/vtt", mpd.Period.AdaptationSets[3].MimeType)
+	assert.Contains(t, string(data), `<BaseURL>subs_nor.vtt</BaseURL>`)
+}
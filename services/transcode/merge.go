@@ -6,7 +6,6 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
-	"strconv"
 	"strings"
 
 	"github.com/bcc-code/bcc-media-flows/paths"
@@ -17,22 +16,29 @@ import (
 	"github.com/samber/lo"
 )
 
-func getFramerate(input common.MergeInput) (int, error) {
+// getFramerate is the frame rate of the longest item, which the others are converted
+// to. A rate timecode is not counted in is taken to be 25 or 50.
+func getFramerate(input common.MergeInput) (utils.TimecodeRate, error) {
 	longestItem := lo.MaxBy(input.Items, func(a common.MergeInputItem, b common.MergeInputItem) bool {
 		return a.End-a.Start > b.End-b.Start
 	})
 
 	info, err := ffmpeg.GetStreamInfo(longestItem.Path.Local())
 	if err != nil {
-		return 0, err
+		return utils.TimecodeRate{}, err
 	}
 
-	var rate = 25
+	return mergeFramerate(info), nil
+}
+
+func mergeFramerate(info ffmpeg.StreamInfo) utils.TimecodeRate {
+	if !info.TimecodeRate.IsZero() {
+		return info.TimecodeRate
+	}
 	if info.FrameRate > 40 {
-		rate = 50
+		return utils.Rate50
 	}
-
-	return rate, nil
+	return utils.Rate25
 }
 
 // MergeVideo takes a list of video files and merges them into one file.
@@ -78,7 +84,7 @@ func MergeVideo(input common.MergeInput, progressCallback ffmpeg.ProgressCallbac
 		"-profile:v", "3",
 		"-vendor", "ap10",
 		"-bits_per_mb", "8000",
-		"-r", strconv.Itoa(rate),
+		"-r", rate.FFmpeg(),
 		"-pix_fmt", "yuv422p10le",
 		"-color_primaries", "bt709",
 		"-color_trc", "bt709",
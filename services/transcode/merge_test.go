@@ -7,6 +7,8 @@ import (
 
 	"github.com/bcc-code/bcc-media-flows/common"
 	"github.com/bcc-code/bcc-media-flows/paths"
+	"github.com/bcc-code/bcc-media-flows/services/ffmpeg"
+	"github.com/bcc-code/bcc-media-flows/utils"
 	"github.com/bcc-code/bcc-media-flows/utils/testutils"
 	"github.com/stretchr/testify/assert"
 )
@@ -237,3 +239,15 @@ func Test_MergeSubtitles2(t *testing.T) {
 
 	assert.Equal(t, expected, actual)
 }
+
+func Test_mergeFramerate(t *testing.T) {
+	info := ffmpeg.ProbeResultToInfo(&ffmpeg.FFProbeResult{Streams: []ffmpeg.FFProbeStream{
+		{CodecType: "video", RFrameRate: "30000/1001"},
+	}})
+	assert.Equal(t, "30000/1001", mergeFramerate(info).FFmpeg())
+
+	assert.Equal(t, utils.Rate25, mergeFramerate(ffmpeg.StreamInfo{FrameRate: 25, TimecodeRate: utils.Rate25}))
+	// A rate timecode is not counted in is rounded to PAL.
+	assert.Equal(t, utils.Rate50, mergeFramerate(ffmpeg.StreamInfo{FrameRate: 48}))
+	assert.Equal(t, utils.Rate25, mergeFramerate(ffmpeg.StreamInfo{FrameRate: 15}))
+}
@@ -8,6 +8,7 @@ import (
 	"github.com/bcc-code/bcc-media-flows/paths"
 	"github.com/bcc-code/bcc-media-flows/services/ffmpeg"
 	"github.com/bcc-code/bcc-media-flows/services/transcode"
+	"github.com/bcc-code/bcc-media-flows/utils"
 	"github.com/bcc-code/bcc-media-flows/utils/testutils"
 	"github.com/stretchr/testify/assert"
 )
@@ -28,7 +29,7 @@ func Test_MuxToSimpleMXF(t *testing.T) {
 		Profile:   "3",
 	})
 
-	transcode.GenerateToneFile(1000, 3, 48000, "01:00:00:00", audioFile)
+	transcode.GenerateToneFile(1000, 3, 48000, utils.MustParseTimecode("01:00:00:00", utils.Rate25), audioFile)
 
 	res, err := transcode.MuxToSimpleMXF(common.SimpleMuxInput{
 		FileName:        "test_output",
@@ -69,8 +70,8 @@ func Test_MuxToSimpleMXF_MultipleAudio(t *testing.T) {
 		Profile:   "3",
 	})
 
-	transcode.GenerateToneFile(1000, 3, 48000, "01:00:00:00", audioFile1)
-	transcode.GenerateToneFile(500, 3, 48000, "01:00:00:00", audioFile2)
+	transcode.GenerateToneFile(1000, 3, 48000, utils.MustParseTimecode("01:00:00:00", utils.Rate25), audioFile1)
+	transcode.GenerateToneFile(500, 3, 48000, utils.MustParseTimecode("01:00:00:00", utils.Rate25), audioFile2)
 
 	res, err := transcode.MuxToSimpleMXF(common.SimpleMuxInput{
 		FileName:        "test_multi_audio",
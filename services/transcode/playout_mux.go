@@ -8,6 +8,7 @@ import (
 
 	"github.com/bcc-code/bcc-media-flows/common"
 	"github.com/bcc-code/bcc-media-flows/services/ffmpeg"
+	"github.com/bcc-code/bcc-media-flows/utils"
 )
 
 /***
@@ -213,22 +214,56 @@ func generateFFmpegParamsForPlayoutMux(input common.PlayoutMuxInput, outputPath
 	params = append(params,
 		"-c:v", "copy",
 		"-c:a", "pcm_s24le",
-		"-y", outputPath,
 	)
+	if input.Timecode != "" {
+		params = append(params, "-timecode", input.Timecode)
+	}
+	params = append(params, "-y", outputPath)
 	return params, nil
 }
 
+// playoutTimecode is the timecode of a video written for its rate. The XDCAM for playout
+// is 25 fps, but it keeps the timecode of what it was made from, which is not valid at
+// 25 fps when that was 29.97 or 59.94, and which the MXF muxer refuses when it is
+// drop-frame. Such a timecode is moved to the same time at the rate of the video. It is
+// empty when there is no timecode, or it cannot be read.
+func playoutTimecode(tag string, rate utils.TimecodeRate) string {
+	if tag == "" || rate.IsZero() {
+		return ""
+	}
+
+	tc, err := utils.ParseTimecode(tag, rate)
+	if err == nil {
+		return tc.String()
+	}
+
+	for _, from := range []utils.TimecodeRate{utils.Rate2997, utils.Rate5994} {
+		tc, err := utils.ParseTimecode(tag, from)
+		if err == nil {
+			return tc.In(rate).String()
+		}
+	}
+	return ""
+}
+
 func PlayoutMux(input common.PlayoutMuxInput, progressCallback ffmpeg.ProgressCallback) (*common.PlayoutMuxResult, error) {
 	base := input.VideoFilePath.Base()
 	fileNameWithoutExtension := base[:len(base)-len(filepath.Ext(base))]
 	outputFilePath := filepath.Join(input.OutputDir.Local(), fileNameWithoutExtension+".mxf")
 
-	params, err := generateFFmpegParamsForPlayoutMux(input, outputFilePath)
+	info, err := ffmpeg.GetStreamInfo(input.VideoFilePath.Local())
 	if err != nil {
 		return nil, err
 	}
 
-	info, err := ffmpeg.GetStreamInfo(input.VideoFilePath.Local())
+	if input.Timecode == "" {
+		tag, err := ffmpeg.GetTimeCode(input.VideoFilePath.Local())
+		if err == nil {
+			input.Timecode = playoutTimecode(tag, info.TimecodeRate)
+		}
+	}
+
+	params, err := generateFFmpegParamsForPlayoutMux(input, outputFilePath)
 	if err != nil {
 		return nil, err
 	}
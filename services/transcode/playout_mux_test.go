@@ -6,6 +6,7 @@ import (
 
 	"github.com/bcc-code/bcc-media-flows/common"
 	"github.com/bcc-code/bcc-media-flows/paths"
+	"github.com/bcc-code/bcc-media-flows/utils"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -114,3 +115,29 @@ func Test_generateFFmpegParamsForPlayoutMux_WithFallback(t *testing.T) {
 	assert.Contains(t, joined, "-c:v copy")
 	assert.Contains(t, joined, "-c:a pcm_s24le")
 }
+
+func Test_generateFFmpegParamsForPlayoutMux_Timecode(t *testing.T) {
+	input := common.PlayoutMuxInput{
+		VideoFilePath:    paths.MustParse("/mnt/isilon/test.mxf"),
+		AudioFilePaths:   map[string]paths.Path{"nor": paths.MustParse("/mnt/temp/audio_nor.wav")},
+		FallbackLanguage: "nor",
+		Timecode:         "10:00:00:00",
+	}
+
+	params, err := generateFFmpegParamsForPlayoutMux(input, "/tmp/output.mxf")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"-timecode", "10:00:00:00", "-y", "/tmp/output.mxf"}, params[len(params)-4:])
+}
+
+func Test_playoutTimecode(t *testing.T) {
+	assert.Equal(t, "10:00:00:00", playoutTimecode("10:00:00:00", utils.Rate25))
+	assert.Equal(t, "01:00:00;00", playoutTimecode("01:00:00;00", utils.Rate2997))
+
+	// 29.97 drop-frame on a 25 fps XDCAM is moved to the same time at 25 fps: ten hours
+	// of drop-frame timecode are 36ms short of ten hours.
+	assert.Equal(t, "09:59:59:24", playoutTimecode("10:00:00;00", utils.Rate25))
+	assert.Equal(t, "00:00:10:00", playoutTimecode("00:00:10;00", utils.Rate25))
+
+	assert.Equal(t, "", playoutTimecode("", utils.Rate25))
+	assert.Equal(t, "", playoutTimecode("10:00:00:00", utils.TimecodeRate{}))
+}
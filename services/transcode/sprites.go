@@ -0,0 +1,152 @@
+package transcode
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/bcc-code/bcc-media-flows/common"
+	"github.com/bcc-code/bcc-media-flows/services/ffmpeg"
+	"github.com/bcc-code/bcc-media-flows/services/subtitles"
+)
+
+const (
+	thumbnailsVTT     = "thumbnails.vtt"
+	thumbnailsPattern = "thumbnails_%03d"
+)
+
+// spriteLayout is where thumbnails go on the sprite sheets.
+type spriteLayout struct {
+	Interval int
+	Width    int
+	Height   int
+	Columns  int
+	Rows     int
+	Ext      string
+}
+
+func (l spriteLayout) perSheet() int {
+	return l.Columns * l.Rows
+}
+
+// sheet is the filename of a sprite sheet, counted from 1 like ffmpeg's image2 muxer.
+func (l spriteLayout) sheet(n int) string {
+	return fmt.Sprintf(thumbnailsPattern, n) + l.Ext
+}
+
+// thumbnailCues points a cue per interval of the duration at its tile. The fps filter
+// takes a frame at the start of every interval, so the last, shorter interval has one.
+func thumbnailCues(layout spriteLayout, duration float64) []subtitles.Cue {
+	count := int(math.Ceil(duration / float64(layout.Interval)))
+	interval := time.Duration(layout.Interval) * time.Second
+	end := time.Duration(duration * float64(time.Second))
+
+	var cues []subtitles.Cue
+	for i := 0; i < count; i++ {
+		tile := i % layout.perSheet()
+		x := (tile % layout.Columns) * layout.Width
+		y := (tile / layout.Columns) * layout.Height
+
+		cue := subtitles.Cue{
+			Start: time.Duration(i) * interval,
+			End:   min(time.Duration(i+1)*interval, end),
+			Lines: []string{fmt.Sprintf("%s#xywh=%d,%d,%d,%d", layout.sheet(i/layout.perSheet()+1), x, y, layout.Width, layout.Height)},
+		}
+		cues = append(cues, cue)
+	}
+	return cues
+}
+
+// ThumbnailSprites takes a thumbnail of the video at an interval, tiles them on sprite
+// sheets and writes a WebVTT thumbnails track for players to scrub with.
+func ThumbnailSprites(input common.ThumbnailSpritesInput, cb ffmpeg.ProgressCallback) (*common.ThumbnailSpritesResult, error) {
+	info, err := ffmpeg.GetStreamInfo(input.FilePath.Local())
+	if err != nil {
+		return nil, err
+	}
+	if !info.HasVideo || info.Width == 0 || info.Height == 0 {
+		return nil, fmt.Errorf("%s has no video to take thumbnails of", input.FilePath.Local())
+	}
+
+	layout := spriteLayout{
+		Interval: input.Interval,
+		Width:    input.Width,
+		Columns:  input.Columns,
+		Rows:     input.Rows,
+		Ext:      ".jpg",
+	}
+	if layout.Interval <= 0 {
+		layout.Interval = 10
+	}
+	if layout.Width <= 0 {
+		layout.Width = 160
+	}
+	if layout.Columns <= 0 {
+		layout.Columns = 10
+	}
+	if layout.Rows <= 0 {
+		layout.Rows = 10
+	}
+	// Even, as some players and encoders want even dimensions.
+	layout.Height = int(math.Round(float64(layout.Width)*float64(info.Height)/float64(info.Width)/2)) * 2
+
+	codecArgs := []string{"-q:v", "3"}
+	switch input.Format {
+	case "", "jpg":
+	case "webp":
+		layout.Ext = ".webp"
+		codecArgs = []string{"-c:v", "libwebp", "-quality", "75"}
+	default:
+		return nil, fmt.Errorf("unknown thumbnail format %q", input.Format)
+	}
+
+	outputDir := input.DestinationPath.Local()
+	err = os.MkdirAll(outputDir, ffmpeg.OutputDirMode)
+	if err != nil {
+		return nil, err
+	}
+
+	args := []string{
+		"-map", "0:v:0",
+		"-vf", fmt.Sprintf("fps=1/%d,scale=%d:%d,tile=%dx%d", layout.Interval, layout.Width, layout.Height, layout.Columns, layout.Rows),
+		"-an",
+	}
+	_, err = ffmpeg.Run(ffmpeg.Job{
+		Input:  input.FilePath.Local(),
+		Output: filepath.Join(outputDir, thumbnailsPattern+layout.Ext),
+		Args:   append(args, codecArgs...),
+		Info:   &info,
+	}, cb)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create thumbnail sprites: %w", err)
+	}
+
+	result := &common.ThumbnailSpritesResult{
+		VTTFile: input.DestinationPath.Append(thumbnailsVTT),
+	}
+	for n := 1; ; n++ {
+		sheet := input.DestinationPath.Append(layout.sheet(n))
+		if _, err := os.Stat(sheet.Local()); err != nil {
+			break
+		}
+		result.Sprites = append(result.Sprites, sheet)
+	}
+	if len(result.Sprites) == 0 {
+		return nil, fmt.Errorf("ffmpeg wrote no sprite sheets")
+	}
+
+	// ffmpeg rounds the frame at the very end either way, so a cue past the last tile it
+	// wrote is dropped rather than pointing at nothing.
+	cues := thumbnailCues(layout, info.TotalSeconds)
+	cues = cues[:min(len(cues), len(result.Sprites)*layout.perSheet())]
+
+	track := &subtitles.Subtitles{Cues: cues}
+	err = os.WriteFile(result.VTTFile.Local(), track.MarshalVTT(), ffmpeg.OutputFileMode)
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
@@ -0,0 +1,33 @@
+package transcode
+
+import (
+	"testing"
+	"time"
+
+	"github.com/bcc-code/bcc-media-flows/services/subtitles"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_thumbnailCues(t *testing.T) {
+	layout := spriteLayout{Interval: 10, Width: 160, Height: 90, Columns: 2, Rows: 2, Ext: ".jpg"}
+
+	cues := thumbnailCues(layout, 45)
+
+	require.Len(t, cues, 5)
+	assert.Equal(t, subtitles.Cue{Start: 0, End: 10 * time.Second, Lines: []string{"thumbnails_001.jpg#xywh=0,0,160,90"}}, cues[0])
+	assert.Equal(t, []string{"thumbnails_001.jpg#xywh=160,0,160,90"}, cues[1].Lines)
+	assert.Equal(t, []string{"thumbnails_001.jpg#xywh=160,90,160,90"}, cues[3].Lines)
+	assert.Equal(t, subtitles.Cue{Start: 40 * time.Second, End: 45 * time.Second, Lines: []string{"thumbnails_002.jpg#xywh=0,0,160,90"}}, cues[4],
+		"the last cue ends with the video, on the next sheet")
+}
+
+func Test_thumbnailCues_VTT(t *testing.T) {
+	layout := spriteLayout{Interval: 5, Width: 100, Height: 56, Columns: 10, Rows: 10, Ext: ".webp"}
+
+	vtt := (&subtitles.Subtitles{Cues: thumbnailCues(layout, 7.5)}).MarshalVTT()
+
+	assert.Equal(t, "WEBVTT\n\n"+
+		"00:00:00.000 --> 00:00:05.000\nthumbnails_001.webp#xywh=0,0,100,56\n\n"+
+		"00:00:05.000 --> 00:00:07.500\nthumbnails_001.webp#xywh=100,0,100,56\n\n", string(vtt))
+}
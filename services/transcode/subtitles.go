@@ -11,6 +11,7 @@ import (
 
 	"github.com/bcc-code/bcc-media-flows/paths"
 	"github.com/bcc-code/bcc-media-flows/services/ffmpeg"
+	"github.com/bcc-code/bcc-media-flows/services/subtitles"
 )
 
 func SubtitleBurnIn(videoFile, subtitleFile, subtitleHeader, outputPath paths.Path, progressCallback ffmpeg.ProgressCallback) (*paths.Path, error) {
@@ -59,34 +60,14 @@ func CreateBurninASSFile(subtitleHeader, subtitleFile paths.Path) (*paths.Path,
 		return &out, specialASSConverter(string(headerData), subtitleFile.Local(), out.Local(), 0.00005)
 	}
 
-	// Converting a subtitle file to ASS, so there is nothing to probe and no
-	// progress to report.
-	_, err = ffmpeg.Run(ffmpeg.Job{
-		Input:  subtitleFile.Local(),
-		Output: out.Local(),
-		Info:   &ffmpeg.StreamInfo{},
-	}, nil)
+	// The header ends with [Events], so the events of the subtitle file, in whichever
+	// format it is, follow it.
+	subs, err := subtitles.ReadFile(subtitleFile.Local())
 	if err != nil {
 		return nil, err
 	}
-	subtitleContents, err := os.ReadFile(out.Local())
-	if err != nil {
-		return nil, err
-	}
-	eventsTagPassed := false
-	var lines []string
-	for _, l := range strings.Split(string(subtitleContents), "\n") {
-		if strings.HasPrefix(l, "[Events]") {
-			eventsTagPassed = true
-			continue
-		}
-		if !eventsTagPassed {
-			continue
-		}
-		lines = append(lines, l)
-	}
 
-	err = os.WriteFile(out.Local(), []byte(string(headerData)+"\n"+strings.Join(lines, "\n")), ffmpeg.OutputFileMode)
+	err = os.WriteFile(out.Local(), []byte(string(headerData)+"\n"+string(subs.MarshalASSEvents())), ffmpeg.OutputFileMode)
 	if err != nil {
 		return nil, err
 	}
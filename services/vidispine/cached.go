@@ -0,0 +1,73 @@
+package vidispine
+
+import (
+	"context"
+	"time"
+
+	"github.com/bcc-code/bcc-media-flows/cache"
+	"github.com/bcc-code/bcc-media-flows/services/vidispine/vsapi"
+)
+
+// CachedClient serves GetMetadata from the cache package's default store. Changes made
+// through it drop what it has cached for the item; changes made anywhere else, such as
+// in Cantemo, are seen once the TTL has run out.
+//
+// Shapes are not cached: their files are moved, registered and change state by file ID,
+// here and in Cantemo, and workflows read them back right after to see that it worked.
+type CachedClient struct {
+	Client
+	TTL time.Duration
+}
+
+func NewCachedClient(client Client, ttl time.Duration) *CachedClient {
+	return &CachedClient{Client: client, TTL: ttl}
+}
+
+func metadataCacheKey(vxID string) string { return "vidispine:metadata:" + vxID }
+
+func (c *CachedClient) forget(vxIDs ...string) {
+	for _, vxID := range vxIDs {
+		cache.Delete(metadataCacheKey(vxID))
+	}
+}
+
+func (c *CachedClient) GetMetadata(vsID string) (*vsapi.MetadataResult, error) {
+	return cache.GetOrSetWithTTL(metadataCacheKey(vsID), c.TTL, func() (*vsapi.MetadataResult, error) {
+		return c.Client.GetMetadata(vsID)
+	})
+}
+
+func (c *CachedClient) AddFileToPlaceholder(itemID, fileID, tag string, fileState vsapi.FileState) (string, error) {
+	defer c.forget(itemID)
+	return c.Client.AddFileToPlaceholder(itemID, fileID, tag, fileState)
+}
+
+func (c *CachedClient) AddShapeToItem(shapeTag, itemVXID, fileVXID string) (string, error) {
+	defer c.forget(itemVXID)
+	return c.Client.AddShapeToItem(shapeTag, itemVXID, fileVXID)
+}
+
+func (c *CachedClient) AddSidecarToItem(itemVXID, filePath, language string) (string, error) {
+	defer c.forget(itemVXID)
+	return c.Client.AddSidecarToItem(itemVXID, filePath, language)
+}
+
+func (c *CachedClient) AddToItemMetadataField(params vsapi.ItemMetadataFieldParams) error {
+	defer c.forget(params.ItemID)
+	return c.Client.AddToItemMetadataField(params)
+}
+
+func (c *CachedClient) SetItemMetadataField(params vsapi.ItemMetadataFieldParams) error {
+	defer c.forget(params.ItemID)
+	return c.Client.SetItemMetadataField(params)
+}
+
+func (c *CachedClient) DeleteShape(assetID, shapeID string) error {
+	defer c.forget(assetID)
+	return c.Client.DeleteShape(assetID, shapeID)
+}
+
+func (c *CachedClient) DeleteItems(ctx context.Context, itemVXIDs []string, deleteFiles bool) error {
+	defer c.forget(itemVXIDs...)
+	return c.Client.DeleteItems(ctx, itemVXIDs, deleteFiles)
+}
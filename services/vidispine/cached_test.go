@@ -0,0 +1,61 @@
+package vidispine
+
+import (
+	"testing"
+	"time"
+
+	"github.com/bcc-code/bcc-media-flows/cache"
+	"github.com/bcc-code/bcc-media-flows/services/vidispine/vsapi"
+	"github.com/bcc-code/bcc-media-flows/services/vidispine/vsmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func withCacheStore(t *testing.T) {
+	t.Helper()
+
+	previous := cache.Default()
+	cache.SetDefault(cache.NewMemory(0))
+	t.Cleanup(func() { cache.SetDefault(previous) })
+}
+
+func TestCachedClient_ServesRepeatLookupsFromCache(t *testing.T) {
+	withCacheStore(t)
+	ctrl := gomock.NewController(t)
+	mock := vsmock.NewMockClient(ctrl)
+
+	mock.EXPECT().GetMetadata("VX-1").Return(&vsapi.MetadataResult{ID: "VX-1"}, nil).Times(1)
+	// Shapes change by file ID, where the client cannot tell the item, so they are not cached.
+	mock.EXPECT().GetShapes("VX-1").Return(&vsapi.ShapeResult{ID: "VX-1"}, nil).Times(2)
+
+	client := NewCachedClient(mock, time.Minute)
+	for range 2 {
+		meta, err := client.GetMetadata("VX-1")
+		require.NoError(t, err)
+		assert.Equal(t, "VX-1", meta.ID)
+
+		shapes, err := client.GetShapes("VX-1")
+		require.NoError(t, err)
+		assert.Equal(t, "VX-1", shapes.ID)
+	}
+}
+
+func TestCachedClient_WritesDropTheItem(t *testing.T) {
+	withCacheStore(t)
+	ctrl := gomock.NewController(t)
+	mock := vsmock.NewMockClient(ctrl)
+
+	mock.EXPECT().GetMetadata("VX-1").Return(&vsapi.MetadataResult{ID: "VX-1"}, nil).Times(2)
+	mock.EXPECT().GetMetadata("VX-2").Return(&vsapi.MetadataResult{ID: "VX-2"}, nil).Times(1)
+	mock.EXPECT().SetItemMetadataField(gomock.Any()).Return(nil)
+
+	client := NewCachedClient(mock, time.Minute)
+	_, _ = client.GetMetadata("VX-1")
+	_, _ = client.GetMetadata("VX-2")
+
+	require.NoError(t, client.SetItemMetadataField(vsapi.ItemMetadataFieldParams{ItemID: "VX-1", Key: "title", Value: "x"}))
+
+	_, _ = client.GetMetadata("VX-1")
+	_, _ = client.GetMetadata("VX-2")
+}
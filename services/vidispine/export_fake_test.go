@@ -0,0 +1,68 @@
+package vidispine_test
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/bcc-code/bcc-media-flows/paths"
+	"github.com/bcc-code/bcc-media-flows/services/vidispine"
+	"github.com/bcc-code/bcc-media-flows/services/vidispine/vsapi"
+	"github.com/bcc-code/bcc-media-flows/services/vidispine/vsfake"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// The same exports as the mock tests, but through the real client against a fake
+// Vidispine, so they do not depend on which calls GetDataForExport makes and how often.
+func Test_GetDataForExport_FakeVidispine(t *testing.T) {
+	fake := vsfake.New()
+	require.NoError(t, fake.LoadDir("testdata"))
+	server := httptest.NewServer(fake)
+	t.Cleanup(server.Close)
+	client := vsapi.NewClient(vsfake.Config{URL: server.URL})
+
+	cases := []struct {
+		name        string
+		vxID        string
+		subclip     string
+		blankAssets bool
+	}{
+		{name: "sequence with chapters", vxID: "VX-431566"},
+		{name: "sequence with embedded audio", vxID: "VX-464406", subclip: "Kåre J. Smith - Tale"},
+		{name: "asset", vxID: "VX-464458"},
+		{name: "subclip", vxID: "VX-460824", subclip: "Kåre J. Smith - Tale"},
+		{name: "sequence with subtitles", vxID: "VX-447459", blankAssets: true},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			expected := &vidispine.ExportData{}
+			fromJSONFile(expected, "testdata/GetDataForExport/"+tc.vxID+".json")
+			if tc.blankAssets {
+				useLocalBlankAssets(expected)
+			}
+
+			res, err := vidispine.GetDataForExport(client, tc.vxID, nil, nil, tc.subclip, false)
+			require.NoError(t, err)
+			assert.Equal(t, expected, res)
+		})
+	}
+}
+
+// useLocalBlankAssets points the blank audio and subtitles the expected data was
+// recorded with at where they are here.
+func useLocalBlankAssets(expected *vidispine.ExportData) {
+	for _, clip := range expected.Clips {
+		for _, audio := range clip.AudioFiles {
+			if strings.HasSuffix(audio.File, "BlankAudio10h.wav") {
+				audio.File = paths.Path{Drive: paths.Drive{Value: "isilon"}, Path: "/system/assets/BlankAudio10h.wav"}.Local()
+			}
+		}
+		for lang, file := range clip.SubtitleFiles {
+			if strings.HasSuffix(file, "empty.srt") {
+				clip.SubtitleFiles[lang] = paths.Path{Drive: paths.Drive{Value: "isilon"}, Path: "/system/assets/empty.srt"}.Local()
+			}
+		}
+	}
+}
@@ -40,6 +40,7 @@ type Client interface {
 	FileExistsInStorage(storageID, absoluteFilePath string) (bool, error)
 
 	SearchByMetadataField(name, value string) ([]string, error)
+	SearchItems(p vsapi.ItemSearchParams) (*vsapi.ItemSearchResult, error)
 	SetItemMetadataField(params vsapi.ItemMetadataFieldParams) error
 
 	UpdateFileState(fileID string, fileState vsapi.FileState) error
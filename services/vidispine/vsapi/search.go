@@ -82,9 +82,63 @@ func (c *Client) SearchByMetadataField(name, value string) ([]string, error) {
 // <value> elements. Multiple values on one field are OR-ed together by
 // Vidispine, so it is the right shape for "media type is video OR audio".
 type searchFieldMulti struct {
-	XMLName xml.Name `xml:"field"`
-	Name    string   `xml:"name"`
-	Values  []string `xml:"value"`
+	XMLName xml.Name     `xml:"field"`
+	Name    string       `xml:"name"`
+	Values  []string     `xml:"value"`
+	Range   *searchRange `xml:"range,omitempty"`
+}
+
+type searchRange struct {
+	Values []string `xml:"value"`
+}
+
+// SearchRange matches the values of a field from Start to End, both included. Dates
+// are compared as Vidispine stores them, such as "2024-01-02T10:00:00".
+type SearchRange struct {
+	Field string
+	Start string
+	End   string
+}
+
+// SearchOperator combines criteria with the Vidispine operation AND, OR or NOT, for
+// searches the plain criteria of ItemSearchParams cannot express. NOT matches the items
+// its criteria together do not. A field with the value "*" matches any value.
+type SearchOperator struct {
+	Operation string
+	Fields    []SearchField
+	Ranges    []SearchRange
+	Operators []SearchOperator
+}
+
+// SearchField matches the items with one of the values in a field.
+type SearchField struct {
+	Name   string
+	Values []string
+}
+
+type searchOperator struct {
+	XMLName   xml.Name           `xml:"operator"`
+	Operation string             `xml:"operation,attr"`
+	Fields    []searchFieldMulti `xml:"field,omitempty"`
+	Operators []searchOperator   `xml:"operator,omitempty"`
+}
+
+func (o SearchOperator) document() searchOperator {
+	doc := searchOperator{Operation: o.Operation}
+	for _, f := range o.Fields {
+		doc.Fields = append(doc.Fields, searchFieldMulti{Name: f.Name, Values: f.Values})
+	}
+	for _, r := range o.Ranges {
+		doc.Fields = append(doc.Fields, r.document())
+	}
+	for _, op := range o.Operators {
+		doc.Operators = append(doc.Operators, op.document())
+	}
+	return doc
+}
+
+func (r SearchRange) document() searchFieldMulti {
+	return searchFieldMulti{Name: r.Field, Range: &searchRange{Values: []string{r.Start, r.End}}}
 }
 
 // searchFacetRequest asks Vidispine to return value counts for a field.
@@ -94,13 +148,14 @@ type searchFacetRequest struct {
 }
 
 // fullItemSearchDocument is the search body used by SearchItems. Element order
-// (text, field, facet) follows the ItemSearchDocument schema sequence.
+// (text, field, operator, facet) follows the ItemSearchDocument schema sequence.
 type fullItemSearchDocument struct {
-	XMLName xml.Name             `xml:"ItemSearchDocument"`
-	Xmlns   string               `xml:"xmlns,attr"`
-	Text    string               `xml:"text,omitempty"`
-	Fields  []searchFieldMulti   `xml:"field,omitempty"`
-	Facets  []searchFacetRequest `xml:"facet,omitempty"`
+	XMLName   xml.Name             `xml:"ItemSearchDocument"`
+	Xmlns     string               `xml:"xmlns,attr"`
+	Text      string               `xml:"text,omitempty"`
+	Fields    []searchFieldMulti   `xml:"field,omitempty"`
+	Operators []searchOperator     `xml:"operator,omitempty"`
+	Facets    []searchFacetRequest `xml:"facet,omitempty"`
 }
 
 // SearchFacetCount is one value/count pair within a facet result.
@@ -137,6 +192,12 @@ type ItemSearchParams struct {
 	Number int
 	// Facet, when true, requests mediaType value counts in the response.
 	Facet bool
+	// HasField optionally restricts results to items with a value in this field.
+	HasField string
+	// Ranges optionally restrict results to items with a value in each range.
+	Ranges []SearchRange
+	// Operators optionally restrict results to items each of them matches.
+	Operators []SearchOperator
 }
 
 // SearchItems runs a paginated item search and returns the items' terse
@@ -158,6 +219,15 @@ func (c *Client) SearchItems(p ItemSearchParams) (*ItemSearchResult, error) {
 	if len(p.MediaTypes) > 0 {
 		doc.Fields = append(doc.Fields, searchFieldMulti{Name: "mediaType", Values: p.MediaTypes})
 	}
+	if p.HasField != "" {
+		doc.Fields = append(doc.Fields, searchFieldMulti{Name: p.HasField, Values: []string{"*"}})
+	}
+	for _, r := range p.Ranges {
+		doc.Fields = append(doc.Fields, r.document())
+	}
+	for _, o := range p.Operators {
+		doc.Operators = append(doc.Operators, o.document())
+	}
 	if p.Facet {
 		doc.Facets = append(doc.Facets, searchFacetRequest{Field: "mediaType"})
 	}
@@ -32,6 +32,25 @@ func Test_fullItemSearchDocument_XML(t *testing.T) {
 	assert.Less(t, strings.Index(s, "<field>"), strings.Index(s, "<facet>"))
 }
 
+func Test_SearchOperator_XML(t *testing.T) {
+	doc := fullItemSearchDocument{
+		Xmlns: "http://xml.vidispine.com/schema/vidispine",
+		Operators: []searchOperator{SearchOperator{
+			Operation: "OR",
+			Ranges:    []SearchRange{{Field: "created", Start: "1000-01-01", End: "2024-01-01"}},
+			Operators: []SearchOperator{{Operation: "NOT", Fields: []SearchField{{Name: "portal_archived", Values: []string{"*"}}}}},
+		}.document()},
+	}
+
+	out, err := xml.Marshal(doc)
+	assert.NoError(t, err)
+	assert.Equal(t, `<ItemSearchDocument xmlns="http://xml.vidispine.com/schema/vidispine">`+
+		`<operator operation="OR">`+
+		`<field><name>created</name><range><value>1000-01-01</value><value>2024-01-01</value></range></field>`+
+		`<operator operation="NOT"><field><name>portal_archived</name><value>*</value></field></operator>`+
+		`</operator></ItemSearchDocument>`, string(out))
+}
+
 func Test_fullItemSearchDocument_XML_TextEscaped(t *testing.T) {
 	doc := fullItemSearchDocument{
 		Xmlns: "http://xml.vidispine.com/schema/vidispine",
@@ -5,6 +5,7 @@ import (
 	"errors"
 	"fmt"
 	"net/url"
+	"strings"
 
 	"github.com/davecgh/go-spew/spew"
 
@@ -236,13 +237,11 @@ func (sr ShapeResult) GetShape(tag string) *Shape {
 func (s Shape) GetPath() string {
 
 	if len(s.ContainerComponent.File) > 0 {
-		// Cut off the "file://" prefix
 		for _, fc := range s.ContainerComponent.File {
 			if len(fc.URI) == 0 {
 				continue
 			}
-			p, _ := url.PathUnescape(fc.URI[0][7:])
-			return p
+			return uriPath(fc.URI[0])
 		}
 	}
 
@@ -252,14 +251,30 @@ func (s Shape) GetPath() string {
 			if len(f.URI) == 0 {
 				continue
 			}
-			p, _ := url.PathUnescape(f.URI[0][7:])
-			return p
+			return uriPath(f.URI[0])
 		}
 	}
 
 	return ""
 }
 
+// uriPath is the local path of a file:// URI. A file on an S3 storage, such as the
+// cold archive, is left an s3:// URI, without the credentials it may carry.
+func uriPath(uri string) string {
+	if rest, ok := strings.CutPrefix(uri, "file://"); ok {
+		p, _ := url.PathUnescape(rest)
+		return p
+	}
+
+	u, err := url.Parse(uri)
+	if err != nil {
+		return ""
+	}
+	u.User = nil
+	p, _ := url.PathUnescape(u.String())
+	return p
+}
+
 ///// SUPPORTING TYPES /////
 
 type ShapeResult struct {
@@ -61,3 +61,13 @@ func Test_GetPath(t *testing.T) {
 	path := sr.GetShape("tag1").GetPath()
 	assert.Equal(t, "/path/to/file", path)
 }
+
+func Test_GetPath_S3(t *testing.T) {
+	shape := Shape{
+		ContainerComponent: ContainerComponent{
+			File: []File{{URI: []string{"s3://KEY:SECRET@cold-archive/2024/01/02/A%20file.mxf"}}},
+		},
+	}
+
+	assert.Equal(t, "s3://cold-archive/2024/01/02/A file.mxf", shape.GetPath())
+}
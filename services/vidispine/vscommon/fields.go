@@ -43,10 +43,14 @@ var (
 	FieldAssetAudioCodec       = FieldType{"ASSET_AUDIO_CODEC"}
 	FieldOriginalAudioCodec    = FieldType{"originalAudioCodec"}
 	FieldTranscribedLanguage   = FieldType{"portal_mf189205"}
+	FieldMediaQC               = FieldType{"portal_media_qc"}
+	FieldFixity                = FieldType{"portal_fixity"}
+	FieldArchived              = FieldType{"portal_archived"}
+	FieldLastUsed              = FieldType{"portal_last_used"}
 	FieldTypes                 = enum.New(FieldDurationSeconds, FieldDescription, FieldExportAudioSource, FieldLangsToExport,
 		FieldPersonsAppearing, FieldSequenceSize, FieldStartTC, FieldSubclipType, FieldTitle,
 		FieldSource, FieldExportAsChapter, FieldSubtransStoryID, FieldOriginalURI, FieldUploadedBy, FieldUploadJob,
 		FieldLanguagesRecorded, FieldGeneralTags, FieldOriginalFileName, FieldOriginalFileNameField,
 		FieldEpisodeDescription, FieldSeason, FieldProgram, FieldEpisode, FieldStlText, FieldIngested, FieldDialogLoudness,
-		FieldDialogPercentage, FieldBmmTrackID, FieldBmmTitle, FieldBmmTrackMetadataJSON, FieldAssetAudioCodec, FieldOriginalAudioCodec, FieldExportTCOverride, FieldSubclipExportTitle, FieldTranscribedLanguage)
+		FieldDialogPercentage, FieldBmmTrackID, FieldBmmTitle, FieldBmmTrackMetadataJSON, FieldAssetAudioCodec, FieldOriginalAudioCodec, FieldExportTCOverride, FieldSubclipExportTitle, FieldTranscribedLanguage, FieldMediaQC, FieldFixity, FieldArchived, FieldLastUsed)
 )
@@ -0,0 +1,704 @@
+package vsfake
+
+import (
+	"encoding/xml"
+	"math"
+	"net/http"
+	"path"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/bcc-code/bcc-media-flows/services/vidispine/vsapi"
+	"github.com/bcc-code/bcc-media-flows/services/vidispine/vscommon"
+	"github.com/gin-gonic/gin"
+	"github.com/samber/lo"
+)
+
+func (s *Server) routes(r *gin.Engine) {
+	r.GET("/item/:id", s.getItem)
+	r.PUT("/item/:id/metadata", s.putItemMetadata)
+	r.GET("/item/:id/sequence/vidispine", s.getSequence)
+	r.GET("/item/:id/relation", s.getRelations)
+	r.POST("/item/:id/shape", s.addShape)
+	r.DELETE("/item/:id/shape/:shape", s.deleteShape)
+	r.POST("/item/:id/thumbnail", s.createThumbnails)
+	r.PUT("/item", s.searchItems)
+	r.DELETE("/item", s.deleteItems)
+
+	r.POST("/import/placeholder", s.createPlaceholder)
+	r.POST("/import/placeholder/:id/container", s.addFileToPlaceholder)
+	r.POST("/import/sidecar/:id", s.addSidecar)
+
+	r.GET("/storage/:id/method", s.getStorageMethods)
+	r.GET("/storage/:id/file", s.listFiles)
+	r.POST("/storage/:id/file", s.registerFileHandler)
+	r.PUT("/file/:id/state/:state", s.updateFileState)
+	r.POST("/file/:id/storage/:storage", s.moveFile)
+
+	r.GET("/job", s.findJobs)
+	r.GET("/job/:id", s.getJob)
+
+	r.GET("/collection/:id/item", s.getCollectionItems)
+
+	r.PUT("/search", s.search)
+}
+
+// notFound answers the way Vidispine does, which vsapi turns into its errors.
+func notFound(c *gin.Context, kind, id string) {
+	c.JSON(http.StatusNotFound, gin.H{"notFound": gin.H{"type": kind, "id": id}})
+}
+
+func invalidInput(c *gin.Context, explanation string) {
+	c.JSON(http.StatusBadRequest, gin.H{"invalidInput": gin.H{"explanation": explanation}})
+}
+
+// lockedItem takes the lock and finds the item in the path, or answers 404 and returns
+// nil. The caller unlocks.
+func (s *Server) lockedItem(c *gin.Context) *item {
+	s.mu.Lock()
+	it, ok := s.items[c.Param("id")]
+	if !ok {
+		notFound(c, "item", c.Param("id"))
+		return nil
+	}
+	return it
+}
+
+func (s *Server) getItem(c *gin.Context) {
+	it := s.lockedItem(c)
+	defer s.mu.Unlock()
+	if it == nil {
+		return
+	}
+
+	if c.Query("content") == "shape" {
+		c.JSON(http.StatusOK, vsapi.ShapeResult{ID: it.id, Shape: it.shapes})
+		return
+	}
+
+	keys := c.QueryArray("field")
+	group := c.Query("group")
+	in, out := math.Inf(-1), math.Inf(1)
+	if interval := c.Query("interval"); interval != "" {
+		var err error
+		in, out, err = parseInterval(interval)
+		if err != nil {
+			invalidInput(c, err.Error())
+			return
+		}
+	}
+
+	c.JSON(http.StatusOK, it.metadata(func(f field) bool {
+		if len(keys) > 0 && !slices.Contains(keys, f.key) {
+			return false
+		}
+		if group != "" && f.group != group {
+			return false
+		}
+		start, end := spanSeconds(f.value)
+		return start < out && end > in
+	}))
+}
+
+// parseInterval parses the in-out interval, in seconds, that vsapi asks for timed
+// metadata in.
+func parseInterval(interval string) (float64, float64, error) {
+	inString, outString, _ := strings.Cut(interval, "-")
+	in, err := strconv.ParseFloat(inString, 64)
+	if err != nil {
+		return 0, 0, err
+	}
+	out, err := strconv.ParseFloat(outString, 64)
+	if err != nil {
+		return 0, 0, err
+	}
+	return in, out, nil
+}
+
+// spanSeconds is the part of the item a value is for, in seconds.
+func spanSeconds(value vsapi.MetadataField) (float64, float64) {
+	start, end := math.Inf(-1), math.Inf(1)
+	if value.Start != vsapi.MinusInf {
+		if seconds, err := vscommon.TCToSeconds(value.Start); err == nil {
+			start = seconds
+		}
+	}
+	if value.End != vsapi.PlusInf {
+		if seconds, err := vscommon.TCToSeconds(value.End); err == nil {
+			end = seconds
+		}
+	}
+	return start, end
+}
+
+// metadataDocument is the MetadataDocument vsapi sends to create placeholders and set
+// metadata.
+type metadataDocument struct {
+	XMLName   xml.Name           `xml:"MetadataDocument"`
+	Group     string             `xml:"group"`
+	Timespans []metadataTimespan `xml:"timespan"`
+}
+
+type metadataTimespan struct {
+	Start  string          `xml:"start,attr"`
+	End    string          `xml:"end,attr"`
+	Fields []metadataField `xml:"field"`
+	Groups []metadataGroup `xml:"group"`
+}
+
+type metadataGroup struct {
+	Name   string          `xml:"name"`
+	Fields []metadataField `xml:"field"`
+	Groups []metadataGroup `xml:"group"`
+}
+
+type metadataField struct {
+	Name   string `xml:"name"`
+	Values []struct {
+		Mode  string `xml:"mode,attr"`
+		Value string `xml:",chardata"`
+	} `xml:"value"`
+}
+
+func (d metadataDocument) apply(it *item) {
+	for _, span := range d.Timespans {
+		start, end := span.Start, span.End
+		if start == "" {
+			start = vsapi.MinusInf
+		}
+		if end == "" {
+			end = vsapi.PlusInf
+		}
+		applyFields(it, "", start, end, span.Fields)
+		applyGroups(it, start, end, span.Groups)
+	}
+}
+
+func applyGroups(it *item, start, end string, groups []metadataGroup) {
+	for _, group := range groups {
+		applyFields(it, group.Name, start, end, group.Fields)
+		applyGroups(it, start, end, group.Groups)
+	}
+}
+
+// applyFields sets the fields, or adds to them the values in mode "add".
+func applyFields(it *item, group, start, end string, fields []metadataField) {
+	for _, f := range fields {
+		replaced := false
+		for _, v := range f.Values {
+			add := v.Mode == "add" || replaced
+			it.set(group, f.Name, start, end, strings.TrimSpace(v.Value), add)
+			replaced = replaced || !add
+		}
+	}
+}
+
+func (s *Server) putItemMetadata(c *gin.Context) {
+	var doc metadataDocument
+	if err := xml.NewDecoder(c.Request.Body).Decode(&doc); err != nil {
+		invalidInput(c, err.Error())
+		return
+	}
+
+	it := s.lockedItem(c)
+	defer s.mu.Unlock()
+	if it == nil {
+		return
+	}
+
+	doc.apply(it)
+	c.JSON(http.StatusOK, it.metadata(func(field) bool { return true }))
+}
+
+func (s *Server) getSequence(c *gin.Context) {
+	it := s.lockedItem(c)
+	defer s.mu.Unlock()
+	if it == nil {
+		return
+	}
+
+	if it.sequence == nil {
+		notFound(c, "sequence", it.id)
+		return
+	}
+	c.XML(http.StatusOK, it.sequence)
+}
+
+func (s *Server) getRelations(c *gin.Context) {
+	it := s.lockedItem(c)
+	defer s.mu.Unlock()
+	if it == nil {
+		return
+	}
+
+	c.JSON(http.StatusOK, vsapi.RelationResult{Relations: it.relations})
+}
+
+// attachFile puts a file on an item as a shape with the tag. The caller holds the lock.
+func (s *Server) attachFile(c *gin.Context, it *item, jobType string) {
+	fileID := c.Query("fileId")
+	f, ok := s.files[fileID]
+	if !ok {
+		notFound(c, "file", fileID)
+		return
+	}
+	tag := c.DefaultQuery("tag", "original")
+
+	f.Items = append(f.Items, vsapi.Item{ID: it.id})
+	it.shapes = append(it.shapes, vsapi.Shape{
+		ID:                 s.newID(),
+		Tag:                []string{tag},
+		ContainerComponent: vsapi.ContainerComponent{File: []vsapi.File{*f}},
+	})
+
+	c.JSON(http.StatusOK, s.startJob(jobType, it.id))
+}
+
+func (s *Server) addShape(c *gin.Context) {
+	it := s.lockedItem(c)
+	defer s.mu.Unlock()
+	if it == nil {
+		return
+	}
+
+	s.attachFile(c, it, "IMPORT")
+}
+
+func (s *Server) deleteShape(c *gin.Context) {
+	it := s.lockedItem(c)
+	defer s.mu.Unlock()
+	if it == nil {
+		return
+	}
+
+	shapeID := c.Param("shape")
+	if !slices.ContainsFunc(it.shapes, func(shape vsapi.Shape) bool { return shape.ID == shapeID }) {
+		notFound(c, "shape", shapeID)
+		return
+	}
+	it.shapes = slices.DeleteFunc(it.shapes, func(shape vsapi.Shape) bool { return shape.ID == shapeID })
+	c.Status(http.StatusOK)
+}
+
+func (s *Server) createThumbnails(c *gin.Context) {
+	it := s.lockedItem(c)
+	defer s.mu.Unlock()
+	if it == nil {
+		return
+	}
+
+	c.JSON(http.StatusOK, s.startJob("THUMBNAIL", it.id))
+}
+
+func (s *Server) deleteItems(c *gin.Context) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	keepFiles := c.Query("keepShapeTagMedia") != ""
+	for _, id := range strings.Split(c.Query("id"), ",") {
+		it, ok := s.items[id]
+		if !ok {
+			continue
+		}
+		if !keepFiles {
+			for _, shape := range it.shapes {
+				for _, f := range shape.ContainerComponent.File {
+					delete(s.files, f.ID)
+				}
+			}
+		}
+		delete(s.items, id)
+		for collection, ids := range s.collections {
+			s.collections[collection] = slices.DeleteFunc(ids, func(itemID string) bool { return itemID == id })
+		}
+	}
+	c.Status(http.StatusOK)
+}
+
+func (s *Server) createPlaceholder(c *gin.Context) {
+	var doc metadataDocument
+	if err := xml.NewDecoder(c.Request.Body).Decode(&doc); err != nil {
+		invalidInput(c, err.Error())
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	it := s.itemFor(s.newID())
+	doc.apply(it)
+	c.JSON(http.StatusOK, vsapi.IDOnlyResult{VXID: it.id})
+}
+
+func (s *Server) addFileToPlaceholder(c *gin.Context) {
+	it := s.lockedItem(c)
+	defer s.mu.Unlock()
+	if it == nil {
+		return
+	}
+
+	s.attachFile(c, it, "PLACEHOLDER_IMPORT")
+}
+
+func (s *Server) addSidecar(c *gin.Context) {
+	it := s.lockedItem(c)
+	defer s.mu.Unlock()
+	if it == nil {
+		return
+	}
+
+	job := s.startJob("SIDECAR_IMPORT", it.id)
+	it.sidecars = append(it.sidecars, Sidecar{
+		Path:     strings.TrimPrefix(c.Query("sidecar"), "file://"),
+		Language: strings.TrimPrefix(c.Query("jobmetadata"), "subtitleLanguage="),
+		JobID:    job.JobID,
+	})
+	c.JSON(http.StatusOK, job)
+}
+
+// lockedStorage takes the lock and finds the storage in the path, or answers 404. The
+// caller unlocks.
+func (s *Server) lockedStorage(c *gin.Context) (string, bool) {
+	s.mu.Lock()
+	storageID := c.Param("id")
+	if _, ok := s.storages[storageID]; !ok {
+		notFound(c, "storage", storageID)
+		return "", false
+	}
+	return storageID, true
+}
+
+func (s *Server) getStorageMethods(c *gin.Context) {
+	storageID, ok := s.lockedStorage(c)
+	defer s.mu.Unlock()
+	if !ok {
+		return
+	}
+
+	uri := s.storages[storageID]
+	if !strings.Contains(uri, "://") {
+		uri = "file://" + uri
+	}
+	c.JSON(http.StatusOK, vsapi.StorageResult{Methods: []vsapi.StorageMethod{{
+		VXID:   s.newID(),
+		URI:    uri,
+		Read:   true,
+		Write:  true,
+		Browse: true,
+		Type:   "NONE",
+	}}})
+}
+
+func (s *Server) listFiles(c *gin.Context) {
+	storageID, ok := s.lockedStorage(c)
+	defer s.mu.Unlock()
+	if !ok {
+		return
+	}
+
+	root := c.Query("path")
+	recursive := c.Query("recursive") != "false"
+	state := c.Query("state")
+	filters := strings.Split(c.Query("filter"), ",")
+
+	var files []vsapi.File
+	for _, f := range s.files {
+		if f.Storage != storageID || (state != "" && f.State != state) {
+			continue
+		}
+		if slices.Contains(filters, "item") && len(f.Items) == 0 ||
+			slices.Contains(filters, "noitem") && len(f.Items) > 0 {
+			continue
+		}
+		dir := strings.TrimSuffix(root, "/")
+		inside := f.Path == root || path.Dir(f.Path) == dir || dir == "" && !strings.Contains(f.Path, "/")
+		if recursive {
+			inside = root == "" || f.Path == root || strings.HasPrefix(f.Path, dir+"/")
+		}
+		if inside {
+			files = append(files, *f)
+		}
+	}
+	slices.SortFunc(files, func(a, b vsapi.File) int { return strings.Compare(a.Path, b.Path) })
+
+	first, _ := strconv.Atoi(c.DefaultQuery("first", "0"))
+	number, _ := strconv.Atoi(c.DefaultQuery("number", "100"))
+	page := files[min(first, len(files)):min(first+number, len(files))]
+
+	c.JSON(http.StatusOK, vsapi.FileSearchResult{Hits: len(files), Files: page})
+}
+
+func (s *Server) registerFileHandler(c *gin.Context) {
+	storageID, ok := s.lockedStorage(c)
+	defer s.mu.Unlock()
+	if !ok {
+		return
+	}
+
+	state := c.DefaultQuery("state", vsapi.FileStateClosed.Value)
+	c.JSON(http.StatusOK, vsapi.IDOnlyResult{VXID: s.registerFile(storageID, c.Query("path"), state).ID})
+}
+
+func (s *Server) updateFileState(c *gin.Context) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	f, ok := s.files[c.Param("id")]
+	if !ok {
+		notFound(c, "file", c.Param("id"))
+		return
+	}
+	f.State = c.Param("state")
+	s.updateShapeFiles(f)
+	c.Status(http.StatusOK)
+}
+
+func (s *Server) moveFile(c *gin.Context) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	f, ok := s.files[c.Param("id")]
+	if !ok {
+		notFound(c, "file", c.Param("id"))
+		return
+	}
+	storageID := c.Param("storage")
+	if _, ok := s.storages[storageID]; !ok {
+		notFound(c, "storage", storageID)
+		return
+	}
+
+	f.Storage = storageID
+	if filename := c.Query("filename"); filename != "" {
+		f.Path = filename
+	}
+	f.URI = []string{s.fileURI(f)}
+	s.updateShapeFiles(f)
+
+	itemID := ""
+	if len(f.Items) > 0 {
+		itemID = f.Items[0].ID
+	}
+	job := s.startJob("COPY_FILE", itemID)
+	c.JSON(http.StatusOK, job)
+}
+
+// updateShapeFiles copies a changed file into the shapes that have it. The caller holds
+// the lock.
+func (s *Server) updateShapeFiles(f *vsapi.File) {
+	for _, it := range s.items {
+		for i := range it.shapes {
+			files := it.shapes[i].ContainerComponent.File
+			for j := range files {
+				if files[j].ID == f.ID {
+					files[j] = *f
+				}
+			}
+		}
+	}
+}
+
+func (s *Server) findJobs(c *gin.Context) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	jobType := c.Query("type")
+	itemID := strings.TrimPrefix(c.Query("jobmetadata"), "itemId=")
+
+	var jobs []vsapi.JobDocument
+	for _, j := range s.jobs {
+		if (jobType == "" || j.Type == jobType) && (itemID == "" || j.itemID == itemID) {
+			jobs = append(jobs, j.JobDocument)
+		}
+	}
+	c.JSON(http.StatusOK, vsapi.JobsSearchResponse{Hits: len(jobs), Jobs: jobs})
+}
+
+func (s *Server) getJob(c *gin.Context) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	j, ok := lo.Find(s.jobs, func(j *job) bool { return j.JobID == c.Param("id") })
+	if !ok {
+		notFound(c, "job", c.Param("id"))
+		return
+	}
+	c.JSON(http.StatusOK, j.JobDocument)
+}
+
+func (s *Server) getCollectionItems(c *gin.Context) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	ids, ok := s.collections[c.Param("id")]
+	if !ok {
+		notFound(c, "collection", c.Param("id"))
+		return
+	}
+
+	number, _ := strconv.Atoi(c.DefaultQuery("number", "100"))
+	result := vsapi.CollectionItemsResult{Hits: int64(len(ids))}
+	for _, id := range ids[:min(number, len(ids))] {
+		if it, ok := s.items[id]; ok {
+			result.Items = append(result.Items, it.metadata(func(field) bool { return true }))
+		}
+	}
+	c.JSON(http.StatusOK, result)
+}
+
+// searchField is a field criterion of a search.
+type searchField struct {
+	Name   string   `xml:"name"`
+	Values []string `xml:"value"`
+	Range  *struct {
+		Values []string `xml:"value"`
+	} `xml:"range"`
+}
+
+// matches is whether an item has one of the values in the field, any value for "*", or
+// one in the range.
+func (f searchField) matches(it *item) bool {
+	return slices.ContainsFunc(it.values(f.Name), func(value string) bool {
+		if f.Range != nil && len(f.Range.Values) == 2 {
+			return value >= f.Range.Values[0] && value <= f.Range.Values[1]
+		}
+		return slices.Contains(f.Values, value) || (value != "" && slices.Contains(f.Values, "*"))
+	})
+}
+
+// searchOperator combines the criteria in it with AND, OR or NOT.
+type searchOperator struct {
+	Operation string           `xml:"operation,attr"`
+	Fields    []searchField    `xml:"field"`
+	Operators []searchOperator `xml:"operator"`
+}
+
+func (o searchOperator) matches(it *item) bool {
+	var results []bool
+	for _, f := range o.Fields {
+		results = append(results, f.matches(it))
+	}
+	for _, op := range o.Operators {
+		results = append(results, op.matches(it))
+	}
+
+	all := !slices.Contains(results, false)
+	switch o.Operation {
+	case "OR":
+		return slices.Contains(results, true)
+	case "NOT":
+		return !all
+	}
+	return all
+}
+
+// searchDocument is the ItemSearchDocument of both searches vsapi makes.
+type searchDocument struct {
+	XMLName   xml.Name         `xml:"ItemSearchDocument"`
+	Text      string           `xml:"text"`
+	Fields    []searchField    `xml:"field"`
+	Operators []searchOperator `xml:"operator"`
+	Facets    []struct {
+		Field string `xml:"field"`
+	} `xml:"facet"`
+}
+
+// matches is whether an item has what the search asks for: the text in its title, each
+// field, and each operator.
+func (d searchDocument) matches(it *item) bool {
+	if d.Text != "" {
+		text := strings.ToLower(d.Text)
+		titled := slices.ContainsFunc(it.values(vscommon.FieldTitle.Value), func(title string) bool {
+			return strings.Contains(strings.ToLower(title), text)
+		})
+		if !titled {
+			return false
+		}
+	}
+
+	for _, f := range d.Fields {
+		if !f.matches(it) {
+			return false
+		}
+	}
+	for _, o := range d.Operators {
+		if !o.matches(it) {
+			return false
+		}
+	}
+	return true
+}
+
+// searchMatches returns the items the search in the body matches, sorted by ID. The
+// caller holds the lock.
+func (s *Server) searchMatches(c *gin.Context) (searchDocument, []*item, bool) {
+	var doc searchDocument
+	if err := xml.NewDecoder(c.Request.Body).Decode(&doc); err != nil {
+		invalidInput(c, err.Error())
+		return doc, nil, false
+	}
+
+	ids := lo.Keys(s.items)
+	slices.Sort(ids)
+	var matched []*item
+	for _, id := range ids {
+		if doc.matches(s.items[id]) {
+			matched = append(matched, s.items[id])
+		}
+	}
+	return doc, matched, true
+}
+
+func (s *Server) search(c *gin.Context) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	_, matched, ok := s.searchMatches(c)
+	if !ok {
+		return
+	}
+
+	result := vsapi.SearchResult{Hits: len(matched)}
+	for _, it := range matched {
+		result.Entry = append(result.Entry, vsapi.Entry{Type: "Item", ID: it.id})
+	}
+	c.JSON(http.StatusOK, result)
+}
+
+func (s *Server) searchItems(c *gin.Context) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	doc, matched, ok := s.searchMatches(c)
+	if !ok {
+		return
+	}
+
+	result := vsapi.ItemSearchResult{Hits: len(matched)}
+	for _, facet := range doc.Facets {
+		counts := map[string]int{}
+		for _, it := range matched {
+			for _, value := range lo.Uniq(it.values(facet.Field)) {
+				counts[value]++
+			}
+		}
+		values := lo.Keys(counts)
+		slices.Sort(values)
+		result.Facet = append(result.Facet, vsapi.SearchFacet{
+			Field: facet.Field,
+			Count: lo.Map(values, func(value string, _ int) vsapi.SearchFacetCount {
+				return vsapi.SearchFacetCount{FieldValue: value, Count: counts[value]}
+			}),
+		})
+	}
+
+	// first is 1-based here, unlike in the file listing.
+	first, _ := strconv.Atoi(c.DefaultQuery("first", "1"))
+	number, _ := strconv.Atoi(c.DefaultQuery("number", "100"))
+	from := min(max(first-1, 0), len(matched))
+	for _, it := range matched[from:min(from+number, len(matched))] {
+		result.Items = append(result.Items, it.metadata(func(field) bool { return true }))
+	}
+	c.JSON(http.StatusOK, result)
+}
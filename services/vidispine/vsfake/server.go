@@ -0,0 +1,109 @@
+// Package vsfake is an in-memory stand-in for the parts of the Vidispine API that
+// vsapi.Client uses: items, shapes, metadata, placeholders, files and storages, jobs,
+// sequences, relations, collections and search.
+//
+// Unlike vsmock it keeps state, so a placeholder created by one call has the file a
+// later call adds to it, and a workflow can be run against a real vsapi.Client from
+// start to end. Jobs finish as soon as they are started unless told otherwise.
+package vsfake
+
+import (
+	"fmt"
+	"net/http"
+	"sync"
+
+	"github.com/bcc-code/bcc-media-flows/services/vidispine/vsapi"
+	"github.com/gin-gonic/gin"
+)
+
+// DefaultStorageRoot is where New puts vsapi.DefaultStorageID.
+const DefaultStorageRoot = "/mnt/isilon/"
+
+const (
+	JobStatusFinished = "FINISHED"
+	JobStatusStarted  = "STARTED"
+	JobStatusFailed   = "FAILED_TOTAL"
+)
+
+type Server struct {
+	mu sync.Mutex
+
+	items       map[string]*item
+	files       map[string]*vsapi.File
+	storages    map[string]string
+	jobs        []*job
+	collections map[string][]string
+
+	// jobStatus is what new jobs start as.
+	jobStatus string
+	nextID    int
+
+	router *gin.Engine
+}
+
+type item struct {
+	id        string
+	fields    []field
+	shapes    []vsapi.Shape
+	sequence  *vsapi.SequenceDocument
+	relations []vsapi.Relation
+	sidecars  []Sidecar
+}
+
+// field is a metadata value, in the group it was set in, if any.
+type field struct {
+	group string
+	key   string
+	value vsapi.MetadataField
+}
+
+type job struct {
+	vsapi.JobDocument
+	itemID string
+}
+
+// Sidecar is a file imported to an item with AddSidecarToItem.
+type Sidecar struct {
+	Path     string
+	Language string
+	JobID    string
+}
+
+// New returns an empty server with vsapi.DefaultStorageID at DefaultStorageRoot.
+func New() *Server {
+	s := &Server{
+		items:       map[string]*item{},
+		files:       map[string]*vsapi.File{},
+		storages:    map[string]string{},
+		collections: map[string][]string{},
+		jobStatus:   JobStatusFinished,
+		nextID:      900000,
+	}
+	s.storages[vsapi.DefaultStorageID] = DefaultStorageRoot
+
+	gin.SetMode(gin.ReleaseMode)
+	s.router = gin.New()
+	s.routes(s.router)
+	return s
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.router.ServeHTTP(w, r)
+}
+
+// Config points a vsapi.Client at the server once it listens on baseURL. The
+// credentials are not checked.
+type Config struct {
+	URL string
+}
+
+func (c Config) BaseURL() string  { return c.URL }
+func (c Config) Username() string { return "fake" }
+func (c Config) Password() string { return "fake" }
+
+// newID is the next VX ID, for any kind of entity, as in Vidispine. The caller holds
+// the lock.
+func (s *Server) newID() string {
+	s.nextID++
+	return fmt.Sprintf("VX-%d", s.nextID)
+}
@@ -0,0 +1,171 @@
+package vsfake_test
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/bcc-code/bcc-media-flows/services/vidispine/vsapi"
+	"github.com/bcc-code/bcc-media-flows/services/vidispine/vscommon"
+	"github.com/bcc-code/bcc-media-flows/services/vidispine/vsfake"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newClient(t *testing.T) (*vsfake.Server, *vsapi.Client) {
+	fake := vsfake.New()
+	server := httptest.NewServer(fake)
+	t.Cleanup(server.Close)
+	return fake, vsapi.NewClient(vsfake.Config{URL: server.URL})
+}
+
+// The calls an ingest makes, in order, each seeing what the one before left.
+func Test_IngestLifecycle(t *testing.T) {
+	fake, client := newClient(t)
+
+	itemID, err := client.CreatePlaceholder(vsapi.PlaceholderTypeRaw, "Konferanse 2024")
+	require.NoError(t, err)
+	assert.Equal(t, "Konferanse 2024", fake.Metadata(itemID).Get(vscommon.FieldTitle, ""))
+
+	fileID, err := client.RegisterFile(vsfake.DefaultStorageRoot+"Ingest/konferanse 2024.mxf", vsapi.FileStateOpen)
+	require.NoError(t, err)
+	assert.Equal(t, "Ingest/konferanse 2024.mxf", fake.File(fileID).Path)
+
+	jobID, err := client.AddFileToPlaceholder(itemID, fileID, "original", vsapi.FileStateOpen)
+	require.NoError(t, err)
+
+	job, err := client.GetJob(jobID)
+	require.NoError(t, err)
+	assert.Equal(t, vsfake.JobStatusFinished, job.Status)
+
+	found, err := client.FindJob(itemID, "PLACEHOLDER_IMPORT")
+	require.NoError(t, err)
+	require.NotNil(t, found)
+	assert.Equal(t, jobID, found.JobID)
+
+	shapes, err := client.GetShapes(itemID)
+	require.NoError(t, err)
+	require.NotNil(t, shapes.GetShape("original"))
+	assert.Equal(t, vsfake.DefaultStorageRoot+"Ingest/konferanse 2024.mxf", shapes.GetShape("original").GetPath())
+
+	require.NoError(t, client.UpdateFileState(fileID, vsapi.FileStateClosed))
+	assert.Equal(t, vsapi.FileStateClosed.Value, fake.File(fileID).State)
+
+	exists, err := client.FileExistsInStorage(vsapi.DefaultStorageID, vsfake.DefaultStorageRoot+"Ingest/konferanse 2024.mxf")
+	require.NoError(t, err)
+	assert.True(t, exists)
+}
+
+func Test_Metadata(t *testing.T) {
+	fake, client := newClient(t)
+	itemID := fake.AddItem(map[string]string{vscommon.FieldTitle.Value: "Tale"})
+
+	require.NoError(t, client.SetItemMetadataField(vsapi.ItemMetadataFieldParams{
+		ItemID: itemID,
+		Key:    vscommon.FieldLanguagesRecorded.Value,
+		Value:  "nor",
+	}))
+	require.NoError(t, client.AddToItemMetadataField(vsapi.ItemMetadataFieldParams{
+		ItemID: itemID,
+		Key:    vscommon.FieldLanguagesRecorded.Value,
+		Value:  "eng",
+	}))
+
+	meta, err := client.GetMetadataFields(itemID, []string{vscommon.FieldLanguagesRecorded.Value})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"nor", "eng"}, meta.GetArray(vscommon.FieldLanguagesRecorded))
+	assert.Equal(t, "", meta.Get(vscommon.FieldTitle, ""))
+
+	// Setting replaces what there was.
+	require.NoError(t, client.SetItemMetadataField(vsapi.ItemMetadataFieldParams{
+		ItemID: itemID,
+		Key:    vscommon.FieldLanguagesRecorded.Value,
+		Value:  "deu",
+	}))
+	assert.Equal(t, []string{"deu"}, fake.Metadata(itemID).GetArray(vscommon.FieldLanguagesRecorded))
+
+	_, err = client.GetMetadata("VX-1")
+	assert.ErrorContains(t, err, "404")
+}
+
+func Test_ChapterMeta(t *testing.T) {
+	fake, client := newClient(t)
+	itemID := fake.AddItem(map[string]string{vscommon.FieldTitle.Value: "Møte"})
+	fake.AddTimedMetadata(itemID, "Subclips", vscommon.FieldTitle.Value, "Sang", "250@PAL", "1000@PAL")
+	fake.AddTimedMetadata(itemID, "Subclips", vscommon.FieldTitle.Value, "Tale", "1000@PAL", "3000@PAL")
+	fake.AddTimedMetadata(itemID, "Subclips", vscommon.FieldTitle.Value, "Etter", "5000@PAL", "6000@PAL")
+
+	clips, err := client.GetChapterMeta(itemID, 0, 120)
+	require.NoError(t, err)
+
+	assert.Len(t, clips, 2)
+	assert.Contains(t, clips, "Sang")
+	assert.Contains(t, clips, "Tale")
+}
+
+// DeleteItems logs through the activity, so deleting is covered by the purge workflow
+// in workflows/scheduled.
+func Test_Search(t *testing.T) {
+	fake, client := newClient(t)
+	kept := fake.AddItem(map[string]string{vscommon.FieldTitle.Value: "Kept", "mediaType": "video"})
+	trashed := fake.AddItem(map[string]string{vscommon.FieldTitle.Value: "Trashed", "mediaType": "audio", "portal_deleted": "2024-03-01T10:00:00"})
+	fake.AddToCollection("VX-5", kept, trashed)
+
+	ids, err := client.SearchByMetadataField("mediaType", "video")
+	require.NoError(t, err)
+	assert.Equal(t, []string{kept}, ids)
+
+	result, err := client.SearchItems(vsapi.ItemSearchParams{Text: "trash", Facet: true})
+	require.NoError(t, err)
+	assert.Equal(t, 1, result.Hits)
+	assert.Equal(t, trashed, result.Items[0].ID)
+	assert.Equal(t, []vsapi.SearchFacetCount{{FieldValue: "audio", Count: 1}}, result.Facet[0].Count)
+
+	result, err = client.SearchItems(vsapi.ItemSearchParams{Ranges: []vsapi.SearchRange{{Field: "portal_deleted", Start: "2024-01-01", End: "2024-06-01"}}})
+	require.NoError(t, err)
+	require.Equal(t, 1, result.Hits)
+	assert.Equal(t, trashed, result.Items[0].ID)
+
+	result, err = client.SearchItems(vsapi.ItemSearchParams{Operators: []vsapi.SearchOperator{{
+		Operation: "NOT",
+		Fields:    []vsapi.SearchField{{Name: "portal_deleted", Values: []string{"*"}}},
+	}}})
+	require.NoError(t, err)
+	require.Equal(t, 1, result.Hits)
+	assert.Equal(t, kept, result.Items[0].ID)
+
+	result, err = client.SearchItems(vsapi.ItemSearchParams{Operators: []vsapi.SearchOperator{{
+		Operation: "OR",
+		Fields:    []vsapi.SearchField{{Name: "mediaType", Values: []string{"video"}}},
+		Ranges:    []vsapi.SearchRange{{Field: "portal_deleted", Start: "2024-01-01", End: "2024-06-01"}},
+	}}})
+	require.NoError(t, err)
+	assert.Equal(t, 2, result.Hits)
+
+	trash, err := client.GetTrash()
+	require.NoError(t, err)
+	assert.Equal(t, []string{trashed}, trash)
+
+	collection, err := client.GetItemsInCollection("VX-5", 1)
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), collection.Hits)
+	require.Len(t, collection.Items, 1)
+	assert.Equal(t, kept, collection.Items[0].ID)
+}
+
+func Test_LoadDir(t *testing.T) {
+	fake, client := newClient(t)
+	require.NoError(t, fake.LoadDir("../testdata"))
+
+	seq, err := client.GetSequence("VX-431566")
+	require.NoError(t, err)
+	assert.NotEmpty(t, seq.Track)
+
+	// An item that is not a sequence has none, which is not an error.
+	seq, err = client.GetSequence("VX-464458")
+	require.NoError(t, err)
+	assert.Empty(t, seq.Track)
+
+	resolutions, err := client.GetResolutions("VX-464458")
+	require.NoError(t, err)
+	assert.NotEmpty(t, resolutions)
+}
@@ -0,0 +1,379 @@
+package vsfake
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/bcc-code/bcc-media-flows/services/vidispine/vsapi"
+	"github.com/samber/lo"
+)
+
+// AddItem creates an item with values for the whole of it, and returns its ID.
+func (s *Server) AddItem(metadata map[string]string) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	it := s.itemFor(s.newID())
+	keys := lo.Keys(metadata)
+	slices.Sort(keys)
+	for _, key := range keys {
+		it.set("", key, vsapi.MinusInf, vsapi.PlusInf, metadata[key], false)
+	}
+	return it.id
+}
+
+// PutMetadata replaces the metadata of an item, creating it if need be, with a
+// recorded GetMetadata response.
+func (s *Server) PutMetadata(itemID string, meta vsapi.MetadataResult) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	it := s.itemFor(itemID)
+	it.fields = nil
+	keys := lo.Keys(meta.Terse)
+	slices.Sort(keys)
+	for _, key := range keys {
+		for _, value := range meta.Terse[key] {
+			it.fields = append(it.fields, field{key: key, value: *value})
+		}
+	}
+}
+
+// SetMetadata replaces the values of a field for the whole of an item.
+func (s *Server) SetMetadata(itemID, key string, values ...string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	it := s.itemFor(itemID)
+	it.fields = slices.DeleteFunc(it.fields, func(f field) bool { return f.group == "" && f.key == key })
+	for _, value := range values {
+		it.set("", key, vsapi.MinusInf, vsapi.PlusInf, value, true)
+	}
+}
+
+// AddTimedMetadata adds a value for part of an item, such as a subclip title in the
+// Subclips group. start and end are timecodes like 250@PAL.
+func (s *Server) AddTimedMetadata(itemID, group, key, value, start, end string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.itemFor(itemID).set(group, key, start, end, value, true)
+}
+
+// AddShape adds a shape to an item, giving it an ID if it has none, and returns the ID.
+func (s *Server) AddShape(itemID string, shape vsapi.Shape) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if shape.ID == "" {
+		shape.ID = s.newID()
+	}
+	it := s.itemFor(itemID)
+	it.shapes = append(it.shapes, shape)
+	return shape.ID
+}
+
+// PutShapes replaces the shapes of an item with a recorded GetShapes response.
+func (s *Server) PutShapes(itemID string, shapes vsapi.ShapeResult) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.itemFor(itemID).shapes = slices.Clone(shapes.Shape)
+}
+
+// SetSequence makes an item a sequence of others.
+func (s *Server) SetSequence(itemID string, sequence vsapi.SequenceDocument) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.itemFor(itemID).sequence = &sequence
+}
+
+func (s *Server) AddRelation(itemID string, relation vsapi.Relation) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if relation.ID == "" {
+		relation.ID = s.newID()
+	}
+	it := s.itemFor(itemID)
+	it.relations = append(it.relations, relation)
+}
+
+// AddStorage adds a storage with its files under root, a local path or an S3 URI
+// such as "s3://bucket/".
+func (s *Server) AddStorage(storageID, root string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.storages[storageID] = root
+}
+
+// AddFile adds a file at a path relative to the root of a storage, and returns its ID.
+func (s *Server) AddFile(storageID, path string, state vsapi.FileState) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.registerFile(storageID, path, state.Value).ID
+}
+
+func (s *Server) AddToCollection(collectionID string, itemIDs ...string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.collections[collectionID] = append(s.collections[collectionID], itemIDs...)
+}
+
+// SetJobStatus changes the status of a job, for example to finish one started with
+// SetNewJobStatus(JobStatusStarted).
+func (s *Server) SetJobStatus(jobID, status string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, j := range s.jobs {
+		if j.JobID == jobID {
+			j.Status = status
+		}
+	}
+}
+
+// SetNewJobStatus is the status jobs started from now on have. It is JobStatusFinished
+// to begin with.
+func (s *Server) SetNewJobStatus(status string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.jobStatus = status
+}
+
+// Metadata is what GetMetadata would return for an item, or nil if there is no item.
+func (s *Server) Metadata(itemID string) *vsapi.MetadataResult {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	it, ok := s.items[itemID]
+	if !ok {
+		return nil
+	}
+	return it.metadata(func(field) bool { return true })
+}
+
+// Shapes is what GetShapes would return for an item, or nil if there is no item.
+func (s *Server) Shapes(itemID string) *vsapi.ShapeResult {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	it, ok := s.items[itemID]
+	if !ok {
+		return nil
+	}
+	return &vsapi.ShapeResult{ID: it.id, Shape: slices.Clone(it.shapes)}
+}
+
+// File returns a copy of a file, or nil if there is none.
+func (s *Server) File(fileID string) *vsapi.File {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	f, ok := s.files[fileID]
+	if !ok {
+		return nil
+	}
+	copied := *f
+	return &copied
+}
+
+// Jobs are the jobs started, in order.
+func (s *Server) Jobs() []vsapi.JobDocument {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return lo.Map(s.jobs, func(j *job, _ int) vsapi.JobDocument { return j.JobDocument })
+}
+
+func (s *Server) Sidecars(itemID string) []Sidecar {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	it, ok := s.items[itemID]
+	if !ok {
+		return nil
+	}
+	return slices.Clone(it.sidecars)
+}
+
+// ItemIDs are the IDs of the items there are, sorted.
+func (s *Server) ItemIDs() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	ids := lo.Keys(s.items)
+	slices.Sort(ids)
+	return ids
+}
+
+// LoadDir adds items from recorded responses, laid out as in
+// services/vidispine/testdata: get_metadata/<VXID>.json, get_shapes/<VXID>.json and
+// get_sequence/<VXID>.xml. Missing folders are skipped.
+func (s *Server) LoadDir(dir string) error {
+	err := loadEach(filepath.Join(dir, "get_metadata"), ".json", func(id string, data []byte) error {
+		var meta vsapi.MetadataResult
+		if err := json.Unmarshal(data, &meta); err != nil {
+			return err
+		}
+		s.PutMetadata(id, meta)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	err = loadEach(filepath.Join(dir, "get_shapes"), ".json", func(id string, data []byte) error {
+		var shapes vsapi.ShapeResult
+		if err := json.Unmarshal(data, &shapes); err != nil {
+			return err
+		}
+		s.PutShapes(id, shapes)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	return loadEach(filepath.Join(dir, "get_sequence"), ".xml", func(id string, data []byte) error {
+		var sequence vsapi.SequenceDocument
+		if err := xml.Unmarshal(data, &sequence); err != nil {
+			return err
+		}
+		s.SetSequence(id, sequence)
+		return nil
+	})
+}
+
+func loadEach(dir, ext string, load func(id string, data []byte) error) error {
+	entries, err := os.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	for _, entry := range entries {
+		if entry.IsDir() || filepath.Ext(entry.Name()) != ext {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(dir, entry.Name()))
+		if err != nil {
+			return err
+		}
+		if err := load(strings.TrimSuffix(entry.Name(), ext), data); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// itemFor returns the item, creating it if there is none. The caller holds the lock.
+func (s *Server) itemFor(itemID string) *item {
+	it, ok := s.items[itemID]
+	if !ok {
+		it = &item{id: itemID}
+		s.items[itemID] = it
+	}
+	return it
+}
+
+// registerFile returns the file at a path on a storage, adding it if there is none. The
+// caller holds the lock.
+func (s *Server) registerFile(storageID, path, state string) *vsapi.File {
+	for _, f := range s.files {
+		if f.Storage == storageID && f.Path == path {
+			f.State = state
+			return f
+		}
+	}
+
+	f := &vsapi.File{
+		ID:      s.newID(),
+		Path:    path,
+		State:   state,
+		Storage: storageID,
+	}
+	f.URI = []string{s.fileURI(f)}
+	s.files[f.ID] = f
+	return f
+}
+
+// fileURI is where a file is, as Vidispine says it, with the path escaped. A storage
+// with a root such as "s3://bucket/" has its files on S3. The caller holds the lock.
+func (s *Server) fileURI(f *vsapi.File) string {
+	root := s.storages[f.Storage]
+	if u, err := url.Parse(root); err == nil && u.Scheme != "" {
+		u.Path += f.Path
+		return u.String()
+	}
+	return (&url.URL{Scheme: "file", Path: root + f.Path}).String()
+}
+
+// startJob records a job on an item, with the status new jobs have. The caller holds
+// the lock.
+func (s *Server) startJob(jobType, itemID string) vsapi.JobDocument {
+	j := &job{
+		JobDocument: vsapi.JobDocument{
+			JobID:  s.newID(),
+			User:   "fake",
+			Status: s.jobStatus,
+			Type:   jobType,
+		},
+		itemID: itemID,
+	}
+	s.jobs = append(s.jobs, j)
+	return j.JobDocument
+}
+
+// set sets a value, or adds it to those the field has in the same group and span.
+func (it *item) set(group, key, start, end, value string, add bool) {
+	if !add {
+		it.fields = slices.DeleteFunc(it.fields, func(f field) bool {
+			return f.group == group && f.key == key && f.value.Start == start && f.value.End == end
+		})
+		if value == "" {
+			return
+		}
+	}
+	it.fields = append(it.fields, field{
+		group: group,
+		key:   key,
+		value: vsapi.MetadataField{Start: start, End: end, Value: value},
+	})
+}
+
+// metadata is the terse metadata of the fields that pass the filter.
+func (it *item) metadata(filter func(field) bool) *vsapi.MetadataResult {
+	result := &vsapi.MetadataResult{ID: it.id, Terse: map[string][]*vsapi.MetadataField{}}
+	for _, f := range it.fields {
+		if !filter(f) {
+			continue
+		}
+		value := f.value
+		result.Terse[f.key] = append(result.Terse[f.key], &value)
+	}
+	return result
+}
+
+// values are the values of a field, wherever in the item they are set.
+func (it *item) values(key string) []string {
+	var values []string
+	for _, f := range it.fields {
+		if f.key == key {
+			values = append(values, f.value.Value)
+		}
+	}
+	return values
+}
@@ -368,6 +368,21 @@ func (mr *MockClientMockRecorder) SearchByMetadataField(name, value any) *gomock
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SearchByMetadataField", reflect.TypeOf((*MockClient)(nil).SearchByMetadataField), name, value)
 }
 
+// SearchItems mocks base method.
+func (m *MockClient) SearchItems(p vsapi.ItemSearchParams) (*vsapi.ItemSearchResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SearchItems", p)
+	ret0, _ := ret[0].(*vsapi.ItemSearchResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SearchItems indicates an expected call of SearchItems.
+func (mr *MockClientMockRecorder) SearchItems(p any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SearchItems", reflect.TypeOf((*MockClient)(nil).SearchItems), p)
+}
+
 // SetItemMetadataField mocks base method.
 func (m *MockClient) SetItemMetadataField(params vsapi.ItemMetadataFieldParams) error {
 	m.ctrl.T.Helper()
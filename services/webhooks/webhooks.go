@@ -0,0 +1,126 @@
+// Package webhooks posts the outcome of a workflow to the URL it was started with, so
+// the system that started it does not have to poll for it.
+package webhooks
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+// SignatureHeader carries the HMAC-SHA256 of the body, as "sha256=<hex>", when the
+// callback has a secret.
+const SignatureHeader = "X-Signature-256"
+
+const (
+	StatusCompleted = "Completed"
+	StatusFailed    = "Failed"
+	StatusCanceled  = "Canceled"
+)
+
+// Callback is where a workflow reports when it is done.
+type Callback struct {
+	URL string
+	// SecretID names the secret that signs the body; without it the request is not
+	// signed. The secret is looked up when the callback is sent, so it is not kept with
+	// the workflow.
+	SecretID string `json:",omitempty"`
+}
+
+// ErrUnknownSecret is a callback naming a secret that is not configured.
+var ErrUnknownSecret = errors.New("unknown callback secret")
+
+// Event is the body of the callback request.
+type Event struct {
+	WorkflowID  string    `json:"workflowId"`
+	RunID       string    `json:"runId"`
+	Workflow    string    `json:"workflow"`
+	Status      string    `json:"status"`
+	VXID        string    `json:"vxid,omitempty"`
+	TriggeredBy string    `json:"triggeredBy,omitempty"`
+	Result      any       `json:"result,omitempty"`
+	Failure     string    `json:"failure,omitempty"`
+	FinishedAt  time.Time `json:"finishedAt"`
+}
+
+// StatusError is a webhook answering with something other than 2xx.
+type StatusError struct {
+	StatusCode int
+	Body       string
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("webhook answered %d: %s", e.StatusCode, e.Body)
+}
+
+// Retryable is false for answers that sending the same request again will not change.
+func (e *StatusError) Retryable() bool {
+	return e.StatusCode >= 500 ||
+		e.StatusCode == http.StatusRequestTimeout ||
+		e.StatusCode == http.StatusTooManyRequests
+}
+
+// Sign returns the signature header value for the body.
+func Sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify reports whether signature is what Sign gives for the body. It is what a
+// receiver of the callbacks would check.
+func Verify(secret string, body []byte, signature string) bool {
+	return hmac.Equal([]byte(Sign(secret, body)), []byte(signature))
+}
+
+var client = &http.Client{Timeout: 30 * time.Second}
+
+// Send posts the event to the callback URL, signed with the secret its SecretID names.
+func Send(ctx context.Context, callback Callback, secrets map[string]string, event Event) error {
+	secret := ""
+	if callback.SecretID != "" {
+		var ok bool
+		secret, ok = secrets[callback.SecretID]
+		if !ok {
+			return fmt.Errorf("%w: %s", ErrUnknownSecret, callback.SecretID)
+		}
+	}
+	return Post(ctx, callback.URL, secret, event)
+}
+
+// Post posts payload as JSON to url, signed when there is a secret.
+func Post(ctx context.Context, url, secret string, payload any) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if secret != "" {
+		req.Header.Set(SignatureHeader, Sign(secret, body))
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		answer, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return &StatusError{StatusCode: resp.StatusCode, Body: string(answer)}
+	}
+	return nil
+}
@@ -0,0 +1,83 @@
+package webhooks
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSend_SignsTheBody(t *testing.T) {
+	var received Event
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+
+		assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+		assert.True(t, Verify("s3cret", body, r.Header.Get(SignatureHeader)))
+		assert.False(t, Verify("other", body, r.Header.Get(SignatureHeader)))
+		require.NoError(t, json.Unmarshal(body, &received))
+	}))
+	defer server.Close()
+
+	event := Event{
+		WorkflowID: "wf-1",
+		RunID:      "run-1",
+		Workflow:   "AssetJSON",
+		Status:     StatusCompleted,
+		VXID:       "VX-1",
+		FinishedAt: time.Date(2026, 5, 1, 10, 0, 0, 0, time.UTC),
+	}
+	require.NoError(t, Send(context.Background(), Callback{URL: server.URL, SecretID: "portal"}, map[string]string{"portal": "s3cret"}, event))
+	assert.Equal(t, event, received)
+}
+
+func TestSend_Unsigned(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Empty(t, r.Header.Get(SignatureHeader))
+	}))
+	defer server.Close()
+
+	require.NoError(t, Send(context.Background(), Callback{URL: server.URL}, nil, Event{}))
+}
+
+// A callback naming a secret that is not configured is not sent unsigned.
+func TestSend_UnknownSecret(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Error("the callback was sent")
+	}))
+	defer server.Close()
+
+	err := Send(context.Background(), Callback{URL: server.URL, SecretID: "portal"}, map[string]string{"other": "s3cret"}, Event{})
+	assert.ErrorIs(t, err, ErrUnknownSecret)
+}
+
+func TestSend_StatusErrors(t *testing.T) {
+	for status, retryable := range map[int]bool{
+		http.StatusNotFound:            false,
+		http.StatusUnauthorized:        false,
+		http.StatusTooManyRequests:     true,
+		http.StatusInternalServerError: true,
+		http.StatusBadGateway:          true,
+	} {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(status)
+			_, _ = w.Write([]byte("nope"))
+		}))
+
+		err := Send(context.Background(), Callback{URL: server.URL}, nil, Event{})
+		server.Close()
+
+		var statusErr *StatusError
+		require.ErrorAs(t, err, &statusErr)
+		assert.Equal(t, status, statusErr.StatusCode)
+		assert.Equal(t, "nope", statusErr.Body)
+		assert.Equal(t, retryable, statusErr.Retryable(), "status %d", status)
+	}
+}
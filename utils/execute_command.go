@@ -77,9 +77,41 @@ func ExecuteCmd(cmd *exec.Cmd, outputCallback func(string)) (string, error) {
 // ExecuteAnalysisCmd executes the cmd and returns the JSON object the command
 // printed to stderr, which is how ffmpeg's loudnorm filter reports its analysis.
 func ExecuteAnalysisCmd(cmd *exec.Cmd, outputCallback func(string)) (string, error) {
+	errorBytes, err := executeForStderr(cmd, outputCallback)
+	if err != nil {
+		return "", err
+	}
+
+	result, err := extractJSONObject(errorBytes)
+	if err != nil {
+		return "", fmt.Errorf("reading stderr failed: %w", err)
+	}
+
+	// replace -Inf with -99 if the audio was silent
+	result = strings.ReplaceAll(result, "\"-inf\"", "\"-99\"")
+
+	// replace inf with 0 target_offset if the audio was silent
+	result = strings.ReplaceAll(result, "\"inf\"", "\"0\"")
+
+	return result, nil
+}
+
+// ExecuteLogCmd executes the cmd and returns all it printed to stderr, which is where
+// ffmpeg's detection filters log what they found.
+func ExecuteLogCmd(cmd *exec.Cmd, outputCallback func(string)) (string, error) {
+	errorBytes, err := executeForStderr(cmd, outputCallback)
+	if err != nil {
+		return "", err
+	}
+	return errorBytes.String(), nil
+}
+
+// executeForStderr executes the cmd, passing stdout to outputCallback line by line,
+// and returns what it printed to stderr once it has exited.
+func executeForStderr(cmd *exec.Cmd, outputCallback func(string)) (*bytes.Buffer, error) {
 	stdout, err := cmd.StdoutPipe()
 	if err != nil {
-		return "", fmt.Errorf("could not open stdout pipe: %w", err)
+		return nil, fmt.Errorf("could not open stdout pipe: %w", err)
 	}
 
 	// Buffered rather than piped, which is what keeps this from deadlocking.
@@ -99,7 +131,7 @@ func ExecuteAnalysisCmd(cmd *exec.Cmd, outputCallback func(string)) (string, err
 
 	err = cmd.Start()
 	if err != nil {
-		return "", fmt.Errorf("start failed: %w", err)
+		return nil, fmt.Errorf("start failed: %w", err)
 	}
 
 	scannerOut := newLineScanner(stdout)
@@ -110,26 +142,15 @@ func ExecuteAnalysisCmd(cmd *exec.Cmd, outputCallback func(string)) (string, err
 		}
 	}
 	if err := scannerOut.Err(); err != nil {
-		return "", fmt.Errorf("reading stdout failed: %w", err)
+		return nil, fmt.Errorf("reading stdout failed: %w", err)
 	}
 
 	err = cmd.Wait()
 	if err != nil {
-		return "", fmt.Errorf("execution failed, message: %s: %w", tailForError(errorBytes.String()), err)
-	}
-
-	result, err := extractJSONObject(&errorBytes)
-	if err != nil {
-		return "", fmt.Errorf("reading stderr failed: %w", err)
+		return nil, fmt.Errorf("execution failed, message: %s: %w", tailForError(errorBytes.String()), err)
 	}
 
-	// replace -Inf with -99 if the audio was silent
-	result = strings.ReplaceAll(result, "\"-inf\"", "\"-99\"")
-
-	// replace inf with 0 target_offset if the audio was silent
-	result = strings.ReplaceAll(result, "\"inf\"", "\"0\"")
-
-	return result, nil
+	return &errorBytes, nil
 }
 
 // extractJSONObject returns the lines between a bare "{" and a bare "}", which is
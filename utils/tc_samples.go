@@ -1,12 +1,6 @@
 package utils
 
-import (
-	"errors"
-	"strconv"
-	"strings"
-
-	"github.com/orsinium-labs/enum"
-)
+import "github.com/orsinium-labs/enum"
 
 type FrameRate enum.Member[string]
 
@@ -16,59 +10,35 @@ var (
 	FrameRates    = enum.New(FrameRateNTSC, FrameRatePAL)
 )
 
+// TCToSamples is the number of audio samples to a timecode, HH:MM:SS:FF in fps or a
+// Vidispine time code such as 1000@PAL.
 func TCToSamples(tc string, fps int, sampleRate int) (int, error) {
-	frames := 0
-	var err error
-	if strings.Contains(tc, "@") {
-
-		splitTc := strings.Split(tc, "@")
-
-		switch splitTc[1] {
-		case FrameRatePAL.Value:
-			fps = 25
-		case FrameRateNTSC.Value:
-			fps = 30
-		default:
-			return 0, errors.New("invalid frame rate")
-		}
-
-		frames, err = strconv.Atoi(splitTc[0])
-	} else {
-		frames, err = TimecodeToFrames(tc, fps)
-	}
-
+	t, err := ParseTimecode(tc, rateForFPS(fps))
 	if err != nil {
 		return 0, err
 	}
-	return frames * sampleRate / fps, nil
+	return t.Samples(sampleRate), nil
 }
 
+// TimecodeToFrames is the number of frames to HH:MM:SS:FF in frameRate.
 func TimecodeToFrames(timecode string, frameRate int) (int, error) {
-	parts := strings.Split(timecode, ":")
-	if len(parts) != 4 {
-		return 0, errors.New("invalid timecode format")
-	}
-
-	hours, err := strconv.Atoi(parts[0])
-	if err != nil {
-		return 0, err
-	}
-
-	minutes, err := strconv.Atoi(parts[1])
-	if err != nil {
-		return 0, err
-	}
-
-	seconds, err := strconv.Atoi(parts[2])
+	t, err := ParseTimecode(timecode, rateForFPS(frameRate))
 	if err != nil {
 		return 0, err
 	}
+	return t.Frames, nil
+}
 
-	frames, err := strconv.Atoi(parts[3])
-	if err != nil {
-		return 0, err
+// rateForFPS is the rate of a whole number of frames per second. ffmpeg.StreamInfo
+// rounds 29.97 down to 29, so 23, 29 and 59 are taken to be the NTSC rates.
+func rateForFPS(fps int) TimecodeRate {
+	switch fps {
+	case 23:
+		return Rate23976
+	case 29:
+		return Rate2997
+	case 59:
+		return Rate5994
 	}
-
-	totalFrames := (hours*3600+minutes*60+seconds)*frameRate + frames
-	return totalFrames, nil
+	return TimecodeRate{Num: fps, Den: 1}
 }
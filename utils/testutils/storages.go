@@ -0,0 +1,28 @@
+package testutils
+
+import (
+	"testing"
+
+	"github.com/bcc-code/bcc-media-flows/paths"
+)
+
+// ColdArchive is the cold archive WithColdArchive adds to the storage registry.
+var ColdArchive = paths.Storage{
+	Name:        "Cold Archive",
+	VidispineID: "VX-90",
+	Linux:       "s3://cold-archive/",
+	Rclone:      &paths.Rclone{FS: "coldarchive:"},
+	Archive:     true,
+	External:    true,
+}
+
+// WithColdArchive configures the storage registry with ColdArchive for the test, and
+// the one before it again when the test is done.
+func WithColdArchive(t *testing.T) {
+	before := paths.Current()
+	t.Cleanup(func() { paths.Configure(before) })
+
+	registry := before
+	registry.Storages = append(append([]paths.Storage{}, before.Storages...), ColdArchive)
+	paths.Configure(registry)
+}
@@ -0,0 +1,293 @@
+package utils
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// TimecodeRate is a frame rate timecode is counted in. NTSC rates are kept as the
+// fractions they are, and DropFrame counts 29.97 and 59.94 in drop-frame timecode.
+type TimecodeRate struct {
+	Num       int
+	Den       int
+	DropFrame bool
+}
+
+var (
+	Rate23976  = TimecodeRate{Num: 24000, Den: 1001}
+	Rate24     = TimecodeRate{Num: 24, Den: 1}
+	Rate25     = TimecodeRate{Num: 25, Den: 1}
+	Rate2997   = TimecodeRate{Num: 30000, Den: 1001}
+	Rate2997DF = TimecodeRate{Num: 30000, Den: 1001, DropFrame: true}
+	Rate30     = TimecodeRate{Num: 30, Den: 1}
+	Rate50     = TimecodeRate{Num: 50, Den: 1}
+	Rate5994   = TimecodeRate{Num: 60000, Den: 1001}
+	Rate5994DF = TimecodeRate{Num: 60000, Den: 1001, DropFrame: true}
+
+	// TimecodeRates are the rates timecode can be in, drop-frame after the rate it drops
+	// frames of.
+	TimecodeRates = []TimecodeRate{Rate23976, Rate24, Rate25, Rate2997, Rate2997DF, Rate30, Rate50, Rate5994, Rate5994DF}
+)
+
+var ErrInvalidTimecode = errors.New("invalid timecode")
+
+// FPS is the number of frames in a second.
+func (r TimecodeRate) FPS() float64 {
+	return float64(r.Num) / float64(r.Den)
+}
+
+// Base is the number of frames timecode counts in a second: 30 for 29.97.
+func (r TimecodeRate) Base() int {
+	return (r.Num + r.Den - 1) / r.Den
+}
+
+func (r TimecodeRate) IsZero() bool {
+	return r.Num == 0 || r.Den == 0
+}
+
+// droppedPerMinute is the number of frame numbers skipped at the start of every minute
+// but every tenth: 2 for 29.97 and 4 for 59.94.
+func (r TimecodeRate) droppedPerMinute() int {
+	if !r.DropFrame {
+		return 0
+	}
+	return r.Base() / 15
+}
+
+// dropFrameRate is the drop-frame rate timecode in a rate is counted in when it is
+// drop-frame. Only 29.97 and 59.94 have one, and 30 and 60 are taken to be those.
+func (r TimecodeRate) dropFrameRate() (TimecodeRate, bool) {
+	switch r.Base() {
+	case 30:
+		return Rate2997DF, true
+	case 60:
+		return Rate5994DF, true
+	}
+	return TimecodeRate{}, false
+}
+
+// String is the rate as people write it, such as 25, 23.976 or 29.97DF.
+func (r TimecodeRate) String() string {
+	if r.IsZero() {
+		return ""
+	}
+	s := strconv.Itoa(r.Num / r.Den)
+	if r.Num%r.Den != 0 {
+		s = strconv.FormatFloat(math.Floor(r.FPS()*1000)/1000, 'f', -1, 64)
+		if r.Base()%30 == 0 {
+			s = strconv.FormatFloat(math.Floor(r.FPS()*100)/100, 'f', -1, 64)
+		}
+	}
+	if r.DropFrame {
+		s += "DF"
+	}
+	return s
+}
+
+// FFmpeg is the rate as ffmpeg takes it, such as 30000/1001.
+func (r TimecodeRate) FFmpeg() string {
+	if r.Den == 1 {
+		return strconv.Itoa(r.Num)
+	}
+	return fmt.Sprintf("%d/%d", r.Num, r.Den)
+}
+
+// ParseRate reads a rate as String writes it, as a fraction like 30000/1001, or as
+// Vidispine names it (PAL and NTSC). 29.97 and 59.94 are non-drop unless followed by DF.
+func ParseRate(s string) (TimecodeRate, error) {
+	switch s {
+	case FrameRatePAL.Value:
+		return Rate25, nil
+	case FrameRateNTSC.Value:
+		return Rate2997, nil
+	}
+
+	value := strings.ToUpper(strings.TrimSpace(s))
+	drop := strings.HasSuffix(value, "DF") && !strings.HasSuffix(value, "NDF")
+	value = strings.TrimSpace(strings.TrimSuffix(strings.TrimSuffix(value, "DF"), "N"))
+
+	var fps float64
+	if num, den, ok := strings.Cut(value, "/"); ok {
+		n, err := strconv.Atoi(num)
+		if err != nil {
+			return TimecodeRate{}, fmt.Errorf("invalid frame rate %q", s)
+		}
+		d, err := strconv.Atoi(den)
+		if err != nil || d == 0 {
+			return TimecodeRate{}, fmt.Errorf("invalid frame rate %q", s)
+		}
+		fps = float64(n) / float64(d)
+	} else {
+		f, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return TimecodeRate{}, fmt.Errorf("invalid frame rate %q", s)
+		}
+		fps = f
+	}
+
+	rate, ok := RateFromFPS(fps)
+	if !ok {
+		return TimecodeRate{}, fmt.Errorf("unsupported frame rate %q", s)
+	}
+	if drop {
+		if rate, ok = rate.dropFrameRate(); !ok {
+			return TimecodeRate{}, fmt.Errorf("no drop-frame timecode at %q", s)
+		}
+	}
+	return rate, nil
+}
+
+// RateFromFPS finds the non-drop rate closest to a measured number of frames per second,
+// such as ffprobe gives, if one is close enough.
+func RateFromFPS(fps float64) (TimecodeRate, bool) {
+	for _, rate := range TimecodeRates {
+		if !rate.DropFrame && math.Abs(rate.FPS()-fps) < 0.01 {
+			return rate, true
+		}
+	}
+	return TimecodeRate{}, false
+}
+
+// Timecode is a frame of a recording, counted from 00:00:00:00 in a rate.
+type Timecode struct {
+	Frames int
+	Rate   TimecodeRate
+}
+
+// ParseTimecode reads HH:MM:SS:FF in a rate. A semicolon or a period before the frames
+// marks drop-frame timecode, so 29.97, or 30, is read as 29.97DF. Vidispine time codes
+// such as 1000@PAL are in the rate they name, and the rate given is not used.
+func ParseTimecode(s string, rate TimecodeRate) (Timecode, error) {
+	if frames, name, ok := strings.Cut(s, "@"); ok {
+		r, err := ParseRate(name)
+		if err != nil {
+			return Timecode{}, err
+		}
+		n, err := strconv.Atoi(frames)
+		if err != nil {
+			return Timecode{}, fmt.Errorf("%w: %q", ErrInvalidTimecode, s)
+		}
+		return Timecode{Frames: n, Rate: r}, nil
+	}
+
+	if rate.IsZero() {
+		return Timecode{}, errors.New("no frame rate")
+	}
+
+	if strings.ContainsAny(s, ";.") {
+		dropFrame, ok := rate.dropFrameRate()
+		if !ok {
+			return Timecode{}, fmt.Errorf("%w: %q is drop-frame, which %s fps is not", ErrInvalidTimecode, s, rate)
+		}
+		rate = dropFrame
+		s = strings.NewReplacer(";", ":", ".", ":").Replace(s)
+	}
+
+	parts := strings.Split(s, ":")
+	if len(parts) != 4 {
+		return Timecode{}, fmt.Errorf("%w: %q", ErrInvalidTimecode, s)
+	}
+	var fields [4]int
+	for i, part := range parts {
+		n, err := strconv.Atoi(part)
+		if err != nil || n < 0 {
+			return Timecode{}, fmt.Errorf("%w: %q", ErrInvalidTimecode, s)
+		}
+		fields[i] = n
+	}
+	hours, minutes, seconds, frames := fields[0], fields[1], fields[2], fields[3]
+
+	if minutes > 59 || seconds > 59 || frames >= rate.Base() {
+		return Timecode{}, fmt.Errorf("%w: %q at %s fps", ErrInvalidTimecode, s, rate)
+	}
+
+	drop := rate.droppedPerMinute()
+	if drop > 0 && seconds == 0 && frames < drop && minutes%10 != 0 {
+		return Timecode{}, fmt.Errorf("%w: drop-frame timecode skips %q", ErrInvalidTimecode, s)
+	}
+
+	total := (hours*3600+minutes*60+seconds)*rate.Base() + frames
+	totalMinutes := hours*60 + minutes
+	total -= drop * (totalMinutes - totalMinutes/10)
+
+	return Timecode{Frames: total, Rate: rate}, nil
+}
+
+// MustParseTimecode is ParseTimecode for timecode known to be valid.
+func MustParseTimecode(s string, rate TimecodeRate) Timecode {
+	tc, err := ParseTimecode(s, rate)
+	if err != nil {
+		panic(err)
+	}
+	return tc
+}
+
+// String writes the timecode as HH:MM:SS:FF, or HH:MM:SS;FF when it is drop-frame.
+// Frames before the start are written as they are counted, from 00:00:00:00 back.
+func (t Timecode) String() string {
+	base := t.Rate.Base()
+	if base == 0 {
+		return ""
+	}
+
+	sign := ""
+	frames := t.Frames
+	if frames < 0 {
+		sign = "-"
+		frames = -frames
+	}
+
+	if drop := t.Rate.droppedPerMinute(); drop > 0 {
+		perMinute := base*60 - drop
+		perTenMinutes := perMinute*10 + drop
+		tens, rest := frames/perTenMinutes, frames%perTenMinutes
+		frames += drop * 9 * tens
+		if rest > drop {
+			frames += drop * ((rest - drop) / perMinute)
+		}
+	}
+
+	separator := ":"
+	if t.Rate.DropFrame {
+		separator = ";"
+	}
+	return fmt.Sprintf("%s%02d:%02d:%02d%s%02d", sign,
+		frames/(base*3600), frames/(base*60)%60, frames/base%60, separator, frames%base)
+}
+
+// Seconds is the time from the start to the frame.
+func (t Timecode) Seconds() float64 {
+	return float64(t.Frames) * float64(t.Rate.Den) / float64(t.Rate.Num)
+}
+
+// Samples is the number of audio samples from the start to the frame, rounded down.
+func (t Timecode) Samples(sampleRate int) int {
+	return int(int64(t.Frames) * int64(sampleRate) * int64(t.Rate.Den) / int64(t.Rate.Num))
+}
+
+// In is the frame nearest the same time in another rate.
+func (t Timecode) In(rate TimecodeRate) Timecode {
+	if t.Rate.Num == rate.Num && t.Rate.Den == rate.Den {
+		return Timecode{Frames: t.Frames, Rate: rate}
+	}
+	frames := math.Round(float64(t.Frames) * float64(t.Rate.Den*rate.Num) / float64(t.Rate.Num*rate.Den))
+	return Timecode{Frames: int(frames), Rate: rate}
+}
+
+// AddFrames is the timecode a number of frames later.
+func (t Timecode) AddFrames(n int) Timecode {
+	return Timecode{Frames: t.Frames + n, Rate: t.Rate}
+}
+
+// Add is the timecode later by the time of another, which may be in another rate.
+func (t Timecode) Add(other Timecode) Timecode {
+	return t.AddFrames(other.In(t.Rate).Frames)
+}
+
+// Sub is the time from another timecode to this one, in the rate of this one.
+func (t Timecode) Sub(other Timecode) Timecode {
+	return t.AddFrames(-other.In(t.Rate).Frames)
+}
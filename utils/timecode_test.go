@@ -0,0 +1,133 @@
+package utils_test
+
+import (
+	"testing"
+
+	"github.com/bcc-code/bcc-media-flows/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseTimecode(t *testing.T) {
+	tests := []struct {
+		tc     string
+		rate   utils.TimecodeRate
+		frames int
+		string string
+	}{
+		{"00:00:01:00", utils.Rate25, 25, "00:00:01:00"},
+		{"10:00:00:00", utils.Rate25, 900000, "10:00:00:00"},
+		{"01:00:00:00", utils.Rate23976, 86400, "01:00:00:00"},
+		{"01:00:00:00", utils.Rate2997, 108000, "01:00:00:00"},
+		{"00:00:59:29", utils.Rate2997, 1799, "00:00:59:29"},
+		{"00:00:59;29", utils.Rate2997, 1799, "00:00:59;29"},
+		// Frames 0 and 1 are dropped at the start of every minute but the tenth.
+		{"00:01:00;02", utils.Rate2997DF, 1800, "00:01:00;02"},
+		{"00:10:00;00", utils.Rate2997DF, 17982, "00:10:00;00"},
+		{"01:00:00;00", utils.Rate2997DF, 107892, "01:00:00;00"},
+		{"01:00:00;00", utils.Rate30, 107892, "01:00:00;00"},
+		{"00:01:00;04", utils.Rate5994DF, 3600, "00:01:00;04"},
+		{"01:00:00:00", utils.Rate50, 180000, "01:00:00:00"},
+		{"1000@PAL", utils.Rate2997, 1000, "00:00:40:00"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.tc+"@"+tt.rate.String(), func(t *testing.T) {
+			tc, err := utils.ParseTimecode(tt.tc, tt.rate)
+			require.NoError(t, err)
+			assert.Equal(t, tt.frames, tc.Frames)
+			assert.Equal(t, tt.string, tc.String())
+		})
+	}
+}
+
+func TestParseTimecode_Invalid(t *testing.T) {
+	for _, tc := range []string{
+		"00:01:00;00", // dropped
+		"00:01:00;01", // dropped
+		"00:00:00:30",
+		"00:60:00:00",
+		"00:00:00",
+		"1000@48000",
+	} {
+		_, err := utils.ParseTimecode(tc, utils.Rate2997)
+		assert.Error(t, err, tc)
+	}
+
+	_, err := utils.ParseTimecode("00:00:00;00", utils.Rate25)
+	assert.ErrorIs(t, err, utils.ErrInvalidTimecode)
+}
+
+// Every frame of a day formats to timecode that parses back to it.
+func TestTimecode_DropFrameRoundTrip(t *testing.T) {
+	for _, rate := range []utils.TimecodeRate{utils.Rate2997DF, utils.Rate5994DF} {
+		for frames := 0; frames < 24*60*60*rate.Base(); frames += 7 {
+			tc := utils.Timecode{Frames: frames, Rate: rate}
+			parsed, err := utils.ParseTimecode(tc.String(), rate)
+			require.NoError(t, err, tc.String())
+			require.Equal(t, frames, parsed.Frames, tc.String())
+		}
+	}
+}
+
+func TestTimecode_Samples(t *testing.T) {
+	// An hour of drop-frame timecode is an hour, less 3.6ms.
+	tc := utils.MustParseTimecode("01:00:00;00", utils.Rate2997DF)
+	assert.Equal(t, 172799827, tc.Samples(48000))
+	assert.InDelta(t, 3599.9964, tc.Seconds(), 0.0001)
+
+	// The same timecode, non-drop, is 3.6 seconds later.
+	ndf := utils.MustParseTimecode("01:00:00:00", utils.Rate2997)
+	assert.Equal(t, 172972800, ndf.Samples(48000))
+
+	assert.Equal(t, 48048, utils.MustParseTimecode("00:00:01:00", utils.Rate2997).Samples(48000))
+}
+
+func TestTimecode_Arithmetic(t *testing.T) {
+	start := utils.MustParseTimecode("00:59:59;28", utils.Rate2997DF)
+	assert.Equal(t, "01:00:00;00", start.AddFrames(2).String())
+
+	// 10 seconds at 25 fps is 300 frames at 29.97 less the 0.3 of one.
+	later := start.Add(utils.MustParseTimecode("00:00:10:00", utils.Rate25))
+	assert.Equal(t, start.Frames+300, later.Frames)
+	assert.Equal(t, 300, later.Sub(start).Frames)
+
+	assert.Equal(t, 90000, utils.MustParseTimecode("01:00:00:00", utils.Rate25).In(utils.Rate50).Frames/2)
+	assert.Equal(t, "-00:00:01:00", utils.Timecode{Frames: -25, Rate: utils.Rate25}.String())
+}
+
+func TestParseRate(t *testing.T) {
+	tests := map[string]utils.TimecodeRate{
+		"23.976":     utils.Rate23976,
+		"23.98":      utils.Rate23976,
+		"24":         utils.Rate24,
+		"25":         utils.Rate25,
+		"PAL":        utils.Rate25,
+		"NTSC":       utils.Rate2997,
+		"29.97":      utils.Rate2997,
+		"29.97NDF":   utils.Rate2997,
+		"29.97DF":    utils.Rate2997DF,
+		"30000/1001": utils.Rate2997,
+		"30":         utils.Rate30,
+		"50":         utils.Rate50,
+		"59.94":      utils.Rate5994,
+		"59.94 DF":   utils.Rate5994DF,
+	}
+	for s, expected := range tests {
+		rate, err := utils.ParseRate(s)
+		require.NoError(t, err, s)
+		assert.Equal(t, expected, rate, s)
+	}
+
+	for _, rate := range utils.TimecodeRates {
+		parsed, err := utils.ParseRate(rate.String())
+		require.NoError(t, err, rate.String())
+		assert.Equal(t, rate, parsed)
+	}
+	assert.Equal(t, "30000/1001", utils.Rate2997DF.FFmpeg())
+
+	_, err := utils.ParseRate("25DF")
+	assert.Error(t, err)
+	_, err = utils.ParseRate("48000")
+	assert.Error(t, err)
+}
@@ -0,0 +1,144 @@
+package wfutils
+
+import (
+	"time"
+
+	"github.com/bcc-code/bcc-media-flows/activities"
+	"github.com/bcc-code/bcc-media-flows/services/webhooks"
+	"go.temporal.io/sdk/client"
+	"go.temporal.io/sdk/converter"
+	"go.temporal.io/sdk/interceptor"
+	"go.temporal.io/sdk/temporal"
+	"go.temporal.io/sdk/workflow"
+)
+
+// CallbackMemoKey is the memo field a workflow started with WithCallback keeps its
+// callback in.
+const CallbackMemoKey = "callback"
+
+// WithCallback makes the workflow post its outcome to the callback when it finishes,
+// successful or not. Without a URL the options are returned as they are.
+//
+// The callback is kept in the workflow's memo, which anyone who can read the workflow
+// in Temporal can see. It names its secret by ID; the activity that sends it looks the
+// secret up.
+func WithCallback(opts client.StartWorkflowOptions, callback webhooks.Callback) client.StartWorkflowOptions {
+	if callback.URL == "" {
+		return opts
+	}
+	memo := map[string]any{}
+	for k, v := range opts.Memo {
+		memo[k] = v
+	}
+	memo[CallbackMemoKey] = callback
+	opts.Memo = memo
+	return opts
+}
+
+// Retried for a day: a receiver that is down for a deploy, or for the night, still
+// hears about the workflow when it is back.
+var callbackActivityOptions = workflow.ActivityOptions{
+	StartToCloseTimeout:    time.Minute,
+	ScheduleToCloseTimeout: 24 * time.Hour,
+	RetryPolicy: &temporal.RetryPolicy{
+		InitialInterval:    10 * time.Second,
+		BackoffCoefficient: 2,
+		MaximumInterval:    30 * time.Minute,
+	},
+}
+
+type CallbackWorkerInterceptor struct {
+	interceptor.WorkerInterceptorBase
+}
+
+func (c *CallbackWorkerInterceptor) InterceptWorkflow(
+	ctx workflow.Context,
+	next interceptor.WorkflowInboundInterceptor,
+) interceptor.WorkflowInboundInterceptor {
+	return &CallbackWorkflowInboundInterceptor{
+		WorkflowInboundInterceptorBase: interceptor.WorkflowInboundInterceptorBase{
+			Next: next,
+		},
+	}
+}
+
+// CallbackWorkflowInboundInterceptor sends the callback of workflows started with
+// WithCallback once they return. Workflows started without one are not affected.
+type CallbackWorkflowInboundInterceptor struct {
+	interceptor.WorkflowInboundInterceptorBase
+}
+
+func (c *CallbackWorkflowInboundInterceptor) ExecuteWorkflow(
+	ctx workflow.Context,
+	in *interceptor.ExecuteWorkflowInput,
+) (any, error) {
+	result, err := c.Next.ExecuteWorkflow(ctx, in)
+
+	// A workflow continuing as new is not done; the next run keeps the memo and
+	// sends the callback when it is.
+	if workflow.IsContinueAsNewError(err) {
+		return result, err
+	}
+
+	callback, ok := workflowCallback(ctx)
+	if ok {
+		sendCallback(ctx, callback, result, err)
+	}
+
+	return result, err
+}
+
+func workflowCallback(ctx workflow.Context) (webhooks.Callback, bool) {
+	var callback webhooks.Callback
+
+	payload, ok := workflow.GetInfo(ctx).Memo.GetFields()[CallbackMemoKey]
+	if !ok {
+		return callback, false
+	}
+	if err := converter.GetDefaultDataConverter().FromPayload(payload, &callback); err != nil {
+		workflow.GetLogger(ctx).Error("Failed to read the callback memo", "error", err)
+		return callback, false
+	}
+	return callback, callback.URL != ""
+}
+
+// sendCallback waits for the callback to be delivered, or to run out of retries. A
+// callback that could not be delivered is logged; it does not change the outcome of
+// the workflow.
+func sendCallback(ctx workflow.Context, callback webhooks.Callback, result any, err error) {
+	info := workflow.GetInfo(ctx)
+	attributes := workflow.GetTypedSearchAttributes(ctx)
+	vxID, _ := attributes.GetKeyword(VXIDKey)
+	triggeredBy, _ := attributes.GetKeyword(TriggeredByKey)
+
+	event := webhooks.Event{
+		WorkflowID:  info.WorkflowExecution.ID,
+		RunID:       info.WorkflowExecution.RunID,
+		Workflow:    info.WorkflowType.Name,
+		Status:      webhooks.StatusCompleted,
+		VXID:        vxID,
+		TriggeredBy: triggeredBy,
+		Result:      result,
+		FinishedAt:  workflow.Now(ctx),
+	}
+	if err != nil {
+		event.Status = webhooks.StatusFailed
+		if temporal.IsCanceledError(err) {
+			event.Status = webhooks.StatusCanceled
+		}
+		event.Result = nil
+		event.Failure = err.Error()
+	}
+
+	// A canceled workflow's context is done, and would cancel the activity with it.
+	ctx, _ = workflow.NewDisconnectedContext(ctx)
+	ctx = workflow.WithActivityOptions(ctx, callbackActivityOptions)
+
+	sendErr := Execute(ctx, activities.Util.SendWebhook, activities.SendWebhookInput{
+		Callback: callback,
+		Event:    event,
+	}).Wait(ctx)
+	if sendErr != nil {
+		workflow.GetLogger(ctx).Error("Failed to send the callback", "url", callback.URL, "error", sendErr)
+	}
+}
@@ -0,0 +1,89 @@
+package wfutils
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/bcc-code/bcc-media-flows/activities"
+	"github.com/bcc-code/bcc-media-flows/services/webhooks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.temporal.io/sdk/client"
+	"go.temporal.io/sdk/interceptor"
+	"go.temporal.io/sdk/testsuite"
+	"go.temporal.io/sdk/worker"
+	"go.temporal.io/sdk/workflow"
+)
+
+type callbackResult struct {
+	ID string
+}
+
+func callbackWorkflow(_ workflow.Context, fail bool) (*callbackResult, error) {
+	if fail {
+		return nil, errors.New("transcode failed")
+	}
+	return &callbackResult{ID: "VX-1"}, nil
+}
+
+// runWithCallback runs callbackWorkflow started with opts, and returns what the
+// webhook activity was asked to send, or nil when it was not called.
+func runWithCallback(t *testing.T, opts client.StartWorkflowOptions, fail bool) *activities.SendWebhookInput {
+	t.Helper()
+
+	suite := &testsuite.WorkflowTestSuite{}
+	env := suite.NewTestWorkflowEnvironment()
+	env.SetWorkerOptions(worker.Options{
+		Interceptors: []interceptor.WorkerInterceptor{&CallbackWorkerInterceptor{}},
+	})
+	env.RegisterWorkflow(callbackWorkflow)
+	env.SetMemoOnStart(opts.Memo)
+	env.SetTypedSearchAttributesOnStart(opts.TypedSearchAttributes)
+
+	var sent *activities.SendWebhookInput
+	env.OnActivity(activities.Util.SendWebhook, mock.Anything, mock.MatchedBy(func(input activities.SendWebhookInput) bool {
+		sent = &input
+		return true
+	})).Return(nil, nil)
+
+	env.ExecuteWorkflow(callbackWorkflow, fail)
+	require.True(t, env.IsWorkflowCompleted())
+	return sent
+}
+
+func TestCallback_Completed(t *testing.T) {
+	opts := WithCallback(NewWorkflowOptions("worker", "VX-1", "portal"), webhooks.Callback{
+		URL:      "https://portal.example/done",
+		SecretID: "portal",
+	})
+
+	sent := runWithCallback(t, opts, false)
+
+	require.NotNil(t, sent, "no callback was sent")
+	assert.Equal(t, webhooks.Callback{URL: "https://portal.example/done", SecretID: "portal"}, sent.Callback)
+	assert.Equal(t, webhooks.StatusCompleted, sent.Event.Status)
+	assert.Equal(t, "callbackWorkflow", sent.Event.Workflow)
+	assert.Equal(t, "VX-1", sent.Event.VXID)
+	assert.Equal(t, "portal", sent.Event.TriggeredBy)
+	assert.Equal(t, map[string]any{"ID": "VX-1"}, sent.Event.Result)
+	assert.Empty(t, sent.Event.Failure)
+}
+
+func TestCallback_Failed(t *testing.T) {
+	opts := WithCallback(NewWorkflowOptions("worker", "", ""), webhooks.Callback{URL: "https://portal.example/done"})
+
+	sent := runWithCallback(t, opts, true)
+
+	require.NotNil(t, sent, "no callback was sent")
+	assert.Equal(t, webhooks.StatusFailed, sent.Event.Status)
+	assert.Contains(t, sent.Event.Failure, "transcode failed")
+	assert.Nil(t, sent.Event.Result)
+}
+
+func TestCallback_NotRequested(t *testing.T) {
+	opts := WithCallback(NewWorkflowOptions("worker", "VX-1", ""), webhooks.Callback{})
+
+	assert.Nil(t, opts.Memo)
+	assert.Nil(t, runWithCallback(t, opts, false))
+}
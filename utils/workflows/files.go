@@ -9,11 +9,11 @@ import (
 
 	"github.com/bcc-code/bcc-media-flows/services/notifications"
 	"github.com/bcc-code/bcc-media-flows/services/rclone"
+	"github.com/bcc-code/bcc-media-flows/services/s3"
 	"github.com/bcc-code/bcc-media-flows/services/telegram"
 	"go.temporal.io/sdk/temporal"
 
 	"github.com/bcc-code/bcc-media-flows/activities"
-	"github.com/bcc-code/bcc-media-flows/environment"
 	"github.com/bcc-code/bcc-media-flows/paths"
 	"go.temporal.io/sdk/workflow"
 )
@@ -35,7 +35,10 @@ func StandardizeFileName(ctx workflow.Context, file paths.Path) (paths.Path, err
 }
 
 func MoveFile(ctx workflow.Context, source, destination paths.Path, priority rclone.Priority) error {
-	external := source.OnExternalDrive() || destination.OnExternalDrive()
+	external, err := OnExternalDrive(ctx, source, destination)
+	if err != nil {
+		return err
+	}
 
 	if external {
 		return RcloneMoveFile(ctx, source, destination, priority)
@@ -48,7 +51,10 @@ func MoveFile(ctx workflow.Context, source, destination paths.Path, priority rcl
 }
 
 func CopyFile(ctx workflow.Context, source, destination paths.Path) error {
-	external := source.OnExternalDrive() || destination.OnExternalDrive()
+	external, err := OnExternalDrive(ctx, source, destination)
+	if err != nil {
+		return err
+	}
 	if external {
 		return RcloneCopyFile(ctx, source, destination, rclone.PriorityNormal)
 	} else {
@@ -143,12 +149,14 @@ func GetWorkflowIsilonOutputFolder(ctx workflow.Context, root string) (paths.Pat
 // GetIsilonExportFolder is where a finished export is delivered for humans to pick
 // up, so it is named after the title rather than the run alone.
 func GetIsilonExportFolder(ctx workflow.Context, safeTitle string) paths.Path {
-	date := workflow.Now(ctx)
-	id := workflow.GetInfo(ctx).OriginalRunID
+	return IsilonExportFolder(workflow.Now(ctx), safeTitle, workflow.GetInfo(ctx).OriginalRunID)
+}
 
+// IsilonExportFolder is the export folder of a run started at a time.
+func IsilonExportFolder(date time.Time, safeTitle, runID string) paths.Path {
 	return paths.New(
 		paths.IsilonDrive,
-		filepath.Join("Export", date.Format("2006-01"), safeTitle+"-"+id),
+		filepath.Join("Export", date.Format("2006-01"), safeTitle+"-"+runID),
 	)
 }
 
@@ -169,7 +177,7 @@ func GetWorkflowTempFolder(ctx workflow.Context) (paths.Path, error) {
 
 	var path paths.Path
 	err := workflow.SideEffect(ctx, func(ctx workflow.Context) any {
-		return paths.MustParse(filepath.Join(environment.GetTempMountPrefix(), "workflows", info.OriginalRunID))
+		return workflowTempFolder(info.OriginalRunID)
 	}).Get(&path)
 
 	if err != nil {
@@ -179,6 +187,24 @@ func GetWorkflowTempFolder(ctx workflow.Context) (paths.Path, error) {
 	return path, CreateFolder(ctx, path)
 }
 
+func workflowTempFolder(runID string) paths.Path {
+	return paths.New(paths.TempDrive, filepath.Join("workflows", runID))
+}
+
+// RunningWorkflowTempFolders are the temp folders of the workflows that are running,
+// which a cleanup leaves alone.
+func RunningWorkflowTempFolders(ctx workflow.Context) ([]paths.Path, error) {
+	runs, err := Execute(ctx, activities.Util.GetRunningWorkflowRuns, nil).Result(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var folders []paths.Path
+	for _, id := range runs {
+		folders = append(folders, workflowTempFolder(id))
+	}
+	return folders, nil
+}
+
 func IsImage(ctx workflow.Context, file paths.Path) (bool, error) {
 	mimeType, err := Execute(ctx, activities.Util.GetMimeType, activities.AnalyzeFileParams{
 		FilePath: file,
@@ -274,6 +300,31 @@ func RcloneCopyFileWithNotifications(ctx workflow.Context, source, destination p
 	return nil
 }
 
+// S3UploadFile uploads the file to an S3 destination given as RcloneCopyFile would
+// reach it, such as "s3prod:vod-asset-ingest-prod/path/file.mxf", without the rclone
+// daemon. An attempt that is lost part way is resumed by the retry rather than
+// started over.
+func S3UploadFile(ctx workflow.Context, source paths.Path, destination string) (*s3.Result, error) {
+	options := GetDefaultActivityOptions()
+	options.StartToCloseTimeout = 12 * time.Hour
+	options.ScheduleToCloseTimeout = 24 * time.Hour
+	// A part is heartbeated when it is uploaded, and one can take the whole request
+	// timeout of the client.
+	options.HeartbeatTimeout = 15 * time.Minute
+	ctx = workflow.WithActivityOptions(ctx, options)
+
+	return Execute(ctx, activities.Util.S3UploadFile, activities.S3UploadFileInput{
+		Source:      source,
+		Destination: destination,
+	}).Result(ctx)
+}
+
+// S3UploadToDrive is S3UploadFile to a path on an S3 drive, such as AssetIngestDrive,
+// in place of RcloneCopyFile.
+func S3UploadToDrive(ctx workflow.Context, source, destination paths.Path) (*s3.Result, error) {
+	return S3UploadFile(ctx, source, destination.Rclone())
+}
+
 func RcloneMoveFile(ctx workflow.Context, source, destination paths.Path, priority rclone.Priority) error {
 	jobID, err := Execute(ctx, activities.Util.RcloneMoveFile, activities.RcloneFileInput{
 		Source:      source,
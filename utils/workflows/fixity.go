@@ -0,0 +1,52 @@
+package wfutils
+
+import (
+	"errors"
+
+	"github.com/bcc-code/bcc-media-flows/activities"
+	vsactivity "github.com/bcc-code/bcc-media-flows/activities/vidispine"
+	"github.com/bcc-code/bcc-media-flows/paths"
+	"github.com/bcc-code/bcc-media-flows/services/fixity"
+	"github.com/bcc-code/bcc-media-flows/services/vidispine/vscommon"
+	"go.temporal.io/sdk/workflow"
+)
+
+// GetFixity returns the checksums the original of an asset was recorded with at
+// ingest, or nil for an asset ingested before they were.
+func GetFixity(ctx workflow.Context, assetID string) (*fixity.Checksums, error) {
+	meta, err := Execute(ctx, activities.Vidispine.GetVXMetadataFields, vsactivity.GetVXMetadataFieldsParams{
+		VXID:   assetID,
+		Fields: []vscommon.FieldType{vscommon.FieldFixity},
+	}).Result(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	sums, err := fixity.Parse(meta.Get(vscommon.FieldFixity, ""))
+	if errors.Is(err, fixity.ErrNotSet) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &sums, nil
+}
+
+// VerifyFile checks a file against the checksums it was recorded with. A file on a
+// drive the worker does not mount is hashed by rclone, which only knows the MD5.
+func VerifyFile(ctx workflow.Context, file paths.Path, sums fixity.Checksums) error {
+	external, err := OnExternalDrive(ctx, file)
+	if err != nil {
+		return err
+	}
+	if external {
+		return Execute(ctx, activities.Util.RcloneVerifyChecksums, activities.VerifyChecksumsInput{
+			Path:      file,
+			Checksums: sums,
+		}).Wait(ctx)
+	}
+	return Execute(ctx, activities.Util.VerifyChecksums, activities.VerifyChecksumsInput{
+		Path:      file,
+		Checksums: sums,
+	}).Wait(ctx)
+}
@@ -0,0 +1,17 @@
+package wfutils
+
+import (
+	"github.com/bcc-code/bcc-media-flows/languages"
+	"go.temporal.io/sdk/workflow"
+)
+
+// Languages is the language table the worker is configured with. It goes through
+// SideEffect, as the table is configuration a replay might not share, so workflows read
+// it here rather than from the maps of the languages package.
+func Languages(ctx workflow.Context) (languages.LanguageList, error) {
+	var list languages.LanguageList
+	err := workflow.SideEffect(ctx, func(workflow.Context) any {
+		return languages.Current().Languages
+	}).Get(&list)
+	return list, err
+}
@@ -19,15 +19,22 @@ func SendTelegramError(ctx workflow.Context, channel telegram.Chat, vxid string,
 		subject += fmt.Sprintf(" of `%s`", vxid)
 	}
 
-	SendTelegramText(ctx, channel, fmt.Sprintf("🟥 %s failed:\n```\n%s\n```", subject, err.Error()))
+	sendTelegramText(ctx, channel, notifications.EventFailed, fmt.Sprintf("🟥 %s failed:\n```\n%s\n```", subject, err.Error()))
 }
 
 func SendTelegramText(ctx workflow.Context, channel telegram.Chat, message string) {
+	sendTelegramText(ctx, channel, notifications.EventMessage, message)
+}
+
+// sendTelegramText sends the message as the event, which is what the notification
+// routes can send it elsewhere by.
+func sendTelegramText(ctx workflow.Context, channel telegram.Chat, event string, message string) {
 	msg, err := telegram.NewMessage(channel, notifications.Simple{Message: message})
 	if err != nil {
 		workflow.GetLogger(ctx).Error("Failed to create telegram message", "error", err)
 		return
 	}
+	msg.Event = event
 
 	err = Execute(ctx, activities.Util.SendTelegramMessage, msg).Wait(ctx)
 
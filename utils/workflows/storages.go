@@ -0,0 +1,72 @@
+package wfutils
+
+import (
+	"github.com/bcc-code/bcc-media-flows/paths"
+	"go.temporal.io/sdk/workflow"
+)
+
+// The storage registry is configuration a replay might not share, so workflows look
+// paths and storages up here, through SideEffect, rather than in the paths package.
+
+// ParsePath is paths.Parse. A path no drive has is recorded as nil, so a replay fails
+// the same way.
+func ParsePath(ctx workflow.Context, path string) (paths.Path, error) {
+	var parsed *paths.Path
+	err := workflow.SideEffect(ctx, func(workflow.Context) any {
+		p, err := paths.Parse(path)
+		if err != nil {
+			return nil
+		}
+		return &p
+	}).Get(&parsed)
+	if err != nil {
+		return paths.Path{}, err
+	}
+	if parsed == nil {
+		return paths.Path{}, paths.InvalidPathError(path)
+	}
+	return *parsed, nil
+}
+
+// OnExternalDrive is whether any of the files is on an external drive, which is copied
+// to and from with rclone.
+func OnExternalDrive(ctx workflow.Context, files ...paths.Path) (bool, error) {
+	var external bool
+	err := workflow.SideEffect(ctx, func(workflow.Context) any {
+		for _, f := range files {
+			if f.OnExternalDrive() {
+				return true
+			}
+		}
+		return false
+	}).Get(&external)
+	return external, err
+}
+
+func storage(ctx workflow.Context, lookup func(paths.Registry) *paths.Storage) (*paths.Storage, error) {
+	var s *paths.Storage
+	err := workflow.SideEffect(ctx, func(workflow.Context) any {
+		return lookup(paths.Current())
+	}).Get(&s)
+	return s, err
+}
+
+// VidispineStorage is the Vidispine storage with a VXID, or nil.
+func VidispineStorage(ctx workflow.Context, vxid string) (*paths.Storage, error) {
+	return storage(ctx, func(r paths.Registry) *paths.Storage {
+		return r.VidispineStorage(vxid)
+	})
+}
+
+// VidispineStorageForPath is the Vidispine storage a file, as Vidispine has its path,
+// is on, or nil.
+func VidispineStorageForPath(ctx workflow.Context, path string) (*paths.Storage, error) {
+	return storage(ctx, func(r paths.Registry) *paths.Storage {
+		return r.VidispineStorageForPath(path)
+	})
+}
+
+// ColdArchive is the storage originals are archived to, or nil when there is none.
+func ColdArchive(ctx workflow.Context) (*paths.Storage, error) {
+	return storage(ctx, paths.Registry.ColdArchive)
+}
@@ -0,0 +1,72 @@
+package wfutils_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/bcc-code/bcc-media-flows/paths"
+	"github.com/bcc-code/bcc-media-flows/utils/testutils"
+	wfutils "github.com/bcc-code/bcc-media-flows/utils/workflows"
+	"github.com/stretchr/testify/suite"
+	"go.temporal.io/sdk/temporal"
+	"go.temporal.io/sdk/testsuite"
+	"go.temporal.io/sdk/workflow"
+)
+
+type StoragesTestSuite struct {
+	suite.Suite
+	testsuite.WorkflowTestSuite
+}
+
+func parsePathWorkflow(ctx workflow.Context, path string) (paths.Path, error) {
+	return wfutils.ParsePath(ctx, path)
+}
+
+// coldArchiveWorkflow returns the Vidispine storage of the cold archive, or nothing.
+func coldArchiveWorkflow(ctx workflow.Context) (string, error) {
+	storage, err := wfutils.ColdArchive(ctx)
+	if err != nil || storage == nil {
+		return "", err
+	}
+	return storage.VidispineID, nil
+}
+
+func (s *StoragesTestSuite) Test_ParsePath() {
+	env := s.NewTestWorkflowEnvironment()
+	env.ExecuteWorkflow(parsePathWorkflow, "/mnt/temp/workflows/a.mxf")
+
+	s.Require().NoError(env.GetWorkflowError())
+	var got paths.Path
+	s.Require().NoError(env.GetWorkflowResult(&got))
+	s.Equal(paths.New(paths.TempDrive, "workflows/a.mxf"), got)
+}
+
+func (s *StoragesTestSuite) Test_ParsePath_Invalid() {
+	env := s.NewTestWorkflowEnvironment()
+	env.ExecuteWorkflow(parsePathWorkflow, "/nowhere/a.mxf")
+
+	var appErr *temporal.ApplicationError
+	s.Require().True(errors.As(env.GetWorkflowError(), &appErr))
+	s.Equal("invalid_path", appErr.Type())
+}
+
+func (s *StoragesTestSuite) Test_ColdArchive() {
+	env := s.NewTestWorkflowEnvironment()
+	env.ExecuteWorkflow(coldArchiveWorkflow)
+	s.Require().NoError(env.GetWorkflowError())
+	var none string
+	s.Require().NoError(env.GetWorkflowResult(&none))
+	s.Empty(none)
+
+	testutils.WithColdArchive(s.T())
+	env = s.NewTestWorkflowEnvironment()
+	env.ExecuteWorkflow(coldArchiveWorkflow)
+	s.Require().NoError(env.GetWorkflowError())
+	var got string
+	s.Require().NoError(env.GetWorkflowResult(&got))
+	s.Equal("VX-90", got)
+}
+
+func TestStoragesTestSuite(t *testing.T) {
+	suite.Run(t, new(StoragesTestSuite))
+}
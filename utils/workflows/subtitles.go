@@ -0,0 +1,32 @@
+package wfutils
+
+import (
+	"github.com/bcc-code/bcc-media-flows/activities"
+	"github.com/bcc-code/bcc-media-flows/paths"
+	"github.com/bcc-code/bcc-media-flows/services/subtitles"
+	"go.temporal.io/sdk/workflow"
+)
+
+// ConvertSubtitles converts the subtitle file to the format the extension of
+// destination names, moving the cues by offset seconds.
+func ConvertSubtitles(ctx workflow.Context, source, destination paths.Path, offset float64) (paths.Path, error) {
+	result, err := Execute(ctx, activities.Util.ConvertSubtitles, activities.ConvertSubtitlesInput{
+		Source:      source,
+		Destination: destination,
+		Offset:      offset,
+	}).Result(ctx)
+	if err != nil {
+		return paths.Path{}, err
+	}
+	return result.Path, nil
+}
+
+// ValidateSubtitles checks the subtitle file against the QC rules of the language,
+// and, when vxID is set, the duration of the asset.
+func ValidateSubtitles(ctx workflow.Context, file paths.Path, language, vxID string) (*subtitles.Report, error) {
+	return Execute(ctx, activities.Util.ValidateSubtitles, activities.ValidateSubtitlesInput{
+		File:     file,
+		Language: language,
+		VXID:     vxID,
+	}).Result(ctx)
+}
@@ -6,6 +6,7 @@ import (
 	"github.com/bcc-code/bcc-media-flows/activities"
 	vsactivity "github.com/bcc-code/bcc-media-flows/activities/vidispine"
 	"github.com/bcc-code/bcc-media-flows/paths"
+	"github.com/bcc-code/bcc-media-flows/services/vidispine/vscommon"
 	"go.temporal.io/sdk/temporal"
 	"go.temporal.io/sdk/workflow"
 )
@@ -25,6 +26,27 @@ func WaitForVidispineJob(ctx workflow.Context, jobID string) error {
 	}).Wait(ctx)
 }
 
+// MarkUsed records in portal_last_used that the assets were used now, which keeps
+// their originals out of the cold archive until they have gone unused for as long
+// again. It does not fail the caller: an asset it misses is only archived sooner.
+func MarkUsed(ctx workflow.Context, vxids ...string) {
+	now := workflow.Now(ctx).UTC().Format(vsactivity.SearchTime)
+
+	var tasks []Task[*vsactivity.SetVXMetadataFieldResult]
+	for _, vxid := range vxids {
+		tasks = append(tasks, Execute(ctx, activities.Vidispine.SetVXMetadataFieldActivity, vsactivity.VXMetadataFieldParams{
+			ItemID: vxid,
+			Key:    vscommon.FieldLastUsed.Value,
+			Value:  now,
+		}))
+	}
+	for i, task := range tasks {
+		if err := task.Wait(ctx); err != nil {
+			workflow.GetLogger(ctx).Warn("Could not mark asset as used", "vxid", vxids[i], "error", err)
+		}
+	}
+}
+
 // WaitForFileVisibleInVidispineStorage blocks until Vidispine can see the file
 // on its default storage (i.e. Mediabanken has stat'd it through its own NFS
 // mount). Use after writing to Isilon and before kicking off a Vidispine import
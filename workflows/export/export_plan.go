@@ -0,0 +1,353 @@
+package export
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/bcc-code/bcc-media-flows/languages"
+	"github.com/bcc-code/bcc-media-flows/services/vidispine"
+	"github.com/bcc-code/bcc-media-flows/utils"
+	wfutils "github.com/bcc-code/bcc-media-flows/utils/workflows"
+	"github.com/samber/lo"
+)
+
+// Where a language's audio comes from in a clip.
+const (
+	AudioSourceEmbedded = "embedded"
+	AudioSourceRelated  = "related"
+	// AudioSourceFallback is the Norwegian audio, used for a language that has none.
+	AudioSourceFallback = "fallback to nor"
+	AudioSourceSilence  = "silence"
+	AudioSourceMissing  = "missing"
+	// AudioSourceMixed is a language whose clips take their audio from different places.
+	AudioSourceMixed = "mixed"
+)
+
+// planRunID stands in for the run ID of the export, which names the folders it uploads
+// to, as the plan is made by another run.
+const planRunID = "<run ID>"
+
+// ExportPlan is what VXExport would do with its parameters, worked out from the export
+// data without encoding or uploading anything. It is what a DryRun export returns.
+type ExportPlan struct {
+	VXID     string `json:"vxid"`
+	Title    string `json:"title"`
+	Duration string `json:"duration"`
+	// HasVideo is false for audio-only items, whose VOD video is rendered from the audio.
+	HasVideo  bool              `json:"has_video"`
+	Clips     []PlannedClip     `json:"clips"`
+	Audio     []PlannedAudio    `json:"audio"`
+	Subtitles []PlannedSubtitle `json:"subtitles"`
+	// MissingLanguages are the languages that have no audio of their own in some clip:
+	// they are silent there, or take the Norwegian audio, or fail the export.
+	MissingLanguages []string `json:"missing_languages"`
+	// NeedsRestore are the assets whose originals are in the cold archive, which the
+	// export restores before it starts.
+	NeedsRestore []string             `json:"needs_restore"`
+	Destinations []PlannedDestination `json:"destinations"`
+	Warnings     []string             `json:"warnings"`
+}
+
+type PlannedClip struct {
+	VXID        string `json:"vxid"`
+	VideoFile   string `json:"video_file"`
+	In          string `json:"in"`
+	Out         string `json:"out"`
+	SequenceIn  string `json:"sequence_in"`
+	SequenceOut string `json:"sequence_out"`
+}
+
+type PlannedAudio struct {
+	Language string `json:"language"`
+	Source   string `json:"source"`
+	// Clips are the sources of each clip, in the order of the clips. Related audio is
+	// followed by the item it comes from.
+	Clips []string `json:"clips"`
+}
+
+type PlannedSubtitle struct {
+	Language string `json:"language"`
+	// AI is set for the transcription, which is exported when no clip has subtitles
+	// and AI subtitles are allowed.
+	AI bool `json:"ai,omitempty"`
+	// Files are the subtitle files of each clip, in the order of the clips; a clip
+	// without subtitles in the language has none and gets an empty file.
+	Files []string `json:"files"`
+}
+
+type PlannedDestination struct {
+	Destination string `json:"destination"`
+	// CoveredBy is set when another destination's export does this one too, and
+	// nothing else is planned for it.
+	CoveredBy   string              `json:"covered_by,omitempty"`
+	Resolutions []PlannedResolution `json:"resolutions,omitempty"`
+	// Files are the files the export writes to its output folder.
+	Files []string `json:"files"`
+	// UploadTo is where the output folder goes, which for Isilon is the folder itself.
+	UploadTo string `json:"upload_to"`
+	// Error is why the export to the destination would fail.
+	Error string `json:"error,omitempty"`
+}
+
+type PlannedResolution struct {
+	Resolution string   `json:"resolution"`
+	File       bool     `json:"file"`
+	Languages  []string `json:"languages"`
+}
+
+// planExport works out the plan of an export. The file names follow the resolutions asked
+// for; the encoder may fit them to the aspect ratio of the source.
+func planExport(params VXExportParams, destinations []*AssetExportDestination, data *vidispine.ExportData, languagesByISO map[string]languages.Language, hasVideo bool, now time.Time) *ExportPlan {
+	plan := &ExportPlan{
+		VXID:     params.VXID,
+		Title:    data.Title,
+		HasVideo: hasVideo,
+		Warnings: data.Warnings,
+	}
+
+	var duration float64
+	for _, clip := range data.Clips {
+		duration += clip.OutSeconds - clip.InSeconds
+		plan.Clips = append(plan.Clips, PlannedClip{
+			VXID:        clip.VXID,
+			VideoFile:   clip.VideoFile,
+			In:          formatSecondsToTimestamp(clip.InSeconds),
+			Out:         formatSecondsToTimestamp(clip.OutSeconds),
+			SequenceIn:  formatSecondsToTimestamp(clip.SequenceIn),
+			SequenceOut: formatSecondsToTimestamp(clip.SequenceOut),
+		})
+	}
+	plan.Duration = formatSecondsToTimestamp(duration)
+
+	if len(data.Clips) == 0 {
+		plan.Warnings = append(plan.Warnings, "No clips found, so there is nothing to export.")
+	}
+
+	plan.Audio, plan.MissingLanguages = planAudio(params.Languages, data.Clips)
+	plan.Subtitles = planSubtitles(data.Clips, params.SubsAllowAI)
+
+	audioLanguages := lo.FilterMap(plan.Audio, func(a PlannedAudio, _ int) (string, bool) {
+		return a.Language, a.Source != AudioSourceMissing
+	})
+
+	hasDestination := func(d AssetExportDestination) bool {
+		return lo.SomeBy(destinations, func(dest *AssetExportDestination) bool {
+			return *dest == d
+		})
+	}
+
+	// Mirrors the destination branching of VXExport.
+	for _, dest := range destinations {
+		planned := PlannedDestination{Destination: dest.Value}
+
+		switch *dest {
+		case AssetExportDestinationCMAF:
+			if hasDestination(AssetExportDestinationVOD) {
+				planned.CoveredBy = AssetExportDestinationVOD.Value
+			} else {
+				planned.CoveredBy = AssetExportDestinationIsilon.Value
+			}
+		case AssetExportDestinationIsilon:
+			if hasDestination(AssetExportDestinationVOD) {
+				planned.CoveredBy = AssetExportDestinationVOD.Value
+				break
+			}
+			planVOD(&planned, params, data, languagesByISO, plan.Subtitles, audioLanguages, hasVideo, hasDestination(AssetExportDestinationCMAF))
+			planned.UploadTo = wfutils.IsilonExportFolder(now, data.SafeTitle, planRunID).Linux()
+		case AssetExportDestinationVOD:
+			planVOD(&planned, params, data, languagesByISO, plan.Subtitles, audioLanguages, hasVideo, hasDestination(AssetExportDestinationCMAF))
+			planned.UploadTo = vodIngestBucket + uploadFolder(data.SafeTitle, planRunID)
+		case AssetExportDestinationXDCAM:
+			planXDCAM(&planned, data, hasVideo)
+		case AssetExportDestinationBMM, AssetExportDestinationBMMIntegration:
+			planBMM(&planned, *dest, data, audioLanguages)
+		}
+
+		plan.Destinations = append(plan.Destinations, planned)
+	}
+
+	return plan
+}
+
+// planRestores adds the archived originals to the plan. They are restored when the
+// export runs, which can take up to 2 days.
+func planRestores(plan *ExportPlan, archived []string, firstClipArchived bool) {
+	plan.NeedsRestore = archived
+	if len(archived) > 0 {
+		plan.Warnings = append(plan.Warnings, fmt.Sprintf("The originals of %s are in the cold archive and need a restore (up to 2 days) before the export.", strings.Join(archived, ", ")))
+	}
+	if firstClipArchived {
+		plan.Warnings = append(plan.Warnings, "The first clip is archived and was not probed, so the plan assumes the item has video.")
+	}
+}
+
+// planAudio finds where the audio of each language comes from, in the languages asked
+// for or, when none are, in those the clips have.
+func planAudio(langs []string, clips []*vidispine.Clip) ([]PlannedAudio, []string) {
+	if len(langs) == 0 {
+		for _, clip := range clips {
+			langs = append(langs, lo.Keys(clip.AudioFiles)...)
+		}
+		langs = lo.Uniq(langs)
+		sort.Strings(langs)
+	}
+
+	var audio []PlannedAudio
+	var missing []string
+	for _, lang := range langs {
+		planned := PlannedAudio{Language: lang}
+		isMissing := false
+		for _, clip := range clips {
+			source, detail := clipAudioSource(clip, lang)
+			if (lang != "nor" && source == AudioSourceFallback) || source == AudioSourceSilence || source == AudioSourceMissing {
+				isMissing = true
+			}
+
+			switch planned.Source {
+			case "":
+				planned.Source = source
+			case source:
+			default:
+				planned.Source = AudioSourceMixed
+			}
+
+			if detail != "" {
+				source += " " + detail
+			}
+			planned.Clips = append(planned.Clips, source)
+		}
+		if len(clips) == 0 {
+			planned.Source = AudioSourceMissing
+		}
+
+		if isMissing {
+			missing = append(missing, lang)
+		}
+		audio = append(audio, planned)
+	}
+
+	return audio, missing
+}
+
+func clipAudioSource(clip *vidispine.Clip, lang string) (source, detail string) {
+	audio := clip.AudioFiles[lang]
+	switch {
+	case audio == nil:
+		return AudioSourceMissing, ""
+	case audio.File == vidispine.EmptyWAVFile:
+		return AudioSourceSilence, ""
+	case lang != "nor" && audio == clip.AudioFiles["nor"]:
+		return AudioSourceFallback, ""
+	case audio.VXID == "" || audio.VXID == clip.VXID:
+		return AudioSourceEmbedded, ""
+	default:
+		return AudioSourceRelated, audio.VXID
+	}
+}
+
+// planSubtitles lists the subtitles of each language. The AI generated ones are left out
+// unless they are allowed, as the VOD export leaves them out.
+func planSubtitles(clips []*vidispine.Clip, allowAI bool) []PlannedSubtitle {
+	var langs []string
+	for _, clip := range clips {
+		langs = append(langs, lo.Keys(clip.SubtitleFiles)...)
+	}
+	langs = lo.Uniq(langs)
+	sort.Strings(langs)
+
+	var subtitles []PlannedSubtitle
+	for _, lang := range langs {
+		if lang == "und" && !allowAI {
+			continue
+		}
+
+		planned := PlannedSubtitle{Language: lang, AI: lang == "und"}
+		for _, clip := range clips {
+			file := clip.SubtitleFiles[lang]
+			if file == vidispine.EmtpySRTFile {
+				file = ""
+			}
+			planned.Files = append(planned.Files, file)
+		}
+		subtitles = append(subtitles, planned)
+	}
+
+	return subtitles
+}
+
+// planVOD plans VXExportToVOD: a stream file per resolution with up to 8 languages each,
+// and a file per language for the resolutions that are files.
+func planVOD(planned *PlannedDestination, params VXExportParams, data *vidispine.ExportData, languagesByISO map[string]languages.Language, subtitles []PlannedSubtitle, audioLanguages []string, hasVideo, packageCMAF bool) {
+	if len(audioLanguages) == 0 && !hasVideo {
+		planned.Error = "no audio available to generate visualization video"
+		return
+	}
+
+	base := data.SafeTitle
+	if !hasVideo {
+		base = "viz_source"
+	}
+
+	for _, q := range assignLanguagesToResolutions(languagesByISO, audioLanguages, params.Resolutions) {
+		r := resolutionFromString(q.Resolution)
+		name := fmt.Sprintf("%s_%dx%d", base, r.Width, r.Height)
+
+		resolution := PlannedResolution{
+			Resolution: fmt.Sprintf("%dx%d", r.Width, r.Height),
+			File:       r.IsFile,
+			Languages:  lo.Map(q.Languages, func(l languages.Language, _ int) string { return l.ISO6391 }),
+		}
+		planned.Resolutions = append(planned.Resolutions, resolution)
+
+		planned.Files = append(planned.Files, name+".mp4")
+		if r.IsFile {
+			for _, lang := range audioLanguages {
+				planned.Files = append(planned.Files, name+"-"+lang+".mp4")
+			}
+		}
+	}
+
+	for _, subtitle := range subtitles {
+		planned.Files = append(planned.Files, data.SafeTitle+"-"+subtitle.Language+".srt")
+	}
+
+	planned.Files = append(planned.Files, "aws.smil", "ingest.json")
+	if params.WithChapters {
+		planned.Files = append(planned.Files, "chapters.json")
+	}
+	if packageCMAF {
+		planned.Files = append(planned.Files, "cmaf/")
+	}
+	if hasVideo {
+		planned.Files = append(planned.Files, "thumbnails/")
+	}
+}
+
+func planXDCAM(planned *PlannedDestination, data *vidispine.ExportData, hasVideo bool) {
+	if !hasVideo {
+		planned.Error = "XDCAM export needs a video file, but this item is audio-only"
+		return
+	}
+
+	planned.Resolutions = []PlannedResolution{{Resolution: utils.Resolution1080.FFMpegString(), File: true}}
+	planned.Files = []string{data.SafeTitle + ".mxf"}
+	planned.UploadTo = xdcamDeliveryFolder
+}
+
+// planBMM plans VXExportToBMM: the audio of each language as AAC and MP3.
+func planBMM(planned *PlannedDestination, dest AssetExportDestination, data *vidispine.ExportData, audioLanguages []string) {
+	for _, lang := range audioLanguages {
+		base := data.SafeTitle + "-" + lang
+		for _, bitrate := range aacBitrates {
+			planned.Files = append(planned.Files, base+"-"+bitrate+".aac")
+		}
+		for _, bitrate := range mp3Bitrates {
+			planned.Files = append(planned.Files, base+"-"+bitrate+".mp3")
+		}
+	}
+	planned.Files = append(planned.Files, "bmm.json")
+
+	planned.UploadTo = getBMMDestinationConfig(dest).Bucket + uploadFolder(data.SafeTitle, planRunID)
+}
@@ -0,0 +1,228 @@
+package export
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/bcc-code/bcc-media-flows/activities"
+	"github.com/bcc-code/bcc-media-flows/languages"
+	"github.com/bcc-code/bcc-media-flows/services/ffmpeg"
+	"github.com/bcc-code/bcc-media-flows/services/vidispine"
+	"github.com/bcc-code/bcc-media-flows/utils"
+	"github.com/bcc-code/bcc-media-flows/utils/testutils"
+	wfutils "github.com/bcc-code/bcc-media-flows/utils/workflows"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.temporal.io/sdk/activity"
+	"go.temporal.io/sdk/converter"
+	"go.temporal.io/sdk/testsuite"
+)
+
+// planTestData is two clips with related audio: English is missing from the second and
+// takes the Norwegian audio there, and German is missing from both. Only the first clip
+// has Norwegian subtitles.
+func planTestData() *vidispine.ExportData {
+	nor1 := &vidispine.AudioFile{VXID: "VX-1", File: "/mnt/isilon/clip1.mxf"}
+	nor2 := &vidispine.AudioFile{VXID: "VX-2", File: "/mnt/isilon/clip2.mxf"}
+
+	return &vidispine.ExportData{
+		Title:     "Test Export",
+		SafeTitle: "Test_Export",
+		Warnings:  []string{"a warning"},
+		Clips: []*vidispine.Clip{
+			{
+				VXID:        "VX-1",
+				VideoFile:   "/mnt/isilon/clip1.mxf",
+				InSeconds:   10,
+				OutSeconds:  70,
+				SequenceOut: 60,
+				AudioFiles: map[string]*vidispine.AudioFile{
+					"nor": nor1,
+					"eng": {VXID: "VX-11", File: "/mnt/isilon/clip1_eng.wav"},
+				},
+				SubtitleFiles: map[string]string{"nor": "/mnt/isilon/clip1_nor.srt"},
+			},
+			{
+				VXID:        "VX-2",
+				VideoFile:   "/mnt/isilon/clip2.mxf",
+				OutSeconds:  30,
+				SequenceIn:  60,
+				SequenceOut: 90,
+				AudioFiles: map[string]*vidispine.AudioFile{
+					"nor": nor2,
+					"eng": nor2,
+				},
+				SubtitleFiles: map[string]string{"nor": vidispine.EmtpySRTFile},
+			},
+		},
+	}
+}
+
+func TestPlanExport_Audio(t *testing.T) {
+	plan := planExport(VXExportParams{VXID: "VX-1", Languages: []string{"nor", "eng", "deu"}}, nil, planTestData(), languages.LanguagesByISO, true, time.Now())
+
+	assert.Equal(t, "00:01:30:00", plan.Duration)
+	require.Len(t, plan.Clips, 2)
+	assert.Equal(t, PlannedClip{
+		VXID:        "VX-2",
+		VideoFile:   "/mnt/isilon/clip2.mxf",
+		In:          "00:00:00:00",
+		Out:         "00:00:30:00",
+		SequenceIn:  "00:01:00:00",
+		SequenceOut: "00:01:30:00",
+	}, plan.Clips[1])
+
+	assert.Equal(t, []PlannedAudio{
+		{Language: "nor", Source: AudioSourceEmbedded, Clips: []string{"embedded", "embedded"}},
+		{Language: "eng", Source: AudioSourceMixed, Clips: []string{"related VX-11", "fallback to nor"}},
+		{Language: "deu", Source: AudioSourceMissing, Clips: []string{"missing", "missing"}},
+	}, plan.Audio)
+	assert.Equal(t, []string{"eng", "deu"}, plan.MissingLanguages)
+
+	assert.Equal(t, []PlannedSubtitle{
+		{Language: "nor", Files: []string{"/mnt/isilon/clip1_nor.srt", ""}},
+	}, plan.Subtitles)
+	assert.Equal(t, []string{"a warning"}, plan.Warnings)
+}
+
+func TestPlanExport_Destinations(t *testing.T) {
+	params := VXExportParams{
+		VXID:         "VX-1",
+		Languages:    []string{"nor", "eng", "deu"},
+		WithChapters: true,
+		Resolutions: []utils.Resolution{
+			{Width: 1920, Height: 1080},
+			{Width: 960, Height: 540, IsFile: true},
+		},
+	}
+	destinations := []*AssetExportDestination{
+		&AssetExportDestinationVOD,
+		&AssetExportDestinationIsilon,
+		&AssetExportDestinationXDCAM,
+		&AssetExportDestinationBMM,
+	}
+
+	plan := planExport(params, destinations, planTestData(), languages.LanguagesByISO, true, time.Now())
+
+	require.Len(t, plan.Destinations, 4)
+
+	vod := plan.Destinations[0]
+	assert.Equal(t, []PlannedResolution{
+		{Resolution: "960x540", File: true, Languages: []string{"nor", "eng"}},
+		{Resolution: "1920x1080", Languages: []string{}},
+	}, vod.Resolutions)
+	assert.Equal(t, []string{
+		"Test_Export_960x540.mp4",
+		"Test_Export_960x540-nor.mp4",
+		"Test_Export_960x540-eng.mp4",
+		"Test_Export_1920x1080.mp4",
+		"Test_Export-nor.srt",
+		"aws.smil",
+		"ingest.json",
+		"chapters.json",
+		"thumbnails/",
+	}, vod.Files)
+	assert.Equal(t, "s3prod:vod-asset-ingest-prod/Test_Export_<run ID>", vod.UploadTo)
+
+	assert.Equal(t, PlannedDestination{Destination: "isilon", CoveredBy: "vod"}, plan.Destinations[1])
+
+	xdcam := plan.Destinations[2]
+	assert.Equal(t, []string{"Test_Export.mxf"}, xdcam.Files)
+	assert.Equal(t, "brunstad:/Delivery/XDCAM", xdcam.UploadTo)
+
+	bmm := plan.Destinations[3]
+	assert.Equal(t, []string{
+		"Test_Export-nor-128k.aac", "Test_Export-nor-256k.aac", "Test_Export-nor-256k.mp3",
+		"Test_Export-eng-128k.aac", "Test_Export-eng-256k.aac", "Test_Export-eng-256k.mp3",
+		"bmm.json",
+	}, bmm.Files)
+	assert.Equal(t, "bmms3:/prod-bmm-mediabanken/Test_Export_<run ID>", bmm.UploadTo)
+}
+
+func TestPlanExport_AudioOnly(t *testing.T) {
+	destinations := []*AssetExportDestination{&AssetExportDestinationXDCAM, &AssetExportDestinationVOD, &AssetExportDestinationCMAF}
+	params := VXExportParams{Resolutions: []utils.Resolution{{Width: 1280, Height: 720}}}
+
+	plan := planExport(params, destinations, planTestData(), languages.LanguagesByISO, false, time.Now())
+
+	assert.Contains(t, plan.Destinations[0].Error, "audio-only")
+	assert.Equal(t, []string{"viz_source_1280x720.mp4", "Test_Export-nor.srt", "aws.smil", "ingest.json", "cmaf/"}, plan.Destinations[1].Files)
+}
+
+// A dry run reads the export data and probes the first clip, and does nothing else.
+func TestVXExport_DryRun(t *testing.T) {
+	suite := &testsuite.WorkflowTestSuite{}
+	env := suite.NewTestWorkflowEnvironment()
+
+	env.OnActivity(activities.Vidispine.GetExportDataActivity, mock.Anything, mock.Anything).
+		Return(planTestData(), nil)
+	env.OnActivity(activities.Audio.AnalyzeFile, mock.Anything, mock.Anything).
+		Return(&ffmpeg.StreamInfo{HasVideo: true}, nil)
+
+	var calls []string
+	env.SetOnActivityStartedListener(func(info *activity.Info, _ context.Context, _ converter.EncodedValues) {
+		calls = append(calls, info.ActivityType.Name)
+	})
+
+	env.ExecuteWorkflow(VXExport, VXExportParams{
+		VXID:         "VX-1",
+		Destinations: []string{"vod"},
+		Languages:    []string{"nor"},
+		DryRun:       true,
+	})
+	require.True(t, env.IsWorkflowCompleted())
+	require.NoError(t, env.GetWorkflowError())
+
+	var results []wfutils.ResultOrError[VXExportResult]
+	require.NoError(t, env.GetWorkflowResult(&results))
+	require.Len(t, results, 1)
+	require.NotNil(t, results[0].Result.Plan)
+	assert.Equal(t, "vod", results[0].Result.Plan.Destinations[0].Destination)
+	assert.Equal(t, []string{"GetExportDataActivity", "AnalyzeFile"}, calls)
+}
+
+// A dry run restores nothing, so it lists the archived originals and leaves the first
+// clip unprobed when it is one of them.
+func TestVXExport_DryRun_Archived(t *testing.T) {
+	testutils.WithColdArchive(t)
+	suite := &testsuite.WorkflowTestSuite{}
+	env := suite.NewTestWorkflowEnvironment()
+
+	data := planTestData()
+	data.Clips[0].VideoFile = "s3://cold-archive/clip1.mxf"
+	env.OnActivity(activities.Vidispine.GetExportDataActivity, mock.Anything, mock.Anything).
+		Return(data, nil)
+
+	var calls []string
+	env.SetOnActivityStartedListener(func(info *activity.Info, _ context.Context, _ converter.EncodedValues) {
+		calls = append(calls, info.ActivityType.Name)
+	})
+
+	env.ExecuteWorkflow(VXExport, VXExportParams{
+		VXID:         "VX-1",
+		Destinations: []string{"vod"},
+		Languages:    []string{"nor"},
+		DryRun:       true,
+	})
+	require.True(t, env.IsWorkflowCompleted())
+	require.NoError(t, env.GetWorkflowError())
+
+	var results []wfutils.ResultOrError[VXExportResult]
+	require.NoError(t, env.GetWorkflowResult(&results))
+	plan := results[0].Result.Plan
+	assert.Equal(t, []string{"VX-1"}, plan.NeedsRestore)
+	assert.True(t, plan.HasVideo)
+	assert.Contains(t, plan.Warnings, "The originals of VX-1 are in the cold archive and need a restore (up to 2 days) before the export.")
+	assert.Equal(t, []string{"GetExportDataActivity"}, calls)
+}
+
+// vod-cmaf is a package added to a VOD or Isilon export, and is not exported on its own.
+func TestValidateDestinations(t *testing.T) {
+	assert.Error(t, validateDestinations([]*AssetExportDestination{&AssetExportDestinationCMAF}))
+	assert.Error(t, validateDestinations([]*AssetExportDestination{&AssetExportDestinationXDCAM, &AssetExportDestinationCMAF}))
+	assert.NoError(t, validateDestinations([]*AssetExportDestination{&AssetExportDestinationVOD, &AssetExportDestinationCMAF}))
+	assert.NoError(t, validateDestinations([]*AssetExportDestination{&AssetExportDestinationIsilon, &AssetExportDestinationCMAF}))
+	assert.NoError(t, validateDestinations([]*AssetExportDestination{&AssetExportDestinationXDCAM}))
+}
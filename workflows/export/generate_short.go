@@ -49,7 +49,7 @@ func GenerateShort(ctx workflow.Context, params GenerateShortDataParams) (*Gener
 		return nil, err
 	}
 
-	outputPath, err := paths.Parse(params.OutputDirPath)
+	outputPath, err := wfutils.ParsePath(ctx, params.OutputDirPath)
 	if err != nil {
 		return nil, err
 	}
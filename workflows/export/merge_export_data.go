@@ -36,7 +36,14 @@ func MergeExportData(ctx workflow.Context, params MergeExportDataParams) (*Merge
 	logger.Info("Starting MergeExportData")
 	data := params.ExportData
 
-	dataMergeInputs := exportDataToMergeInputs(data, params.TempDir, params.SubtitlesDir)
+	// The files are resolved with the storage registry, which a replay might not share.
+	var dataMergeInputs MergeInput
+	err := workflow.SideEffect(ctx, func(workflow.Context) any {
+		return exportDataToMergeInputs(data, params.TempDir, params.SubtitlesDir)
+	}).Get(&dataMergeInputs)
+	if err != nil {
+		return nil, err
+	}
 
 	mergeInput := dataMergeInputs.MergeInput
 	audioMergeInputs := dataMergeInputs.AudioMergeInputs
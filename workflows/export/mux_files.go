@@ -12,15 +12,26 @@ import (
 	"github.com/bcc-code/bcc-media-flows/common/smil"
 	"github.com/bcc-code/bcc-media-flows/languages"
 	"github.com/bcc-code/bcc-media-flows/utils"
+	"github.com/samber/lo"
 	"go.temporal.io/sdk/workflow"
 )
 
 type quality string
 
-func getSubtitlesResult(ctx workflow.Context, subtitleFiles map[string]paths.Path) []smil.TextStream {
+// orderedLanguages looks the languages up in the table the workflow read, and sorts them
+// by their number.
+func orderedLanguages(languagesByISO map[string]languages.Language, keys []string) languages.LanguageList {
+	langs := languages.LanguageList(lo.Map(keys, func(key string, _ int) languages.Language {
+		return languagesByISO[key]
+	}))
+	sort.Sort(langs)
+	return langs
+}
+
+func getSubtitlesResult(ctx workflow.Context, languagesByISO map[string]languages.Language, subtitleFiles map[string]paths.Path) []smil.TextStream {
 	var subtitles []smil.TextStream
 	keys, _ := wfutils.GetMapKeysSafely(ctx, subtitleFiles)
-	subtitleLanguages := utils.LanguageKeysToOrderedLanguages(keys)
+	subtitleLanguages := orderedLanguages(languagesByISO, keys)
 	for _, language := range subtitleLanguages {
 		path := subtitleFiles[language.ISO6391]
 		subtitles = append(subtitles, smil.TextStream{
@@ -49,8 +60,8 @@ type ResolutionWithLanguages struct {
 	Languages  []languages.Language
 }
 
-func assignLanguagesToResolutions(audioKeys []string, resolutions []utils.Resolution) []ResolutionWithLanguages {
-	langs := utils.LanguageKeysToOrderedLanguages(audioKeys)
+func assignLanguagesToResolutions(languagesByISO map[string]languages.Language, audioKeys []string, resolutions []utils.Resolution) []ResolutionWithLanguages {
+	langs := orderedLanguages(languagesByISO, audioKeys)
 
 	sortedResolutions := sortResolutionsForVODStreaming(resolutions)
 
@@ -3,11 +3,12 @@ package export
 import (
 	"testing"
 
+	"github.com/bcc-code/bcc-media-flows/languages"
 	"github.com/bcc-code/bcc-media-flows/utils"
 )
 
 func Test_getQualitiesWithLanguages(t *testing.T) {
-	l := assignLanguagesToResolutions([]string{"en", "no"}, []utils.Resolution{
+	l := assignLanguagesToResolutions(languages.LanguagesByISO, []string{"en", "no"}, []utils.Resolution{
 		{
 			Width:  1920,
 			Height: 1080,
@@ -45,7 +46,7 @@ func Test_getQualitiesWithLanguages(t *testing.T) {
 }
 
 func Test_assignLanguagesToResolutions_noLanguages(t *testing.T) {
-	l := assignLanguagesToResolutions([]string{}, []utils.Resolution{
+	l := assignLanguagesToResolutions(languages.LanguagesByISO, []string{}, []utils.Resolution{
 		{Width: 1920, Height: 1080, IsFile: true},
 		{Width: 1280, Height: 720, IsFile: true},
 	})
@@ -62,7 +63,7 @@ func Test_assignLanguagesToResolutions_noLanguages(t *testing.T) {
 
 func Test_assignLanguagesToResolutions_manyLanguages(t *testing.T) {
 	langs := []string{"en", "no", "de", "fr", "es", "it", "ru", "sv", "da", "fi"}
-	l := assignLanguagesToResolutions(langs, []utils.Resolution{
+	l := assignLanguagesToResolutions(languages.LanguagesByISO, langs, []utils.Resolution{
 		{Width: 1920, Height: 1080, IsFile: true},
 		{Width: 1280, Height: 720, IsFile: true},
 	})
@@ -77,7 +77,7 @@ func ExportTimedMetadata(ctx workflow.Context, params ExportTimedMetadataParams)
 
 	// Copies created files and any remaining files needed.
 	s3Dir := fmt.Sprintf("timedmetadata/%s", params.VXID)
-	err = wfutils.RcloneCopyDir(ctx, outputDir.Rclone(), "s3prod:vod-asset-ingest-prod/"+s3Dir, rclone.PriorityNormal)
+	err = wfutils.RcloneCopyDir(ctx, outputDir.Rclone(), vodIngestBucket+s3Dir, rclone.PriorityNormal)
 	if err != nil {
 		return nil, err
 	}
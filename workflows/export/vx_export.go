@@ -3,6 +3,7 @@ package export
 import (
 	"fmt"
 	"github.com/bcc-code/bcc-media-flows/activities"
+	"slices"
 	"strings"
 
 	"github.com/bcc-code/bcc-media-flows/utils"
@@ -12,6 +13,7 @@ import (
 	"github.com/bcc-code/bcc-media-flows/services/telegram"
 	"github.com/bcc-code/bcc-media-flows/services/vidispine"
 	wfutils "github.com/bcc-code/bcc-media-flows/utils/workflows"
+	miscworkflows "github.com/bcc-code/bcc-media-flows/workflows/misc"
 	"github.com/orsinium-labs/enum"
 	"github.com/samber/lo"
 	"go.temporal.io/sdk/workflow"
@@ -25,12 +27,16 @@ var (
 	AssetExportDestinationBMM            = AssetExportDestination{Value: "bmm"}
 	AssetExportDestinationBMMIntegration = AssetExportDestination{Value: "bmm-integration"}
 	AssetExportDestinationIsilon         = AssetExportDestination{Value: "isilon"}
-	AssetExportDestinations              = enum.New(
+	// AssetExportDestinationCMAF adds an HLS/DASH package to the VOD or Isilon export,
+	// and is rejected without one of them.
+	AssetExportDestinationCMAF = AssetExportDestination{Value: "vod-cmaf"}
+	AssetExportDestinations    = enum.New(
 		AssetExportDestinationXDCAM,
 		AssetExportDestinationVOD,
 		AssetExportDestinationBMM,
 		AssetExportDestinationBMMIntegration,
 		AssetExportDestinationIsilon,
+		AssetExportDestinationCMAF,
 	)
 )
 
@@ -47,6 +53,13 @@ type VXExportParams struct {
 	Resolutions               []utils.Resolution
 	SubsAllowAI               bool
 	ForceReplaceTranscription bool
+	// ThumbnailInterval is the seconds between the scrub thumbnails of a VOD export.
+	// Zero means 10.
+	ThumbnailInterval int
+	// ThumbnailFormat is "jpg" or "webp" for the thumbnail sprite sheets. Empty means jpg.
+	ThumbnailFormat string
+	// DryRun returns the plan of the export instead of exporting.
+	DryRun bool
 }
 
 type VXExportResult struct {
@@ -55,6 +68,13 @@ type VXExportResult struct {
 	Duration     string `json:"duration"`
 	SmilFile     string `json:"smil_file"`
 	ChaptersFile string `json:"chapters_file"`
+	// HLSFile and DASHFile are set when the export was packaged as CMAF.
+	HLSFile  string `json:"hls_file,omitempty"`
+	DASHFile string `json:"dash_file,omitempty"`
+	// ThumbnailsFile is the WebVTT thumbnails track, set when the export has video.
+	ThumbnailsFile string `json:"thumbnails_file,omitempty"`
+	// Plan is set, and nothing else is, when the export was a dry run.
+	Plan *ExportPlan `json:"plan,omitempty"`
 }
 
 type VXExportChildWorkflowParams struct {
@@ -67,6 +87,8 @@ type VXExportChildWorkflowParams struct {
 	Upload                    bool
 	ExportDestination         AssetExportDestination
 	ForceReplaceTranscription bool
+	// PackageCMAF makes the VOD export write an HLS/DASH package next to the SMIL file.
+	PackageCMAF bool
 }
 
 // announceExportStarted reports the warnings the export data came with, then that the
@@ -92,6 +114,42 @@ func announceExportStarted(ctx workflow.Context, chat telegram.Chat, params VXEx
 			params.VXID, data.Title, destinations, runID))
 }
 
+// exportedAssets are the assets whose originals the export reads.
+func exportedAssets(data *vidispine.ExportData) []string {
+	var vxids []string
+	for _, clip := range data.Clips {
+		vxids = append(vxids, clip.VXID)
+		for _, audio := range clip.AudioFiles {
+			if audio != nil {
+				vxids = append(vxids, audio.VXID)
+			}
+		}
+	}
+	vxids = lo.Compact(lo.Uniq(vxids))
+	slices.Sort(vxids)
+	return vxids
+}
+
+// archivedOriginals are the assets whose originals the export reads from the cold
+// archive.
+func archivedOriginals(coldArchive *paths.Storage, data *vidispine.ExportData) []string {
+	var vxids []string
+	for _, clip := range data.Clips {
+		if miscworkflows.IsArchived(coldArchive, clip.VideoFile) {
+			vxids = append(vxids, clip.VXID)
+		}
+		for _, audio := range clip.AudioFiles {
+			if audio != nil && miscworkflows.IsArchived(coldArchive, audio.File) {
+				vxids = append(vxids, audio.VXID)
+			}
+		}
+	}
+	vxids = lo.Uniq(vxids)
+	// AudioFiles is a map, and the restores are started in this order.
+	slices.Sort(vxids)
+	return vxids
+}
+
 func createExportFolders(ctx workflow.Context) (tempDir, outputDir, subtitlesDir paths.Path, err error) {
 	tempDir, err = wfutils.GetWorkflowTempFolder(ctx)
 	if err != nil {
@@ -110,6 +168,72 @@ func createExportFolders(ctx workflow.Context) (tempDir, outputDir, subtitlesDir
 	return
 }
 
+// validateDestinations rejects vod-cmaf on its own, as it is a package added to the VOD
+// or Isilon export and not a delivery.
+func validateDestinations(destinations []*AssetExportDestination) error {
+	var cmaf, vod bool
+	for _, dest := range destinations {
+		switch *dest {
+		case AssetExportDestinationCMAF:
+			cmaf = true
+		case AssetExportDestinationVOD, AssetExportDestinationIsilon:
+			vod = true
+		}
+	}
+	if cmaf && !vod {
+		return fmt.Errorf("destination %s needs %s or %s", AssetExportDestinationCMAF.Value, AssetExportDestinationVOD.Value, AssetExportDestinationIsilon.Value)
+	}
+	return nil
+}
+
+// dryRunExport plans the export, which only needs to know if the item has video on top
+// of the export data. Nothing is restored, so the plan lists the archived originals, and
+// the first clip is only probed when it is not one of them.
+func dryRunExport(ctx workflow.Context, params VXExportParams, destinations []*AssetExportDestination, data *vidispine.ExportData) ([]wfutils.ResultOrError[VXExportResult], error) {
+	coldArchive, err := wfutils.ColdArchive(ctx)
+	if err != nil {
+		return nil, err
+	}
+	archived := archivedOriginals(coldArchive, data)
+
+	hasVideo := false
+	firstClipArchived := len(data.Clips) > 0 && miscworkflows.IsArchived(coldArchive, data.Clips[0].VideoFile)
+	if firstClipArchived {
+		// An audio-only item is rare, and there is no file to probe until the restore.
+		hasVideo = true
+	} else if len(data.Clips) > 0 {
+		firstClip, err := wfutils.ParsePath(ctx, data.Clips[0].VideoFile)
+		if err != nil {
+			return nil, err
+		}
+
+		fileInfo, err := wfutils.Execute(ctx, activities.Audio.AnalyzeFile, activities.AnalyzeFileParams{
+			FilePath: firstClip,
+		}).Result(ctx)
+		if err != nil {
+			return nil, err
+		}
+		hasVideo = fileInfo.HasVideo
+	}
+
+	languageList, err := wfutils.Languages(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	plan := planExport(params, destinations, data, languageList.ByISO6391(), hasVideo, workflow.Now(ctx))
+	planRestores(plan, archived, firstClipArchived)
+
+	return []wfutils.ResultOrError[VXExportResult]{{
+		Result: &VXExportResult{
+			ID:       params.VXID,
+			Title:    plan.Title,
+			Duration: plan.Duration,
+			Plan:     plan,
+		},
+	}}, nil
+}
+
 // VXExport is the main workflow for exporting assets from vidispine
 // It will create a child workflow for each destination
 func VXExport(ctx workflow.Context, params VXExportParams) ([]wfutils.ResultOrError[VXExportResult], error) {
@@ -132,19 +256,48 @@ func VXExport(ctx workflow.Context, params VXExportParams) ([]wfutils.ResultOrEr
 		}
 		destinations = append(destinations, d)
 	}
+	if err := validateDestinations(destinations); err != nil {
+		return nil, err
+	}
 
-	data, err := wfutils.Execute(ctx, avidispine.Vidispine.GetExportDataActivity, avidispine.GetExportDataParams{
+	exportDataParams := avidispine.GetExportDataParams{
 		VXID:        params.VXID,
 		Languages:   params.Languages,
 		AudioSource: params.AudioSource,
 		Subclip:     params.Subclip,
 		SubsAllowAI: params.SubsAllowAI,
-	}).Result(ctx)
+	}
+	data, err := wfutils.Execute(ctx, avidispine.Vidispine.GetExportDataActivity, exportDataParams).Result(ctx)
 	if err != nil {
 		return nil, err
 	}
 
+	if params.DryRun {
+		return dryRunExport(ctx, params, destinations, data)
+	}
+
 	announceExportStarted(ctx, telegramChat, params, data)
+	wfutils.MarkUsed(ctx, exportedAssets(data)...)
+
+	coldArchive, err := wfutils.ColdArchive(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if archived := archivedOriginals(coldArchive, data); len(archived) > 0 {
+		wfutils.SendTelegramText(ctx, telegramChat,
+			fmt.Sprintf("🟧 Export of `%s` is waiting for %d originals to be restored from the cold archive, which can take two days: `%s`",
+				params.VXID, len(archived), strings.Join(archived, ", ")))
+
+		err = miscworkflows.RestoreArchivedOriginals(ctx, archived)
+		if err != nil {
+			return nil, err
+		}
+
+		data, err = wfutils.Execute(ctx, avidispine.Vidispine.GetExportDataActivity, exportDataParams).Result(ctx)
+		if err != nil {
+			return nil, err
+		}
+	}
 
 	logger.Info("Retrieved data from vidispine")
 
@@ -153,7 +306,7 @@ func VXExport(ctx workflow.Context, params VXExportParams) ([]wfutils.ResultOrEr
 		return nil, err
 	}
 
-	firstClip, err := paths.Parse(data.Clips[0].VideoFile)
+	firstClip, err := wfutils.ParsePath(ctx, data.Clips[0].VideoFile)
 	if err != nil {
 		return nil, err
 	}
@@ -205,10 +358,14 @@ func VXExport(ctx workflow.Context, params VXExportParams) ([]wfutils.ResultOrEr
 			Upload:                    true,
 			ExportDestination:         *dest,
 			ForceReplaceTranscription: params.ForceReplaceTranscription,
+			PackageCMAF:               hasDestination(AssetExportDestinationCMAF),
 		}
 
 		var w interface{}
 		switch *dest {
+		case AssetExportDestinationCMAF:
+			// packaged by the VOD or Isilon subflow
+			continue
 		case AssetExportDestinationIsilon:
 			if hasDestination(AssetExportDestinationVOD) {
 				// this is just a subflow of VOD
@@ -117,7 +117,7 @@ func VXExportToBMM(ctx workflow.Context, params VXExportChildWorkflowParams) (*V
 
 	config := getBMMDestinationConfig(params.ExportDestination)
 
-	ingestFolder := params.ExportData.SafeTitle + "_" + workflow.GetInfo(ctx).OriginalRunID
+	ingestFolder := uploadFolder(params.ExportData.SafeTitle, workflow.GetInfo(ctx).OriginalRunID)
 	err = wfutils.RcloneCopyDir(ctx, params.OutputDir.Rclone(), config.Bucket+ingestFolder, rclone.PriorityNormal)
 	if err != nil {
 		return nil, err
@@ -261,7 +261,11 @@ func makeBMMJSON(
 	}
 	jsonData.TrackID = params.ExportData.BmmTrackID
 
-	jsonData.TranscriptionFiles = bmmTranscriptionFiles(ctx, params.MergeResult.JSONTranscript)
+	languageList, err := wfutils.Languages(ctx)
+	if err != nil {
+		return nil, err
+	}
+	jsonData.TranscriptionFiles = bmmTranscriptionFiles(ctx, params.MergeResult.JSONTranscript, languageList.ByISO6392TwoLetter())
 
 	if len(chapters) > 0 {
 		recordedBase := workflow.Now(ctx).Truncate(time.Hour * 6)
@@ -285,13 +289,13 @@ func makeBMMJSON(
 //
 // Nothing here fails the export. A missing transcription costs a transcription and
 // nothing else, and an episode without one is still an episode people can listen to.
-func bmmTranscriptionFiles(ctx workflow.Context, transcripts map[string]paths.Path) map[string]string {
+func bmmTranscriptionFiles(ctx workflow.Context, transcripts map[string]paths.Path, languagesByTwoLetter map[string]languages.Language) map[string]string {
 	langs, _ := wfutils.GetMapKeysSafely(ctx, transcripts)
 
 	files := map[string]string{}
 	for _, lang := range langs {
 		bmmLang := lang
-		if val, ok := languages.LanguagesByISOTwoLetter[lang]; ok {
+		if val, ok := languagesByTwoLetter[lang]; ok {
 			bmmLang = val.ISO6391
 		}
 
@@ -12,6 +12,9 @@ import (
 	"go.temporal.io/sdk/workflow"
 )
 
+// xdcamDeliveryFolder is where XDCAM exports are delivered for playout.
+const xdcamDeliveryFolder = "brunstad:/Delivery/XDCAM"
+
 func VXExportToXDCAM(ctx workflow.Context, params VXExportChildWorkflowParams) (*VXExportResult, error) {
 	logger := workflow.GetLogger(ctx)
 	logger.Info("Starting ExportToXDCAM")
@@ -58,8 +61,7 @@ func VXExportToXDCAM(ctx workflow.Context, params VXExportChildWorkflowParams) (
 		return nil, err
 	}
 
-	destination := "brunstad:/Delivery/XDCAM"
-	err = wfutils.RcloneCopyDir(ctx, params.OutputDir.Rclone(), destination, rclone.PriorityNormal)
+	err = wfutils.RcloneCopyDir(ctx, params.OutputDir.Rclone(), xdcamDeliveryFolder, rclone.PriorityNormal)
 	if err != nil {
 		return nil, err
 	}
@@ -42,7 +42,7 @@ func VXExportToVOD(ctx workflow.Context, params VXExportChildWorkflowParams) (*V
 		}).Future
 	}
 
-	err := copySubtitlesToOutput(ctx, params)
+	subtitleFiles, err := copySubtitlesToOutput(ctx, params)
 	if err != nil {
 		return nil, err
 	}
@@ -58,7 +58,10 @@ func VXExportToVOD(ctx workflow.Context, params VXExportChildWorkflowParams) (*V
 
 	var wm *paths.Path
 	if params.ParentParams.WatermarkPath != "" {
-		path := paths.MustParse(params.ParentParams.WatermarkPath)
+		path, err := wfutils.ParsePath(ctx, params.ParentParams.WatermarkPath)
+		if err != nil {
+			return nil, err
+		}
 		wm = &path
 	}
 
@@ -67,12 +70,32 @@ func VXExportToVOD(ctx workflow.Context, params VXExportChildWorkflowParams) (*V
 		return nil, err
 	}
 
+	// Scrub thumbnails of a rendered visualization would show nothing worth seeing.
+	var thumbnails wfutils.Task[*common.ThumbnailSpritesResult]
+	if primaryMediaType == "video" {
+		thumbnails = wfutils.Execute(ctx, activities.Video.CreateThumbnailSprites, common.ThumbnailSpritesInput{
+			FilePath:        baseVideo,
+			DestinationPath: params.OutputDir.Append("thumbnails"),
+			Interval:        params.ParentParams.ThumbnailInterval,
+			Format:          params.ParentParams.ThumbnailFormat,
+		})
+	}
+
+	languageList, err := wfutils.Languages(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	languagesByISO := languageList.ByISO6391()
+
 	service := &vxExportVodService{
-		ingestFolder:           params.ExportData.SafeTitle + "_" + params.RunID,
+		ingestFolder:           uploadFolder(params.ExportData.SafeTitle, params.RunID),
 		params:                 params,
 		fileFutures:            wfutils.NewFutureGroup(ctx),
-		qualitiesWithLanguages: assignLanguagesToResolutions(audioKeys, params.ParentParams.Resolutions),
+		qualitiesWithLanguages: assignLanguagesToResolutions(languagesByISO, audioKeys, params.ParentParams.Resolutions),
+		languagesByISO:         languagesByISO,
 		smilVideos:             make(map[resolutionString]smil.Video),
+		videoFiles:             make(map[resolutionString]paths.Path),
 	}
 
 	onVideoCreated := func(f workflow.Future, resolution utils.Resolution) {
@@ -91,6 +114,7 @@ func VXExportToVOD(ctx workflow.Context, params VXExportChildWorkflowParams) (*V
 			service.errs = append(service.errs, fmt.Errorf("failed to find language for resolution %v", resolution))
 			return
 		}
+		service.videoFiles[resolutionWithLanguages.Resolution] = result.OutputPath
 		languages := resolutionWithLanguages.Languages
 		future := createStreamFile(ctx, languages, result.OutputPath, params.OutputDir, audioFiles)
 		onFileCreated := func(f workflow.Future) {
@@ -131,20 +155,48 @@ func VXExportToVOD(ctx workflow.Context, params VXExportChildWorkflowParams) (*V
 		return nil, errors.Join(service.errs...)
 	}
 
+	var cmafResult *common.CMAFResult
+	if params.PackageCMAF {
+		cmafResult, err = wfutils.Execute(ctx, activities.Video.PackageCMAF, common.CMAFInput{
+			VideoFiles:        sortedVideoFiles(service.videoFiles, service.qualitiesWithLanguages),
+			AudioFilePaths:    audioFiles,
+			SubtitleFilePaths: subtitleFiles,
+			DestinationPath:   params.OutputDir.Append("cmaf"),
+		}).Result(ctx)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// The export is still playable without thumbnails, so it goes out without them.
+	var thumbnailsResult *common.ThumbnailSpritesResult
+	if thumbnails.Future != nil {
+		thumbnailsResult, err = thumbnails.Result(ctx)
+		if err != nil {
+			logger.Error("Failed to create thumbnail sprites", "error", err)
+			thumbnailsResult = nil
+		}
+	}
+
 	return service.setMetadataAndPublishToVOD(
 		ctx,
 		chapterDataWF,
 		params.OutputDir,
 		primaryMediaType,
+		cmafResult,
+		thumbnailsResult,
 	)
 }
 
-func copySubtitlesToOutput(ctx workflow.Context, params VXExportChildWorkflowParams) error {
+// copySubtitlesToOutput copies the subtitles this export includes to the output folder,
+// and returns them by language.
+func copySubtitlesToOutput(ctx workflow.Context, params VXExportChildWorkflowParams) (map[string]paths.Path, error) {
 	langs, err := wfutils.GetMapKeysSafely(ctx, params.MergeResult.SubtitleFiles)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
+	copied := map[string]paths.Path{}
 	for _, lang := range langs {
 		if lang == "und" && !params.ParentParams.SubsAllowAI {
 			// "und" is the AI generated one.
@@ -154,11 +206,12 @@ func copySubtitlesToOutput(ctx workflow.Context, params VXExportChildWorkflowPar
 		subtitle := params.MergeResult.SubtitleFiles[lang]
 		err = wfutils.CopyFile(ctx, subtitle, params.OutputDir.Append(subtitle.Base()))
 		if err != nil {
-			return err
+			return nil, err
 		}
+		copied[lang] = subtitle
 	}
 
-	return nil
+	return copied, nil
 }
 
 // baseVideoForVOD is the video every rendition is derived from, and what kind of media
@@ -280,19 +333,31 @@ func prepareAudioFiles(ctx workflow.Context, mergeResult MergeExportDataResult,
 	return audioFiles, nil
 }
 
+// vodIngestBucket is where VOD exports are uploaded, each to its uploadFolder, for the
+// VOD platform to ingest.
+const vodIngestBucket = "s3prod:vod-asset-ingest-prod/"
+
+// uploadFolder is the folder a VOD or BMM export is uploaded to in its bucket.
+func uploadFolder(safeTitle, runID string) string {
+	return safeTitle + "_" + runID
+}
+
 type vxExportVodService struct {
 	params                 VXExportChildWorkflowParams
 	ingestFolder           string
 	qualitiesWithLanguages []ResolutionWithLanguages
+	languagesByISO         map[string]languages.Language
 	// fileFutures tracks the stream and translated-file futures. onVideoCreated
 	// registers them itself and returns early when a transcode fails, so the count
 	// cannot be derived from the resolution and language lists — see
 	// wfutils.FutureGroup.
 	fileFutures *wfutils.FutureGroup
 	smilVideos  map[resolutionString]smil.Video
-	files       []asset.IngestFileMeta
-	tasks       []wfutils.Task[bool]
-	errs        []error
+	// videoFiles are the video-only renditions, which the CMAF package is built from.
+	videoFiles map[resolutionString]paths.Path
+	files      []asset.IngestFileMeta
+	tasks      []wfutils.Task[bool]
+	errs       []error
 }
 
 func (v *vxExportVodService) setMetadataAndPublishToVOD(
@@ -300,6 +365,8 @@ func (v *vxExportVodService) setMetadataAndPublishToVOD(
 	chapterDataWF workflow.Future,
 	outputDir paths.Path,
 	primaryMediaType string,
+	cmafResult *common.CMAFResult,
+	thumbnailsResult *common.ThumbnailSpritesResult,
 ) (*VXExportResult, error) {
 	ingestData := asset.IngestJSONMeta{
 		Title:            v.params.ExportData.SafeTitle,
@@ -314,7 +381,7 @@ func (v *vxExportVodService) setMetadataAndPublishToVOD(
 	smilData.Head.Meta.Content = "mp4"
 
 	smilData.Body.Switch.Videos = sortedVideos(v.smilVideos, v.qualitiesWithLanguages)
-	smilData.Body.Switch.TextStreams = getSubtitlesResult(ctx, v.params.MergeResult.SubtitleFiles)
+	smilData.Body.Switch.TextStreams = getSubtitlesResult(ctx, v.languagesByISO, v.params.MergeResult.SubtitleFiles)
 
 	xmlData, _ := wfutils.MarshalXml(ctx, smilData)
 	xmlData = append([]byte("<?xml version=\"1.0\" encoding=\"utf-8\" standalone=\"yes\"?>\n"), xmlData...)
@@ -353,7 +420,7 @@ func (v *vxExportVodService) setMetadataAndPublishToVOD(
 
 	if v.params.Upload {
 		// Copies created files and any remaining files needed.
-		err = wfutils.RcloneCopyDir(ctx, outputDir.Rclone(), fmt.Sprintf("s3prod:vod-asset-ingest-prod/%s", v.ingestFolder), rclone.PriorityNormal)
+		err = wfutils.RcloneCopyDir(ctx, outputDir.Rclone(), vodIngestBucket+v.ingestFolder, rclone.PriorityNormal)
 		if err != nil {
 			return nil, err
 		}
@@ -370,13 +437,21 @@ func (v *vxExportVodService) setMetadataAndPublishToVOD(
 	}
 
 	//err = DeletePath(ctx, tempFolder)
-	return &VXExportResult{
+	result := &VXExportResult{
 		ID:           v.params.ParentParams.VXID,
 		ChaptersFile: ingestData.ChaptersFile,
 		SmilFile:     ingestData.SmilFile,
 		Duration:     ingestData.Duration,
 		Title:        ingestData.Title,
-	}, nil
+	}
+	if cmafResult != nil {
+		result.HLSFile = filepath.Join("cmaf", cmafResult.HLSPlaylist.Base())
+		result.DASHFile = filepath.Join("cmaf", cmafResult.DASHManifest.Base())
+	}
+	if thumbnailsResult != nil {
+		result.ThumbnailsFile = filepath.Join("thumbnails", thumbnailsResult.VTTFile.Base())
+	}
+	return result, nil
 }
 
 func sortedVideos(streams map[resolutionString]smil.Video, qualities []ResolutionWithLanguages) []smil.Video {
@@ -387,6 +462,17 @@ func sortedVideos(streams map[resolutionString]smil.Video, qualities []Resolutio
 	return videos
 }
 
+// sortedVideoFiles is sortedVideos for the video-only renditions.
+func sortedVideoFiles(files map[resolutionString]paths.Path, qualities []ResolutionWithLanguages) []paths.Path {
+	var videos []paths.Path
+	for _, q := range qualities {
+		if file, ok := files[q.Resolution]; ok {
+			videos = append(videos, file)
+		}
+	}
+	return videos
+}
+
 func (v *vxExportVodService) handleFileWorkflowFuture(ctx workflow.Context, lang string, resolution utils.Resolution, f workflow.Future) {
 	logger := workflow.GetLogger(ctx)
 
@@ -396,7 +482,7 @@ func (v *vxExportVodService) handleFileWorkflowFuture(ctx workflow.Context, lang
 		v.errs = append(v.errs, err)
 		return
 	}
-	code := languages.LanguagesByISO[lang].ISO6392TwoLetter
+	code := v.languagesByISO[lang].ISO6392TwoLetter
 	if code == "" {
 		code = lang
 	}
@@ -1,7 +1,9 @@
 package export
 
 import (
+	"context"
 	"errors"
+	"fmt"
 	"testing"
 
 	"github.com/bcc-code/bcc-media-flows/activities"
@@ -74,6 +76,14 @@ func (s *VODExportTestSuite) mockSupportingActivities(env *testsuite.TestWorkflo
 
 	env.OnActivity(activities.Util.WriteFile, mock.Anything, mock.Anything).Return(nil, nil).Maybe()
 
+	env.OnActivity(activities.Video.CreateThumbnailSprites, mock.Anything, mock.Anything).Return(
+		func(_ context.Context, input common.ThumbnailSpritesInput) (*common.ThumbnailSpritesResult, error) {
+			return &common.ThumbnailSpritesResult{
+				Sprites: []paths.Path{input.DestinationPath.Append("thumbnails_001.jpg")},
+				VTTFile: input.DestinationPath.Append("thumbnails.vtt"),
+			}, nil
+		}).Maybe()
+
 	// notifyExportDone fires only on the success path, and swallows its own errors.
 	env.OnActivity(activities.Util.SendTelegramMessage, mock.Anything, mock.Anything).Return(
 		&telegram.Message{}, nil).Maybe()
@@ -141,6 +151,98 @@ func (s *VODExportTestSuite) Test_AllVideoTranscodesSucceed() {
 	s.Equal("aws.smil", result.SmilFile)
 }
 
+// With PackageCMAF the renditions are packaged once all of them exist, in the order the
+// master playlist lists them, and the result points at the manifests.
+func (s *VODExportTestSuite) Test_PackageCMAF() {
+	env := s.NewTestWorkflowEnvironment()
+	s.mockSupportingActivities(env)
+
+	env.OnActivity(activities.Video.TranscodeToVideoH264, mock.Anything, mock.Anything).Return(
+		func(_ context.Context, input common.VideoInput) (*common.VideoResult, error) {
+			return &common.VideoResult{OutputPath: testPath(fmt.Sprintf("video_%d.mp4", input.Resolution.Height))}, nil
+		})
+
+	var packaged common.CMAFInput
+	env.OnActivity(activities.Video.PackageCMAF, mock.Anything, mock.Anything).Return(
+		func(_ context.Context, input common.CMAFInput) (*common.CMAFResult, error) {
+			packaged = input
+			return &common.CMAFResult{
+				HLSPlaylist:  input.DestinationPath.Append("master.m3u8"),
+				DASHManifest: input.DestinationPath.Append("manifest.mpd"),
+			}, nil
+		}).Once()
+
+	params := vodTestParams()
+	params.PackageCMAF = true
+	env.ExecuteWorkflow(VXExportToVOD, params)
+
+	s.True(env.IsWorkflowCompleted())
+	s.NoError(env.GetWorkflowError())
+
+	s.Equal([]paths.Path{testPath("video_540.mp4"), testPath("video_1080.mp4")}, packaged.VideoFiles)
+	s.Equal(map[string]paths.Path{"nor": testPath("nor.aac")}, packaged.AudioFilePaths)
+	s.Equal(testPath("output/cmaf"), packaged.DestinationPath)
+
+	var result VXExportResult
+	s.NoError(env.GetWorkflowResult(&result))
+	s.Equal("cmaf/master.m3u8", result.HLSFile)
+	s.Equal("cmaf/manifest.mpd", result.DASHFile)
+}
+
+// The thumbnails are taken of the base video, and the result points at their track.
+func (s *VODExportTestSuite) Test_ThumbnailSprites() {
+	env := s.NewTestWorkflowEnvironment()
+
+	var sprites common.ThumbnailSpritesInput
+	env.OnActivity(activities.Video.CreateThumbnailSprites, mock.Anything, mock.Anything).Return(
+		func(_ context.Context, input common.ThumbnailSpritesInput) (*common.ThumbnailSpritesResult, error) {
+			sprites = input
+			return &common.ThumbnailSpritesResult{VTTFile: input.DestinationPath.Append("thumbnails.vtt")}, nil
+		}).Once()
+	s.mockSupportingActivities(env)
+
+	env.OnActivity(activities.Video.TranscodeToVideoH264, mock.Anything, mock.Anything).Return(
+		&common.VideoResult{OutputPath: testPath("video.mp4")}, nil)
+
+	params := vodTestParams()
+	params.ParentParams.ThumbnailInterval = 5
+	params.ParentParams.ThumbnailFormat = "webp"
+	env.ExecuteWorkflow(VXExportToVOD, params)
+
+	s.True(env.IsWorkflowCompleted())
+	s.NoError(env.GetWorkflowError())
+
+	s.Equal(testPath("source.mxf"), sprites.FilePath)
+	s.Equal(testPath("output/thumbnails"), sprites.DestinationPath)
+	s.Equal(5, sprites.Interval)
+	s.Equal("webp", sprites.Format)
+
+	var result VXExportResult
+	s.NoError(env.GetWorkflowResult(&result))
+	s.Equal("thumbnails/thumbnails.vtt", result.ThumbnailsFile)
+}
+
+// Failing thumbnails do not fail the export, which then has none.
+func (s *VODExportTestSuite) Test_ThumbnailSprites_FailureIsNotFatal() {
+	env := s.NewTestWorkflowEnvironment()
+
+	env.OnActivity(activities.Video.CreateThumbnailSprites, mock.Anything, mock.Anything).Return(
+		nil, errors.New("ffmpeg failed"))
+	s.mockSupportingActivities(env)
+
+	env.OnActivity(activities.Video.TranscodeToVideoH264, mock.Anything, mock.Anything).Return(
+		&common.VideoResult{OutputPath: testPath("video.mp4")}, nil)
+
+	env.ExecuteWorkflow(VXExportToVOD, vodTestParams())
+
+	s.True(env.IsWorkflowCompleted())
+	s.NoError(env.GetWorkflowError())
+
+	var result VXExportResult
+	s.NoError(env.GetWorkflowResult(&result))
+	s.Empty(result.ThumbnailsFile)
+}
+
 func TestVODExportTestSuite(t *testing.T) {
 	suite.Run(t, new(VODExportTestSuite))
 }
@@ -5,7 +5,6 @@ import (
 	"path/filepath"
 	"strings"
 
-	"github.com/bcc-code/bcc-media-flows/paths"
 	"github.com/bcc-code/bcc-media-flows/services/ingest"
 	wfutils "github.com/bcc-code/bcc-media-flows/utils/workflows"
 	"github.com/samber/lo"
@@ -37,7 +36,10 @@ func AssetJSON(ctx workflow.Context, params AssetJSONParams) (*AssetResult, erro
 	if !filepath.IsAbs(jsonPathStr) {
 		jsonPathStr = filepath.Join(sidecarStorageRoot, jsonPathStr)
 	}
-	jsonPath := paths.MustParse(jsonPathStr)
+	jsonPath, err := wfutils.ParsePath(ctx, jsonPathStr)
+	if err != nil {
+		return nil, err
+	}
 
 	form, err := wfutils.UnmarshalJSONFile[ingest.JSONForm](ctx, jsonPath)
 	if err != nil {
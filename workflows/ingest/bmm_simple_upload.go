@@ -33,7 +33,10 @@ type BmmSimpleUploadResult struct {
 func BmmIngestUpload(ctx workflow.Context, params BmmSimpleUploadParams) (*BmmSimpleUploadResult, error) {
 	workflow.GetLogger(ctx).Info("Starting BmmSimpleUpload")
 
-	path := paths.MustParse(params.FilePath)
+	path, err := wfutils.ParsePath(ctx, params.FilePath)
+	if err != nil {
+		return nil, err
+	}
 	ctx = workflow.WithActivityOptions(ctx, wfutils.GetDefaultActivityOptions())
 
 	workflow.GetLogger(ctx).Info("Uploading file to BMM", "path", path)
@@ -160,7 +160,7 @@ func getOrderFormFilename(orderForm OrderForm, file paths.Path, props ingest.Job
 	return "", errors.New("unsupported order form")
 }
 
-func notifyImportCompleted(ctx workflow.Context, recipients []string, jobID int, filesByAssetID map[string]paths.Path) error {
+func notifyImportCompleted(ctx workflow.Context, recipients []string, jobID int, filesByAssetID map[string]paths.Path, qcByAssetID map[string]string) error {
 	var content notifications.ImportCompleted
 	err := workflow.SideEffect(ctx, func(ctx workflow.Context) any {
 		return notifications.ImportCompleted{
@@ -170,6 +170,7 @@ func notifyImportCompleted(ctx workflow.Context, recipients []string, jobID int,
 				return notifications.File{
 					VXID: entry.Key,
 					Name: entry.Value.Base(),
+					QC:   qcByAssetID[entry.Key],
 				}
 			}),
 		}
@@ -0,0 +1,38 @@
+package ingestworkflows
+
+import (
+	"github.com/bcc-code/bcc-media-flows/activities"
+	"github.com/bcc-code/bcc-media-flows/paths"
+	"github.com/bcc-code/bcc-media-flows/services/fixity"
+	"github.com/bcc-code/bcc-media-flows/services/vidispine/vscommon"
+	wfutils "github.com/bcc-code/bcc-media-flows/utils/workflows"
+	"go.temporal.io/sdk/workflow"
+)
+
+// startFixity starts computing the checksums of an imported original. It runs
+// alongside the rest of the ingest, and finishFixity collects it.
+func startFixity(ctx workflow.Context, file paths.Path) wfutils.Task[*fixity.Checksums] {
+	return wfutils.Execute(ctx, activities.Util.ComputeChecksums, activities.FileInput{
+		Path: file,
+	})
+}
+
+// finishFixity waits for the checksums and stores them on the asset, where moves,
+// exports and the scheduled check of the archive verify the original against them.
+// Checksums that could not be computed are logged and not an error, as the file is
+// imported by then.
+func finishFixity(ctx workflow.Context, assetID string, task wfutils.Task[*fixity.Checksums]) {
+	sums, err := task.Result(ctx)
+	if err != nil {
+		workflow.GetLogger(ctx).Error("Checksums could not be computed", "assetID", assetID, "error", err)
+		return
+	}
+	storeFixity(ctx, assetID, *sums)
+}
+
+func storeFixity(ctx workflow.Context, assetID string, sums fixity.Checksums) {
+	err := wfutils.SetVidispineMeta(ctx, assetID, vscommon.FieldFixity.Value, sums.String())
+	if err != nil {
+		workflow.GetLogger(ctx).Error("Checksums could not be stored", "assetID", assetID, "error", err)
+	}
+}
@@ -13,7 +13,6 @@ import (
 	"github.com/bcc-code/bcc-media-flows/activities/cantemo"
 	vsactivity "github.com/bcc-code/bcc-media-flows/activities/vidispine"
 	"github.com/bcc-code/bcc-media-flows/common"
-	"github.com/bcc-code/bcc-media-flows/languages"
 	"github.com/bcc-code/bcc-media-flows/paths"
 	"github.com/bcc-code/bcc-media-flows/services/vidispine/vscommon"
 	wfutils "github.com/bcc-code/bcc-media-flows/utils/workflows"
@@ -39,6 +38,12 @@ func RelateAudioToVideo(ctx workflow.Context, params RelateAudioToVideoParams) e
 		return err
 	}
 
+	languageList, err := wfutils.Languages(ctx)
+	if err != nil {
+		return err
+	}
+	languagesByISO := languageList.ByISO6391()
+
 	for _, lang := range langs {
 		path := params.AudioList[lang]
 
@@ -97,7 +102,7 @@ func RelateAudioToVideo(ctx workflow.Context, params RelateAudioToVideoParams) e
 		err = wfutils.Execute(ctx, activities.Vidispine.SetVXMetadataFieldActivity, vsactivity.VXMetadataFieldParams{
 			ItemID:  params.VideoVXID,
 			GroupID: "System",
-			Key:     languages.LanguagesByISO[lang].RelatedMBFieldID,
+			Key:     languagesByISO[lang].RelatedMBFieldID,
 			Value:   assetResult.AssetID,
 		}).Wait(ctx)
 		if err != nil {
@@ -141,7 +146,10 @@ func ImportAudioFileFromReaper(ctx workflow.Context, params ImportAudioFileFromR
 }
 
 func doImportAudioFileFromReaper(ctx workflow.Context, params ImportAudioFileFromReaperParams) error {
-	inputFile := paths.MustParse(params.Path)
+	inputFile, err := wfutils.ParsePath(ctx, params.Path)
+	if err != nil {
+		return err
+	}
 
 	fileOK, err := wfutils.Execute(ctx, activities.Util.WaitForFile, activities.FileInput{
 		Path: inputFile,
@@ -176,8 +184,14 @@ func doImportAudioFileFromReaper(ctx workflow.Context, params ImportAudioFileFro
 		return err
 	}
 
+	languageList, err := wfutils.Languages(ctx)
+	if err != nil {
+		return err
+	}
+	lang := languageList.ByReaperChan()[reaperTrackNumber]
+
 	if isSilent {
-		wfutils.SendTelegramText(ctx, telegram.ChatOther, fmt.Sprintf("🟧 File %s is silent, skipping", languages.LanguagesByReaper[reaperTrackNumber].LanguageName))
+		wfutils.SendTelegramText(ctx, telegram.ChatOther, fmt.Sprintf("🟧 File %s is silent, skipping", lang.LanguageName))
 
 		// This is not a fail, so we should not send an error
 		return nil
@@ -193,8 +207,6 @@ func doImportAudioFileFromReaper(ctx workflow.Context, params ImportAudioFileFro
 		return err
 	}
 
-	lang := languages.LanguagesByReaper[reaperTrackNumber]
-
 	// Generate a filename with the language code
 	outPath := outputFolder.Append(fmt.Sprintf("%s-%s.wav", params.BaseName, strings.ToUpper(lang.ISO6391)))
 	err = wfutils.Execute(ctx, activities.Audio.AdjustAudioToVideoStart, activities.AdjustAudioToVideoStartInput{
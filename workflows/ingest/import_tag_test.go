@@ -0,0 +1,84 @@
+package ingestworkflows
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	vsactivity "github.com/bcc-code/bcc-media-flows/activities/vidispine"
+	"github.com/bcc-code/bcc-media-flows/paths"
+	"github.com/bcc-code/bcc-media-flows/services/vidispine/vsapi"
+	"github.com/bcc-code/bcc-media-flows/services/vidispine/vscommon"
+	"github.com/bcc-code/bcc-media-flows/services/vidispine/vsfake"
+	wfutils "github.com/bcc-code/bcc-media-flows/utils/workflows"
+	"github.com/stretchr/testify/suite"
+	"go.temporal.io/sdk/testsuite"
+	"go.temporal.io/sdk/workflow"
+)
+
+// ImportTagTestSuite runs the import of a file against a fake Vidispine through the
+// real Vidispine activities, so what the ingest leaves in Vidispine is checked and not
+// only which calls it makes.
+type ImportTagTestSuite struct {
+	suite.Suite
+	testsuite.WorkflowTestSuite
+
+	fake *vsfake.Server
+	env  *testsuite.TestWorkflowEnvironment
+}
+
+func (s *ImportTagTestSuite) SetupTest() {
+	s.fake = vsfake.New()
+	server := httptest.NewServer(s.fake)
+	s.T().Cleanup(server.Close)
+
+	s.env = s.NewTestWorkflowEnvironment()
+	s.env.RegisterActivity(&vsactivity.Activities{Client: vsapi.NewClient(vsfake.Config{URL: server.URL})})
+	s.env.RegisterWorkflow(importTagWorkflow)
+}
+
+// importTagWorkflow is how the ingests use ImportFileAsTag.
+func importTagWorkflow(ctx workflow.Context, path paths.Path) (*ImportTagResult, error) {
+	ctx = workflow.WithActivityOptions(ctx, wfutils.GetDefaultActivityOptions())
+
+	result, err := ImportFileAsTag(ctx, "original", path, "Konferanse 2024")
+	if err != nil {
+		return nil, err
+	}
+	return result, WaitForImportTag(ctx, result)
+}
+
+func importTagTestPath() paths.Path {
+	return paths.New(paths.IsilonDrive, "Production/Ingest/konferanse.mxf")
+}
+
+func (s *ImportTagTestSuite) Test_ImportsFileAsShape() {
+	s.env.ExecuteWorkflow(importTagWorkflow, importTagTestPath())
+	s.True(s.env.IsWorkflowCompleted())
+	s.NoError(s.env.GetWorkflowError())
+
+	var result ImportTagResult
+	s.NoError(s.env.GetWorkflowResult(&result))
+
+	s.Equal("Konferanse 2024", s.fake.Metadata(result.AssetID).Get(vscommon.FieldTitle, ""))
+	shape := s.fake.Shapes(result.AssetID).GetShape("original")
+	s.Require().NotNil(shape)
+	s.Equal(importTagTestPath().Local(), shape.GetPath())
+}
+
+// A failed import job is retried once with the shape replaced, not added to.
+func (s *ImportTagTestSuite) Test_FailedJobIsRetriedOnce() {
+	s.fake.SetNewJobStatus(vsfake.JobStatusFailed)
+
+	s.env.ExecuteWorkflow(importTagWorkflow, importTagTestPath())
+	s.True(s.env.IsWorkflowCompleted())
+	s.Error(s.env.GetWorkflowError())
+
+	jobs := s.fake.Jobs()
+	s.Len(jobs, 2)
+	assetID := s.fake.ItemIDs()[0]
+	s.Len(s.fake.Shapes(assetID).Shape, 1)
+}
+
+func Test_ImportTagTestSuite(t *testing.T) {
+	suite.Run(t, new(ImportTagTestSuite))
+}
@@ -3,7 +3,7 @@ package ingestworkflows
 import (
 	"errors"
 	"fmt"
-	"path/filepath"
+	"path"
 	"strings"
 	"time"
 
@@ -13,6 +13,7 @@ import (
 	"github.com/bcc-code/bcc-media-flows/activities"
 	vsactivity "github.com/bcc-code/bcc-media-flows/activities/vidispine"
 	"github.com/bcc-code/bcc-media-flows/paths"
+	"github.com/bcc-code/bcc-media-flows/services/fixity"
 	"github.com/bcc-code/bcc-media-flows/services/vidispine/vscommon"
 	wfutils "github.com/bcc-code/bcc-media-flows/utils/workflows"
 	"go.temporal.io/sdk/temporal"
@@ -28,18 +29,49 @@ type transferSample struct {
 type IncrementalParams struct {
 	Path            string
 	ReaperSessionID string
+	// Source is the room or recorder the file comes from. Live ingests from different
+	// sources run side by side, each with its own Reaper. Empty is the default source.
+	Source string
 }
 
 // Constants for workflow and signal
 const (
 	LiveIngestWorkflowID  = "LIVE-INGEST"
 	FileTransferredSignal = "file_transferred"
+	// LiveIngestStatusQuery answers with the LiveIngestStatus of a running ingest.
+	LiveIngestStatusQuery = "live_ingest_status"
 )
 
+// LiveIngestWorkflowIDFor is the workflow ID of the live ingest from a source. The ID
+// is what keeps a second ingest from the same source from starting.
+func LiveIngestWorkflowIDFor(source string) string {
+	if source == "" {
+		return LiveIngestWorkflowID
+	}
+	return LiveIngestWorkflowID + "-" + source
+}
+
+// Stages of a live ingest, as LiveIngestStatus reports them.
+const (
+	LiveIngestStageStarting  = "starting"
+	LiveIngestStageRecording = "recording"
+	LiveIngestStageImporting = "importing"
+)
+
+// LiveIngestStatus is what a running live ingest is doing.
+type LiveIngestStatus struct {
+	Source          string
+	Path            string
+	VXID            string
+	ReaperSessionID string
+	Stage           string
+}
+
 // Incremental is a workflow that ingests a growing file into Vidispine.
 // It also starts the Reaper recording.
 //
-// The workflow has a fixed ID "LIVE-INGEST" and listens for file transfer signals.
+// The workflow ID is "LIVE-INGEST", or "LIVE-INGEST-<source>" for an ingest from a
+// named source, and it listens for file transfer signals.
 // It will repeatedly attempt to copy files until it receives a signal that the file
 // has been completely transferred.
 //
@@ -52,19 +84,23 @@ const (
 // linked properly to the video file
 func Incremental(ctx workflow.Context, params IncrementalParams) error {
 	logger := workflow.GetLogger(ctx)
-	logger.Info("Starting Incremental with fixed ID: LIVE-INGEST")
+	logger.Info("Starting Incremental", "source", params.Source)
 
-	// Override the workflow ID to be LIVE-INGEST
+	// The ID is what keeps two ingests of a source apart, so one started with another
+	// would run next to the ingest it should have been refused for.
+	expectedID := LiveIngestWorkflowIDFor(params.Source)
 	info := workflow.GetInfo(ctx)
-	if info.WorkflowExecution.ID != LiveIngestWorkflowID {
-		logger.Warn(fmt.Sprintf("Workflow was started with ID %s instead of %s", info.WorkflowExecution.ID, LiveIngestWorkflowID))
+	if info.WorkflowExecution.ID != expectedID {
+		return temporal.NewNonRetryableApplicationError(
+			fmt.Sprintf("live ingest from %q must be started with ID %s, not %s", params.Source, expectedID, info.WorkflowExecution.ID),
+			"WRONG_WORKFLOW_ID", nil)
 	}
 
 	ctx = workflow.WithActivityOptions(ctx, wfutils.GetDefaultActivityOptions())
 
 	err := doIncremental(ctx, params)
 	if err != nil {
-		wfutils.SendTelegramText(ctx, telegram.ChatOther, fmt.Sprintf("🟥 Incremental ingest failed\n\n```%s```", err.Error()))
+		wfutils.SendTelegramText(ctx, telegram.ChatOther, fmt.Sprintf("🟥 Incremental ingest%s failed\n\n```%s```", sourceLabel(params.Source), err.Error()))
 		return err
 	}
 	return nil
@@ -74,7 +110,22 @@ func doIncremental(ctx workflow.Context, params IncrementalParams) error {
 	logger := workflow.GetLogger(ctx)
 	logger.Info("Starting doIncremental")
 
-	in := paths.MustParse(params.Path)
+	in, err := wfutils.ParsePath(ctx, params.Path)
+	if err != nil {
+		return err
+	}
+
+	status := &LiveIngestStatus{
+		Source: params.Source,
+		Path:   params.Path,
+		Stage:  LiveIngestStageStarting,
+	}
+	err = workflow.SetQueryHandler(ctx, LiveIngestStatusQuery, func() (LiveIngestStatus, error) {
+		return *status, nil
+	})
+	if err != nil {
+		return err
+	}
 
 	outDir, err := wfutils.GetWorkflowRawOutputFolder(ctx)
 	if err != nil {
@@ -86,18 +137,21 @@ func doIncremental(ctx workflow.Context, params IncrementalParams) error {
 	// Create a signal channel to listen for file transfer completions
 	signalChan := workflow.GetSignalChannel(ctx, FileTransferredSignal)
 
-	// Extract the base filename we're waiting for
-	expectedFilename := in.Base()
-	logger.Info(fmt.Sprintf("Waiting for signal with filename: %s", expectedFilename))
+	// The file we're waiting for, in the folder of its source
+	expectedFile := transferredFile(in, params.Source)
+	logger.Info(fmt.Sprintf("Waiting for signal with file: %s", expectedFile))
 
 	videoVXID, err := createGrowingPlaceholder(ctx, in)
 	if err != nil {
 		return err
 	}
 
-	reaperSessionID := startReaperSession(ctx, params.ReaperSessionID)
+	status.VXID = videoVXID
+
+	reaperSessionID := startReaperSession(ctx, params.Source, params.ReaperSessionID)
+	status.ReaperSessionID = reaperSessionID
 
-	wfutils.SendTelegramText(ctx, telegram.ChatOther, fmt.Sprintf("🟦 Starting live ingest: https://vault.bcc.media/item/%s", videoVXID))
+	wfutils.SendTelegramText(ctx, telegram.ChatOther, fmt.Sprintf("🟦 Starting live ingest%s: https://vault.bcc.media/item/%s", sourceLabel(params.Source), videoVXID))
 
 	jobResult, err := wfutils.Execute(ctx, activities.Vidispine.AddFileToPlaceholder, vsactivity.AddFileToPlaceholderParams{
 		AssetID:  videoVXID,
@@ -110,7 +164,9 @@ func doIncremental(ctx workflow.Context, params IncrementalParams) error {
 
 	previewPath, stopPreview := startGrowingPreview(ctx, rawPath, videoVXID)
 
-	copyUntilTransferred(ctx, in, rawPath, signalChan, expectedFilename)
+	status.Stage = LiveIngestStageRecording
+	sums := copyUntilTransferred(ctx, in, rawPath, signalChan, expectedFile)
+	status.Stage = LiveIngestStageImporting
 
 	wfutils.SendTelegramText(ctx, telegram.ChatOther, fmt.Sprintf("🟦 Video ingest ended: https://vault.bcc.media/item/%s\n\nImporting reaper files.", videoVXID))
 
@@ -118,7 +174,7 @@ func doIncremental(ctx workflow.Context, params IncrementalParams) error {
 
 	stopPreview()
 
-	reaperResult, err := listReaperFiles(ctx, reaperSessionID, videoVXID)
+	reaperResult, err := listReaperFiles(ctx, params.Source, reaperSessionID, videoVXID)
 	if err != nil {
 		return err
 	}
@@ -130,6 +186,11 @@ func doIncremental(ctx workflow.Context, params IncrementalParams) error {
 		return err
 	}
 
+	qcTask := startMediaQC(ctx, rawPath)
+	if sums != nil {
+		storeFixity(ctx, videoVXID, *sums)
+	}
+
 	baseName := strings.TrimSuffix(in.Base(), "_MU1.mxf")
 
 	ctx = wfutils.WithChildSearchAttributes(ctx, videoVXID)
@@ -191,6 +252,9 @@ func doIncremental(ctx workflow.Context, params IncrementalParams) error {
 		errs = append(errs, err)
 	}
 
+	qc := finishMediaQC(ctx, videoVXID, qcTask)
+	wfutils.SendTelegramText(ctx, telegram.ChatOther, fmt.Sprintf("🟦 Media QC for https://vault.bcc.media/item/%s\n\n%s", videoVXID, qc))
+
 	if len(errs) > 0 {
 		return fmt.Errorf("failed to import one or more audio files: %w", errors.Join(errs...))
 	}
@@ -246,17 +310,28 @@ func createGrowingPlaceholder(ctx workflow.Context, in paths.Path) (string, erro
 	return assetResult.AssetID, nil
 }
 
-// startReaperSession returns the session to take audio from. A failure to start one is
-// reported and not fatal: the video ingest is worth finishing without the audio.
-func startReaperSession(ctx workflow.Context, existing string) string {
+// sourceLabel names the source of a live ingest in its notifications, when it has one.
+func sourceLabel(source string) string {
+	if source == "" {
+		return ""
+	}
+	return " from " + source
+}
+
+// startReaperSession returns the session to take audio from, on the Reaper of the
+// source. A failure to start one is reported and not fatal: the video ingest is worth
+// finishing without the audio.
+func startReaperSession(ctx workflow.Context, source, existing string) string {
 	if existing != "" {
 		wfutils.SendTelegramText(ctx, telegram.ChatOther, fmt.Sprintf("🟦 ASSUMING REAPER SESSION: %s", existing))
 		return existing
 	}
 
-	sessionID, err := wfutils.Execute(ctx, activities.Live.StartReaper, nil).Result(ctx)
+	sessionID, err := wfutils.Execute(ctx, activities.Live.StartReaper, &activities.StartReaperParams{
+		Source: source,
+	}).Result(ctx)
 	if err != nil {
-		wfutils.SendTelegramText(ctx, telegram.ChatOther, fmt.Sprintf("🟦 Unable to start reaper. Start it manually and notify Matjaz!\n\n```%s```", err.Error()))
+		wfutils.SendTelegramText(ctx, telegram.ChatOther, fmt.Sprintf("🟦 Unable to start reaper%s. Start it manually and notify Matjaz!\n\n```%s```", sourceLabel(source), err.Error()))
 	}
 
 	return sessionID
@@ -332,8 +407,10 @@ func startGrowingPreview(ctx workflow.Context, rawPath paths.Path, videoVXID str
 
 // copyUntilTransferred copies the growing source until the watcher signals that the
 // transfer finished, then copies once more: the last copy ran before the signal, so
-// whatever was written in between is not here yet.
-func copyUntilTransferred(ctx workflow.Context, in, rawPath paths.Path, signalChan workflow.ReceiveChannel, expectedFilename string) {
+// whatever was written in between is not here yet. The last copy is verified against
+// the finished source, and its checksums are returned; nil when it was not made or
+// failed.
+func copyUntilTransferred(ctx workflow.Context, in, rawPath paths.Path, signalChan workflow.ReceiveChannel, expectedFile string) *fixity.Checksums {
 	logger := workflow.GetLogger(ctx)
 
 	samples := []transferSample{}
@@ -362,7 +439,7 @@ func copyUntilTransferred(ctx workflow.Context, in, rawPath paths.Path, signalCh
 		// Waiting on the signal and the timer together is what makes the signal
 		// act on arrival. A signal sent while the copy above was running is
 		// already queued on the channel, so this returns without sleeping at all.
-		signalReceived = waitForTransferSignal(ctx, signalChan, expectedFilename, copyRetryInterval)
+		signalReceived = waitForTransferSignal(ctx, signalChan, expectedFile, copyRetryInterval)
 
 		if signalReceived {
 			logger.Info("Received signal, breaking out of copy loop")
@@ -371,18 +448,22 @@ func copyUntilTransferred(ctx workflow.Context, in, rawPath paths.Path, signalCh
 	}
 
 	if !signalReceived {
-		return
+		return nil
 	}
 
 	logger.Info("Copying once more now that the source is complete")
-	if _, copyErr := wfutils.Execute(ctx, activities.Live.RsyncIncrementalCopy, activities.RsyncIncrementalCopyInput{
-		In:  in,
-		Out: rawPath,
-	}).Result(ctx); copyErr != nil {
+	result, copyErr := wfutils.Execute(ctx, activities.Live.RsyncIncrementalCopy, activities.RsyncIncrementalCopyInput{
+		In:     in,
+		Out:    rawPath,
+		Verify: true,
+	}).Result(ctx)
+	if copyErr != nil {
 		logger.Error("Final copy failed", "error", copyErr)
 		wfutils.SendTelegramText(ctx, telegram.ChatOther,
-			fmt.Sprintf("🟥 The final copy of %s failed, the ingested file may be short: %v", in.Base(), copyErr))
+			fmt.Sprintf("🟥 The final copy of %s failed, the ingested file may be short or damaged: %v", in.Base(), copyErr))
+		return nil
 	}
+	return result.Checksums
 }
 
 const (
@@ -402,7 +483,7 @@ type alertState struct {
 // nothing to list in that case: the reaper answers a request for a session it does not
 // have with an error, or worse, with somebody else's recording. The result is empty, so
 // no audio is imported and the audio/video sync that follows it is skipped too.
-func listReaperFiles(ctx workflow.Context, sessionID, videoVXID string) (*activities.ReaperResult, error) {
+func listReaperFiles(ctx workflow.Context, source, sessionID, videoVXID string) (*activities.ReaperResult, error) {
 	if sessionID == "" {
 		wfutils.SendTelegramText(ctx, telegram.ChatOther,
 			fmt.Sprintf("🟧 No reaper session for %s, skipping the audio import. The video is unaffected.", videoVXID))
@@ -411,6 +492,7 @@ func listReaperFiles(ctx workflow.Context, sessionID, videoVXID string) (*activi
 
 	result, err := wfutils.Execute(ctx, activities.Live.ListReaperFiles, &activities.ListReaperFilesParams{
 		SessionID: sessionID,
+		Source:    source,
 	}).Result(ctx)
 	if err != nil {
 		return nil, err
@@ -497,8 +579,30 @@ const (
 	copyRetryInterval = time.Minute
 )
 
+// transferredFile is what the transfer signal of an ingest ends with: the file, in the
+// folder of its source when it has one, so two rooms recording the same name are told
+// apart.
+func transferredFile(in paths.Path, source string) string {
+	if source == "" {
+		return in.Base()
+	}
+	return source + "/" + in.Base()
+}
+
+// isTransferredFile reports whether a transfer signal is for the expected file. The
+// signal is the path the file was transferred to; a bare filename, from before the
+// path was sent, is taken as the file.
+func isTransferredFile(signal, expectedFile string) bool {
+	signal = strings.ToLower(strings.ReplaceAll(signal, "\\", "/"))
+	expectedFile = strings.ToLower(expectedFile)
+	if !strings.Contains(signal, "/") {
+		return signal == path.Base(expectedFile)
+	}
+	return signal == expectedFile || strings.HasSuffix(signal, "/"+expectedFile)
+}
+
 // waitForTransferSignal waits up to interval for the transfer-complete signal
-// for expectedFilename, reporting whether it arrived.
+// for expectedFile, reporting whether it arrived.
 //
 // Signals for other files are consumed and ignored. It returns the moment the
 // right signal lands rather than at the end of the current copy-and-sleep
@@ -507,7 +611,7 @@ const (
 func waitForTransferSignal(
 	ctx workflow.Context,
 	signalChan workflow.ReceiveChannel,
-	expectedFilename string,
+	expectedFile string,
 	interval time.Duration,
 ) bool {
 	logger := workflow.GetLogger(ctx)
@@ -530,7 +634,7 @@ func waitForTransferSignal(
 
 		logger.Info(fmt.Sprintf("Received file transfer signal for: %s", signalFileName))
 
-		if strings.EqualFold(filepath.Base(signalFileName), expectedFilename) {
+		if isTransferredFile(signalFileName, expectedFile) {
 			logger.Info("Signal matches our file, marking as completed")
 			received = true
 			return
@@ -10,6 +10,7 @@ import (
 	miscworkflows "github.com/bcc-code/bcc-media-flows/workflows/misc"
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
+	"go.temporal.io/sdk/client"
 	"go.temporal.io/sdk/testsuite"
 )
 
@@ -59,6 +60,8 @@ func newIncrementalEnv(t *testing.T) *testsuite.TestWorkflowEnvironment {
 
 	env.OnActivity(activities.Video.TranscodeGrowingPreview, mock.Anything, mock.Anything).
 		Return(nil, nil).Maybe()
+	env.OnActivity(activities.Video.MediaQC, mock.Anything, mock.Anything).
+		Return(&ffmpeg.QCReport{}, nil).Maybe()
 
 	env.OnWorkflow(miscworkflows.TranscribeVX, mock.Anything, mock.Anything).Return(nil).Maybe()
 	env.OnWorkflow(miscworkflows.FixDurationVX, mock.Anything, mock.Anything).Return(nil).Maybe()
@@ -76,6 +79,7 @@ func newIncrementalEnv(t *testing.T) *testsuite.TestWorkflowEnvironment {
 func runIncremental(t *testing.T, env *testsuite.TestWorkflowEnvironment) {
 	t.Helper()
 
+	env.SetStartWorkflowOptions(client.StartWorkflowOptions{ID: LiveIngestWorkflowIDFor("")})
 	env.ExecuteWorkflow(Incremental, IncrementalParams{
 		Path:            "/mnt/filecatalyst/ingestgrow/TEST_MU1.mxf",
 		ReaperSessionID: "session-1",
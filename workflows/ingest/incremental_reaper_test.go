@@ -1,15 +1,21 @@
 package ingestworkflows
 
 import (
+	"context"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
+	"go.temporal.io/sdk/activity"
+	"go.temporal.io/sdk/client"
+	"go.temporal.io/sdk/converter"
 	"go.temporal.io/sdk/testsuite"
 	"go.temporal.io/sdk/workflow"
 
 	"github.com/bcc-code/bcc-media-flows/activities"
+	"github.com/bcc-code/bcc-media-flows/services/ffmpeg"
 	"github.com/bcc-code/bcc-media-flows/services/telegram"
 	wfutils "github.com/bcc-code/bcc-media-flows/utils/workflows"
 )
@@ -17,7 +23,7 @@ import (
 // listReaperFilesWorkflow drives the helper the way doIncremental does.
 func listReaperFilesWorkflow(ctx workflow.Context, sessionID string) (*activities.ReaperResult, error) {
 	ctx = workflow.WithActivityOptions(ctx, wfutils.GetDefaultActivityOptions())
-	return listReaperFiles(ctx, sessionID, "VX-1")
+	return listReaperFiles(ctx, "", sessionID, "VX-1")
 }
 
 func runListReaperFiles(t *testing.T, sessionID string, setup func(env *testsuite.TestWorkflowEnvironment)) (*activities.ReaperResult, error) {
@@ -87,3 +93,46 @@ func TestListReaperFiles_ListingFailureIsAnError(t *testing.T) {
 
 	require.Error(t, err)
 }
+
+// Two rooms record at once, so an ingest from a source records on that source's Reaper
+// and says what it is doing when asked.
+func TestIncremental_FromASource(t *testing.T) {
+	env := newIncrementalEnv(t)
+	env.OnActivity(activities.Audio.AnalyzeFile, mock.Anything, mock.Anything).
+		Return(&ffmpeg.StreamInfo{TotalSeconds: incrementalTestDurationSeconds}, nil)
+
+	var reaperSource string
+	env.SetOnActivityStartedListener(func(info *activity.Info, _ context.Context, args converter.EncodedValues) {
+		if info.ActivityType.Name == "StartReaper" {
+			var params *activities.StartReaperParams
+			require.NoError(t, args.Get(&params))
+			reaperSource = params.Source
+		}
+	})
+
+	var status LiveIngestStatus
+	env.RegisterDelayedCallback(func() {
+		value, err := env.QueryWorkflow(LiveIngestStatusQuery)
+		require.NoError(t, err)
+		require.NoError(t, value.Get(&status))
+	}, 500*time.Millisecond)
+
+	env.SetStartWorkflowOptions(client.StartWorkflowOptions{ID: LiveIngestWorkflowIDFor("room-b")})
+	env.ExecuteWorkflow(Incremental, IncrementalParams{
+		Path:   "/mnt/filecatalyst/ingestgrow/room-b/TEST_MU1.mxf",
+		Source: "room-b",
+	})
+	require.True(t, env.IsWorkflowCompleted())
+	require.NoError(t, env.GetWorkflowError())
+
+	assert.Equal(t, "room-b", reaperSource)
+	assert.Equal(t, LiveIngestStatus{
+		Source:          "room-b",
+		Path:            "/mnt/filecatalyst/ingestgrow/room-b/TEST_MU1.mxf",
+		VXID:            "VX-1",
+		ReaperSessionID: "session-1",
+		Stage:           LiveIngestStageRecording,
+	}, status)
+	assert.Equal(t, "LIVE-INGEST-room-b", LiveIngestWorkflowIDFor("room-b"))
+	assert.Equal(t, "LIVE-INGEST", LiveIngestWorkflowIDFor(""))
+}
@@ -90,3 +90,14 @@ func TestWaitForTransferSignalSeesASignalQueuedBeforeItStarts(t *testing.T) {
 	assert.True(t, res.Received)
 	assert.Zero(t, res.Waited)
 }
+
+// Two rooms can record files of the same name, so the folder of the source is part of
+// what the signal has to match.
+func TestIsTransferredFile(t *testing.T) {
+	assert.True(t, isTransferredFile("/mnt/filecatalyst/ingestgrow/room-b/X_MU1.mxf", "room-b/X_MU1.mxf"))
+	assert.True(t, isTransferredFile(`\Ingest\room-b\x_mu1.mxf`, "room-b/X_MU1.mxf"))
+	assert.False(t, isTransferredFile("/mnt/filecatalyst/ingestgrow/room-a/X_MU1.mxf", "room-b/X_MU1.mxf"))
+	assert.False(t, isTransferredFile("/mnt/filecatalyst/ingestgrow/room-b/Y_MU1.mxf", "room-b/X_MU1.mxf"))
+	assert.True(t, isTransferredFile("/mnt/filecatalyst/ingestgrow/X_MU1.mxf", "X_MU1.mxf"))
+	assert.True(t, isTransferredFile("X_MU1.mxf", "room-b/X_MU1.mxf"))
+}
@@ -14,6 +14,8 @@ import (
 	vsactivity "github.com/bcc-code/bcc-media-flows/activities/vidispine"
 	"github.com/bcc-code/bcc-media-flows/common"
 	"github.com/bcc-code/bcc-media-flows/paths"
+	"github.com/bcc-code/bcc-media-flows/services/ffmpeg"
+	"github.com/bcc-code/bcc-media-flows/services/fixity"
 	"github.com/bcc-code/bcc-media-flows/services/ingest"
 	"github.com/bcc-code/bcc-media-flows/services/vidispine/vscommon"
 	wfutils "github.com/bcc-code/bcc-media-flows/utils/workflows"
@@ -58,27 +60,35 @@ func Masters(ctx workflow.Context, params MasterParams) (*MasterResult, error) {
 	return result, nil
 }
 
-func processMaster(ctx workflow.Context, sourceFile paths.Path, destinationFile paths.Path, metadata *ingest.Metadata) (string, error) {
+// processMaster imports a master and returns its asset ID with its media QC and fixity,
+// which are still running, for the caller to collect once every master is imported.
+func processMaster(ctx workflow.Context, sourceFile paths.Path, destinationFile paths.Path, metadata *ingest.Metadata) (string, wfutils.Task[*ffmpeg.QCReport], wfutils.Task[*fixity.Checksums], error) {
+	var qcTask wfutils.Task[*ffmpeg.QCReport]
+	var fixityTask wfutils.Task[*fixity.Checksums]
+
 	err := wfutils.MoveFile(ctx, sourceFile, destinationFile, rclone.PriorityNormal)
 	if err != nil {
-		return "", err
+		return "", qcTask, fixityTask, err
 	}
 
 	result, err := ImportFileAsTag(ctx, "original", destinationFile, destinationFile.Base())
 	if err != nil {
-		return "", err
+		return "", qcTask, fixityTask, err
 	}
 
 	err = addMetaTags(ctx, result.AssetID, metadata)
 	if err != nil {
-		return "", err
+		return "", qcTask, fixityTask, err
 	}
 
 	err = WaitForImportTag(ctx, result)
 	if err != nil {
-		return "", err
+		return "", qcTask, fixityTask, err
 	}
 
+	qcTask = startMediaQC(ctx, destinationFile)
+	fixityTask = startFixity(ctx, destinationFile)
+
 	asyncCtx := wfutils.WithAbandonChildOptions(ctx)
 
 	// Trigger transcribe and create previews but don't wait for them to finish. We must still
@@ -89,7 +99,7 @@ func processMaster(ctx workflow.Context, sourceFile paths.Path, destinationFile
 		Language: "no",
 	})
 	if err = transcribeFuture.GetChildWorkflowExecution().Get(ctx, nil); err != nil {
-		return "", err
+		return "", qcTask, fixityTask, err
 	}
 
 	// This just triggers the task, the actual work is done in the background by Vidispine
@@ -98,10 +108,10 @@ func processMaster(ctx workflow.Context, sourceFile paths.Path, destinationFile
 	}).Wait(ctx)
 
 	if _, err := createPreviewsAsync(ctx, []string{result.AssetID}); err != nil {
-		return "", err
+		return "", qcTask, fixityTask, err
 	}
 
-	return result.AssetID, nil
+	return result.AssetID, qcTask, fixityTask, nil
 }
 
 func uploadMaster(ctx workflow.Context, params MasterParams) (*MasterResult, error) {
@@ -148,6 +158,8 @@ func uploadMaster(ctx workflow.Context, params MasterParams) (*MasterResult, err
 	}
 
 	importedVXs := map[string]paths.Path{}
+	qcTasks := map[string]wfutils.Task[*ffmpeg.QCReport]{}
+	fixityTasks := map[string]wfutils.Task[*fixity.Checksums]{}
 	var errs []error
 
 	for _, sourceFile := range sourceFiles {
@@ -157,7 +169,7 @@ func uploadMaster(ctx workflow.Context, params MasterParams) (*MasterResult, err
 			file = params.OutputDir.Append(filename)
 		}
 
-		result, err := processMaster(ctx, sourceFile, file, params.Metadata)
+		result, qcTask, fixityTask, err := processMaster(ctx, sourceFile, file, params.Metadata)
 
 		if err != nil {
 			errs = append(errs, err)
@@ -165,6 +177,21 @@ func uploadMaster(ctx workflow.Context, params MasterParams) (*MasterResult, err
 		}
 
 		importedVXs[result] = file
+		qcTasks[result] = qcTask
+		fixityTasks[result] = fixityTask
+	}
+
+	// The masters that were imported are QC'd and checked even when others failed.
+	assetIDs, err := wfutils.GetMapKeysSafely(ctx, importedVXs)
+	if err != nil {
+		return nil, err
+	}
+	qc := map[string]string{}
+	for _, id := range assetIDs {
+		qc[id] = finishMediaQC(ctx, id, qcTasks[id])
+	}
+	for _, id := range assetIDs {
+		finishFixity(ctx, id, fixityTasks[id])
 	}
 
 	if len(errs) > 0 {
@@ -172,7 +199,7 @@ func uploadMaster(ctx workflow.Context, params MasterParams) (*MasterResult, err
 	}
 
 	asyncCtx := wfutils.WithAbandonChildOptions(ctx)
-	err = notifyImportCompleted(asyncCtx, params.Targets, params.Metadata.JobProperty.JobID, importedVXs)
+	err = notifyImportCompleted(asyncCtx, params.Targets, params.Metadata.JobProperty.JobID, importedVXs, qc)
 	if err != nil {
 		return nil, err
 	}
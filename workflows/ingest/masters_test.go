@@ -7,6 +7,8 @@ import (
 	"github.com/bcc-code/bcc-media-flows/activities"
 	vsactivity "github.com/bcc-code/bcc-media-flows/activities/vidispine"
 	"github.com/bcc-code/bcc-media-flows/paths"
+	"github.com/bcc-code/bcc-media-flows/services/ffmpeg"
+	"github.com/bcc-code/bcc-media-flows/services/fixity"
 	"github.com/bcc-code/bcc-media-flows/services/ingest"
 	"github.com/bcc-code/bcc-media-flows/services/vidispine/vsapi"
 	"github.com/bcc-code/bcc-media-flows/utils/testutils"
@@ -66,6 +68,8 @@ func (s *UnitTestSuite) Test_VBBulk_MasterFlow() {
 		Profile:   "3",
 	})
 
+	bulkChecksums := fixity.Checksums{MD5: "5d41402abc4b2a76b9719d911017c592", XXHash: "26c7827d889f6da3", Size: 5}
+
 	bulkDir := paths.MustParse("./testdata/generated/VBBulk")
 	params := MasterParams{
 		OrderForm: OrderFormVBMasterBulk,
@@ -113,6 +117,12 @@ func (s *UnitTestSuite) Test_VBBulk_MasterFlow() {
 		{ItemID: "VBBulk2", Key: "portal_mf846642", Value: "6434"},
 		{ItemID: "VBBulk2", Key: "portal_mf189850", Value: "MUL"},
 		{ItemID: "VBBulk2", Key: "portal_mf426791", Value: "Testprosjekt"},
+
+		{ItemID: "VBBulk1", Key: "portal_media_qc", Value: "[]"},
+		{ItemID: "VBBulk2", Key: "portal_media_qc", Value: "[]"},
+
+		{ItemID: "VBBulk1", Key: "portal_fixity", Value: bulkChecksums.String()},
+		{ItemID: "VBBulk2", Key: "portal_fixity", Value: bulkChecksums.String()},
 	}
 
 	for _, field := range fileldsToSet {
@@ -121,6 +131,9 @@ func (s *UnitTestSuite) Test_VBBulk_MasterFlow() {
 
 	s.env.OnActivity(activities.Vidispine.JobCompleteOrErr, mock.Anything, mock.Anything).Times(2).Return(true, nil)
 
+	s.env.OnActivity(activities.Video.MediaQC, mock.Anything, mock.Anything).Times(2).Return(&ffmpeg.QCReport{}, nil)
+	s.env.OnActivity(activities.Util.ComputeChecksums, mock.Anything, mock.Anything).Times(2).Return(&bulkChecksums, nil)
+
 	s.env.OnWorkflow(miscworkflows.TranscribeVX, mock.Anything, miscworkflows.TranscribeVXInput{
 		VXID:     "VBBulk1",
 		Language: "no",
@@ -0,0 +1,66 @@
+package ingestworkflows
+
+import (
+	"encoding/json"
+	"strconv"
+
+	"github.com/bcc-code/bcc-media-flows/activities"
+	"github.com/bcc-code/bcc-media-flows/paths"
+	"github.com/bcc-code/bcc-media-flows/services/ffmpeg"
+	"github.com/bcc-code/bcc-media-flows/services/vidispine/vscommon"
+	wfutils "github.com/bcc-code/bcc-media-flows/utils/workflows"
+	"go.temporal.io/sdk/workflow"
+)
+
+// startMediaQC starts media QC of an imported file. It runs alongside the rest of the
+// ingest, and finishMediaQC collects it.
+func startMediaQC(ctx workflow.Context, file paths.Path) wfutils.Task[*ffmpeg.QCReport] {
+	return wfutils.Execute(ctx, activities.Video.MediaQC, activities.MediaQCParams{
+		FilePath: file,
+	})
+}
+
+// finishMediaQC waits for media QC and stores what it found on the asset: the issues,
+// timed, and the loudness of the first audio stream. It returns a summary for the
+// import notification. QC that fails is in the summary, and not an error, as the file
+// is imported by then.
+func finishMediaQC(ctx workflow.Context, assetID string, task wfutils.Task[*ffmpeg.QCReport]) string {
+	logger := workflow.GetLogger(ctx)
+
+	report, err := task.Result(ctx)
+	if err != nil {
+		logger.Error("Media QC failed", "assetID", assetID, "error", err)
+		return "QC failed: " + err.Error()
+	}
+
+	issues := report.Issues()
+	if issues == nil {
+		issues = []ffmpeg.QCIssue{}
+	}
+	issuesJSON, err := json.Marshal(issues)
+	if err != nil {
+		logger.Error("Media QC issues could not be stored", "assetID", assetID, "error", err)
+		return report.Summary()
+	}
+
+	fields := [][2]string{
+		{vscommon.FieldMediaQC.Value, string(issuesJSON)},
+	}
+	if len(report.Audio) > 0 {
+		audio := report.Audio[0]
+		fields = append(fields,
+			[2]string{vscommon.FieldLoudnessLUFS.Value, strconv.FormatFloat(audio.IntegratedLoudness, 'f', 2, 64)},
+			[2]string{vscommon.FieldTruePeak.Value, strconv.FormatFloat(audio.TruePeak, 'f', 2, 64)},
+			[2]string{vscommon.FieldLoudnessRange.Value, strconv.FormatFloat(audio.LoudnessRange, 'f', 2, 64)},
+		)
+	}
+
+	for _, f := range fields {
+		err = wfutils.SetVidispineMeta(ctx, assetID, f[0], f[1])
+		if err != nil {
+			logger.Error("Media QC could not be stored", "assetID", assetID, "field", f[0], "error", err)
+		}
+	}
+
+	return report.Summary()
+}
@@ -8,7 +8,6 @@ import (
 
 	"github.com/bcc-code/bcc-media-flows/activities"
 	vsactivity "github.com/bcc-code/bcc-media-flows/activities/vidispine"
-	"github.com/bcc-code/bcc-media-flows/languages"
 	"github.com/bcc-code/bcc-media-flows/paths"
 	wfutils "github.com/bcc-code/bcc-media-flows/utils/workflows"
 	"go.temporal.io/sdk/workflow"
@@ -93,6 +92,12 @@ func ExtractAudioFromMU1MU2(ctx workflow.Context, input ExtractAudioFromMU1MU2In
 		return err
 	}
 
+	languageList, err := wfutils.Languages(ctx)
+	if err != nil {
+		return err
+	}
+	languagesByMU1, languagesByMU2 := languageList.ByMU1(), languageList.ByMU2()
+
 	filesToImport := map[string]paths.Path{}
 	var tasks []wfutils.Waiter
 
@@ -114,7 +119,7 @@ func ExtractAudioFromMU1MU2(ctx workflow.Context, input ExtractAudioFromMU1MU2In
 			})
 
 			tasks = append(tasks, f)
-			filesToImport[languages.LanguagesByMU2[key].ISO6391] = outputFile
+			filesToImport[languagesByMU2[key].ISO6391] = outputFile
 		}
 	} else if sampleOffset > 0 {
 		for _, key := range keys {
@@ -128,7 +133,7 @@ func ExtractAudioFromMU1MU2(ctx workflow.Context, input ExtractAudioFromMU1MU2In
 			})
 
 			tasks = append(tasks, f)
-			filesToImport[languages.LanguagesByMU2[key].ISO6391] = outputFile
+			filesToImport[languagesByMU2[key].ISO6391] = outputFile
 		}
 	} else {
 		return errors.New("no offset - this is extremely unlikely to happen, please check the input files - STOPPING WORKFLOW")
@@ -148,7 +153,7 @@ func ExtractAudioFromMU1MU2(ctx workflow.Context, input ExtractAudioFromMU1MU2In
 			Destination: destinationFile,
 		})
 		tasks = append(tasks, f)
-		filesToImport[languages.LanguagesByMU1[i].ISO6391] = destinationFile
+		filesToImport[languagesByMU1[i].ISO6391] = destinationFile
 	}
 
 	var errs []error
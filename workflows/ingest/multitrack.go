@@ -93,6 +93,9 @@ func Multitrack(ctx workflow.Context, params MasterParams) (*MasterResult, error
 		return nil, err
 	}
 
+	qcTask := startMediaQC(ctx, muxResult.OutputPath)
+	fixityTask := startFixity(ctx, muxResult.OutputPath)
+
 	if _, err = createPreviewsAsync(ctx, []string{result.AssetID}); err != nil {
 		return nil, err
 	}
@@ -100,8 +103,12 @@ func Multitrack(ctx workflow.Context, params MasterParams) (*MasterResult, error
 	importedVXs := map[string]paths.Path{
 		result.AssetID: muxResult.OutputPath,
 	}
+	qc := map[string]string{
+		result.AssetID: finishMediaQC(ctx, result.AssetID, qcTask),
+	}
+	finishFixity(ctx, result.AssetID, fixityTask)
 
-	err = notifyImportCompleted(ctx, params.Targets, params.Metadata.JobProperty.JobID, importedVXs)
+	err = notifyImportCompleted(ctx, params.Targets, params.Metadata.JobProperty.JobID, importedVXs, qc)
 	if err != nil {
 		return nil, err
 	}
@@ -9,6 +9,7 @@ import (
 	vsactivity "github.com/bcc-code/bcc-media-flows/activities/vidispine"
 	"github.com/bcc-code/bcc-media-flows/paths"
 	"github.com/bcc-code/bcc-media-flows/services/ffmpeg"
+	"github.com/bcc-code/bcc-media-flows/services/fixity"
 	"github.com/bcc-code/bcc-media-flows/services/ingest"
 	"github.com/bcc-code/bcc-media-flows/utils"
 	wfutils "github.com/bcc-code/bcc-media-flows/utils/workflows"
@@ -38,7 +39,7 @@ func RawMaterialForm(ctx workflow.Context, params RawMaterialFormParams) error {
 		return err
 	}
 
-	fileByAssetID, err := RawMaterial(ctx, RawMaterialParams{
+	fileByAssetID, qc, err := rawMaterial(ctx, RawMaterialParams{
 		FilesToIngest:    originalFiles,
 		DeliveryMetadata: params.Metadata,
 		Language:         params.Metadata.JobProperty.Language,
@@ -48,7 +49,7 @@ func RawMaterialForm(ctx workflow.Context, params RawMaterialFormParams) error {
 		return err
 	}
 
-	err = notifyImportCompleted(ctx, params.Targets, params.Metadata.JobProperty.JobID, fileByAssetID)
+	err = notifyImportCompleted(ctx, params.Targets, params.Metadata.JobProperty.JobID, fileByAssetID, qc)
 	if err != nil {
 		return err
 	}
@@ -63,24 +64,31 @@ type RawMaterialParams struct {
 }
 
 func RawMaterial(ctx workflow.Context, params RawMaterialParams) (map[string]paths.Path, error) {
+	imported, _, err := rawMaterial(ctx, params)
+	return imported, err
+}
+
+// rawMaterial imports the files, and returns the media QC summary of each media asset
+// next to the imported files.
+func rawMaterial(ctx workflow.Context, params RawMaterialParams) (map[string]paths.Path, map[string]string, error) {
 	ctx = workflow.WithActivityOptions(ctx, wfutils.GetDefaultActivityOptions())
 
 	outputDir, err := wfutils.GetWorkflowRawOutputFolder(ctx)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
 	files := []paths.Path{}
 	for _, f := range params.FilesToIngest {
 		if !utils.ValidRawFilename(f.Local()) {
-			return nil, fmt.Errorf("invalid filename: %s", f)
+			return nil, nil, fmt.Errorf("invalid filename: %s", f)
 		}
 
 		newFileName := strings.ReplaceAll(f.Base(), " ", "_")
 		newPath := outputDir.Append(newFileName)
 		err = wfutils.MoveFile(ctx, f, newPath, rclone.PriorityNormal)
 		if err != nil {
-			return nil, err
+			return nil, nil, err
 		}
 
 		files = append(files, newPath)
@@ -89,13 +97,15 @@ func RawMaterial(ctx workflow.Context, params RawMaterialParams) (map[string]pat
 	var fileByAssetID = map[string]paths.Path{}
 	var mediaAnalyzeTasks = map[string]wfutils.Task[*ffmpeg.StreamInfo]{}
 	var importResults = map[string]*ImportTagResult{}
+	var qcTasks = map[string]wfutils.Task[*ffmpeg.QCReport]{}
+	var fixityTasks = map[string]wfutils.Task[*fixity.Checksums]{}
 
 	imported := map[string]paths.Path{}
 	for _, file := range files {
 		var result *ImportTagResult
 		result, err = ImportFileAsTag(ctx, "original", file, file.Base())
 		if err != nil {
-			return imported, err
+			return imported, nil, err
 		}
 
 		imported[result.AssetID] = file
@@ -103,23 +113,25 @@ func RawMaterial(ctx workflow.Context, params RawMaterialParams) (map[string]pat
 		if params.DeliveryMetadata != nil {
 			err = addMetaTags(ctx, result.AssetID, params.DeliveryMetadata)
 			if err != nil {
-				return imported, err
+				return imported, nil, err
 			}
 		}
 
 		fileByAssetID[result.AssetID] = file
 		importResults[result.AssetID] = result
+		fixityTasks[result.AssetID] = startFixity(ctx, file)
 
 		if utils.IsMedia(file.Local()) {
 			mediaAnalyzeTasks[result.AssetID] = wfutils.Execute(ctx, activities.Audio.AnalyzeFile, activities.AnalyzeFileParams{
 				FilePath: file,
 			})
+			qcTasks[result.AssetID] = startMediaQC(ctx, file)
 		}
 	}
 
 	mediaAssetIDs, err := wfutils.GetMapKeysSafely(ctx, mediaAnalyzeTasks)
 	if err != nil {
-		return imported, err
+		return imported, nil, err
 	}
 
 	audioAssetIDs := []string{}
@@ -129,13 +141,13 @@ func RawMaterial(ctx workflow.Context, params RawMaterialParams) (map[string]pat
 		task := mediaAnalyzeTasks[id]
 		result, err := task.Result(ctx)
 		if err != nil {
-			return imported, err
+			return imported, nil, err
 		}
 
 		// need to wait for vidispine to import the file before we can create thumbnails
 		err = WaitForImportTag(ctx, importResults[id])
 		if err != nil {
-			return imported, err
+			return imported, nil, err
 		}
 		// Only create thumbnails if the file has video
 		if result.HasVideo {
@@ -143,7 +155,7 @@ func RawMaterial(ctx workflow.Context, params RawMaterialParams) (map[string]pat
 				AssetID: id,
 			}).Wait(ctx)
 			if err != nil {
-				return imported, err
+				return imported, nil, err
 			}
 		}
 
@@ -160,9 +172,26 @@ func RawMaterial(ctx workflow.Context, params RawMaterialParams) (map[string]pat
 	}
 
 	if _, err = createPreviewsAsync(ctx, previewAssetIDs); err != nil {
-		return imported, err
+		return imported, nil, err
 	}
 
 	err = transcribe(ctx, audioAssetIDs, params.Language)
-	return imported, err
+	if err != nil {
+		return imported, nil, err
+	}
+
+	qc := map[string]string{}
+	for _, id := range mediaAssetIDs {
+		qc[id] = finishMediaQC(ctx, id, qcTasks[id])
+	}
+
+	assetIDs, err := wfutils.GetMapKeysSafely(ctx, fixityTasks)
+	if err != nil {
+		return imported, qc, err
+	}
+	for _, id := range assetIDs {
+		finishFixity(ctx, id, fixityTasks[id])
+	}
+
+	return imported, qc, nil
 }
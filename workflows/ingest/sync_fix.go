@@ -121,6 +121,10 @@ func IngestSyncFix(ctx workflow.Context, params IngestSyncFixParams) error {
 	return nil
 }
 
+// maxSyncDriftMs is how far the offset may drift through a file before the operator is
+// warned, a frame at 25fps.
+const maxSyncDriftMs = 40
+
 // calculateAudioAdjustment measures how far the reaper audio drifts from the audio in
 // the original video, so the copies can be shifted by it. A video with no audio to
 // compare against is not an error: the adjustment stays zero and the operator is told
@@ -144,7 +148,7 @@ func calculateAudioAdjustment(ctx workflow.Context, vxID string, audioPaths map[
 		return 0, errors.New("original shape has no audio")
 	}
 
-	originalPath, err := paths.Parse(originalShape.GetPath())
+	originalPath, err := wfutils.ParsePath(ctx, originalShape.GetPath())
 	if err != nil {
 		return 0, err
 	}
@@ -171,15 +175,19 @@ func calculateAudioAdjustment(ctx workflow.Context, vxID string, audioPaths map[
 		return 0, errors.New("nor audio not found")
 	}
 
-	diff, err := wfutils.Execute(ctx, activities.Util.GetAudioDiff, activities.GetAudioDiffParams{
-		ReferenceFile: prepareResult.OutputPath.Linux(),
-		TargetFile:    reaperAudio.Linux(),
+	diff, err := wfutils.Execute(ctx, activities.Audio.GetAudioDiff, activities.GetAudioDiffParams{
+		ReferenceFile: prepareResult.OutputPath.Local(),
+		TargetFile:    reaperAudio.Local(),
 	}).Result(ctx)
 	if err != nil {
 		return 0, err
 	}
 
-	wfutils.SendTelegramText(ctx, telegram.ChatVOD, fmt.Sprintf("🟦 `%s`\n\nAutomatic adjustment calculated: %dms", vxID, diff.Difference))
+	message := fmt.Sprintf("🟦 `%s`\n\nAutomatic adjustment calculated: %dms (confidence %.2f)", vxID, diff.Difference, diff.Confidence)
+	if diff.Drift > maxSyncDriftMs || diff.Drift < -maxSyncDriftMs {
+		message += fmt.Sprintf("\n\n🟧 The audio drifts %dms through the file, so a single adjustment will not line all of it up.", diff.Drift)
+	}
+	wfutils.SendTelegramText(ctx, telegram.ChatVOD, message)
 
 	return diff.Difference, nil
 }
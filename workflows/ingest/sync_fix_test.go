@@ -1,12 +1,14 @@
 package ingestworkflows
 
 import (
+	"context"
 	"errors"
 	"testing"
 
 	"github.com/bcc-code/bcc-media-flows/activities"
 	"github.com/bcc-code/bcc-media-flows/paths"
 	"github.com/bcc-code/bcc-media-flows/services/telegram"
+	"github.com/bcc-code/bcc-media-flows/services/vidispine/vsapi"
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/suite"
 	"go.temporal.io/sdk/testsuite"
@@ -24,8 +26,8 @@ func syncFixAudioPaths() map[string]paths.Path {
 	}
 }
 
-// mockCommon covers everything up to the copy loop. Adjustment is non-zero in
-// every test so the workflow skips the automatic-adjustment branch, which is a
+// mockCommon covers everything up to the copy loop. execute passes a non-zero
+// Adjustment so the workflow skips the automatic-adjustment branch, which is a
 // separate concern from the drain.
 func (s *SyncFixTestSuite) mockCommon(env *testsuite.TestWorkflowEnvironment) {
 	env.OnActivity(activities.Vidispine.GetRelatedAudioFiles, mock.Anything, mock.Anything).Return(
@@ -129,6 +131,51 @@ func (s *SyncFixTestSuite) Test_AllLanguagesFail_ReportsEveryCause() {
 	s.Contains(err.Error(), "eng copy failed")
 }
 
+// With no adjustment given, it is measured from the original audio against the reaper
+// recording and applied to every language.
+func (s *SyncFixTestSuite) Test_CalculatesAdjustment() {
+	env := s.NewTestWorkflowEnvironment()
+
+	env.OnActivity(activities.Vidispine.GetShapes, mock.Anything, mock.Anything).Return(
+		&vsapi.ShapeResult{Shape: []vsapi.Shape{{
+			Tag:                []string{"original"},
+			AudioComponent:     []vsapi.AudioComponent{{ChannelCount: 2}},
+			ContainerComponent: vsapi.ContainerComponent{File: []vsapi.File{{URI: []string{"file:///mnt/isilon/original.mxf"}}}},
+		}}}, nil)
+	prepared := paths.New(paths.TempDrive, "original.wav")
+	env.OnActivity(activities.Audio.PrepareForTranscription, mock.Anything, mock.Anything).Return(
+		&activities.PrepareTranscriptionResult{OutputPath: &prepared, HasAudio: true}, nil)
+
+	var measured activities.GetAudioDiffParams
+	env.OnActivity(activities.Audio.GetAudioDiff, mock.Anything, mock.Anything).Return(
+		func(_ context.Context, params activities.GetAudioDiffParams) (*activities.GetAudioDiffResult, error) {
+			measured = params
+			return &activities.GetAudioDiffResult{Difference: 120, Confidence: 0.9}, nil
+		}).Once()
+
+	var silences []activities.PrependSilenceInput
+	env.OnActivity(activities.Audio.PrependSilence, mock.Anything, mock.Anything).Return(
+		func(_ context.Context, input activities.PrependSilenceInput) (*activities.PrependSilenceOutput, error) {
+			silences = append(silences, input)
+			return &activities.PrependSilenceOutput{}, nil
+		})
+	s.mockCommon(env)
+
+	env.OnActivity(activities.Util.RcloneCopyFile, mock.Anything, mock.Anything).Return(1, nil)
+	env.OnActivity(activities.Util.RcloneWaitForJob, mock.Anything, mock.Anything).Return(true, nil)
+
+	env.ExecuteWorkflow(IngestSyncFix, IngestSyncFixParams{VXID: "VX-1"})
+	s.True(env.IsWorkflowCompleted())
+	s.NoError(env.GetWorkflowError())
+
+	s.Equal(prepared.Local(), measured.ReferenceFile)
+	s.Equal(syncFixAudioPaths()["nor"].Local(), measured.TargetFile)
+	s.Len(silences, 2)
+	for _, silence := range silences {
+		s.Equal(120*48, silence.Samples)
+	}
+}
+
 func TestSyncFixTestSuite(t *testing.T) {
 	suite.Run(t, new(SyncFixTestSuite))
 }
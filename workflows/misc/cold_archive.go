@@ -0,0 +1,236 @@
+package miscworkflows
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/bcc-code/bcc-media-flows/activities"
+	"github.com/bcc-code/bcc-media-flows/activities/cantemo"
+	vsactivity "github.com/bcc-code/bcc-media-flows/activities/vidispine"
+	"github.com/bcc-code/bcc-media-flows/paths"
+	"github.com/bcc-code/bcc-media-flows/services/vidispine/vsapi"
+	"github.com/bcc-code/bcc-media-flows/services/vidispine/vscommon"
+	wfutils "github.com/bcc-code/bcc-media-flows/utils/workflows"
+	"github.com/samber/lo"
+	"go.temporal.io/sdk/temporal"
+	"go.temporal.io/sdk/workflow"
+)
+
+// ArchiveRecord is where an original was before it was archived, stored as JSON in
+// portal_archived so it can be restored to the same place. The field is cleared when the
+// original is restored, so it is set on exactly the assets whose originals are archived.
+type ArchiveRecord struct {
+	// Storage is the Vidispine storage the original was on.
+	Storage string `json:"storage"`
+	// Path is the path of the original on the storage.
+	Path     string    `json:"path"`
+	Archived time.Time `json:"archived"`
+}
+
+// String is the record as it is stored in Vidispine.
+func (r ArchiveRecord) String() string {
+	data, _ := json.Marshal(r)
+	return string(data)
+}
+
+// ParseArchiveRecord reads a record as String writes it, and returns nil for an asset
+// that was never archived.
+func ParseArchiveRecord(value string) (*ArchiveRecord, error) {
+	if value == "" {
+		return nil, nil
+	}
+	var r ArchiveRecord
+	if err := json.Unmarshal([]byte(value), &r); err != nil {
+		return nil, fmt.Errorf("invalid archive record %q: %w", value, err)
+	}
+	return &r, nil
+}
+
+// IsArchived is whether a file, as Shape.GetPath returns it, is on the cold archive,
+// as wfutils.ColdArchive returns it. Without a cold archive nothing is.
+func IsArchived(coldArchive *paths.Storage, path string) bool {
+	return coldArchive != nil && strings.HasPrefix(path, coldArchive.Linux)
+}
+
+// GetArchiveRecord returns where the original of an asset was before it was archived,
+// or nil if it never was.
+func GetArchiveRecord(ctx workflow.Context, assetID string) (*ArchiveRecord, error) {
+	meta, err := wfutils.Execute(ctx, activities.Vidispine.GetVXMetadataFields, vsactivity.GetVXMetadataFieldsParams{
+		VXID:   assetID,
+		Fields: []vscommon.FieldType{vscommon.FieldArchived},
+	}).Result(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return ParseArchiveRecord(meta.Get(vscommon.FieldArchived, ""))
+}
+
+const (
+	// restorePollInterval is how often S3 is asked whether a restore is done. The
+	// cheapest restores take up to two days.
+	restorePollInterval = 30 * time.Minute
+	restoreTimeout      = 72 * time.Hour
+)
+
+type RestoreOriginalParams struct {
+	VXID string
+}
+
+// RestoreOriginal brings the original of an asset back from the cold archive to the
+// storage it was archived from, and checks it against its checksums there. It waits
+// for S3 to restore the file first, which takes hours. An original that is not archived
+// is left as it is.
+func RestoreOriginal(ctx workflow.Context, params RestoreOriginalParams) error {
+	logger := workflow.GetLogger(ctx)
+	logger.Info("Starting RestoreOriginal", "vxid", params.VXID)
+
+	ctx = workflow.WithActivityOptions(ctx, wfutils.GetDefaultActivityOptions())
+
+	shapes, err := wfutils.Execute(ctx, activities.Vidispine.GetShapes, vsactivity.VXOnlyParam{VXID: params.VXID}).Result(ctx)
+	if err != nil {
+		return err
+	}
+	shape := shapes.GetShape("original")
+	if shape == nil {
+		return fmt.Errorf("no original shape found for item %s", params.VXID)
+	}
+	coldArchive, err := wfutils.ColdArchive(ctx)
+	if err != nil {
+		return err
+	}
+	uri := shape.GetPath()
+	if !IsArchived(coldArchive, uri) {
+		logger.Info("Original is not archived", "vxid", params.VXID, "path", uri)
+		return nil
+	}
+
+	record, err := GetArchiveRecord(ctx, params.VXID)
+	if err != nil {
+		return err
+	}
+	if record == nil || record.Storage == "" {
+		return temporal.NewNonRetryableApplicationError(
+			fmt.Sprintf("original of %s is archived without a record of where it was", params.VXID),
+			"NO_ARCHIVE_RECORD", nil)
+	}
+
+	err = waitForColdRestore(ctx, uri)
+	if err != nil {
+		return err
+	}
+
+	sums, err := wfutils.GetFixity(ctx, params.VXID)
+	if err != nil {
+		return err
+	}
+
+	err = wfutils.Execute(ctx, activities.Cantemo.MoveFileWait, &cantemo.RenameFileParams{
+		ItemID:            params.VXID,
+		ShapeID:           shape.ID,
+		SourceStorage:     shapeStorage(shape),
+		DestinatinStorage: record.Storage,
+		NewPath:           record.Path,
+		Checksums:         sums,
+	}).Wait(ctx)
+	if err != nil {
+		return err
+	}
+
+	wfutils.MarkUsed(ctx, params.VXID)
+	return wfutils.SetVidispineMeta(ctx, params.VXID, vscommon.FieldArchived.Value, "")
+}
+
+// shapeStorage is the storage the file of a shape is on.
+func shapeStorage(shape *vsapi.Shape) string {
+	for _, f := range shape.ContainerComponent.File {
+		return f.Storage
+	}
+	return ""
+}
+
+// waitForColdRestore asks S3 to restore an archived file, and waits until it can be
+// read.
+func waitForColdRestore(ctx workflow.Context, uri string) error {
+	deadline := workflow.Now(ctx).Add(restoreTimeout)
+	for {
+		status, err := wfutils.Execute(ctx, activities.Util.StartColdRestore, activities.ColdObjectInput{URI: uri}).Result(ctx)
+		if err != nil {
+			return err
+		}
+		if status.Readable() {
+			return nil
+		}
+		if workflow.Now(ctx).After(deadline) {
+			return fmt.Errorf("%s was not restored in %s", uri, restoreTimeout)
+		}
+		err = workflow.Sleep(ctx, restorePollInterval)
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// RestoreArchivedOriginals restores the originals of the assets from the cold archive,
+// and returns once they are all back. An export that needs an archived original calls
+// it before it reads the file.
+//
+// Each asset is restored by one RestoreOriginal, whatever starts it: an export that
+// finds one running for its asset waits for the original to be back instead.
+func RestoreArchivedOriginals(ctx workflow.Context, vxids []string) error {
+	vxids = lo.Uniq(vxids)
+
+	var futures []workflow.ChildWorkflowFuture
+	for _, vxid := range vxids {
+		options := wfutils.GetVXDefaultWorkflowOptions(ctx, vxid)
+		options.WorkflowID = "restore-original-" + vxid
+		futures = append(futures, workflow.ExecuteChildWorkflow(workflow.WithChildOptions(ctx, options), RestoreOriginal, RestoreOriginalParams{VXID: vxid}))
+	}
+
+	var errs []error
+	for i, future := range futures {
+		err := future.Get(ctx, nil)
+		if temporal.IsWorkflowExecutionAlreadyStartedError(err) {
+			err = waitForRestoredOriginal(ctx, vxids[i])
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("restoring %s: %w", vxids[i], err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// waitForRestoredOriginal waits for a restore another workflow started, until the
+// original is off the cold archive.
+func waitForRestoredOriginal(ctx workflow.Context, vxid string) error {
+	ctx = workflow.WithActivityOptions(ctx, wfutils.GetDefaultActivityOptions())
+
+	coldArchive, err := wfutils.ColdArchive(ctx)
+	if err != nil {
+		return err
+	}
+
+	deadline := workflow.Now(ctx).Add(restoreTimeout)
+	for {
+		shapes, err := wfutils.Execute(ctx, activities.Vidispine.GetShapes, vsactivity.VXOnlyParam{VXID: vxid}).Result(ctx)
+		if err != nil {
+			return err
+		}
+		shape := shapes.GetShape("original")
+		if shape == nil {
+			return fmt.Errorf("no original shape found for item %s", vxid)
+		}
+		if !IsArchived(coldArchive, shape.GetPath()) {
+			return nil
+		}
+		if workflow.Now(ctx).After(deadline) {
+			return fmt.Errorf("original of %s was not restored in %s", vxid, restoreTimeout)
+		}
+		err = workflow.Sleep(ctx, restorePollInterval)
+		if err != nil {
+			return err
+		}
+	}
+}
@@ -0,0 +1,115 @@
+package miscworkflows
+
+import (
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/bcc-code/bcc-media-flows/activities"
+	"github.com/bcc-code/bcc-media-flows/activities/cantemo"
+	vsactivity "github.com/bcc-code/bcc-media-flows/activities/vidispine"
+	"github.com/bcc-code/bcc-media-flows/services/fixity"
+	"github.com/bcc-code/bcc-media-flows/services/s3"
+	"github.com/bcc-code/bcc-media-flows/services/vidispine/vsapi"
+	"github.com/bcc-code/bcc-media-flows/services/vidispine/vscommon"
+	"github.com/bcc-code/bcc-media-flows/services/vidispine/vsfake"
+	"github.com/bcc-code/bcc-media-flows/utils/testutils"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+	"go.temporal.io/sdk/testsuite"
+)
+
+type ColdArchiveTestSuite struct {
+	suite.Suite
+	testsuite.WorkflowTestSuite
+
+	env    *testsuite.TestWorkflowEnvironment
+	fake   *vsfake.Server
+	client *vsapi.Client
+}
+
+func (s *ColdArchiveTestSuite) SetupTest() {
+	s.env = s.NewTestWorkflowEnvironment()
+	testutils.WithColdArchive(s.T())
+
+	s.fake = vsfake.New()
+	s.fake.AddStorage("VX-56", "/mnt/isilon/Master/")
+	s.fake.AddStorage("VX-90", "s3://cold-archive/")
+
+	server := httptest.NewServer(s.fake)
+	s.T().Cleanup(server.Close)
+	s.client = vsapi.NewClient(vsfake.Config{URL: server.URL})
+	s.env.RegisterActivity(&vsactivity.Activities{Client: s.client})
+}
+
+func (s *ColdArchiveTestSuite) AfterTest(suiteName, testName string) {
+	s.env.AssertExpectations(s.T())
+}
+
+// addOriginal adds an item with its original on a storage.
+func (s *ColdArchiveTestSuite) addOriginal(storage, path string, meta map[string]string) (string, string) {
+	itemID := s.fake.AddItem(meta)
+	fileID := s.fake.AddFile(storage, path, vsapi.FileStateClosed)
+	s.fake.AddShape(itemID, vsapi.Shape{
+		Tag:                []string{"original"},
+		ContainerComponent: vsapi.ContainerComponent{File: []vsapi.File{*s.fake.File(fileID)}},
+	})
+	return itemID, fileID
+}
+
+func (s *ColdArchiveTestSuite) Test_RestoreOriginal() {
+	recorded := fixity.Checksums{MD5: "5d41402abc4b2a76b9719d911017c592", XXHash: "26c7827d889f6da3", Size: 5}
+	archived := ArchiveRecord{Storage: "VX-56", Path: "2020/01/01/A file.mxf", Archived: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)}
+	itemID, fileID := s.addOriginal("VX-90", "2020/01/01/A file.mxf", map[string]string{
+		vscommon.FieldFixity.Value:   recorded.String(),
+		vscommon.FieldArchived.Value: archived.String(),
+	})
+
+	// S3 takes two looks to restore it.
+	s.env.OnActivity(activities.Util.StartColdRestore, mock.Anything, activities.ColdObjectInput{URI: "s3://cold-archive/2020/01/01/A file.mxf"}).
+		Once().Return(&s3.ObjectStatus{StorageClass: "DEEP_ARCHIVE", Restoring: true}, nil)
+	s.env.OnActivity(activities.Util.StartColdRestore, mock.Anything, activities.ColdObjectInput{URI: "s3://cold-archive/2020/01/01/A file.mxf"}).
+		Once().Return(&s3.ObjectStatus{StorageClass: "DEEP_ARCHIVE", Restored: true}, nil)
+
+	s.env.OnActivity(activities.Cantemo.MoveFileWait, mock.Anything, mock.Anything).
+		Run(func(args mock.Arguments) {
+			params := args.Get(1).(*cantemo.RenameFileParams)
+			s.Equal("VX-90", params.SourceStorage)
+			s.Equal("VX-56", params.DestinatinStorage)
+			s.Equal("2020/01/01/A file.mxf", params.NewPath)
+			s.Equal(&recorded, params.Checksums)
+			_, err := s.client.MoveFile(fileID, params.DestinatinStorage, params.NewPath)
+			s.NoError(err)
+		}).Once().Return(nil, nil)
+
+	s.env.ExecuteWorkflow(RestoreOriginal, RestoreOriginalParams{VXID: itemID})
+	s.True(s.env.IsWorkflowCompleted())
+	s.NoError(s.env.GetWorkflowError())
+
+	s.Equal("/mnt/isilon/Master/2020/01/01/A file.mxf", s.fake.Shapes(itemID).GetShape("original").GetPath())
+
+	// Not archived any more, and used now, so ArchiveOriginals leaves it for a while.
+	meta := s.fake.Metadata(itemID)
+	s.Empty(meta.Get(vscommon.FieldArchived, ""))
+	s.Equal(s.env.Now().UTC().Format(vsactivity.SearchTime), meta.Get(vscommon.FieldLastUsed, ""))
+}
+
+func (s *ColdArchiveTestSuite) Test_RestoreOriginal_NotArchived() {
+	itemID, _ := s.addOriginal("VX-56", "2020/01/01/file.mxf", map[string]string{})
+
+	s.env.ExecuteWorkflow(RestoreOriginal, RestoreOriginalParams{VXID: itemID})
+	s.True(s.env.IsWorkflowCompleted())
+	s.NoError(s.env.GetWorkflowError())
+}
+
+func (s *ColdArchiveTestSuite) Test_RestoreOriginal_NoRecord() {
+	itemID, _ := s.addOriginal("VX-90", "2020/01/01/file.mxf", map[string]string{})
+
+	s.env.ExecuteWorkflow(RestoreOriginal, RestoreOriginalParams{VXID: itemID})
+	s.True(s.env.IsWorkflowCompleted())
+	s.ErrorContains(s.env.GetWorkflowError(), "without a record")
+}
+
+func TestColdArchiveTestSuite(t *testing.T) {
+	suite.Run(t, new(ColdArchiveTestSuite))
+}
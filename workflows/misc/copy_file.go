@@ -1,7 +1,6 @@
 package miscworkflows
 
 import (
-	"github.com/bcc-code/bcc-media-flows/paths"
 	wfutils "github.com/bcc-code/bcc-media-flows/utils/workflows"
 	"go.temporal.io/sdk/workflow"
 )
@@ -14,11 +13,11 @@ type CopyFileInput struct {
 func CopyFile(ctx workflow.Context, params CopyFileInput) error {
 	ctx = workflow.WithActivityOptions(ctx, wfutils.GetDefaultActivityOptions())
 
-	source, err := paths.Parse(params.Source)
+	source, err := wfutils.ParsePath(ctx, params.Source)
 	if err != nil {
 		return err
 	}
-	destination, err := paths.Parse(params.Destination)
+	destination, err := wfutils.ParsePath(ctx, params.Destination)
 	if err != nil {
 		return err
 	}
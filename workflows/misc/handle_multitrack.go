@@ -59,7 +59,10 @@ func HandleMultitrackFile(
 	options := wfutils.GetDefaultActivityOptions()
 	ctx = workflow.WithActivityOptions(ctx, options)
 
-	path := paths.MustParse(params.Path)
+	path, err := wfutils.ParsePath(ctx, params.Path)
+	if err != nil {
+		return err
+	}
 	lucidPath := makeLucidMultitrackPath(ctx, path)
 
 	jobID, err := wfutils.ExecuteWithLowPrioQueue(ctx, activities.Util.RcloneCopyFile, activities.RcloneFileInput{
@@ -1,10 +1,14 @@
 package miscworkflows
 
 import (
+	"fmt"
+
 	"github.com/bcc-code/bcc-media-flows/activities"
 	vsactivity "github.com/bcc-code/bcc-media-flows/activities/vidispine"
 	"github.com/bcc-code/bcc-media-flows/paths"
+	"github.com/bcc-code/bcc-media-flows/services/subtitles"
 	wfutils "github.com/bcc-code/bcc-media-flows/utils/workflows"
+	"go.temporal.io/sdk/temporal"
 	"go.temporal.io/sdk/workflow"
 )
 
@@ -21,15 +25,32 @@ type ImportSidecarSubtitleInput struct {
 // workflow that scheduled it completes, whereas a child workflow started through
 // wfutils.WithAbandonChildOptions survives the parent closing. TranscribeVX uses
 // it that way.
-func ImportSidecarSubtitle(ctx workflow.Context, params ImportSidecarSubtitleInput) error {
+//
+// The file is checked by subtitle QC first, and not imported when the policy of its
+// language blocks it.
+func ImportSidecarSubtitle(ctx workflow.Context, params ImportSidecarSubtitleInput) (*subtitles.Report, error) {
 	logger := workflow.GetLogger(ctx)
 	logger.Info("Starting ImportSidecarSubtitle", "vxid", params.VXID, "language", params.Language)
 
 	ctx = workflow.WithActivityOptions(ctx, wfutils.GetDefaultActivityOptions())
 
-	return wfutils.Execute(ctx, activities.Vidispine.ImportFileAsSidecarActivity, vsactivity.ImportSubtitleAsSidecarParams{
+	reports, err := validateSubtitles(ctx, params.VXID, map[string]paths.Path{params.Language: params.FilePath})
+	if err != nil {
+		return nil, err
+	}
+	report := reports[params.Language]
+	if report.Blocked {
+		msg := fmt.Sprintf("subtitles %s failed QC with %d errors", params.FilePath.Base(), report.Errors())
+		return nil, temporal.NewNonRetryableApplicationError(msg, "SUBTITLES_QC", nil, report)
+	}
+
+	err = wfutils.Execute(ctx, activities.Vidispine.ImportFileAsSidecarActivity, vsactivity.ImportSubtitleAsSidecarParams{
 		AssetID:  params.VXID,
 		FilePath: params.FilePath,
 		Language: params.Language,
 	}).Wait(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &report, nil
 }
@@ -7,6 +7,8 @@ import (
 	"github.com/bcc-code/bcc-media-flows/activities"
 	vsactivity "github.com/bcc-code/bcc-media-flows/activities/vidispine"
 	"github.com/bcc-code/bcc-media-flows/paths"
+	"github.com/bcc-code/bcc-media-flows/services/subtitles"
+	"github.com/bcc-code/bcc-media-flows/services/telegram"
 	wfutils "github.com/bcc-code/bcc-media-flows/utils/workflows"
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/suite"
@@ -24,6 +26,12 @@ func (s *ImportSidecarSubtitleTestSuite) Test_ImportsTheSubtitle() {
 
 	srtPath := paths.MustParse("/mnt/temp/workflows/transcript.srt")
 
+	env.OnActivity(activities.Util.ValidateSubtitles, mock.Anything, activities.ValidateSubtitlesInput{
+		File:     srtPath,
+		Language: "no",
+		VXID:     "VX-1",
+	}).Once().Return(&subtitles.Report{Language: "no", Cues: 10, Policy: subtitles.PolicyWarn}, nil)
+
 	var got vsactivity.ImportSubtitleAsSidecarParams
 	env.OnActivity(activities.Vidispine.ImportFileAsSidecarActivity, mock.Anything, mock.MatchedBy(
 		func(input vsactivity.ImportSubtitleAsSidecarParams) bool {
@@ -44,11 +52,51 @@ func (s *ImportSidecarSubtitleTestSuite) Test_ImportsTheSubtitle() {
 	s.Equal("VX-1", got.AssetID)
 	s.Equal(srtPath, got.FilePath)
 	s.Equal("no", got.Language)
+
+	var report subtitles.Report
+	s.NoError(env.GetWorkflowResult(&report))
+	s.Equal(10, report.Cues)
+}
+
+func (s *ImportSidecarSubtitleTestSuite) Test_DoesNotImportSubtitlesBlockedByQC() {
+	env := s.NewTestWorkflowEnvironment()
+
+	env.OnActivity(activities.Util.ValidateSubtitles, mock.Anything, mock.Anything).Return(&subtitles.Report{
+		Language: "no",
+		File:     "transcript.srt",
+		Cues:     10,
+		Policy:   subtitles.PolicyBlock,
+		Blocked:  true,
+		Issues: []subtitles.Issue{
+			{Rule: subtitles.RuleOverlap, Severity: subtitles.SeverityError, Cue: 4, Time: "00:00:12.000", Message: "starts before cue 3 ends"},
+		},
+	}, nil)
+	var posted []string
+	env.OnActivity(activities.Util.SendTelegramMessage, mock.Anything, mock.MatchedBy(func(msg *telegram.Message) bool {
+		posted = append(posted, msg.Markdown)
+		return true
+	})).Return(nil, nil)
+
+	env.ExecuteWorkflow(ImportSidecarSubtitle, ImportSidecarSubtitleInput{
+		VXID:     "VX-1",
+		FilePath: paths.MustParse("/mnt/temp/workflows/transcript.srt"),
+		Language: "no",
+	})
+
+	s.True(env.IsWorkflowCompleted())
+	err := env.GetWorkflowError()
+	s.Error(err)
+	s.Contains(err.Error(), "failed QC")
+	s.Require().Len(posted, 1, "the report is posted to the export chat")
+	s.Contains(posted[0], "overlap")
+	env.AssertNotCalled(s.T(), "ImportFileAsSidecarActivity", mock.Anything, mock.Anything)
 }
 
 func (s *ImportSidecarSubtitleTestSuite) Test_ReportsActivityFailure() {
 	env := s.NewTestWorkflowEnvironment()
 
+	env.OnActivity(activities.Util.ValidateSubtitles, mock.Anything, mock.Anything).
+		Return(&subtitles.Report{Language: "no", Cues: 10, Policy: subtitles.PolicyWarn}, nil)
 	env.OnActivity(activities.Vidispine.ImportFileAsSidecarActivity, mock.Anything, mock.Anything).
 		Return(nil, errors.New("vidispine rejected the sidecar"))
 
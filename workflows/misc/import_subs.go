@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"strings"
 
+	"github.com/bcc-code/bcc-media-flows/services/subtitles"
 	"github.com/bcc-code/bcc-media-flows/services/telegram"
 
 	vsactivity "github.com/bcc-code/bcc-media-flows/activities/vidispine"
@@ -17,10 +18,38 @@ type ImportSubtitlesFromSubtransInput struct {
 	VXID string
 }
 
+type ImportSubtitlesFromSubtransResult struct {
+	// Reports are the subtitle QC reports, by language. Languages whose report is
+	// blocked are not imported.
+	Reports map[string]subtitles.Report
+}
+
+// Blocked are the languages QC kept from being imported, sorted.
+func (r ImportSubtitlesFromSubtransResult) Blocked() []string {
+	var blocked []string
+	for _, lang := range wfutils.SortedKeys(r.Reports) {
+		if r.Reports[lang].Blocked {
+			blocked = append(blocked, lang)
+		}
+	}
+	return blocked
+}
+
+// finishedMessage is green when every language was imported, and lists the ones QC
+// blocked when not.
+func (r ImportSubtitlesFromSubtransResult) finishedMessage(vxID string) string {
+	blocked := r.Blocked()
+	if len(blocked) == 0 {
+		return "🟩 Sub import for VXID: " + vxID + " finished"
+	}
+	return fmt.Sprintf("🟧 Sub import for VXID: %s finished, but QC blocked %d of %d languages: %s",
+		vxID, len(blocked), len(r.Reports), strings.Join(blocked, ", "))
+}
+
 func ImportSubtitlesFromSubtrans(
 	ctx workflow.Context,
 	params ImportSubtitlesFromSubtransInput,
-) error {
+) (*ImportSubtitlesFromSubtransResult, error) {
 	logger := workflow.GetLogger(ctx)
 
 	options := wfutils.GetDefaultActivityOptions()
@@ -29,16 +58,16 @@ func ImportSubtitlesFromSubtrans(
 	logger.Info("Starting sub import flow")
 	wfutils.SendTelegramText(ctx, telegram.ChatOther, "🟦 Starting sub import for VXID: "+params.VXID)
 
-	err := doImportSubtitlesFromSubtrans(ctx, params)
+	result, err := doImportSubtitlesFromSubtrans(ctx, params)
 	if err != nil {
 		wfutils.SendTelegramText(ctx, telegram.ChatOther, fmt.Sprintf("🟥 Sub import for VXID: %s failed\n\n```%s```", params.VXID, err.Error()))
-		return err
+		return nil, err
 	}
-	wfutils.SendTelegramText(ctx, telegram.ChatOther, "🟩 Sub import for VXID: "+params.VXID+" finished")
-	return nil
+	wfutils.SendTelegramText(ctx, telegram.ChatOther, result.finishedMessage(params.VXID))
+	return result, nil
 }
 
-func doImportSubtitlesFromSubtrans(ctx workflow.Context, params ImportSubtitlesFromSubtransInput) error {
+func doImportSubtitlesFromSubtrans(ctx workflow.Context, params ImportSubtitlesFromSubtransInput) (*ImportSubtitlesFromSubtransResult, error) {
 	logger := workflow.GetLogger(ctx)
 
 	input := activities.GetSubtransIDInput{
@@ -48,7 +77,7 @@ func doImportSubtitlesFromSubtrans(ctx workflow.Context, params ImportSubtitlesF
 
 	subtransIDResponse, err := wfutils.Execute(ctx, activities.Util.GetSubtransIDActivity, input).Result(ctx)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	outputPath, _ := wfutils.GetWorkflowAuxOutputFolder(ctx)
@@ -61,16 +90,25 @@ func doImportSubtitlesFromSubtrans(ctx workflow.Context, params ImportSubtitlesF
 		//FilePrefix:        "subs_", <-- Generated by subtrans if empty
 	}).Result(ctx)
 	if err != nil {
-		return err
+		return nil, err
+	}
+
+	reports, err := validateSubtitles(ctx, params.VXID, subsList)
+	if err != nil {
+		return nil, err
 	}
 
 	var langs []string
 	subsKeys, err := wfutils.GetMapKeysSafely(ctx, subsList)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	for _, lang := range subsKeys {
+		if reports[lang].Blocked {
+			logger.Info("Subtitles blocked by QC", "lang", lang)
+			continue
+		}
 		sub := subsList[lang]
 		lang := strings.ToLower(lang)
 
@@ -82,7 +120,7 @@ func doImportSubtitlesFromSubtrans(ctx workflow.Context, params ImportSubtitlesF
 		}).Result(ctx)
 
 		if err != nil {
-			return err
+			return nil, err
 		}
 
 		if jobRes.JobID == "" {
@@ -105,6 +143,9 @@ func doImportSubtitlesFromSubtrans(ctx workflow.Context, params ImportSubtitlesF
 	)
 
 	for _, lang := range subsKeys {
+		if reports[lang].Blocked {
+			continue
+		}
 		sub := subsList[lang]
 		lang := strings.ToLower(lang)
 
@@ -115,7 +156,7 @@ func doImportSubtitlesFromSubtrans(ctx workflow.Context, params ImportSubtitlesF
 		}).Result(ctx)
 
 		if err != nil {
-			return err
+			return nil, err
 		}
 
 		if jobRes.JobID == "" {
@@ -129,5 +170,5 @@ func doImportSubtitlesFromSubtrans(ctx workflow.Context, params ImportSubtitlesF
 		}).Wait(ctx)
 	}
 
-	return nil
+	return &ImportSubtitlesFromSubtransResult{Reports: reports}, nil
 }
@@ -0,0 +1,21 @@
+package miscworkflows
+
+import (
+	"testing"
+
+	"github.com/bcc-code/bcc-media-flows/services/subtitles"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestImportSubtitlesFromSubtransResult_FinishedMessage(t *testing.T) {
+	result := ImportSubtitlesFromSubtransResult{Reports: map[string]subtitles.Report{
+		"nor": {},
+		"eng": {Blocked: true},
+		"deu": {Blocked: true},
+	}}
+	assert.Equal(t, []string{"deu", "eng"}, result.Blocked())
+	assert.Equal(t, "🟧 Sub import for VXID: VX-1 finished, but QC blocked 2 of 3 languages: deu, eng", result.finishedMessage("VX-1"))
+
+	result.Reports = map[string]subtitles.Report{"nor": {}}
+	assert.Equal(t, "🟩 Sub import for VXID: VX-1 finished", result.finishedMessage("VX-1"))
+}
@@ -87,7 +87,7 @@ func MASVImport(ctx workflow.Context, params MASVImportParams) error {
 		return err
 	}
 
-	srcFolder, err := paths.Parse(src)
+	srcFolder, err := wfutils.ParsePath(ctx, src)
 	if err != nil {
 		return err
 	}
@@ -102,7 +102,7 @@ func MASVImport(ctx workflow.Context, params MASVImportParams) error {
 		return err
 	}
 
-	outputDestination := paths.MustParse("/mnt/isilon/Input/FromMASV").Append(church).Append(params.ID)
+	outputDestination := paths.New(paths.IsilonDrive, "Input/FromMASV").Append(church).Append(params.ID)
 
 	transcodeJobs, err := deliverPackageFiles(ctx, filesToCopy, outputDestination)
 	if err != nil {
@@ -149,7 +149,7 @@ func waitForTransferManifest(ctx workflow.Context, srcFolder, tmpRoot paths.Path
 		return nil, fmt.Errorf("could not find metadata file for package %s", packageID)
 	}
 
-	remotePath, err := paths.Parse("s3prod:/" + manifest.Path)
+	remotePath, err := wfutils.ParsePath(ctx, "s3prod:/"+manifest.Path)
 	if err != nil {
 		return nil, err
 	}
@@ -181,7 +181,7 @@ func stagePackageFiles(ctx workflow.Context, masvMeta *MASVMetadata, tmpRoot pat
 	var staged []paths.Path
 
 	for _, f := range masvMeta.Package.Files {
-		remotePath, err := paths.Parse(fmt.Sprintf("s3prod:/massiveio-bccm/%s/%s", f.Path, f.Name))
+		remotePath, err := wfutils.ParsePath(ctx, fmt.Sprintf("s3prod:/massiveio-bccm/%s/%s", f.Path, f.Name))
 		if err != nil {
 			return nil, "", err
 		}
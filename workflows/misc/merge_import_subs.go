@@ -11,6 +11,7 @@ import (
 	vsactivity "github.com/bcc-code/bcc-media-flows/activities/vidispine"
 	"github.com/bcc-code/bcc-media-flows/common"
 	"github.com/bcc-code/bcc-media-flows/paths"
+	"github.com/bcc-code/bcc-media-flows/services/subtitles"
 	"github.com/bcc-code/bcc-media-flows/services/telegram"
 	wfutils "github.com/bcc-code/bcc-media-flows/utils/workflows"
 	"go.temporal.io/sdk/workflow"
@@ -51,7 +52,13 @@ func getSeparatorRune(s string) rune {
 	return ','
 }
 
-func MergeAndImportSubtitlesFromCSV(ctx workflow.Context, params MergeAndImportSubtitlesFromCSVParams) (bool, error) {
+type MergeAndImportSubtitlesFromCSVResult struct {
+	// Reports are the subtitle QC reports of the merged subtitles, by language.
+	// Languages whose report is blocked are not imported.
+	Reports map[string]subtitles.Report
+}
+
+func MergeAndImportSubtitlesFromCSV(ctx workflow.Context, params MergeAndImportSubtitlesFromCSVParams) (*MergeAndImportSubtitlesFromCSVResult, error) {
 
 	logger := workflow.GetLogger(ctx)
 
@@ -66,7 +73,7 @@ func MergeAndImportSubtitlesFromCSV(ctx workflow.Context, params MergeAndImportS
 
 	entries, err := parseSubMergeData([]byte(params.CSVData), getSeparatorRune(params.Separator))
 	if err != nil {
-		return false, err
+		return nil, err
 	}
 
 	mergeData := map[string]*common.MergeInput{}
@@ -74,7 +81,7 @@ func MergeAndImportSubtitlesFromCSV(ctx workflow.Context, params MergeAndImportS
 	for _, entry := range entries {
 		offset, err := convertCSVTimestamp(entry.TimecodeStr)
 		if err != nil {
-			return false, err
+			return nil, err
 		}
 
 		res, err := wfutils.Execute(ctx, activities.Util.GetSubtitlesActivity, activities.GetSubtitlesInput{
@@ -102,7 +109,7 @@ func MergeAndImportSubtitlesFromCSV(ctx workflow.Context, params MergeAndImportS
 		}
 
 		if err != nil {
-			return false, err
+			return nil, err
 		}
 	}
 
@@ -110,19 +117,28 @@ func MergeAndImportSubtitlesFromCSV(ctx workflow.Context, params MergeAndImportS
 
 	langs, err := wfutils.GetMapKeysSafely(ctx, mergeData)
 	if err != nil {
-		return false, err
+		return nil, err
 	}
 
 	for _, lang := range langs {
 		merge := mergeData[lang]
 		res, err := wfutils.Execute(ctx, activities.Audio.MergeSubtitlesByOffset, *merge).Result(ctx)
 		if err != nil {
-			return false, err
+			return nil, err
 		}
 		merged[lang] = res.Path
 	}
 
+	reports, err := validateSubtitles(ctx, params.TargetVXID, merged)
+	if err != nil {
+		return nil, err
+	}
+
 	for _, lang := range langs {
+		if reports[lang].Blocked {
+			logger.Info("Subtitles blocked by QC", "lang", lang)
+			continue
+		}
 		sub := merged[lang]
 		lang := strings.ToLower(lang)
 
@@ -134,7 +150,7 @@ func MergeAndImportSubtitlesFromCSV(ctx workflow.Context, params MergeAndImportS
 		}).Result(ctx)
 
 		if err != nil {
-			return false, err
+			return nil, err
 		}
 
 		if jobRes.JobID == "" {
@@ -152,7 +168,7 @@ func MergeAndImportSubtitlesFromCSV(ctx workflow.Context, params MergeAndImportS
 
 	wfutils.SendTelegramText(ctx, telegram.ChatOther, "🟩 CSV based sub merge and import for VXID: "+params.TargetVXID+" finished")
 
-	return true, nil
+	return &MergeAndImportSubtitlesFromCSVResult{Reports: reports}, nil
 }
 
 type SubtitleEntry struct {
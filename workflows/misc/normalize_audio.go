@@ -3,8 +3,6 @@ package miscworkflows
 import (
 	"math"
 
-	"github.com/bcc-code/bcc-media-flows/paths"
-
 	"github.com/bcc-code/bcc-media-flows/activities"
 	"github.com/bcc-code/bcc-media-flows/common"
 	wfutils "github.com/bcc-code/bcc-media-flows/utils/workflows"
@@ -33,7 +31,10 @@ func NormalizeAudioLevelWorkflow(
 	ctx = workflow.WithActivityOptions(ctx, wfutils.GetDefaultActivityOptions())
 	out := &NormalizeAudioResult{}
 
-	filePath := paths.MustParse(params.FilePath)
+	filePath, err := wfutils.ParsePath(ctx, params.FilePath)
+	if err != nil {
+		return nil, err
+	}
 
 	r128Result, err := wfutils.Execute(ctx, activities.Audio.AnalyzeEBUR128Activity, activities.AnalyzeEBUR128Params{
 		FilePath:       filePath,
@@ -75,83 +75,6 @@ const MoveMBFileSignalName = "move_mb_file"
 // completing. SignalWithStartWorkflow starts the flow again for the next one.
 const moveFilesIdleTimeout = 10 * time.Second
 
-type MBStorage struct {
-	VXID     string
-	BasePath string
-	Name     string
-}
-
-var Storages = []MBStorage{
-	{
-		VXID:     "VX-1",
-		BasePath: "/srv/media/media1/",
-		Name:     "media1",
-	},
-	{
-		VXID:     "VX-4",
-		BasePath: "/srv/space/Production/",
-		Name:     "space_production",
-	},
-	{
-		VXID:     "VX-5",
-		BasePath: "/srv/media/fcserver/Media/",
-		Name:     "fcs_media",
-	},
-	{
-		VXID:     "VX-6",
-		BasePath: "/srv/media/fcserver/masters/",
-		Name:     "fcs_masters",
-	},
-	{
-		VXID:     "VX-7",
-		BasePath: "/srv/media/fcserver/Roughcuts/",
-		Name:     "fcs_roughcuts",
-	},
-	{
-		VXID:     "VX-41",
-		BasePath: "/mnt/isilon/system/ingestgrow/",
-		Name:     "Isilon Ingestgrow",
-	},
-	{
-		VXID:     "VX-42",
-		BasePath: "/mnt/isilon/Production/",
-		Name:     "Isilon Production",
-	},
-	{
-		VXID:     "VX-47",
-		BasePath: "/mnt/isilon/system/tempingest/",
-		Name:     "Isilon Tempingest",
-	},
-	{
-		VXID:     "VX-56",
-		BasePath: "/mnt/isilon/Master/",
-		Name:     "Isilon Master",
-	},
-	{
-		VXID:     "VX-82",
-		BasePath: "/mnt/archive/",
-		Name:     "Archive",
-	},
-}
-
-func FindStorageForPath(path string) *MBStorage {
-	for _, s := range Storages {
-		if strings.HasPrefix(path, s.BasePath) {
-			return &s
-		}
-	}
-	return nil
-}
-
-func FindStorageForVXID(vxid string) *MBStorage {
-	for _, s := range Storages {
-		if s.VXID == vxid {
-			return &s
-		}
-	}
-	return nil
-}
-
 // MoveFilesWorkerFlow drains move requests signalled to the fixed
 // "move_mb_file" execution, and exits once none has arrived for
 // moveFilesIdleTimeout.
@@ -176,7 +99,11 @@ func MoveFilesWorkerFlow(ctx workflow.Context) error {
 			break
 		}
 
-		dstStorage := FindStorageForVXID(msg.DestinationStorage)
+		dstStorage, err := wfutils.VidispineStorage(ctx, msg.DestinationStorage)
+		if err != nil {
+			workflow.GetLogger(ctx).Error("Failed to look up destination storage", "error", err)
+			continue
+		}
 		if dstStorage == nil {
 			workflow.GetLogger(ctx).Error("Failed to find destination storage", "vxid", msg.DestinationStorage)
 			continue
@@ -198,19 +125,23 @@ func MoveFilesWorkerFlow(ctx workflow.Context) error {
 			}
 
 			shapePath := s.GetPath()
-			storage := FindStorageForPath(shapePath)
+			storage, err := wfutils.VidispineStorageForPath(ctx, shapePath)
+			if err != nil {
+				workflow.GetLogger(ctx).Error("Failed to look up storage for path, skipping", "path", shapePath, "error", err)
+				continue
+			}
 
 			if storage == nil {
 				workflow.GetLogger(ctx).Error("Failed to find storage for path, skipping", "path", shapePath, "vxid", msg.VXID)
 				continue
 			}
 
-			if storage.Name == "Archive" {
+			if storage.Archive {
 				workflow.GetLogger(ctx).Debug("Skipping archive path", "path", shapePath)
 				continue
 			}
 
-			trimmedName := strings.TrimPrefix(shapePath, storage.BasePath)
+			trimmedName := strings.TrimPrefix(shapePath, storage.Linux)
 
 			depth := strings.Count(trimmedName, "/")
 			if depth < 2 {
@@ -226,12 +157,20 @@ func MoveFilesWorkerFlow(ctx workflow.Context) error {
 			renameParams := cantemo.RenameFileParams{
 				ItemID:            msg.VXID,
 				ShapeID:           s.ID,
-				SourceStorage:     storage.VXID,
-				DestinatinStorage: dstStorage.VXID,
+				SourceStorage:     storage.VidispineID,
+				DestinatinStorage: dstStorage.VidispineID,
 				NewPath:           newPath,
 			}
 
-			err := wfutils.Execute(ctx, activities.Cantemo.MoveFileWait, &renameParams).Wait(ctx)
+			// Only the original is recorded with checksums at ingest.
+			if shapeTag == "original" {
+				renameParams.Checksums, err = wfutils.GetFixity(ctx, msg.VXID)
+				if err != nil {
+					workflow.GetLogger(ctx).Error("Failed to get checksums, moving without verifying", "error", err, "vxid", msg.VXID)
+				}
+			}
+
+			err = wfutils.Execute(ctx, activities.Cantemo.MoveFileWait, &renameParams).Wait(ctx)
 			if err != nil {
 				workflow.GetLogger(ctx).Error("Failed to rename file", "error", err)
 				continue
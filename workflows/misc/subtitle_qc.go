@@ -0,0 +1,39 @@
+package miscworkflows
+
+import (
+	"fmt"
+
+	"github.com/bcc-code/bcc-media-flows/paths"
+	"github.com/bcc-code/bcc-media-flows/services/subtitles"
+	"github.com/bcc-code/bcc-media-flows/services/telegram"
+	wfutils "github.com/bcc-code/bcc-media-flows/utils/workflows"
+	"go.temporal.io/sdk/workflow"
+)
+
+// validateSubtitles checks the subtitle files, by language, before they are imported
+// to the asset, and posts the reports that have issues to the export chat. Files whose
+// report is blocked must not be imported.
+func validateSubtitles(ctx workflow.Context, vxID string, files map[string]paths.Path) (map[string]subtitles.Report, error) {
+	reports := map[string]subtitles.Report{}
+	for _, lang := range wfutils.SortedKeys(files) {
+		report, err := wfutils.ValidateSubtitles(ctx, files[lang], lang, vxID)
+		if err != nil {
+			return nil, err
+		}
+		reports[lang] = *report
+	}
+
+	for _, lang := range wfutils.SortedKeys(reports) {
+		report := reports[lang]
+		if len(report.Issues) == 0 {
+			continue
+		}
+		icon := "🟨"
+		if report.Blocked {
+			icon = "🟥"
+		}
+		wfutils.SendTelegramText(ctx, telegram.ChatVOD, fmt.Sprintf("%s Subtitle QC for VXID: %s\n\n```%s```", icon, vxID, report.Summary()))
+	}
+
+	return reports, nil
+}
@@ -2,7 +2,6 @@ package miscworkflows
 
 import (
 	"github.com/bcc-code/bcc-media-flows/activities"
-	"github.com/bcc-code/bcc-media-flows/paths"
 	"github.com/bcc-code/bcc-media-flows/services/transcode"
 	wfutils "github.com/bcc-code/bcc-media-flows/utils/workflows"
 
@@ -23,8 +22,14 @@ func TranscodeHAP(
 
 	ctx = workflow.WithActivityOptions(ctx, wfutils.GetDefaultActivityOptions())
 
-	filePath := paths.MustParse(params.FilePath)
-	outputDir := paths.MustParse(params.OutputDir)
+	filePath, err := wfutils.ParsePath(ctx, params.FilePath)
+	if err != nil {
+		return nil, err
+	}
+	outputDir, err := wfutils.ParsePath(ctx, params.OutputDir)
+	if err != nil {
+		return nil, err
+	}
 
 	return wfutils.Execute(ctx, activities.Video.TranscodeToHAPActivity, activities.HAPInput{
 		FilePath:  filePath,
@@ -2,7 +2,6 @@ package miscworkflows
 
 import (
 	"github.com/bcc-code/bcc-media-flows/activities"
-	"github.com/bcc-code/bcc-media-flows/paths"
 	wfutils "github.com/bcc-code/bcc-media-flows/utils/workflows"
 
 	"go.temporal.io/sdk/workflow"
@@ -24,7 +23,10 @@ func TranscodePreviewFile(
 
 	ctx = workflow.WithActivityOptions(ctx, wfutils.GetDefaultActivityOptions())
 
-	filePath := paths.MustParse(params.FilePath)
+	filePath, err := wfutils.ParsePath(ctx, params.FilePath)
+	if err != nil {
+		return err
+	}
 
 	return wfutils.Execute(ctx, activities.Video.TranscodePreview, activities.TranscodePreviewParams{
 		FilePath:           filePath,
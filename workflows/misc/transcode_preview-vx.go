@@ -3,7 +3,6 @@ package miscworkflows
 import (
 	"errors"
 	"fmt"
-	"github.com/bcc-code/bcc-media-flows/languages"
 	"github.com/samber/lo"
 	"path/filepath"
 	"strings"
@@ -94,9 +93,15 @@ func TranscodePreviewVX(
 		return err
 	}
 
+	languageList, err := wfutils.Languages(ctx)
+	if err != nil {
+		return err
+	}
+	languagesByISO := languageList.ByISO6391()
+
 	for _, l := range audioLangs {
 		p := previewResponse.AudioPreviewFiles[l]
-		tag := languages.LanguagesByISO[l].MBPreviewTag
+		tag := languagesByISO[l].MBPreviewTag
 		if tag == "" {
 			logger.Info("Skipping audio preview with empty MBPreviewTag", "language", l)
 			continue
@@ -1,7 +1,6 @@
 package miscworkflows
 
 import (
-	"github.com/bcc-code/bcc-media-flows/paths"
 	"github.com/bcc-code/bcc-media-flows/services/rclone"
 	wfutils "github.com/bcc-code/bcc-media-flows/utils/workflows"
 
@@ -33,8 +32,14 @@ func TranscribeFile(
 		return err
 	}
 
-	file := paths.MustParse(params.File)
-	destination := paths.MustParse(params.DestinationPath)
+	file, err := wfutils.ParsePath(ctx, params.File)
+	if err != nil {
+		return err
+	}
+	destination, err := wfutils.ParsePath(ctx, params.DestinationPath)
+	if err != nil {
+		return err
+	}
 
 	prepareResult, err := wfutils.Execute(ctx, activities.Audio.PrepareForTranscription, common.AudioInput{
 		Path:            file,
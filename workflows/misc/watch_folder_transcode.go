@@ -9,7 +9,6 @@ import (
 	"github.com/bcc-code/bcc-media-flows/activities"
 	"github.com/bcc-code/bcc-media-flows/common"
 	"github.com/bcc-code/bcc-media-flows/environment"
-	"github.com/bcc-code/bcc-media-flows/paths"
 	"github.com/bcc-code/bcc-media-flows/services/rclone"
 	"github.com/bcc-code/bcc-media-flows/services/transcode"
 	wfutils "github.com/bcc-code/bcc-media-flows/utils/workflows"
@@ -60,10 +59,13 @@ func WatchFolderTranscode(ctx workflow.Context, params WatchFolderTranscodeInput
 
 	ctx = workflow.WithActivityOptions(ctx, wfutils.GetDefaultActivityOptions())
 
-	path := paths.MustParse(params.Path)
+	path, err := wfutils.ParsePath(ctx, params.Path)
+	if err != nil {
+		return err
+	}
 	dir := path.Dir()
 
-	path, err := wfutils.StandardizeFileName(ctx, path)
+	path, err = wfutils.StandardizeFileName(ctx, path)
 	if err != nil {
 		return err
 	}
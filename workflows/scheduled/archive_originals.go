@@ -0,0 +1,271 @@
+package scheduled
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/bcc-code/bcc-media-flows/activities"
+	"github.com/bcc-code/bcc-media-flows/activities/cantemo"
+	vsactivity "github.com/bcc-code/bcc-media-flows/activities/vidispine"
+	"github.com/bcc-code/bcc-media-flows/paths"
+	"github.com/bcc-code/bcc-media-flows/services/fixity"
+	"github.com/bcc-code/bcc-media-flows/services/telegram"
+	"github.com/bcc-code/bcc-media-flows/services/vidispine/vscommon"
+	wfutils "github.com/bcc-code/bcc-media-flows/utils/workflows"
+	miscworkflows "github.com/bcc-code/bcc-media-flows/workflows/misc"
+	"go.temporal.io/sdk/workflow"
+)
+
+type ArchiveOriginalsParams struct {
+	// Months is how long an asset has gone unused before its original is archived.
+	// Zero means 12.
+	Months int
+	// Limit is how many originals are archived in a run. Zero means 50.
+	Limit int
+
+	// The rest is set when a run continues as new after a page of the search.
+
+	// Cutoff is when the assets were last used, at the latest, fixed by the first run.
+	Cutoff time.Time
+	// First is where in the search the run continues. Only the originals left where they
+	// were by the earlier pages are before it, as archived ones drop out of the search.
+	First int
+	// Done is what the earlier pages archived and failed.
+	Done *ArchiveOriginalsResult
+}
+
+// ArchivedOriginal is an original moved to the cold archive.
+type ArchivedOriginal struct {
+	VXID string
+	// Path is where the original was.
+	Path string
+	Size int64
+}
+
+type ArchiveOriginalsResult struct {
+	Archived []ArchivedOriginal
+	// Failed are originals that could not be archived, or were archived and are not
+	// in the archive as they were recorded.
+	Failed []OriginalCheck
+}
+
+const (
+	defaultArchiveMonths = 12
+	defaultArchiveLimit  = 50
+)
+
+// archiveSearchPage is how many candidates a run looks at.
+var archiveSearchPage = 100
+
+// ArchiveOriginals moves the originals of assets unused for months from Isilon to the
+// cold archive, a Vidispine storage on an S3 bucket whose objects go to a cold storage
+// class. The shapes keep their files, on the archive storage, and exports restore an
+// archived original with RestoreOriginal when they need it. It is started on a schedule.
+//
+// An asset is unused when it has not been exported or restored since the cutoff, as
+// portal_last_used records, or, for one that never was, when it was created before it.
+// The search leaves out the originals already archived. Each page of it is one run,
+// which continues as new for the next, so the history stays small however many
+// originals are looked at. Each original is checked against its checksums before it is
+// moved, so a damaged file is not archived, and the object in the archive is checked
+// against the size that was recorded.
+func ArchiveOriginals(ctx workflow.Context, params ArchiveOriginalsParams) (*ArchiveOriginalsResult, error) {
+	logger := workflow.GetLogger(ctx)
+	logger.Info("Starting ArchiveOriginals", "first", params.First)
+
+	ctx = workflow.WithActivityOptions(ctx, wfutils.GetDefaultActivityOptions())
+
+	if params.Months <= 0 {
+		params.Months = defaultArchiveMonths
+	}
+	if params.Limit <= 0 {
+		params.Limit = defaultArchiveLimit
+	}
+	if params.Cutoff.IsZero() {
+		params.Cutoff = workflow.Now(ctx).AddDate(0, -params.Months, 0)
+	}
+	if params.First <= 0 {
+		params.First = 1
+	}
+
+	result := params.Done
+	if result == nil {
+		result = &ArchiveOriginalsResult{}
+	}
+
+	coldArchive, err := wfutils.ColdArchive(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if coldArchive == nil {
+		logger.Info("No cold archive in the storage registry, archiving nothing")
+		return result, nil
+	}
+
+	page, err := wfutils.Execute(ctx, activities.Vidispine.SearchArchiveCandidates, vsactivity.SearchArchiveCandidatesParams{
+		UnusedSince: params.Cutoff,
+		First:       params.First,
+		Number:      archiveSearchPage,
+	}).Result(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	left := 0
+	for _, vxid := range page.VXIDs {
+		if len(result.Archived)+len(result.Failed) >= params.Limit {
+			break
+		}
+
+		archived, check := archiveOriginal(ctx, vxid, coldArchive)
+		if check != nil {
+			result.Failed = append(result.Failed, *check)
+		}
+		if archived != nil {
+			result.Archived = append(result.Archived, *archived)
+		} else {
+			left++
+		}
+	}
+
+	more := params.First-1+len(page.VXIDs) < page.Hits
+	if more && len(page.VXIDs) > 0 && len(result.Archived)+len(result.Failed) < params.Limit {
+		params.First += left
+		params.Done = result
+		return nil, workflow.NewContinueAsNewError(ctx, ArchiveOriginals, params)
+	}
+
+	if len(result.Archived) > 0 || len(result.Failed) > 0 {
+		wfutils.SendTelegramText(ctx, telegram.ChatOther, result.report())
+	}
+
+	return result, nil
+}
+
+// archiveOriginal moves the original of an asset to the cold archive, and returns
+// it, what went wrong, or neither for an original that is not archived from where it
+// is, such as one already in the archive. An original that is archived and then found
+// to differ from what was recorded is returned with what was wrong.
+func archiveOriginal(ctx workflow.Context, vxid string, coldArchive *paths.Storage) (*ArchivedOriginal, *OriginalCheck) {
+	check := &OriginalCheck{VXID: vxid}
+	fail := func(err error) (*ArchivedOriginal, *OriginalCheck) {
+		check.Error = err.Error()
+		return nil, check
+	}
+
+	shapes, err := wfutils.Execute(ctx, activities.Vidispine.GetShapes, vsactivity.VXOnlyParam{VXID: vxid}).Result(ctx)
+	if err != nil {
+		return fail(err)
+	}
+	shape := shapes.GetShape("original")
+	if shape == nil || shape.GetPath() == "" || miscworkflows.IsArchived(coldArchive, shape.GetPath()) {
+		return nil, nil
+	}
+	check.Path = shape.GetPath()
+
+	source, err := wfutils.VidispineStorageForPath(ctx, check.Path)
+	if err != nil {
+		return fail(err)
+	}
+	if source == nil || source.Archive {
+		return nil, nil
+	}
+
+	file, err := wfutils.ParsePath(ctx, check.Path)
+	if err != nil {
+		return fail(err)
+	}
+
+	sums, err := originalChecksums(ctx, vxid, file)
+	if err != nil {
+		return fail(err)
+	}
+
+	record := miscworkflows.ArchiveRecord{
+		Storage:  source.VidispineID,
+		Path:     strings.TrimPrefix(check.Path, source.Linux),
+		Archived: workflow.Now(ctx),
+	}
+
+	err = wfutils.Execute(ctx, activities.Cantemo.MoveFileWait, &cantemo.RenameFileParams{
+		ItemID:            vxid,
+		ShapeID:           shape.ID,
+		SourceStorage:     source.VidispineID,
+		DestinatinStorage: coldArchive.VidispineID,
+		NewPath:           record.Path,
+	}).Wait(ctx)
+	if err != nil {
+		return fail(err)
+	}
+
+	err = wfutils.SetVidispineMeta(ctx, vxid, vscommon.FieldArchived.Value, record.String())
+	if err != nil {
+		return fail(err)
+	}
+
+	archived := &ArchivedOriginal{VXID: vxid, Path: check.Path, Size: sums.Size}
+	if err := checkArchivedOriginal(ctx, vxid, coldArchive, *sums); err != nil {
+		check.Error = err.Error()
+		return archived, check
+	}
+	return archived, nil
+}
+
+// originalChecksums checks an original against the checksums it was recorded with, or
+// computes and records them for one ingested before they were.
+func originalChecksums(ctx workflow.Context, vxid string, file paths.Path) (*fixity.Checksums, error) {
+	sums, err := wfutils.GetFixity(ctx, vxid)
+	if err != nil {
+		return nil, err
+	}
+
+	if sums != nil {
+		return sums, wfutils.VerifyFile(ctx, file, *sums)
+	}
+
+	sums, err = wfutils.Execute(ctx, activities.Util.ComputeChecksums, activities.FileInput{Path: file}).Result(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return sums, wfutils.SetVidispineMeta(ctx, vxid, vscommon.FieldFixity.Value, sums.String())
+}
+
+// checkArchivedOriginal checks that the original is in the archive, with the size it
+// was recorded with. The object is not read, as it is in a cold storage class.
+func checkArchivedOriginal(ctx workflow.Context, vxid string, coldArchive *paths.Storage, sums fixity.Checksums) error {
+	shapes, err := wfutils.Execute(ctx, activities.Vidispine.GetShapes, vsactivity.VXOnlyParam{VXID: vxid}).Result(ctx)
+	if err != nil {
+		return err
+	}
+	shape := shapes.GetShape("original")
+	if shape == nil || !miscworkflows.IsArchived(coldArchive, shape.GetPath()) {
+		return fmt.Errorf("original is not in the archive after the move")
+	}
+
+	object, err := wfutils.Execute(ctx, activities.Util.GetColdObject, activities.ColdObjectInput{URI: shape.GetPath()}).Result(ctx)
+	if err != nil {
+		return err
+	}
+	if object.Size != sums.Size {
+		return fmt.Errorf("%s: size is %d, expected %d", shape.GetPath(), object.Size, sums.Size)
+	}
+	return nil
+}
+
+func (r ArchiveOriginalsResult) report() string {
+	var size int64
+	for _, a := range r.Archived {
+		size += a.Size
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "🟦 Archived %d originals, %.1f GB, to the cold archive", len(r.Archived), float64(size)/1e9)
+	if len(r.Failed) > 0 {
+		fmt.Fprintf(&b, "\n\n🟥 %d failed:", len(r.Failed))
+		for _, c := range r.Failed {
+			fmt.Fprintf(&b, "\nhttps://vault.bcc.media/item/%s: %s", c.VXID, c.Error)
+		}
+	}
+	return b.String()
+}
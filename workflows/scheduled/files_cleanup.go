@@ -1,11 +1,16 @@
 package scheduled
 
 import (
+	"fmt"
+	"sort"
+	"strings"
 	"time"
 
 	"github.com/bcc-code/bcc-media-flows/activities"
 	"github.com/bcc-code/bcc-media-flows/paths"
+	"github.com/bcc-code/bcc-media-flows/services/telegram"
 	wfutils "github.com/bcc-code/bcc-media-flows/utils/workflows"
+	"github.com/samber/lo"
 	"go.temporal.io/sdk/workflow"
 )
 
@@ -13,19 +18,32 @@ import (
 //
 // Counts rather than paths: this is the workflow's completion event, and the
 // paths of a fortnight of temp files across sixty folders do not belong in the
-// history. They are in the activity results and the worker logs.
+// history. A dry run lists them in CSV reports.
 type CleanupResult struct {
 	DeletedCount        int
 	DeletedCountPerRoot map[string]int
+	// DeletedSize is how many bytes were deleted.
+	DeletedSize        int64
+	DeletedSizePerRoot map[string]int64
+	// FreedForSpace is what was deleted on each drive that was short of space, on top of
+	// the files that were old enough, by drive. It is in the totals.
+	FreedForSpace map[string]activities.CleanupFilesResult
+	// DryRun results count what would have been deleted.
+	DryRun bool
+	// Report is the folder the files deleted, or that would have been, are listed in.
+	Report string
 }
 
 const defaultRetention = 14 * 24 * time.Hour
 
-// cleanupFolder is one folder to sweep. A zero Retention keeps
-// defaultRetention, so only a folder that needs a different one says so.
+// cleanupFolder is one folder to sweep. A zero Retention keeps the retention of
+// the storage the folder is on, or defaultRetention when the storage has none, so
+// only a folder that needs a different one says so.
 type cleanupFolder struct {
 	Path      string
 	Retention time.Duration
+	// Root is the folder as a path on its drive, set by storagePolicy.
+	Root paths.Path
 }
 
 func (f cleanupFolder) retention() time.Duration {
@@ -39,125 +57,279 @@ func (f cleanupFolder) cutoff(ctx workflow.Context) time.Time {
 	return workflow.Now(ctx).Add(-f.retention())
 }
 
-func CleanupTemp(ctx workflow.Context) (*CleanupResult, error) {
-	logger := workflow.GetLogger(ctx)
-	logger.Info("Starting temp files cleanup")
+var cleanupFolders = []cleanupFolder{
+	{Path: "/mnt/temp/"},
+	{Path: "/mnt/filecatalyst/ingestgrow/"},
+	{Path: "/mnt/filecatalyst/workflow/"},
+	{Path: "/mnt/isilon/Input/FromArvoll"},
+	{Path: "/mnt/isilon/Input/FromDelivery"},
+	{Path: "/mnt/isilon/Input/MGOF"},
+	{Path: "/mnt/isilon/Input/Rawmaterial"},
 
-	ctx = workflow.WithActivityOptions(ctx, wfutils.GetDefaultActivityOptions())
+	// Transcoding folders
+	{Path: "/mnt/isilon/Transcoding/AVCintra100_HD/error"},
+	{Path: "/mnt/isilon/Transcoding/AVCintra100_HD/out"},
+	{Path: "/mnt/isilon/Transcoding/AVCintra100_HD/processed"},
+	{Path: "/mnt/isilon/Transcoding/AVCintra100_HD/processing"},
+	{Path: "/mnt/isilon/Transcoding/AVCintra100_HD/tmp"},
+
+	{Path: "/mnt/isilon/Transcoding/AVCIntra100_TCSet/In"},
+	{Path: "/mnt/isilon/Transcoding/AVCIntra100_TCSet/Out"},
+
+	{Path: "/mnt/isilon/Transcoding/BroadcastWav_withTC/In"},
+	{Path: "/mnt/isilon/Transcoding/BroadcastWav_withTC/Out"},
+
+	{Path: "/mnt/isilon/Transcoding/Fallback/In"},
+	{Path: "/mnt/isilon/Transcoding/Fallback/Out"},
+
+	{Path: "/mnt/isilon/Transcoding/ImageSequence/Input"},
+	{Path: "/mnt/isilon/Transcoding/ImageSequence/Out"},
+
+	{Path: "/mnt/isilon/Transcoding/IMX50/In"},
+	{Path: "/mnt/isilon/Transcoding/IMX50/Out"},
 
-	folders := []cleanupFolder{
-		{Path: "/mnt/temp/"},
-		{Path: "/mnt/filecatalyst/ingestgrow/"},
-		{Path: "/mnt/filecatalyst/workflow/"},
-		{Path: "/mnt/isilon/Input/FromArvoll"},
-		{Path: "/mnt/isilon/Input/FromDelivery"},
-		{Path: "/mnt/isilon/Input/MGOF"},
-		{Path: "/mnt/isilon/Input/Rawmaterial"},
+	{Path: "/mnt/isilon/Transcoding/Multitrack_Playback/Input"},
+	{Path: "/mnt/isilon/Transcoding/Multitrack_Playback/Output"},
 
-		// Transcoding folders
-		{Path: "/mnt/isilon/Transcoding/AVCintra100_HD/error"},
-		{Path: "/mnt/isilon/Transcoding/AVCintra100_HD/out"},
-		{Path: "/mnt/isilon/Transcoding/AVCintra100_HD/processed"},
-		{Path: "/mnt/isilon/Transcoding/AVCintra100_HD/processing"},
-		{Path: "/mnt/isilon/Transcoding/AVCintra100_HD/tmp"},
+	{Path: "/mnt/isilon/Transcoding/ProRes422D/in"},
 
-		{Path: "/mnt/isilon/Transcoding/AVCIntra100_TCSet/In"},
-		{Path: "/mnt/isilon/Transcoding/AVCIntra100_TCSet/Out"},
+	{Path: "/mnt/isilon/Transcoding/ProRes422HQ_HD/error"},
+	{Path: "/mnt/isilon/Transcoding/ProRes422HQ_HD/out"},
+	{Path: "/mnt/isilon/Transcoding/ProRes422HQ_HD/processed"},
+	{Path: "/mnt/isilon/Transcoding/ProRes422HQ_HD/processing"},
+	{Path: "/mnt/isilon/Transcoding/ProRes422HQ_HD/tmp"},
 
-		{Path: "/mnt/isilon/Transcoding/BroadcastWav_withTC/In"},
-		{Path: "/mnt/isilon/Transcoding/BroadcastWav_withTC/Out"},
+	{Path: "/mnt/isilon/Transcoding/ProRes422HQ_HD_16chaudio/In"},
+	{Path: "/mnt/isilon/Transcoding/ProRes422HQ_HD_16chaudio/Out"},
 
-		{Path: "/mnt/isilon/Transcoding/Fallback/In"},
-		{Path: "/mnt/isilon/Transcoding/Fallback/Out"},
+	{Path: "/mnt/isilon/Transcoding/ProRes422HQ_Native/error"},
+	{Path: "/mnt/isilon/Transcoding/ProRes422HQ_Native/out"},
+	{Path: "/mnt/isilon/Transcoding/ProRes422HQ_Native/processed"},
+	{Path: "/mnt/isilon/Transcoding/ProRes422HQ_Native/processing"},
+	{Path: "/mnt/isilon/Transcoding/ProRes422HQ_Native/tmp"},
 
-		{Path: "/mnt/isilon/Transcoding/ImageSequence/Input"},
-		{Path: "/mnt/isilon/Transcoding/ImageSequence/Out"},
+	{Path: "/mnt/isilon/Transcoding/ProRes422HQ_Native_25FPS/error"},
+	{Path: "/mnt/isilon/Transcoding/ProRes422HQ_Native_25FPS/out"},
+	{Path: "/mnt/isilon/Transcoding/ProRes422HQ_Native_25FPS/processed"},
+	{Path: "/mnt/isilon/Transcoding/ProRes422HQ_Native_25FPS/processing"},
+	{Path: "/mnt/isilon/Transcoding/ProRes422HQ_Native_25FPS/tmp"},
 
-		{Path: "/mnt/isilon/Transcoding/IMX50/In"},
-		{Path: "/mnt/isilon/Transcoding/IMX50/Out"},
+	{Path: "/mnt/isilon/Transcoding/ProRes444_4K-25FPS/In"},
+	{Path: "/mnt/isilon/Transcoding/ProRes444_4K-25FPS/Out"},
 
-		{Path: "/mnt/isilon/Transcoding/Multitrack_Playback/Input"},
-		{Path: "/mnt/isilon/Transcoding/Multitrack_Playback/Output"},
+	{Path: "/mnt/isilon/Transcoding/SRT_TCOffset/In"},
+	{Path: "/mnt/isilon/Transcoding/SRT_TCOffset/Out"},
 
-		{Path: "/mnt/isilon/Transcoding/ProRes422D/in"},
+	{Path: "/mnt/isilon/Transcoding/tmp"},
 
-		{Path: "/mnt/isilon/Transcoding/ProRes422HQ_HD/error"},
-		{Path: "/mnt/isilon/Transcoding/ProRes422HQ_HD/out"},
-		{Path: "/mnt/isilon/Transcoding/ProRes422HQ_HD/processed"},
-		{Path: "/mnt/isilon/Transcoding/ProRes422HQ_HD/processing"},
-		{Path: "/mnt/isilon/Transcoding/ProRes422HQ_HD/tmp"},
+	{Path: "/mnt/isilon/Transcoding/Transcribe/error"},
+	{Path: "/mnt/isilon/Transcoding/Transcribe/out"},
+	{Path: "/mnt/isilon/Transcoding/Transcribe/processed"},
+	{Path: "/mnt/isilon/Transcoding/Transcribe/processing"},
+	{Path: "/mnt/isilon/Transcoding/Transcribe/tmp"},
 
-		{Path: "/mnt/isilon/Transcoding/ProRes422HQ_HD_16chaudio/In"},
-		{Path: "/mnt/isilon/Transcoding/ProRes422HQ_HD_16chaudio/Out"},
+	{Path: "/mnt/isilon/Transcoding/Wav/In"},
+	{Path: "/mnt/isilon/Transcoding/Wav/Out"},
 
-		{Path: "/mnt/isilon/Transcoding/ProRes422HQ_Native/error"},
-		{Path: "/mnt/isilon/Transcoding/ProRes422HQ_Native/out"},
-		{Path: "/mnt/isilon/Transcoding/ProRes422HQ_Native/processed"},
-		{Path: "/mnt/isilon/Transcoding/ProRes422HQ_Native/processing"},
-		{Path: "/mnt/isilon/Transcoding/ProRes422HQ_Native/tmp"},
+	{Path: "/mnt/isilon/Transcoding/XDCAMHD422/In"},
+	{Path: "/mnt/isilon/Transcoding/XDCAMHD422/Out"},
 
-		{Path: "/mnt/isilon/Transcoding/ProRes422HQ_Native_25FPS/error"},
-		{Path: "/mnt/isilon/Transcoding/ProRes422HQ_Native_25FPS/out"},
-		{Path: "/mnt/isilon/Transcoding/ProRes422HQ_Native_25FPS/processed"},
-		{Path: "/mnt/isilon/Transcoding/ProRes422HQ_Native_25FPS/processing"},
-		{Path: "/mnt/isilon/Transcoding/ProRes422HQ_Native_25FPS/tmp"},
+	{Path: "/mnt/isilon/Export"},
+}
 
-		{Path: "/mnt/isilon/Transcoding/ProRes444_4K-25FPS/In"},
-		{Path: "/mnt/isilon/Transcoding/ProRes444_4K-25FPS/Out"},
+// cleanupPolicy is how the folders are cleaned up, as the storage registry has it.
+type cleanupPolicy struct {
+	Folders []cleanupFolder
+	// Quotas are the drives cleaned up when they are short of space, by name.
+	Quotas map[string]paths.Quota
+}
 
-		{Path: "/mnt/isilon/Transcoding/SRT_TCOffset/In"},
-		{Path: "/mnt/isilon/Transcoding/SRT_TCOffset/Out"},
+// storagePolicy gives the folders that keep the default retention the one of the
+// storage they are on, when the storage registry has one, resolves them to their
+// drives and finds the drives with a free space quota. It goes through SideEffect so a replay on a worker with another
+// registry sweeps the same.
+func storagePolicy(ctx workflow.Context, folders []cleanupFolder) (*cleanupPolicy, error) {
+	var policy cleanupPolicy
+	err := workflow.SideEffect(ctx, func(workflow.Context) any {
+		policy := cleanupPolicy{Quotas: map[string]paths.Quota{}}
+		for _, f := range folders {
+			f.Root = paths.MustParse(f.Path)
+			drive := f.Root.Drive
+			if f.Retention == 0 {
+				f.Retention = paths.Current().Retention(drive)
+			}
+			policy.Folders = append(policy.Folders, f)
+			if quota := paths.Current().Quota(drive); quota != nil {
+				policy.Quotas[drive.Value] = *quota
+			}
+		}
+		return policy
+	}).Get(&policy)
 
-		{Path: "/mnt/isilon/Transcoding/tmp"},
+	return &policy, err
+}
 
-		{Path: "/mnt/isilon/Transcoding/Transcribe/error"},
-		{Path: "/mnt/isilon/Transcoding/Transcribe/out"},
-		{Path: "/mnt/isilon/Transcoding/Transcribe/processed"},
-		{Path: "/mnt/isilon/Transcoding/Transcribe/processing"},
-		{Path: "/mnt/isilon/Transcoding/Transcribe/tmp"},
+type CleanupTempParams struct {
+	// DryRun deletes nothing, and reports the files that would be deleted and the space
+	// that would be freed.
+	DryRun bool
+}
 
-		{Path: "/mnt/isilon/Transcoding/Wav/In"},
-		{Path: "/mnt/isilon/Transcoding/Wav/Out"},
+// CleanupTemp deletes the files in the temporary folders that are older than their
+// retention. A drive with a free space quota that is still short of space after that
+// has its oldest files deleted, however young, until it has its target free. Nothing is
+// deleted in the temp folders of the workflows that are running. The files deleted are
+// listed in a CSV report per folder, so it can be told afterwards what went.
+func CleanupTemp(ctx workflow.Context, params CleanupTempParams) (*CleanupResult, error) {
+	logger := workflow.GetLogger(ctx)
+	logger.Info("Starting temp files cleanup", "dryRun", params.DryRun)
 
-		{Path: "/mnt/isilon/Transcoding/XDCAMHD422/In"},
-		{Path: "/mnt/isilon/Transcoding/XDCAMHD422/Out"},
+	ctx = workflow.WithActivityOptions(ctx, wfutils.GetDefaultActivityOptions())
 
-		{Path: "/mnt/isilon/Export"},
+	policy, err := storagePolicy(ctx, cleanupFolders)
+	if err != nil {
+		return nil, err
 	}
 
-	deletedTotal := 0
-	deletedPerRoot := map[string]int{}
+	running, err := wfutils.RunningWorkflowTempFolders(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	res := &CleanupResult{
+		DeletedCountPerRoot: map[string]int{},
+		DeletedSizePerRoot:  map[string]int64{},
+		FreedForSpace:       map[string]activities.CleanupFilesResult{},
+		DryRun:              params.DryRun,
+	}
+
+	reports, err := wfutils.GetWorkflowIsilonOutputFolder(ctx, "system/cleanup")
+	if err != nil {
+		return nil, err
+	}
+	res.Report = reports.Linux()
+
+	var drives []paths.Drive
+	inputs := map[paths.Drive][]activities.CleanupInput{}
+	deletedPerDrive := map[paths.Drive]int64{}
 
-	for _, folder := range folders {
-		deletedFilesLoop, err := wfutils.ExecuteWithLowPrioQueue(ctx, activities.Util.DeleteOldFiles, activities.CleanupInput{
-			Root:      paths.MustParse(folder.Path),
+	for _, folder := range policy.Folders {
+		root := folder.Root
+		input := activities.CleanupInput{
+			Root:      root,
 			OlderThan: folder.cutoff(ctx),
-		}).Result(ctx)
+			Exclude:   foldersWithin(running, root),
+			DryRun:    params.DryRun,
+			Report:    cleanupReport(reports, folder.Path),
+		}
 
+		deleted, err := wfutils.ExecuteWithLowPrioQueue(ctx, activities.Util.DeleteOldFiles, input).Result(ctx)
 		if err != nil {
 			logger.Error("Error during temp files cleanup", "error", err)
 			return nil, err
 		}
 
-		logger.Info("Deleted files", "root", folder.Path, "count", len(deletedFilesLoop))
+		logger.Info("Deleted files", "root", folder.Path, "count", deleted.Count, "size", deleted.Size)
+
+		res.DeletedCountPerRoot[folder.Path] = deleted.Count
+		res.DeletedSizePerRoot[folder.Path] = deleted.Size
+		res.DeletedCount += deleted.Count
+		res.DeletedSize += deleted.Size
 
-		deletedPerRoot[folder.Path] = len(deletedFilesLoop)
-		deletedTotal += len(deletedFilesLoop)
+		if _, ok := inputs[root.Drive]; !ok {
+			drives = append(drives, root.Drive)
+		}
+		inputs[root.Drive] = append(inputs[root.Drive], input)
+		deletedPerDrive[root.Drive] += deleted.Size
+
+		if params.DryRun {
+			continue
+		}
 
 		err = wfutils.ExecuteWithLowPrioQueue(ctx, activities.Util.DeleteEmptyDirectories, activities.CleanupInput{
-			Root: paths.MustParse(folder.Path),
+			Root:    root,
+			Exclude: input.Exclude,
 		}).Wait(ctx)
+		if err != nil {
+			return nil, err
+		}
+	}
 
+	for _, drive := range drives {
+		quota, ok := policy.Quotas[drive.Value]
+		if !ok {
+			continue
+		}
+
+		usage, err := wfutils.Execute(ctx, activities.Util.GetDiskUsage, activities.FileInput{Path: paths.New(drive, "")}).Result(ctx)
 		if err != nil {
 			return nil, err
 		}
 
+		// A dry run has freed nothing, so it counts what it would have.
+		if params.DryRun {
+			usage.Free += deletedPerDrive[drive]
+		}
+		if usage.FreePercent() >= quota.MinFreePercent {
+			continue
+		}
+
+		size := int64(quota.TargetFreePercent/100*float64(usage.Size)) - usage.Free
+		logger.Info("Drive is short of space", "drive", drive.Value, "free", usage.FreePercent(), "freeing", size)
+
+		freed, err := wfutils.ExecuteWithLowPrioQueue(ctx, activities.Util.FreeSpace, activities.FreeSpaceInput{
+			Folders: inputs[drive],
+			Size:    size,
+			DryRun:  params.DryRun,
+			Report:  cleanupReport(reports, "space "+drive.Value),
+		}).Result(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		res.FreedForSpace[drive.Value] = *freed
+		res.DeletedCount += freed.Count
+		res.DeletedSize += freed.Size
 	}
 
-	res := &CleanupResult{
-		DeletedCount:        deletedTotal,
-		DeletedCountPerRoot: deletedPerRoot,
+	if params.DryRun || len(res.FreedForSpace) > 0 {
+		wfutils.SendTelegramText(ctx, telegram.ChatOther, res.report())
 	}
 
 	return res, nil
 }
+
+// foldersWithin are the folders that are in a root.
+func foldersWithin(folders []paths.Path, root paths.Path) []paths.Path {
+	var out []paths.Path
+	for _, f := range folders {
+		if f.Drive == root.Drive && (root.Path == "" || f.Path == root.Path || strings.HasPrefix(f.Path, strings.TrimSuffix(root.Path, "/")+"/")) {
+			out = append(out, f)
+		}
+	}
+	return out
+}
+
+// cleanupReport is the report file of a folder.
+func cleanupReport(reports paths.Path, name string) *paths.Path {
+	name = strings.ReplaceAll(strings.Trim(name, "/"), "/", "_")
+	report := reports.Append(name + ".csv")
+	return &report
+}
+
+func (r CleanupResult) report() string {
+	var b strings.Builder
+	if r.DryRun {
+		fmt.Fprintf(&b, "🟦 A cleanup would delete %d temp files, %.1f GB. They are listed in %s", r.DeletedCount, float64(r.DeletedSize)/1e9, r.Report)
+	} else {
+		fmt.Fprintf(&b, "🟧 Deleted %d temp files, %.1f GB. They are listed in %s", r.DeletedCount, float64(r.DeletedSize)/1e9, r.Report)
+	}
+
+	drives := lo.Keys(r.FreedForSpace)
+	sort.Strings(drives)
+	for _, drive := range drives {
+		freed := r.FreedForSpace[drive]
+		fmt.Fprintf(&b, "\n%s was short of space: %d files, %.1f GB, before their retention", drive, freed.Count, float64(freed.Size)/1e9)
+	}
+	return b.String()
+}
@@ -1,17 +1,32 @@
 package scheduled
 
 import (
+	"context"
+	"fmt"
+	"net/http/httptest"
 	"os"
 	"testing"
 	"time"
 
 	"github.com/bcc-code/bcc-media-flows/activities"
+	"github.com/bcc-code/bcc-media-flows/activities/cantemo"
 	vsactivity "github.com/bcc-code/bcc-media-flows/activities/vidispine"
+	"github.com/bcc-code/bcc-media-flows/paths"
+	"github.com/bcc-code/bcc-media-flows/services/fixity"
+	"github.com/bcc-code/bcc-media-flows/services/s3"
+	"github.com/bcc-code/bcc-media-flows/services/vidispine/vsapi"
+	"github.com/bcc-code/bcc-media-flows/services/vidispine/vscommon"
+	"github.com/bcc-code/bcc-media-flows/services/vidispine/vsfake"
+	"github.com/bcc-code/bcc-media-flows/utils/testutils"
+	miscworkflows "github.com/bcc-code/bcc-media-flows/workflows/misc"
 	"github.com/samber/lo"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/suite"
+	"go.temporal.io/sdk/converter"
+	"go.temporal.io/sdk/temporal"
 	"go.temporal.io/sdk/testsuite"
+	"go.temporal.io/sdk/workflow"
 )
 
 type ScheduledTestSuite struct {
@@ -70,14 +85,231 @@ func (s *ScheduledTestSuite) Test_MediabankenPurgeTrash_Empty() {
 	s.Empty(result.DeletedVXIDs)
 }
 
+// The same purge against a fake Vidispine, checking what is left rather than the calls.
+func (s *ScheduledTestSuite) Test_MediabankenPurgeTrash_FakeVidispine() {
+	fake := vsfake.New()
+	kept := fake.AddItem(map[string]string{"title": "Kept"})
+	trashed := fake.AddItem(map[string]string{"title": "Trashed", "portal_deleted": "2024-03-01T10:00:00"})
+
+	server := httptest.NewServer(fake)
+	defer server.Close()
+	s.env.RegisterActivity(&vsactivity.Activities{Client: vsapi.NewClient(vsfake.Config{URL: server.URL})})
+
+	s.env.ExecuteWorkflow(MediabankenPurgeTrash)
+	s.True(s.env.IsWorkflowCompleted())
+	s.NoError(s.env.GetWorkflowError())
+
+	var result MediabankenPurgeTrashResult
+	s.NoError(s.env.GetWorkflowResult(&result))
+	s.Equal([]string{trashed}, result.DeletedVXIDs)
+	s.Equal([]string{kept}, fake.ItemIDs())
+}
+
+// originalShape is an original shape with its file at a path on the test drive.
+func originalShape(path string) vsapi.Shape {
+	return vsapi.Shape{
+		Tag: []string{"original"},
+		ContainerComponent: vsapi.ContainerComponent{
+			File: []vsapi.File{{URI: []string{"file://" + path}}},
+		},
+	}
+}
+
+func (s *ScheduledTestSuite) Test_VerifyOriginals() {
+	s.Require().NoError(os.MkdirAll("testdata/generated", os.ModePerm))
+	s.T().Cleanup(func() { _ = os.RemoveAll("testdata") })
+	s.Require().NoError(os.WriteFile("testdata/generated/intact.mxf", []byte("hello"), 0644))
+	s.Require().NoError(os.WriteFile("testdata/generated/rotten.mxf", []byte("hellO"), 0644))
+
+	recorded := fixity.Checksums{MD5: "5d41402abc4b2a76b9719d911017c592", XXHash: "26c7827d889f6da3", Size: 5}
+
+	fake := vsfake.New()
+	intact := fake.AddItem(map[string]string{"title": "Intact", "portal_fixity": recorded.String()})
+	fake.AddShape(intact, originalShape("testdata/generated/intact.mxf"))
+	rotten := fake.AddItem(map[string]string{"title": "Rotten", "portal_fixity": recorded.String()})
+	fake.AddShape(rotten, originalShape("testdata/generated/rotten.mxf"))
+	missing := fake.AddItem(map[string]string{"title": "Missing", "portal_fixity": recorded.String()})
+	fake.AddItem(map[string]string{"title": "Not recorded"})
+	// Archived originals are in a cold storage class, so they are not sampled.
+	fake.AddItem(map[string]string{"title": "Archived", "portal_fixity": recorded.String(), "portal_archived": `{"storage":"VX-56"}`})
+
+	server := httptest.NewServer(fake)
+	defer server.Close()
+	s.env.RegisterActivity(&vsactivity.Activities{Client: vsapi.NewClient(vsfake.Config{URL: server.URL})})
+	s.env.RegisterActivity(activities.Util.VerifyChecksums)
+
+	var report string
+	s.env.OnActivity(activities.Util.SendTelegramMessage, mock.Anything, mock.Anything).
+		Run(func(args mock.Arguments) { report = fmt.Sprint(args.Get(1)) }).
+		Once().Return(nil, nil)
+
+	s.env.ExecuteWorkflow(VerifyOriginals, VerifyOriginalsParams{SampleSize: 10})
+	s.True(s.env.IsWorkflowCompleted())
+	s.NoError(s.env.GetWorkflowError())
+
+	var result VerifyOriginalsResult
+	s.NoError(s.env.GetWorkflowResult(&result))
+	s.Equal(2, result.Checked)
+	s.Require().Len(result.Damaged, 1)
+	s.Equal(rotten, result.Damaged[0].VXID)
+	s.Require().Len(result.Failed, 1)
+	s.Equal(missing, result.Failed[0].VXID)
+	s.Contains(report, rotten)
+}
+
+// archiveFixture is a Vidispine with originals on Isilon, one of each kind ArchiveOriginals
+// tells apart, and the cold archive storage VX-90. The activities are registered on the
+// fake.
+type archiveFixture struct {
+	fake     *vsfake.Server
+	client   *vsapi.Client
+	recorded fixity.Checksums
+	// old was last used long ago, and rotten, never used and created long ago, does not
+	// match its checksums.
+	old, oldFile, rotten string
+}
+
+func (s *ScheduledTestSuite) archiveFixture() archiveFixture {
+	testutils.WithColdArchive(s.T())
+
+	f := archiveFixture{
+		fake:     vsfake.New(),
+		recorded: fixity.Checksums{MD5: "5d41402abc4b2a76b9719d911017c592", XXHash: "26c7827d889f6da3", Size: 5},
+	}
+	f.fake.AddStorage("VX-56", "/mnt/isilon/Master/")
+	f.fake.AddStorage("VX-90", "s3://cold-archive/")
+	addOriginal := func(storage, path string, meta map[string]string) (string, string) {
+		meta["portal_fixity"] = f.recorded.String()
+		itemID := f.fake.AddItem(meta)
+		fileID := f.fake.AddFile(storage, path, vsapi.FileStateClosed)
+		f.fake.AddShape(itemID, vsapi.Shape{
+			Tag:                []string{"original"},
+			ContainerComponent: vsapi.ContainerComponent{File: []vsapi.File{*f.fake.File(fileID)}},
+		})
+		return itemID, fileID
+	}
+
+	f.old, f.oldFile = addOriginal("VX-56", "2020/01/01/old.mxf", map[string]string{
+		"created": "2020-01-01T10:00:00", "portal_last_used": "2021-01-01T10:00:00",
+	})
+	addOriginal("VX-56", "2026/01/01/new.mxf", map[string]string{
+		"created": "2026-01-01T10:00:00",
+	})
+	// Created long ago, and exported a month ago.
+	addOriginal("VX-56", "2020/01/02/used.mxf", map[string]string{
+		"created": "2020-01-02T10:00:00", "portal_last_used": s.env.Now().AddDate(0, -1, 0).UTC().Format(vsactivity.SearchTime),
+	})
+	archived := miscworkflows.ArchiveRecord{Storage: "VX-56", Path: "2020/01/03/archived.mxf"}
+	addOriginal("VX-90", "2020/01/03/archived.mxf", map[string]string{
+		"created": "2020-01-03T10:00:00", "portal_archived": archived.String(),
+	})
+	f.rotten, _ = addOriginal("VX-56", "2020/01/04/rotten.mxf", map[string]string{
+		"created": "2020-01-04T10:00:00",
+	})
+
+	server := httptest.NewServer(f.fake)
+	s.T().Cleanup(server.Close)
+	f.client = vsapi.NewClient(vsfake.Config{URL: server.URL})
+	s.env.RegisterActivity(&vsactivity.Activities{Client: f.client})
+
+	return f
+}
+
+// onArchive mocks archiving old: it matches its checksums, is moved, and is in the
+// archive with the size recorded.
+func (s *ScheduledTestSuite) onArchive(f archiveFixture) {
+	s.env.OnActivity(activities.Util.VerifyChecksums, mock.Anything, mock.MatchedBy(func(input activities.VerifyChecksumsInput) bool {
+		return input.Path.Base() == "old.mxf"
+	})).Once().Return(&f.recorded, nil)
+
+	s.env.OnActivity(activities.Cantemo.MoveFileWait, mock.Anything, mock.Anything).
+		Run(func(args mock.Arguments) {
+			params := args.Get(1).(*cantemo.RenameFileParams)
+			s.Equal(f.old, params.ItemID)
+			s.Equal("VX-56", params.SourceStorage)
+			s.Equal("VX-90", params.DestinatinStorage)
+			_, err := f.client.MoveFile(f.oldFile, params.DestinatinStorage, params.NewPath)
+			s.NoError(err)
+		}).Once().Return(nil, nil)
+
+	s.env.OnActivity(activities.Util.GetColdObject, mock.Anything, activities.ColdObjectInput{URI: "s3://cold-archive/2020/01/01/old.mxf"}).
+		Once().Return(&s3.ObjectStatus{Size: 5, StorageClass: "DEEP_ARCHIVE"}, nil)
+}
+
+func (s *ScheduledTestSuite) Test_ArchiveOriginals() {
+	f := s.archiveFixture()
+	s.onArchive(f)
+
+	s.env.OnActivity(activities.Util.VerifyChecksums, mock.Anything, mock.MatchedBy(func(input activities.VerifyChecksumsInput) bool {
+		return input.Path.Base() == "rotten.mxf"
+	})).Once().Return(nil, temporal.NewNonRetryableApplicationError("rotten.mxf: size is 6, expected 5", fixity.ErrorType, nil))
+
+	var report string
+	s.env.OnActivity(activities.Util.SendTelegramMessage, mock.Anything, mock.Anything).
+		Run(func(args mock.Arguments) { report = fmt.Sprint(args.Get(1)) }).
+		Once().Return(nil, nil)
+
+	s.env.ExecuteWorkflow(ArchiveOriginals, ArchiveOriginalsParams{})
+	s.True(s.env.IsWorkflowCompleted())
+	s.NoError(s.env.GetWorkflowError())
+
+	var result ArchiveOriginalsResult
+	s.NoError(s.env.GetWorkflowResult(&result))
+	s.Equal([]ArchivedOriginal{{VXID: f.old, Path: "/mnt/isilon/Master/2020/01/01/old.mxf", Size: 5}}, result.Archived)
+	s.Require().Len(result.Failed, 1)
+	s.Equal(f.rotten, result.Failed[0].VXID)
+	s.Contains(report, f.rotten)
+
+	record, err := miscworkflows.ParseArchiveRecord(f.fake.Metadata(f.old).Get(vscommon.FieldArchived, ""))
+	s.Require().NoError(err)
+	s.Equal("VX-56", record.Storage)
+	s.Equal("2020/01/01/old.mxf", record.Path)
+}
+
+// A run handles one page of the search, and continues as new with what it did. The
+// originals it archived drop out of the search, so the next page starts past only the
+// ones it left.
+func (s *ScheduledTestSuite) Test_ArchiveOriginals_ContinuesAsNewPerPage() {
+	archiveSearchPage = 1
+	s.T().Cleanup(func() { archiveSearchPage = 100 })
+
+	f := s.archiveFixture()
+	s.onArchive(f)
+
+	s.env.ExecuteWorkflow(ArchiveOriginals, ArchiveOriginalsParams{})
+	s.True(s.env.IsWorkflowCompleted())
+
+	var next *workflow.ContinueAsNewError
+	s.Require().ErrorAs(s.env.GetWorkflowError(), &next)
+	var params ArchiveOriginalsParams
+	s.Require().NoError(converter.GetDefaultDataConverter().FromPayloads(next.Input, &params))
+	s.Equal(1, params.First)
+	s.Equal(s.env.Now().AddDate(0, -12, 0).UTC(), params.Cutoff.UTC())
+	s.Require().NotNil(params.Done)
+	s.Equal(f.old, params.Done.Archived[0].VXID)
+}
+
+// onCleanupActivities mocks the activities every cleanup calls. Nothing is running, and
+// every drive is 1000 bytes with free of them free.
+func (s *ScheduledTestSuite) onCleanupActivities(free int64) {
+	s.env.OnActivity(activities.Util.GetRunningWorkflowRuns, mock.Anything, mock.Anything).
+		Return([]string{}, nil)
+	s.env.OnActivity(activities.Util.GetDiskUsage, mock.Anything, mock.Anything).
+		Return(&activities.DiskUsage{Size: 1000, Free: free}, nil).Maybe()
+	s.env.OnActivity(activities.Util.CreateFolder, mock.Anything, mock.Anything).
+		Return(nil, nil).Maybe()
+}
+
 func (s *ScheduledTestSuite) Test_CleanupTemp() {
+	s.onCleanupActivities(500)
+
 	s.env.OnActivity(activities.Util.DeleteOldFiles, mock.Anything, mock.Anything).
-		Return([]string{"file1.tmp", "file2.tmp"}, nil)
+		Return(&activities.CleanupFilesResult{Count: 2, Size: 20}, nil)
 
 	s.env.OnActivity(activities.Util.DeleteEmptyDirectories, mock.Anything, mock.Anything).
 		Return(nil, nil)
 
-	s.env.ExecuteWorkflow(CleanupTemp)
+	s.env.ExecuteWorkflow(CleanupTemp, CleanupTempParams{})
 	s.True(s.env.IsWorkflowCompleted())
 	err := s.env.GetWorkflowError()
 	s.NoError(err)
@@ -102,19 +334,21 @@ func (s *ScheduledTestSuite) Test_CleanupTemp_OneCutoffForEveryFolder() {
 	var cutoffs []time.Time
 	var roots []string
 
+	s.onCleanupActivities(500)
+
 	s.env.OnActivity(activities.Util.DeleteOldFiles, mock.Anything, mock.Anything).
 		Run(func(args mock.Arguments) {
 			input := args.Get(1).(activities.CleanupInput)
 			cutoffs = append(cutoffs, input.OlderThan)
 			roots = append(roots, input.Root.Local())
-		}).Return([]string{}, nil)
+		}).Return(&activities.CleanupFilesResult{}, nil)
 
 	s.env.OnActivity(activities.Util.DeleteEmptyDirectories, mock.Anything, mock.Anything).
 		Return(nil, nil)
 
 	start := s.env.Now()
 
-	s.env.ExecuteWorkflow(CleanupTemp)
+	s.env.ExecuteWorkflow(CleanupTemp, CleanupTempParams{})
 	s.True(s.env.IsWorkflowCompleted())
 	s.NoError(s.env.GetWorkflowError())
 
@@ -134,3 +368,126 @@ func TestCleanupFolder_Retention(t *testing.T) {
 	assert.Equal(t, 90*24*time.Hour, kept.retention())
 	assert.Greater(t, kept.retention(), defaultRetention, "a longer retention sweeps less")
 }
+
+func (s *ScheduledTestSuite) Test_CleanupTemp_ShortOfSpace() {
+	s.env.OnActivity(activities.Util.GetRunningWorkflowRuns, mock.Anything, mock.Anything).
+		Return([]string{"run-1"}, nil)
+	s.env.OnActivity(activities.Util.GetDiskUsage, mock.Anything, activities.FileInput{Path: paths.New(paths.TempDrive, "")}).
+		Once().Return(&activities.DiskUsage{Size: 1000, Free: 100}, nil)
+	s.env.OnActivity(activities.Util.CreateFolder, mock.Anything, mock.Anything).
+		Once().Return(nil, nil)
+
+	var excluded []paths.Path
+	s.env.OnActivity(activities.Util.DeleteOldFiles, mock.Anything, mock.Anything).
+		Run(func(args mock.Arguments) {
+			input := args.Get(1).(activities.CleanupInput)
+			excluded = append(excluded, input.Exclude...)
+			s.False(input.DryRun)
+		}).Return(&activities.CleanupFilesResult{Count: 1, Size: 10}, nil)
+	s.env.OnActivity(activities.Util.DeleteEmptyDirectories, mock.Anything, mock.Anything).
+		Return(nil, nil)
+
+	s.env.OnActivity(activities.Util.FreeSpace, mock.Anything, mock.Anything).
+		Run(func(args mock.Arguments) {
+			input := args.Get(1).(activities.FreeSpaceInput)
+			s.Require().Len(input.Folders, 1)
+			s.Equal("/mnt/temp", input.Folders[0].Root.Linux())
+			// 25% of 1000 free, from 100.
+			s.Equal(int64(150), input.Size)
+			s.Equal("space temp.csv", input.Report.Base())
+		}).Once().Return(&activities.CleanupFilesResult{Count: 3, Size: 160}, nil)
+
+	var report string
+	s.env.OnActivity(activities.Util.SendTelegramMessage, mock.Anything, mock.Anything).
+		Run(func(args mock.Arguments) { report = fmt.Sprint(args.Get(1)) }).
+		Once().Return(nil, nil)
+
+	s.env.ExecuteWorkflow(CleanupTemp, CleanupTempParams{})
+	s.True(s.env.IsWorkflowCompleted())
+	s.NoError(s.env.GetWorkflowError())
+
+	// Only the temp folder has the running workflow's folder in it.
+	s.Equal([]paths.Path{paths.New(paths.TempDrive, "workflows/run-1")}, excluded)
+
+	var result CleanupResult
+	s.NoError(s.env.GetWorkflowResult(&result))
+	s.Equal(60, result.DeletedCount)
+	s.Equal(int64(730), result.DeletedSize)
+	s.Equal(activities.CleanupFilesResult{Count: 3, Size: 160}, result.FreedForSpace["temp"])
+	s.Contains(report, "temp was short of space")
+	// What went is listed, as the space pass deletes files younger than their retention.
+	s.Contains(result.Report, "/mnt/isilon/system/cleanup/")
+	s.Contains(report, result.Report)
+}
+
+func (s *ScheduledTestSuite) Test_CleanupTemp_DryRun() {
+	s.onCleanupActivities(100)
+
+	var reports []string
+	s.env.OnActivity(activities.Util.DeleteOldFiles, mock.Anything, mock.Anything).
+		Run(func(args mock.Arguments) {
+			input := args.Get(1).(activities.CleanupInput)
+			s.True(input.DryRun)
+			s.Require().NotNil(input.Report)
+			reports = append(reports, input.Report.Base())
+		}).Return(&activities.CleanupFilesResult{Count: 1, Size: 10}, nil)
+
+	s.env.OnActivity(activities.Util.FreeSpace, mock.Anything, mock.Anything).
+		Run(func(args mock.Arguments) {
+			input := args.Get(1).(activities.FreeSpaceInput)
+			s.True(input.DryRun)
+			// What the age pass would have freed on temp counts.
+			s.Equal(int64(140), input.Size)
+			s.Equal("space temp.csv", input.Report.Base())
+		}).Once().Return(&activities.CleanupFilesResult{Count: 2, Size: 140}, nil)
+
+	var report string
+	s.env.OnActivity(activities.Util.SendTelegramMessage, mock.Anything, mock.Anything).
+		Run(func(args mock.Arguments) { report = fmt.Sprint(args.Get(1)) }).
+		Once().Return(nil, nil)
+
+	s.env.ExecuteWorkflow(CleanupTemp, CleanupTempParams{DryRun: true})
+	s.True(s.env.IsWorkflowCompleted())
+	s.NoError(s.env.GetWorkflowError())
+
+	s.Contains(reports, "mnt_temp.csv")
+	s.Contains(reports, "mnt_isilon_Transcoding_tmp.csv")
+
+	var result CleanupResult
+	s.NoError(s.env.GetWorkflowResult(&result))
+	s.True(result.DryRun)
+	s.Contains(result.Report, "/mnt/isilon/system/cleanup/")
+	s.Equal(59, result.DeletedCount)
+	s.Contains(report, "would delete 59 temp files")
+}
+
+func (s *ScheduledTestSuite) Test_StorageSummary() {
+	s.onCleanupActivities(500)
+
+	s.env.OnActivity(activities.Util.GetFolderUsage, mock.Anything, mock.Anything).
+		Return(func(_ context.Context, input activities.CleanupInput) (*activities.FolderUsage, error) {
+			if input.Root.Path == "Export" {
+				return &activities.FolderUsage{Count: 10, Size: 300e9, OldCount: 4, OldSize: 100e9}, nil
+			}
+			return &activities.FolderUsage{Count: 1, Size: 1e9}, nil
+		})
+
+	var report string
+	s.env.OnActivity(activities.Util.SendTelegramMessage, mock.Anything, mock.Anything).
+		Run(func(args mock.Arguments) { report = fmt.Sprint(args.Get(1)) }).
+		Once().Return(nil, nil)
+
+	s.env.ExecuteWorkflow(StorageSummary)
+	s.True(s.env.IsWorkflowCompleted())
+	s.NoError(s.env.GetWorkflowError())
+
+	var result StorageSummaryResult
+	s.NoError(s.env.GetWorkflowResult(&result))
+	s.Equal([]string{"temp", "filecatalyst", "isilon"}, lo.Map(result.Drives, func(d DriveSummary, _ int) string { return d.Drive }))
+
+	isilon := result.Drives[2]
+	s.Len(isilon.Folders, 54)
+	s.Equal("/mnt/isilon/Export", isilon.Folders[0].Path)
+	s.Contains(report, "Cleanup folders: 63 files, 353.0 GB, 100.0 GB past retention")
+	s.Contains(report, "/mnt/isilon/Export: 300.0 GB")
+}
@@ -0,0 +1,117 @@
+package scheduled
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/bcc-code/bcc-media-flows/activities"
+	"github.com/bcc-code/bcc-media-flows/paths"
+	"github.com/bcc-code/bcc-media-flows/services/telegram"
+	wfutils "github.com/bcc-code/bcc-media-flows/utils/workflows"
+	"go.temporal.io/sdk/workflow"
+)
+
+// storageSummaryFolders is how many of the largest folders of a drive the summary lists.
+const storageSummaryFolders = 5
+
+// DriveSummary is how full a drive with cleanup folders is, and what is in them.
+type DriveSummary struct {
+	Drive string
+	Size  int64
+	Free  int64
+	// Folders are the cleanup folders on the drive, largest first.
+	Folders []FolderSummary
+}
+
+type FolderSummary struct {
+	Path string
+	activities.FolderUsage
+}
+
+type StorageSummaryResult struct {
+	Drives []DriveSummary
+}
+
+// StorageSummary posts how full each drive with cleanup folders is, how much is in the
+// folders and how much of it the next cleanup deletes. It is started on a schedule,
+// weekly.
+func StorageSummary(ctx workflow.Context) (*StorageSummaryResult, error) {
+	logger := workflow.GetLogger(ctx)
+	logger.Info("Starting StorageSummary")
+
+	ctx = workflow.WithActivityOptions(ctx, wfutils.GetDefaultActivityOptions())
+
+	policy, err := storagePolicy(ctx, cleanupFolders)
+	if err != nil {
+		return nil, err
+	}
+
+	running, err := wfutils.RunningWorkflowTempFolders(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &StorageSummaryResult{}
+	byDrive := map[paths.Drive]int{}
+
+	for _, folder := range policy.Folders {
+		root := folder.Root
+
+		i, ok := byDrive[root.Drive]
+		if !ok {
+			usage, err := wfutils.Execute(ctx, activities.Util.GetDiskUsage, activities.FileInput{Path: paths.New(root.Drive, "")}).Result(ctx)
+			if err != nil {
+				return nil, err
+			}
+			i = len(result.Drives)
+			byDrive[root.Drive] = i
+			result.Drives = append(result.Drives, DriveSummary{Drive: root.Drive.Value, Size: usage.Size, Free: usage.Free})
+		}
+
+		usage, err := wfutils.ExecuteWithLowPrioQueue(ctx, activities.Util.GetFolderUsage, activities.CleanupInput{
+			Root:      root,
+			OlderThan: folder.cutoff(ctx),
+			Exclude:   foldersWithin(running, root),
+		}).Result(ctx)
+		if err != nil {
+			return nil, err
+		}
+		result.Drives[i].Folders = append(result.Drives[i].Folders, FolderSummary{Path: folder.Path, FolderUsage: *usage})
+	}
+
+	for _, drive := range result.Drives {
+		sort.SliceStable(drive.Folders, func(a, b int) bool {
+			return drive.Folders[a].Size > drive.Folders[b].Size
+		})
+	}
+
+	wfutils.SendTelegramText(ctx, telegram.ChatOther, result.report())
+
+	return result, nil
+}
+
+func (r StorageSummaryResult) report() string {
+	gb := func(size int64) float64 { return float64(size) / 1e9 }
+
+	var b strings.Builder
+	b.WriteString("🟦 Weekly storage summary")
+	for _, drive := range r.Drives {
+		var files, size, oldSize int64
+		for _, f := range drive.Folders {
+			files += int64(f.Count)
+			size += f.Size
+			oldSize += f.OldSize
+		}
+		usage := activities.DiskUsage{Size: drive.Size, Free: drive.Free}
+		fmt.Fprintf(&b, "\n\n%s: %.0f of %.0f GB free (%.0f%%)", drive.Drive, gb(drive.Free), gb(drive.Size), usage.FreePercent())
+		fmt.Fprintf(&b, "\nCleanup folders: %d files, %.1f GB, %.1f GB past retention", files, gb(size), gb(oldSize))
+		for i, f := range drive.Folders {
+			if i == storageSummaryFolders || f.Size == 0 {
+				break
+			}
+			fmt.Fprintf(&b, "\n%s: %.1f GB", f.Path, gb(f.Size))
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,143 @@
+package scheduled
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/bcc-code/bcc-media-flows/activities"
+	vsactivity "github.com/bcc-code/bcc-media-flows/activities/vidispine"
+	"github.com/bcc-code/bcc-media-flows/services/fixity"
+	"github.com/bcc-code/bcc-media-flows/services/telegram"
+	"github.com/bcc-code/bcc-media-flows/services/vidispine/vscommon"
+	wfutils "github.com/bcc-code/bcc-media-flows/utils/workflows"
+	"go.temporal.io/sdk/temporal"
+	"go.temporal.io/sdk/workflow"
+)
+
+type VerifyOriginalsParams struct {
+	// SampleSize is how many originals are checked. Zero means 20.
+	SampleSize int
+}
+
+// OriginalCheck is an original that was not found as it was recorded.
+type OriginalCheck struct {
+	VXID  string
+	Path  string
+	Error string
+}
+
+type VerifyOriginalsResult struct {
+	Checked int
+	// Damaged are originals that no longer match their checksums.
+	Damaged []OriginalCheck
+	// Failed are originals that could not be checked, such as ones with no file.
+	Failed []OriginalCheck
+}
+
+const defaultFixitySample = 20
+
+// VerifyOriginals checks a sample of the originals recorded with checksums at ingest
+// against them, to find files that have rotted on the storage, and reports what it
+// finds to Telegram. It is started on a schedule; each run checks a new sample.
+func VerifyOriginals(ctx workflow.Context, params VerifyOriginalsParams) (*VerifyOriginalsResult, error) {
+	logger := workflow.GetLogger(ctx)
+	logger.Info("Starting VerifyOriginals")
+
+	ctx = workflow.WithActivityOptions(ctx, wfutils.GetDefaultActivityOptions())
+
+	if params.SampleSize <= 0 {
+		params.SampleSize = defaultFixitySample
+	}
+
+	sample, err := wfutils.Execute(ctx, activities.Vidispine.SampleItemsWithField, vsactivity.SampleItemsWithFieldParams{
+		Field: vscommon.FieldFixity,
+		Count: params.SampleSize,
+		// Archived originals are in a cold storage class, and cannot be read to be
+		// checked without restoring them.
+		Without: []vscommon.FieldType{vscommon.FieldArchived},
+	}).Result(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &VerifyOriginalsResult{}
+	for _, item := range sample {
+		check, damaged := verifyOriginal(ctx, item)
+		switch {
+		case check == nil:
+			result.Checked++
+		case damaged:
+			result.Checked++
+			result.Damaged = append(result.Damaged, *check)
+		default:
+			result.Failed = append(result.Failed, *check)
+		}
+	}
+
+	if len(result.Damaged) > 0 || len(result.Failed) > 0 {
+		wfutils.SendTelegramText(ctx, telegram.ChatOther, result.report())
+	}
+
+	return result, nil
+}
+
+// verifyOriginal checks the original of an item against its checksums, and returns
+// what was wrong with it, or nil, and whether it was checked and did not match.
+func verifyOriginal(ctx workflow.Context, item vsactivity.ItemFieldValue) (*OriginalCheck, bool) {
+	check := &OriginalCheck{VXID: item.VXID}
+
+	sums, err := fixity.Parse(item.Value)
+	if err != nil {
+		check.Error = err.Error()
+		return check, false
+	}
+
+	shapes, err := wfutils.Execute(ctx, activities.Vidispine.GetShapes, vsactivity.VXOnlyParam{VXID: item.VXID}).Result(ctx)
+	if err != nil {
+		check.Error = err.Error()
+		return check, false
+	}
+	shape := shapes.GetShape("original")
+	if shape == nil || shape.GetPath() == "" {
+		check.Error = "no original"
+		return check, false
+	}
+	check.Path = shape.GetPath()
+
+	file, err := wfutils.ParsePath(ctx, check.Path)
+	if err != nil {
+		check.Error = err.Error()
+		return check, false
+	}
+
+	err = wfutils.VerifyFile(ctx, file, sums)
+	var appErr *temporal.ApplicationError
+	if errors.As(err, &appErr) && appErr.Type() == fixity.ErrorType {
+		check.Error = appErr.Message()
+		return check, true
+	}
+	if err != nil {
+		check.Error = err.Error()
+		return check, false
+	}
+	return nil, false
+}
+
+func (r VerifyOriginalsResult) report() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "🟥 Checked %d originals against their checksums", r.Checked)
+	if len(r.Damaged) > 0 {
+		fmt.Fprintf(&b, "\n\n%d no longer match:", len(r.Damaged))
+		for _, c := range r.Damaged {
+			fmt.Fprintf(&b, "\nhttps://vault.bcc.media/item/%s `%s`", c.VXID, c.Path)
+		}
+	}
+	if len(r.Failed) > 0 {
+		fmt.Fprintf(&b, "\n\n%d could not be checked:", len(r.Failed))
+		for _, c := range r.Failed {
+			fmt.Fprintf(&b, "\nhttps://vault.bcc.media/item/%s: %s", c.VXID, c.Error)
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,277 @@
+package triggers
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/bcc-code/bcc-media-flows/workflows/export"
+	"github.com/go-playground/validator/v10"
+	"github.com/samber/lo"
+)
+
+// vxIDParam is the parameter that names the asset a workflow is about. It is what
+// httpin tags the started workflow with.
+const vxIDParam = "vxID"
+
+// FieldError is why one parameter was refused.
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// ValidationError lists every parameter of a request that was refused.
+type ValidationError struct {
+	Trigger string
+	Errors  []FieldError
+}
+
+func (e *ValidationError) Error() string {
+	var fields []string
+	for _, f := range e.Errors {
+		fields = append(fields, f.Field+" "+f.Message)
+	}
+	return fmt.Sprintf("invalid parameters for %s: %s", e.Trigger, strings.Join(fields, "; "))
+}
+
+var resolutionPattern = regexp.MustCompile(`^[0-9]+x[0-9]+$`)
+
+// enums are validate rules a value passes by being one of the values of an enum, so a
+// parameter names the enum rather than a list of its values that can drift from it.
+var enums = map[string][]string{
+	"exportdestination": export.AssetExportDestinations.Values(),
+}
+
+var validate = func() *validator.Validate {
+	v := validator.New()
+	v.RegisterTagNameFunc(paramName)
+	_ = v.RegisterValidation("resolution", func(fl validator.FieldLevel) bool {
+		return resolutionPattern.MatchString(fl.Field().String())
+	})
+	for tag, values := range enums {
+		_ = v.RegisterValidation(tag, func(fl validator.FieldLevel) bool {
+			return lo.Contains(values, fl.Field().String())
+		})
+	}
+	return v
+}()
+
+// paramName is the name a parameter is sent as: its json tag.
+func paramName(field reflect.StructField) string {
+	name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+	if name == "" || name == "-" {
+		return field.Name
+	}
+	return name
+}
+
+// describe says what kind of value a parameter takes, for error messages.
+func describe(t reflect.Type) string {
+	switch t.Kind() {
+	case reflect.Int:
+		return "an integer"
+	case reflect.Float64:
+		return "a number"
+	case reflect.Bool:
+		return "true or false"
+	case reflect.Slice:
+		return "a list of strings"
+	}
+	return "a string"
+}
+
+// Common are the parameters every trigger takes besides its own.
+type Common struct {
+	TriggeredBy      string `json:"triggeredBy" doc:"Who started the workflow, recorded on it. Defaults to httpin."`
+	CallbackURL      string `json:"callbackUrl" validate:"omitempty,http_url" doc:"URL the outcome of the workflow is posted to when it finishes, successful or not."`
+	CallbackSecretID string `json:"callbackSecretId" doc:"ID of the configured secret the callback is signed with, as an HMAC-SHA256 in its X-Signature-256 header."`
+}
+
+var commonType = reflect.TypeOf(Common{})
+
+// param is one parameter of a request, and where its value is read into.
+type param struct {
+	name  string
+	field reflect.StructField
+	value reflect.Value
+}
+
+// requestParams lists the parameters of the trigger followed by the common ones.
+func requestParams(params, common reflect.Value) []param {
+	var all []param
+	for _, v := range []reflect.Value{params.Elem(), common.Elem()} {
+		for i := 0; i < v.NumField(); i++ {
+			field := v.Type().Field(i)
+			all = append(all, param{paramName(field), field, v.Field(i)})
+		}
+	}
+	return all
+}
+
+// BindJSON reads the params from a JSON object. An empty body is an empty object.
+func (t Trigger) BindJSON(data []byte) (*Start, error) {
+	var raw map[string]json.RawMessage
+	if len(bytes.TrimSpace(data)) > 0 {
+		if err := json.Unmarshal(data, &raw); err != nil {
+			return nil, fmt.Errorf("body is not a JSON object: %w", err)
+		}
+	}
+
+	params, common := reflect.New(t.params), reflect.New(commonType)
+	var fieldErrors []FieldError
+	known := map[string]bool{}
+	for _, p := range requestParams(params, common) {
+		known[p.name] = true
+
+		value, ok := raw[p.name]
+		if !ok {
+			continue
+		}
+		if err := json.Unmarshal(value, p.value.Addr().Interface()); err != nil {
+			fieldErrors = append(fieldErrors, FieldError{p.name, "must be " + describe(p.field.Type)})
+		}
+	}
+	for name := range raw {
+		if !known[name] {
+			fieldErrors = append(fieldErrors, FieldError{name, "is not a parameter of " + t.Name})
+		}
+	}
+
+	return t.bind(params, common, fieldErrors)
+}
+
+// BindValues reads the params from query or form values. A list can be sent as
+// repeated values or as one comma separated value.
+func (t Trigger) BindValues(values url.Values) (*Start, error) {
+	params, common := reflect.New(t.params), reflect.New(commonType)
+	var fieldErrors []FieldError
+	for _, p := range requestParams(params, common) {
+		sent := values[p.name]
+		if len(sent) == 0 || len(sent) == 1 && sent[0] == "" {
+			continue
+		}
+		if err := setValue(p.value, sent); err != nil {
+			fieldErrors = append(fieldErrors, FieldError{p.name, "must be " + describe(p.field.Type)})
+		}
+	}
+
+	return t.bind(params, common, fieldErrors)
+}
+
+func setValue(v reflect.Value, sent []string) error {
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(sent[0])
+	case reflect.Int:
+		i, err := strconv.Atoi(sent[0])
+		if err != nil {
+			return err
+		}
+		v.SetInt(int64(i))
+	case reflect.Float64:
+		f, err := strconv.ParseFloat(sent[0], 64)
+		if err != nil {
+			return err
+		}
+		v.SetFloat(f)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(sent[0])
+		if err != nil {
+			return err
+		}
+		v.SetBool(b)
+	case reflect.Slice:
+		if len(sent) == 1 {
+			sent = strings.Split(sent[0], ",")
+		}
+		v.Set(reflect.ValueOf(sent))
+	default:
+		return fmt.Errorf("unsupported parameter type %s", v.Type())
+	}
+	return nil
+}
+
+// bind validates the params once they are read, and builds the workflow input from
+// them. Values that could not be read are reported together with what fails
+// validation, so the caller sees everything wrong with the request at once.
+func (t Trigger) bind(params, common reflect.Value, fieldErrors []FieldError) (*Start, error) {
+	unreadable := map[string]bool{}
+	for _, f := range fieldErrors {
+		unreadable[f.Field] = true
+	}
+
+	for _, v := range []reflect.Value{params, common} {
+		err := validate.Struct(v.Interface())
+		var invalid validator.ValidationErrors
+		if errors.As(err, &invalid) {
+			for _, fe := range invalid {
+				// A value that could not be read is left zero, which its rules would
+				// refuse too; the first error says more.
+				if !unreadable[fe.Field()] {
+					fieldErrors = append(fieldErrors, FieldError{fe.Field(), message(fe)})
+				}
+			}
+		} else if err != nil {
+			return nil, err
+		}
+	}
+
+	if len(fieldErrors) > 0 {
+		return nil, &ValidationError{Trigger: t.Name, Errors: fieldErrors}
+	}
+
+	start := &Start{
+		Common:   common.Elem().Interface().(Common),
+		Workflow: t.Workflow,
+		Input:    t.input(params.Interface()),
+		Queue:    t.queue(),
+	}
+	if t.WorkflowID != nil {
+		start.WorkflowID = t.WorkflowID(start.Input)
+	}
+	for i := 0; i < t.params.NumField(); i++ {
+		if paramName(t.params.Field(i)) == vxIDParam {
+			start.VXID = params.Elem().Field(i).String()
+		}
+	}
+	return start, nil
+}
+
+func message(fe validator.FieldError) string {
+	if values, ok := enums[fe.Tag()]; ok {
+		return "must be one of " + strings.Join(values, ", ")
+	}
+
+	switch fe.Tag() {
+	case "required":
+		return "is required"
+	case "oneof":
+		return "must be one of " + strings.ReplaceAll(fe.Param(), " ", ", ")
+	case "resolution":
+		return "must be WIDTHxHEIGHT, such as 1920x1080"
+	case "http_url":
+		return "must be an http or https URL"
+	case "min", "max":
+		bound := "at least"
+		if fe.Tag() == "max" {
+			bound = "at most"
+		}
+		switch fe.Kind() {
+		case reflect.Slice:
+			if fe.Tag() == "min" && fe.Param() == "1" {
+				return "must not be empty"
+			}
+			return fmt.Sprintf("must have %s %s values", bound, fe.Param())
+		case reflect.String:
+			return fmt.Sprintf("must be %s %s characters", bound, fe.Param())
+		}
+		return fmt.Sprintf("must be %s %s", bound, fe.Param())
+	}
+	return "fails " + fe.Tag()
+}
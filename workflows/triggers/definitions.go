@@ -0,0 +1,176 @@
+package triggers
+
+import (
+	"fmt"
+
+	"github.com/bcc-code/bcc-media-flows/utils"
+	"github.com/bcc-code/bcc-media-flows/workflows/export"
+	ingestworkflows "github.com/bcc-code/bcc-media-flows/workflows/ingest"
+	miscworkflows "github.com/bcc-code/bcc-media-flows/workflows/misc"
+)
+
+type vxParams struct {
+	VXID string `json:"vxID" validate:"required" doc:"The Vidispine item, such as VX-123."`
+}
+
+type transcribeVXParams struct {
+	VXID     string `json:"vxID" validate:"required" doc:"The Vidispine item to transcribe."`
+	Language string `json:"language" validate:"required" doc:"Language spoken in the item, such as no or en."`
+}
+
+type transcribeFileParams struct {
+	File            string `json:"file" validate:"required" doc:"Path of the file to transcribe."`
+	Language        string `json:"language" validate:"required" doc:"Language spoken in the file, such as no or en."`
+	DestinationPath string `json:"destinationPath" validate:"required" doc:"Folder the transcription is written to."`
+}
+
+type fileParams struct {
+	File string `json:"file" validate:"required" doc:"Path of the file."`
+}
+
+type createThumbnailsVXParams struct {
+	VXID   string `json:"vxID" validate:"required" doc:"The Vidispine item to create thumbnails for."`
+	Width  int    `json:"width" validate:"min=0,max=7680" doc:"Thumbnail width; 0 or unset uses the default of 320."`
+	Height int    `json:"height" validate:"min=0,max=4320" doc:"Thumbnail height; 0 or unset uses the default of 180."`
+}
+
+type exportAssetVXParams struct {
+	VXID          string   `json:"vxID" validate:"required" doc:"The Vidispine item to export."`
+	Destinations  []string `json:"destinations" validate:"min=1,dive,exportdestination" doc:"Where to export to."`
+	Languages     []string `json:"languages" doc:"Audio and subtitle languages to include; unset includes all."`
+	Resolutions   []string `json:"resolutions" validate:"dive,resolution" doc:"Video resolutions to export, as WIDTHxHEIGHT; unset uses the destination's defaults."`
+	WithChapters  bool     `json:"withChapters" doc:"Export chapter markers too."`
+	WatermarkPath string   `json:"watermarkPath" doc:"Image burnt into the video, if any."`
+	DryRun        bool     `json:"dryRun" doc:"Return the plan of the export instead of exporting."`
+}
+
+type executeFFmpegParams struct {
+	Arguments []string `json:"arguments" validate:"min=1" doc:"Arguments ffmpeg is run with."`
+}
+
+type assetIngestJSONParams struct {
+	JSONPath string `json:"jsonPath" validate:"required" doc:"Path of the JSON order form."`
+}
+
+type normalizeAudioParams struct {
+	File       string  `json:"file" validate:"required" doc:"Path of the audio file to normalize."`
+	TargetLUFS float64 `json:"targetLUFS" validate:"required,min=-70,max=-1" doc:"Integrated loudness to normalize to, such as -23."`
+}
+
+type incrementalIngestParams struct {
+	Path   string `json:"path" validate:"required" doc:"Path of the growing file."`
+	Source string `json:"source" doc:"Room or recorder the file comes from; unset is the default source."`
+}
+
+// parseResolutions reads resolutions that validated as WIDTHxHEIGHT.
+func parseResolutions(values []string) []utils.Resolution {
+	var resolutions []utils.Resolution
+	for _, r := range values {
+		var width, height int
+		_, _ = fmt.Sscanf(r, "%dx%d", &width, &height)
+		resolutions = append(resolutions, utils.Resolution{
+			Width:  width,
+			Height: height,
+			IsFile: false,
+		})
+	}
+	return resolutions
+}
+
+var definitions = []Trigger{
+	define("TranscribeVX", "Transcribe the audio of a Vidispine item.", miscworkflows.TranscribeVX,
+		func(p transcribeVXParams) any {
+			return miscworkflows.TranscribeVXInput{
+				Language: p.Language,
+				VXID:     p.VXID,
+			}
+		}),
+	define("TranscribeFile", "Transcribe the audio of a file.", miscworkflows.TranscribeFile,
+		func(p transcribeFileParams) any {
+			return miscworkflows.TranscribeFileInput{
+				Language:        p.Language,
+				File:            p.File,
+				DestinationPath: p.DestinationPath,
+			}
+		}),
+	define("TranscodePreviewVX", "Create the preview shape of a Vidispine item.", miscworkflows.TranscodePreviewVX,
+		func(p vxParams) any {
+			return miscworkflows.TranscodePreviewVXInput{
+				VXID: p.VXID,
+			}
+		}),
+	define("TranscodePreviewFile", "Create a preview of a file, next to it.", miscworkflows.TranscodePreviewFile,
+		func(p fileParams) any {
+			return miscworkflows.TranscodePreviewFileInput{
+				FilePath: p.File,
+			}
+		}),
+	define("CreateThumbnailsVX", "Create the thumbnails of a Vidispine item.", miscworkflows.CreateThumbnailsVX,
+		func(p createThumbnailsVXParams) any {
+			return miscworkflows.CreateThumbnailsVXInput{
+				VXID:   p.VXID,
+				Width:  p.Width,
+				Height: p.Height,
+			}
+		}),
+	define("ExportTimedMetadata", "Export the timed metadata of a Vidispine item.", export.ExportTimedMetadata,
+		func(p vxParams) any {
+			return export.ExportTimedMetadataParams{
+				VXID: p.VXID,
+			}
+		}),
+	define("ExportAssetVX", "Export a Vidispine item to one or more destinations.", export.VXExport,
+		func(p exportAssetVXParams) any {
+			return export.VXExportParams{
+				VXID:          p.VXID,
+				WithChapters:  p.WithChapters,
+				WatermarkPath: p.WatermarkPath,
+				Destinations:  p.Destinations,
+				Languages:     p.Languages,
+				Resolutions:   parseResolutions(p.Resolutions),
+				DryRun:        p.DryRun,
+			}
+		}),
+	define("ExecuteFFmpeg", "Run ffmpeg with the given arguments, reporting its progress.", miscworkflows.ExecuteFFmpeg,
+		func(p executeFFmpegParams) any {
+			return miscworkflows.ExecuteFFmpegInput{
+				Arguments: p.Arguments,
+			}
+		}),
+	define("AssetIngestJSON", "Ingest the files of a JSON order form.", ingestworkflows.AssetJSON,
+		func(p assetIngestJSONParams) any {
+			return ingestworkflows.AssetJSONParams{
+				JSONPath: p.JSONPath,
+			}
+		}),
+	define("ImportSubtitlesFromSubtrans", "Import the subtitles Subtrans has for a Vidispine item.", miscworkflows.ImportSubtitlesFromSubtrans,
+		func(p vxParams) any {
+			return miscworkflows.ImportSubtitlesFromSubtransInput{
+				VXID: p.VXID,
+			}
+		}),
+	define("UpdateAssetRelations", "Update the relations of a Vidispine item to other items.", miscworkflows.UpdateAssetRelations,
+		func(p vxParams) any {
+			return miscworkflows.UpdateAssetRelationsParams{
+				AssetID: p.VXID,
+			}
+		}),
+	define("NormalizeAudio", "Normalize the loudness of an audio file.", miscworkflows.NormalizeAudioLevelWorkflow,
+		func(p normalizeAudioParams) any {
+			return miscworkflows.NormalizeAudioParams{
+				FilePath:              p.File,
+				TargetLUFS:            p.TargetLUFS,
+				PerformOutputAnalysis: true,
+			}
+		}),
+	// One live ingest runs per source, which the workflow ID is what ensures.
+	define("IncrementalIngest", "Ingest a file while it is still being written.", ingestworkflows.Incremental,
+		func(p incrementalIngestParams) any {
+			return ingestworkflows.IncrementalParams{
+				Path:   p.Path,
+				Source: p.Source,
+			}
+		}).withWorkflowID(func(input any) string {
+		return ingestworkflows.LiveIngestWorkflowIDFor(input.(ingestworkflows.IncrementalParams).Source)
+	}),
+}
@@ -0,0 +1,192 @@
+package triggers
+
+import (
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// Schema is the subset of an OpenAPI schema object the triggers need.
+type Schema struct {
+	Ref                  string             `json:"$ref,omitempty"`
+	Type                 string             `json:"type,omitempty"`
+	Description          string             `json:"description,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	AdditionalProperties *bool              `json:"additionalProperties,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	Enum                 []string           `json:"enum,omitempty"`
+	Pattern              string             `json:"pattern,omitempty"`
+	Minimum              *float64           `json:"minimum,omitempty"`
+	Maximum              *float64           `json:"maximum,omitempty"`
+	MinLength            *int               `json:"minLength,omitempty"`
+	MaxLength            *int               `json:"maxLength,omitempty"`
+	MinItems             *int               `json:"minItems,omitempty"`
+	MaxItems             *int               `json:"maxItems,omitempty"`
+}
+
+func ref(name string) *Schema {
+	return &Schema{Ref: "#/components/schemas/" + name}
+}
+
+func schemaType(t reflect.Type) *Schema {
+	switch t.Kind() {
+	case reflect.Int:
+		return &Schema{Type: "integer"}
+	case reflect.Float64:
+		return &Schema{Type: "number"}
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Slice:
+		return &Schema{Type: "array", Items: schemaType(t.Elem())}
+	}
+	return &Schema{Type: "string"}
+}
+
+// applyRules adds the validate rules of a parameter to its schema. Rules after dive
+// apply to the items of a list. It reports whether the parameter is required.
+func applyRules(s *Schema, rules string) bool {
+	required := false
+	for _, rule := range strings.Split(rules, ",") {
+		tag, param, _ := strings.Cut(rule, "=")
+		if values, ok := enums[tag]; ok {
+			s.Enum = values
+			continue
+		}
+		switch tag {
+		case "required":
+			required = true
+		case "dive":
+			_, items, _ := strings.Cut(rules, "dive,")
+			applyRules(s.Items, items)
+			return required
+		case "oneof":
+			s.Enum = strings.Fields(param)
+		case "resolution":
+			s.Pattern = resolutionPattern.String()
+		case "min", "max":
+			n, err := strconv.ParseFloat(param, 64)
+			if err != nil {
+				continue
+			}
+			i := int(n)
+			switch s.Type {
+			case "array":
+				if tag == "min" {
+					s.MinItems = &i
+				} else {
+					s.MaxItems = &i
+				}
+			case "string":
+				if tag == "min" {
+					s.MinLength = &i
+				} else {
+					s.MaxLength = &i
+				}
+			default:
+				if tag == "min" {
+					s.Minimum = &n
+				} else {
+					s.Maximum = &n
+				}
+			}
+		}
+	}
+	return required
+}
+
+// ParamsSchema describes the parameters of the trigger as a JSON object.
+func (t Trigger) ParamsSchema() *Schema {
+	noMore := false
+	s := &Schema{
+		Type:                 "object",
+		Properties:           map[string]*Schema{},
+		AdditionalProperties: &noMore,
+	}
+	for _, p := range requestParams(reflect.New(t.params), reflect.New(commonType)) {
+		field, name := p.field, p.name
+
+		property := schemaType(field.Type)
+		property.Description = field.Tag.Get("doc")
+		if applyRules(property, field.Tag.Get("validate")) {
+			s.Required = append(s.Required, name)
+		}
+		s.Properties[name] = property
+	}
+	return s
+}
+
+// OpenAPI returns an OpenAPI 3 document describing POST /trigger/{name} for every
+// trigger.
+func OpenAPI() map[string]any {
+	schemas := map[string]*Schema{
+		"Started": {
+			Type: "object",
+			Properties: map[string]*Schema{
+				"workflowId": {Type: "string"},
+				"runId":      {Type: "string"},
+			},
+			Required: []string{"workflowId", "runId"},
+		},
+		"Error": {
+			Type: "object",
+			Properties: map[string]*Schema{
+				"error": {Type: "string"},
+				"fields": {Type: "array", Items: &Schema{
+					Type: "object",
+					Properties: map[string]*Schema{
+						"field":   {Type: "string"},
+						"message": {Type: "string"},
+					},
+					Required: []string{"field", "message"},
+				}},
+			},
+			Required: []string{"error"},
+		},
+	}
+	response := func(description, schema string) map[string]any {
+		return map[string]any{
+			"description": description,
+			"content": map[string]any{
+				"application/json": map[string]any{"schema": ref(schema)},
+			},
+		}
+	}
+
+	paths := map[string]any{}
+	for _, t := range All() {
+		schemaName := t.Name + "Params"
+		schemas[schemaName] = t.ParamsSchema()
+
+		paths["/trigger/"+t.Name] = map[string]any{
+			"post": map[string]any{
+				"operationId": t.Name,
+				"summary":     t.Summary,
+				"description": "The parameters can also be sent as query or form values; lists then as repeated or comma separated values.",
+				"requestBody": map[string]any{
+					"content": map[string]any{
+						"application/json":                  map[string]any{"schema": ref(schemaName)},
+						"application/x-www-form-urlencoded": map[string]any{"schema": ref(schemaName)},
+					},
+				},
+				"responses": map[string]any{
+					"200": response("The workflow was started.", "Started"),
+					"400": response("The parameters were refused; fields says why for each.", "Error"),
+					"500": response("The workflow could not be started.", "Error"),
+				},
+			},
+		}
+	}
+
+	return map[string]any{
+		"openapi": "3.0.3",
+		"info": map[string]any{
+			"title":   "bcc-media-flows triggers",
+			"version": "1.0.0",
+		},
+		"paths": paths,
+		"components": map[string]any{
+			"schemas": schemas,
+		},
+	}
+}
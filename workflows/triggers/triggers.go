@@ -0,0 +1,99 @@
+// Package triggers describes the workflows that can be started by name from outside
+// the worker: the parameters each one takes, how they are validated, and the queue it
+// runs on. httpin binds and validates requests with it and serves it as OpenAPI.
+package triggers
+
+import (
+	"reflect"
+	"sort"
+
+	"github.com/bcc-code/bcc-media-flows/environment"
+)
+
+// Trigger is a workflow that can be started by name.
+type Trigger struct {
+	Name    string
+	Summary string
+	// Workflow is the workflow function started with the input built from the params.
+	Workflow any
+	// Queue returns the task queue the workflow is started on. Nil means
+	// environment.GetQueue().
+	Queue func() string
+	// WorkflowID returns the ID the workflow is started with, from its input, for
+	// workflows of which one runs at a time per ID. Nil leaves the ID to the client.
+	WorkflowID func(input any) string
+
+	params reflect.Type
+	input  func(params any) any
+}
+
+// Start is what binding a request to a trigger produced: the workflow to run, where,
+// and with what.
+type Start struct {
+	Common
+	Workflow any
+	Input    any
+	Queue    string
+	// WorkflowID is the ID the workflow is started with. Empty leaves it to the client.
+	WorkflowID string
+	// VXID is the asset the workflow is about, when the trigger takes a vxID.
+	VXID string
+}
+
+// define declares a trigger whose request parameters are the fields of P, described
+// by their json, validate and doc tags. input turns validated params into the
+// workflow's input.
+func define[P any](name, summary string, workflow any, input func(P) any) Trigger {
+	return Trigger{
+		Name:     name,
+		Summary:  summary,
+		Workflow: workflow,
+		params:   reflect.TypeOf((*P)(nil)).Elem(),
+		input: func(params any) any {
+			return input(*params.(*P))
+		},
+	}
+}
+
+// withWorkflowID makes the trigger start its workflow with the ID id gives for the
+// input.
+func (t Trigger) withWorkflowID(id func(input any) string) Trigger {
+	t.WorkflowID = id
+	return t
+}
+
+func (t Trigger) queue() string {
+	if t.Queue != nil {
+		return t.Queue()
+	}
+	return environment.GetQueue()
+}
+
+var byName = func() map[string]Trigger {
+	m := map[string]Trigger{}
+	for _, t := range definitions {
+		if _, ok := m[t.Name]; ok {
+			panic("triggers: " + t.Name + " is defined twice")
+		}
+		m[t.Name] = t
+	}
+	return m
+}()
+
+// Get returns the trigger with the given name.
+func Get(name string) (Trigger, bool) {
+	t, ok := byName[name]
+	return t, ok
+}
+
+// All returns every trigger, ordered by name.
+func All() []Trigger {
+	all := make([]Trigger, 0, len(byName))
+	for _, t := range byName {
+		all = append(all, t)
+	}
+	sort.Slice(all, func(i, j int) bool {
+		return all[i].Name < all[j].Name
+	})
+	return all
+}
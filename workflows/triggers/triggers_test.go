@@ -0,0 +1,153 @@
+package triggers
+
+import (
+	"encoding/json"
+	"net/url"
+	"reflect"
+	"runtime"
+	"testing"
+
+	"github.com/bcc-code/bcc-media-flows/utils"
+	"github.com/bcc-code/bcc-media-flows/workflows"
+	"github.com/bcc-code/bcc-media-flows/workflows/export"
+	miscworkflows "github.com/bcc-code/bcc-media-flows/workflows/misc"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func mustGet(t *testing.T, name string) Trigger {
+	t.Helper()
+
+	trigger, ok := Get(name)
+	require.True(t, ok, name)
+	return trigger
+}
+
+func fieldErrors(t *testing.T, err error) []FieldError {
+	t.Helper()
+
+	var invalid *ValidationError
+	require.ErrorAs(t, err, &invalid)
+	return invalid.Errors
+}
+
+func Test_BindValues_ListsAndResolutions(t *testing.T) {
+	start, err := mustGet(t, "ExportAssetVX").BindValues(url.Values{
+		"vxID":         {"VX-1"},
+		"destinations": {"vod,bmm"},
+		"resolutions":  {"1920x1080,1280x720"},
+		"withChapters": {"true"},
+		"triggeredBy":  {"someone"},
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, "VX-1", start.VXID)
+	assert.Equal(t, export.VXExportParams{
+		VXID:         "VX-1",
+		WithChapters: true,
+		Destinations: []string{"vod", "bmm"},
+		Resolutions: []utils.Resolution{
+			{Width: 1920, Height: 1080},
+			{Width: 1280, Height: 720},
+		},
+	}, start.Input)
+}
+
+// Atoi errors used to be ignored, so a typo in width started a workflow with the
+// default size.
+func Test_BindValues_UnparseableInt(t *testing.T) {
+	_, err := mustGet(t, "CreateThumbnailsVX").BindValues(url.Values{
+		"vxID":  {"VX-1"},
+		"width": {"abc"},
+	})
+
+	assert.Equal(t, []FieldError{{"width", "must be an integer"}}, fieldErrors(t, err))
+}
+
+func Test_BindValues_ReportsEveryField(t *testing.T) {
+	_, err := mustGet(t, "ExportAssetVX").BindValues(url.Values{
+		"destinations": {"vod", "tape"},
+		"resolutions":  {"1080p"},
+	})
+
+	assert.ElementsMatch(t, []FieldError{
+		{"vxID", "is required"},
+		{"destinations[1]", "must be one of xdcam, vod, bmm, bmm-integration, isilon, vod-cmaf"},
+		{"resolutions[0]", "must be WIDTHxHEIGHT, such as 1920x1080"},
+	}, fieldErrors(t, err))
+}
+
+func Test_BindJSON(t *testing.T) {
+	trigger := mustGet(t, "NormalizeAudio")
+
+	start, err := trigger.BindJSON([]byte(`{"file": "/mnt/isilon/x.wav", "targetLUFS": -23}`))
+	require.NoError(t, err)
+	assert.Equal(t, miscworkflows.NormalizeAudioParams{
+		FilePath:              "/mnt/isilon/x.wav",
+		TargetLUFS:            -23,
+		PerformOutputAnalysis: true,
+	}, start.Input)
+	assert.Empty(t, start.VXID)
+
+	_, err = trigger.BindJSON([]byte(`{"file": "/mnt/isilon/x.wav", "targetLUFS": "loud", "target": -23}`))
+	assert.ElementsMatch(t, []FieldError{
+		{"targetLUFS", "must be a number"},
+		{"target", "is not a parameter of NormalizeAudio"},
+	}, fieldErrors(t, err))
+
+	_, err = trigger.BindJSON([]byte(`[1, 2]`))
+	assert.ErrorContains(t, err, "not a JSON object")
+}
+
+// A second live ingest from a source is only refused when both have its ID.
+func Test_Bind_WorkflowID(t *testing.T) {
+	start, err := mustGet(t, "IncrementalIngest").BindJSON([]byte(`{"path": "/mnt/filecatalyst/ingestgrow/room-b/x.mxf", "source": "room-b"}`))
+	require.NoError(t, err)
+	assert.Equal(t, "LIVE-INGEST-room-b", start.WorkflowID)
+
+	start, err = mustGet(t, "NormalizeAudio").BindJSON([]byte(`{"file": "/mnt/isilon/x.wav", "targetLUFS": -23}`))
+	require.NoError(t, err)
+	assert.Empty(t, start.WorkflowID)
+}
+
+func Test_Definitions(t *testing.T) {
+	registered := map[string]bool{}
+	for _, w := range workflows.WorkerWorkflows {
+		registered[runtime.FuncForPC(reflect.ValueOf(w).Pointer()).Name()] = true
+	}
+
+	for _, trigger := range All() {
+		name := runtime.FuncForPC(reflect.ValueOf(trigger.Workflow).Pointer()).Name()
+		assert.True(t, registered[name], "%s starts %s, which the worker does not run", trigger.Name, name)
+	}
+
+	// The destinations a request may name are the ones VXExport accepts.
+	schema := mustGet(t, "ExportAssetVX").ParamsSchema()
+	assert.ElementsMatch(t, export.AssetExportDestinations.Values(), schema.Properties["destinations"].Items.Enum)
+}
+
+func Test_OpenAPI(t *testing.T) {
+	data, err := json.Marshal(OpenAPI())
+	require.NoError(t, err)
+
+	var doc struct {
+		Paths      map[string]map[string]any `json:"paths"`
+		Components struct {
+			Schemas map[string]*Schema `json:"schemas"`
+		} `json:"components"`
+	}
+	require.NoError(t, json.Unmarshal(data, &doc))
+
+	assert.Len(t, doc.Paths, len(All()))
+	assert.Contains(t, doc.Paths["/trigger/NormalizeAudio"], "post")
+
+	params := doc.Components.Schemas["NormalizeAudioParams"]
+	require.NotNil(t, params)
+	assert.ElementsMatch(t, []string{"file", "targetLUFS"}, params.Required)
+	assert.Equal(t, "number", params.Properties["targetLUFS"].Type)
+	assert.Equal(t, -70.0, *params.Properties["targetLUFS"].Minimum)
+
+	resolutions := doc.Components.Schemas["ExportAssetVXParams"].Properties["resolutions"]
+	assert.Equal(t, "array", resolutions.Type)
+	assert.Equal(t, resolutionPattern.String(), resolutions.Items.Pattern)
+}
@@ -1,6 +1,8 @@
 package vb_export
 
 import (
+	"strings"
+
 	"github.com/bcc-code/bcc-media-flows/activities"
 	"github.com/bcc-code/bcc-media-flows/paths"
 	"github.com/bcc-code/bcc-media-flows/services/rclone"
@@ -18,10 +20,16 @@ type vbExportDestination struct {
 	destination Destination
 
 	// ext is ignored when imageAware, which delivers an image under its own
-	// extension and everything else as .mov.
+	// extension and everything else as ext, or .mov when ext is empty.
 	ext        string
 	imageAware bool
 
+	// folder replaces the delivery folder of the destination when set.
+	folder string
+	// filename is a template for the delivered file, as in DefaultFilename. Empty names
+	// a copied file after its source, and anything else by DefaultFilename.
+	filename string
+
 	copySource func(VBExportChildWorkflowParams) paths.Path
 	transcode  transcodeFunc
 
@@ -50,10 +58,15 @@ func runVBExportChild(ctx workflow.Context, params VBExportChildWorkflowParams,
 	if dest.copySource != nil {
 		filePath = dest.copySource(params)
 		destName = filePath.Base()
+		if dest.filename != "" {
+			destName = expandFilename(dest.filename, params, "", filePath.Ext())
+		}
 	} else {
 		ext := dest.ext
 		if dest.imageAware {
-			ext = ".mov"
+			if ext == "" {
+				ext = ".mov"
+			}
 			if isImage {
 				ext = params.InputFile.Ext()
 			}
@@ -64,10 +77,18 @@ func runVBExportChild(ctx workflow.Context, params VBExportChildWorkflowParams,
 			extraFileName = "_SUB_NOR"
 		}
 
-		destName = params.OriginalFilenameWithoutExt + extraFileName + ext
+		template := dest.filename
+		if template == "" {
+			template = DefaultFilename
+		}
+		destName = expandFilename(template, params, extraFileName, ext)
 	}
 
-	rcloneDestination := deliveryFolder.Append(dest.destination.DeliveryFolder(), destName)
+	folder := dest.folder
+	if folder == "" {
+		folder = dest.destination.DeliveryFolder()
+	}
+	rcloneDestination := deliveryFolder.Append(folder, destName)
 
 	err := wfutils.RcloneWaitForFileGone(ctx, rcloneDestination, telegram.ChatOslofjord, 10)
 	if err != nil {
@@ -99,6 +120,13 @@ func runVBExportChild(ctx workflow.Context, params VBExportChildWorkflowParams,
 		return nil, err
 	}
 
+	if dest.copySource != nil && filePath == params.OriginalFile && params.OriginalChecksums != nil {
+		err = wfutils.VerifyFile(ctx, rcloneDestination, *params.OriginalChecksums)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	notifyExportDone(ctx, params, dest.destination, filePath)
 
 	return &VBExportResult{
@@ -107,6 +135,16 @@ func runVBExportChild(ctx workflow.Context, params VBExportChildWorkflowParams,
 	}, nil
 }
 
+// expandFilename fills in a filename template. See DefaultFilename.
+func expandFilename(template string, params VBExportChildWorkflowParams, sub, ext string) string {
+	return strings.NewReplacer(
+		"{name}", params.OriginalFilenameWithoutExt,
+		"{sub}", sub,
+		"{vxid}", params.ParentParams.VXID,
+		"{ext}", ext,
+	).Replace(template)
+}
+
 type proRes struct {
 	interlace bool
 	alpha     bool
@@ -0,0 +1,397 @@
+package vb_export
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/bcc-code/bcc-media-flows/activities"
+	"github.com/bcc-code/bcc-media-flows/paths"
+	"github.com/bcc-code/bcc-media-flows/services/transcode"
+	"github.com/bcc-code/bcc-media-flows/utils"
+	wfutils "github.com/bcc-code/bcc-media-flows/utils/workflows"
+	"go.temporal.io/sdk/workflow"
+)
+
+// Encoders a profile can use.
+const (
+	EncoderProRes          = "prores"
+	EncoderHyperdeckProRes = "prores-hyperdeck"
+	EncoderAVCIntra        = "avc-intra"
+	EncoderXDCAM           = "xdcam"
+	EncoderHAP             = "hap"
+	EncoderHAPQ            = "hapq"
+	// EncoderCopy delivers the input with normalized audio, as it is.
+	EncoderCopy = "copy"
+	// EncoderCopyOriginal delivers the original file, as it is.
+	EncoderCopyOriginal = "copy-original"
+)
+
+// AudioLayout4Mono splits a 5.1 stream into four mono streams before encoding.
+const AudioLayout4Mono = "4mono"
+
+// What a profile does with an image input.
+const (
+	// ImagesDeliver delivers the image as it is.
+	ImagesDeliver = "deliver"
+	// ImagesCopy copies the image into the output folder and delivers the copy.
+	ImagesCopy = "copy"
+)
+
+// DefaultFilename is the filename template of a profile that has none. {name} is the
+// filename of the original without its extension, {sub} is "_SUB_NOR" when subtitles
+// are burnt in, {vxid} is the item and {ext} is the container.
+const DefaultFilename = "{name}{sub}{ext}"
+
+// encodeActivities are the encoders that take EncodeParams.
+var encodeActivities = map[string]func(context.Context, activities.EncodeParams) (*activities.EncodeResult, error){
+	EncoderProRes:          activities.Video.TranscodeToProResActivity,
+	EncoderHyperdeckProRes: activities.Video.TranscodeToHyperdeckProResActivity,
+	EncoderAVCIntra:        activities.Video.TranscodeToAVCIntraActivity,
+	EncoderXDCAM:           activities.Video.TranscodeToXDCAMActivity,
+}
+
+var hapFormats = map[string]transcode.HAPFormat{
+	EncoderHAP:  transcode.HAPFormatHAP,
+	EncoderHAPQ: transcode.HAPFormatHAPQ,
+}
+
+// defaultContainers is the container of each encoder, for profiles that do not set one.
+var defaultContainers = map[string]string{
+	EncoderProRes:          ".mov",
+	EncoderHyperdeckProRes: ".mov",
+	EncoderAVCIntra:        ".mxf",
+	EncoderXDCAM:           ".mxf",
+	EncoderHAP:             ".mov",
+	EncoderHAPQ:            ".mov",
+}
+
+// Profile is a VB export destination described in configuration rather than code. HAP
+// and the copy encoders keep the resolution and frame rate of the input.
+type Profile struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	// Extends is a profile this one starts from. The fields set here replace its own.
+	Extends string `json:"extends,omitempty"`
+
+	Encoder    string `json:"encoder"`
+	Resolution string `json:"resolution,omitempty"`
+	FrameRate  int    `json:"frameRate,omitempty"`
+	Interlace  bool   `json:"interlace,omitempty"`
+	Alpha      bool   `json:"alpha,omitempty"`
+	Bitrate    string `json:"bitrate,omitempty"`
+
+	AudioLayout string `json:"audioLayout,omitempty"`
+	// BurnInSubtitles is whether the selected subtitles are burnt in. Nil burns them in.
+	BurnInSubtitles *bool `json:"burnInSubtitles,omitempty"`
+
+	// Container is the extension of the delivered file, such as ".mov". The encoders
+	// each write one container, so it can only be that one; copies keep the container of
+	// what they copy.
+	Container string `json:"container,omitempty"`
+	Images    string `json:"images,omitempty"`
+	// Folder is the subfolder of the delivery folder the file is delivered to.
+	Folder   string `json:"folder"`
+	Filename string `json:"filename,omitempty"`
+}
+
+func (p Profile) burnInSubtitles() bool {
+	return p.BurnInSubtitles == nil || *p.BurnInSubtitles
+}
+
+func (p Profile) copies() bool {
+	return p.Encoder == EncoderCopy || p.Encoder == EncoderCopyOriginal
+}
+
+func (p Profile) container() string {
+	if p.Container != "" {
+		return p.Container
+	}
+	return defaultContainers[p.Encoder]
+}
+
+func (p Profile) validate() error {
+	if p.Name == "" {
+		return fmt.Errorf("name is required")
+	}
+	if Destinations.Parse(p.Name) != nil {
+		return fmt.Errorf("%q is a built-in destination", p.Name)
+	}
+	if p.Folder == "" {
+		return fmt.Errorf("folder is required")
+	}
+
+	_, encodes := encodeActivities[p.Encoder]
+	_, hap := hapFormats[p.Encoder]
+	if !encodes && !hap && !p.copies() {
+		return fmt.Errorf("unknown encoder %q", p.Encoder)
+	}
+	if encodes {
+		if _, err := utils.ResolutionFromString(p.Resolution); err != nil {
+			return err
+		}
+		if p.FrameRate <= 0 {
+			return fmt.Errorf("frameRate is required for %s", p.Encoder)
+		}
+	}
+
+	if p.Container != "" {
+		if p.copies() {
+			return fmt.Errorf("container cannot be set for %s, which keeps the container of the file", p.Encoder)
+		}
+		if p.Container != defaultContainers[p.Encoder] {
+			return fmt.Errorf("container %q is not what %s writes, %s", p.Container, p.Encoder, defaultContainers[p.Encoder])
+		}
+	}
+
+	switch p.AudioLayout {
+	case "", AudioLayout4Mono:
+	default:
+		return fmt.Errorf("unknown audio layout %q", p.AudioLayout)
+	}
+
+	switch p.Images {
+	case "", ImagesDeliver, ImagesCopy:
+	default:
+		return fmt.Errorf("images is not %q or %q", ImagesDeliver, ImagesCopy)
+	}
+
+	if !strings.Contains(p.Filename, "{name}") && !strings.Contains(p.Filename, "{vxid}") && p.Filename != "" {
+		return fmt.Errorf("filename %q has neither {name} nor {vxid}", p.Filename)
+	}
+	return nil
+}
+
+// LoadProfiles reads profiles from a JSON file, a list of them under "profiles".
+func LoadProfiles(file string) ([]Profile, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+
+	var config struct {
+		Profiles []json.RawMessage `json:"profiles"`
+	}
+	if err := json.Unmarshal(data, &config); err != nil {
+		return nil, fmt.Errorf("%s: %w", file, err)
+	}
+
+	raw := map[string]json.RawMessage{}
+	var names []string
+	for i, r := range config.Profiles {
+		var p Profile
+		if err := json.Unmarshal(r, &p); err != nil {
+			return nil, fmt.Errorf("%s: profile %d: %w", file, i+1, err)
+		}
+		if _, ok := raw[p.Name]; ok {
+			return nil, fmt.Errorf("%s: profile %q is defined twice", file, p.Name)
+		}
+		raw[p.Name] = r
+		names = append(names, p.Name)
+	}
+
+	var profiles []Profile
+	for _, name := range names {
+		p, err := resolveProfile(raw, name, nil)
+		if err != nil {
+			return nil, fmt.Errorf("%s: profile %q: %w", file, name, err)
+		}
+		if err := p.validate(); err != nil {
+			return nil, fmt.Errorf("%s: profile %q: %w", file, name, err)
+		}
+		profiles = append(profiles, p)
+	}
+	return profiles, nil
+}
+
+// resolveProfile decodes a profile over the one it extends, so that the fields it
+// leaves out keep their values from there.
+func resolveProfile(raw map[string]json.RawMessage, name string, seen []string) (Profile, error) {
+	for _, s := range seen {
+		if s == name {
+			return Profile{}, fmt.Errorf("extends itself through %s", strings.Join(append(seen, name), " -> "))
+		}
+	}
+
+	r, ok := raw[name]
+	if !ok {
+		return Profile{}, fmt.Errorf("extends %q, which is not defined", name)
+	}
+
+	var header struct {
+		Extends string `json:"extends"`
+	}
+	if err := json.Unmarshal(r, &header); err != nil {
+		return Profile{}, err
+	}
+
+	var p Profile
+	if header.Extends != "" {
+		base, err := resolveProfile(raw, header.Extends, append(seen, name))
+		if err != nil {
+			return Profile{}, err
+		}
+		p = base
+	}
+
+	if err := json.Unmarshal(r, &p); err != nil {
+		return Profile{}, err
+	}
+	return p, nil
+}
+
+var (
+	profilesLock sync.RWMutex
+	profiles     = map[string]Profile{}
+)
+
+// ConfigureProfiles replaces the profiles VBExport offers next to the built-in
+// destinations.
+func ConfigureProfiles(list []Profile) {
+	m := map[string]Profile{}
+	for _, p := range list {
+		m[p.Name] = p
+	}
+
+	profilesLock.Lock()
+	defer profilesLock.Unlock()
+	profiles = m
+}
+
+// Profiles are the configured profiles, by name.
+func Profiles() []Profile {
+	profilesLock.RLock()
+	defer profilesLock.RUnlock()
+
+	var list []Profile
+	for _, p := range profiles {
+		list = append(list, p)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Name < list[j].Name })
+	return list
+}
+
+// DestinationOption is a destination the export form offers.
+type DestinationOption struct {
+	Value       string
+	Description string
+}
+
+// DestinationOptions are the built-in destinations followed by the configured profiles.
+func DestinationOptions() []DestinationOption {
+	var options []DestinationOption
+	for _, d := range Destinations.Members() {
+		options = append(options, DestinationOption{Value: d.Value, Description: d.Description()})
+	}
+	for _, p := range Profiles() {
+		options = append(options, DestinationOption{Value: p.Name, Description: p.Description})
+	}
+	return options
+}
+
+// lookupProfiles finds the profiles of the destinations that are not built in. It goes
+// through SideEffect, as the profiles are configuration a replay might not share.
+func lookupProfiles(ctx workflow.Context, names []string) (map[string]Profile, error) {
+	var found map[string]Profile
+	err := workflow.SideEffect(ctx, func(workflow.Context) any {
+		profilesLock.RLock()
+		defer profilesLock.RUnlock()
+
+		m := map[string]Profile{}
+		for _, name := range names {
+			if p, ok := profiles[name]; ok {
+				m[name] = p
+			}
+		}
+		return m
+	}).Get(&found)
+
+	return found, err
+}
+
+// VBExportToProfile exports to a destination described by a profile, which VBExport
+// passes in the params.
+func VBExportToProfile(ctx workflow.Context, params VBExportChildWorkflowParams) (*VBExportResult, error) {
+	if params.Profile == nil {
+		return nil, fmt.Errorf("no profile to export with")
+	}
+	profile := *params.Profile
+
+	if !profile.burnInSubtitles() {
+		params.SubtitleFile = nil
+	}
+
+	return runVBExportChild(ctx, params, profile.destination())
+}
+
+func (p Profile) destination() vbExportDestination {
+	dest := vbExportDestination{
+		destination: Destination{Value: p.Name},
+		ext:         p.container(),
+		folder:      p.Folder,
+		filename:    p.Filename,
+		imageAware:  p.Images != "",
+	}
+	if dest.filename == "" {
+		dest.filename = DefaultFilename
+	}
+	if p.Images == ImagesCopy {
+		dest.image = copyImageToOutputDir
+	}
+
+	if format, ok := hapFormats[p.Encoder]; ok {
+		dest.transcode = transcodeToHAP(format)
+		return dest
+	}
+
+	switch p.Encoder {
+	case EncoderCopy:
+		dest.copySource = func(params VBExportChildWorkflowParams) paths.Path { return params.InputFile }
+	case EncoderCopyOriginal:
+		dest.copySource = func(params VBExportChildWorkflowParams) paths.Path { return params.OriginalFile }
+	default:
+		dest.transcode = p.transcode
+	}
+	return dest
+}
+
+func (p Profile) transcode(ctx workflow.Context, params VBExportChildWorkflowParams, outputDir paths.Path) (paths.Path, error) {
+	file := params.InputFile
+	if p.AudioLayout == AudioLayout4Mono {
+		analyzeResult, err := wfutils.Execute(ctx, activities.Audio.AnalyzeFile, activities.AnalyzeFileParams{
+			FilePath: params.InputFile,
+		}).Result(ctx)
+		if err != nil {
+			return paths.Path{}, err
+		}
+
+		file, err = convertTo4Mono(ctx, params, analyzeResult)
+		if err != nil {
+			return paths.Path{}, err
+		}
+	}
+
+	res, err := wfutils.Execute(ctx, encodeActivities[p.Encoder], activities.EncodeParams{
+		FilePath:       file,
+		OutputDir:      outputDir,
+		Resolution:     utils.MustResolution(p.Resolution),
+		FrameRate:      p.FrameRate,
+		Interlace:      p.Interlace,
+		Bitrate:        p.Bitrate,
+		BurnInSubtitle: params.SubtitleFile,
+		SubtitleStyle:  params.SubtitleStyle,
+		Alpha:          p.Alpha,
+	}).Result(ctx)
+	if err != nil {
+		return paths.Path{}, err
+	}
+
+	if res.OutputPath.Ext() != p.container() {
+		return paths.Path{}, fmt.Errorf("expected %s output to be %s, got %s", p.Name, p.container(), res.OutputPath.Ext())
+	}
+	return res.OutputPath, nil
+}
@@ -0,0 +1,68 @@
+package vb_export
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeProfiles(t *testing.T, content string) string {
+	file := filepath.Join(t.TempDir(), "profiles.json")
+	require.NoError(t, os.WriteFile(file, []byte(content), 0644))
+	return file
+}
+
+func TestLoadProfiles_Extends(t *testing.T) {
+	file := writeProfiles(t, `{"profiles": [
+		{"name": "clean", "extends": "led", "burnInSubtitles": false, "filename": "{name}_clean{ext}"},
+		{"name": "led", "description": "LED wall", "encoder": "prores", "resolution": "3840x2160", "frameRate": 50, "interlace": true, "folder": "LED"}
+	]}`)
+
+	profiles, err := LoadProfiles(file)
+	require.NoError(t, err)
+	require.Len(t, profiles, 2)
+
+	clean := profiles[0]
+	assert.Equal(t, "clean", clean.Name)
+	assert.Equal(t, EncoderProRes, clean.Encoder)
+	assert.Equal(t, "3840x2160", clean.Resolution)
+	assert.True(t, clean.Interlace)
+	assert.Equal(t, "LED", clean.Folder)
+	assert.False(t, clean.burnInSubtitles())
+	assert.Equal(t, ".mov", clean.container())
+
+	assert.True(t, profiles[1].burnInSubtitles())
+}
+
+func TestLoadProfiles_Invalid(t *testing.T) {
+	for name, content := range map[string]string{
+		"unknown encoder":  `{"profiles": [{"name": "a", "encoder": "mpeg1", "folder": "A"}]}`,
+		"no resolution":    `{"profiles": [{"name": "a", "encoder": "prores", "frameRate": 50, "folder": "A"}]}`,
+		"no folder":        `{"profiles": [{"name": "a", "encoder": "copy"}]}`,
+		"built-in name":    `{"profiles": [{"name": "xdcam", "encoder": "copy", "folder": "A"}]}`,
+		"defined twice":    `{"profiles": [{"name": "a", "encoder": "copy", "folder": "A"}, {"name": "a", "encoder": "copy", "folder": "B"}]}`,
+		"extends a cycle":  `{"profiles": [{"name": "a", "extends": "b"}, {"name": "b", "extends": "a"}]}`,
+		"extends unknown":  `{"profiles": [{"name": "a", "extends": "b"}]}`,
+		"bad audio layout": `{"profiles": [{"name": "a", "encoder": "hap", "audioLayout": "7.1", "folder": "A"}]}`,
+		"bad filename":     `{"profiles": [{"name": "a", "encoder": "hap", "filename": "out{ext}", "folder": "A"}]}`,
+		"other container":  `{"profiles": [{"name": "a", "encoder": "hap", "container": ".mxf", "folder": "A"}]}`,
+		"copy container":   `{"profiles": [{"name": "a", "encoder": "copy", "container": ".mov", "folder": "A"}]}`,
+	} {
+		t.Run(name, func(t *testing.T) {
+			_, err := LoadProfiles(writeProfiles(t, content))
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestDestinationOptions(t *testing.T) {
+	ConfigureProfiles([]Profile{{Name: "led", Description: "LED wall"}})
+	defer ConfigureProfiles(nil)
+
+	options := DestinationOptions()
+	require.Len(t, options, len(Destinations.Members())+1)
+	assert.Equal(t, DestinationOption{Value: "led", Description: "LED wall"}, options[len(options)-1])
+}
@@ -17,7 +17,9 @@ import (
 
 	avidispine "github.com/bcc-code/bcc-media-flows/activities/vidispine"
 	"github.com/bcc-code/bcc-media-flows/services/ffmpeg"
+	"github.com/bcc-code/bcc-media-flows/services/fixity"
 	wfutils "github.com/bcc-code/bcc-media-flows/utils/workflows"
+	miscworkflows "github.com/bcc-code/bcc-media-flows/workflows/misc"
 	"go.temporal.io/sdk/workflow"
 )
 
@@ -136,6 +138,10 @@ type VBExportChildWorkflowParams struct {
 	TempDir                    paths.Path
 	OutputDir                  paths.Path
 	AnalyzeResult              ffmpeg.StreamInfo
+	// Profile is the destination of VBExportToProfile.
+	Profile *Profile `json:",omitempty"`
+	// OriginalChecksums are what OriginalFile was recorded with at ingest, if it was.
+	OriginalChecksums *fixity.Checksums `json:",omitempty"`
 }
 
 // subtitleStyleDir goes through SideEffect so a replay on a differently configured
@@ -160,14 +166,32 @@ func VBExport(ctx workflow.Context, params VBExportParams) ([]wfutils.ResultOrEr
 	}
 
 	var destinations []*Destination
+	var profileNames []string
 	for _, dest := range params.Destinations {
 		d := Destinations.Parse(dest)
 		if d == nil {
-			return nil, fmt.Errorf("invalid destination: %s", dest)
+			d = &Destination{Value: dest}
+			profileNames = append(profileNames, dest)
 		}
 		destinations = append(destinations, d)
 	}
 
+	// Only exports to profiles look them up, so exports to built-in destinations have
+	// the history they always had.
+	destinationProfiles := map[string]Profile{}
+	if len(profileNames) > 0 {
+		var err error
+		destinationProfiles, err = lookupProfiles(ctx, profileNames)
+		if err != nil {
+			return nil, err
+		}
+		for _, name := range profileNames {
+			if _, ok := destinationProfiles[name]; !ok {
+				return nil, fmt.Errorf("invalid destination: %s", name)
+			}
+		}
+	}
+
 	shapes, err := wfutils.Execute(ctx, activities.Vidispine.GetShapes, avidispine.VXOnlyParam{
 		VXID: params.VXID,
 	}).Result(ctx)
@@ -192,6 +216,33 @@ func VBExport(ctx workflow.Context, params VBExportParams) ([]wfutils.ResultOrEr
 		),
 	)
 
+	wfutils.MarkUsed(ctx, params.VXID)
+
+	coldArchive, err := wfutils.ColdArchive(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if miscworkflows.IsArchived(coldArchive, videoShape.GetPath()) {
+		wfutils.SendTelegramText(ctx, telegram.ChatOslofjord,
+			fmt.Sprintf("🟧 VB Export of %s is waiting for the original to be restored from the cold archive, which can take two days.", params.VXID))
+
+		err = miscworkflows.RestoreArchivedOriginals(ctx, []string{params.VXID})
+		if err != nil {
+			return nil, err
+		}
+
+		shapes, err = wfutils.Execute(ctx, activities.Vidispine.GetShapes, avidispine.VXOnlyParam{
+			VXID: params.VXID,
+		}).Result(ctx)
+		if err != nil {
+			return nil, err
+		}
+		videoShape = shapes.GetShape("original")
+		if videoShape == nil {
+			return nil, fmt.Errorf("no original shape found for item %s", params.VXID)
+		}
+	}
+
 	tempDir, err := wfutils.GetWorkflowTempFolder(ctx)
 	if err != nil {
 		return nil, err
@@ -203,7 +254,10 @@ func VBExport(ctx workflow.Context, params VBExportParams) ([]wfutils.ResultOrEr
 		return nil, err
 	}
 
-	videoFilePath := paths.MustParse(videoShape.GetPath())
+	videoFilePath, err := wfutils.ParsePath(ctx, videoShape.GetPath())
+	if err != nil {
+		return nil, err
+	}
 	originalVideoFilePath := videoFilePath
 
 	originalFilenameWithoutExt := videoFilePath.Base()[0 : len(videoFilePath.Base())-len(videoFilePath.Ext())]
@@ -215,9 +269,22 @@ func VBExport(ctx workflow.Context, params VBExportParams) ([]wfutils.ResultOrEr
 	}
 
 	destinationsWithAudioOutput := lo.Filter(destinations, func(dest *Destination, _ int) bool {
+		if profile, ok := destinationProfiles[dest.Value]; ok {
+			return profile.Encoder != EncoderCopyOriginal
+		}
 		return *dest != DestinationCasparCG
 	})
 
+	// The rest deliver the original, which is checked against its checksums once it is
+	// delivered.
+	var originalChecksums *fixity.Checksums
+	if len(destinationsWithAudioOutput) < len(destinations) {
+		originalChecksums, err = wfutils.GetFixity(ctx, params.VXID)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	if len(destinationsWithAudioOutput) > 0 && analyzeResult.HasAudio && len(analyzeResult.AudioStreams) <= 2 {
 		normalizeAudioResult, err := wfutils.Execute(ctx, activities.Audio.NormalizeAudioActivity, activities.NormalizeAudioParams{
 			FilePath:              videoFilePath,
@@ -241,14 +308,20 @@ func VBExport(ctx workflow.Context, params VBExportParams) ([]wfutils.ResultOrEr
 			return nil, err
 		}
 
-		subtitleStylePath := paths.MustParse(styleDir + params.SubtitleStyle)
+		subtitleStylePath, err := wfutils.ParsePath(ctx, styleDir+params.SubtitleStyle)
+		if err != nil {
+			return nil, err
+		}
 		subtitleStyle = &subtitleStylePath
 
 	outer:
 		for _, shape := range shapes.Shape {
 			for _, tag := range shape.Tag {
 				if tag == params.SubtitleShapeTag {
-					path := paths.MustParse(shape.GetPath())
+					path, err := wfutils.ParsePath(ctx, shape.GetPath())
+					if err != nil {
+						return nil, err
+					}
 					subtitleFile = &path
 					break outer
 				}
@@ -263,6 +336,7 @@ func VBExport(ctx workflow.Context, params VBExportParams) ([]wfutils.ResultOrEr
 			OriginalFilenameWithoutExt: originalFilenameWithoutExt,
 			InputFile:                  videoFilePath,
 			OriginalFile:               originalVideoFilePath,
+			OriginalChecksums:          originalChecksums,
 			SubtitleFile:               subtitleFile,
 			SubtitleStyle:              subtitleStyle,
 			TempDir:                    tempDir,
@@ -272,6 +346,10 @@ func VBExport(ctx workflow.Context, params VBExportParams) ([]wfutils.ResultOrEr
 		}
 
 		w, ok := destinationWorkflows[*dest]
+		if profile, isProfile := destinationProfiles[dest.Value]; isProfile {
+			w, ok = VBExportToProfile, true
+			childParams.Profile = &profile
+		}
 		if !ok {
 			return nil, fmt.Errorf("destination not implemented: %s", dest)
 		}
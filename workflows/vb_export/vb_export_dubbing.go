@@ -6,7 +6,6 @@ import (
 	"github.com/bcc-code/bcc-media-flows/activities"
 	vsactivity "github.com/bcc-code/bcc-media-flows/activities/vidispine"
 	"github.com/bcc-code/bcc-media-flows/common"
-	"github.com/bcc-code/bcc-media-flows/languages"
 	"github.com/bcc-code/bcc-media-flows/paths"
 	"github.com/bcc-code/bcc-media-flows/services/rclone"
 	"github.com/bcc-code/bcc-media-flows/services/vidispine/vscommon"
@@ -47,6 +46,9 @@ func VBExportToDubbing(ctx workflow.Context, params VBExportChildWorkflowParams)
 	}
 
 	exportTC := vxMeta.Get(vscommon.FieldExportTCOverride, "00:00:00:00")
+	// The timecode is in the rate of the video, so the audio starts with the frame it
+	// belongs to in 29.97 drop-frame material too.
+	frameRate := params.AnalyzeResult.TimecodeRate
 
 	transcodeSelector := workflow.NewSelector(ctx)
 
@@ -55,6 +57,12 @@ func VBExportToDubbing(ctx workflow.Context, params VBExportChildWorkflowParams)
 		return nil, err
 	}
 
+	languageList, err := wfutils.Languages(ctx)
+	if err != nil {
+		return nil, err
+	}
+	languagesByISO := languageList.ByISO6391()
+
 	for _, lang := range langs {
 		audioFile := relatedAudioFiles[lang]
 		logger.Info("Starting audio transcoding", "lang", lang, "audioFile", audioFile)
@@ -64,9 +72,15 @@ func VBExportToDubbing(ctx workflow.Context, params VBExportChildWorkflowParams)
 			Path:            audioFile,
 			DestinationPath: dubbingOutputDir,
 			Timecode:        exportTC,
+			FrameRate:       frameRate,
 		})
 
-		transcodeSelector.AddFuture(f.Future, postTranscodeAudio(ctx, audioFile, deliveryFolder, lang))
+		dubbReaperChannel := 99
+		if l, ok := languagesByISO[lang]; ok {
+			dubbReaperChannel = l.ReaperChannel
+		}
+
+		transcodeSelector.AddFuture(f.Future, postTranscodeAudio(ctx, audioFile, deliveryFolder, lang, dubbReaperChannel))
 	}
 
 	pilotFile := dubbingOutputDir.Append(params.OriginalFilenameWithoutExt + "_PILOT.wav")
@@ -75,6 +89,7 @@ func VBExportToDubbing(ctx workflow.Context, params VBExportChildWorkflowParams)
 		Frequency:       1000, // Fixed frequency for the pilot tone
 		SampleRate:      48000,
 		TimeCode:        exportTC,
+		FrameRate:       frameRate,
 		DestinationFile: pilotFile,
 	}).Result(ctx)
 	if err != nil {
@@ -96,7 +111,7 @@ func VBExportToDubbing(ctx workflow.Context, params VBExportChildWorkflowParams)
 	}, nil
 }
 
-func postTranscodeAudio(ctx workflow.Context, originalFile paths.Path, destinationBase paths.Path, lang string) func(f workflow.Future) {
+func postTranscodeAudio(ctx workflow.Context, originalFile paths.Path, destinationBase paths.Path, lang string, dubbReaperChannel int) func(f workflow.Future) {
 	logger := workflow.GetLogger(ctx)
 	return func(f workflow.Future) {
 		res, err := wfutils.FutureResult[*common.AudioResult](ctx, f)
@@ -107,11 +122,6 @@ func postTranscodeAudio(ctx workflow.Context, originalFile paths.Path, destinati
 
 		//Naming: wav with trackNumber_languageCode at the end of the name e.g. BIST_S01_E07_MAS_NORmov_1_nor
 
-		dubbReaperChannel := 99
-		if l, ok := languages.LanguagesByISO[lang]; ok {
-			dubbReaperChannel = l.ReaperChannel
-		}
-
 		baseName := originalFile.BaseNoExt()
 		dstName := res.OutputPath.Dir().Append(fmt.Sprintf("%02d_%s_%s.wav", dubbReaperChannel, baseName, lang))
 
@@ -8,6 +8,7 @@ import (
 	"github.com/bcc-code/bcc-media-flows/activities"
 	"github.com/bcc-code/bcc-media-flows/common"
 	"github.com/bcc-code/bcc-media-flows/paths"
+	"github.com/bcc-code/bcc-media-flows/services/ffmpeg"
 	"github.com/bcc-code/bcc-media-flows/utils"
 	wfutils "github.com/bcc-code/bcc-media-flows/utils/workflows"
 	"go.temporal.io/sdk/workflow"
@@ -33,18 +34,9 @@ func transcodeToHyperdeckProRes(ctx workflow.Context, params VBExportChildWorkfl
 		return paths.Path{}, errors.New("hyperdeck export currently does not support alpha channels")
 	}
 
-	fileToTranscode := params.InputFile
-
-	// The prefix catches 5.1, 5.1(side), and any other variation.
-	if len(analyzeResult.AudioStreams) == 1 && strings.HasPrefix(analyzeResult.AudioStreams[0].ChannelLayout, "5.1") {
-		fileToTranscode = params.TempDir.Append("4mono_" + params.InputFile.Base())
-		err = wfutils.Execute(ctx, activities.Audio.Convert51to4Mono, common.AudioInput{
-			Path:            params.InputFile,
-			DestinationPath: fileToTranscode,
-		}).Wait(ctx)
-		if err != nil {
-			return paths.Path{}, err
-		}
+	fileToTranscode, err := convertTo4Mono(ctx, params, analyzeResult)
+	if err != nil {
+		return paths.Path{}, err
 	}
 
 	videoResult, err := wfutils.Execute(ctx, activities.Video.TranscodeToHyperdeckProResActivity, activities.EncodeParams{
@@ -66,3 +58,22 @@ func transcodeToHyperdeckProRes(ctx workflow.Context, params VBExportChildWorkfl
 
 	return videoResult.OutputPath, nil
 }
+
+// convertTo4Mono splits the audio of the input into four mono streams when it is a
+// single 5.1 stream, and returns the input as it is otherwise.
+func convertTo4Mono(ctx workflow.Context, params VBExportChildWorkflowParams, analyzeResult *ffmpeg.StreamInfo) (paths.Path, error) {
+	// The prefix catches 5.1, 5.1(side), and any other variation.
+	if len(analyzeResult.AudioStreams) != 1 || !strings.HasPrefix(analyzeResult.AudioStreams[0].ChannelLayout, "5.1") {
+		return params.InputFile, nil
+	}
+
+	output := params.TempDir.Append("4mono_" + params.InputFile.Base())
+	err := wfutils.Execute(ctx, activities.Audio.Convert51to4Mono, common.AudioInput{
+		Path:            params.InputFile,
+		DestinationPath: output,
+	}).Wait(ctx)
+	if err != nil {
+		return paths.Path{}, err
+	}
+	return output, nil
+}
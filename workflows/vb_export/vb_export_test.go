@@ -319,6 +319,78 @@ func (s *VBExportTestSuite) Test_VBExportChild_SubtitleFileNamesTheDelivery() {
 	s.Equal("/Delivery/FraMB/XDCAM/test_video_SUB_NOR.mxf", copied.Destination.Path)
 }
 
+func (s *VBExportTestSuite) Test_VBExport_Profile() {
+	ConfigureProfiles([]Profile{{Name: "led-wall", Encoder: EncoderProRes, Resolution: "3840x2160", FrameRate: 50, Folder: "LED-Wall"}})
+	defer ConfigureProfiles(nil)
+
+	s.env.OnActivity(activities.Util.SendTelegramMessage, mock.Anything, mock.Anything).Maybe().Return(nil, nil)
+	s.env.OnActivity(activities.Util.CreateFolder, mock.Anything, mock.Anything).Maybe().Return(nil, nil)
+	s.env.OnActivity(activities.Vidispine.GetShapes, mock.Anything, mock.Anything).Return(&vsapi.ShapeResult{
+		Shape: []vsapi.Shape{
+			{
+				Tag: []string{"original"},
+				ContainerComponent: vsapi.ContainerComponent{
+					File: []vsapi.File{{URI: []string{"file:///mnt/isilon/Production/masters/test_video.mxf"}}},
+				},
+			},
+		},
+	}, nil)
+	s.env.OnActivity(activities.Audio.AnalyzeFile, mock.Anything, mock.Anything).Return(&ffmpeg.StreamInfo{HasVideo: true}, nil)
+
+	var child VBExportChildWorkflowParams
+	s.env.OnWorkflow(VBExportToProfile, mock.Anything, mock.Anything).
+		Run(func(args mock.Arguments) { child = args.Get(1).(VBExportChildWorkflowParams) }).
+		Return(&VBExportResult{ID: "VX-123"}, nil)
+
+	s.env.ExecuteWorkflow(VBExport, VBExportParams{
+		VXID:         "VX-123",
+		Destinations: []string{"led-wall"},
+	})
+	s.True(s.env.IsWorkflowCompleted())
+	s.NoError(s.env.GetWorkflowError())
+
+	s.Require().NotNil(child.Profile)
+	s.Equal("LED-Wall", child.Profile.Folder)
+}
+
+func (s *VBExportTestSuite) Test_VBExportToProfile() {
+	s.env.OnActivity(activities.Util.SendTelegramMessage, mock.Anything, mock.Anything).Maybe().Return(nil, nil)
+	s.env.OnActivity(activities.Util.CreateFolder, mock.Anything, mock.Anything).Maybe().Return(nil, nil)
+	s.env.OnActivity(activities.Util.RcloneCheckFileExists, mock.Anything, mock.Anything).Maybe().Return(false, nil)
+	s.env.OnActivity(activities.Util.RcloneWaitForJob, mock.Anything, mock.Anything).Maybe().Return(true, nil)
+
+	outputPath := paths.MustParse("/mnt/temp/workflows/led-wall_output/test_video.mov")
+	s.env.OnActivity(activities.Video.TranscodeToProResActivity, mock.Anything, mock.MatchedBy(func(p activities.EncodeParams) bool {
+		return p.Resolution.Width == 3840 && p.FrameRate == 50 && p.BurnInSubtitle == nil
+	})).Return(&activities.EncodeResult{OutputPath: outputPath}, nil)
+
+	var copied activities.RcloneFileInput
+	s.env.OnActivity(activities.Util.RcloneCopyFile, mock.Anything, mock.Anything).
+		Run(func(args mock.Arguments) { copied = args.Get(1).(activities.RcloneFileInput) }).
+		Return(1, nil)
+
+	noSubtitles := false
+	params := childParams()
+	subtitle := paths.MustParse("/mnt/temp/workflows/test_video.srt")
+	params.SubtitleFile = &subtitle
+	params.Profile = &Profile{
+		Name:            "led-wall",
+		Encoder:         EncoderProRes,
+		Resolution:      "3840x2160",
+		FrameRate:       50,
+		BurnInSubtitles: &noSubtitles,
+		Folder:          "LED-Wall",
+		Filename:        "{vxid}_{name}{sub}{ext}",
+	}
+
+	s.env.ExecuteWorkflow(VBExportToProfile, params)
+	s.True(s.env.IsWorkflowCompleted())
+	s.NoError(s.env.GetWorkflowError())
+
+	s.Equal(outputPath, copied.Source)
+	s.Equal("/Delivery/FraMB/LED-Wall/VX-123_test_video.mov", copied.Destination.Path)
+}
+
 func childParams() VBExportChildWorkflowParams {
 	return VBExportChildWorkflowParams{
 		ParentParams: VBExportParams{
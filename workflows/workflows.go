@@ -23,6 +23,7 @@ var WorkerWorkflows = []any{
 	miscworkflows.MoveMBFile,
 	miscworkflows.MoveFilesWorkerFlow,
 	miscworkflows.CopyFile,
+	miscworkflows.RestoreOriginal,
 	ingestworkflows.BmmIngestUpload,
 	ingestworkflows.BmmTrackMetadata,
 	export.VXExport,
@@ -63,8 +64,12 @@ var WorkerWorkflows = []any{
 	vb_export.VBExportToHyperdeck,
 	vb_export.VBExportToXDCAM,
 	vb_export.VBExportToCasparCG,
+	vb_export.VBExportToProfile,
 	scheduled.CleanupTemp,
+	scheduled.StorageSummary,
 	scheduled.MediabankenPurgeTrash,
+	scheduled.VerifyOriginals,
+	scheduled.ArchiveOriginals,
 	// Massive.app import workflow
 	miscworkflows.MASVImport,
 }